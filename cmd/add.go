@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/vibercode/cli/internal/generator"
+	"github.com/vibercode/cli/internal/storage"
+	"github.com/vibercode/cli/pkg/ui"
+)
+
+var addCmd = &cobra.Command{
+	Use:   "add <feature> [name]",
+	Short: "➕ Add a feature to an existing project",
+	Long: ui.Bold.Sprint("Add a feature to a project generated by vibercode") + "\n\n" +
+		"The project is read from its .vibercode/manifest.vibe. Only the files of the feature are\n" +
+		"generated, its routes and dependencies are registered in cmd/server/main.go and SetupRoutes\n" +
+		"through their syntax tree, keeping your changes, and go.mod requires the new modules.\n\n" +
+		ui.Bold.Sprint("Available features:") + "\n" +
+		"  " + ui.IconGear + " auth          - Accounts, JWT bearer tokens and role middleware\n" +
+		"  " + ui.IconDoc + " docs          - Swagger UI serving the OpenAPI documents\n" +
+		"  " + ui.IconCORS + " middleware    - Middleware preset (api-security, web-app, microservice, public-api), Gin only\n" +
+		"  " + ui.IconHealth + " observability - OpenTelemetry tracing and Prometheus metrics on /metrics\n" +
+		"  " + ui.IconCode + " resource      - CRUD resource of a schema\n" +
+		"  " + ui.IconBuild + " worker        - Background job queue and worker\n\n" +
+		ui.Bold.Sprint("Examples:") + "\n" +
+		"  vibercode add observability\n" +
+		"  vibercode add resource Product\n" +
+		"  vibercode add middleware api-security --output ./my-api\n",
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+
+		options := generator.AddOptions{
+			OutputPath: output,
+			Feature:    args[0],
+			Schemas:    storage.NewFileSchemaStorage(storage.GetDefaultSchemaPath()),
+		}
+		if len(args) > 1 {
+			options.Name = args[1]
+		}
+		return generator.NewAddGenerator().Add(options)
+	},
+}
+
+func init() {
+	addCmd.Flags().String("output", ".", "Project directory")
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"github.com/spf13/cobra"
 	"github.com/vibercode/cli/internal/generator"
+	"github.com/vibercode/cli/internal/models"
 	"github.com/vibercode/cli/pkg/ui"
 )
 
@@ -17,6 +18,10 @@ var generateCmd = &cobra.Command{
 		"  " + ui.IconReact + " ui         - Frontend components with Atomic Design\n" +
 		"  " + ui.IconTest + " test       - Unit, integration, and benchmark tests\n" +
 		"  " + ui.IconDocker + " deployment - Docker, Kubernetes, cloud deployment\n" +
+		"  " + ui.IconGear + " worker     - Background job queue, schedules and worker\n" +
+		"  " + ui.IconDatabase + " admin      - Server-rendered admin panel for the resources\n" +
+		"  " + ui.IconPackage + " sdk        - Typed Go or TypeScript client SDK of the API\n" +
+		"  " + ui.IconCLI + " cli        - Command line client of the API\n" +
 		"  " + ui.IconCode + " plugin     - Plugin scaffolding and templates\n",
 }
 
@@ -27,12 +32,34 @@ var generateAPICmd = &cobra.Command{
 		"This command creates a production-ready Go API with:\n" +
 		"  " + ui.IconPackage + " Clean architecture (handlers, services, repositories)\n" +
 		"  " + ui.IconDatabase + " Database integration (PostgreSQL, MySQL, SQLite)\n" +
+		"  " + ui.IconAPI + " HTTP framework of your choice (Gin, net/http, Chi, Echo, Fiber)\n" +
 		"  " + ui.IconDocker + " Docker setup with docker-compose\n" +
 		"  " + ui.IconGear + " Environment configuration\n" +
 		"  " + ui.IconBuild + " Makefile with common commands\n" +
-		"  " + ui.IconDoc + " Complete documentation\n",
+		"  " + ui.IconDoc + " Complete documentation\n\n" +
+		ui.Bold.Sprint("Examples:") + "\n" +
+		"  vibercode generate api\n" +
+		"  vibercode generate api --http stdlib\n" +
+		"  vibercode generate api --layout hexagonal\n",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		httpName, _ := cmd.Flags().GetString("http")
+		layoutName, _ := cmd.Flags().GetString("layout")
+
 		gen := generator.NewAPIGenerator()
+		if httpName != "" {
+			framework, err := models.ParseHTTPFramework(httpName)
+			if err != nil {
+				return err
+			}
+			gen.WithHTTPFramework(framework)
+		}
+		if layoutName != "" {
+			layout, err := models.ParseProjectLayout(layoutName)
+			if err != nil {
+				return err
+			}
+			gen.WithLayout(layout)
+		}
 		return gen.Generate()
 	},
 }
@@ -205,6 +232,146 @@ var generateDeploymentCmd = &cobra.Command{
 	},
 }
 
+var generateWorkerCmd = &cobra.Command{
+	Use:   "worker",
+	Short: "⏳ Generate a background job queue and worker",
+	Long: ui.Bold.Sprint("Generate a background job queue and worker") + "\n\n" +
+		"This command adds to the project in the current directory:\n" +
+		"  " + ui.IconDatabase + " Database-backed job queue (SKIP LOCKED on PostgreSQL and MySQL, polling on SQLite)\n" +
+		"  " + ui.IconCode + " Typed job handlers with retries and a dead letter\n" +
+		"  " + ui.IconGear + " Cron-style schedules\n" +
+		"  " + ui.IconBuild + " Worker entrypoint under cmd/worker\n" +
+		"  " + ui.IconAPI + " Admin endpoints to inspect the queues\n" +
+		"  " + ui.IconDocker + " docker-compose and Kubernetes Deployment entries\n\n" +
+		ui.Bold.Sprint("Examples:") + "\n" +
+		"  vibercode generate worker\n" +
+		"  vibercode generate worker --database sqlite --http chi\n",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		module, _ := cmd.Flags().GetString("module")
+		database, _ := cmd.Flags().GetString("database")
+		httpName, _ := cmd.Flags().GetString("http")
+
+		options := generator.WorkerOptions{
+			OutputPath: output,
+			Module:     module,
+			Database:   database,
+		}
+		if httpName != "" {
+			framework, err := models.ParseHTTPFramework(httpName)
+			if err != nil {
+				return err
+			}
+			options.HTTP = framework
+		}
+		return generator.NewWorkerGenerator().Generate(options)
+	},
+}
+
+var generateAdminCmd = &cobra.Command{
+	Use:   "admin",
+	Short: "🗂️ Generate a server-rendered admin panel",
+	Long: ui.Bold.Sprint("Generate a server-rendered admin panel") + "\n\n" +
+		"This command adds to the project in the current directory:\n" +
+		"  " + ui.IconCode + " List, detail, create and edit pages for every resource of the project\n" +
+		"  " + ui.IconGear + " Forms laid out from the UI configuration of the schemas\n" +
+		"  " + ui.IconDatabase + " Relation pickers and links to related records\n" +
+		"  " + ui.IconAPI + " Role checks per action and CSRF-protected forms\n\n" +
+		"The pages use html/template and are embedded in the binary, no frontend build is needed.\n\n" +
+		ui.Bold.Sprint("Examples:") + "\n" +
+		"  vibercode generate admin\n" +
+		"  vibercode generate admin --output ./shop --http chi\n",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		module, _ := cmd.Flags().GetString("module")
+		httpName, _ := cmd.Flags().GetString("http")
+
+		options := generator.AdminOptions{
+			OutputPath: output,
+			Module:     module,
+		}
+		if httpName != "" {
+			framework, err := models.ParseHTTPFramework(httpName)
+			if err != nil {
+				return err
+			}
+			options.HTTP = framework
+		}
+		return generator.NewAdminGenerator().Generate(options)
+	},
+}
+
+var generateSDKCmd = &cobra.Command{
+	Use:   "sdk",
+	Short: "📦 Generate a typed client SDK",
+	Long: ui.Bold.Sprint("Generate a typed client SDK") + "\n\n" +
+		"This command adds to the project in the current directory a Go (pkg/sdk) or\n" +
+		"TypeScript (sdk/typescript) client of its API with:\n" +
+		"  " + ui.IconCode + " Typed methods for every resource of the project\n" +
+		"  " + ui.IconGear + " Filter builders and pagination iterators\n" +
+		"  " + ui.IconAPI + " Retries with backoff and bearer token injection\n" +
+		"  " + ui.IconDatabase + " Errors mirroring the problem details of the API\n\n" +
+		"The Go SDK is tested against the handlers of the project with httptest.\n\n" +
+		ui.Bold.Sprint("Examples:") + "\n" +
+		"  vibercode generate sdk\n" +
+		"  vibercode generate sdk --lang ts --output ./shop\n",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		lang, _ := cmd.Flags().GetString("lang")
+		output, _ := cmd.Flags().GetString("output")
+		module, _ := cmd.Flags().GetString("module")
+		httpName, _ := cmd.Flags().GetString("http")
+
+		options := generator.SDKOptions{
+			OutputPath: output,
+			Module:     module,
+			Lang:       lang,
+		}
+		if httpName != "" {
+			framework, err := models.ParseHTTPFramework(httpName)
+			if err != nil {
+				return err
+			}
+			options.HTTP = framework
+		}
+		return generator.NewSDKGenerator().Generate(options)
+	},
+}
+
+var generateCLICmd = &cobra.Command{
+	Use:   "cli",
+	Short: "🖥️ Generate a command line client of the API",
+	Long: ui.Bold.Sprint("Generate a command line client of the API") + "\n\n" +
+		"This command adds to the project in the current directory a cobra binary (cmd/<name>) with:\n" +
+		"  " + ui.IconCode + " list, get, create, update and delete commands for every resource\n" +
+		"  " + ui.IconDatabase + " Commands listing the children and showing the parent of a record\n" +
+		"  " + ui.IconGear + " Config profiles holding the base URL and token of an API\n" +
+		"  " + ui.IconDoc + " Table, JSON and YAML output\n\n" +
+		"The commands call the API through the Go SDK, which is generated in pkg/sdk.\n\n" +
+		ui.Bold.Sprint("Examples:") + "\n" +
+		"  vibercode generate cli\n" +
+		"  vibercode generate cli --name shop --output ./shop\n",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		module, _ := cmd.Flags().GetString("module")
+		name, _ := cmd.Flags().GetString("name")
+		httpName, _ := cmd.Flags().GetString("http")
+
+		options := generator.CLIOptions{
+			OutputPath: output,
+			Module:     module,
+			Name:       name,
+		}
+		if httpName != "" {
+			framework, err := models.ParseHTTPFramework(httpName)
+			if err != nil {
+				return err
+			}
+			options.HTTP = framework
+		}
+		return generator.NewCLIGenerator().Generate(options)
+	},
+}
+
 var generatePluginCmd = &cobra.Command{
 	Use:   "plugin",
 	Short: "🔌 Generate plugin scaffolding and templates",
@@ -245,8 +412,16 @@ func init() {
 	generateCmd.AddCommand(generateMiddlewareCmd)
 	generateCmd.AddCommand(generateTestCmd)
 	generateCmd.AddCommand(generateDeploymentCmd)
+	generateCmd.AddCommand(generateWorkerCmd)
+	generateCmd.AddCommand(generateAdminCmd)
+	generateCmd.AddCommand(generateSDKCmd)
+	generateCmd.AddCommand(generateCLICmd)
 	generateCmd.AddCommand(generatePluginCmd)
 
+	// API command flags
+	generateAPICmd.Flags().String("http", "", "HTTP framework (gin, stdlib, chi, echo, fiber)")
+	generateAPICmd.Flags().String("layout", "", "Project layout (standard, clean, hexagonal, flat, feature)")
+
 	// UI command flags
 	generateUICmd.Flags().Bool("atomic-design", false, "Generate complete Atomic Design structure")
 	generateUICmd.Flags().String("framework", "react", "Choose framework (react, vue, angular)")
@@ -284,6 +459,29 @@ func init() {
 	generateDeploymentCmd.Flags().Bool("with-hpa", false, "Include Horizontal Pod Autoscaler")
 	generateDeploymentCmd.Flags().Bool("full-suite", false, "Generate complete deployment suite")
 
+	// Worker command flags
+	generateWorkerCmd.Flags().String("output", ".", "Project directory")
+	generateWorkerCmd.Flags().String("module", "", "Go module of the project (default from the manifest or go.mod)")
+	generateWorkerCmd.Flags().String("database", "", "Database provider (postgres, mysql, sqlite, supabase) (default from the manifest)")
+	generateWorkerCmd.Flags().String("http", "", "HTTP framework of the admin endpoints (default from the manifest)")
+
+	// Admin command flags
+	generateAdminCmd.Flags().String("output", ".", "Project directory")
+	generateAdminCmd.Flags().String("module", "", "Go module of the project (default from the manifest or go.mod)")
+	generateAdminCmd.Flags().String("http", "", "HTTP framework the panel is mounted on (default from the manifest)")
+
+	// SDK command flags
+	generateSDKCmd.Flags().String("lang", "go", "Language of the SDK (go, ts)")
+	generateSDKCmd.Flags().String("output", ".", "Project directory")
+	generateSDKCmd.Flags().String("module", "", "Go module of the project (default from the manifest or go.mod)")
+	generateSDKCmd.Flags().String("http", "", "HTTP framework the Go SDK tests serve the handlers with (default from the manifest)")
+
+	// CLI command flags
+	generateCLICmd.Flags().String("output", ".", "Project directory")
+	generateCLICmd.Flags().String("module", "", "Go module of the project (default from the manifest or go.mod)")
+	generateCLICmd.Flags().String("name", "", "Name of the binary (default the project name followed by ctl)")
+	generateCLICmd.Flags().String("http", "", "HTTP framework the Go SDK tests serve the handlers with (default from the manifest)")
+
 	// Plugin command flags
 	generatePluginCmd.Flags().String("name", "", "Plugin name (required)")
 	generatePluginCmd.Flags().String("type", "generator", "Plugin type (generator, template, command, integration)")
@@ -31,6 +31,7 @@ func Execute() error {
 
 func init() {
 	rootCmd.AddCommand(generateCmd)
+	rootCmd.AddCommand(addCmd)
 	rootCmd.AddCommand(schemaCmd)
 	rootCmd.AddCommand(runCmd)
 	rootCmd.AddCommand(serveCmd)
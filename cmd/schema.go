@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -95,10 +96,18 @@ var schemaDeleteCmd = &cobra.Command{
 
 // Command flags
 var (
-	outputDir    string
-	module       string
-	dbProvider   string
-	templateName string
+	outputDir       string
+	module          string
+	dbProvider      string
+	templateName    string
+	features        []string
+	graphQL         bool
+	grpcGateway     bool
+	httpName        string
+	dataLayer       string
+	eventPublishers []string
+	apiVersion      string
+	deprecations    []string
 )
 
 func init() {
@@ -113,6 +122,14 @@ func init() {
 	schemaGenerateCmd.Flags().StringVarP(&outputDir, "output", "o", ".", "Output directory for generated code")
 	schemaGenerateCmd.Flags().StringVarP(&module, "module", "m", "", "Go module name")
 	schemaGenerateCmd.Flags().StringVarP(&dbProvider, "database", "d", "postgres", "Database provider (postgres, mysql, sqlite, supabase, mongodb)")
+	schemaGenerateCmd.Flags().StringSliceVar(&features, "features", nil, "Additional features to generate (bulk, export, graphql, grpc, events, caching, search, webhooks, observability)")
+	schemaGenerateCmd.Flags().BoolVar(&graphQL, "graphql", false, "Generate a GraphQL API next to the REST handlers")
+	schemaGenerateCmd.Flags().BoolVar(&grpcGateway, "grpc-gateway", false, "Generate the gRPC service with a REST gateway")
+	schemaGenerateCmd.Flags().StringVar(&httpName, "http", "", "HTTP framework of the handlers (gin, stdlib, chi, echo, fiber), defaults to the project manifest")
+	schemaGenerateCmd.Flags().StringVar(&dataLayer, "data-layer", "gorm", "Data access layer of the repositories (gorm, sqlc, sqlx, pgx)")
+	schemaGenerateCmd.Flags().StringSliceVar(&eventPublishers, "event-publishers", nil, "Broker publishers of the events feature (nats, kafka), implies --features events")
+	schemaGenerateCmd.Flags().StringVar(&apiVersion, "api-version", "", "API version the schema is generated as (v1, v2, ...), defaults to the latest version of the resource")
+	schemaGenerateCmd.Flags().StringSliceVar(&deprecations, "deprecate", nil, "Older API versions to deprecate, with an optional sunset date (v1=2027-01-31)")
 
 	schemaCreateCmd.Flags().StringVarP(&templateName, "template", "t", "", "Use a predefined template")
 }
@@ -325,20 +342,44 @@ func collectRelationConfig(field *models.SchemaField) error {
 	// Relation type
 	if field.Type == "relation" {
 		field.Relation.Type = "one_to_one"
+	} else if ui.ConfirmAction("Is this a many-to-many relation?") {
+		field.Relation.Type = "many_to_many"
 	} else {
 		field.Relation.Type = "one_to_many"
 	}
 
-	// Foreign key
-	foreignKey, err := ui.TextInput(ui.IconGear + " Foreign key field:", field.Name+"_id")
-	if err != nil {
-		return err
+	if field.Relation.Type == "many_to_many" {
+		// Pivot table
+		pivot, err := ui.TextInput(ui.IconDatabase + " Pivot table (optional):")
+		if err != nil {
+			return err
+		}
+		field.Relation.PivotTable = strings.TrimSpace(pivot)
+	} else {
+		// Foreign key
+		foreignKey, err := ui.TextInput(ui.IconGear + " Foreign key field:", field.Name+"_id")
+		if err != nil {
+			return err
+		}
+		field.Relation.ForeignKey = strings.TrimSpace(foreignKey)
+	}
+
+	// Cascade
+	if field.Type == "relation_array" {
+		field.Relation.Cascade = ui.ConfirmAction("Cascade deletes to this relation?")
 	}
-	field.Relation.ForeignKey = strings.TrimSpace(foreignKey)
 
 	// Populate
-	populate := ui.ConfirmAction("Auto-populate this relation?")
-	field.Relation.Populate = populate
+	field.Relation.Populate = ui.ConfirmAction("Auto-populate this relation?")
+	if field.Relation.Populate {
+		depth, err := ui.TextInput(ui.IconGear + " Max include depth:", "1")
+		if err != nil {
+			return err
+		}
+		if field.Relation.PopulateDepth, err = strconv.Atoi(strings.TrimSpace(depth)); err != nil || field.Relation.PopulateDepth < 1 {
+			return fmt.Errorf("include depth must be a positive number")
+		}
+	}
 
 	return nil
 }
@@ -372,7 +413,17 @@ func collectValidationRules(field *models.SchemaField) error {
 			field.Validation.Pattern = pattern
 		}
 
-	case "number", "float", "currency":
+	case "currency":
+		// Allowed currency codes
+		if codes, err := ui.TextInput("Allowed currency codes (optional, comma separated):"); err == nil && codes != "" {
+			for _, code := range strings.Split(codes, ",") {
+				if code = strings.ToUpper(strings.TrimSpace(code)); code != "" {
+					field.Validation.AllowedValues = append(field.Validation.AllowedValues, code)
+				}
+			}
+		}
+
+	case "number", "float":
 		// Min/Max value
 		if min, err := ui.TextInput("Minimum value (optional):"); err == nil && min != "" {
 			if val, err := parseFloatPointer(min); err == nil {
@@ -579,11 +630,44 @@ func generateFromSchema(schemaName string) error {
 		}
 	}
 
+	// Target the project's HTTP framework unless one is given
+	if httpName == "" {
+		if manifest, err := generator.LoadManifest(outputDir); err == nil {
+			httpName = string(manifest.HTTPFramework)
+		}
+	}
+	httpFramework, err := models.ParseHTTPFramework(httpName)
+	if err != nil {
+		return err
+	}
+	layer, err := models.ParseDataLayer(dataLayer)
+	if err != nil {
+		return err
+	}
+	if !layer.SupportsProvider(dbProvider) {
+		return fmt.Errorf("the %s data layer does not support the %s database provider", layer.GetDisplayName(), dbProvider)
+	}
+
+	if graphQL {
+		features = append(features, models.FeatureGraphQL)
+	}
+	if grpcGateway {
+		features = append(features, models.FeatureGRPC)
+	}
+	if len(eventPublishers) > 0 {
+		features = append(features, models.FeatureEvents)
+	}
+
 	ui.PrintHeader("Generating Code")
 	ui.PrintFeature(ui.IconAPI, "Schema", schema.Name)
 	ui.PrintFeature(ui.IconPackage, "Module", module)
 	ui.PrintFeature(ui.IconDatabase, "Database", dbProvider)
+	ui.PrintFeature(ui.IconAPI, "HTTP Framework", httpFramework.GetDisplayName())
+	ui.PrintFeature(ui.IconDatabase, "Data Layer", layer.GetDisplayName())
 	ui.PrintFeature(ui.IconGear, "Output", outputDir)
+	if len(features) > 0 {
+		ui.PrintFeature(ui.IconCode, "Features", strings.Join(features, ", "))
+	}
 
 	if !ui.ConfirmAction("Generate code with these settings?") {
 		ui.PrintInfo("Code generation cancelled")
@@ -591,7 +675,20 @@ func generateFromSchema(schemaName string) error {
 	}
 
 	// Generate code
-	generator := generator.NewSchemaGenerator(schemaStorage)
+	generator := generator.NewSchemaGenerator(schemaStorage).WithFeatures(features...).WithHTTPFramework(httpFramework).WithDataLayer(layer)
+	if grpcGateway {
+		generator.WithGRPCGateway()
+	}
+	if len(eventPublishers) > 0 {
+		generator.WithEventPublishers(eventPublishers...)
+	}
+	if apiVersion != "" {
+		generator.WithAPIVersion(apiVersion)
+	}
+	for _, deprecation := range deprecations {
+		version, sunset, _ := strings.Cut(deprecation, "=")
+		generator.WithDeprecation(strings.TrimSpace(version), strings.TrimSpace(sunset))
+	}
 	if err := generator.GenerateFromSchema(schema.ID, outputDir, module, dbProvider); err != nil {
 		return fmt.Errorf("failed to generate code: %w", err)
 	}
@@ -10,6 +10,7 @@ import (
 	"text/tabwriter"
 
 	"github.com/spf13/cobra"
+	"github.com/vibercode/cli/internal/generator"
 	"github.com/vibercode/cli/internal/templates"
 	"github.com/vibercode/cli/pkg/ui"
 )
@@ -21,6 +22,10 @@ var (
 	showAll        bool
 	categoryFilter string
 	typeFilter     string
+	ejectProject   string
+	ejectGlobal    bool
+	ejectForce     bool
+	ejectStdout    bool
 )
 
 var templateCmd = &cobra.Command{
@@ -51,7 +56,9 @@ var templateCmd = &cobra.Command{
 		ui.Dim.Sprint("  # Generate with custom variables\n") +
 		"  vibercode template generate react-crud-component --output ./components --var schema=user.json\n\n" +
 		ui.Dim.Sprint("  # Show template details\n") +
-		"  vibercode template show react-crud-component",
+		"  vibercode template show react-crud-component\n\n" +
+		ui.Dim.Sprint("  # Override the handler template of the schemas of a project\n") +
+		"  vibercode template eject handler",
 }
 
 var templateListCmd = &cobra.Command{
@@ -85,6 +92,18 @@ var templateValidateCmd = &cobra.Command{
 	RunE:  runTemplateValidateCommand,
 }
 
+var templateEjectCmd = &cobra.Command{
+	Use:   "eject [template-name]",
+	Short: "📤 Copy a built-in schema template to override it",
+	Long: "Copy a built-in schema template (" + strings.Join(generator.SchemaTemplateNames(), ", ") + ") to\n" +
+		".vibercode/templates/<name>.tmpl of the project, or of the home directory with --global.\n" +
+		"Schemas are generated with the template of the project, then of the home directory, then the\n" +
+		"built-in one, with a warning when the built-in template changed since it was ejected.\n" +
+		"Without a name, the schema templates are listed with their overrides.",
+	Args: cobra.MaximumNArgs(1),
+	RunE: runTemplateEjectCommand,
+}
+
 func runTemplateListCommand(cmd *cobra.Command, args []string) error {
 	registry := templates.NewTemplateRegistry(templateDir)
 	if err := registry.LoadTemplates(); err != nil {
@@ -346,6 +365,45 @@ func runTemplateValidateCommand(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runTemplateEjectCommand(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "NAME\tOVERRIDE\tSTATUS\n")
+		for _, name := range generator.SchemaTemplateNames() {
+			override, err := generator.FindTemplateOverride(name, ejectProject)
+			if err != nil {
+				return err
+			}
+			switch {
+			case override == nil:
+				fmt.Fprintf(w, "%s\t-\tbuilt-in\n", name)
+			case override.Outdated:
+				fmt.Fprintf(w, "%s\t%s\tbuilt-in template changed since ejected\n", name, override.Path)
+			default:
+				fmt.Fprintf(w, "%s\t%s\toverridden\n", name, override.Path)
+			}
+		}
+		return w.Flush()
+	}
+
+	name := args[0]
+	if ejectStdout {
+		content, ok := templates.GetSchemaTemplates()[name]
+		if !ok {
+			return fmt.Errorf("unknown template %q (available: %s)", name, strings.Join(generator.SchemaTemplateNames(), ", "))
+		}
+		fmt.Print(content)
+		return nil
+	}
+
+	path, err := generator.EjectTemplate(name, ejectProject, ejectGlobal, ejectForce)
+	if err != nil {
+		return err
+	}
+	ui.PrintSuccess(fmt.Sprintf("Ejected the %s template to %s", name, path))
+	return nil
+}
+
 func init() {
 	// Template management flags
 	templateCmd.PersistentFlags().StringVar(&templateDir, "template-dir", getDefaultTemplateDir(), "Directory containing templates")
@@ -359,11 +417,18 @@ func init() {
 	templateGenerateCmd.Flags().StringVarP(&templateOutput, "output", "o", "", "Output directory for generated files")
 	templateGenerateCmd.Flags().StringSliceVarP(&templateVars, "var", "v", []string{}, "Template variables (key=value or key=file.json)")
 
+	// Eject command flags
+	templateEjectCmd.Flags().StringVar(&ejectProject, "project", ".", "Project directory")
+	templateEjectCmd.Flags().BoolVar(&ejectGlobal, "global", false, "Eject to the templates of the home directory, used by every project")
+	templateEjectCmd.Flags().BoolVar(&ejectForce, "force", false, "Replace an existing override")
+	templateEjectCmd.Flags().BoolVar(&ejectStdout, "stdout", false, "Print the built-in template instead of writing it")
+
 	// Add subcommands
 	templateCmd.AddCommand(templateListCmd)
 	templateCmd.AddCommand(templateShowCmd)
 	templateCmd.AddCommand(templateGenerateCmd)
 	templateCmd.AddCommand(templateValidateCmd)
+	templateCmd.AddCommand(templateEjectCmd)
 }
 
 func getDefaultTemplateDir() string {
@@ -0,0 +1,704 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/module"
+
+	"github.com/vibercode/cli/internal/models"
+	"github.com/vibercode/cli/internal/templates"
+	"github.com/vibercode/cli/pkg/ui"
+)
+
+// Features vibercode add adds to an existing project
+const (
+	AddAuth          = "auth"
+	AddDocs          = "docs"
+	AddMiddleware    = "middleware"
+	AddObservability = "observability"
+	AddResource      = "resource"
+	AddWorker        = "worker"
+)
+
+// AddFeatures lists the features vibercode add adds to a project
+var AddFeatures = []string{AddAuth, AddDocs, AddMiddleware, AddObservability, AddResource, AddWorker}
+
+// addModules are the modules imported by the added features, at the versions the project
+// templates require
+var addModules = []module.Version{
+	{Path: "github.com/go-playground/validator/v10", Version: "v10.14.0"},
+	{Path: "github.com/go-redis/redis/v8", Version: "v8.11.5"},
+	{Path: "github.com/golang-jwt/jwt/v5", Version: "v5.2.1"},
+	{Path: "github.com/google/uuid", Version: "v1.4.0"},
+	{Path: "github.com/joho/godotenv", Version: "v1.4.0"},
+	{Path: "github.com/prometheus/client_golang", Version: "v1.19.1"},
+	{Path: "github.com/shopspring/decimal", Version: "v1.4.0"},
+	{Path: "github.com/sirupsen/logrus", Version: "v1.9.3"},
+	{Path: "go.mongodb.org/mongo-driver", Version: "v1.13.1"},
+	{Path: "go.opentelemetry.io/otel", Version: "v1.28.0"},
+	{Path: "go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp", Version: "v1.28.0"},
+	{Path: "go.opentelemetry.io/otel/sdk", Version: "v1.28.0"},
+	{Path: "go.opentelemetry.io/otel/trace", Version: "v1.28.0"},
+	{Path: "golang.org/x/crypto", Version: "v0.26.0"},
+	{Path: "golang.org/x/time", Version: "v0.5.0"},
+	{Path: "gorm.io/gorm", Version: "v1.25.5"},
+}
+
+// AddOptions contains configuration for adding a feature to a project generated by vibercode
+type AddOptions struct {
+	OutputPath string
+	Feature    string
+	Name       string               // Schema of a resource, preset of the middleware
+	Schemas    models.SchemaStorage // Storage of the schema of a resource
+}
+
+// AddGenerator adds a feature to an existing project: it reads the project from its
+// .vibercode/manifest.vibe, generates the files of the feature and registers its routes and
+// dependencies by editing the syntax tree of cmd/server/main.go and SetupRoutes, so that the
+// changes the user made to them are kept
+type AddGenerator struct {
+	options  AddOptions
+	manifest *VibercodeManifest
+	data     *AddTemplateData
+	provider string
+	files    *SchemaGenerator
+	notes    []string // Wiring left to the user
+}
+
+// AddTemplateData holds the data of the templates of added features
+type AddTemplateData struct {
+	Title  string
+	Module string
+	HTTP   *HTTPDialect
+}
+
+// NewAddGenerator creates a new add generator
+func NewAddGenerator() *AddGenerator {
+	return &AddGenerator{files: NewSchemaGenerator(nil)}
+}
+
+// Add adds a feature to the project in the output path
+func (g *AddGenerator) Add(options AddOptions) error {
+	g.options = options
+	if g.options.OutputPath == "" {
+		g.options.OutputPath = "."
+	}
+	outputPath := g.options.OutputPath
+	feature := g.options.Feature
+
+	ui.PrintStep(1, 4, "Reading project manifest...")
+	manifest, err := LoadManifest(outputPath)
+	if err != nil {
+		return fmt.Errorf("run the command in a project generated by vibercode: %w", err)
+	}
+	g.manifest = manifest
+	if err := g.readProject(); err != nil {
+		return err
+	}
+
+	var generate, wire func() error
+	switch feature {
+	case AddAuth:
+		generate, wire = g.generateAuth, g.wireAuth
+	case AddDocs:
+		generate, wire = g.generateDocs, g.wireDocs
+	case AddMiddleware:
+		generate, wire = g.generateMiddleware, g.wireMiddleware
+	case AddObservability:
+		generate, wire = g.generateObservability, g.wireObservability
+	case AddResource:
+		generate, wire = g.generateResource, g.wireResource
+	case AddWorker:
+		generate, wire = g.generateWorker, g.wireWorker
+	default:
+		return fmt.Errorf("unknown feature %q, add one of %s", feature, strings.Join(AddFeatures, ", "))
+	}
+	// Resources are added one by one, their registration is skipped when present
+	if feature != AddResource && slices.Contains(manifest.Features, feature) {
+		return fmt.Errorf("%s was already added to the project", feature)
+	}
+
+	ui.PrintStep(2, 4, fmt.Sprintf("Generating %s...", feature))
+	if err := generate(); err != nil {
+		return err
+	}
+
+	ui.PrintStep(3, 4, "Registering routes and dependencies...")
+	if err := wire(); err != nil {
+		return err
+	}
+	imports, err := projectImports(outputPath)
+	if err != nil {
+		return err
+	}
+	required, err := requireModules(outputPath, imports, g.knownModules())
+	if err != nil {
+		return fmt.Errorf("failed to update go.mod: %w", err)
+	}
+	for _, mod := range required {
+		ui.PrintInfo(fmt.Sprintf("Required %s %s in go.mod", mod.Path, mod.Version))
+	}
+
+	ui.PrintStep(4, 4, "Updating manifest...")
+	if err := g.recordFeature(); err != nil {
+		return err
+	}
+
+	ui.PrintSuccess(fmt.Sprintf("Added %s to the project!", g.description()))
+	for _, note := range g.notes {
+		ui.PrintInfo(note)
+	}
+	if len(required) > 0 {
+		ui.PrintInfo("Run 'go mod tidy' to download the new requirements")
+	}
+	return nil
+}
+
+// readProject resolves the module, HTTP framework and database provider of the project
+func (g *AddGenerator) readProject() error {
+	g.data = &AddTemplateData{
+		Title:  g.manifest.Name,
+		Module: g.manifest.Module,
+		HTTP:   newHTTPDialect(g.manifest.HTTPFramework),
+	}
+	if g.data.Title == "" {
+		g.data.Title = filepath.Base(absPath(g.options.OutputPath))
+	}
+	if g.data.Module == "" {
+		g.data.Module = readGoModule(g.options.OutputPath)
+	}
+	if g.data.Module == "" {
+		return fmt.Errorf("could not find the Go module of %s", g.options.OutputPath)
+	}
+
+	g.provider = "postgres"
+	if g.manifest.Database != nil && g.manifest.Database.Type != "" {
+		g.provider = g.manifest.Database.Type
+	}
+	return nil
+}
+
+// knownModules returns the modules go.mod may require for the added features
+func (g *AddGenerator) knownModules() []module.Version {
+	known := append([]module.Version{}, addModules...)
+	if framework := g.data.HTTP.Framework; framework.ModulePath() != "" {
+		known = append(known, module.Version{Path: framework.ModulePath(), Version: framework.ModuleVersion()})
+	}
+	return known
+}
+
+// description names the added feature in messages and the manifest history
+func (g *AddGenerator) description() string {
+	switch g.options.Feature {
+	case AddResource:
+		return "the " + g.options.Name + " resource"
+	case AddMiddleware:
+		return "the " + g.options.Name + " middleware preset"
+	default:
+		return g.options.Feature
+	}
+}
+
+// recordFeature appends the change to the manifest history. The manifest is read again, the
+// generators of the feature may have recorded resources in it.
+func (g *AddGenerator) recordFeature() error {
+	manifest, err := LoadManifest(g.options.OutputPath)
+	if err != nil {
+		return err
+	}
+	command := "vibercode add " + g.options.Feature
+	if g.options.Name != "" {
+		command += " " + g.options.Name
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	if g.options.Feature != AddResource {
+		manifest.Features = append(manifest.Features, g.options.Feature)
+	}
+	manifest.History = append(manifest.History, VibercodeManifestEvent{
+		Type:        "add_" + g.options.Feature,
+		Description: "Added " + g.description(),
+		Timestamp:   now,
+		CLI:         VibercodeManifestCLI{Version: "1.0.0", Command: command},
+	})
+	manifest.UpdatedAt = now
+	return SaveManifest(g.options.OutputPath, manifest)
+}
+
+// usesGORM reports whether the project connects to a SQL database through GORM
+func (g *AddGenerator) usesGORM() bool {
+	return g.provider != "mongodb" && g.provider != "redis"
+}
+
+// generateAuth generates the accounts, tokens and authentication middleware of the project
+func (g *AddGenerator) generateAuth() error {
+	if !g.usesGORM() {
+		return fmt.Errorf("accounts are stored with GORM, %s projects are not supported (postgres, mysql, sqlite)", g.provider)
+	}
+	files := []struct {
+		template string
+		path     string
+	}{
+		{templates.AuthPackageTemplate, filepath.Join("internal", "auth", "auth.go")},
+		{templates.AuthHTTPTemplate, filepath.Join("internal", "auth", "http.go")},
+		{templates.AuthTestTemplate, filepath.Join("internal", "auth", "auth_test.go")},
+	}
+	for _, file := range files {
+		if err := g.files.generateGoFile(file.template, g.data, filepath.Join(g.options.OutputPath, file.path)); err != nil {
+			return err
+		}
+		ui.PrintFileCreated(file.path)
+	}
+	return nil
+}
+
+// wireAuth migrates the accounts and creates the auth service after the database connection,
+// and serves the account endpoints under /auth
+func (g *AddGenerator) wireAuth() error {
+	main, fn, err := g.mainFunc()
+	if err != nil {
+		return err
+	}
+	if findCall(fn.Body, "auth.NewService") < 0 {
+		db := connectedDB(fn.Body)
+		if db == "" {
+			return fmt.Errorf("%s does not connect to the database with database.Connect", main.path)
+		}
+		src := fmt.Sprintf(`if err := auth.Migrate(%[1]s); err != nil {
+	log.Fatal("Failed to migrate accounts:", err)
+}
+authService, err := auth.NewService(%[1]s, os.Getenv("JWT_SECRET"))
+if err != nil {
+	log.Fatal("Failed to set up authentication:", err)
+}`, db)
+		if err := main.insert(fn.Body, afterConnect(fn.Body), src); err != nil {
+			return err
+		}
+
+		router, health := healthRoute(fn.Body)
+		if router == "" {
+			return fmt.Errorf("%s has no /health route to register the account endpoints next to", main.path)
+		}
+		mount := withReceiver(g.data.HTTP.Mount("/auth", "authService.Handler()"), router)
+		if err := main.insert(fn.Body, health+1, mount); err != nil {
+			return err
+		}
+		for _, path := range []string{"log", "os", g.data.Module + "/internal/auth"} {
+			main.addImport(path)
+		}
+		g.addAdaptorImport(main)
+	}
+	if err := main.save(); err != nil {
+		return err
+	}
+
+	g.notes = append(g.notes,
+		"Set JWT_SECRET to at least 32 random bytes, e.g. openssl rand -base64 32",
+		"Accounts are served on POST /auth/register, POST /auth/login and GET /auth/me, protect routes with authService.Middleware(roles...)",
+		"Roles are granted in the roles column of the auth_users table, pass auth.Roles as the Roles of the admin panel",
+	)
+	return nil
+}
+
+// generateDocs generates the docs package serving the OpenAPI documents of the project
+func (g *AddGenerator) generateDocs() error {
+	documents, _ := filepath.Glob(filepath.Join(g.options.OutputPath, "docs", "openapi", "*.json"))
+	if len(documents) == 0 {
+		return fmt.Errorf("the project has no OpenAPI documents in docs/openapi, generate a resource first")
+	}
+	path := filepath.Join("docs", "docs.go")
+	if err := g.files.generateGoFile(templates.DocsPackageTemplate, g.data, filepath.Join(g.options.OutputPath, path)); err != nil {
+		return err
+	}
+	ui.PrintFileCreated(path)
+	return nil
+}
+
+// wireDocs mounts the documentation under /docs of the API routes
+func (g *AddGenerator) wireDocs() error {
+	routes, fn, err := g.setupRoutes()
+	if err != nil {
+		return err
+	}
+	if findCall(fn.Body, "docs.Handler") < 0 {
+		router, _ := routeParams(fn)
+		if err := routes.insert(fn.Body, -1, withReceiver(g.data.HTTP.Mount("/docs", "docs.Handler()"), router)); err != nil {
+			return err
+		}
+		routes.addImport(g.data.Module + "/docs")
+		g.addFrameworkImport(routes)
+		g.addAdaptorImport(routes)
+	}
+	if err := routes.save(); err != nil {
+		return err
+	}
+	g.notes = append(g.notes, "The API documentation is served on /api/v1/docs/")
+	return nil
+}
+
+// generateMiddleware generates a middleware preset, the presets use Gin
+func (g *AddGenerator) generateMiddleware() error {
+	if !g.data.HTTP.Is("gin") {
+		return fmt.Errorf("middleware presets use Gin, this project uses %s", g.data.HTTP.Framework.GetDisplayName())
+	}
+	if !models.IsValidPreset(g.options.Name) {
+		return fmt.Errorf("add middleware needs a preset, one of %s", strings.Join(middlewarePresets(), ", "))
+	}
+	return NewMiddlewareGenerator().Generate(MiddlewareOptions{Preset: g.options.Name, OutputPath: g.options.OutputPath})
+}
+
+// wireMiddleware creates the middleware manager and registers its middleware on the router
+func (g *AddGenerator) wireMiddleware() error {
+	main, fn, err := g.mainFunc()
+	if err != nil {
+		return err
+	}
+	if findCall(fn.Body, "middleware.NewManager") < 0 {
+		router, health := healthRoute(fn.Body)
+		if router == "" {
+			return fmt.Errorf("%s has no /health route to find the router", main.path)
+		}
+		index := lastCall(fn.Body, router+".Use") + 1
+		if index == 0 {
+			index = health
+		}
+		src := fmt.Sprintf(`middlewares := middleware.NewManager(config.DefaultMiddlewareConfig(), logrus.New())
+middlewares.RegisterMiddleware(%s)`, router)
+		if err := main.insert(fn.Body, index, src); err != nil {
+			return err
+		}
+		for _, path := range []string{g.data.Module + "/internal/middleware", g.data.Module + "/internal/config", "github.com/sirupsen/logrus"} {
+			main.addImport(path)
+		}
+	}
+	if err := main.save(); err != nil {
+		return err
+	}
+	g.notes = append(g.notes, "Tune the middleware in internal/config/middleware.go, protected routes are registered with middlewares.RegisterAuthMiddleware")
+	return nil
+}
+
+// generateObservability generates the observability package and its deployment files
+func (g *AddGenerator) generateObservability() error {
+	data := &EnhancedSchema{
+		ResourceSchema: &models.ResourceSchema{},
+		Module:         g.data.Module,
+		DBProvider:     g.provider,
+		HTTP:           g.data.HTTP,
+		DataLayer:      models.DataLayerGORM,
+	}
+	_, hooks, err := g.files.writeObservability(data, g.options.OutputPath)
+	if err != nil {
+		return err
+	}
+	for _, hook := range hooks {
+		// The hooks of the database connection of main.go are registered by wireObservability
+		if !strings.Contains(hook, "InstrumentGORM") && !strings.Contains(hook, "RedisHook") {
+			g.notes = append(g.notes, "Wire "+hook+" where the client is created")
+		}
+	}
+	ui.PrintFileCreated(filepath.Join("internal", "observability"))
+	return nil
+}
+
+// wireObservability sets up tracing after the database connection, instruments the
+// connection, registers the request middleware and serves the metrics on /metrics
+func (g *AddGenerator) wireObservability() error {
+	main, fn, err := g.mainFunc()
+	if err != nil {
+		return err
+	}
+	if findCall(fn.Body, "observability.Setup") >= 0 {
+		return main.save()
+	}
+
+	index := afterConnect(fn.Body)
+	src := fmt.Sprintf(`shutdown, err := observability.Setup(context.Background(), %q)
+if err != nil {
+	log.Fatal("Failed to set up observability:", err)
+}
+defer shutdown(context.Background())`, g.data.Title)
+	if db := connectedDB(fn.Body); db != "" {
+		switch {
+		case g.usesGORM():
+			src += fmt.Sprintf(`
+if err := observability.InstrumentGORM(%s); err != nil {
+	log.Fatal("Failed to instrument the database:", err)
+}`, db)
+		case g.provider == "redis":
+			src += fmt.Sprintf("\n%s.AddHook(observability.RedisHook{})", db)
+		}
+	}
+	if err := main.insert(fn.Body, index, src); err != nil {
+		return err
+	}
+
+	router, health := healthRoute(fn.Body)
+	if router == "" {
+		return fmt.Errorf("%s has no /health route to find the router", main.path)
+	}
+	// Metrics are served next to the health check, outside the API routes
+	metrics := (&HTTPRouteGroup{dialect: g.data.HTTP, receiver: router}).Handle("GET", "/metrics", "observability.MetricsHandler()")
+	if err := main.insert(fn.Body, health+1, metrics); err != nil {
+		return err
+	}
+	if err := g.registerObservabilityMiddleware(main, fn.Body, router); err != nil {
+		return err
+	}
+
+	for _, path := range []string{"context", "log", g.data.Module + "/internal/observability"} {
+		main.addImport(path)
+	}
+	g.addAdaptorImport(main)
+	if err := main.save(); err != nil {
+		return err
+	}
+
+	g.notes = append(g.notes,
+		"Send outbound requests with observability.NewHTTPClient or observability.NewTransport to propagate the trace",
+		"Start the collector, Jaeger, Prometheus and Grafana with docker compose -f docker-compose.yml -f docker-compose.observability.yml up",
+	)
+	return nil
+}
+
+// registerObservabilityMiddleware registers the request middleware after the middleware of
+// the router. On net/http the middleware wraps the mux of the API routes, whose patterns
+// name the routes.
+func (g *AddGenerator) registerObservabilityMiddleware(main *goFile, body *ast.BlockStmt, router string) error {
+	if g.data.HTTP.Is("stdlib") {
+		strip := callOf(body, "http.StripPrefix")
+		if strip == nil || len(strip.Args) != 2 {
+			g.notes = append(g.notes, "Wrap the mux of the API routes with observability.Middleware")
+			return nil
+		}
+		main.wrap(strip.Args[1], "observability.Middleware(", ")")
+		return nil
+	}
+
+	use := lastCall(body, router+".Use")
+	if use < 0 {
+		g.notes = append(g.notes, "Register "+observabilityMiddleware(g.data.HTTP)+" before the routes")
+		return nil
+	}
+	src := fmt.Sprintf("%s.Use(observability.Middleware())", router)
+	if g.data.HTTP.Is("chi") {
+		src = fmt.Sprintf("%s.Use(observability.Middleware)", router)
+	}
+	return main.insert(body, use+1, src)
+}
+
+// generateResource generates the resource of a schema
+func (g *AddGenerator) generateResource() error {
+	if g.options.Name == "" {
+		return fmt.Errorf("add resource needs the name of a schema, e.g. vibercode add resource Product")
+	}
+	if g.options.Schemas == nil {
+		return fmt.Errorf("no schema storage to load %s from", g.options.Name)
+	}
+	return NewSchemaGenerator(g.options.Schemas).
+		WithHTTPFramework(g.data.HTTP.Framework).
+		GenerateFromSchemaName(g.options.Name, g.options.OutputPath, g.data.Module, g.provider)
+}
+
+// wireResource creates the repository, service and handler of the resource in SetupRoutes
+// and registers its routes
+func (g *AddGenerator) wireResource() error {
+	schema, err := g.options.Schemas.LoadByName(g.options.Name)
+	if err != nil {
+		return err
+	}
+	names := schema.Names
+	if names.PascalCase == "" {
+		names = models.CreateResourceNames(schema.Name)
+	}
+
+	routes, fn, err := g.setupRoutes()
+	if err != nil {
+		return err
+	}
+	setup := "Setup" + names.PascalCase + "Routes"
+	if findCall(fn.Body, setup) >= 0 {
+		ui.PrintInfo(fmt.Sprintf("%s is already registered in SetupRoutes", setup))
+		return routes.save()
+	}
+
+	// The repository is only created in SetupRoutes when it is opened with its database
+	constructor := "New" + names.PascalCase + "Repository"
+	_, repository, err := g.parseFunc(filepath.Join("internal", "repositories", names.SnakeCase+"_repository.go"), constructor)
+	if err != nil {
+		return err
+	}
+	if want, got := paramType(repository, 0), paramType(fn, 1); want != got {
+		g.notes = append(g.notes, fmt.Sprintf("%s takes a %s, register %s in SetupRoutes with its repository", constructor, want, setup))
+		return nil
+	}
+
+	router, db := routeParams(fn)
+	src := fmt.Sprintf(`%[1]sRepo := repositories.New%[2]sRepository(%[4]s)
+%[1]sService := services.New%[2]sService(%[1]sRepo)
+%[1]sHandler := New%[2]sHandler(%[1]sService)
+%[5]s(%[3]s, %[1]sHandler)`, names.CamelCase, names.PascalCase, router, db, setup)
+	if err := routes.insert(fn.Body, -1, src); err != nil {
+		return err
+	}
+	routes.addImport(g.data.Module + "/internal/repositories")
+	routes.addImport(g.data.Module + "/internal/services")
+	return routes.save()
+}
+
+// generateWorker generates the background job queue and worker
+func (g *AddGenerator) generateWorker() error {
+	return NewWorkerGenerator().Generate(WorkerOptions{
+		OutputPath: g.options.OutputPath,
+		Module:     g.data.Module,
+		Database:   g.provider,
+		HTTP:       g.data.HTTP.Framework,
+	})
+}
+
+// wireWorker leaves the registration of the admin endpoints of the queues to the user, they
+// belong behind the authentication of the project. The worker runs as its own binary.
+func (g *AddGenerator) wireWorker() error {
+	return nil
+}
+
+// mainFunc parses cmd/server/main.go and returns its main function
+func (g *AddGenerator) mainFunc() (*goFile, *ast.FuncDecl, error) {
+	return g.parseFunc(filepath.Join("cmd", "server", "main.go"), "main")
+}
+
+// setupRoutes parses internal/handlers/routes.go and returns its SetupRoutes function
+func (g *AddGenerator) setupRoutes() (*goFile, *ast.FuncDecl, error) {
+	routes, fn, err := g.parseFunc(filepath.Join("internal", "handlers", "routes.go"), "SetupRoutes")
+	if err == nil && len(routeParamNames(fn)) < 2 {
+		err = fmt.Errorf("SetupRoutes of %s does not take the router and the database", routes.path)
+	}
+	return routes, fn, err
+}
+
+func (g *AddGenerator) parseFunc(path, name string) (*goFile, *ast.FuncDecl, error) {
+	file, err := parseGoFile(layoutPath(g.options.OutputPath, path))
+	if err != nil {
+		return nil, nil, err
+	}
+	fn := file.function(name)
+	if fn == nil {
+		return nil, nil, fmt.Errorf("%s has no %s function", path, name)
+	}
+	return file, fn, nil
+}
+
+// addFrameworkImport imports the framework package wrapping mounted handlers
+func (g *AddGenerator) addFrameworkImport(file *goFile) {
+	switch {
+	case g.data.HTTP.Is("gin"):
+		file.addImport("github.com/gin-gonic/gin")
+	case g.data.HTTP.Is("echo"):
+		file.addImport("github.com/labstack/echo/v4")
+	}
+}
+
+// addAdaptorImport imports the Fiber adaptor of net/http handlers
+func (g *AddGenerator) addAdaptorImport(file *goFile) {
+	if g.data.HTTP.Is("fiber") {
+		file.addImport("github.com/gofiber/fiber/v2/middleware/adaptor")
+	}
+}
+
+// routeParams returns the names of the router and database parameters of SetupRoutes
+func routeParams(fn *ast.FuncDecl) (string, string) {
+	names := routeParamNames(fn)
+	return names[0], names[1]
+}
+
+// paramType returns the type of the parameter at index of a function, empty when there is none
+func paramType(fn *ast.FuncDecl, index int) string {
+	for _, field := range fn.Type.Params.List {
+		names := len(field.Names)
+		if names == 0 {
+			names = 1
+		}
+		if index < names {
+			return types.ExprString(field.Type)
+		}
+		index -= names
+	}
+	return ""
+}
+
+func routeParamNames(fn *ast.FuncDecl) []string {
+	var names []string
+	for _, field := range fn.Type.Params.List {
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+	}
+	return names
+}
+
+// connectedDB returns the variable the database.Connect result is assigned to
+func connectedDB(body *ast.BlockStmt) string {
+	index := findCall(body, "database.Connect")
+	if index < 0 {
+		return ""
+	}
+	assign, ok := body.List[index].(*ast.AssignStmt)
+	if !ok || len(assign.Lhs) == 0 {
+		return ""
+	}
+	if ident, ok := assign.Lhs[0].(*ast.Ident); ok && ident.Name != "_" {
+		return ident.Name
+	}
+	return ""
+}
+
+// afterConnect returns the index of the statement following the database connection and the
+// check of its error, the start of the body without connection
+func afterConnect(body *ast.BlockStmt) int {
+	index := findCall(body, "database.Connect")
+	if index < 0 {
+		return 0
+	}
+	index++
+	if index < len(body.List) {
+		if _, ok := body.List[index].(*ast.IfStmt); ok {
+			index++
+		}
+	}
+	return index
+}
+
+// healthRoute returns the router the /health route is registered on and the index of the
+// statement registering it
+func healthRoute(body *ast.BlockStmt) (string, int) {
+	for i, stmt := range body.List {
+		if router := routeReceiver(stmt, "/health"); router != "" {
+			return router, i
+		}
+	}
+	return "", -1
+}
+
+// withReceiver replaces the r receiver of a registration statement of the HTTP dialect
+func withReceiver(stmt, receiver string) string {
+	return receiver + "." + strings.TrimPrefix(stmt, "r.")
+}
+
+// middlewarePresets lists the middleware presets
+func middlewarePresets() []string {
+	presets := []string{
+		string(models.APISecurityPreset),
+		string(models.WebAppPreset),
+		string(models.MicroservicePreset),
+		string(models.PublicAPIPreset),
+	}
+	sort.Strings(presets)
+	return presets
+}
@@ -0,0 +1,118 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vibercode/cli/internal/models"
+)
+
+// newAddTestProject generates an API project, with a database package, to add features to
+func newAddTestProject(t *testing.T, framework models.HTTPFramework, provider string) string {
+	dir := filepath.Join(t.TempDir(), "shop")
+	project := &APIProject{
+		Name:      dir,
+		Port:      "8080",
+		Database:  &models.DatabaseProvider{Type: provider},
+		Module:    "example.com/shop",
+		Framework: framework,
+	}
+	gen := NewAPIGenerator().WithHTTPFramework(framework)
+	require.NoError(t, gen.createProjectStructure(project))
+	for _, fn := range []func(*APIProject) error{gen.generateGoMod, gen.generateMain, gen.generateHandlers, gen.generateMiddleware, gen.generateManifest} {
+		require.NoError(t, fn(project))
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "pkg", "database"), 0755))
+	database := "package database\n\nimport \"gorm.io/gorm\"\n\nfunc Connect() (*gorm.DB, error) { return nil, nil }\n"
+	if provider == "mongodb" {
+		database = "package database\n\nimport \"go.mongodb.org/mongo-driver/mongo\"\n\nfunc Connect() (*mongo.Database, error) { return nil, nil }\n"
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pkg", "database", "database.go"), []byte(database), 0644))
+	return dir
+}
+
+func TestAddGenerator(t *testing.T) {
+	dir := newAddTestProject(t, models.HTTPStdlib, "postgres")
+	schemas := newMemorySchemaStorage(newTestProductSchema())
+	add := func(feature, name string) error {
+		return NewAddGenerator().Add(AddOptions{OutputPath: dir, Feature: feature, Name: name, Schemas: schemas})
+	}
+
+	assert.Error(t, add("cache", ""))
+	assert.Error(t, add(AddDocs, ""), "Docs serve the OpenAPI documents of the resources")
+	assert.Error(t, add(AddMiddleware, string(models.APISecurityPreset)), "Middleware presets use Gin")
+	assert.Error(t, NewAddGenerator().Add(AddOptions{OutputPath: t.TempDir(), Feature: AddDocs}), "Features are added to generated projects")
+
+	// The repository of the schema is opened with MongoDB, it is not created with the GORM connection
+	require.NoError(t, add(AddResource, "Product"))
+	assertGeneratedFiles(t, dir, generatedFile{path: "internal/handlers/product_handler.go"})
+	for _, feature := range []string{AddDocs, AddObservability, AddAuth} {
+		require.NoError(t, add(feature, ""))
+	}
+	assert.Error(t, add(AddObservability, ""), "Features are added once")
+
+	assertGeneratedFiles(t, dir,
+		generatedFile{
+			path: "internal/handlers/routes.go",
+			contains: []string{
+				"\tr.HandleFunc(\"GET /example\", example)\n\tr.Handle(\"/docs/\", docs.Handler())\n}",
+				`"example.com/shop/docs"`,
+				// Edits keep the comments
+				"// SetupRoutes sets up all API routes",
+			},
+			excludes: []string{"SetupProductRoutes(r, productHandler)"},
+		},
+		generatedFile{path: "docs/docs.go"},
+		generatedFile{
+			path: "cmd/server/main.go",
+			contains: []string{
+				"\tif err != nil {\n\t\tlog.Fatal(\"Failed to connect to database:\", err)\n\t}\n\tif err := auth.Migrate(db); err != nil {",
+				`authService, err := auth.NewService(db, os.Getenv("JWT_SECRET"))`,
+				"shutdown, err := observability.Setup(context.Background(), ",
+				"defer shutdown(context.Background())",
+				"if err := observability.InstrumentGORM(db); err != nil {",
+				`mux.Handle("/api/v1/", http.StripPrefix("/api/v1", observability.Middleware(api)))`,
+				`mux.Handle("GET /metrics", observability.MetricsHandler())`,
+				`mux.Handle("/auth/", authService.Handler())`,
+				`"example.com/shop/internal/observability"`,
+				"// Health check endpoint",
+			},
+		},
+		generatedFile{
+			path:     "go.mod",
+			contains: []string{"go.opentelemetry.io/otel v1.28.0", "github.com/golang-jwt/jwt/v5 v5.2.1"},
+		},
+	)
+	assertGoFilesParse(t, dir)
+
+	manifest, err := LoadManifest(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{AddDocs, AddObservability, AddAuth}, manifest.Features)
+	last := manifest.History[len(manifest.History)-1]
+	assert.Equal(t, "add_auth", last.Type)
+	assert.Equal(t, "vibercode add auth", last.CLI.Command)
+}
+
+func TestAddGenerator_Resource(t *testing.T) {
+	dir := newAddTestProject(t, models.HTTPGin, "mongodb")
+	schemas := newMemorySchemaStorage(newTestProductSchema())
+	for i := 0; i < 2; i++ {
+		require.NoError(t, NewAddGenerator().Add(AddOptions{OutputPath: dir, Feature: AddResource, Name: "Product", Schemas: schemas}))
+	}
+	assert.Error(t, NewAddGenerator().Add(AddOptions{OutputPath: dir, Feature: AddAuth}), "Accounts are stored with GORM")
+
+	routes := readGeneratedFile(t, dir, "internal/handlers/routes.go")
+	assert.Contains(t, routes, "\tproductRepo := repositories.NewProductRepository(db)\n\tproductService := services.NewProductService(productRepo)\n\tproductHandler := NewProductHandler(productService)\n\tSetupProductRoutes(r, productHandler)\n}")
+	assert.Equal(t, 1, strings.Count(routes, "SetupProductRoutes("), "Resources are registered once")
+	assert.Contains(t, routes, `"example.com/shop/internal/services"`)
+	assertGoFilesParse(t, dir)
+
+	manifest, err := LoadManifest(dir)
+	require.NoError(t, err)
+	assert.Empty(t, manifest.Features, "Resources are recorded in the history only")
+	assert.Equal(t, "add_resource", manifest.History[len(manifest.History)-1].Type)
+}
@@ -0,0 +1,533 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vibercode/cli/internal/models"
+	"github.com/vibercode/cli/internal/templates"
+	"github.com/vibercode/cli/pkg/ui"
+)
+
+// AdminOptions contains configuration for admin panel generation. Empty options are read from
+// the .vibercode/manifest.vibe of the project.
+type AdminOptions struct {
+	OutputPath string
+	Module     string
+	HTTP       models.HTTPFramework
+}
+
+// AdminGenerator generates the server-rendered admin panel of the resources of a project
+type AdminGenerator struct {
+	options AdminOptions
+	files   *SchemaGenerator
+}
+
+// AdminTemplateData holds the data of the admin panel templates
+type AdminTemplateData struct {
+	Title     string
+	Module    string
+	HTTP      *HTTPDialect
+	Resources []*AdminResource
+}
+
+// AdminResource holds the template data of the pages of a resource
+type AdminResource struct {
+	Module      string
+	Names       *models.NamingConventions
+	Path        string
+	Label       string
+	PluralLabel string
+	Group       string
+	Display     string
+	Columns     []string
+	Actions     []string // Constants of the row actions
+	Sortable    bool
+	Searchable  bool
+	Roles       []AdminRoles
+	Fields      []AdminField
+}
+
+// AdminRoles lists the roles allowed to perform an action
+type AdminRoles struct {
+	Action string // Constant of the action
+	Roles  []string
+}
+
+// AdminField holds how a field is displayed and edited
+type AdminField struct {
+	Name        string
+	Label       string
+	Type        string
+	Widget      string
+	Group       string
+	Width       string
+	Placeholder string
+	Help        string
+	Hidden      bool
+	ReadOnly    bool
+	Required    bool
+	Options     []string
+	Relation    string
+	ForeignKey  string
+}
+
+// adminPicker is the resource a foreign key is picked from and the label of its relation
+type adminPicker struct {
+	path  string
+	label string
+}
+
+// adminActions maps the admin actions of schemas onto the constants of the admin package
+var adminActions = map[string]string{
+	models.AdminActionView:   "ActionView",
+	models.AdminActionCreate: "ActionCreate",
+	models.AdminActionEdit:   "ActionEdit",
+	models.AdminActionDelete: "ActionDelete",
+}
+
+// NewAdminGenerator creates a new admin panel generator
+func NewAdminGenerator() *AdminGenerator {
+	return &AdminGenerator{files: NewSchemaGenerator(nil)}
+}
+
+// Generate generates the admin panel of the project in the output path, with pages for
+// every resource recorded in its API versions
+func (g *AdminGenerator) Generate(options AdminOptions) error {
+	g.options = options
+	if g.options.OutputPath == "" {
+		g.options.OutputPath = "."
+	}
+	outputPath := g.options.OutputPath
+
+	ui.PrintStep(1, 3, "Reading project schemas...")
+	manifest, err := LoadManifest(outputPath)
+	if err != nil {
+		return fmt.Errorf("the admin panel is generated from the schemas of the project, run the command in a project: %w", err)
+	}
+	if !manifest.Layout.Layered() {
+		return fmt.Errorf("the admin panel imports the services and is mounted by the handlers, which the %s layout merges", manifest.Layout)
+	}
+	schemas, err := projectSchemas(outputPath, manifest)
+	if err != nil {
+		return err
+	}
+	if len(schemas) == 0 {
+		return fmt.Errorf("the project has no resources, generate them with 'vibercode schema generate' first")
+	}
+	data, err := g.templateData(manifest, schemas)
+	if err != nil {
+		return err
+	}
+
+	ui.PrintStep(2, 3, "Generating admin pages...")
+	type file struct {
+		template string
+		data     interface{}
+		path     string
+	}
+	files := []file{
+		{templates.AdminPackageTemplate, data, filepath.Join("internal", "admin", "admin.go")},
+		{templates.AdminResourceTemplate, data, filepath.Join("internal", "admin", "resource.go")},
+		{templates.AdminPanelTemplate, data, filepath.Join("internal", "admin", "panel.go")},
+		{templates.AdminTestTemplate, data, filepath.Join("internal", "admin", "admin_test.go")},
+	}
+	for _, resource := range data.Resources {
+		files = append(files, file{templates.AdminSchemaTemplate, resource, filepath.Join("internal", "admin", resource.Names.SnakeCase+".go")})
+	}
+	// Forms report the validation errors of the services through the domain errors
+	for _, problemFile := range problemFiles {
+		files = append(files, file{problemFile.template, data, problemFile.path})
+	}
+	for _, file := range files {
+		if err := g.files.generateGoFile(file.template, file.data, filepath.Join(outputPath, file.path)); err != nil {
+			return err
+		}
+		ui.PrintFileCreated(file.path)
+	}
+
+	routesPath := filepath.Join("internal", "handlers", "admin_routes.go")
+	if err := g.files.generateHandlerFile(templates.AdminRoutesTemplate, data, filepath.Join(outputPath, routesPath)); err != nil {
+		return err
+	}
+	ui.PrintFileCreated(routesPath)
+
+	ui.PrintStep(3, 3, "Writing templates and assets...")
+	// The pages are rendered with html/template and embedded in the binary, no Node
+	// toolchain builds them
+	assets := []struct{ path, content string }{
+		{filepath.Join("templates", "layout.html"), templates.AdminLayoutHTML},
+		{filepath.Join("templates", "index.html"), templates.AdminIndexHTML},
+		{filepath.Join("templates", "list.html"), templates.AdminListHTML},
+		{filepath.Join("templates", "detail.html"), templates.AdminDetailHTML},
+		{filepath.Join("templates", "form.html"), templates.AdminFormHTML},
+		{filepath.Join("templates", "error.html"), templates.AdminErrorHTML},
+		{filepath.Join("static", "admin.css"), templates.AdminCSS},
+	}
+	for _, asset := range assets {
+		path := filepath.Join("internal", "admin", asset.path)
+		if err := g.files.writeGeneratedFile(filepath.Join(outputPath, path), []byte(asset.content)); err != nil {
+			return err
+		}
+		ui.PrintFileCreated(path)
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	manifest.History = append(manifest.History, VibercodeManifestEvent{
+		Type:        "generate_admin",
+		Description: fmt.Sprintf("Generated the admin panel of %d resources", len(data.Resources)),
+		Timestamp:   now,
+		CLI:         VibercodeManifestCLI{Version: "1.0.0", Command: "vibercode generate admin"},
+	})
+	manifest.UpdatedAt = now
+	if err := SaveManifest(outputPath, manifest); err != nil {
+		return err
+	}
+
+	ui.PrintSuccess("Admin panel generated successfully!")
+	ui.PrintInfo("Create the panel with admin.NewPanel(admin.Services{...}) from the services of the resources and mount it with handlers.SetupAdminRoutes on the root router")
+	ui.PrintInfo("Serve the panel behind authentication, the roles of the actions are read from the \"roles\" value of the request context")
+	return nil
+}
+
+// templateData resolves the module and HTTP framework of the project and the pages of its resources
+func (g *AdminGenerator) templateData(manifest *VibercodeManifest, schemas []*models.ResourceSchema) (*AdminTemplateData, error) {
+	name := manifest.Name
+	if name == "" {
+		name = filepath.Base(absPath(g.options.OutputPath))
+	}
+	data := &AdminTemplateData{
+		Title:  strings.Title(strings.ReplaceAll(name, "-", " ")) + " admin",
+		Module: g.options.Module,
+	}
+	if data.Module == "" {
+		data.Module = manifest.Module
+	}
+	if data.Module == "" {
+		data.Module = readGoModule(g.options.OutputPath)
+	}
+	if data.Module == "" {
+		return nil, fmt.Errorf("could not find the Go module of %s, pass --module", g.options.OutputPath)
+	}
+	framework := g.options.HTTP
+	if framework == "" {
+		framework = manifest.HTTPFramework
+	}
+	data.HTTP = newHTTPDialect(framework)
+
+	paths := make(map[string]string, len(schemas))
+	for _, schema := range schemas {
+		paths[schema.Name] = schema.Names.KebabPlural
+	}
+	for _, schema := range schemas {
+		resource, err := newAdminResource(schema, data.Module, paths)
+		if err != nil {
+			return nil, err
+		}
+		data.Resources = append(data.Resources, resource)
+	}
+	return data, nil
+}
+
+// projectSchemas loads the schemas of the resources of a project from the snapshots of its
+// API versions, the latest version of each resource
+func projectSchemas(outputPath string, manifest *VibercodeManifest) ([]*models.ResourceSchema, error) {
+	var schemas []*models.ResourceSchema
+	index := make(map[string]int)
+	for _, version := range manifest.APIVersions {
+		for _, resource := range version.Resources {
+			content, err := os.ReadFile(filepath.Join(outputPath, ".vibercode", filepath.FromSlash(resource.Snapshot)))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read the schema of %s: %w", resource.Name, err)
+			}
+			schema, err := models.FromJSON(content)
+			if err != nil {
+				return nil, fmt.Errorf("invalid schema snapshot %s: %w", resource.Snapshot, err)
+			}
+			if schema.Names == nil {
+				schema.Names = models.CreateResourceNames(schema.Name)
+			}
+			if i, ok := index[resource.Name]; ok {
+				schemas[i] = schema
+				continue
+			}
+			index[resource.Name] = len(schemas)
+			schemas = append(schemas, schema)
+		}
+	}
+	return schemas, nil
+}
+
+// newAdminResource describes the pages of a schema from the UI configuration of its fields,
+// the table configuration of its frontend and its admin configuration
+func newAdminResource(schema *models.ResourceSchema, module string, paths map[string]string) (*AdminResource, error) {
+	resource := &AdminResource{
+		Module:      module,
+		Names:       schema.Names,
+		Path:        schema.Names.KebabPlural,
+		Label:       schema.DisplayName,
+		PluralLabel: strings.Title(strings.ReplaceAll(schema.Names.SnakePlural, "_", " ")),
+		Group:       schema.GetAdminConfig().Group,
+		Sortable:    true,
+		Searchable:  true,
+	}
+	if resource.Label == "" {
+		resource.Label = strings.Title(strings.ReplaceAll(schema.Names.SnakeCase, "_", " "))
+	}
+
+	// Foreign keys of many-to-one relations are edited with a picker of the target
+	pickers := make(map[string]adminPicker)
+	for _, field := range schema.Fields {
+		if field.Type == "relation" && field.Relation != nil && field.Relation.ForeignKey != "" {
+			if path, ok := paths[field.Relation.Target]; ok {
+				label := field.DisplayName
+				if label == "" {
+					label = strings.Title(strings.ReplaceAll(toSnakeCase(field.Name), "_", " "))
+				}
+				pickers[toSnakeCase(field.Relation.ForeignKey)] = adminPicker{path: path, label: label}
+			}
+		}
+	}
+
+	for i := range schema.Fields {
+		resource.Fields = append(resource.Fields, newAdminField(&schema.Fields[i], pickers, paths))
+	}
+	sort.SliceStable(resource.Fields, func(i, j int) bool {
+		return adminFieldOrder(schema, resource.Fields[i].Name) < adminFieldOrder(schema, resource.Fields[j].Name)
+	})
+
+	resource.Display = "id"
+	for _, name := range []string{"name", "title", "label", "email", "username", "slug"} {
+		if field := adminFieldNamed(resource.Fields, name); field != nil && field.Type != "password" {
+			resource.Display = name
+			break
+		}
+	}
+	if resource.Display == "id" {
+		for _, field := range resource.Fields {
+			if field.listable() && field.Widget == "text" {
+				resource.Display = field.Name
+				break
+			}
+		}
+	}
+
+	// Tables list the configured columns, or the first fields short enough for a cell
+	var table *models.TableConfig
+	if schema.Frontend != nil {
+		table = schema.Frontend.Tables
+	}
+	if table != nil {
+		for _, name := range table.Columns {
+			if field := adminFieldNamed(resource.Fields, toSnakeCase(name)); field != nil && field.listable() {
+				resource.Columns = append(resource.Columns, field.Name)
+			}
+		}
+		for _, action := range table.Actions {
+			if constant, ok := adminActions[action]; ok && action != models.AdminActionCreate {
+				resource.Actions = append(resource.Actions, constant)
+			}
+		}
+		if len(table.Features) > 0 {
+			resource.Sortable = hasValue(table.Features, "sorting")
+			resource.Searchable = hasValue(table.Features, "filtering")
+		}
+	}
+	if len(resource.Columns) == 0 {
+		for _, field := range resource.Fields {
+			if field.listable() && field.Widget != "textarea" && len(resource.Columns) < 5 {
+				resource.Columns = append(resource.Columns, field.Name)
+			}
+		}
+	}
+	if len(resource.Actions) == 0 {
+		resource.Actions = []string{"ActionView", "ActionEdit", "ActionDelete"}
+	}
+
+	roles := schema.GetAdminConfig().Roles
+	actions := make([]string, 0, len(roles))
+	for action := range roles {
+		if _, ok := adminActions[action]; !ok {
+			return nil, fmt.Errorf("unknown admin action %q of %s, expected view, create, edit or delete", action, schema.Name)
+		}
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	for _, action := range actions {
+		resource.Roles = append(resource.Roles, AdminRoles{Action: adminActions[action], Roles: roles[action]})
+	}
+	return resource, nil
+}
+
+// newAdminField describes a field from its UI configuration
+func newAdminField(field *models.SchemaField, pickers map[string]adminPicker, paths map[string]string) AdminField {
+	name := toSnakeCase(field.Name)
+	admin := AdminField{
+		Name:     name,
+		Label:    field.DisplayName,
+		Type:     field.Type,
+		Widget:   adminWidget(field),
+		Required: field.Required,
+		ReadOnly: field.IsUpload(),
+	}
+	if admin.Label == "" {
+		admin.Label = strings.Title(strings.ReplaceAll(name, "_", " "))
+	}
+	if field.Validation != nil && !field.IsMoney() {
+		admin.Options = field.Validation.AllowedValues
+	}
+
+	if ui := field.UI; ui != nil {
+		if ui.Label != "" {
+			admin.Label = ui.Label
+		}
+		admin.Placeholder = ui.Placeholder
+		admin.Help = ui.HelpText
+		admin.Group = ui.Group
+		admin.Hidden = ui.Hidden
+		admin.ReadOnly = admin.ReadOnly || ui.ReadOnly
+		// Fields span the whole form row unless they are narrower
+		switch ui.Width {
+		case "half", "third", "quarter":
+			admin.Width = ui.Width
+		}
+	}
+
+	if picker, ok := pickers[name]; ok {
+		admin.Relation = picker.path
+		admin.Widget = "select"
+		if field.UI == nil || field.UI.Label == "" {
+			admin.Label = picker.label
+		}
+	}
+	// Records of one-to-many relations are listed by the table of the target
+	if field.Type == "relation_array" && field.Relation != nil && field.Relation.Type == "one_to_many" && field.Relation.ForeignKey != "" {
+		if path, ok := paths[field.Relation.Target]; ok {
+			admin.Relation = path
+			admin.ForeignKey = toSnakeCase(field.Relation.ForeignKey)
+		}
+	}
+	return admin
+}
+
+// adminWidget returns the form control of a field, the component of its UI configuration
+// when the admin panel has one
+func adminWidget(field *models.SchemaField) string {
+	if field.UI != nil {
+		switch field.UI.Component {
+		case "textarea", "select", "checkbox":
+			return field.UI.Component
+		case "datepicker":
+			if field.Type == "date" {
+				return "date"
+			}
+			return "datetime-local"
+		}
+	}
+	if field.Validation != nil && len(field.Validation.AllowedValues) > 0 && !field.IsMoney() {
+		return "select"
+	}
+
+	switch field.Type {
+	case "relation", "relation_array":
+		return ""
+	case "text", "json", "mixed":
+		return "textarea"
+	case "boolean":
+		return "checkbox"
+	case "number", "integer", "float", "decimal":
+		return "number"
+	case "date":
+		return "date"
+	case "datetime", "timestamp":
+		return "datetime-local"
+	case "email", "url", "color", "password":
+		return field.Type
+	default:
+		return "text"
+	}
+}
+
+// adminFieldOrder returns the position of a field in the pages, the order of its UI
+// configuration and then its position in the schema
+func adminFieldOrder(schema *models.ResourceSchema, name string) int {
+	for _, field := range schema.Fields {
+		if toSnakeCase(field.Name) == name && field.UI != nil {
+			return field.UI.Order
+		}
+	}
+	return 0
+}
+
+// adminFieldNamed returns the field with the given name, nil when there is none
+func adminFieldNamed(fields []AdminField, name string) *AdminField {
+	for i := range fields {
+		if fields[i].Name == name {
+			return &fields[i]
+		}
+	}
+	return nil
+}
+
+// listable reports whether the field can be a column of the table
+func (f *AdminField) listable() bool {
+	switch f.Type {
+	case "relation", "relation_array", "password", "json", "mixed":
+		return false
+	}
+	return !f.Hidden
+}
+
+// Literal returns the field as a composite literal of the admin package
+func (f AdminField) Literal() string {
+	parts := []string{
+		"Name: " + strconv.Quote(f.Name),
+		"Label: " + strconv.Quote(f.Label),
+		"Type: " + strconv.Quote(f.Type),
+	}
+	optional := []struct{ name, value string }{
+		{"Widget", f.Widget},
+		{"Group", f.Group},
+		{"Width", f.Width},
+		{"Placeholder", f.Placeholder},
+		{"Help", f.Help},
+		{"Relation", f.Relation},
+		{"ForeignKey", f.ForeignKey},
+	}
+	for _, field := range optional {
+		if field.value != "" {
+			parts = append(parts, field.name+": "+strconv.Quote(field.value))
+		}
+	}
+	for _, flag := range []struct {
+		name string
+		set  bool
+	}{{"Hidden", f.Hidden}, {"ReadOnly", f.ReadOnly}, {"Required", f.Required}} {
+		if flag.set {
+			parts = append(parts, flag.name+": true")
+		}
+	}
+	if len(f.Options) > 0 {
+		options := make([]string, len(f.Options))
+		for i, option := range f.Options {
+			options[i] = strconv.Quote(option)
+		}
+		parts = append(parts, "Options: []string{"+strings.Join(options, ", ")+"}")
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+// hasValue reports whether values contains value
+func hasValue(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
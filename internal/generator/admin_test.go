@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vibercode/cli/internal/models"
+)
+
+func TestAdminGenerator(t *testing.T) {
+	assert.ErrorContains(t, NewAdminGenerator().Generate(AdminOptions{OutputPath: t.TempDir()}), "run the command in a project")
+
+	category := &models.ResourceSchema{
+		ID: "category-1", Name: "Category", DisplayName: "Category", Names: models.CreateResourceNames("Category"),
+		Fields: []models.SchemaField{
+			{Name: "title", Type: "string", DisplayName: "Title", Required: true},
+			{Name: "products", Type: "relation_array", DisplayName: "Products", Relation: &models.RelationConfig{Type: "one_to_many", Target: "Product", ForeignKey: "category_id"}},
+		},
+		Database: &models.DatabaseConfig{Provider: "postgres", TableName: "categories"},
+	}
+	product := newTestProductSchema()
+	product.Fields[2].UI = &models.FieldUI{Label: "Details", Width: "half", Group: "Content", Order: 1}
+	product.Fields = append(product.Fields,
+		models.SchemaField{Name: "category_id", Type: "string", DisplayName: "Category ID"},
+		models.SchemaField{Name: "category", Type: "relation", DisplayName: "Category", Relation: &models.RelationConfig{Type: "many_to_one", Target: "Category", ForeignKey: "category_id"}},
+	)
+	product.Frontend = &models.FrontendConfig{Tables: &models.TableConfig{Columns: []string{"name", "stock", "category_id"}, Actions: []string{"view", "edit"}, Features: []string{"sorting"}}}
+	product.Options = &models.GenerationOptions{Admin: &models.AdminConfig{Group: "Catalog", Roles: map[string][]string{"delete": {"editor"}}}}
+	gen := NewSchemaGenerator(newMemorySchemaStorage(category, product)).WithHTTPFramework(models.HTTPChi)
+	dir := generateTestProject(t, gen, "postgres", category, product)
+	require.NoError(t, NewAdminGenerator().Generate(AdminOptions{OutputPath: dir, HTTP: models.HTTPChi}))
+
+	assertGeneratedFiles(t, dir,
+		generatedFile{path: "internal/admin/admin.go"},
+		generatedFile{path: "internal/admin/resource.go"},
+		generatedFile{path: "internal/admin/panel.go", contains: []string{"Product  services.ProductServiceInterface"}},
+		generatedFile{path: "internal/admin/admin_test.go"},
+		generatedFile{
+			path: "internal/admin/product.go",
+			contains: []string{
+				`Group:       "Catalog",`,
+				`Columns:     []string{"name", "stock", "category_id"},`,
+				`Actions:     []string{ActionView, ActionEdit},`,
+				"Searchable:  false,",
+				`ActionDelete: {"editor"},`,
+				`{Name: "category_id", Label: "Category", Type: "string", Widget: "select", Relation: "categories"},`,
+				"ServiceStore[models.Product, models.ProductRequest, models.ProductFilter](service)",
+			},
+		},
+		generatedFile{
+			path:     "internal/admin/category.go",
+			contains: []string{`Display:     "title",`, `Relation: "products", ForeignKey: "category_id"}`},
+		},
+		generatedFile{path: "internal/admin/templates/list.html"},
+		generatedFile{path: "internal/admin/templates/form.html"},
+		generatedFile{path: "internal/admin/static/admin.css"},
+		generatedFile{path: "internal/handlers/admin_routes.go", contains: []string{`r.Mount("/admin", panel)`}},
+	)
+	assert.Regexp(t, `(?s)"active".*"description", Label: "Details", Type: "text", Widget: "textarea", Group: "Content", Width: "half"`,
+		readGeneratedFile(t, dir, "internal/admin/product.go"), "Fields follow the order of their UI configuration")
+
+	manifest, err := LoadManifest(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "generate_admin", manifest.History[len(manifest.History)-1].Type)
+
+	assertGoFilesParse(t, filepath.Join(dir, "internal"))
+
+	product.Options.Admin.Roles = map[string][]string{"publish": {"editor"}}
+	require.NoError(t, gen.GenerateFromSchema(product.ID, dir, "example.com/shop", "postgres"))
+	assert.ErrorContains(t, NewAdminGenerator().Generate(AdminOptions{OutputPath: dir}), `unknown admin action "publish"`)
+}
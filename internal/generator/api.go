@@ -1,6 +1,7 @@
 package generator
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -15,19 +16,42 @@ import (
 )
 
 // APIGenerator handles API project generation
-type APIGenerator struct{}
+type APIGenerator struct {
+	framework models.HTTPFramework
+	layout    models.ProjectLayout
+}
 
 // NewAPIGenerator creates a new APIGenerator
 func NewAPIGenerator() *APIGenerator {
 	return &APIGenerator{}
 }
 
+// WithHTTPFramework selects the HTTP framework of the generated project instead of asking for it
+func (g *APIGenerator) WithHTTPFramework(framework models.HTTPFramework) *APIGenerator {
+	g.framework = framework
+	return g
+}
+
+// WithLayout selects the layout preset of the generated project instead of asking for it
+func (g *APIGenerator) WithLayout(layout models.ProjectLayout) *APIGenerator {
+	g.layout = layout
+	return g
+}
+
 // APIProject represents an API project configuration
 type APIProject struct {
 	Name     string
 	Port     string
 	Database *models.DatabaseProvider
 	Module   string
+
+	Framework models.HTTPFramework
+	Layout    models.ProjectLayout
+}
+
+// HTTP returns the dialect rendering the framework specific code of the project
+func (p *APIProject) HTTP() *HTTPDialect {
+	return newHTTPDialect(p.Framework)
 }
 
 // VibercodeManifest represents the project configuration that can be used to regenerate the project
@@ -43,6 +67,11 @@ type VibercodeManifest struct {
 	CLI         VibercodeManifestCLI      `json:"cli"`
 	History     []VibercodeManifestEvent  `json:"history,omitempty"`
 	Resources   []VibercodeResource       `json:"resources,omitempty"`
+
+	HTTPFramework models.HTTPFramework  `json:"http_framework,omitempty"`
+	Layout        models.ProjectLayout  `json:"layout,omitempty"` // Layout preset, kept by later generations
+	APIVersions   []VibercodeAPIVersion `json:"api_versions,omitempty"`
+	Features      []string              `json:"features,omitempty"` // Features added with vibercode add
 }
 
 // VibercodeManifestCLI represents CLI-specific information
@@ -67,6 +96,30 @@ type VibercodeResource struct {
 	GeneratedAt string                   `json:"generated_at"`
 }
 
+// VibercodeAPIVersion represents an API version and the schemas its resources were generated from
+type VibercodeAPIVersion struct {
+	Version    string                        `json:"version"`              // "v1", "v2", ...
+	Deprecated string                        `json:"deprecated,omitempty"` // RFC 3339 time the version was deprecated
+	Sunset     string                        `json:"sunset,omitempty"`     // Date the version stops being served, YYYY-MM-DD
+	Resources  []VibercodeAPIVersionResource `json:"resources"`
+}
+
+// VibercodeAPIVersionResource records the schema a resource of an API version was generated from
+type VibercodeAPIVersionResource struct {
+	Name          string `json:"name"`
+	SchemaID      string `json:"schema_id"`
+	SchemaVersion string `json:"schema_version,omitempty"`
+	// SchemaUpdatedAt is the last change of the schema, which identifies schemas without a version
+	SchemaUpdatedAt string `json:"schema_updated_at,omitempty"`
+	Snapshot        string `json:"snapshot"` // Copy of the schema, relative to .vibercode
+	GeneratedAt     string `json:"generated_at"`
+	// Endpoints, Relations and Includes list the feature endpoints, the relation fields with
+	// nested routes and the relations ?include expands, as generated for the resource
+	Endpoints []string `json:"endpoints,omitempty"`
+	Relations []string `json:"relations,omitempty"`
+	Includes  []string `json:"includes,omitempty"`
+}
+
 // VibercodeResourceField represents a field in a resource
 type VibercodeResourceField struct {
 	Name     string `json:"name"`
@@ -88,6 +141,8 @@ func (g *APIGenerator) Generate() error {
 	ui.PrintFeature(ui.IconAPI, "Project Name", project.Name)
 	ui.PrintFeature(ui.IconGear, "Port", project.Port)
 	ui.PrintFeature(ui.IconDatabase, "Database", project.Database.GetDisplayName())
+	ui.PrintFeature(ui.IconAPI, "HTTP Framework", project.Framework.GetDisplayName())
+	ui.PrintFeature(ui.IconPackage, "Layout", project.Layout.GetDisplayName())
 	ui.PrintFeature(ui.IconPackage, "Module", project.Module)
 	fmt.Println()
 
@@ -124,6 +179,7 @@ func (g *APIGenerator) Generate() error {
 		{"main.go", g.generateMain},
 		{"database package", g.generateDatabase},
 		{"handlers", g.generateHandlers},
+		{"middleware", g.generateMiddleware},
 		{"Dockerfile", g.generateDockerfile},
 		{".env.example", g.generateEnvExample},
 		{"docker-compose.yml", g.generateDockerCompose},
@@ -158,7 +214,19 @@ func (g *APIGenerator) Generate() error {
 	ui.PrintSuccess(fmt.Sprintf("API project '%s' generated successfully!", project.Name))
 	
 	// Show project structure
-	ui.PrintProjectStructure(project.Name)
+	if project.Layout.OrDefault() == models.LayoutStandard {
+		ui.PrintProjectStructure(project.Name)
+	} else {
+		ui.PrintInfo(fmt.Sprintf("%s layout: models in %s, repositories in %s, services in %s and handlers in %s",
+			project.Layout.GetDisplayName(),
+			project.Layout.Dir(models.PackageModels),
+			project.Layout.Dir(models.PackageRepositories),
+			project.Layout.Dir(models.PackageServices),
+			project.Layout.Dir(models.PackageHandlers)))
+		if dir := project.Layout.PortsDir(); dir != "" {
+			ui.PrintInfo(fmt.Sprintf("Repository and service interfaces in %s", dir))
+		}
+	}
 	
 	// Show database info
 	ui.PrintDatabaseInfo(project.Database.Type, project.Name)
@@ -237,6 +305,26 @@ func (g *APIGenerator) collectProjectInfo() (*APIProject, error) {
 	}
 	project.Module = strings.TrimSpace(module)
 
+	// HTTP framework
+	project.Framework = g.framework
+	if project.Framework == "" {
+		framework, err := ui.SelectOption(ui.IconAPI+" HTTP framework:", models.SupportedHTTPFrameworks())
+		if err != nil {
+			return nil, err
+		}
+		project.Framework = models.HTTPFramework(framework)
+	}
+
+	// Layout preset
+	project.Layout = g.layout
+	if project.Layout == "" {
+		layout, err := ui.SelectOption(ui.IconPackage+" Project layout:", models.SupportedProjectLayouts())
+		if err != nil {
+			return nil, err
+		}
+		project.Layout = models.ProjectLayout(layout)
+	}
+
 	return project, nil
 }
 
@@ -260,16 +348,20 @@ func getDefaultPort(dbType string) int {
 func (g *APIGenerator) createProjectStructure(project *APIProject) error {
 	dirs := []string{
 		filepath.Join(project.Name, "cmd", "server"),
-		filepath.Join(project.Name, "internal", "handlers"),
-		filepath.Join(project.Name, "internal", "services"),
-		filepath.Join(project.Name, "internal", "repositories"),
-		filepath.Join(project.Name, "internal", "models"),
+		filepath.Join(project.Name, "internal", "apperrors"),
+		filepath.Join(project.Name, filepath.FromSlash(project.Layout.Dir(models.PackageHandlers))),
+		filepath.Join(project.Name, filepath.FromSlash(project.Layout.Dir(models.PackageServices))),
+		filepath.Join(project.Name, filepath.FromSlash(project.Layout.Dir(models.PackageRepositories))),
+		filepath.Join(project.Name, filepath.FromSlash(project.Layout.Dir(models.PackageModels))),
 		filepath.Join(project.Name, "internal", "middleware"),
 		filepath.Join(project.Name, "pkg", "database"),
 		filepath.Join(project.Name, "pkg", "config"),
 		filepath.Join(project.Name, "pkg", "utils"),
 		filepath.Join(project.Name, "docs"),
 	}
+	if dir := project.Layout.PortsDir(); dir != "" {
+		dirs = append(dirs, filepath.Join(project.Name, filepath.FromSlash(dir)))
+	}
 
 	for _, dir := range dirs {
 		if err := os.MkdirAll(dir, 0755); err != nil {
@@ -284,10 +376,15 @@ func (g *APIGenerator) createProjectStructure(project *APIProject) error {
 func (g *APIGenerator) generateGoMod(project *APIProject) error {
 	template := `module {{.Module}}
 
-go 1.21
+go {{.Framework.GoVersion}}
 
 require (
-	github.com/gin-gonic/gin v1.9.1
+{{- if .Framework.ModulePath}}
+	{{.Framework.ModulePath}} {{.Framework.ModuleVersion}}
+{{- end}}
+{{- if not .HTTP.Validates}}
+	github.com/go-playground/validator/v10 v10.14.0
+{{- end}}
 	github.com/joho/godotenv v1.4.0
 {{- if eq .Database.Type "mongodb"}}
 	go.mongodb.org/mongo-driver v1.13.1
@@ -315,62 +412,7 @@ require (
 
 // generateMain generates the main.go file
 func (g *APIGenerator) generateMain(project *APIProject) error {
-	template := `package main
-
-import (
-	"log"
-	"net/http"
-	"os"
-
-	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
-	"{{.Module}}/internal/handlers"
-	"{{.Module}}/pkg/database"{{- if eq .Database.Type "mongodb"}}
-	_ "go.mongodb.org/mongo-driver/mongo"{{- else if eq .Database.Type "redis"}}
-	_ "github.com/go-redis/redis/v8"{{- end}}
-)
-
-func main() {
-	// Load environment variables
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found")
-	}
-
-	// Connect to database
-	db, err := database.Connect()
-	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
-	}
-
-	// Initialize Gin router
-	r := gin.Default()
-
-	// Add middleware
-	r.Use(gin.Logger())
-	r.Use(gin.Recovery())
-
-	// Setup routes
-	api := r.Group("/api/v1")
-	handlers.SetupRoutes(api, db)
-
-	// Health check endpoint
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status": "ok",
-			"service": "{{.Name}}",
-		})
-	})
-
-	// Start server
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "{{.Port}}"
-	}
-
-	log.Printf("Server starting on port %s", port)
-	log.Fatal(http.ListenAndServe(":"+port, r))
-}
-`
+	template := templates.GetAPIMainTemplate(project.Framework)
 	return g.generateFromTemplate(project, template, filepath.Join(project.Name, "cmd", "server", "main.go"))
 }
 
@@ -446,7 +488,7 @@ func Connect() (*gorm.DB, error) {
 
 // getDefaultDSN returns the default database connection string
 func getDefaultDSN() string {
-	return "{{call .Database.GetDSN}}"
+	return "{{.Database.GetDSN}}"
 }
 
 // Close closes the database connection
@@ -465,78 +507,34 @@ func Close() error {
 	return g.generateFromTemplate(project, template, filepath.Join(project.Name, "pkg", "database", "database.go"))
 }
 
-// generateHandlers generates the handlers setup
+// generateHandlers generates the handlers setup and the problem details responses
 func (g *APIGenerator) generateHandlers(project *APIProject) error {
-	var template string
-	
-	if project.Database.Type == "mongodb" {
-		template = `package handlers
-
-import (
-	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/mongo"
-)
-
-// SetupRoutes sets up all API routes
-func SetupRoutes(r *gin.RouterGroup, db *mongo.Database) {
-	// Initialize repositories
-	// userRepo := repositories.NewUserRepository(db)
-
-	// Initialize services
-	// userService := services.NewUserService(userRepo)
-
-	// Initialize handlers
-	// userHandler := NewUserHandler(userService)
-
-	// Setup routes
-	// userHandler.SetupUserRoutes(r)
-
-	// Example endpoint
-	r.GET("/example", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"message": "{{.Name}} API is running!",
-		})
-	})
+	handlersDir := filepath.Join(project.Name, "internal", "handlers")
+	for _, file := range problemFiles {
+		if err := g.generateGoFromTemplate(project, file.template, filepath.Join(project.Name, file.path)); err != nil {
+			return err
+		}
+	}
+	if !project.HTTP().Validates() {
+		if err := g.generateGoFromTemplate(project, templates.HTTPHelpersTemplate, filepath.Join(handlersDir, "http_helpers.go")); err != nil {
+			return err
+		}
+	}
+	return g.generateGoFromTemplate(project, templates.APIRoutesTemplate, filepath.Join(handlersDir, "routes.go"))
 }
-`
-	} else {
-		template = `package handlers
-
-import (
-	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
-)
-
-// SetupRoutes sets up all API routes
-func SetupRoutes(r *gin.RouterGroup, db *gorm.DB) {
-	// Initialize repositories
-	// userRepo := repositories.NewUserRepository(db)
 
-	// Initialize services
-	// userService := services.NewUserService(userRepo)
-
-	// Initialize handlers
-	// userHandler := NewUserHandler(userService)
-
-	// Setup routes
-	// userHandler.SetupUserRoutes(r)
-
-	// Example endpoint
-	r.GET("/example", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"message": "{{.Name}} API is running!",
-		})
-	})
-}
-`
+// generateMiddleware generates the middleware the net/http server chains, other
+// frameworks ship their own logger and recovery middleware
+func (g *APIGenerator) generateMiddleware(project *APIProject) error {
+	if project.Framework != models.HTTPStdlib {
+		return nil
 	}
-	
-	return g.generateFromTemplate(project, template, filepath.Join(project.Name, "internal", "handlers", "routes.go"))
+	return g.generateFromTemplate(project, templates.APIStdlibMiddlewareTemplate, filepath.Join(project.Name, "internal", "middleware", "middleware.go"))
 }
 
 // generateDockerfile generates a Dockerfile
 func (g *APIGenerator) generateDockerfile(project *APIProject) error {
-	template := `FROM golang:1.21-alpine AS builder
+	template := `FROM golang:{{.Framework.GoVersion}}-alpine AS builder
 
 WORKDIR /app
 COPY go.mod ./
@@ -801,7 +799,7 @@ A Go web API built with clean architecture principles.
 - ✅ **{{.Database.Type | ToCamel}} Database**: Ready to use {{.Database.Type}} database
 - ✅ **Docker Support**: Complete Docker setup with docker-compose
 - ✅ **GORM Integration**: Database operations with GORM
-- ✅ **Gin Framework**: Fast HTTP router and middleware
+- ✅ **{{.Framework.GetDisplayName}} Framework**: HTTP routing and middleware
 - ✅ **Environment Configuration**: Flexible configuration management
 - ✅ **Health Check**: Built-in health check endpoint
 
@@ -1167,6 +1165,29 @@ func Exists(key string) (bool, error) {
 
 // generateFromTemplate generates a file from a template string
 func (g *APIGenerator) generateFromTemplate(project *APIProject, templateStr, outputPath string) error {
+	content, err := g.renderTemplate(project, templateStr)
+	if err != nil {
+		return err
+	}
+	return writeAPIFile(project, outputPath, content)
+}
+
+// generateGoFromTemplate generates a Go file whose framework specific code comes from
+// the project's HTTP dialect, dropping unused imports and formatting the result
+func (g *APIGenerator) generateGoFromTemplate(project *APIProject, templateStr, outputPath string) error {
+	content, err := g.renderTemplate(project, templateStr)
+	if err != nil {
+		return err
+	}
+	formatted, err := formatGoSource(content)
+	if err != nil {
+		return fmt.Errorf("%s: %w", filepath.Base(outputPath), err)
+	}
+	return writeAPIFile(project, outputPath, formatted)
+}
+
+// renderTemplate executes a project template
+func (g *APIGenerator) renderTemplate(project *APIProject, templateStr string) ([]byte, error) {
 	tmpl, err := template.New("generator").Funcs(template.FuncMap{
 		"ToCamel":      func(s string) string { return strings.Title(s) },
 		"ToLowerCamel": func(s string) string { return strings.ToLower(s[:1]) + s[1:] },
@@ -1175,19 +1196,30 @@ func (g *APIGenerator) generateFromTemplate(project *APIProject, templateStr, ou
 		"GetEnvVars":   func(db *models.DatabaseProvider) map[string]string { return db.GetEnvironmentVars() },
 	}).Parse(templateStr)
 	if err != nil {
-		return fmt.Errorf("failed to parse template: %w", err)
+		return nil, fmt.Errorf("failed to parse template: %w", err)
 	}
 
-	file, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("failed to create file %s: %w", outputPath, err)
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, project); err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
 	}
-	defer file.Close()
+	return buf.Bytes(), nil
+}
 
-	if err := tmpl.Execute(file, project); err != nil {
-		return fmt.Errorf("failed to execute template: %w", err)
+// writeAPIFile writes a generated project file, in the package of the layout of the project
+func writeAPIFile(project *APIProject, outputPath string, content []byte) error {
+	files := []placedFile{{path: outputPath, content: content}}
+	if layout := newProjectLayout(project.Layout, project.Name, project.Module); layout != nil {
+		var err error
+		if files, err = layout.place(outputPath, content); err != nil {
+			return err
+		}
+	}
+	for _, file := range files {
+		if err := os.WriteFile(file.path, file.content, 0644); err != nil {
+			return fmt.Errorf("failed to create file %s: %w", file.path, err)
+		}
 	}
-
 	return nil
 }
 
@@ -1210,6 +1242,10 @@ func (g *APIGenerator) generateManifest(project *APIProject) error {
 		Module:      project.Module,
 		GeneratedAt: now,
 		UpdatedAt:   now,
+
+		HTTPFramework: project.Framework,
+		Layout:        project.Layout,
+
 		CLI: VibercodeManifestCLI{
 			Version: "1.0.0", // TODO: Get this from build info
 			Command: "vibercode generate api",
@@ -1241,4 +1277,35 @@ func (g *APIGenerator) generateManifest(project *APIProject) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// LoadManifest reads the .vibercode/manifest.vibe file of the project in dir
+func LoadManifest(dir string) (*VibercodeManifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ".vibercode", "manifest.vibe"))
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest VibercodeManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// SaveManifest writes the .vibercode/manifest.vibe file of the project in dir
+func SaveManifest(dir string, manifest *VibercodeManifest) error {
+	vibercodeDir := filepath.Join(dir, ".vibercode")
+	if err := os.MkdirAll(vibercodeDir, 0755); err != nil {
+		return fmt.Errorf("failed to create .vibercode directory: %w", err)
+	}
+
+	jsonData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest to JSON: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(vibercodeDir, "manifest.vibe"), jsonData, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest file: %w", err)
+	}
+	return nil
+}
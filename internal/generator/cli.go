@@ -0,0 +1,248 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/vibercode/cli/internal/models"
+	"github.com/vibercode/cli/internal/templates"
+	"github.com/vibercode/cli/pkg/ui"
+)
+
+// CLIOptions contains configuration for command line client generation. Empty options are
+// read from the .vibercode/manifest.vibe of the project.
+type CLIOptions struct {
+	OutputPath string
+	Module     string
+	Name       string // Name of the binary, the project name followed by ctl by default
+	HTTP       models.HTTPFramework
+}
+
+// CLIGenerator generates a cobra command line client of the API of a project, built on its
+// Go SDK
+type CLIGenerator struct {
+	options CLIOptions
+	files   *SchemaGenerator
+}
+
+// CLITemplateData holds the data of the command line templates
+type CLITemplateData struct {
+	Name      string
+	EnvPrefix string
+	Title     string
+	Module    string
+	BaseURL   string
+	Resources []*CLIResource
+	Test      *SDKTest
+}
+
+// CLIResource holds the template data of the commands of a resource
+type CLIResource struct {
+	Module   string
+	Names    *models.NamingConventions
+	Columns  []string
+	Filters  []CLIFilter
+	Includes []string
+	Children []CLIRelation
+	Parents  []CLIRelation
+}
+
+// CLIFilter is a --filter field and the parser of its values
+type CLIFilter struct {
+	JSON   string
+	Method string
+	Parse  string // Parser of the value, none for strings
+}
+
+// CLIRelation is a command listing the records a record has (children) or showing the record
+// it belongs to (parents)
+type CLIRelation struct {
+	Command  string
+	Relation string // PascalCase name of the relation field
+	Names    *models.NamingConventions
+	Method   string // Filter method selecting the children of a record
+	Field    string // Foreign key field holding the parent of a record
+}
+
+// cliParsers are the parsers of the filter values by Go type
+var cliParsers = map[string]string{
+	"int64":     "parseInt",
+	"float64":   "parseFloat",
+	"bool":      "parseBool",
+	"time.Time": "parseTime",
+}
+
+// cliMaxColumns is the number of columns of the tables of records
+const cliMaxColumns = 6
+
+// NewCLIGenerator creates a new command line client generator
+func NewCLIGenerator() *CLIGenerator {
+	return &CLIGenerator{files: NewSchemaGenerator(nil)}
+}
+
+// Generate generates the command line client of the project in the output path, with
+// commands for every resource recorded in its API versions
+func (g *CLIGenerator) Generate(options CLIOptions) error {
+	g.options = options
+	if g.options.OutputPath == "" {
+		g.options.OutputPath = "."
+	}
+	outputPath := g.options.OutputPath
+
+	ui.PrintStep(1, 3, "Generating the Go SDK the commands call...")
+	sdk := NewSDKGenerator()
+	if err := sdk.Generate(SDKOptions{OutputPath: outputPath, Module: g.options.Module, Lang: SDKLangGo, HTTP: g.options.HTTP}); err != nil {
+		return err
+	}
+	manifest, err := LoadManifest(outputPath)
+	if err != nil {
+		return err
+	}
+	schemas, err := projectSchemas(outputPath, manifest)
+	if err != nil {
+		return err
+	}
+	sdkData, err := sdk.templateData(manifest, schemas)
+	if err != nil {
+		return err
+	}
+	data := g.templateData(manifest, schemas, sdkData)
+
+	ui.PrintStep(2, 3, "Generating commands...")
+	type file struct {
+		template string
+		data     interface{}
+		path     string
+	}
+	files := []file{
+		{templates.CLIMainTemplate, data, filepath.Join("cmd", data.Name, "main.go")},
+		{templates.CLIRootTemplate, data, filepath.Join("internal", "cli", "cli.go")},
+		{templates.CLIConfigTemplate, data, filepath.Join("internal", "cli", "config.go")},
+		{templates.CLIOutputTemplate, data, filepath.Join("internal", "cli", "output.go")},
+	}
+	for _, resource := range data.Resources {
+		files = append(files, file{templates.CLIResourceTemplate, resource, filepath.Join("internal", "cli", resource.Names.SnakeCase+".go")})
+	}
+	if data.Test != nil {
+		files = append(files, file{templates.CLITestTemplate, data, filepath.Join("internal", "cli", "cli_test.go")})
+	}
+	for _, file := range files {
+		if err := g.files.generateGoFile(file.template, file.data, filepath.Join(outputPath, file.path)); err != nil {
+			return err
+		}
+		ui.PrintFileCreated(file.path)
+	}
+
+	ui.PrintStep(3, 3, "Updating manifest...")
+	now := time.Now().Format(time.RFC3339)
+	manifest.History = append(manifest.History, VibercodeManifestEvent{
+		Type:        "generate_cli",
+		Description: fmt.Sprintf("Generated the %s command line client of %d resources", data.Name, len(data.Resources)),
+		Timestamp:   now,
+		CLI:         VibercodeManifestCLI{Version: "1.0.0", Command: "vibercode generate cli"},
+	})
+	manifest.UpdatedAt = now
+	if err := SaveManifest(outputPath, manifest); err != nil {
+		return err
+	}
+
+	ui.PrintSuccess("Command line client generated successfully!")
+	ui.PrintInfo(fmt.Sprintf("The commands use github.com/spf13/cobra and gopkg.in/yaml.v3, run 'go mod tidy' then 'go build ./cmd/%s'", data.Name))
+	ui.PrintInfo(fmt.Sprintf("Point it at an API with '%s config set default --base-url <url> --token <token>'", data.Name))
+	return nil
+}
+
+// templateData names the binary and derives the commands of the resources from the SDK
+// resources and the relations of their schemas
+func (g *CLIGenerator) templateData(manifest *VibercodeManifest, schemas []*models.ResourceSchema, sdkData *SDKTemplateData) *CLITemplateData {
+	name := g.options.Name
+	if name == "" {
+		name = sdkData.Name + "ctl"
+	}
+	port := manifest.Port
+	if port == "" {
+		port = "8080"
+	}
+	data := &CLITemplateData{
+		Name:      name,
+		EnvPrefix: strings.ToUpper(strings.ReplaceAll(toSnakeCase(name), "-", "_")),
+		Title:     sdkData.Title,
+		Module:    sdkData.Module,
+		BaseURL:   "http://localhost:" + port,
+		Test:      sdkData.Test,
+	}
+
+	resources := make(map[string]*SDKResource, len(schemas))
+	for i, schema := range schemas {
+		resources[schema.Name] = sdkData.Resources[i]
+	}
+	for i, schema := range schemas {
+		data.Resources = append(data.Resources, newCLIResource(schema, sdkData.Resources[i], resources, data.Module))
+	}
+	return data
+}
+
+// newCLIResource describes the commands of a resource: the table columns and filters come
+// from its SDK types, the relation commands from the foreign keys of its relations
+func newCLIResource(schema *models.ResourceSchema, resource *SDKResource, resources map[string]*SDKResource, module string) *CLIResource {
+	cli := &CLIResource{
+		Module:   module,
+		Names:    resource.Names,
+		Columns:  []string{"id"},
+		Includes: resource.Includes,
+	}
+	for _, field := range resource.Fields {
+		if len(cli.Columns) == cliMaxColumns {
+			break
+		}
+		if _, ok := sdkFilterTypes[field.kind]; ok || field.kind == "enum" || field.kind == "uuid" {
+			cli.Columns = append(cli.Columns, field.JSON)
+		}
+	}
+	for _, filter := range resource.Filters {
+		cli.Filters = append(cli.Filters, CLIFilter{JSON: filter.JSON, Method: filter.Method, Parse: cliParsers[filter.GoType]})
+	}
+
+	commands := map[string]bool{"list": true, "get": true, "create": true, "update": true, "delete": true}
+	for i := range schema.Fields {
+		field := &schema.Fields[i]
+		if !isRelationField(field) || field.Relation.ForeignKey == "" {
+			continue
+		}
+		target, ok := resources[field.Relation.Target]
+		command := toKebabCase(field.Name)
+		if !ok || commands[command] {
+			continue
+		}
+		relation := CLIRelation{Command: command, Relation: toPascalCase(field.Name), Names: target.Names}
+		foreignKey := toSnakeCase(field.Relation.ForeignKey)
+
+		switch {
+		case field.Type == "relation_array" && field.Relation.Type == "one_to_many":
+			// The children are the target records whose foreign key filter is the ID
+			for _, filter := range target.Filters {
+				if filter.JSON == foreignKey && filter.GoType == "string" {
+					relation.Method = filter.Method
+				}
+			}
+			if relation.Method != "" {
+				cli.Children = append(cli.Children, relation)
+				commands[command] = true
+			}
+		case field.Type == "relation":
+			// The parent is the target record the foreign key of the record holds
+			for _, keyField := range resource.Fields {
+				if keyField.JSON == foreignKey && keyField.GoType == "string" {
+					relation.Field = keyField.Name
+				}
+			}
+			if relation.Field != "" {
+				cli.Parents = append(cli.Parents, relation)
+				commands[command] = true
+			}
+		}
+	}
+	return cli
+}
@@ -0,0 +1,67 @@
+package generator
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vibercode/cli/internal/models"
+)
+
+func TestCLIGenerator(t *testing.T) {
+	assert.Error(t, NewCLIGenerator().Generate(CLIOptions{OutputPath: t.TempDir()}))
+
+	category := &models.ResourceSchema{
+		ID: "category-1", Name: "Category", DisplayName: "Category", Names: models.CreateResourceNames("Category"),
+		Fields: []models.SchemaField{
+			{Name: "title", Type: "string", DisplayName: "Title", Required: true},
+			{Name: "products", Type: "relation_array", DisplayName: "Products", Relation: &models.RelationConfig{Type: "one_to_many", Target: "Product", ForeignKey: "category_id"}},
+		},
+		Database: &models.DatabaseConfig{Provider: "postgres", TableName: "categories"},
+	}
+	product := newTestProductSchema()
+	product.Fields = append(product.Fields,
+		models.SchemaField{Name: "category_id", Type: "string", DisplayName: "Category ID"},
+		models.SchemaField{Name: "category", Type: "relation", DisplayName: "Category", Relation: &models.RelationConfig{Type: "many_to_one", Target: "Category", ForeignKey: "category_id", Populate: true}},
+	)
+	dir := generateTestProject(t, NewSchemaGenerator(newMemorySchemaStorage(category, product)), "postgres", category, product)
+	require.NoError(t, NewCLIGenerator().Generate(CLIOptions{OutputPath: dir, Name: "shopctl"}))
+
+	assertGeneratedFiles(t, dir,
+		generatedFile{path: "cmd/shopctl/main.go"},
+		generatedFile{
+			path:     "internal/cli/cli.go",
+			contains: []string{`EnvBaseURL = "SHOPCTL_BASE_URL"`, `const DefaultBaseURL = "http://localhost:8080"`},
+		},
+		generatedFile{path: "internal/cli/config.go"},
+		generatedFile{path: "internal/cli/output.go"},
+		generatedFile{path: "internal/cli/cli_test.go"},
+		generatedFile{
+			path: "internal/cli/product.go",
+			contains: []string{
+				`var productColumns = []string{"id", "sku", "name", "description", "stock", "active"}`,
+				"parsed, err := parseInt(name, value)\n\t\t\tif err != nil {\n\t\t\t\treturn nil, err\n\t\t\t}\n\t\t\tfilter.WhereStock(parsed)",
+				// Records show the record their foreign key holds
+				`Use:   "category <id>",`,
+				"client.Categories.Get(cmd.Context(), product.CategoryId)",
+			},
+		},
+		generatedFile{
+			path: "internal/cli/category.go",
+			contains: []string{
+				// Records list the records whose foreign key holds them
+				`Use:   "products <id>",`,
+				"app.listProducts(cmd, filter.WhereCategoryId(args[0]), flags.all)",
+			},
+		},
+		generatedFile{path: "pkg/sdk/client.go"},
+		generatedFile{path: "pkg/sdk/product.go"},
+	)
+
+	manifest, err := LoadManifest(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "generate_cli", manifest.History[len(manifest.History)-1].Type)
+
+	assertGoFilesParse(t, filepath.Join(dir, "internal", "cli"))
+}
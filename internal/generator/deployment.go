@@ -328,6 +328,7 @@ func (g *DeploymentGenerator) getFullSuiteConfig() (models.DeploymentConfig, err
 	config = models.GetDefaultDeploymentSuite(models.CloudProvider(provider))
 	config.AppName = appName
 	config.Environment = environment
+	config.WithWorker = hasWorkerEntrypoint()
 
 	return config, nil
 }
@@ -347,6 +348,7 @@ func (g *DeploymentGenerator) getSpecificDeploymentConfig() (models.DeploymentCo
 		WithIngress: g.options.WithIngress,
 		WithSecrets: g.options.WithSecrets,
 		WithHPA:     g.options.WithHPA,
+		WithWorker:  hasWorkerEntrypoint(),
 		Replicas:    3,
 	}
 
@@ -366,6 +368,12 @@ func (g *DeploymentGenerator) getSpecificDeploymentConfig() (models.DeploymentCo
 	return config, nil
 }
 
+// hasWorkerEntrypoint reports whether the project has the background worker of "generate worker"
+func hasWorkerEntrypoint() bool {
+	_, err := os.Stat(filepath.Join("cmd", "worker", "main.go"))
+	return err == nil
+}
+
 // createDeploymentDirectories creates deployment directory structure
 func (g *DeploymentGenerator) createDeploymentDirectories() error {
 	dirs := []string{
@@ -7,6 +7,7 @@ import (
 // EnhancedField extends SchemaField with template-specific data
 type EnhancedField struct {
 	*models.SchemaField
+	GoType         string
 	GoStructField  string
 	GoRequestField string
 	GoResponseField string
@@ -26,9 +27,27 @@ type EnhancedSchema struct {
 	DBProvider      string
 	Relations       []RelationInfo
 	RequiredImports []string
-	GetPreloads     string
 	GetSearchFields string
 	GetSearchValues string
+	HTTP            *HTTPDialect
+	DataLayer       models.DataLayer
+	Geo             *GeoField
+	// Includes lists the relations list and get endpoints expand with ?include
+	Includes []IncludeRelation
+	// Endpoints lists the feature endpoints generated for the schema and RelationRoutes its
+	// nested relation routes, recorded for the OpenAPI documents
+	Endpoints      []string
+	RelationRoutes []RelationRoute
+}
+
+// UsesGORM reports whether models and repositories are persisted with GORM
+func (s *EnhancedSchema) UsesGORM() bool {
+	return s.DBProvider != "mongodb" && s.DataLayer == models.DataLayerGORM
+}
+
+// UsesMoney reports whether the schema has currency fields, held by money.Money amounts
+func (s *EnhancedSchema) UsesMoney() bool {
+	return len(s.GetMoneyFields()) > 0
 }
 
 // enhanceField converts a SchemaField to EnhancedField
@@ -40,6 +59,7 @@ func (g *SchemaGenerator) enhanceField(field *models.SchemaField, dbProvider str
 		ReadOnly:    g.isFieldReadOnly(field),
 	}
 
+	enhanced.GoType = field.GetGoType()
 	enhanced.GoStructField = g.generateGoStructField(field, dbProvider)
 	enhanced.GoRequestField = g.generateGoRequestField(field)
 	enhanced.GoResponseField = g.generateGoResponseField(field)
@@ -58,16 +78,18 @@ func (g *SchemaGenerator) prepareEnhancedTemplateData(schema *models.ResourceSch
 		DBProvider:     dbProvider,
 		Relations:      g.extractRelations(schema),
 		RequiredImports: g.getRequiredImports(schema, dbProvider),
+		HTTP:            newHTTPDialect(g.httpFramework),
+		DataLayer:       g.dataLayer.OrDefault(),
+		Geo:             prepareGeoField(schema, dbProvider),
 	}
 
 	// Enhance fields
 	enhanced.Fields = make([]EnhancedField, len(schema.Fields))
-	for i, field := range schema.Fields {
-		enhanced.Fields[i] = g.enhanceField(&field, dbProvider)
+	for i := range schema.Fields {
+		enhanced.Fields[i] = g.enhanceField(&schema.Fields[i], dbProvider)
 	}
 
 	// Add helper methods
-	enhanced.GetPreloads = g.generatePreloads(schema)
 	enhanced.GetSearchFields = g.generateSearchFields(schema)
 	enhanced.GetSearchValues = g.generateSearchValues(schema)
 
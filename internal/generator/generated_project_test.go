@@ -0,0 +1,109 @@
+//go:build integration
+
+package generator
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vibercode/cli/internal/models"
+)
+
+// TestGeneratedProjectsCompile vets a generated project with related resources for every
+// HTTP framework and data layer. The go command resolves the dependencies of the generated
+// modules, so the test needs a module proxy or a warm module cache:
+//
+//	go test -tags integration -run TestGeneratedProjectsCompile ./internal/generator
+func TestGeneratedProjectsCompile(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiling generated projects is slow")
+	}
+
+	layers := []struct {
+		layer    models.DataLayer
+		provider string
+	}{
+		{models.DataLayerGORM, "postgres"},
+		{models.DataLayerSQLC, "postgres"},
+		{models.DataLayerSQLX, "mysql"},
+		{models.DataLayerPGX, "postgres"},
+	}
+
+	for _, name := range models.SupportedHTTPFrameworks() {
+		framework := models.HTTPFramework(name)
+		for _, tt := range layers {
+			t.Run(name+"/"+string(tt.layer), func(t *testing.T) {
+				if tt.layer == models.DataLayerSQLC {
+					if _, err := exec.LookPath("sqlc"); err != nil {
+						t.Skip("sqlc is not installed")
+					}
+				}
+
+				dir := newCompileTestProject(t, framework, tt.provider)
+				// The other data layers can't include relations
+				populate := tt.layer == models.DataLayerGORM
+				category := &models.ResourceSchema{
+					ID:          "category-1",
+					Name:        "Category",
+					DisplayName: "Category",
+					Names:       models.CreateResourceNames("Category"),
+					Fields: []models.SchemaField{
+						{Name: "name", Type: "string", DisplayName: "Name", Required: true},
+						{Name: "products", Type: "relation_array", DisplayName: "Products", Relation: &models.RelationConfig{Type: "one_to_many", Target: "Product", ForeignKey: "category_id", Populate: populate}},
+					},
+					Database: &models.DatabaseConfig{Provider: tt.provider, TableName: "categories"},
+				}
+				product := newTestProductSchema()
+				product.Database.Provider = tt.provider
+				product.Fields = append(product.Fields,
+					models.SchemaField{Name: "category_id", Type: "string", DisplayName: "Category ID"},
+					models.SchemaField{Name: "category", Type: "relation", DisplayName: "Category", Relation: &models.RelationConfig{Type: "many_to_one", Target: "Category", ForeignKey: "category_id", Populate: populate}},
+				)
+
+				gen := NewSchemaGenerator(newMemorySchemaStorage(category, product)).
+					WithHTTPFramework(framework).
+					WithDataLayer(tt.layer)
+				for _, schema := range []*models.ResourceSchema{product, category} {
+					require.NoError(t, gen.GenerateFromSchema(schema.ID, dir, "example.com/shop", tt.provider))
+				}
+
+				if tt.layer == models.DataLayerSQLC {
+					runCommand(t, dir, "sqlc", "generate")
+				}
+				runCommand(t, dir, "go", "mod", "tidy")
+				runCommand(t, dir, "go", "vet", "./...")
+			})
+		}
+	}
+}
+
+// newCompileTestProject generates an API project with its database package
+func newCompileTestProject(t *testing.T, framework models.HTTPFramework, provider string) string {
+	dir := filepath.Join(t.TempDir(), "shop")
+	project := &APIProject{
+		Name:      dir,
+		Port:      "8080",
+		Database:  &models.DatabaseProvider{Type: provider},
+		Module:    "example.com/shop",
+		Framework: framework,
+	}
+	gen := NewAPIGenerator().WithHTTPFramework(framework)
+	require.NoError(t, gen.createProjectStructure(project))
+	for _, fn := range []func(*APIProject) error{gen.generateGoMod, gen.generateMain, gen.generateDatabase, gen.generateHandlers, gen.generateMiddleware, gen.generateManifest} {
+		require.NoError(t, fn(project))
+	}
+	return dir
+}
+
+// runCommand runs a command in dir and fails the test with its output when it fails
+func runCommand(t *testing.T, dir, name string, args ...string) {
+	t.Helper()
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%s %s: %v\n%s", name, strings.Join(args, " "), err, output)
+	}
+}
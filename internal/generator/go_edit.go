@@ -0,0 +1,435 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// goFile is a Go source file of a project edited through its syntax tree, so that code is
+// registered in functions the user may have changed since they were generated. The tree
+// locates the edits, which are inserted in the source text to keep its comments and layout.
+type goFile struct {
+	path     string
+	fset     *token.FileSet
+	file     *ast.File
+	src      []byte
+	edits    []*goEdit
+	inserted map[ast.Stmt]*goEdit // Edits of the statements added to the tree
+	imports  *goEdit              // Import block opened by the edits
+}
+
+// goEdit is text inserted at an offset of the original source, replacing the source up to
+// end when end is after offset. Edits at the same offset are applied in the order of the
+// edits of their file.
+type goEdit struct {
+	offset int
+	end    int
+	text   string
+}
+
+// parseGoFile parses the Go file at path along with its comments
+func parseGoFile(path string) (*goFile, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseGoSource(path, src)
+}
+
+// parseGoSource parses the source of the Go file at path
+func parseGoSource(path string, src []byte) (*goFile, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &goFile{path: path, fset: fset, file: file, src: src, inserted: make(map[ast.Stmt]*goEdit)}, nil
+}
+
+// function returns the top level function named name, nil when the file has none
+func (f *goFile) function(name string) *ast.FuncDecl {
+	for _, decl := range f.file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == name && fn.Body != nil {
+			return fn
+		}
+	}
+	return nil
+}
+
+// addImport imports path unless the file already does. Standard library packages join the
+// last standard library import, the others the last import of a module.
+func (f *goFile) addImport(path string) {
+	f.addNamedImport("", path)
+}
+
+// addNamedImport imports path under name, its package name when name is empty, unless the
+// file already imports path
+func (f *goFile) addNamedImport(name, path string) {
+	for _, spec := range f.file.Imports {
+		if value, err := strconv.Unquote(spec.Path.Value); err == nil && value == path {
+			return
+		}
+	}
+	spec := &ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(path)}}
+	line := "\n" + spec.Path.Value
+	if name != "" {
+		spec.Name = ast.NewIdent(name)
+		line = "\n" + name + " " + spec.Path.Value
+	}
+	f.file.Imports = append(f.file.Imports, spec)
+
+	// Imports added to a file without an import block share the block they open
+	if f.imports != nil {
+		f.imports.text = strings.TrimSuffix(f.imports.text, "\n)") + line + "\n)"
+		return
+	}
+	var decl *ast.GenDecl
+	for _, d := range f.file.Decls {
+		if gen, ok := d.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			decl = gen
+			break
+		}
+	}
+	switch {
+	case decl == nil:
+		f.imports = &goEdit{offset: f.lineEnd(f.file.Name.End()), text: "\n\nimport (" + line + "\n)"}
+		f.edits = append(f.edits, f.imports)
+	case !decl.Lparen.IsValid():
+		f.add(f.offset(decl.Specs[0].Pos()), "(\n")
+		f.imports = &goEdit{offset: f.offset(decl.Specs[0].End()), text: line + "\n)"}
+		f.edits = append(f.edits, f.imports)
+	default:
+		anchor := token.NoPos
+		for _, s := range decl.Specs {
+			if isStdImport(s.(*ast.ImportSpec)) == isStdImport(spec) {
+				anchor = s.End()
+			}
+		}
+		if !anchor.IsValid() {
+			anchor = decl.Lparen
+			if !isStdImport(spec) && len(decl.Specs) > 0 {
+				anchor = decl.Specs[len(decl.Specs)-1].End()
+			}
+		}
+		f.add(f.lineEnd(anchor), line)
+	}
+}
+
+// isStdImport reports whether an import is a package of the standard library, whose first
+// path element has no dot
+func isStdImport(spec *ast.ImportSpec) bool {
+	path, _ := strconv.Unquote(spec.Path.Value)
+	return !strings.Contains(strings.Split(path, "/")[0], ".")
+}
+
+// insert parses the statements of src and inserts them in body before the statement at index,
+// at the end of the body when index is out of range. The statements are added to the tree
+// too, so that they are found by the following edits.
+func (f *goFile) insert(body *ast.BlockStmt, index int, src string) error {
+	stmts, err := parseStmts(src)
+	if err != nil {
+		return err
+	}
+	if index < 0 || index > len(body.List) {
+		index = len(body.List)
+	}
+
+	// The statements follow the line of the previous statement, or of the brace
+	edit := &goEdit{text: "\n" + src}
+	position := 0
+	if index == 0 {
+		edit.offset = f.lineEnd(body.Lbrace)
+	} else if previous, ok := f.inserted[body.List[index-1]]; ok {
+		edit.offset = previous.offset
+		position = f.indexOf(previous) + 1
+	} else {
+		edit.offset = f.lineEnd(body.List[index-1].End())
+	}
+	if position == 0 {
+		// Edits already at the offset follow the statement at index
+		position = len(f.edits)
+		for i, e := range f.edits {
+			if e.offset == edit.offset {
+				position = i
+				break
+			}
+		}
+	}
+	f.edits = append(f.edits[:position], append([]*goEdit{edit}, f.edits[position:]...)...)
+	for _, stmt := range stmts {
+		f.inserted[stmt] = edit
+	}
+
+	list := make([]ast.Stmt, 0, len(body.List)+len(stmts))
+	list = append(list, body.List[:index]...)
+	list = append(list, stmts...)
+	body.List = append(list, body.List[index:]...)
+	return nil
+}
+
+// wrap wraps an expression of the original source, e.g. in a call with the "f(" prefix and
+// the ")" suffix
+func (f *goFile) wrap(expr ast.Expr, prefix, suffix string) {
+	f.add(f.offset(expr.Pos()), prefix)
+	f.add(f.offset(expr.End()), suffix)
+}
+
+// deleteImport deletes an import spec of an import declaration, the declaration when it
+// has no other spec
+func (f *goFile) deleteImport(decl *ast.GenDecl, spec *ast.ImportSpec) {
+	if !decl.Lparen.IsValid() {
+		f.replace(decl, "")
+		return
+	}
+	// The line of the spec goes with it, unless the spec shares it
+	start, end := f.offset(spec.Pos()), f.offset(spec.End())
+	lineStart := bytes.LastIndexByte(f.src[:start], '\n') + 1
+	lineEnd := f.lineEnd(spec.End())
+	if len(bytes.TrimSpace(f.src[lineStart:start])) == 0 && len(bytes.TrimSpace(f.src[end:lineEnd])) == 0 && lineEnd < len(f.src) {
+		start, end = lineStart, lineEnd+1
+	}
+	f.edits = append(f.edits, &goEdit{offset: start, end: end})
+}
+
+// add inserts text at an offset, after the edits already at the offset
+func (f *goFile) add(offset int, text string) {
+	f.edits = append(f.edits, &goEdit{offset: offset, text: text})
+}
+
+// replace replaces the source of a node with text
+func (f *goFile) replace(node ast.Node, text string) {
+	f.edits = append(f.edits, &goEdit{offset: f.offset(node.Pos()), end: f.offset(node.End()), text: text})
+}
+
+func (f *goFile) indexOf(edit *goEdit) int {
+	for i, e := range f.edits {
+		if e == edit {
+			return i
+		}
+	}
+	return len(f.edits) - 1
+}
+
+// offset returns the offset of a position of the file in its source
+func (f *goFile) offset(pos token.Pos) int {
+	return f.fset.Position(pos).Offset
+}
+
+// lineEnd returns the offset of the end of the line of a position, after a trailing comment
+func (f *goFile) lineEnd(pos token.Pos) int {
+	offset := f.offset(pos)
+	if end := bytes.IndexByte(f.src[offset:], '\n'); end >= 0 {
+		return offset + end
+	}
+	return len(f.src)
+}
+
+// save applies the edits and writes the file back when it was changed, placing the packages
+// it imports according to the layout of its project
+func (f *goFile) save() error {
+	if len(f.edits) == 0 {
+		return nil
+	}
+	content, err := f.source()
+	if err != nil {
+		return err
+	}
+	if layout := findProjectLayout(filepath.Dir(f.path)); layout != nil {
+		if content, err = layout.rewrite(f.path, content); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(f.path, content, 0644)
+}
+
+// source returns the source with the edits applied, formatted
+func (f *goFile) source() ([]byte, error) {
+	edits := append([]*goEdit{}, f.edits...)
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].offset < edits[j].offset })
+
+	var buf bytes.Buffer
+	last := 0
+	for _, edit := range edits {
+		if edit.offset > last {
+			buf.Write(f.src[last:edit.offset])
+		}
+		buf.WriteString(edit.text)
+		last = max(edit.offset, edit.end)
+	}
+	buf.Write(f.src[last:])
+
+	// Formatting indents the inserted statements and sorts the added imports into their group
+	content, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("edited %s does not parse: %w", f.path, err)
+	}
+	return content, nil
+}
+
+// parseStmts parses a list of statements
+func parseStmts(src string) ([]ast.Stmt, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), "", "package p\nfunc _() {\n"+src+"\n}", 0)
+	if err != nil {
+		return nil, fmt.Errorf("generated statements do not parse: %w", err)
+	}
+	return file.Decls[0].(*ast.FuncDecl).Body.List, nil
+}
+
+// findCall returns the index of the first statement of body containing a call of fun, written
+// as it is called, e.g. "r.Use" or "SetupProductRoutes", -1 when there is none
+func findCall(body *ast.BlockStmt, fun string) int {
+	for i, stmt := range body.List {
+		if callOf(stmt, fun) != nil {
+			return i
+		}
+	}
+	return -1
+}
+
+// lastCall returns the index of the last statement of body containing a call of fun, -1 when
+// there is none
+func lastCall(body *ast.BlockStmt, fun string) int {
+	for i := len(body.List) - 1; i >= 0; i-- {
+		if callOf(body.List[i], fun) != nil {
+			return i
+		}
+	}
+	return -1
+}
+
+// callOf returns the first call of fun in node, nil when there is none
+func callOf(node ast.Node, fun string) *ast.CallExpr {
+	var found *ast.CallExpr
+	ast.Inspect(node, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok && found == nil && types.ExprString(call.Fun) == fun {
+			found = call
+		}
+		return found == nil
+	})
+	return found
+}
+
+// routeReceiver returns the router a statement registers a route of path on, e.g. r for
+// r.GET("/health", ...), empty when the statement registers no such route
+func routeReceiver(stmt ast.Stmt, path string) string {
+	expr, ok := stmt.(*ast.ExprStmt)
+	if !ok {
+		return ""
+	}
+	call, ok := expr.X.(*ast.CallExpr)
+	if !ok || len(call.Args) == 0 {
+		return ""
+	}
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return ""
+	}
+	pattern, err := strconv.Unquote(lit.Value)
+	// net/http patterns may start with a method
+	if err != nil || (pattern != path && !strings.HasSuffix(pattern, " "+path)) {
+		return ""
+	}
+	return types.ExprString(selector.X)
+}
+
+// projectImports returns the import paths of the Go files of a project
+func projectImports(dir string) (map[string]bool, error) {
+	imports := make(map[string]bool)
+	err := filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			name := entry.Name()
+			if path != dir && (strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules" || name == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".go" {
+			return nil
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.ImportsOnly)
+		if err != nil {
+			// Files the user is editing do not stop the requirements of the others
+			return nil
+		}
+		for _, spec := range file.Imports {
+			if value, err := strconv.Unquote(spec.Path.Value); err == nil {
+				imports[value] = true
+			}
+		}
+		return nil
+	})
+	return imports, err
+}
+
+// requireModules adds the modules of known providing the imports of a project to the
+// requirements of its go.mod, returning the modules it added
+func requireModules(dir string, imports map[string]bool, known []module.Version) ([]module.Version, error) {
+	path := filepath.Join(dir, "go.mod")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	file, err := modfile.Parse(path, data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	required := make(map[string]bool, len(file.Require))
+	for _, req := range file.Require {
+		required[req.Mod.Path] = true
+	}
+	var added []module.Version
+	for importPath := range imports {
+		mod, ok := providingModule(importPath, known)
+		if !ok || required[mod.Path] {
+			continue
+		}
+		if err := file.AddRequire(mod.Path, mod.Version); err != nil {
+			return nil, err
+		}
+		required[mod.Path] = true
+		added = append(added, mod)
+	}
+	if len(added) == 0 {
+		return nil, nil
+	}
+	sort.Slice(added, func(i, j int) bool { return added[i].Path < added[j].Path })
+
+	file.Cleanup()
+	content, err := file.Format()
+	if err != nil {
+		return nil, err
+	}
+	return added, os.WriteFile(path, content, 0644)
+}
+
+// providingModule returns the module of known with the longest path providing a package
+func providingModule(importPath string, known []module.Version) (module.Version, bool) {
+	var found module.Version
+	for _, mod := range known {
+		if (importPath == mod.Path || strings.HasPrefix(importPath, mod.Path+"/")) && len(mod.Path) > len(found.Path) {
+			found = mod
+		}
+	}
+	return found, found.Path != ""
+}
@@ -0,0 +1,701 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/vibercode/cli/internal/models"
+	"github.com/vibercode/cli/internal/templates"
+	"github.com/vibercode/cli/pkg/ui"
+)
+
+// HTTPDialect renders the framework specific parts of generated handlers and routes.
+// Templates describe a handler once and ask the dialect for the handler signature,
+// request accessors, responses and route registrations of the target framework.
+type HTTPDialect struct {
+	Framework models.HTTPFramework
+}
+
+// newHTTPDialect creates the dialect of a framework, falling back to the default framework
+func newHTTPDialect(framework models.HTTPFramework) *HTTPDialect {
+	return &HTTPDialect{Framework: framework.OrDefault()}
+}
+
+// WithHTTPFramework selects the HTTP framework of generated handlers and routes
+func (g *SchemaGenerator) WithHTTPFramework(framework models.HTTPFramework) *SchemaGenerator {
+	g.httpFramework = framework
+	return g
+}
+
+// Name returns the framework name
+func (d *HTTPDialect) Name() string {
+	return string(d.Framework)
+}
+
+// Is reports whether the dialect targets the named framework
+func (d *HTTPDialect) Is(name string) bool {
+	return string(d.Framework) == name
+}
+
+// NetHTTP reports whether handlers are plain net/http handler functions
+func (d *HTTPDialect) NetHTTP() bool {
+	return d.Framework == models.HTTPStdlib || d.Framework == models.HTTPChi
+}
+
+// Validates reports whether binding a request checks its binding tags. Gin does, other
+// frameworks bind through the generated helpers running the same validator.
+func (d *HTTPDialect) Validates() bool {
+	return d.Framework == models.HTTPGin
+}
+
+// Imports returns the framework packages handlers and routes may use.
+// Packages a rendered file does not use are pruned by formatGoSource.
+func (d *HTTPDialect) Imports() []string {
+	switch d.Framework {
+	case models.HTTPChi:
+		return []string{"github.com/go-chi/chi/v5"}
+	case models.HTTPEcho:
+		return []string{"github.com/labstack/echo/v4"}
+	case models.HTTPFiber:
+		return []string{"github.com/gofiber/fiber/v2", "github.com/gofiber/fiber/v2/middleware/adaptor"}
+	case models.HTTPStdlib:
+		return nil
+	default:
+		return []string{"github.com/gin-gonic/gin"}
+	}
+}
+
+// HandlerParams returns the parameter list of a handler function
+func (d *HTTPDialect) HandlerParams() string {
+	switch d.Framework {
+	case models.HTTPEcho:
+		return "c echo.Context"
+	case models.HTTPFiber:
+		return "c *fiber.Ctx"
+	case models.HTTPStdlib, models.HTTPChi:
+		return "w http.ResponseWriter, r *http.Request"
+	default:
+		return "c *gin.Context"
+	}
+}
+
+// HandlerResult returns the result list of a handler function, including the leading space
+func (d *HTTPDialect) HandlerResult() string {
+	if d.Framework == models.HTTPEcho || d.Framework == models.HTTPFiber {
+		return " error"
+	}
+	return ""
+}
+
+// HandlerArgs returns the arguments passing the handler parameters on to a helper
+func (d *HTTPDialect) HandlerArgs() string {
+	if d.NetHTTP() {
+		return "w, r"
+	}
+	return "c"
+}
+
+// Router returns the router parameter of the route setup functions
+func (d *HTTPDialect) Router() string {
+	switch d.Framework {
+	case models.HTTPChi:
+		return "r chi.Router"
+	case models.HTTPEcho:
+		return "r *echo.Group"
+	case models.HTTPFiber:
+		return "r fiber.Router"
+	case models.HTTPStdlib:
+		return "r *http.ServeMux"
+	default:
+		return "r *gin.RouterGroup"
+	}
+}
+
+// Map returns the map type used for ad hoc JSON objects
+func (d *HTTPDialect) Map() string {
+	switch d.Framework {
+	case models.HTTPEcho:
+		return "echo.Map"
+	case models.HTTPFiber:
+		return "fiber.Map"
+	case models.HTTPStdlib, models.HTTPChi:
+		return "map[string]interface{}"
+	default:
+		return "gin.H"
+	}
+}
+
+// Context returns the request context expression
+func (d *HTTPDialect) Context() string {
+	switch d.Framework {
+	case models.HTTPEcho:
+		return "c.Request().Context()"
+	case models.HTTPFiber:
+		return "c.UserContext()"
+	case models.HTTPStdlib, models.HTTPChi:
+		return "r.Context()"
+	default:
+		return "c.Request.Context()"
+	}
+}
+
+// Param returns the expression reading a path parameter
+func (d *HTTPDialect) Param(name string) string {
+	switch d.Framework {
+	case models.HTTPFiber:
+		return fmt.Sprintf("c.Params(%q)", name)
+	case models.HTTPChi:
+		return fmt.Sprintf("chi.URLParam(r, %q)", name)
+	case models.HTTPStdlib:
+		return fmt.Sprintf("r.PathValue(%q)", name)
+	default:
+		return fmt.Sprintf("c.Param(%q)", name)
+	}
+}
+
+// Query returns the expression reading a query string parameter
+func (d *HTTPDialect) Query(name string) string {
+	switch d.Framework {
+	case models.HTTPEcho:
+		return fmt.Sprintf("c.QueryParam(%q)", name)
+	case models.HTTPStdlib, models.HTTPChi:
+		return fmt.Sprintf("r.URL.Query().Get(%q)", name)
+	default:
+		return fmt.Sprintf("c.Query(%q)", name)
+	}
+}
+
+// RawQuery returns the expression reading the undecoded query string
+func (d *HTTPDialect) RawQuery() string {
+	switch d.Framework {
+	case models.HTTPEcho:
+		return "c.Request().URL.RawQuery"
+	case models.HTTPFiber:
+		return "string(c.Request().URI().QueryString())"
+	case models.HTTPStdlib, models.HTTPChi:
+		return "r.URL.RawQuery"
+	default:
+		return "c.Request.URL.RawQuery"
+	}
+}
+
+// Header returns the expression reading a request header
+func (d *HTTPDialect) Header(name string) string {
+	switch d.Framework {
+	case models.HTTPEcho:
+		return fmt.Sprintf("c.Request().Header.Get(%q)", name)
+	case models.HTTPFiber:
+		return fmt.Sprintf("c.Get(%q)", name)
+	case models.HTTPStdlib, models.HTTPChi:
+		return fmt.Sprintf("r.Header.Get(%q)", name)
+	default:
+		return fmt.Sprintf("c.GetHeader(%q)", name)
+	}
+}
+
+// ContentType returns the expression reading the request content type
+func (d *HTTPDialect) ContentType() string {
+	if d.Framework == models.HTTPGin {
+		return "c.ContentType()"
+	}
+	return d.Header("Content-Type")
+}
+
+// BindJSON returns the expression binding and validating the JSON body into target
+func (d *HTTPDialect) BindJSON(target string) string {
+	switch d.Framework {
+	case models.HTTPEcho, models.HTTPFiber:
+		return fmt.Sprintf("bindJSON(c, %s)", target)
+	case models.HTTPStdlib, models.HTTPChi:
+		return fmt.Sprintf("decodeJSON(r, %s)", target)
+	default:
+		return fmt.Sprintf("c.ShouldBindJSON(%s)", target)
+	}
+}
+
+// BindQuery returns the expression binding the query string into target
+func (d *HTTPDialect) BindQuery(target string) string {
+	switch d.Framework {
+	case models.HTTPEcho:
+		return fmt.Sprintf("(&echo.DefaultBinder{}).BindQueryParams(c, %s)", target)
+	case models.HTTPFiber:
+		return fmt.Sprintf("c.QueryParser(%s)", target)
+	case models.HTTPStdlib, models.HTTPChi:
+		return fmt.Sprintf("decodeQuery(r, %s)", target)
+	default:
+		return fmt.Sprintf("c.ShouldBindQuery(%s)", target)
+	}
+}
+
+// DecodeJSON returns the expression decoding the JSON body into target without
+// running the framework's binding validation
+func (d *HTTPDialect) DecodeJSON(target string) string {
+	switch d.Framework {
+	case models.HTTPEcho:
+		return fmt.Sprintf("json.NewDecoder(c.Request().Body).Decode(%s)", target)
+	case models.HTTPFiber:
+		return fmt.Sprintf("json.Unmarshal(c.Body(), %s)", target)
+	case models.HTTPStdlib, models.HTTPChi:
+		return fmt.Sprintf("json.NewDecoder(r.Body).Decode(%s)", target)
+	default:
+		return fmt.Sprintf("json.NewDecoder(c.Request.Body).Decode(%s)", target)
+	}
+}
+
+// LimitBody returns the request body as a reader failing after limit bytes.
+// Fiber buffers bodies up to its BodyLimit setting, so the limit is applied there.
+func (d *HTTPDialect) LimitBody(limit string) string {
+	switch d.Framework {
+	case models.HTTPEcho:
+		return fmt.Sprintf("http.MaxBytesReader(c.Response(), c.Request().Body, %s)", limit)
+	case models.HTTPFiber:
+		return "bytes.NewReader(c.Body())"
+	case models.HTTPStdlib, models.HTTPChi:
+		return fmt.Sprintf("http.MaxBytesReader(w, r.Body, %s)", limit)
+	default:
+		return fmt.Sprintf("http.MaxBytesReader(c.Writer, c.Request.Body, %s)", limit)
+	}
+}
+
+// SetHeader returns the statement setting a response header
+func (d *HTTPDialect) SetHeader(name, value string) string {
+	switch d.Framework {
+	case models.HTTPEcho:
+		return fmt.Sprintf("c.Response().Header().Set(%s, %s)", name, value)
+	case models.HTTPFiber:
+		return fmt.Sprintf("c.Set(%s, %s)", name, value)
+	case models.HTTPStdlib, models.HTTPChi:
+		return fmt.Sprintf("w.Header().Set(%s, %s)", name, value)
+	default:
+		return fmt.Sprintf("c.Header(%s, %s)", name, value)
+	}
+}
+
+// Writer returns the response body writer of streaming handlers
+func (d *HTTPDialect) Writer() string {
+	switch d.Framework {
+	case models.HTTPEcho:
+		return "c.Response()"
+	case models.HTTPStdlib, models.HTTPChi:
+		return "w"
+	default:
+		return "c.Writer"
+	}
+}
+
+// WriteStatus returns the statement sending the status line of a streamed response
+func (d *HTTPDialect) WriteStatus(status string) string {
+	switch d.Framework {
+	case models.HTTPEcho:
+		return fmt.Sprintf("c.Response().WriteHeader(%s)", status)
+	case models.HTTPFiber:
+		return fmt.Sprintf("c.Status(%s)", status)
+	case models.HTTPStdlib, models.HTTPChi:
+		return fmt.Sprintf("w.WriteHeader(%s)", status)
+	default:
+		return fmt.Sprintf("c.Status(%s)", status)
+	}
+}
+
+// Reply opens the final JSON response of a handler; the template writes the body and the closing parenthesis
+func (d *HTTPDialect) Reply(status string) string {
+	return d.Return(d.jsonCall(status))
+}
+
+// Respond returns the final statement of a handler sending body as JSON
+func (d *HTTPDialect) Respond(status, body string) string {
+	return d.Reply(status) + body + ")"
+}
+
+// Error returns the final statement of a handler answering with a problem of the given status
+func (d *HTTPDialect) Error(status, message string) string {
+	return d.Return(d.errorCall(status, message))
+}
+
+// Fail returns the statements answering with a problem of the given status and leaving the handler
+func (d *HTTPDialect) Fail(status, message string) string {
+	return d.Exit(d.errorCall(status, message))
+}
+
+// Problem returns the statements answering with the problem details of the error err and
+// leaving the handler, the status and code come from the apperrors error it wraps
+func (d *HTTPDialect) Problem(err string) string {
+	if d.NetHTTP() {
+		return d.Exit(fmt.Sprintf("writeProblem(w, r, %s)", err))
+	}
+	return d.Exit(fmt.Sprintf("writeProblem(c, %s)", err))
+}
+
+// jsonCall opens the call writing a JSON response
+func (d *HTTPDialect) jsonCall(status string) string {
+	switch d.Framework {
+	case models.HTTPFiber:
+		return fmt.Sprintf("c.Status(%s).JSON(", status)
+	case models.HTTPStdlib, models.HTTPChi:
+		return fmt.Sprintf("writeJSON(w, %s, ", status)
+	default:
+		return fmt.Sprintf("c.JSON(%s, ", status)
+	}
+}
+
+// errorCall returns the call writing a problem details response
+func (d *HTTPDialect) errorCall(status, message string) string {
+	if d.NetHTTP() {
+		return fmt.Sprintf("writeError(w, %s, %s)", status, message)
+	}
+	return fmt.Sprintf("writeError(c, %s, %s)", status, message)
+}
+
+// Return returns call as the final statement of a handler
+func (d *HTTPDialect) Return(call string) string {
+	if d.HandlerResult() != "" {
+		return "return " + call
+	}
+	return call
+}
+
+// Exit returns the statements running call and leaving the handler early
+func (d *HTTPDialect) Exit(call string) string {
+	if d.HandlerResult() != "" {
+		return "return " + call
+	}
+	return call + "\nreturn"
+}
+
+// Root returns the routes registered directly on the router parameter
+func (d *HTTPDialect) Root() *HTTPRouteGroup {
+	return &HTTPRouteGroup{dialect: d, receiver: "r"}
+}
+
+// Group returns a route group named name below prefix
+func (d *HTTPDialect) Group(name, prefix string) *HTTPRouteGroup {
+	// net/http and chi routes are registered with their full path, so that several
+	// setup functions can share a prefix without mounting conflicting sub-routers
+	if d.NetHTTP() {
+		return &HTTPRouteGroup{dialect: d, receiver: "r", prefix: prefix}
+	}
+	return &HTTPRouteGroup{dialect: d, receiver: name, prefix: prefix, declare: true}
+}
+
+// Mount returns the statement serving an http.Handler at path and every path below it. The
+// handler receives the full request path.
+func (d *HTTPDialect) Mount(path, handler string) string {
+	switch d.Framework {
+	case models.HTTPStdlib:
+		return fmt.Sprintf("r.Handle(%q, %s)", path+"/", handler)
+	case models.HTTPChi:
+		return fmt.Sprintf("r.Mount(%q, %s)", path, handler)
+	case models.HTTPFiber:
+		return fmt.Sprintf("r.All(%q, adaptor.HTTPHandler(%s))", path+"/*", handler)
+	case models.HTTPEcho:
+		return fmt.Sprintf("r.Any(%q, echo.WrapHandler(%s))", path+"/*", handler)
+	default:
+		return fmt.Sprintf("r.Any(%q, gin.WrapH(%s))", path+"/*path", handler)
+	}
+}
+
+// HTTPRouteGroup renders route registrations sharing a path prefix
+type HTTPRouteGroup struct {
+	dialect  *HTTPDialect
+	receiver string
+	prefix   string
+	declare  bool
+}
+
+// Open returns the statement declaring the group, empty when routes use their full path
+func (rg *HTTPRouteGroup) Open() string {
+	if !rg.declare {
+		return ""
+	}
+	open := fmt.Sprintf("%s := r.Group(%q)", rg.receiver, rg.prefix)
+	if rg.dialect.Framework == models.HTTPGin {
+		open += "\n{"
+	}
+	return open
+}
+
+// Close returns the statement closing the group
+func (rg *HTTPRouteGroup) Close() string {
+	if rg.declare && rg.dialect.Framework == models.HTTPGin {
+		return "}"
+	}
+	return ""
+}
+
+// Route returns the statement registering a handler function. Path parameters are written as :name.
+func (rg *HTTPRouteGroup) Route(method, path, handler string) string {
+	return rg.register(method, path, handler, false)
+}
+
+// Handle returns the statement registering an http.Handler value
+func (rg *HTTPRouteGroup) Handle(method, path, handler string) string {
+	return rg.register(method, path, handler, true)
+}
+
+var routeParamPattern = regexp.MustCompile(`:([A-Za-z_][A-Za-z0-9_]*)`)
+
+func (rg *HTTPRouteGroup) register(method, path, handler string, httpHandler bool) string {
+	method = strings.ToUpper(method)
+	d := rg.dialect
+
+	if !rg.declare {
+		path = rg.prefix + path
+		if path == "" {
+			path = "/"
+		}
+	}
+	if d.NetHTTP() {
+		path = routeParamPattern.ReplaceAllString(path, "{$1}")
+	}
+
+	switch d.Framework {
+	case models.HTTPStdlib:
+		if httpHandler {
+			return fmt.Sprintf("%s.Handle(%q, %s)", rg.receiver, method+" "+path, handler)
+		}
+		return fmt.Sprintf("%s.HandleFunc(%q, %s)", rg.receiver, method+" "+path, handler)
+	case models.HTTPChi:
+		if httpHandler {
+			return fmt.Sprintf("%s.Method(%q, %q, %s)", rg.receiver, method, path, handler)
+		}
+		return fmt.Sprintf("%s.%s(%q, %s)", rg.receiver, titleMethod(method), path, handler)
+	case models.HTTPFiber:
+		if httpHandler {
+			handler = fmt.Sprintf("adaptor.HTTPHandler(%s)", handler)
+		}
+		return fmt.Sprintf("%s.%s(%q, %s)", rg.receiver, titleMethod(method), path, handler)
+	case models.HTTPEcho:
+		if httpHandler {
+			handler = fmt.Sprintf("echo.WrapHandler(%s)", handler)
+		}
+		return fmt.Sprintf("%s.%s(%q, %s)", rg.receiver, method, path, handler)
+	default:
+		if httpHandler {
+			handler = fmt.Sprintf("gin.WrapH(%s)", handler)
+		}
+		return fmt.Sprintf("%s.%s(%q, %s)", rg.receiver, method, path, handler)
+	}
+}
+
+// titleMethod converts an HTTP method to the router method name used by chi and fiber
+func titleMethod(method string) string {
+	return method[:1] + strings.ToLower(method[1:])
+}
+
+// formatGoSource removes unused imports from generated Go code and formats it.
+// Handler templates import every package the target framework may need and rely
+// on this to drop the ones a file does not use.
+func formatGoSource(src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, 0)
+	if err != nil {
+		return nil, fmt.Errorf("generated code does not parse: %w", err)
+	}
+
+	formatted, err := format.Source(removeUnusedImports(fset, file, src))
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return formatted, nil
+}
+
+// removeUnusedImports deletes the lines of imports whose package name is never referenced
+func removeUnusedImports(fset *token.FileSet, file *ast.File, src []byte) []byte {
+	used := make(map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				used[ident.Name] = true
+			}
+		}
+		return true
+	})
+
+	type span struct{ start, end int }
+	var unused []span
+	for _, spec := range file.Imports {
+		name := importName(spec)
+		if name == "" || used[name] {
+			continue
+		}
+		start := fset.Position(spec.Pos()).Offset
+		end := fset.Position(spec.End()).Offset
+		// Only drop imports that sit on a line of their own
+		lineStart := bytes.LastIndexByte(src[:start], '\n') + 1
+		lineEnd := bytes.IndexByte(src[end:], '\n')
+		if lineEnd < 0 || strings.TrimSpace(string(src[lineStart:start])) != "" || strings.TrimSpace(string(src[end:end+lineEnd])) != "" {
+			continue
+		}
+		unused = append(unused, span{lineStart, end + lineEnd + 1})
+	}
+
+	for i := len(unused) - 1; i >= 0; i-- {
+		src = append(src[:unused[i].start:unused[i].start], src[unused[i].end:]...)
+	}
+	return src
+}
+
+var majorVersionPattern = regexp.MustCompile(`^v[0-9]+$`)
+
+// importName returns the package name an import is referenced by, or an empty
+// string for blank and dot imports and paths whose package name can't be derived
+func importName(spec *ast.ImportSpec) string {
+	if spec.Name != nil {
+		if spec.Name.Name == "_" || spec.Name.Name == "." {
+			return ""
+		}
+		return spec.Name.Name
+	}
+
+	path, err := strconv.Unquote(spec.Path.Value)
+	if err != nil {
+		return ""
+	}
+	parts := strings.Split(path, "/")
+	name := parts[len(parts)-1]
+	if majorVersionPattern.MatchString(name) && len(parts) > 1 {
+		name = parts[len(parts)-2]
+	}
+	if !token.IsIdentifier(name) {
+		return ""
+	}
+	return name
+}
+
+// HandlerTestData is the template data of the generated handler tests
+type HandlerTestData struct {
+	*EnhancedSchema
+	Payload     string
+	HasRequired bool
+}
+
+// problemFiles are the domain errors of a project and the problem details responses the
+// handlers answer errors with. They only depend on the module and HTTP framework.
+var problemFiles = []struct {
+	template string
+	path     string
+}{
+	{templates.AppErrorsTemplate, filepath.Join("internal", "apperrors", "apperrors.go")},
+	{templates.AppErrorsTestTemplate, filepath.Join("internal", "apperrors", "apperrors_test.go")},
+	{templates.ProblemsTemplate, filepath.Join("internal", "handlers", "problems.go")},
+}
+
+// generateHTTPSupport generates the route tests of a schema, the problem details responses
+// and, for frameworks other than Gin, the request binding and JSON response helpers
+func (g *SchemaGenerator) generateHTTPSupport(data *EnhancedSchema, outputPath string) error {
+	handlersDir := filepath.Join(outputPath, "internal", "handlers")
+
+	for _, file := range problemFiles {
+		if err := g.generateGoFile(file.template, data, filepath.Join(outputPath, file.path)); err != nil {
+			return fmt.Errorf("failed to generate problem details: %w", err)
+		}
+	}
+
+	if !data.HTTP.Validates() {
+		if err := g.generateHandlerFile(templates.HTTPHelpersTemplate, data, filepath.Join(handlersDir, "http_helpers.go")); err != nil {
+			return fmt.Errorf("failed to generate HTTP helpers: %w", err)
+		}
+		ui.PrintInfo("Handlers validate requests with github.com/go-playground/validator/v10, run 'go mod tidy' after generation")
+	}
+
+	testData := &HandlerTestData{EnhancedSchema: data, Payload: handlerTestPayload(data)}
+	for _, field := range data.Fields {
+		if field.Required && !field.ReadOnly {
+			testData.HasRequired = true
+		}
+	}
+	testPath := filepath.Join(handlersDir, data.Names.SnakeCase+"_handler_test.go")
+	if err := g.generateHandlerFile(templates.SchemaHandlerTestTemplate, testData, testPath); err != nil {
+		return fmt.Errorf("failed to generate handler test: %w", err)
+	}
+	return nil
+}
+
+// handlerTestPayload builds a request body that passes the binding rules of every framework
+func handlerTestPayload(data *EnhancedSchema) string {
+	var pairs []string
+	for _, field := range data.Fields {
+		if field.ReadOnly {
+			continue
+		}
+		value, ok := handlerTestValue(field.SchemaField)
+		if !ok {
+			continue
+		}
+		key, _ := json.Marshal(field.Names.SnakeCase)
+		pairs = append(pairs, string(key)+":"+value)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// handlerTestValue returns a JSON sample for a request field within its validation limits
+func handlerTestValue(field *models.SchemaField) (string, bool) {
+	validation := field.Validation
+	if validation == nil {
+		validation = &models.FieldValidation{}
+	}
+
+	quote := func(s string) string {
+		encoded, _ := json.Marshal(s)
+		return string(encoded)
+	}
+
+	if field.IsGeo() {
+		return `{"latitude":48.8566,"longitude":2.3522}`, true
+	}
+
+	switch fieldKind(field) {
+	case "secret":
+		return quote("s3cret-value"), true
+	case "enum":
+		return quote(validation.AllowedValues[0]), true
+	case "uuid":
+		return quote("00000000-0000-0000-0000-000000000001"), true
+	case "money":
+		amount, currency, _ := strings.Cut(moneySample(field), " ")
+		return fmt.Sprintf(`{"amount":%s,"currency":%s}`, quote(amount), quote(currency)), true
+	case "time":
+		return quote("2024-01-02T15:04:05Z"), true
+	case "json":
+		return `{"enabled":true}`, true
+	case "bool":
+		return "true", true
+	case "int", "float":
+		value := 1.0
+		if validation.Min != nil && *validation.Min > value {
+			value = math.Ceil(*validation.Min)
+		}
+		if validation.Max != nil && *validation.Max < value {
+			value = math.Floor(*validation.Max)
+		}
+		return strconv.FormatFloat(value, 'f', -1, 64), true
+	case "string":
+		switch field.Type {
+		case "email":
+			return quote("user@example.com"), true
+		case "url":
+			return quote("https://example.com"), true
+		}
+		value := "sample-" + toKebabCase(field.Name)
+		if validation.MinLength != nil {
+			for len(value) < *validation.MinLength {
+				value += "x"
+			}
+		}
+		if validation.MaxLength != nil && len(value) > *validation.MaxLength {
+			value = value[:*validation.MaxLength]
+		}
+		return quote(value), true
+	}
+	return "", false
+}
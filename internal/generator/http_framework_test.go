@@ -0,0 +1,148 @@
+package generator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vibercode/cli/internal/models"
+)
+
+func TestSchemaGenerator_HTTPFrameworks(t *testing.T) {
+	tests := []struct {
+		framework models.HTTPFramework
+		handler   []string
+		routes    []string
+		helpers   bool
+	}{
+		{
+			framework: models.HTTPGin,
+			handler:   []string{"func (h *ProductHandler) Create(c *gin.Context) {", "c.ShouldBindJSON(&req)"},
+			routes:    []string{`products := r.Group("/products")`, `products.POST("/bulk", handler.BulkCreate)`, `r.GET("/products/export", handler.Export)`},
+		},
+		{
+			framework: models.HTTPStdlib,
+			handler:   []string{"func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {", `r.PathValue("id")`},
+			routes:    []string{`r.HandleFunc("POST /products/bulk", handler.BulkCreate)`, `r.HandleFunc("GET /products/export", handler.Export)`},
+			helpers:   true,
+		},
+		{
+			framework: models.HTTPChi,
+			handler:   []string{"func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {", `chi.URLParam(r, "id")`},
+			routes:    []string{`r.Post("/products/bulk", handler.BulkCreate)`, `r.Get("/products/export", handler.Export)`},
+			helpers:   true,
+		},
+		{
+			framework: models.HTTPEcho,
+			handler:   []string{"func (h *ProductHandler) Create(c echo.Context) error {", "return c.JSON(http.StatusCreated"},
+			routes:    []string{`products := r.Group("/products")`, `products.POST("/bulk", handler.BulkCreate)`, `r.GET("/products/export", handler.Export)`},
+			helpers:   true,
+		},
+		{
+			framework: models.HTTPFiber,
+			handler:   []string{"func (h *ProductHandler) Create(c *fiber.Ctx) error {", "c.Context().SetBodyStreamWriter"},
+			routes:    []string{`products.Post("/bulk", handler.BulkCreate)`, `r.Get("/products/export", handler.Export)`},
+			helpers:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.framework), func(t *testing.T) {
+			schema := newTestProductSchema()
+			gen := NewSchemaGenerator(newMemorySchemaStorage(schema)).
+				WithFeatures(models.FeatureBulk, models.FeatureExport).
+				WithHTTPFramework(tt.framework)
+			dir := generateTestProject(t, gen, "postgres", schema)
+
+			var handlers strings.Builder
+			for _, file := range []string{"product_handler.go", "product_bulk_handler.go", "product_export_handler.go"} {
+				handlers.WriteString(readGeneratedFile(t, dir, "internal/handlers/"+file))
+			}
+			for _, snippet := range append(tt.handler, tt.routes...) {
+				assert.Contains(t, handlers.String(), snippet)
+			}
+			if tt.framework != models.HTTPGin {
+				assert.NotContains(t, handlers.String(), "gin-gonic")
+			}
+
+			assertGeneratedFiles(t, dir,
+				generatedFile{path: "internal/handlers/product_handler_test.go"},
+				generatedFile{path: "internal/handlers/http_helpers.go", missing: !tt.helpers},
+			)
+
+			assertGoFilesParse(t, filepath.Join(dir, "internal"))
+		})
+	}
+}
+
+func TestAPIGenerator_HTTPFramework(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "shop")
+	project := &APIProject{
+		Name:      dir,
+		Port:      "8080",
+		Database:  &models.DatabaseProvider{Type: "postgres"},
+		Module:    "example.com/shop",
+		Framework: models.HTTPStdlib,
+	}
+
+	gen := NewAPIGenerator().WithHTTPFramework(models.HTTPStdlib)
+	require.NoError(t, gen.createProjectStructure(project))
+	for _, fn := range []func(*APIProject) error{gen.generateGoMod, gen.generateMain, gen.generateHandlers, gen.generateMiddleware, gen.generateManifest} {
+		require.NoError(t, fn(project))
+	}
+
+	assertGeneratedFiles(t, dir,
+		generatedFile{path: "go.mod", contains: []string{"go 1.22"}, excludes: []string{"gin-gonic"}},
+		generatedFile{
+			path:     "internal/handlers/routes.go",
+			contains: []string{"func SetupRoutes(r *http.ServeMux, db *gorm.DB) {", `r.HandleFunc("GET /example", example)`},
+		},
+		generatedFile{
+			path:     "cmd/server/main.go",
+			contains: []string{`mux.Handle("/api/v1/", http.StripPrefix("/api/v1", api))`},
+		},
+		generatedFile{path: "internal/handlers/http_helpers.go"},
+		generatedFile{path: "internal/middleware/middleware.go"},
+	)
+	assertGoFilesParse(t, dir)
+
+	manifest, err := LoadManifest(dir)
+	require.NoError(t, err)
+	assert.Equal(t, models.HTTPStdlib, manifest.HTTPFramework)
+}
+
+func TestSchemaGenerator_ProblemDetails(t *testing.T) {
+	product := newTestProductSchema()
+	gen := NewSchemaGenerator(newMemorySchemaStorage(product)).WithHTTPFramework(models.HTTPGin)
+	dir := generateTestProject(t, gen, "postgres", product)
+
+	assertGeneratedFiles(t, dir,
+		generatedFile{path: "internal/apperrors/apperrors.go"},
+		generatedFile{path: "internal/apperrors/apperrors_test.go"},
+		generatedFile{path: "internal/handlers/problems.go", contains: []string{"func writeProblem(c *gin.Context, err error) {"}},
+		// Validation reports every invalid field with its JSON name
+		generatedFile{
+			path: "internal/models/product.go",
+			contains: []string{
+				`fields = append(fields, apperrors.Field("sku", "required", "SKU is required"))`,
+				"return apperrors.Invalid(fields...)",
+			},
+		},
+		generatedFile{
+			path:     "internal/services/product_service.go",
+			contains: []string{`apperrors.Conflict("SKU already exists")`, `apperrors.FromDatabase(err, "Product")`},
+		},
+		generatedFile{
+			path:     "internal/handlers/product_handler.go",
+			contains: []string{"writeProblem(c, apperrors.FromBinding(err, &req))", "writeProblem(c, err)"},
+		},
+		generatedFile{
+			path:     "docs/openapi/v1.json",
+			contains: []string{`"application/problem+json"`, `"$ref": "#/components/responses/Conflict"`},
+		},
+	)
+
+	assertGoFilesParse(t, filepath.Join(dir, "internal"))
+}
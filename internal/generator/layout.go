@@ -0,0 +1,670 @@
+package generator
+
+import (
+	"go/ast"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/vibercode/cli/internal/models"
+)
+
+// projectLayout places the packages of the files generated in a project according to its
+// layout preset. Files are generated for the standard layout, then written in the directory
+// of their package with their package clause, imports and qualified identifiers rewritten,
+// so that templates and features do not know about layouts.
+type projectLayout struct {
+	preset models.ProjectLayout
+	root   string // Project directory
+	module string
+
+	// Resources the layouts packaging by feature give a package, and the resource being
+	// generated, whose package gets a copy of the helpers of the packages it is split from
+	resources []*models.NamingConventions
+	current   *models.NamingConventions
+}
+
+// newProjectLayout returns the layout of the project in root, nil for the standard layout
+func newProjectLayout(preset models.ProjectLayout, root, module string) *projectLayout {
+	if preset.OrDefault() == models.LayoutStandard || !preset.IsValid() {
+		return nil
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil
+	}
+	if module == "" {
+		module = readGoModule(root)
+	}
+	return &projectLayout{preset: preset, root: root, module: module}
+}
+
+// findProjectLayout returns the layout recorded in the manifest of the project containing
+// dir, nil when the project uses the standard layout or has no manifest
+func findProjectLayout(dir string) *projectLayout {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".vibercode", "manifest.vibe")); err == nil {
+			manifest, err := LoadManifest(dir)
+			if err != nil {
+				return nil
+			}
+			layout := newProjectLayout(manifest.Layout, dir, manifest.Module)
+			if layout != nil && layout.preset.ByFeature() {
+				layout.resources = manifestResources(dir, manifest)
+			}
+			return layout
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil
+		}
+		dir = parent
+	}
+}
+
+// manifestResources returns the names of the resources the manifest of a project records
+func manifestResources(root string, manifest *VibercodeManifest) []*models.NamingConventions {
+	var resources []*models.NamingConventions
+	if schemas, err := projectSchemas(root, manifest); err == nil {
+		for _, schema := range schemas {
+			resources = append(resources, schema.Names)
+		}
+		return resources
+	}
+	// The names of the resources without a readable snapshot follow their schema name
+	seen := make(map[string]bool)
+	for _, version := range manifest.APIVersions {
+		for _, resource := range version.Resources {
+			if !seen[resource.Name] {
+				seen[resource.Name] = true
+				resources = append(resources, models.CreateResourceNames(resource.Name))
+			}
+		}
+	}
+	return resources
+}
+
+// withResource adds the resource being generated to the resources of a layout packaging by
+// feature, the manifest only records it once its files are generated
+func (l *projectLayout) withResource(names *models.NamingConventions) *projectLayout {
+	if l == nil || names == nil || !l.preset.ByFeature() {
+		return l
+	}
+	l.current = names
+	for _, resource := range l.resources {
+		if resource.SnakeCase == names.SnakeCase {
+			return l
+		}
+	}
+	l.resources = append(l.resources, names)
+	return l
+}
+
+// layoutPath returns the path of a file of the standard layout in the project at outputPath,
+// e.g. internal/repositories/product_repository.go, in the layout of the project
+func layoutPath(outputPath string, elem ...string) string {
+	path := filepath.Join(append([]string{outputPath}, elem...)...)
+	if layout := findProjectLayout(outputPath); layout != nil {
+		return layout.path(path)
+	}
+	return path
+}
+
+// path returns where a file generated at path for the standard layout is placed
+func (l *projectLayout) path(file string) string {
+	pkg, rest, ok := l.standardFile(file)
+	if !ok {
+		return file
+	}
+	if dir, ok := l.featurePath(pkg, rest); ok {
+		return filepath.Join(l.root, filepath.FromSlash(dir))
+	}
+	// Files of the package named after a resource alone, e.g. events/product.go,
+	// would collide with the files of the packages merged with it
+	if !l.preset.Layered() && slices.Contains(prefixedPackages, pkg) && path.Ext(rest) != "" &&
+		strings.Count(rest, "/") == 1 && !strings.HasPrefix(rest, "/"+pkg) {
+		rest = "/" + pkg + "_" + rest[1:]
+	}
+	return filepath.Join(l.root, filepath.FromSlash(l.preset.Dir(pkg)+rest))
+}
+
+// standardFile returns the package of the standard layout a file generated at path for the
+// standard layout belongs to, and its slash separated path in the package directory
+func (l *projectLayout) standardFile(file string) (string, string, bool) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return "", "", false
+	}
+	rel, err := filepath.Rel(l.root, abs)
+	if err != nil {
+		return "", "", false
+	}
+	rel = filepath.ToSlash(rel)
+	for _, pkg := range models.LayoutPackages() {
+		standard := "internal/" + pkg
+		if rel == standard || strings.HasPrefix(rel, standard+"/") {
+			return pkg, strings.TrimPrefix(rel, standard), true
+		}
+	}
+	return "", "", false
+}
+
+// featurePath returns the slash separated path, relative to the project, of a file of the
+// repositories, services or handlers packages in layouts packaging by feature, given its path
+// in the package. Files named after a resource go to the package of the resource without its
+// name, e.g. repositories/product_cache_repository.go to product/cache_repo.go, and the
+// loaders of included relations go to the common package of the models.
+func (l *projectLayout) featurePath(pkg, rest string) (string, bool) {
+	if !l.preset.ByFeature() || !slices.Contains(models.FeaturePackages(), pkg) {
+		return "", false
+	}
+	dir, base := path.Split(strings.TrimPrefix(rest, "/"))
+	if pkg == models.PackageRepositories && dir == "" && strings.HasSuffix(base, "_includes.go") {
+		return l.preset.Dir(models.PackageModels) + "/" + base, true
+	}
+	names := l.fileResource(base)
+	if names == nil {
+		return "", false
+	}
+	// Files in subdirectories, such as the SQL queries, keep their name
+	if dir == "" {
+		base = strings.Replace(strings.TrimPrefix(base, names.SnakeCase+"_"), "repository", "repo", 1)
+	}
+	return l.preset.ResourceDir(names) + "/" + dir + base, true
+}
+
+// fileResource returns the resource a file is named after, the longest name matching
+func (l *projectLayout) fileResource(base string) *models.NamingConventions {
+	name := strings.TrimSuffix(base, path.Ext(base))
+	var found *models.NamingConventions
+	for _, names := range l.resources {
+		if (name == names.SnakeCase || strings.HasPrefix(name, names.SnakeCase+"_")) &&
+			(found == nil || len(names.SnakeCase) > len(found.SnakeCase)) {
+			found = names
+		}
+	}
+	return found
+}
+
+// declResource returns the resource an identifier is named after, e.g. Product for
+// NewCachedProductRepository: the resource named first, the longest name matching
+func (l *projectLayout) declResource(name string) *models.NamingConventions {
+	var found *models.NamingConventions
+	first := len(name)
+	for _, names := range l.resources {
+		pascal := names.PascalCase
+		for from := 0; from < len(name); {
+			i := strings.Index(name[from:], pascal)
+			if i < 0 {
+				break
+			}
+			i += from
+			end := i + len(pascal)
+			if end == len(name) || unicode.IsUpper(rune(name[end])) {
+				if i < first || (i == first && len(pascal) > len(found.PascalCase)) {
+					found, first = names, i
+				}
+				break
+			}
+			from = i + 1
+		}
+	}
+	return found
+}
+
+// declDir returns the slash separated directory, relative to the project, of the package
+// an identifier declared by a package of the standard layout is placed in
+func (l *projectLayout) declDir(pkg, name string) string {
+	if !l.preset.ByFeature() || !slices.Contains(models.FeaturePackages(), pkg) {
+		return l.preset.Dir(pkg)
+	}
+	if pkg == models.PackageRepositories && (strings.HasSuffix(name, "Includes") || strings.HasSuffix(name, "Includer")) {
+		return l.preset.Dir(models.PackageModels)
+	}
+	if names := l.declResource(name); names != nil {
+		return l.preset.ResourceDir(names)
+	}
+	return l.preset.Dir(pkg)
+}
+
+// isHelper reports whether a Go file declares unexported identifiers only, such as the
+// problem details responses of the handlers. Layouts packaging by feature copy the helpers
+// of the packages they split to the packages of the resources.
+func isHelper(content []byte) bool {
+	source, err := parseGoSource("", content)
+	if err != nil {
+		return false
+	}
+	for _, decl := range source.file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Recv == nil && decl.Name.IsExported() {
+				return false
+			}
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					if spec.Name.IsExported() {
+						return false
+					}
+				case *ast.ValueSpec:
+					for _, name := range spec.Names {
+						if name.IsExported() {
+							return false
+						}
+					}
+				}
+			}
+		}
+	}
+	return true
+}
+
+// prefixedPackages are the packages whose files are prefixed with the package name in the
+// layouts merging packages
+var prefixedPackages = []string{models.PackageEvents, models.PackageWebhooks, models.PackageGraphQL}
+
+// placedFile is a generated file placed in the layout of its project
+type placedFile struct {
+	path    string
+	content []byte
+}
+
+// place returns the files a generated file is written to in the layout: the file in the
+// directory of its package, rewritten for the package when it is a Go file, followed in
+// layouts with a ports package by the file of the ports it declares
+func (l *projectLayout) place(file string, content []byte) ([]placedFile, error) {
+	if helpers := l.helperPaths(file, content); helpers != nil {
+		var placed []placedFile
+		for _, path := range helpers {
+			rewritten, err := l.rewrite(path, content)
+			if err != nil {
+				return nil, err
+			}
+			placed = append(placed, placedFile{path: path, content: rewritten})
+		}
+		return placed, nil
+	}
+
+	content, ports, err := l.splitPorts(file, content)
+	if err != nil {
+		return nil, err
+	}
+	file = l.path(file)
+	if content, err = l.rewrite(file, content); err != nil {
+		return nil, err
+	}
+	placed := []placedFile{{path: file, content: content}}
+
+	if ports != nil {
+		path := filepath.Join(l.root, filepath.FromSlash(l.preset.PortsDir()), filepath.Base(file))
+		if ports, err = l.rewrite(path, ports); err != nil {
+			return nil, err
+		}
+		placed = append(placed, placedFile{path: path, content: ports})
+	}
+	return placed, nil
+}
+
+// helperPaths returns where layouts packaging by feature place a helper of the packages they
+// split: in the package of the resource being generated, and in the handlers package, whose
+// shared handlers use the helpers too. It returns nil for the other files.
+func (l *projectLayout) helperPaths(file string, content []byte) []string {
+	if !l.preset.ByFeature() || filepath.Ext(file) != ".go" {
+		return nil
+	}
+	pkg, rest, ok := l.standardFile(file)
+	if !ok || !slices.Contains(models.FeaturePackages(), pkg) || strings.Count(rest, "/") != 1 {
+		return nil
+	}
+	if _, ok := l.featurePath(pkg, rest); ok || !isHelper(content) {
+		return nil
+	}
+
+	var paths []string
+	if l.current != nil {
+		paths = append(paths, filepath.Join(l.root, filepath.FromSlash(l.preset.ResourceDir(l.current)+rest)))
+	}
+	if l.current == nil || pkg == models.PackageHandlers {
+		paths = append(paths, filepath.Join(l.root, filepath.FromSlash(l.preset.Dir(pkg)+rest)))
+	}
+	return paths
+}
+
+// portPackages are the packages of the standard layout declaring ports
+var portPackages = []string{models.PackageRepositories, models.PackageServices}
+
+// isPort reports whether a type declared by the repositories and services packages is a
+// port, an interface the other packages use them through: the repository and service
+// interfaces and the loaders of included relations
+func isPort(name string) bool {
+	return strings.HasSuffix(name, "RepositoryInterface") || strings.HasSuffix(name, "ServiceInterface") || strings.HasSuffix(name, "Includer")
+}
+
+// splitPorts moves the ports a Go file of the repositories and services packages declares
+// to a file of the ports package when the layout has one. It returns the file without the
+// ports and the file of the ports, nil when there are none.
+func (l *projectLayout) splitPorts(file string, content []byte) ([]byte, []byte, error) {
+	if l.preset.PortsDir() == "" || filepath.Ext(file) != ".go" {
+		return content, nil, nil
+	}
+	source, err := parseGoSource(file, content)
+	if err != nil || !slices.Contains(portPackages, source.file.Name.Name) {
+		return content, nil, nil
+	}
+
+	ports := []string{"package " + path.Base(l.preset.PortsDir())}
+	for _, decl := range source.file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		// The ports keep the imports of the file, formatting drops those they do not use
+		if gen.Tok == token.IMPORT {
+			ports = append(ports, string(content[source.offset(gen.Pos()):source.offset(gen.End())]))
+			continue
+		}
+		if gen.Tok != token.TYPE || len(gen.Specs) != 1 {
+			continue
+		}
+		spec := gen.Specs[0].(*ast.TypeSpec)
+		if _, ok := spec.Type.(*ast.InterfaceType); !ok || !isPort(spec.Name.Name) {
+			continue
+		}
+		start := gen.Pos()
+		if gen.Doc != nil {
+			start = gen.Doc.Pos()
+		}
+		ports = append(ports, string(content[source.offset(start):source.offset(gen.End())]))
+		source.edits = append(source.edits, &goEdit{offset: source.offset(start), end: source.offset(gen.End())})
+	}
+	if len(source.edits) == 0 {
+		return content, nil, nil
+	}
+
+	if content, err = source.source(); err != nil {
+		return nil, nil, err
+	}
+	if content, err = formatGoSource(content); err != nil {
+		return nil, nil, err
+	}
+	portsContent, err := formatGoSource([]byte(strings.Join(ports, "\n\n") + "\n"))
+	if err != nil {
+		return nil, nil, err
+	}
+	return content, portsContent, nil
+}
+
+// rewrite rewrites a Go file placed at path for the layout: the package clause of the
+// packages of the standard layout is renamed after their directory, and their imports
+// are replaced by the imports of the layout packages, or dropped along with their
+// qualifiers when the layout places them in the package of the file
+func (l *projectLayout) rewrite(file string, content []byte) ([]byte, error) {
+	if filepath.Ext(file) != ".go" {
+		return content, nil
+	}
+	source, err := parseGoSource(file, content)
+	if err != nil {
+		// Files that do not parse are written as generated, the compiler reports them
+		return content, nil
+	}
+
+	dir, err := filepath.Rel(l.root, filepath.Dir(file))
+	if err != nil {
+		return content, nil
+	}
+	dir = filepath.ToSlash(dir)
+	own := l.module + "/" + dir
+
+	// The package clause
+	name := source.file.Name.Name
+	standard := strings.TrimSuffix(name, "_test")
+	external := standard != name
+	if slices.Contains(models.LayoutPackages(), standard) && l.isPackageDir(dir) && path.Base(dir) != standard {
+		source.replace(source.file.Name, path.Base(dir)+strings.TrimPrefix(name, standard))
+	}
+
+	// Names the file declares or imports, which the imported packages of the layout must not shadow
+	declared := make(map[string]bool)
+	ast.Inspect(source.file, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && ident.Obj != nil {
+			declared[ident.Name] = true
+		}
+		return true
+	})
+	imported := make(map[string]string) // Import path of the packages kept to their name in the file
+	for _, spec := range source.file.Imports {
+		value, _ := strconv.Unquote(spec.Path.Value)
+		if _, ok := l.standardPackage(value); !ok {
+			imported[value] = importName(spec)
+			declared[imported[value]] = true
+		}
+	}
+
+	// The standard packages the file imports, by their name in the file
+	standards := make(map[string]string)
+	specs := make(map[string]*ast.ImportSpec)
+	specDecls := make(map[*ast.ImportSpec]*ast.GenDecl)
+	// Names of the standard packages declaring ports
+	portQualifiers := make(map[string]bool)
+	for _, decl := range source.file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			spec, ok := spec.(*ast.ImportSpec)
+			if !ok {
+				continue
+			}
+			value, _ := strconv.Unquote(spec.Path.Value)
+			pkg, ok := l.standardPackage(value)
+			if !ok {
+				continue
+			}
+			if spec.Name != nil && (spec.Name.Name == "_" || spec.Name.Name == ".") {
+				source.replace(spec.Path, strconv.Quote(l.module+"/"+l.preset.Dir(pkg)))
+				continue
+			}
+			old := importName(spec)
+			standards[old] = pkg
+			specs[old] = spec
+			specDecls[spec] = gen
+			portQualifiers[old] = slices.Contains(portPackages, pkg)
+		}
+	}
+
+	// importAs returns the name the file refers to a layout package with, empty in the package
+	// of the file. The package is imported in place of spec when spec is not nil.
+	importAs := func(target string, spec *ast.ImportSpec) string {
+		switch {
+		case target == own && !external:
+			return ""
+		case imported[target] != "":
+			return imported[target]
+		}
+		name, alias := path.Base(target), ""
+		if declared[name] {
+			name += "pkg"
+			alias = name
+		}
+		if spec != nil {
+			text := strconv.Quote(target)
+			if alias != "" {
+				text = alias + " " + text
+			}
+			source.replace(spec, text)
+		} else {
+			source.addNamedImport(alias, target)
+		}
+		imported[target] = name
+		declared[name] = true
+		return name
+	}
+
+	// The layout packages the identifiers of each standard package are placed in, which
+	// layouts packaging by feature split
+	targetOf := func(selector *ast.SelectorExpr) (string, string, bool) {
+		x, ok := selector.X.(*ast.Ident)
+		if !ok || x.Obj != nil {
+			return "", "", false
+		}
+		pkg, ok := standards[x.Name]
+		if !ok {
+			return "", "", false
+		}
+		return x.Name, l.module + "/" + l.declDir(pkg, selector.Sel.Name), true
+	}
+	targets := make(map[string][]string)
+	ast.Inspect(source.file, func(n ast.Node) bool {
+		if selector, ok := n.(*ast.SelectorExpr); ok {
+			if old, target, ok := targetOf(selector); ok && !slices.Contains(targets[old], target) {
+				targets[old] = append(targets[old], target)
+			}
+		}
+		return true
+	})
+
+	// The name of the layout packages in the file, by standard package. The first package the
+	// file does not import yet takes the place of the import of the standard package.
+	qualifiers := make(map[string]map[string]string)
+	for _, spec := range source.file.Imports {
+		old := importName(spec)
+		if specs[old] != spec {
+			continue
+		}
+		if len(targets[old]) == 0 {
+			targets[old] = []string{l.module + "/" + l.preset.Dir(standards[old])}
+		}
+		qualifiers[old] = make(map[string]string)
+		replaced := false
+		for _, target := range targets[old] {
+			if !replaced && (target != own || external) && imported[target] == "" {
+				qualifiers[old][target] = importAs(target, spec)
+				replaced = true
+				continue
+			}
+			qualifiers[old][target] = importAs(target, nil)
+		}
+		if !replaced {
+			source.deleteImport(specDecls[spec], spec)
+		}
+	}
+
+	// Layouts with a ports package refer to the ports of the repositories and services
+	// packages through it, the files of these packages included
+	ported := make(map[*ast.SelectorExpr]bool)
+	if portsDir := l.preset.PortsDir(); portsDir != "" && dir != portsDir {
+		portsName := path.Base(portsDir)
+		alias := ""
+		if declared[portsName] {
+			portsName += "pkg"
+			alias = portsName
+		}
+		declaresPorts := slices.Contains(portPackages, standard)
+		fields := make(map[*ast.Ident]bool) // Selected and keyed fields, embedded ports keep their name
+		referenced := false
+		ast.Inspect(source.file, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.SelectorExpr:
+				if x, ok := n.X.(*ast.Ident); ok && x.Obj == nil && portQualifiers[x.Name] && isPort(n.Sel.Name) {
+					source.replace(n, portsName+"."+n.Sel.Name)
+					ported[n] = true
+					referenced = true
+					return false
+				}
+				fields[n.Sel] = true
+			case *ast.KeyValueExpr:
+				if key, ok := n.Key.(*ast.Ident); ok {
+					fields[key] = true
+				}
+			case *ast.Ident:
+				if declaresPorts && n.Obj == nil && !fields[n] && isPort(n.Name) {
+					source.add(source.offset(n.Pos()), portsName+".")
+					referenced = true
+				}
+			}
+			return true
+		})
+		if referenced {
+			source.addNamedImport(alias, l.module+"/"+portsDir)
+		}
+	}
+
+	ast.Inspect(source.file, func(n ast.Node) bool {
+		selector, ok := n.(*ast.SelectorExpr)
+		if !ok || ported[selector] {
+			return true
+		}
+		old, target, ok := targetOf(selector)
+		if !ok {
+			return true
+		}
+		switch qualifier := qualifiers[old][target]; qualifier {
+		case old:
+		case "":
+			source.edits = append(source.edits, &goEdit{offset: source.offset(selector.X.Pos()), end: source.offset(selector.Sel.Pos())})
+		default:
+			source.replace(selector.X, qualifier)
+		}
+		return true
+	})
+
+	// Layouts packaging by feature split the package of the file, the identifiers it declares
+	// are qualified with the package they are placed in
+	if l.preset.ByFeature() && slices.Contains(models.FeaturePackages(), standard) {
+		for _, ident := range source.file.Unresolved {
+			if !ident.IsExported() {
+				continue
+			}
+			target := l.module + "/" + l.declDir(standard, ident.Name)
+			if target == own {
+				continue
+			}
+			source.add(source.offset(ident.Pos()), importAs(target, nil)+".")
+		}
+	}
+
+	if len(source.edits) == 0 {
+		return content, nil
+	}
+	content, err = source.source()
+	if err != nil || (len(ported) == 0 && !l.preset.ByFeature()) {
+		return content, err
+	}
+	// The packages referred to for their ports only are no longer used, the imports added
+	// for the packages of the resources are sorted
+	return formatGoSource(content)
+}
+
+// isPackageDir reports whether a directory holds a layout package or, in layouts packaging
+// by feature, the package of a resource
+func (l *projectLayout) isPackageDir(dir string) bool {
+	for _, pkg := range models.LayoutPackages() {
+		if l.preset.Dir(pkg) == dir {
+			return true
+		}
+	}
+	for _, names := range l.resources {
+		if l.preset.ResourceDir(names) == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// standardPackage returns the package of the standard layout an import path names
+func (l *projectLayout) standardPackage(importPath string) (string, bool) {
+	pkg, ok := strings.CutPrefix(importPath, l.module+"/internal/")
+	return pkg, ok && slices.Contains(models.LayoutPackages(), pkg)
+}
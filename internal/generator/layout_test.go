@@ -0,0 +1,185 @@
+package generator
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vibercode/cli/internal/models"
+)
+
+func newLayoutTestProject(t *testing.T, layout models.ProjectLayout, provider string) string {
+	dir := filepath.Join(t.TempDir(), "shop")
+	project := &APIProject{
+		Name:      dir,
+		Port:      "8080",
+		Database:  &models.DatabaseProvider{Type: provider},
+		Module:    "example.com/shop",
+		Framework: models.HTTPGin,
+		Layout:    layout,
+	}
+	gen := NewAPIGenerator().WithHTTPFramework(models.HTTPGin).WithLayout(layout)
+	require.NoError(t, gen.createProjectStructure(project))
+	for _, fn := range []func(*APIProject) error{gen.generateGoMod, gen.generateMain, gen.generateHandlers, gen.generateMiddleware, gen.generateManifest} {
+		require.NoError(t, fn(project))
+	}
+	return dir
+}
+
+func TestProjectLayout_Hexagonal(t *testing.T) {
+	dir := newLayoutTestProject(t, models.LayoutHexagonal, "postgres")
+	schema := newTestProductSchema()
+	gen := NewSchemaGenerator(newMemorySchemaStorage(schema)).WithHTTPFramework(models.HTTPGin)
+	require.NoError(t, gen.GenerateFromSchema(schema.ID, dir, "example.com/shop", "postgres"))
+
+	assertGeneratedFiles(t, dir,
+		generatedFile{path: "internal/domain/product.go", contains: []string{"package domain\n"}},
+		generatedFile{
+			path:     "internal/adapters/persistence/product_repository.go",
+			contains: []string{"package persistence\n"},
+			excludes: []string{"type ProductRepositoryInterface interface"},
+		},
+		generatedFile{
+			path:     "internal/ports/product_repository.go",
+			contains: []string{"package ports\n", "type ProductRepositoryInterface interface", "*domain.Product"},
+		},
+		generatedFile{path: "internal/ports/product_service.go", contains: []string{"type ProductServiceInterface interface"}},
+		generatedFile{
+			path: "internal/application/product_service.go",
+			contains: []string{
+				"package application\n",
+				`"example.com/shop/internal/ports"`,
+				`"example.com/shop/internal/domain"`,
+				"ports.ProductRepositoryInterface",
+			},
+			// the application depends on the ports, not on the adapters
+			excludes: []string{"internal/adapters", "repositories."},
+		},
+		generatedFile{
+			path:     "internal/adapters/rest/product_handler.go",
+			contains: []string{"package rest\n", "ports.ProductServiceInterface"},
+		},
+		generatedFile{path: "internal/adapters/rest/product_handler_test.go", contains: []string{"package rest\n"}},
+		generatedFile{path: "cmd/server/main.go", contains: []string{"rest.SetupRoutes("}},
+	)
+
+	assert.NoDirExists(t, filepath.Join(dir, "internal", "models"))
+	assert.NoDirExists(t, filepath.Join(dir, "internal", "handlers"))
+	assertGoFilesParse(t, dir)
+
+	manifest, err := LoadManifest(dir)
+	require.NoError(t, err)
+	assert.Equal(t, models.LayoutHexagonal, manifest.Layout)
+	assert.Equal(t, filepath.Join(dir, "internal", "adapters", "persistence", "product_repository.go"), layoutPath(dir, "internal", "repositories", "product_repository.go"))
+}
+
+func TestProjectLayout_Flat(t *testing.T) {
+	dir := newLayoutTestProject(t, models.LayoutFlat, "postgres")
+	schema := newTestProductSchema()
+	gen := NewSchemaGenerator(newMemorySchemaStorage(schema)).WithHTTPFramework(models.HTTPGin)
+	require.NoError(t, gen.GenerateFromSchema(schema.ID, dir, "example.com/shop", "postgres"))
+
+	assertGeneratedFiles(t, dir,
+		generatedFile{
+			path:     "internal/app/product_handler.go",
+			contains: []string{"package app\n", "h.service.Create("},
+			excludes: []string{"models.", `"example.com/shop/internal/services"`},
+		},
+		generatedFile{path: "internal/app/product_repository.go"},
+	)
+	assertGoFilesParse(t, dir)
+
+	// Events, webhooks and GraphQL are merged too, their files prefixed not to collide
+	features := NewSchemaGenerator(newMemorySchemaStorage(schema)).WithHTTPFramework(models.HTTPGin).
+		WithFeatures(models.FeatureEvents, models.FeatureWebhooks, models.FeatureGraphQL)
+	require.NoError(t, features.GenerateFromSchema(schema.ID, dir, "example.com/shop", "postgres"))
+
+	assertGeneratedFiles(t, dir,
+		generatedFile{path: "internal/app/events.go", contains: []string{"package app\n", "type OutboxStore interface"}},
+		generatedFile{path: "internal/app/events_product.go", contains: []string{"package app\n"}},
+		generatedFile{path: "internal/app/events_relay_test.go", contains: []string{"package app\n"}},
+		generatedFile{path: "internal/app/schemas/product.json"},
+		generatedFile{path: "internal/app/webhooks_product.go", contains: []string{"package app\n"}},
+		generatedFile{path: "internal/app/graphql_product_resolver.go", contains: []string{"package app\n"}, excludes: []string{"services.", "models."}},
+		generatedFile{path: "internal/app/schema/product.graphql"},
+		generatedFile{
+			path:     "internal/app/product_service.go",
+			contains: []string{"transactor TransactionRunner"},
+			excludes: []string{"events.", `"example.com/shop/internal/events"`},
+		},
+	)
+	assertGoFilesParse(t, dir)
+
+	assert.Error(t, NewAdminGenerator().Generate(AdminOptions{OutputPath: dir}))
+}
+
+func TestProjectLayout_Feature(t *testing.T) {
+	dir := newLayoutTestProject(t, models.LayoutFeature, "mongodb")
+	category := &models.ResourceSchema{
+		ID:          "category-1",
+		Name:        "Category",
+		DisplayName: "Category",
+		Names:       models.CreateResourceNames("Category"),
+		Fields: []models.SchemaField{
+			{Name: "name", Type: "string", DisplayName: "Name", Required: true},
+			{Name: "products", Type: "relation_array", DisplayName: "Products", Relation: &models.RelationConfig{Type: "one_to_many", Target: "Product", ForeignKey: "category_id", Populate: true, PopulateDepth: 2}},
+		},
+		Database: &models.DatabaseConfig{Provider: "mongodb", TableName: "categories"},
+	}
+	product := newTestProductSchema()
+	product.Fields = append(product.Fields,
+		models.SchemaField{Name: "category_id", Type: "string", DisplayName: "Category ID"},
+		models.SchemaField{Name: "category", Type: "relation", DisplayName: "Category", Relation: &models.RelationConfig{Type: "many_to_one", Target: "Category", ForeignKey: "category_id", Populate: true}},
+	)
+	schemas := newMemorySchemaStorage(category, product)
+
+	gen := NewSchemaGenerator(schemas).WithHTTPFramework(models.HTTPGin)
+	require.NoError(t, gen.GenerateFromSchema(category.ID, dir, "example.com/shop", "mongodb"))
+	require.NoError(t, NewAddGenerator().Add(AddOptions{OutputPath: dir, Feature: AddResource, Name: "Product", Schemas: schemas}))
+	// Categories are generated again for the relation to products
+	require.NoError(t, gen.GenerateFromSchema(category.ID, dir, "example.com/shop", "mongodb"))
+
+	assertGeneratedFiles(t, dir,
+		generatedFile{path: "internal/product/repo.go", contains: []string{"package product\n", "type ProductRepositoryInterface interface", "*common.Product"}},
+		generatedFile{
+			path:     "internal/product/service.go",
+			contains: []string{"package product\n", "repo ProductRepositoryInterface", "common.ProductIncluder", `"example.com/shop/internal/common"`},
+			excludes: []string{"repositories.", "models."},
+		},
+		generatedFile{path: "internal/product/handler.go", contains: []string{"package product\n", "service ProductServiceInterface"}},
+		generatedFile{path: "internal/product/handler_test.go", contains: []string{"package product\n"}},
+		// Helpers are copied to the packages of the resources, the shared handlers keep theirs
+		generatedFile{path: "internal/product/problems.go", contains: []string{"package product\n"}},
+		generatedFile{path: "internal/handlers/problems.go", contains: []string{"package handlers\n"}},
+		generatedFile{path: "internal/category/problems.go", contains: []string{"package category\n"}},
+		generatedFile{path: "internal/category/relation_handler.go", contains: []string{"package category\n", "writeError("}},
+		generatedFile{path: "internal/category/relation_repo.go", contains: []string{"package category\n"}},
+		// The models and the loaders of included relations reference each other
+		generatedFile{path: "internal/common/product.go", contains: []string{"package common\n"}},
+		generatedFile{path: "internal/common/category_includes.go", contains: []string{"package common\n", "NewProductIncludes(r.db)"}},
+		generatedFile{
+			path: "internal/handlers/routes.go",
+			contains: []string{
+				"\tproductRepo := product.NewProductRepository(db)\n\tproductService := product.NewProductService(productRepo)\n\tproductHandler := product.NewProductHandler(productService)\n\tproduct.SetupProductRoutes(r, productHandler)\n}",
+				`"example.com/shop/internal/product"`,
+			},
+			excludes: []string{"internal/repositories", "internal/services"},
+		},
+	)
+
+	assert.NoDirExists(t, filepath.Join(dir, "internal", "models"))
+	assert.NoFileExists(t, filepath.Join(dir, "internal", "repositories", "product_repository.go"))
+	assertGoFilesParse(t, dir)
+	assert.Equal(t, filepath.Join(dir, "internal", "product", "repo.go"), layoutPath(dir, "internal", "repositories", "product_repository.go"))
+
+	// The package of a resource must not be a package of the project
+	cache := newTestProductSchema()
+	cache.ID, cache.Name, cache.Names = "cache-1", "Cache", models.CreateResourceNames("Cache")
+	assert.Error(t, NewSchemaGenerator(newMemorySchemaStorage(cache)).GenerateFromSchema(cache.ID, dir, "example.com/shop", "mongodb"))
+
+	// The repositories of the SQL data layers share unexported helpers
+	plain := newTestProductSchema()
+	sqlx := NewSchemaGenerator(newMemorySchemaStorage(plain)).WithDataLayer(models.DataLayerSQLX)
+	assert.ErrorContains(t, sqlx.GenerateFromSchema(plain.ID, dir, "example.com/shop", "postgres"), "layout does not support")
+}
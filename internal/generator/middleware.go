@@ -14,10 +14,11 @@ import (
 
 // MiddlewareOptions contains configuration for middleware generation
 type MiddlewareOptions struct {
-	Type   string
-	Name   string
-	Custom bool
-	Preset string
+	Type       string
+	Name       string
+	Custom     bool
+	Preset     string
+	OutputPath string // Project directory, the current directory by default
 }
 
 // MiddlewareGenerator handles middleware generation
@@ -36,6 +37,11 @@ func (g *MiddlewareGenerator) Generate(options MiddlewareOptions) error {
 
 	ui.PrintStep(1, 1, "Starting middleware generation...")
 
+	// Middleware templates target Gin
+	if manifest, err := LoadManifest(g.projectPath(".")); err == nil && manifest.HTTPFramework.OrDefault() != models.HTTPGin {
+		ui.PrintWarning(fmt.Sprintf("Generated middleware uses Gin, this project targets %s", manifest.HTTPFramework.GetDisplayName()))
+	}
+
 	// Handle preset generation
 	if options.Preset != "" {
 		return g.generatePreset()
@@ -363,7 +369,7 @@ func (g *MiddlewareGenerator) createMiddlewareDirectory() error {
 	}
 
 	for _, dir := range dirs {
-		if err := os.MkdirAll(dir, 0755); err != nil {
+		if err := os.MkdirAll(g.projectPath(dir), 0755); err != nil {
 			return fmt.Errorf("failed to create directory %s: %w", dir, err)
 		}
 	}
@@ -381,7 +387,7 @@ func (g *MiddlewareGenerator) generateMiddlewareFile(config models.MiddlewareCon
 
 // generateMiddlewareRegistry generates the middleware registry file
 func (g *MiddlewareGenerator) generateMiddlewareRegistry(middlewares []models.MiddlewareConfig) error {
-	content := templates.GetMiddlewareRegistryTemplate(middlewares)
+	content := templates.GetMiddlewareRegistryTemplate(g.module(), middlewares)
 	filename := "internal/middleware/middleware.go"
 
 	return g.writeFile(filename, content)
@@ -397,14 +403,42 @@ func (g *MiddlewareGenerator) generateMiddlewareConfig(middlewares []models.Midd
 
 // writeFile writes content to a file
 func (g *MiddlewareGenerator) writeFile(filePath, content string) error {
+	filePath = g.projectPath(filePath)
+
 	// Create directory if it doesn't exist
 	dir := filepath.Dir(filePath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
 	}
 
+	// Middleware templates import every package their options may need
+	data := []byte(content)
+	if filepath.Ext(filePath) == ".go" {
+		formatted, err := formatGoSource(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", filepath.Base(filePath), err)
+		}
+		data = formatted
+	}
+
 	// Write file
-	return os.WriteFile(filePath, []byte(content), 0644)
+	return os.WriteFile(filePath, data, 0644)
+}
+
+// projectPath returns a path relative to the project directory
+func (g *MiddlewareGenerator) projectPath(path string) string {
+	return filepath.Join(g.options.OutputPath, path)
+}
+
+// module returns the Go module of the project, named after its directory outside a module
+func (g *MiddlewareGenerator) module() string {
+	if manifest, err := LoadManifest(g.projectPath(".")); err == nil && manifest.Module != "" {
+		return manifest.Module
+	}
+	if module := readGoModule(g.projectPath(".")); module != "" {
+		return module
+	}
+	return filepath.Base(absPath(g.projectPath(".")))
 }
 
 // showPresetSummary shows a summary of the generated preset
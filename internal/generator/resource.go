@@ -78,6 +78,11 @@ func (g *ResourceGenerator) Generate() error {
 			strings.ToLower(file.name)))
 	}
 
+	hasMoney, err := g.generateMoney(resource)
+	if err != nil {
+		return fmt.Errorf("failed to generate money package: %w", err)
+	}
+
 	// Show success message
 	fmt.Println()
 	ui.PrintSuccess(fmt.Sprintf("CRUD resource '%s' generated successfully!", resource.Name))
@@ -88,6 +93,9 @@ func (g *ResourceGenerator) Generate() error {
 		fmt.Sprintf("internal/services/%s_service.go", strcase.ToSnake(resource.Name)),
 		fmt.Sprintf("internal/repositories/%s_repository.go", strcase.ToSnake(resource.Name)),
 	}
+	if hasMoney {
+		generatedFiles = append(generatedFiles, "internal/money/money.go")
+	}
 	
 	ui.PrintGeneratedFiles(generatedFiles)
 
@@ -315,6 +323,17 @@ func (g *ResourceGenerator) generateRepository(resource *models.Resource) error
 	)
 }
 
+// generateMoney generates the money package holding the amounts of currency fields, it
+// reports whether the resource has any
+func (g *ResourceGenerator) generateMoney(resource *models.Resource) (bool, error) {
+	for _, field := range resource.Fields {
+		if field.Type == models.FieldTypeCurrency {
+			return true, g.generateFromTemplate(resource, templates.MoneyPackageTemplate, "internal/money", "money.go")
+		}
+	}
+	return false, nil
+}
+
 // generateFromTemplate generates a file from a template
 func (g *ResourceGenerator) generateFromTemplate(resource *models.Resource, templateStr, dir, filename string) error {
 	// Create directory if it doesn't exist
@@ -0,0 +1,217 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/vibercode/cli/internal/models"
+	"github.com/vibercode/cli/internal/templates"
+	"github.com/vibercode/cli/pkg/ui"
+)
+
+// BulkField describes a field that can be written through bulk endpoints
+type BulkField struct {
+	JSONName string
+	Column   string
+	Kind     string
+}
+
+// BulkTemplateData contains the template data for the bulk feature
+type BulkTemplateData struct {
+	*EnhancedSchema
+	Bulk            *models.BulkConfig
+	UpsertColumns   []string
+	UpdateColumns   []string
+	PatchableFields []BulkField
+	// ImportTestRows are the NDJSON rows of the import test, none when the schema has no
+	// unique field whose samples can be varied
+	ImportTestRows []string
+	// CacheTestField is the number or boolean field the cache test patches, CacheTestPayload
+	// the request creating its record. The test is generated with the caching feature when
+	// the schema has such a field and no relation.
+	CacheTestField   *EnhancedField
+	CacheTestPayload string
+}
+
+// generateBulkFeature generates batch repositories, services and handlers for a schema
+func (g *SchemaGenerator) generateBulkFeature(data *EnhancedSchema, outputPath string) error {
+	if data.DBProvider == "mongodb" {
+		ui.PrintWarning("Bulk endpoints require a SQL database provider, skipping for " + data.Name)
+		return nil
+	}
+
+	bulkData := g.prepareBulkData(data)
+	if len(bulkData.UpsertColumns) == 0 {
+		ui.PrintInfo("No natural key found for " + data.Name + ", bulk upsert will not be generated")
+	}
+
+	snake := data.Names.SnakeCase
+	files := []struct {
+		template string
+		path     string
+	}{
+		{templates.BulkPackageTemplate, filepath.Join("internal", "bulk", "bulk.go")},
+		{templates.BulkResponseHelperTemplate, filepath.Join("internal", "handlers", "bulk_response.go")},
+		{templates.SchemaBulkRepositoryTemplate, filepath.Join("internal", "repositories", snake+"_bulk_repository.go")},
+		{templates.SchemaBulkServiceTemplate, filepath.Join("internal", "services", snake+"_bulk_service.go")},
+		{templates.SchemaBulkHandlerTemplate, filepath.Join("internal", "handlers", snake+"_bulk_handler.go")},
+	}
+	if len(bulkData.ImportTestRows) > 0 {
+		files = append(files, struct {
+			template string
+			path     string
+		}{templates.SchemaBulkImportTestTemplate, filepath.Join("internal", "services", snake+"_bulk_service_test.go")})
+		ui.PrintInfo("The import test of " + data.Name + " requires gorm.io/driver/sqlite, run 'go mod tidy' after generation")
+	}
+	if bulkData.CacheTestField != nil {
+		files = append(files, struct {
+			template string
+			path     string
+		}{templates.SchemaBulkCacheTestTemplate, filepath.Join("internal", "services", snake+"_bulk_cache_test.go")})
+		ui.PrintInfo("The bulk cache test of " + data.Name + " requires gorm.io/driver/sqlite, run 'go mod tidy' after generation")
+	}
+
+	for _, file := range files {
+		if err := g.generateSchemaFile(file.template, bulkData, filepath.Join(outputPath, file.path)); err != nil {
+			return err
+		}
+	}
+
+	data.Endpoints = append(data.Endpoints, models.FeatureBulk)
+	if len(bulkData.UpsertColumns) > 0 {
+		data.Endpoints = append(data.Endpoints, endpointBulkUpsert)
+	}
+	if bulkData.Bulk.Import {
+		data.Endpoints = append(data.Endpoints, endpointImport)
+	}
+	return nil
+}
+
+// prepareBulkData derives upsert keys and writable columns for the bulk templates
+func (g *SchemaGenerator) prepareBulkData(data *EnhancedSchema) *BulkTemplateData {
+	bulkData := &BulkTemplateData{
+		EnhancedSchema: data,
+		Bulk:           data.GetBulkConfig(),
+	}
+
+	keys := make(map[string]bool)
+	for _, field := range data.GetUpsertKeyFields() {
+		column := columnName(&field)
+		keys[column] = true
+		bulkData.UpsertColumns = append(bulkData.UpsertColumns, column)
+	}
+
+	for _, field := range data.Fields {
+		if field.ReadOnly || isRelationField(field.SchemaField) {
+			continue
+		}
+		column := columnName(field.SchemaField)
+		bulkData.PatchableFields = append(bulkData.PatchableFields, BulkField{
+			JSONName: field.Names.SnakeCase,
+			Column:   column,
+			Kind:     valueKind(field.SchemaField),
+		})
+		if !keys[column] {
+			bulkData.UpdateColumns = append(bulkData.UpdateColumns, column)
+		}
+	}
+	bulkData.UpdateColumns = append(bulkData.UpdateColumns, "updated_at")
+	if bulkData.Bulk.Import {
+		bulkData.ImportTestRows = bulkImportTestRows(data)
+	}
+	if data.HasFeature(models.FeatureCaching) {
+		bulkData.CacheTestField = bulkCacheTestField(data)
+		bulkData.CacheTestPayload = handlerTestPayload(data)
+	}
+
+	return bulkData
+}
+
+// bulkCacheTestField returns the field the cache test filters on and patches: a writable
+// number or boolean field, whose filter matches on equality. It returns nil for schemas with
+// relations the test can't migrate.
+func bulkCacheTestField(data *EnhancedSchema) *EnhancedField {
+	var testField *EnhancedField
+	for i := range data.Fields {
+		field := &data.Fields[i]
+		if isRelationField(field.SchemaField) {
+			return nil
+		}
+		if testField != nil || field.ReadOnly || !field.Filterable || field.GoFilterQuery == "" {
+			continue
+		}
+		switch field.GoType {
+		case "int64", "float64", "bool":
+			testField = field
+		}
+	}
+	return testField
+}
+
+// bulkImportTestRows builds three valid import rows, the second one repeating the unique
+// fields of the first. It returns nil when the rows can't be built: without unique field,
+// with unique fields whose samples can't be varied, or with relations the test can't migrate.
+func bulkImportTestRows(data *EnhancedSchema) []string {
+	rows := make([]string, 3)
+	for i, n := range []int{1, 1, 2} {
+		unique := false
+		var pairs []string
+		for _, field := range data.Fields {
+			if isRelationField(field.SchemaField) {
+				return nil
+			}
+			value, ok := handlerTestValue(field.SchemaField)
+			if field.Database != nil && field.Database.Unique {
+				if field.ReadOnly {
+					return nil
+				}
+				if value, ok = uniqueTestValue(field.SchemaField, n); !ok {
+					return nil
+				}
+				unique = true
+			}
+			if field.ReadOnly || !ok {
+				continue
+			}
+			key, _ := json.Marshal(field.Names.SnakeCase)
+			pairs = append(pairs, string(key)+":"+value)
+		}
+		if !unique {
+			return nil
+		}
+		rows[i] = "{" + strings.Join(pairs, ",") + "}"
+	}
+	return rows
+}
+
+// uniqueTestValue returns the n-th sample of a unique string field as JSON, false for fields
+// whose samples can't be varied within their validation rules
+func uniqueTestValue(field *models.SchemaField, n int) (string, bool) {
+	if fieldKind(field) != "string" || (field.Validation != nil && field.Validation.Pattern != "") {
+		return "", false
+	}
+
+	var value string
+	switch field.Type {
+	case "email":
+		value = fmt.Sprintf("user%d@example.com", n)
+	case "url":
+		value = fmt.Sprintf("https://example.com/%d", n)
+	default:
+		sample, _ := handlerTestValue(field)
+		_ = json.Unmarshal([]byte(sample), &value)
+		suffix := strconv.Itoa(n)
+		if field.Validation != nil && field.Validation.MaxLength != nil && len(value)+len(suffix) > *field.Validation.MaxLength {
+			if *field.Validation.MaxLength < len(suffix) {
+				return "", false
+			}
+			value = value[:*field.Validation.MaxLength-len(suffix)]
+		}
+		value += suffix
+	}
+	encoded, _ := json.Marshal(value)
+	return string(encoded), true
+}
@@ -0,0 +1,93 @@
+package generator
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vibercode/cli/internal/models"
+)
+
+func TestSchemaGenerator_BulkFeature(t *testing.T) {
+	schema := newTestProductSchema()
+	schema.Options = &models.GenerationOptions{
+		Features: []string{models.FeatureBulk},
+		Bulk:     &models.BulkConfig{MaxBatchSize: 100, Import: true},
+	}
+
+	dir := generateTestProject(t, NewSchemaGenerator(newMemorySchemaStorage(schema)), "postgres", schema)
+
+	assertGeneratedFiles(t, dir,
+		generatedFile{path: "internal/bulk/bulk.go"},
+		generatedFile{path: "internal/handlers/bulk_response.go"},
+		generatedFile{
+			path: "internal/handlers/product_bulk_handler.go",
+			contains: []string{
+				`products.PUT("/bulk", handler.BulkUpsert)`,
+				`products.POST("/import", handler.Import)`,
+			},
+		},
+		generatedFile{
+			path: "internal/repositories/product_bulk_repository.go",
+			// Items report the hex IDs the API serves
+			contains: []string{`{Name: "sku"}`, `tx.RollbackTo("import_row")`, "report.OK(i, item.ID.Hex())"},
+			// upsert keys must not be updated on conflict
+			excludes: []string{`"sku",`},
+		},
+		generatedFile{
+			path: "internal/services/product_bulk_service.go",
+			contains: []string{
+				`config.MaxBatchSize = 100`,
+				`"stock": "int"`,
+				"s.repo.ImportMany(ctx, batch, report)",
+				"result.Reject(lines[i], errors.New(item.Error))",
+			},
+			// Without caching there is no cache to invalidate
+			excludes: []string{"WithCache", "s.invalidate(ctx)"},
+		},
+		generatedFile{
+			path:     "internal/services/product_bulk_service_test.go",
+			contains: []string{`report.Errors[0].Line != 2`},
+		},
+		generatedFile{path: "internal/services/product_bulk_cache_test.go", missing: true},
+	)
+
+	for _, pkg := range []string{"bulk", "handlers", "repositories", "services"} {
+		assertGoFilesParse(t, filepath.Join(dir, "internal", pkg))
+	}
+}
+
+func TestSchemaGenerator_BulkFeatureWithoutNaturalKey(t *testing.T) {
+	schema := newTestProductSchema()
+	schema.Fields[0].Database = nil
+
+	gen := NewSchemaGenerator(newMemorySchemaStorage(schema)).WithFeatures(models.FeatureBulk)
+	dir := generateTestProject(t, gen, "sqlite", schema)
+
+	assertGeneratedFiles(t, dir,
+		generatedFile{path: "internal/handlers/product_bulk_handler.go", excludes: []string{"BulkUpsert"}},
+		generatedFile{path: "internal/services/product_bulk_service_test.go", missing: true},
+	)
+	assertGoFilesParse(t, filepath.Join(dir, "internal", "bulk"))
+}
+
+func TestBulkImportTestRows(t *testing.T) {
+	gen := NewSchemaGenerator(nil)
+	data := gen.prepareTemplateData(newTestProductSchema(), "example.com/shop", "postgres")
+
+	rows := bulkImportTestRows(data)
+	require.Len(t, rows, 3)
+	assert.Contains(t, rows[0], `"sku":"`)
+	assert.Equal(t, rows[0], rows[1], "the second row repeats the unique fields of the first")
+	assert.NotEqual(t, rows[0], rows[2])
+}
+
+func TestSchemaGenerator_BulkFeatureSkippedForMongo(t *testing.T) {
+	schema := newTestProductSchema()
+
+	gen := NewSchemaGenerator(newMemorySchemaStorage(schema)).WithFeatures(models.FeatureBulk)
+	dir := generateTestProject(t, gen, "mongodb", schema)
+
+	assertGeneratedFiles(t, dir, generatedFile{path: "internal/bulk/bulk.go", missing: true})
+}
@@ -0,0 +1,118 @@
+package generator
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/vibercode/cli/internal/models"
+	"github.com/vibercode/cli/internal/templates"
+	"github.com/vibercode/cli/pkg/ui"
+)
+
+// CacheTemplateData contains the template data for the caching feature
+type CacheTemplateData struct {
+	*EnhancedSchema
+	Cache   *models.CacheConfig
+	Lookups []CacheLookup
+	// DependsOn lists the resources included in cached reads of this resource
+	DependsOn []string
+	// Dependents lists the resources whose cached reads include this resource
+	Dependents []string
+}
+
+// CacheLookup is a unique field lookup of the repository interface
+type CacheLookup struct {
+	Name   string
+	Field  string
+	Param  string
+	GoType string
+}
+
+// generateCachingFeature generates the cache package and the read-through caching
+// decorator of a schema repository
+func (g *SchemaGenerator) generateCachingFeature(data *EnhancedSchema, outputPath string) error {
+	cacheData := g.prepareCacheData(data)
+	snake := data.Names.SnakeCase
+
+	files := []struct {
+		template string
+		path     string
+	}{
+		{templates.CachePackageTemplate, filepath.Join("internal", "cache", "cache.go")},
+		{templates.CacheLRUTemplate, filepath.Join("internal", "cache", "lru.go")},
+		{templates.CacheRedisTemplate, filepath.Join("internal", "cache", "redis.go")},
+		{templates.CacheTestTemplate, filepath.Join("internal", "cache", "cache_test.go")},
+		{templates.SchemaCacheRepositoryTemplate, filepath.Join("internal", "repositories", snake+"_cache_repository.go")},
+		{templates.SchemaCacheRepositoryTestTemplate, filepath.Join("internal", "repositories", snake+"_cache_repository_test.go")},
+	}
+
+	for _, file := range files {
+		if err := g.generateGoFile(file.template, cacheData, filepath.Join(outputPath, file.path)); err != nil {
+			return err
+		}
+	}
+
+	if data.HasFeature(models.FeatureBulk) {
+		ui.PrintInfo("Wire New" + data.Names.PascalCase + "BulkService(...).WithCache(cachedRepo) so bulk writes invalidate the cached " + data.Names.Plural)
+	}
+	ui.PrintInfo("Caching uses github.com/go-redis/redis/v8 when REDIS_ADDR is set and an in-memory LRU otherwise, run 'go mod tidy' after generation")
+	ui.PrintInfo("Wire New" + data.Names.PascalCase + "Service(repositories.NewCached" + data.Names.PascalCase + "Repository(repo, cache.FromEnv(10000)))")
+	return nil
+}
+
+// prepareCacheData collects the unique lookups of the repository and the resources related
+// through relation fields
+func (g *SchemaGenerator) prepareCacheData(data *EnhancedSchema) *CacheTemplateData {
+	cacheData := &CacheTemplateData{
+		EnhancedSchema: data,
+		Cache:          data.GetCacheConfig(),
+	}
+
+	// The lookups follow the repository interface of the data layer
+	if data.DataLayer == models.DataLayerGORM {
+		for _, field := range data.Fields {
+			if field.Database != nil && field.Database.Unique {
+				cacheData.Lookups = append(cacheData.Lookups, CacheLookup{
+					Name:   toSnakeCase(field.Name),
+					Field:  field.Names.PascalCase,
+					Param:  field.Names.CamelCase,
+					GoType: field.GoType,
+				})
+			}
+		}
+	} else {
+		for _, column := range g.prepareDataLayerData(data).UniqueColumns() {
+			cacheData.Lookups = append(cacheData.Lookups, CacheLookup{
+				Name:   column.Key,
+				Field:  column.Field,
+				Param:  column.Param,
+				GoType: column.GoType,
+			})
+		}
+	}
+
+	dependsOn := make(map[string]bool)
+	for _, field := range data.ResourceSchema.Fields {
+		if isRelationField(&field) && field.Relation != nil && field.Relation.Target != "" {
+			dependsOn[toSnakeCase(field.Relation.Target)] = true
+		}
+	}
+	cacheData.DependsOn = sortedKeys(dependsOn)
+
+	dependents := make(map[string]bool)
+	for _, name := range cacheData.Cache.Invalidates {
+		dependents[toSnakeCase(name)] = true
+	}
+	cacheData.Dependents = sortedKeys(dependents)
+	return cacheData
+}
+
+// sortedKeys returns the keys of a set in order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
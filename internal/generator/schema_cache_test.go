@@ -0,0 +1,92 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/vibercode/cli/internal/models"
+)
+
+func TestSchemaGenerator_CachingFeature(t *testing.T) {
+	schema := newTestProductSchema()
+	schema.Fields = append(schema.Fields,
+		models.SchemaField{Name: "category", Type: "relation", DisplayName: "Category", Relation: &models.RelationConfig{Type: "many_to_one", Target: "Category", ForeignKey: "category_id"}},
+	)
+	schema.Options = &models.GenerationOptions{
+		Features: []string{models.FeatureCaching},
+		Cache:    &models.CacheConfig{TTL: 120, Invalidates: []string{"Order"}},
+	}
+
+	dir := generateTestProject(t, NewSchemaGenerator(newMemorySchemaStorage(schema)), "postgres", schema)
+
+	assertGeneratedFiles(t, dir,
+		generatedFile{path: "internal/cache/cache.go"},
+		generatedFile{path: "internal/cache/lru.go"},
+		generatedFile{path: "internal/cache/redis.go"},
+		generatedFile{path: "internal/cache/cache_test.go"},
+		generatedFile{
+			path: "internal/repositories/product_cache_repository.go",
+			contains: []string{
+				"func NewCachedProductRepository(next ProductRepositoryInterface, c cache.Cache) *CachedProductRepository {",
+				`keys:    cache.NewNamespace(c, "product"),`,
+				"TTL:     120 * time.Second,",
+				fmt.Sprintf("ListTTL: %d * time.Second,", models.DefaultCacheListTTL),
+				"func (r *CachedProductRepository) GetBySku(ctx context.Context, sku string) (*models.Product, error) {",
+				`cache.DependsOn("product", "category")`,
+				`cache.DependsOn("order", "product")`,
+				"var _ ProductRepositoryInterface = (*CachedProductRepository)(nil)",
+			},
+		},
+		generatedFile{path: "internal/repositories/product_cache_repository_test.go"},
+	)
+
+	assertGoFilesParse(t, filepath.Join(dir, "internal"))
+}
+
+func TestSchemaGenerator_CachingFeatureDataLayer(t *testing.T) {
+	schema := newTestProductSchema()
+	gen := NewSchemaGenerator(newMemorySchemaStorage(schema)).
+		WithFeatures(models.FeatureCaching).
+		WithDataLayer(models.DataLayerSQLX)
+	dir := generateTestProject(t, gen, "sqlite", schema)
+
+	assertGeneratedFiles(t, dir, generatedFile{
+		path: "internal/repositories/product_cache_repository.go",
+		contains: []string{
+			"func (r *CachedProductRepository) GetBySku(ctx context.Context, sku string) (*models.Product, error) {",
+			fmt.Sprintf("TTL:     %d * time.Second,", models.DefaultCacheTTL),
+		},
+	})
+
+	assertGoFilesParse(t, filepath.Join(dir, "internal"))
+}
+
+func TestSchemaGenerator_CachingFeatureBulk(t *testing.T) {
+	schema := newTestProductSchema()
+	schema.Options = &models.GenerationOptions{Features: []string{models.FeatureBulk, models.FeatureCaching}}
+
+	dir := generateTestProject(t, NewSchemaGenerator(newMemorySchemaStorage(schema)), "postgres", schema)
+
+	assertGeneratedFiles(t, dir,
+		generatedFile{
+			path: "internal/services/product_bulk_service.go",
+			contains: []string{
+				"func (s *ProductBulkService) WithCache(cached *repositories.CachedProductRepository) *ProductBulkService {",
+				"defer s.invalidate(ctx)",
+				"if err := s.cached.Invalidate(ctx); err != nil {",
+			},
+		},
+		generatedFile{
+			path: "internal/services/product_bulk_cache_test.go",
+			contains: []string{
+				"service := NewProductBulkService(repositories.NewProductBulkRepository(db), bulk.Config{}).WithCache(cached)",
+				// Stock is the first number field, patched through an equality filter
+				"filter := &models.ProductFilter{Stock: &value}",
+				`service.PatchWhere(ctx, filter, map[string]interface{}{"stock": want})`,
+			},
+		},
+	)
+
+	assertGoFilesParse(t, filepath.Join(dir, "internal"))
+}
@@ -0,0 +1,774 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/vibercode/cli/internal/models"
+	"github.com/vibercode/cli/internal/templates"
+	"github.com/vibercode/cli/pkg/ui"
+)
+
+// DataLayerTemplateData extends the schema with the table, columns and named queries
+// of the sqlc, sqlx and pgx repositories
+type DataLayerTemplateData struct {
+	*EnhancedSchema
+	Layer   models.DataLayer
+	Table   string
+	Columns []SQLColumn
+	Queries []SQLQuery
+	// RowType is the struct rows are scanned into, the sqlc model of the table or
+	// a struct declared by the repository
+	RowType string
+}
+
+// SQLColumn is a table column holding a schema field
+type SQLColumn struct {
+	Name     string
+	Field    string
+	GoType   string
+	SQLType  string
+	Nullable bool
+	// JSONText is set for JSON columns read and written as text, which every database/sql driver handles
+	JSONText bool
+	Unique   bool
+	Index    bool
+	// RowField is the column's field in the row struct, named after the column the way sqlc names it
+	RowField string
+	// Filter is how the list query matches the field filter: contains, equal, day or empty
+	Filter string
+	Search bool
+	// Key is the JSON name of the field, accepted by the sort filter
+	Key   string
+	Param string
+	// Geo is set for the columns of coordinates fields: geography, lat or lng
+	Geo string
+	// Money is set for the columns of currency fields: amount or currency
+	Money string
+}
+
+// SQLCoordinates is a coordinates field stored as latitude and longitude columns, named by their row fields
+type SQLCoordinates struct {
+	Field string
+	Lat   string
+	Lng   string
+}
+
+// SQLMoney is a currency field stored as amount and currency columns, named by their row fields.
+// Minor is set for amounts stored as integer minor units.
+type SQLMoney struct {
+	Field    string
+	Key      string
+	Amount   string
+	Currency string
+	Minor    bool
+}
+
+// SQLQuery is a named query of a query file. Command is the sqlc query annotation.
+type SQLQuery struct {
+	Name    string
+	Command string
+	SQL     string
+}
+
+// SQLMigrationData is the data of a versioned SQL migration
+type SQLMigrationData struct {
+	Version     string
+	Name        string
+	CreatedAt   string
+	Description string
+	UpSQL       string
+	DownSQL     string
+}
+
+// WithDataLayer selects the data access library of generated repositories
+func (g *SchemaGenerator) WithDataLayer(layer models.DataLayer) *SchemaGenerator {
+	g.dataLayer = layer
+	return g
+}
+
+// checkDataLayer rejects the features and relation includes of a schema its data layer
+// can't generate, before any file is written
+func (g *SchemaGenerator) checkDataLayer(schema *models.ResourceSchema) error {
+	layer := g.dataLayer.OrDefault()
+	if schema.Options != nil {
+		for _, feature := range schema.Options.Features {
+			if !layer.SupportsFeature(feature) {
+				return fmt.Errorf("the %s data layer does not support the %s feature of %s, use the GORM data layer", layer.GetDisplayName(), feature, schema.Name)
+			}
+		}
+	}
+	if layer == models.DataLayerGORM {
+		return nil
+	}
+	for i := range schema.Fields {
+		field := &schema.Fields[i]
+		if isRelationField(field) && field.Relation != nil && field.Relation.Populate {
+			return fmt.Errorf("the %s data layer does not support including %s.%s, unset populate or use the GORM data layer", layer.GetDisplayName(), schema.Name, field.Name)
+		}
+	}
+	return nil
+}
+
+// RowFieldType returns the Go type of the column in row structs, nullable columns are pointers
+func (c SQLColumn) RowFieldType() string {
+	if c.JSONText {
+		return "*string"
+	}
+	if c.Nullable {
+		return "*" + c.GoType
+	}
+	return c.GoType
+}
+
+// ModelValue returns the Go expression of the column value in the model named receiver
+func (c SQLColumn) ModelValue(receiver string) string {
+	value := receiver + "." + c.Field
+	switch c.Money {
+	case "amount":
+		if c.GoType == "int64" {
+			return value + ".MinorUnits()"
+		}
+		return value + ".Amount"
+	case "currency":
+		return value + ".Currency"
+	}
+	if c.Nullable {
+		return "&" + value
+	}
+	return value
+}
+
+// LatLng reports whether the column is the latitude or longitude column of a coordinates field
+func (c SQLColumn) LatLng() bool {
+	return c.Geo == "lat" || c.Geo == "lng"
+}
+
+// generateDataLayer generates the repository, query file and SQL migration of a schema
+// for the sqlc, sqlx and pgx data layers
+func (g *SchemaGenerator) generateDataLayer(data *EnhancedSchema, outputPath string) error {
+	layerData := g.prepareDataLayerData(data)
+	snake := data.Names.SnakeCase
+
+	var queryPath string
+	switch layerData.Layer {
+	case models.DataLayerSQLC:
+		queryPath = filepath.Join(outputPath, "internal", "db", "queries", snake+".sql")
+	default:
+		queryPath = filepath.Join(outputPath, "internal", "repositories", "queries", snake+".sql")
+		helperPath := filepath.Join(outputPath, "internal", "repositories", "queries.go")
+		if err := g.generateGoFile(templates.RepositoryQueriesTemplate, layerData, helperPath); err != nil {
+			return fmt.Errorf("failed to generate query loader: %w", err)
+		}
+	}
+	if err := g.generateFile(templates.SQLQueriesTemplate, layerData, queryPath); err != nil {
+		return fmt.Errorf("failed to generate queries: %w", err)
+	}
+
+	repositoryTemplates := map[models.DataLayer]string{
+		models.DataLayerSQLC: templates.SchemaSQLCRepositoryTemplate,
+		models.DataLayerSQLX: templates.SchemaSQLXRepositoryTemplate,
+		models.DataLayerPGX:  templates.SchemaPGXRepositoryTemplate,
+	}
+	repositoryPath := filepath.Join(outputPath, "internal", "repositories", snake+"_repository.go")
+	if err := g.generateGoFile(repositoryTemplates[layerData.Layer], layerData, repositoryPath); err != nil {
+		return fmt.Errorf("failed to generate repository: %w", err)
+	}
+
+	transactionTemplate := templates.PGXTransactionTemplate
+	if layerData.Layer == models.DataLayerSQLX {
+		transactionTemplate = templates.SQLXTransactionTemplate
+	}
+	transactionPath := filepath.Join(outputPath, "internal", "repositories", "transaction.go")
+	if err := g.generateGoFile(transactionTemplate, layerData, transactionPath); err != nil {
+		return fmt.Errorf("failed to generate transactor: %w", err)
+	}
+
+	if err := g.generateSQLMigration(layerData, outputPath); err != nil {
+		return fmt.Errorf("failed to generate migration: %w", err)
+	}
+
+	switch layerData.Layer {
+	case models.DataLayerSQLC:
+		// The configuration is shared by every resource, keep local changes
+		configPath := filepath.Join(outputPath, "sqlc.yaml")
+		if _, err := os.Stat(configPath); os.IsNotExist(err) {
+			if err := g.generateFile(templates.SQLCConfigTemplate, layerData, configPath); err != nil {
+				return fmt.Errorf("failed to generate sqlc configuration: %w", err)
+			}
+		}
+		ui.PrintInfo("Run 'sqlc generate' to generate the internal/db package, then 'go mod tidy'")
+	case models.DataLayerSQLX:
+		if data.DBProvider == "mysql" {
+			ui.PrintInfo("sqlx repositories scan DATE and DATETIME columns into time.Time, add parseTime=true to the MySQL DSN")
+		}
+		ui.PrintInfo("sqlx repositories require github.com/jmoiron/sqlx, run 'go mod tidy' after generation")
+	case models.DataLayerPGX:
+		ui.PrintInfo("pgx repositories require github.com/jackc/pgx/v5, run 'go mod tidy' after generation")
+	}
+	return nil
+}
+
+// prepareDataLayerData maps the schema fields onto table columns and builds the named queries
+func (g *SchemaGenerator) prepareDataLayerData(data *EnhancedSchema) *DataLayerTemplateData {
+	layerData := &DataLayerTemplateData{
+		EnhancedSchema: data,
+		Layer:          data.DataLayer,
+		Table:          data.Names.TableName,
+		RowType:        data.Names.CamelCase + "Row",
+	}
+	if layerData.Layer == models.DataLayerSQLC {
+		layerData.RowType = "db." + sqlcIdentifier(layerData.Table)
+	}
+
+	for _, field := range data.Fields {
+		if field.IsGeo() {
+			layerData.Columns = append(layerData.Columns, geoColumns(field, data.DBProvider)...)
+			continue
+		}
+		if field.IsMoney() {
+			layerData.Columns = append(layerData.Columns, moneyColumns(field, data.DBProvider)...)
+			continue
+		}
+
+		column := columnName(field.SchemaField)
+		sqlType := sqlColumnType(field.SchemaField, data.DBProvider)
+		if sqlType == "" || isRelationField(field.SchemaField) || column == "id" || column == "created_at" || column == "updated_at" {
+			continue
+		}
+
+		goType := field.GetGoType()
+		layerData.Columns = append(layerData.Columns, SQLColumn{
+			Name:    column,
+			Field:   field.Names.PascalCase,
+			GoType:  goType,
+			SQLType: sqlType,
+			// JSON columns scan NULL into a nil json.RawMessage
+			Nullable: field.Database.Nullable && goType != "json.RawMessage",
+			JSONText: layerData.Layer == models.DataLayerSQLX && goType == "json.RawMessage",
+			Unique:   field.Database.Unique,
+			Index:    field.Database.Index,
+			RowField: sqlcIdentifier(column),
+			Filter:   sqlFilterKind(field.SchemaField),
+			Search:   (field.Type == "string" || field.Type == "text") && !field.IsSensitive(),
+			Key:      toSnakeCase(field.Name),
+			Param:    field.Names.CamelCase,
+		})
+	}
+
+	layerData.Queries = g.sqlQueries(layerData)
+	return layerData
+}
+
+// geoColumns returns the columns of a coordinates field, a PostGIS geography column or
+// nullable latitude and longitude columns
+func geoColumns(field EnhancedField, provider string) []SQLColumn {
+	column := columnName(field.SchemaField)
+	base := SQLColumn{
+		Name:     column,
+		Field:    field.Names.PascalCase,
+		GoType:   "*models.Coordinates",
+		SQLType:  "geography(Point,4326)",
+		RowField: sqlcIdentifier(column),
+		Key:      toSnakeCase(field.Name),
+		Param:    field.Names.CamelCase,
+		Geo:      "geography",
+	}
+	if usesGeography(provider) {
+		return []SQLColumn{base}
+	}
+
+	floatType := sqlColumnType(&models.SchemaField{Name: field.Name, Type: "float"}, provider)
+	lat, lng := base, base
+	lat.Name, lat.RowField, lat.Geo = column+"_lat", sqlcIdentifier(column+"_lat"), "lat"
+	lng.Name, lng.RowField, lng.Geo = column+"_lng", sqlcIdentifier(column+"_lng"), "lng"
+	for _, c := range []*SQLColumn{&lat, &lng} {
+		c.GoType, c.SQLType, c.Nullable = "float64", floatType, true
+	}
+	return []SQLColumn{lat, lng}
+}
+
+// moneyColumns returns the amount and currency columns of a currency field. Amounts are
+// NUMERIC columns of the field precision and scale, integer minor units on SQLite.
+func moneyColumns(field EnhancedField, provider string) []SQLColumn {
+	column := columnName(field.SchemaField)
+	amount := SQLColumn{
+		Name:     column + "_amount",
+		Field:    field.Names.PascalCase,
+		GoType:   "decimal.Decimal",
+		SQLType:  sqlColumnType(field.SchemaField, provider),
+		Index:    field.Database != nil && field.Database.Index,
+		RowField: sqlcIdentifier(column + "_amount"),
+		Key:      toSnakeCase(field.Name),
+		Param:    field.Names.CamelCase,
+		Money:    "amount",
+	}
+	if provider == "sqlite" {
+		amount.GoType = "int64"
+	}
+	currency := amount
+	currency.Name, currency.RowField, currency.Money = column+"_currency", sqlcIdentifier(column+"_currency"), "currency"
+	currency.GoType, currency.SQLType, currency.Index = "string", "VARCHAR(3)", false
+	return []SQLColumn{amount, currency}
+}
+
+// sqlQueries builds the named queries of a resource. sqlc and pgx target PostgreSQL
+// positional parameters, sqlx binds rows by column name and rebinds ? for the driver.
+func (g *SchemaGenerator) sqlQueries(data *DataLayerTemplateData) []SQLQuery {
+	named := data.Layer == models.DataLayerSQLX
+	columns := append([]string{"id", "created_at", "updated_at"}, data.columnNames()...)
+	selectColumns := strings.Join(columns, ", ")
+	pascal := data.Names.PascalCase
+
+	param := func(n int) string {
+		if named {
+			return "?"
+		}
+		return fmt.Sprintf("$%d", n)
+	}
+
+	values := make([]string, len(columns))
+	for i, column := range columns {
+		if named {
+			values[i] = ":" + column
+		} else {
+			values[i] = fmt.Sprintf("$%d", i+1)
+		}
+	}
+
+	var assignments []string
+	for i, column := range columns[2:] {
+		if named {
+			assignments = append(assignments, fmt.Sprintf("%s = :%s", column, column))
+		} else {
+			assignments = append(assignments, fmt.Sprintf("%s = $%d", column, i+2))
+		}
+	}
+	byID := "id = " + param(1)
+	if named {
+		byID = "id = :id"
+	}
+
+	exists := fmt.Sprintf("SELECT EXISTS(SELECT 1 FROM %s WHERE id = %s)", data.Table, param(1))
+	if named {
+		// EXISTS returns an integer or a boolean depending on the driver, COUNT is portable
+		exists = fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE id = ?", data.Table)
+	}
+
+	queries := []SQLQuery{
+		{Name: "Create" + pascal, Command: ":exec", SQL: fmt.Sprintf("INSERT INTO %s (%s)\nVALUES (%s)", data.Table, selectColumns, strings.Join(values, ", "))},
+		{Name: "Get" + pascal, Command: ":one", SQL: fmt.Sprintf("SELECT %s\nFROM %s\nWHERE id = %s", selectColumns, data.Table, param(1))},
+		{Name: "Update" + pascal, Command: ":exec", SQL: fmt.Sprintf("UPDATE %s\nSET %s\nWHERE %s", data.Table, strings.Join(assignments, ", "), byID)},
+		{Name: "Delete" + pascal, Command: ":exec", SQL: fmt.Sprintf("DELETE FROM %s\nWHERE id = %s", data.Table, param(1))},
+		{Name: pascal + "Exists", Command: ":one", SQL: exists},
+	}
+	for _, column := range data.Columns {
+		if !column.Unique {
+			continue
+		}
+		queries = append(queries, SQLQuery{
+			Name:    "Get" + pascal + "By" + column.Field,
+			Command: ":one",
+			SQL:     fmt.Sprintf("SELECT %s\nFROM %s\nWHERE %s = %s", selectColumns, data.Table, column.Name, param(1)),
+		})
+	}
+
+	// Filtered lists are built at runtime from these base statements. sqlc only
+	// generates static queries, its repository keeps them in Go.
+	if data.Layer != models.DataLayerSQLC {
+		queries = append(queries,
+			SQLQuery{Name: "List" + data.Names.PascalPlural, Command: ":many", SQL: fmt.Sprintf("SELECT %s\nFROM %s", selectColumns, data.Table)},
+			SQLQuery{Name: "Count" + data.Names.PascalPlural, Command: ":one", SQL: fmt.Sprintf("SELECT COUNT(*)\nFROM %s", data.Table)},
+		)
+	}
+	return queries
+}
+
+// columnNames returns the field column names in table order
+func (d *DataLayerTemplateData) columnNames() []string {
+	names := make([]string, len(d.Columns))
+	for i, column := range d.Columns {
+		names[i] = column.Name
+	}
+	return names
+}
+
+// SelectColumns returns the comma separated columns of the table
+func (d *DataLayerTemplateData) SelectColumns() string {
+	return strings.Join(append([]string{"id", "created_at", "updated_at"}, d.columnNames()...), ", ")
+}
+
+// UniqueColumns returns the columns with a GetBy lookup
+func (d *DataLayerTemplateData) UniqueColumns() []SQLColumn {
+	var columns []SQLColumn
+	for _, column := range d.Columns {
+		if column.Unique {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// LatLngFields returns the coordinates fields stored as latitude and longitude columns
+func (d *DataLayerTemplateData) LatLngFields() []SQLCoordinates {
+	var fields []SQLCoordinates
+	for i, column := range d.Columns {
+		if column.Geo == "lat" && i+1 < len(d.Columns) {
+			fields = append(fields, SQLCoordinates{Field: column.Field, Lat: column.RowField, Lng: d.Columns[i+1].RowField})
+		}
+	}
+	return fields
+}
+
+// MoneyFields returns the currency fields stored as amount and currency columns
+func (d *DataLayerTemplateData) MoneyFields() []SQLMoney {
+	var fields []SQLMoney
+	for i, column := range d.Columns {
+		if column.Money == "amount" && i+1 < len(d.Columns) {
+			fields = append(fields, SQLMoney{
+				Field:    column.Field,
+				Key:      column.Key,
+				Amount:   column.RowField,
+				Currency: d.Columns[i+1].RowField,
+				Minor:    column.GoType == "int64",
+			})
+		}
+	}
+	return fields
+}
+
+// SearchColumns returns the columns matched by the search filter
+func (d *DataLayerTemplateData) SearchColumns() []SQLColumn {
+	var columns []SQLColumn
+	for _, column := range d.Columns {
+		if column.Search {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// generateSQLMigration writes the versioned SQL migration creating the resource table
+func (g *SchemaGenerator) generateSQLMigration(data *DataLayerTemplateData, outputPath string) error {
+	return g.writeSQLMigration(outputPath, "create_"+data.Table, fmt.Sprintf("Create the %s table", data.Table),
+		sqlCreateTable(data), fmt.Sprintf("DROP TABLE IF EXISTS %s;", data.Table))
+}
+
+// writeSQLMigration writes a versioned SQL migration. Regenerating keeps the version of an
+// existing migration with the same name so it isn't applied twice, new migrations are
+// versioned after the existing ones so they run in the order they were written.
+func (g *SchemaGenerator) writeSQLMigration(outputPath, name, description, up, down string) error {
+	dir := filepath.Join(outputPath, "migrations")
+	now := time.Now()
+
+	version := now.Format(sqlMigrationVersionLayout)
+	if existing, _ := filepath.Glob(filepath.Join(dir, "*_"+name+".sql")); len(existing) > 0 {
+		version = strings.TrimSuffix(filepath.Base(existing[0]), "_"+name+".sql")
+	} else if latest := latestSQLMigrationVersion(dir); latest >= version {
+		previous, err := time.Parse(sqlMigrationVersionLayout, latest)
+		if err != nil {
+			return fmt.Errorf("invalid migration version %s: %w", latest, err)
+		}
+		version = previous.Add(time.Second).Format(sqlMigrationVersionLayout)
+	}
+
+	migration := &SQLMigrationData{
+		Version:     version,
+		Name:        name,
+		CreatedAt:   now.Format(time.RFC3339),
+		Description: description,
+		UpSQL:       up,
+		DownSQL:     down,
+	}
+	return g.generateFile(templates.MigrationTemplate, migration, filepath.Join(dir, version+"_"+name+".sql"))
+}
+
+// sqlMigrationVersionLayout is the time layout of SQL migration versions
+const sqlMigrationVersionLayout = "20060102150405"
+
+// latestSQLMigrationVersion returns the highest version of the SQL migrations in dir
+func latestSQLMigrationVersion(dir string) string {
+	files, _ := filepath.Glob(filepath.Join(dir, "*_*.sql"))
+	latest := ""
+	for _, file := range files {
+		version, _, _ := strings.Cut(filepath.Base(file), "_")
+		if len(version) == len(sqlMigrationVersionLayout) && version > latest {
+			latest = version
+		}
+	}
+	return latest
+}
+
+// sqlCreateTable returns the statements creating the resource table and its indexes
+func sqlCreateTable(data *DataLayerTemplateData) string {
+	provider := data.DBProvider
+	timestamp := sqlTimestampType(provider)
+
+	lines := []string{
+		"id VARCHAR(24) PRIMARY KEY",
+		"created_at " + timestamp + " NOT NULL",
+		"updated_at " + timestamp + " NOT NULL",
+	}
+	for _, column := range data.Columns {
+		line := column.Name + " " + column.SQLType
+		// Coordinates are optional pointers in the model
+		if !column.Nullable && column.GoType != "json.RawMessage" && column.Geo == "" {
+			line += " NOT NULL"
+		}
+		if column.Unique {
+			line += " UNIQUE"
+		}
+		lines = append(lines, line)
+	}
+
+	var b strings.Builder
+	if data.Geo != nil && data.Geo.Geography {
+		b.WriteString("CREATE EXTENSION IF NOT EXISTS postgis;\n\n")
+	}
+	fmt.Fprintf(&b, "CREATE TABLE IF NOT EXISTS %s (\n    %s\n);", data.Table, strings.Join(lines, ",\n    "))
+
+	// MySQL has no IF NOT EXISTS for indexes
+	ifNotExists := " IF NOT EXISTS"
+	if provider == "mysql" {
+		ifNotExists = ""
+	}
+	for _, column := range data.Columns {
+		if column.Index && !column.Unique {
+			fmt.Fprintf(&b, "\n\nCREATE INDEX%s idx_%s_%s ON %s (%s);", ifNotExists, data.Table, column.Name, data.Table, column.Name)
+		}
+	}
+	// Geographic filters match a GiST index on geography columns, a bounding box on latitude and longitude columns
+	for _, column := range data.Columns {
+		switch column.Geo {
+		case "geography":
+			fmt.Fprintf(&b, "\n\nCREATE INDEX%s idx_%s_%s ON %s USING GIST (%s);", ifNotExists, data.Table, column.Name, data.Table, column.Name)
+		case "lat":
+			base := strings.TrimSuffix(column.Name, "_lat")
+			fmt.Fprintf(&b, "\n\nCREATE INDEX%s idx_%s_%s ON %s (%s_lat, %s_lng);", ifNotExists, data.Table, base, data.Table, base, base)
+		}
+	}
+	for _, index := range data.Indexes {
+		// gin indexes declare the fields of the full-text search, see the search feature
+		if strings.EqualFold(index.Type, "gin") {
+			continue
+		}
+		var columns []string
+		for _, name := range index.Fields {
+			columns = append(columns, sqlIndexColumn(data.ResourceSchema, name))
+		}
+		if len(columns) == 0 {
+			continue
+		}
+		indexName := index.Name
+		if indexName == "" {
+			indexName = "idx_" + data.Table + "_" + strings.Join(columns, "_")
+		}
+		kind := "INDEX"
+		if index.Unique {
+			kind = "UNIQUE INDEX"
+		}
+		fmt.Fprintf(&b, "\n\nCREATE %s%s %s ON %s (%s);", kind, ifNotExists, indexName, data.Table, strings.Join(columns, ", "))
+	}
+	return b.String()
+}
+
+// sqlIndexColumn returns the column of a field named by a schema index
+func sqlIndexColumn(schema *models.ResourceSchema, name string) string {
+	for i := range schema.Fields {
+		if schema.Fields[i].Name == name {
+			return columnName(&schema.Fields[i])
+		}
+	}
+	return toSnakeCase(name)
+}
+
+// sqlTimestampType returns the column type of the created_at and updated_at timestamps
+func sqlTimestampType(provider string) string {
+	switch provider {
+	case "mysql":
+		return "DATETIME(6)"
+	case "sqlite":
+		return "DATETIME"
+	default:
+		return "TIMESTAMPTZ"
+	}
+}
+
+// sqlColumnType returns the column type of a field in SQL migrations. The types map onto
+// the Go types of the model, the sqlc configuration overrides the rest of them.
+// Fields without a column type are not persisted by the SQL data layers.
+func sqlColumnType(field *models.SchemaField, provider string) string {
+	if field.Database != nil && field.Database.Type != "" {
+		return field.Database.Type
+	}
+
+	switch field.GetGoType() {
+	case "string":
+		if field.Type == "text" {
+			return "TEXT"
+		}
+		size := 255
+		if field.Database != nil && field.Database.Size > 0 {
+			size = field.Database.Size
+		}
+		return fmt.Sprintf("VARCHAR(%d)", size)
+	case "int64":
+		if provider == "sqlite" {
+			return "INTEGER"
+		}
+		return "BIGINT"
+	case "float64":
+		switch provider {
+		case "mysql":
+			return "DOUBLE"
+		case "sqlite":
+			return "REAL"
+		}
+		return "DOUBLE PRECISION"
+	case "bool":
+		return "BOOLEAN"
+	case "time.Time":
+		if field.Type == "date" {
+			return "DATE"
+		}
+		return sqlTimestampType(provider)
+	case "uuid.UUID":
+		switch provider {
+		case "mysql":
+			return "CHAR(36)"
+		case "sqlite":
+			return "TEXT"
+		}
+		return "UUID"
+	case "money.Money":
+		// The amount column of currency fields, next to their currency column
+		precision, scale := 19, 4
+		if field.Database != nil && field.Database.Precision > 0 {
+			precision, scale = field.Database.Precision, field.Database.Scale
+		}
+		switch provider {
+		case "mysql":
+			return fmt.Sprintf("DECIMAL(%d,%d)", precision, scale)
+		case "sqlite":
+			// SQLite has no exact decimal type, amounts are integer minor units
+			return "INTEGER"
+		}
+		return fmt.Sprintf("NUMERIC(%d,%d)", precision, scale)
+	case "json.RawMessage":
+		switch provider {
+		case "mysql":
+			return "JSON"
+		case "sqlite":
+			return "TEXT"
+		}
+		return "JSONB"
+	}
+	return ""
+}
+
+// sqlFilterKind returns how the list query matches a field filter, following the GORM filters
+func sqlFilterKind(field *models.SchemaField) string {
+	switch field.Type {
+	case "string", "text", "email", "url":
+		return "contains"
+	case "number", "integer", "float", "decimal", "boolean":
+		return "equal"
+	case "date", "datetime", "timestamp":
+		return "day"
+	}
+	return ""
+}
+
+// sqlcIdentifier returns the Go name sqlc gives a table or column, with its default "id" initialism
+func sqlcIdentifier(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, name)
+
+	var b strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part == "id" {
+			b.WriteString("ID")
+			continue
+		}
+		b.WriteString(strings.Title(part))
+	}
+	return b.String()
+}
+
+// FilterCondition returns the list query condition of a column filter, with ? standing for its argument
+func (d *DataLayerTemplateData) FilterCondition(column SQLColumn) string {
+	switch column.Filter {
+	case "contains":
+		if d.Layer == models.DataLayerSQLX {
+			return fmt.Sprintf("LOWER(%s) LIKE ?", column.Name)
+		}
+		return column.Name + " ILIKE ?"
+	case "day":
+		if d.Layer != models.DataLayerSQLX {
+			return column.Name + "::date = ?::date"
+		}
+		if d.DBProvider == "sqlite" {
+			return fmt.Sprintf("DATE(%s) = DATE(?)", column.Name)
+		}
+		return fmt.Sprintf("CAST(%s AS DATE) = CAST(? AS DATE)", column.Name)
+	default:
+		return column.Name + " = ?"
+	}
+}
+
+// FilterArg returns the Go expression of a column filter argument
+func (d *DataLayerTemplateData) FilterArg(column SQLColumn) string {
+	value := "*filter." + column.Field
+	if column.Filter != "contains" {
+		return value
+	}
+	return d.likePattern(value)
+}
+
+// SearchCondition returns the list query condition of the search filter
+func (d *DataLayerTemplateData) SearchCondition() string {
+	var conditions []string
+	for _, column := range d.SearchColumns() {
+		if d.Layer == models.DataLayerSQLX {
+			conditions = append(conditions, fmt.Sprintf("LOWER(%s) LIKE ?", column.Name))
+		} else {
+			conditions = append(conditions, column.Name+" ILIKE ?")
+		}
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")"
+}
+
+// SearchArg returns the Go expression of the search filter argument
+func (d *DataLayerTemplateData) SearchArg() string {
+	return d.likePattern("filter.Search")
+}
+
+// likePattern wraps a Go string expression into a substring pattern, lowered for the
+// LOWER(column) LIKE conditions sqlx uses on every driver
+func (d *DataLayerTemplateData) likePattern(value string) string {
+	if d.Layer == models.DataLayerSQLX {
+		value = "strings.ToLower(" + value + ")"
+	}
+	return `"%"+` + value + `+"%"`
+}
+
+// HasFilters reports whether lists can be filtered by a search or a column filter
+func (d *DataLayerTemplateData) HasFilters() bool {
+	if len(d.SearchColumns()) > 0 {
+		return true
+	}
+	for _, column := range d.Columns {
+		if column.Filter != "" {
+			return true
+		}
+	}
+	return false
+}
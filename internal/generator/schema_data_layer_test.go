@@ -0,0 +1,144 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vibercode/cli/internal/models"
+)
+
+func TestSchemaGenerator_DataLayers(t *testing.T) {
+	tests := []struct {
+		layer      models.DataLayer
+		provider   string
+		repository []string
+		queries    string
+	}{
+		{
+			layer:      models.DataLayerSQLC,
+			provider:   "postgres",
+			repository: []string{"func NewProductRepository(pool *pgxpool.Pool) *ProductRepository {", "r.queries(ctx).CreateProduct(ctx, db.CreateProductParams{", "pgx.RowToStructByName[db.Products]"},
+			queries:    "internal/db/queries/product.sql",
+		},
+		{
+			layer:      models.DataLayerSQLX,
+			provider:   "mysql",
+			repository: []string{"func NewProductRepository(db *sqlx.DB) *ProductRepository {", `loadQueries("product.sql")`, `where("LOWER(name) LIKE ?"`},
+			queries:    "internal/repositories/queries/product.sql",
+		},
+		{
+			layer:      models.DataLayerPGX,
+			provider:   "postgres",
+			repository: []string{"func NewProductRepository(db *pgxpool.Pool) *ProductRepository {", `where("name ILIKE ?"`, "pgx.CollectOneRow"},
+			queries:    "internal/repositories/queries/product.sql",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.layer), func(t *testing.T) {
+			schema := newTestProductSchema()
+			gen := NewSchemaGenerator(newMemorySchemaStorage(schema)).WithDataLayer(tt.layer)
+			dir := generateTestProject(t, gen, tt.provider, schema)
+
+			assertGeneratedFiles(t, dir,
+				generatedFile{
+					path:     "internal/repositories/product_repository.go",
+					contains: append(tt.repository, "GetBySku(ctx context.Context, sku string) (*models.Product, error)"),
+					excludes: []string{"mongo.Collection"},
+				},
+				generatedFile{path: "internal/repositories/transaction.go"},
+				generatedFile{path: tt.queries, contains: []string{"-- name: GetProductBySku :one"}},
+				generatedFile{path: "internal/models/product.go", excludes: []string{"gorm"}},
+				generatedFile{
+					path: "migrations/*_create_products.sql",
+					contains: []string{
+						"-- +migrate Up\nCREATE TABLE IF NOT EXISTS products (",
+						"sku VARCHAR(255) NOT NULL UNIQUE",
+						"-- +migrate Down\nDROP TABLE IF EXISTS products;",
+					},
+				},
+				generatedFile{path: "migrations/product_migration.go", missing: true},
+				generatedFile{path: "internal/models/objectid.go", missing: true},
+				generatedFile{path: "sqlc.yaml", missing: tt.layer != models.DataLayerSQLC},
+			)
+			assertGoFilesParse(t, filepath.Join(dir, "internal"))
+
+			// Regenerating keeps the migration version
+			migrations, err := filepath.Glob(filepath.Join(dir, "migrations", "*.sql"))
+			require.NoError(t, err)
+			require.NoError(t, gen.GenerateFromSchema(schema.ID, dir, "example.com/shop", tt.provider))
+			regenerated, err := filepath.Glob(filepath.Join(dir, "migrations", "*.sql"))
+			require.NoError(t, err)
+			assert.Equal(t, migrations, regenerated)
+		})
+	}
+}
+
+func TestSchemaGenerator_DataLayerProviders(t *testing.T) {
+	schema := newTestProductSchema()
+	gen := NewSchemaGenerator(newMemorySchemaStorage(schema)).WithDataLayer(models.DataLayerPGX)
+	err := gen.GenerateFromSchema(schema.ID, t.TempDir(), "example.com/shop", "mysql")
+	assert.ErrorContains(t, err, "does not support the mysql database provider")
+
+	_, err = models.ParseDataLayer("ent")
+	assert.Error(t, err)
+}
+
+func TestSchemaGenerator_DataLayerFeatures(t *testing.T) {
+	for _, feature := range []string{models.FeatureBulk, models.FeatureExport, models.FeatureGraphQL} {
+		t.Run(feature, func(t *testing.T) {
+			schema := newTestProductSchema()
+			dir := t.TempDir()
+			gen := NewSchemaGenerator(newMemorySchemaStorage(schema)).WithFeatures(feature).WithDataLayer(models.DataLayerSQLX)
+			err := gen.GenerateFromSchema(schema.ID, dir, "example.com/shop", "postgres")
+			assert.ErrorContains(t, err, "does not support the "+feature+" feature")
+
+			// Nothing is written for a project that would miss endpoints
+			entries, err := os.ReadDir(dir)
+			require.NoError(t, err)
+			assert.Empty(t, entries)
+		})
+	}
+
+	schema := newTestProductSchema()
+	schema.Fields = append(schema.Fields,
+		models.SchemaField{Name: "category_id", Type: "string", DisplayName: "Category ID"},
+		models.SchemaField{Name: "category", Type: "relation", DisplayName: "Category", Relation: &models.RelationConfig{Type: "many_to_one", Target: "Category", ForeignKey: "category_id", Populate: true}},
+	)
+	gen := NewSchemaGenerator(newMemorySchemaStorage(schema)).WithDataLayer(models.DataLayerPGX)
+	err := gen.GenerateFromSchema(schema.ID, t.TempDir(), "example.com/shop", "postgres")
+	assert.ErrorContains(t, err, "does not support including Product.category")
+}
+
+func TestSchemaGenerator_SQLMigrationsRunInWrittenOrder(t *testing.T) {
+	schema := newTestProductSchema()
+	gen := NewSchemaGenerator(newMemorySchemaStorage(schema)).
+		WithFeatures(models.FeatureSearch, models.FeatureEvents).
+		WithDataLayer(models.DataLayerSQLX)
+	dir := generateTestProject(t, gen, "sqlite", schema)
+
+	migrations, err := filepath.Glob(filepath.Join(dir, "migrations", "*.sql"))
+	require.NoError(t, err)
+	sort.Strings(migrations)
+	require.Len(t, migrations, 3)
+	assert.True(t, strings.HasSuffix(migrations[0], "_create_products.sql"), "the table must be created first")
+
+	versions := make(map[string]bool)
+	for _, migration := range migrations {
+		version, _, _ := strings.Cut(filepath.Base(migration), "_")
+		assert.False(t, versions[version], "migration versions must be unique")
+		versions[version] = true
+	}
+
+	// Regenerating keeps the versions
+	require.NoError(t, gen.GenerateFromSchema(schema.ID, dir, "example.com/shop", "sqlite"))
+	regenerated, err := filepath.Glob(filepath.Join(dir, "migrations", "*.sql"))
+	require.NoError(t, err)
+	sort.Strings(regenerated)
+	assert.Equal(t, migrations, regenerated)
+}
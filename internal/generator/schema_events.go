@@ -0,0 +1,283 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vibercode/cli/internal/models"
+	"github.com/vibercode/cli/internal/templates"
+	"github.com/vibercode/cli/pkg/ui"
+)
+
+// EventsTemplateData contains the template data for the events feature
+type EventsTemplateData struct {
+	*EnhancedSchema
+	PayloadFields []EventPayloadField
+}
+
+// EventPayloadField is a schema field carried by the payload of resource events
+type EventPayloadField struct {
+	GoName   string
+	GoType   string
+	JSONName string
+}
+
+// WithEventPublishers generates the broker publishers (nats, kafka) of the events feature
+func (g *SchemaGenerator) WithEventPublishers(publishers ...string) *SchemaGenerator {
+	g.eventPublishers = append(g.eventPublishers, publishers...)
+	return g
+}
+
+// generateEventsFeature generates the outbox, relay, publishers and event payloads of a schema.
+// The service records events through the transactor of the repositories, so they are
+// written with the change or not at all.
+func (g *SchemaGenerator) generateEventsFeature(data *EnhancedSchema, outputPath string) error {
+	eventsData := g.prepareEventsData(data)
+	snake := data.Names.SnakeCase
+
+	type file struct {
+		template string
+		path     string
+	}
+	files := []file{
+		{templates.EventsPackageTemplate, filepath.Join("internal", "events", "events.go")},
+		{templates.EventsRelayTemplate, filepath.Join("internal", "events", "relay.go")},
+		{templates.EventsMemoryStoreTemplate, filepath.Join("internal", "events", "memory.go")},
+		{templates.EventsChannelPublisherTemplate, filepath.Join("internal", "events", "channel.go")},
+		{templates.EventsWebhookPublisherTemplate, filepath.Join("internal", "events", "webhook.go")},
+		{templates.EventsRelayTestTemplate, filepath.Join("internal", "events", "relay_test.go")},
+		{templates.SchemaEventsTemplate, filepath.Join("internal", "events", snake+".go")},
+		{templates.SchemaEventsServiceTestTemplate, filepath.Join("internal", "services", snake+"_events_test.go")},
+	}
+
+	var modules []string
+	for _, publisher := range g.eventPublisherNames(data) {
+		switch publisher {
+		case models.EventPublisherNATS:
+			files = append(files, file{templates.EventsNATSPublisherTemplate, filepath.Join("internal", "events", "nats.go")})
+			modules = append(modules, "github.com/nats-io/nats.go")
+		case models.EventPublisherKafka:
+			files = append(files, file{templates.EventsKafkaPublisherTemplate, filepath.Join("internal", "events", "kafka.go")})
+			modules = append(modules, "github.com/segmentio/kafka-go")
+		default:
+			ui.PrintWarning("Unknown event publisher " + publisher + ", ignoring")
+		}
+	}
+
+	// The repository transactor and outbox follow the data layer of the CRUD repository
+	switch data.DataLayer {
+	case models.DataLayerSQLX:
+		files = append(files, file{templates.SQLXOutboxRepositoryTemplate, filepath.Join("internal", "repositories", "outbox_repository.go")})
+	case models.DataLayerSQLC, models.DataLayerPGX:
+		files = append(files, file{templates.PGXOutboxRepositoryTemplate, filepath.Join("internal", "repositories", "outbox_repository.go")})
+	default:
+		files = append(files,
+			file{templates.MongoTransactionTemplate, filepath.Join("internal", "repositories", "transaction.go")},
+			file{templates.MongoOutboxRepositoryTemplate, filepath.Join("internal", "repositories", "outbox_repository.go")})
+	}
+
+	for _, file := range files {
+		if err := g.generateGoFile(file.template, eventsData, filepath.Join(outputPath, file.path)); err != nil {
+			return err
+		}
+	}
+
+	if err := g.writeEventSchema(eventsData, layoutPath(outputPath, "internal", "events", "schemas", snake+".json")); err != nil {
+		return fmt.Errorf("failed to generate event schema: %w", err)
+	}
+
+	if data.DataLayer == models.DataLayerGORM {
+		ui.PrintInfo("Events are recorded in MongoDB transactions, which require a replica set")
+	} else if err := g.writeSQLMigration(outputPath, "create_outbox_events", "Create the outbox_events table",
+		sqlOutboxTable(data.DBProvider), "DROP TABLE IF EXISTS outbox_events;"); err != nil {
+		return fmt.Errorf("failed to generate outbox migration: %w", err)
+	}
+
+	if len(modules) > 0 {
+		ui.PrintInfo("Event publishers require " + strings.Join(modules, ", ") + ", run 'go mod tidy' after generation")
+	}
+	ui.PrintInfo("Wire " + data.Names.PascalCase + "Service.WithEvents(repositories.NewTransactor(db), outbox) and run events.NewRelay(outbox, publisher) in the background")
+	return nil
+}
+
+// prepareEventsData collects the payload fields, leaving out sensitive, relation and
+// unsupported fields
+func (g *SchemaGenerator) prepareEventsData(data *EnhancedSchema) *EventsTemplateData {
+	eventsData := &EventsTemplateData{EnhancedSchema: data}
+	for _, field := range data.Fields {
+		if !isEventPayloadField(field.SchemaField) {
+			continue
+		}
+		eventsData.PayloadFields = append(eventsData.PayloadFields, EventPayloadField{
+			GoName:   field.Names.PascalCase,
+			GoType:   field.GoType,
+			JSONName: toSnakeCase(field.Name),
+		})
+	}
+	return eventsData
+}
+
+// eventPublisherNames returns the broker publishers requested on the generator and the schema
+func (g *SchemaGenerator) eventPublisherNames(data *EnhancedSchema) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, publisher := range append(append([]string{}, g.eventPublishers...), data.GetEventsConfig().Publishers...) {
+		publisher = strings.ToLower(strings.TrimSpace(publisher))
+		if publisher != "" && !seen[publisher] {
+			seen[publisher] = true
+			names = append(names, publisher)
+		}
+	}
+	return names
+}
+
+// isEventPayloadField reports whether a field is carried by event payloads
+func isEventPayloadField(field *models.SchemaField) bool {
+	kind := fieldKind(field)
+	return kind != "" && kind != "secret" && !isRelationField(field)
+}
+
+// writeEventSchema writes the JSON Schema of the event payload of a resource
+func (g *SchemaGenerator) writeEventSchema(data *EventsTemplateData, path string) error {
+	properties := map[string]interface{}{
+		"id":         map[string]interface{}{"type": "string", "pattern": "^[0-9a-f]{24}$"},
+		"created_at": map[string]interface{}{"type": "string", "format": "date-time"},
+		"updated_at": map[string]interface{}{"type": "string", "format": "date-time"},
+	}
+	required := []string{"id", "created_at", "updated_at"}
+
+	for _, field := range data.ResourceSchema.Fields {
+		if !isEventPayloadField(&field) {
+			continue
+		}
+		name := toSnakeCase(field.Name)
+		properties[name] = eventSchemaProperty(&field)
+		required = append(required, name)
+	}
+
+	pascal := data.Names.PascalCase
+	schema := map[string]interface{}{
+		"$schema":              "https://json-schema.org/draft/2020-12/schema",
+		"title":                pascal + "Payload",
+		"description":          fmt.Sprintf("Payload of the %sCreated, %sUpdated and %sDeleted events", pascal, pascal, pascal),
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+
+	content, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(content, '\n'), 0644)
+}
+
+// eventSchemaProperty returns the JSON Schema of a payload field, derived from its type
+// and validation rules
+func eventSchemaProperty(field *models.SchemaField) map[string]interface{} {
+	property := make(map[string]interface{})
+	switch fieldKind(field) {
+	case "int":
+		property["type"] = "integer"
+	case "float":
+		property["type"] = "number"
+	case "bool":
+		property["type"] = "boolean"
+	case "time":
+		property["type"] = "string"
+		property["format"] = "date-time"
+	case "uuid":
+		property["type"] = "string"
+		property["format"] = "uuid"
+	case "money":
+		// Amounts are encoded as strings to keep their precision, missing amounts as null
+		currency := map[string]interface{}{"type": "string", "pattern": "^[A-Z]{3}$"}
+		if codes := field.AllowedCurrencies(); len(codes) > 0 {
+			currency = map[string]interface{}{"type": "string", "enum": codes}
+		}
+		property["type"] = []string{"object", "null"}
+		property["properties"] = map[string]interface{}{
+			"amount":   map[string]interface{}{"type": "string", "pattern": `^-?[0-9]+(\.[0-9]+)?$`},
+			"currency": currency,
+		}
+		property["required"] = []string{"amount", "currency"}
+		property["additionalProperties"] = false
+	case "enum":
+		property["type"] = "string"
+		property["enum"] = field.Validation.AllowedValues
+	case "json":
+		// Any JSON value
+	default:
+		property["type"] = "string"
+		switch field.Type {
+		case "email":
+			property["format"] = "email"
+		case "url":
+			property["format"] = "uri"
+		}
+	}
+
+	if field.Description != "" {
+		property["description"] = field.Description
+	}
+	if validation := field.Validation; validation != nil {
+		switch property["type"] {
+		case "string":
+			if validation.MinLength != nil {
+				property["minLength"] = *validation.MinLength
+			}
+			if validation.MaxLength != nil {
+				property["maxLength"] = *validation.MaxLength
+			}
+			if validation.Pattern != "" && property["pattern"] == nil {
+				property["pattern"] = validation.Pattern
+			}
+		case "integer", "number":
+			if validation.Min != nil {
+				property["minimum"] = *validation.Min
+			}
+			if validation.Max != nil {
+				property["maximum"] = *validation.Max
+			}
+		}
+	}
+	return property
+}
+
+// sqlOutboxTable returns the statements creating the outbox table of the events feature
+func sqlOutboxTable(provider string) string {
+	timestamp := sqlTimestampType(provider)
+	payload := "JSONB"
+	switch provider {
+	case "mysql":
+		payload = "JSON"
+	case "sqlite":
+		payload = "TEXT"
+	}
+
+	index := "CREATE INDEX IF NOT EXISTS"
+	if provider == "mysql" {
+		index = "CREATE INDEX"
+	}
+
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS outbox_events (
+    id VARCHAR(36) PRIMARY KEY,
+    event_type VARCHAR(255) NOT NULL,
+    aggregate VARCHAR(255) NOT NULL,
+    aggregate_id VARCHAR(255) NOT NULL,
+    payload %s NOT NULL,
+    occurred_at %s NOT NULL,
+    published_at %s,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT
+);
+
+%s idx_outbox_events_pending ON outbox_events (published_at, occurred_at);`, payload, timestamp, timestamp, index)
+}
@@ -0,0 +1,76 @@
+package generator
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/vibercode/cli/internal/models"
+)
+
+func TestSchemaGenerator_EventsFeature(t *testing.T) {
+	schema := newTestProductSchema()
+	schema.Fields = append(schema.Fields,
+		models.SchemaField{Name: "secret", Type: "password", DisplayName: "Secret"},
+		models.SchemaField{Name: "status", Type: "enum", DisplayName: "Status", Validation: &models.FieldValidation{AllowedValues: []string{"draft", "live"}}},
+	)
+	schema.Options = &models.GenerationOptions{
+		Features: []string{models.FeatureEvents},
+		Events:   &models.EventsConfig{Publishers: []string{models.EventPublisherKafka}},
+	}
+
+	gen := NewSchemaGenerator(newMemorySchemaStorage(schema)).WithEventPublishers(models.EventPublisherNATS)
+	dir := generateTestProject(t, gen, "postgres", schema)
+
+	assertGeneratedFiles(t, dir,
+		generatedFile{path: "internal/events/events.go"},
+		generatedFile{path: "internal/events/relay.go"},
+		generatedFile{path: "internal/events/memory.go"},
+		generatedFile{path: "internal/events/channel.go"},
+		generatedFile{path: "internal/events/webhook.go"},
+		generatedFile{path: "internal/events/nats.go"},
+		generatedFile{path: "internal/events/kafka.go"},
+		generatedFile{path: "internal/events/relay_test.go"},
+		generatedFile{
+			path:     "internal/events/product.go",
+			contains: []string{"Status:      product.Status,"},
+			// sensitive fields must not be part of event payloads
+			excludes: []string{"Secret"},
+		},
+		generatedFile{
+			path:     "internal/events/schemas/product.json",
+			contains: []string{`"title": "ProductPayload"`, `"enum": [`},
+			excludes: []string{"secret"},
+		},
+		generatedFile{path: "internal/services/product_events_test.go"},
+		generatedFile{path: "internal/repositories/transaction.go"},
+		generatedFile{path: "internal/repositories/outbox_repository.go"},
+		generatedFile{
+			path: "internal/services/product_service.go",
+			contains: []string{
+				"func (s *ProductService) WithEvents(transactor events.TransactionRunner, outbox events.Outbox) *ProductService {",
+				"return s.recordEvent(ctx, events.ProductCreated, product)",
+				"return s.recordEvent(ctx, events.ProductDeleted, product)",
+			},
+		},
+	)
+
+	assertGoFilesParse(t, filepath.Join(dir, "internal"))
+}
+
+func TestSchemaGenerator_EventsFeatureDataLayer(t *testing.T) {
+	schema := newTestProductSchema()
+	gen := NewSchemaGenerator(newMemorySchemaStorage(schema)).
+		WithFeatures(models.FeatureEvents).
+		WithDataLayer(models.DataLayerSQLX)
+	dir := generateTestProject(t, gen, "sqlite", schema)
+
+	assertGeneratedFiles(t, dir,
+		generatedFile{
+			path:     "internal/repositories/outbox_repository.go",
+			contains: []string{"func NewOutboxRepository(db *sqlx.DB) *OutboxRepository {"},
+		},
+		generatedFile{path: "migrations/*_create_outbox_events.sql", contains: []string{"payload TEXT NOT NULL"}},
+		generatedFile{path: "internal/events/nats.go", missing: true},
+	)
+	assertGoFilesParse(t, filepath.Join(dir, "internal"))
+}
@@ -0,0 +1,96 @@
+package generator
+
+import (
+	"path/filepath"
+
+	"github.com/vibercode/cli/internal/models"
+	"github.com/vibercode/cli/internal/templates"
+	"github.com/vibercode/cli/pkg/ui"
+)
+
+// ExportField describes a field that can be written by export endpoints
+type ExportField struct {
+	JSONName string
+	Column   string
+	GoName   string
+}
+
+// ExportTemplateData contains the template data for the export feature
+type ExportTemplateData struct {
+	*EnhancedSchema
+	Export        *models.ExportConfig
+	ExportFields  []ExportField
+	DefaultFields []string
+	HasSearch     bool
+}
+
+// generateExportFeature generates streaming export repositories, services and handlers for a schema
+func (g *SchemaGenerator) generateExportFeature(data *EnhancedSchema, outputPath string) error {
+	if data.DBProvider == "mongodb" {
+		ui.PrintWarning("Export endpoints require a SQL database provider, skipping for " + data.Name)
+		return nil
+	}
+
+	exportData := g.prepareExportData(data)
+
+	snake := data.Names.SnakeCase
+	files := []struct {
+		template string
+		path     string
+	}{
+		{templates.ExportPackageTemplate, filepath.Join("internal", "export", "export.go")},
+		{templates.ExportPackageTestTemplate, filepath.Join("internal", "export", "export_test.go")},
+		{templates.SchemaExportRepositoryTemplate, filepath.Join("internal", "repositories", snake+"_export_repository.go")},
+		{templates.SchemaExportServiceTemplate, filepath.Join("internal", "services", snake+"_export_service.go")},
+		{templates.SchemaExportHandlerTemplate, filepath.Join("internal", "handlers", snake+"_export_handler.go")},
+	}
+
+	for _, file := range files {
+		if err := g.generateSchemaFile(file.template, exportData, filepath.Join(outputPath, file.path)); err != nil {
+			return err
+		}
+	}
+
+	data.Endpoints = append(data.Endpoints, models.FeatureExport)
+	return nil
+}
+
+// prepareExportData collects the exportable columns, leaving out sensitive and excluded fields
+func (g *SchemaGenerator) prepareExportData(data *EnhancedSchema) *ExportTemplateData {
+	exportData := &ExportTemplateData{
+		EnhancedSchema: data,
+		Export:         data.GetExportConfig(),
+	}
+
+	exportable := make(map[string]string)
+	for _, field := range data.GetExportableFields() {
+		if g.isFieldReadOnly(&field) {
+			continue
+		}
+		name := toSnakeCase(field.Name)
+		exportable[field.Name] = name
+		exportData.ExportFields = append(exportData.ExportFields, ExportField{
+			JSONName: name,
+			Column:   columnName(&field),
+			GoName:   toPascalCase(field.Name),
+		})
+		if field.Type == "string" || field.Type == "text" {
+			exportData.HasSearch = true
+		}
+	}
+
+	for _, name := range exportData.Export.Fields {
+		switch name {
+		case "id", "created_at", "updated_at":
+			exportData.DefaultFields = append(exportData.DefaultFields, name)
+			continue
+		}
+		if column, ok := exportable[name]; ok {
+			exportData.DefaultFields = append(exportData.DefaultFields, column)
+		} else {
+			ui.PrintWarning("Export field " + name + " is not exportable for " + data.Name + ", ignoring")
+		}
+	}
+
+	return exportData
+}
@@ -0,0 +1,42 @@
+package generator
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/vibercode/cli/internal/models"
+)
+
+func TestSchemaGenerator_ExportFeature(t *testing.T) {
+	schema := newTestProductSchema()
+	schema.Fields = append(schema.Fields, models.SchemaField{Name: "secret", Type: "password", DisplayName: "Secret"})
+	schema.Options = &models.GenerationOptions{
+		Export: &models.ExportConfig{Fields: []string{"sku", "name", "secret"}, Exclude: []string{"description"}},
+	}
+
+	gen := NewSchemaGenerator(newMemorySchemaStorage(schema)).WithFeatures(models.FeatureExport)
+	dir := generateTestProject(t, gen, "postgres", schema)
+
+	assertGeneratedFiles(t, dir,
+		generatedFile{path: "internal/export/export.go"},
+		generatedFile{path: "internal/export/export_test.go", contains: []string{"func TestEscapeCSVCell(", "func TestNegotiate(", "func TestSelectFields("}},
+		generatedFile{path: "internal/repositories/product_export_repository.go"},
+		generatedFile{
+			path: "internal/services/product_export_service.go",
+			contains: []string{
+				`"stock": "stock"`,
+				"productExportDefaultFields = []string{\n\t\"sku\",\n\t\"name\",\n}",
+			},
+			// password fields must never be exported, nor excluded ones
+			excludes: []string{`"secret"`, `"description"`},
+		},
+		generatedFile{
+			path:     "internal/handlers/product_export_handler.go",
+			contains: []string{`r.GET("/products/export", handler.Export)`},
+		},
+	)
+
+	for _, pkg := range []string{"export", "repositories", "services", "handlers"} {
+		assertGoFilesParse(t, filepath.Join(dir, "internal", pkg))
+	}
+}
@@ -0,0 +1,156 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vibercode/cli/internal/models"
+)
+
+// schemaFeature generates the artifacts of an opt-in schema feature
+type schemaFeature struct {
+	Name     string
+	Generate func(g *SchemaGenerator, data *EnhancedSchema, outputPath string) error
+}
+
+// schemaFeatures lists the opt-in features in generation order
+var schemaFeatures = []schemaFeature{
+	{Name: models.FeatureBulk, Generate: (*SchemaGenerator).generateBulkFeature},
+	{Name: models.FeatureExport, Generate: (*SchemaGenerator).generateExportFeature},
+	{Name: models.FeatureGraphQL, Generate: (*SchemaGenerator).generateGraphQLFeature},
+	{Name: models.FeatureGRPC, Generate: (*SchemaGenerator).generateGRPCFeature},
+	{Name: models.FeatureEvents, Generate: (*SchemaGenerator).generateEventsFeature},
+	{Name: models.FeatureCaching, Generate: (*SchemaGenerator).generateCachingFeature},
+	{Name: models.FeatureSearch, Generate: (*SchemaGenerator).generateSearchFeature},
+	{Name: models.FeatureWebhooks, Generate: (*SchemaGenerator).generateWebhooksFeature},
+	{Name: models.FeatureObservability, Generate: (*SchemaGenerator).generateObservabilityFeature},
+}
+
+// featureRequirements lists the features an opt-in feature builds on, enabled along with it
+var featureRequirements = map[string][]string{
+	models.FeatureWebhooks: {models.FeatureEvents},
+}
+
+// WithFeatures enables additional features for every generated schema
+func (g *SchemaGenerator) WithFeatures(features ...string) *SchemaGenerator {
+	g.features = append(g.features, features...)
+	return g
+}
+
+// applyFeatures merges the generator-level features into the schema options, along with
+// the features they require
+func (g *SchemaGenerator) applyFeatures(schema *models.ResourceSchema) {
+	if len(g.features) == 0 && schema.Options == nil {
+		return
+	}
+	if schema.Options == nil {
+		schema.Options = &models.GenerationOptions{}
+	}
+	for _, feature := range g.features {
+		if !schema.HasFeature(feature) {
+			schema.Options.Features = append(schema.Options.Features, feature)
+		}
+	}
+	for _, feature := range schema.Options.Features {
+		for _, required := range featureRequirements[feature] {
+			if !schema.HasFeature(required) {
+				schema.Options.Features = append(schema.Options.Features, required)
+			}
+		}
+	}
+}
+
+// generateFeatures generates every feature enabled for the schema
+func (g *SchemaGenerator) generateFeatures(data *EnhancedSchema, outputPath string) error {
+	for _, feature := range schemaFeatures {
+		if !data.HasFeature(feature.Name) {
+			continue
+		}
+		if err := feature.Generate(g, data, outputPath); err != nil {
+			return fmt.Errorf("failed to generate %s feature: %w", feature.Name, err)
+		}
+	}
+	return nil
+}
+
+// columnName returns the database column for a schema field
+func columnName(field *models.SchemaField) string {
+	if field.Database != nil && field.Database.ColumnName != "" {
+		return field.Database.ColumnName
+	}
+	return toSnakeCase(field.Name)
+}
+
+// valueKind returns the kind used to decode untyped (CSV, query string) values for a field
+func valueKind(field *models.SchemaField) string {
+	switch field.GetGoType() {
+	case "int64":
+		return "int"
+	case "float64":
+		return "float"
+	case "bool":
+		return "bool"
+	case "json.RawMessage":
+		return "json"
+	default:
+		return "string"
+	}
+}
+
+// isRelationField reports whether a field is a relation
+func isRelationField(field *models.SchemaField) bool {
+	return field.Type == "relation" || field.Type == "relation_array"
+}
+
+// fieldKind classifies a field by how it is converted between Go and API types
+// such as GraphQL or protobuf. Fields returning an empty kind are not exposed.
+func fieldKind(field *models.SchemaField) string {
+	if field.IsSensitive() {
+		return "secret"
+	}
+	switch field.Type {
+	case "enum":
+		if field.Validation != nil && len(field.Validation.AllowedValues) > 0 {
+			return "enum"
+		}
+	case "uuid":
+		return "uuid"
+	case "currency":
+		return "money"
+	}
+
+	switch field.GetGoType() {
+	case "string":
+		return "string"
+	case "int64":
+		return "int"
+	case "float64":
+		return "float"
+	case "bool":
+		return "bool"
+	case "time.Time":
+		return "time"
+	case "json.RawMessage":
+		return "json"
+	}
+	return ""
+}
+
+// enumValueName converts a stored value into an upper snake case enum value name
+// that is valid in GraphQL and protobuf
+func enumValueName(value string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(toSnakeCase(value)) {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	name := b.String()
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "V_" + name
+	}
+	return name
+}
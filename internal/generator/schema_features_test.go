@@ -0,0 +1,169 @@
+package generator
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vibercode/cli/internal/models"
+)
+
+// memorySchemaStorage is an in-memory SchemaStorage for generator tests
+type memorySchemaStorage struct {
+	schemas map[string]*models.ResourceSchema
+}
+
+func newMemorySchemaStorage(schemas ...*models.ResourceSchema) *memorySchemaStorage {
+	storage := &memorySchemaStorage{schemas: make(map[string]*models.ResourceSchema)}
+	for _, schema := range schemas {
+		storage.schemas[schema.ID] = schema
+	}
+	return storage
+}
+
+func (s *memorySchemaStorage) Save(schema *models.ResourceSchema) error {
+	s.schemas[schema.ID] = schema
+	return nil
+}
+
+func (s *memorySchemaStorage) Load(id string) (*models.ResourceSchema, error) {
+	schema, ok := s.schemas[id]
+	if !ok {
+		return nil, fmt.Errorf("schema %s not found", id)
+	}
+	return schema, nil
+}
+
+func (s *memorySchemaStorage) LoadByName(name string) (*models.ResourceSchema, error) {
+	for _, schema := range s.schemas {
+		if schema.Name == name {
+			return schema, nil
+		}
+	}
+	return nil, fmt.Errorf("schema %s not found", name)
+}
+
+func (s *memorySchemaStorage) List() ([]*models.ResourceSchema, error) {
+	var schemas []*models.ResourceSchema
+	for _, schema := range s.schemas {
+		schemas = append(schemas, schema)
+	}
+	return schemas, nil
+}
+
+func (s *memorySchemaStorage) Delete(id string) error {
+	delete(s.schemas, id)
+	return nil
+}
+
+func (s *memorySchemaStorage) Search(query string) ([]*models.ResourceSchema, error) {
+	return s.List()
+}
+
+func (s *memorySchemaStorage) GetVersions(id string) ([]*models.ResourceSchema, error) {
+	schema, err := s.Load(id)
+	if err != nil {
+		return nil, err
+	}
+	return []*models.ResourceSchema{schema}, nil
+}
+
+// newTestProductSchema returns a schema exercising the common field types
+func newTestProductSchema() *models.ResourceSchema {
+	return &models.ResourceSchema{
+		ID:          "product-1",
+		Name:        "Product",
+		DisplayName: "Product",
+		Names: &models.NamingConventions{
+			Singular:     "product",
+			Plural:       "products",
+			PascalCase:   "Product",
+			PascalPlural: "Products",
+			CamelCase:    "product",
+			CamelPlural:  "products",
+			SnakeCase:    "product",
+			SnakePlural:  "products",
+			KebabCase:    "product",
+			KebabPlural:  "products",
+			TableName:    "products",
+		},
+		Fields: []models.SchemaField{
+			{Name: "sku", Type: "string", DisplayName: "SKU", Required: true, Database: &models.DatabaseFieldConfig{Unique: true}},
+			{Name: "name", Type: "string", DisplayName: "Name", Required: true},
+			{Name: "description", Type: "text", DisplayName: "Description"},
+			{Name: "stock", Type: "number", DisplayName: "Stock"},
+			{Name: "active", Type: "boolean", DisplayName: "Active"},
+		},
+		Database: &models.DatabaseConfig{Provider: "postgres", TableName: "products"},
+	}
+}
+
+// assertGoFilesParse checks that every generated Go file under dir is syntactically valid
+func assertGoFilesParse(t *testing.T, dir string) {
+	t.Helper()
+	fset := token.NewFileSet()
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(path, ".go") {
+			return err
+		}
+		if _, parseErr := parser.ParseFile(fset, path, nil, parser.AllErrors); parseErr != nil {
+			t.Errorf("generated file %s does not parse: %v", path, parseErr)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+// generateTestProject generates the given schemas into a temporary project and returns its root
+func generateTestProject(t *testing.T, gen *SchemaGenerator, provider string, schemas ...*models.ResourceSchema) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, schema := range schemas {
+		require.NoError(t, gen.GenerateFromSchema(schema.ID, dir, "example.com/shop", provider))
+	}
+	return dir
+}
+
+// generatedFile describes what a generated file must and must not contain
+type generatedFile struct {
+	path     string // slash-separated, relative to the project root and may be a glob
+	contains []string
+	excludes []string
+	missing  bool // the file must not be generated
+}
+
+// assertGeneratedFiles checks each file of a generated project against its expectations
+func assertGeneratedFiles(t *testing.T, dir string, files ...generatedFile) {
+	t.Helper()
+	for _, file := range files {
+		matches, err := filepath.Glob(filepath.Join(dir, filepath.FromSlash(file.path)))
+		require.NoError(t, err)
+		if file.missing {
+			assert.Empty(t, matches, file.path)
+			continue
+		}
+		require.Len(t, matches, 1, file.path)
+		content, err := os.ReadFile(matches[0])
+		require.NoError(t, err)
+		for _, s := range file.contains {
+			assert.Contains(t, string(content), s, file.path)
+		}
+		for _, s := range file.excludes {
+			assert.NotContains(t, string(content), s, file.path)
+		}
+	}
+}
+
+// readGeneratedFile returns the content of a file in a generated project
+func readGeneratedFile(t *testing.T, dir, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(path)))
+	require.NoError(t, err)
+	return string(content)
+}
@@ -1,9 +1,12 @@
 package generator
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -13,7 +16,16 @@ import (
 
 // SchemaGenerator generates code from resource schemas
 type SchemaGenerator struct {
-	storage models.SchemaStorage
+	storage         models.SchemaStorage
+	features        []string
+	grpcGateway     bool
+	httpFramework   models.HTTPFramework
+	dataLayer       models.DataLayer
+	eventPublishers []string
+	apiVersion      string
+	deprecations    map[string]string
+	warnedTemplates map[string]bool           // Outdated template overrides already reported
+	resource        *models.NamingConventions // Resource being generated
 }
 
 // NewSchemaGenerator creates a new schema generator
@@ -30,9 +42,35 @@ func (g *SchemaGenerator) GenerateFromSchema(schemaID, outputPath, module string
 	if err != nil {
 		return fmt.Errorf("failed to load schema: %w", err)
 	}
+	g.applyFeatures(schema)
+
+	if !g.dataLayer.SupportsProvider(dbProvider) {
+		return fmt.Errorf("the %s data layer does not support the %s database provider", g.dataLayer.GetDisplayName(), dbProvider)
+	}
+	if err := g.checkDataLayer(schema); err != nil {
+		return err
+	}
+	if layout := findProjectLayout(outputPath); layout != nil {
+		if err := layout.preset.CheckResource(schema.Names); err != nil {
+			return err
+		}
+		// The SQL repositories share unexported helpers, such as the connection of the
+		// current transaction, which packages by resource can't reach
+		if layer := g.dataLayer.OrDefault(); layout.preset.ByFeature() && layer != models.DataLayerGORM {
+			return fmt.Errorf("the %s layout does not support the %s data layer, use the GORM data layer", layout.preset, layer.GetDisplayName())
+		}
+	}
+	g.resource = schema.Names
+
+	// Resolve the API version before any file is written
+	versionPlan, err := g.planAPIVersion(schema, outputPath, module)
+	if err != nil {
+		return err
+	}
 
 	// Prepare template data
 	data := g.prepareTemplateData(schema, module, dbProvider)
+	data.Includes = g.prepareIncludes(data, outputPath)
 
 	// Generate files
 	generators := map[string]string{
@@ -41,21 +79,83 @@ func (g *SchemaGenerator) GenerateFromSchema(schemaID, outputPath, module string
 		"service":    filepath.Join("internal", "services", schema.Names.SnakeCase+"_service.go"),
 		"handler":    filepath.Join("internal", "handlers", schema.Names.SnakeCase+"_handler.go"),
 	}
-
-	schemaTemplates := templates.GetSchemaTemplates()
+	// The sqlc, sqlx and pgx data layers generate their own repositories
+	if data.DataLayer != models.DataLayerGORM {
+		delete(generators, "repository")
+	}
 
 	for templateName, relativePath := range generators {
-		template := schemaTemplates[templateName]
+		// Projects and users may override the built-in templates in .vibercode/templates
+		template, err := g.schemaTemplate(templateName, outputPath)
+		if err != nil {
+			return err
+		}
 		fullPath := filepath.Join(outputPath, relativePath)
 
-		if err := g.generateFile(template, data, fullPath); err != nil {
+		generate := g.generateFile
+		if templateName == "handler" {
+			generate = g.generateHandlerFile
+		}
+		if err := generate(template, data, fullPath); err != nil {
 			return fmt.Errorf("failed to generate %s: %w", templateName, err)
 		}
 	}
 
-	// Generate migration file
-	if err := g.generateMigration(schema, outputPath, dbProvider); err != nil {
-		return fmt.Errorf("failed to generate migration: %w", err)
+	// Generate the route tests and framework helpers
+	if err := g.generateHTTPSupport(data, outputPath); err != nil {
+		return err
+	}
+
+	// SQL providers persist ObjectIDs through a GORM serializer
+	if data.UsesGORM() {
+		serializerPath := filepath.Join(outputPath, "internal", "models", "objectid.go")
+		if err := g.generateFile(templates.ObjectIDSerializerTemplate, data, serializerPath); err != nil {
+			return fmt.Errorf("failed to generate ObjectID serializer: %w", err)
+		}
+	}
+
+	// Generate migration file, or the repository, queries and SQL migration of the data layer
+	if data.DataLayer == models.DataLayerGORM {
+		if err := g.generateMigration(schema, outputPath, module, dbProvider); err != nil {
+			return fmt.Errorf("failed to generate migration: %w", err)
+		}
+	} else if err := g.generateDataLayer(data, outputPath); err != nil {
+		return err
+	}
+
+	// Generate the geo package and Coordinates type of coordinates fields
+	if err := g.generateGeo(data, outputPath); err != nil {
+		return err
+	}
+
+	// Generate the money package of currency fields
+	if err := g.generateMoney(data, outputPath); err != nil {
+		return err
+	}
+
+	// Generate the upload endpoints and blob storage of file and image fields
+	if err := g.generateUploads(data, outputPath); err != nil {
+		return err
+	}
+
+	// Generate the nested routes of relation fields
+	if err := g.generateRelations(data, outputPath); err != nil {
+		return err
+	}
+
+	// Generate the include graph, sparse fieldsets and relation loader of list and get endpoints
+	if err := g.generateIncludes(data, outputPath); err != nil {
+		return err
+	}
+
+	// Generate opt-in features
+	if err := g.generateFeatures(data, outputPath); err != nil {
+		return err
+	}
+
+	// Record the API version and generate older versions and the OpenAPI documents
+	if err := g.generateAPIVersions(data, outputPath, versionPlan); err != nil {
+		return err
 	}
 
 	return nil
@@ -112,6 +212,10 @@ func (g *SchemaGenerator) extractRelations(schema *models.ResourceSchema) []Rela
 	for _, field := range schema.Fields {
 		if (field.Type == "relation" || field.Type == "relation_array") && field.Relation != nil {
 			target := field.Relation.Target
+			// Targets with their own schema get a real model, no placeholder is needed
+			if target == schema.Name || g.hasSchema(target) {
+				continue
+			}
 			if !seen[target] {
 				relations = append(relations, RelationInfo{
 					Name:        target,
@@ -127,6 +231,15 @@ func (g *SchemaGenerator) extractRelations(schema *models.ResourceSchema) []Rela
 	return relations
 }
 
+// hasSchema reports whether a schema with the given name is stored
+func (g *SchemaGenerator) hasSchema(name string) bool {
+	if g.storage == nil {
+		return false
+	}
+	_, err := g.storage.LoadByName(name)
+	return err == nil
+}
+
 // getRequiredImports returns required imports for the schema
 func (g *SchemaGenerator) getRequiredImports(schema *models.ResourceSchema, dbProvider string) []string {
 	imports := make(map[string]bool)
@@ -135,12 +248,10 @@ func (g *SchemaGenerator) getRequiredImports(schema *models.ResourceSchema, dbPr
 		switch field.Type {
 		case "uuid":
 			imports["github.com/google/uuid"] = true
-		case "decimal", "currency":
+		case "decimal":
 			imports["github.com/shopspring/decimal"] = true
 		case "json", "mixed":
 			imports["encoding/json"] = true
-		case "date", "datetime", "timestamp":
-			imports["time"] = true
 		}
 
 		// Database-specific imports
@@ -153,6 +264,7 @@ func (g *SchemaGenerator) getRequiredImports(schema *models.ResourceSchema, dbPr
 	for imp := range imports {
 		result = append(result, imp)
 	}
+	sort.Strings(result)
 
 	return result
 }
@@ -166,7 +278,15 @@ func (g *SchemaGenerator) generateGoStructField(field *models.SchemaField, dbPro
 
 	var tags []string
 	tags = append(tags, jsonTag)
-	if gormTag != "" {
+	// Missing coordinates are left out of documents, a null would decode as a position
+	if field.IsGeo() {
+		tags = append(tags, fmt.Sprintf(`bson:"%s,omitempty"`, strings.ToLower(fieldName)))
+	}
+	// Related lists are served by the nested relation routes, not stored in documents
+	if field.Type == "relation_array" {
+		tags = append(tags, `bson:"-"`)
+	}
+	if gormTag != "" && g.dataLayer.OrDefault() == models.DataLayerGORM {
 		tags = append(tags, fmt.Sprintf(`gorm:"%s"`, gormTag))
 	}
 
@@ -239,33 +359,65 @@ func (g *SchemaGenerator) generateGoFilterField(field *models.SchemaField) strin
 	
 	// Make filter fields optional (pointers for primitive types)
 	switch fieldType {
-	case "string", "int64", "float64", "bool":
+	case "string", "int64", "float64", "bool", "time.Time":
 		fieldType = "*" + fieldType
 	}
 
-	jsonTag := fmt.Sprintf(`json:"%s,omitempty" form:"%s"`, toSnakeCase(field.Name), toSnakeCase(field.Name))
+	name := toSnakeCase(field.Name)
+	jsonTag := fmt.Sprintf(`json:"%s,omitempty" form:"%s" query:"%s"`, name, name, name)
 	tagString := "`" + jsonTag + "`"
 	
 	return fmt.Sprintf("%s %s %s", fieldName, fieldType, tagString)
 }
 
-// generateGoValidation generates Go validation code
+// generateGoValidation generates Go validation code appending the rejected fields to the
+// fields slice of Validate, reported as one apperrors validation error
 func (g *SchemaGenerator) generateGoValidation(field *models.SchemaField) string {
 	fieldName := toPascalCase(field.Name)
-	
+	jsonName := toSnakeCase(field.Name)
+	reject := func(code, message string) string {
+		return fmt.Sprintf("fields = append(fields, apperrors.Field(%q, %q, %s))", jsonName, code, message)
+	}
+
 	switch field.Type {
 	case "string", "text", "email", "url":
 		if field.Required {
 			return fmt.Sprintf(`if r.%s == "" {
-		return fmt.Errorf("%s is required")
-	}`, fieldName, field.DisplayName)
+		%s
+	}`, fieldName, reject("required", strconv.Quote(field.DisplayName+" is required")))
 		}
 	case "number", "integer":
 		if field.Required {
 			return fmt.Sprintf(`if r.%s <= 0 {
-		return fmt.Errorf("%s must be greater than 0")
-	}`, fieldName, field.DisplayName)
+		%s
+	}`, fieldName, reject("gt", strconv.Quote(field.DisplayName+" must be greater than 0")))
+		}
+	case "coordinates", "location":
+		if field.Required {
+			return fmt.Sprintf(`if r.%s == nil {
+		%s
+	}`, fieldName, reject("required", strconv.Quote(field.DisplayName+" is required")))
+		}
+	case "currency":
+		// Decoded amounts are already checked, Validate covers amounts built in Go
+		checks := []string{fmt.Sprintf(`if err := r.%s.Validate(); err != nil {
+		%s
+	}`, fieldName, reject("invalid", strconv.Quote(field.DisplayName+": ")+" + err.Error()"))}
+		if field.Required {
+			checks = append(checks, fmt.Sprintf(`if r.%s.Currency == "" {
+		%s
+	}`, fieldName, reject("required", strconv.Quote(field.DisplayName+" is required"))))
+		}
+		if codes := field.AllowedCurrencies(); len(codes) > 0 {
+			quoted := make([]string, len(codes))
+			for i, code := range codes {
+				quoted[i] = strconv.Quote(code)
+			}
+			checks = append(checks, fmt.Sprintf(`if r.%s.Currency != "" && !r.%s.In(%s) {
+		%s
+	}`, fieldName, fieldName, strings.Join(quoted, ", "), reject("oneof", strconv.Quote(field.DisplayName+" currency must be one of "+strings.Join(codes, ", ")))))
 		}
+		return strings.Join(checks, "\n\t")
 	}
 	
 	return fmt.Sprintf("// %s validation can be added here if needed", field.DisplayName)
@@ -319,22 +471,8 @@ func (g *SchemaGenerator) isFieldReadOnly(field *models.SchemaField) bool {
 			return true
 		}
 	}
-	return false
-}
-
-// generatePreloads generates GORM preload statements
-func (g *SchemaGenerator) generatePreloads(schema *models.ResourceSchema) string {
-	var preloads []string
-	
-	for _, field := range schema.Fields {
-		if field.Type == "relation" || field.Type == "relation_array" {
-			if field.Relation != nil && field.Relation.Populate {
-				preloads = append(preloads, fmt.Sprintf(`.Preload("%s")`, toPascalCase(field.Name)))
-			}
-		}
-	}
-	
-	return strings.Join(preloads, "")
+	// File and image fields are written through their upload endpoints
+	return field.IsUpload()
 }
 
 // generateSearchFields generates search field conditions
@@ -383,35 +521,84 @@ func generateFieldNamingConventions(name string) *FieldNamingConventions {
 
 // generateFile generates a file from template
 func (g *SchemaGenerator) generateFile(templateStr string, data interface{}, outputPath string) error {
-	// Create directory if it doesn't exist
-	dir := filepath.Dir(outputPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	content, err := g.renderTemplate(templateStr, data)
+	if err != nil {
+		return err
 	}
+	return g.writeGeneratedFile(outputPath, content)
+}
 
-	// Parse template
-	tmpl, err := template.New("generator").Funcs(templates.SchemaHelperFunctions).Parse(templateStr)
+// generateHandlerFile generates a Go file whose framework specific code comes from the
+// HTTP dialect. Unused framework imports are dropped and the result is gofmt'ed.
+func (g *SchemaGenerator) generateHandlerFile(templateStr string, data interface{}, outputPath string) error {
+	return g.generateGoFile(templateStr, data, outputPath)
+}
+
+// generateGoFile generates a Go file from a template importing every package it may
+// need, unused imports are dropped and the result is gofmt'ed
+func (g *SchemaGenerator) generateGoFile(templateStr string, data interface{}, outputPath string) error {
+	content, err := g.renderTemplate(templateStr, data)
 	if err != nil {
-		return fmt.Errorf("failed to parse template: %w", err)
+		return err
+	}
+	formatted, err := formatGoSource(content)
+	if err != nil {
+		return fmt.Errorf("%s: %w", filepath.Base(outputPath), err)
+	}
+	return g.writeGeneratedFile(outputPath, formatted)
+}
+
+// generateSchemaFile generates a feature file, going through generateHandlerFile for the
+// handlers package whose code depends on the HTTP framework
+func (g *SchemaGenerator) generateSchemaFile(templateStr string, data interface{}, outputPath string) error {
+	if filepath.Base(filepath.Dir(outputPath)) == "handlers" && filepath.Ext(outputPath) == ".go" {
+		return g.generateHandlerFile(templateStr, data, outputPath)
 	}
+	return g.generateFile(templateStr, data, outputPath)
+}
 
-	// Create file
-	file, err := os.Create(outputPath)
+// renderTemplate executes a template with the schema helper functions
+func (g *SchemaGenerator) renderTemplate(templateStr string, data interface{}) ([]byte, error) {
+	// Parse template
+	tmpl, err := template.New("generator").Funcs(templates.SchemaHelperFunctions).Parse(templateStr)
 	if err != nil {
-		return fmt.Errorf("failed to create file %s: %w", outputPath, err)
+		return nil, fmt.Errorf("failed to parse template: %w", err)
 	}
-	defer file.Close()
 
 	// Execute template
-	if err := tmpl.Execute(file, data); err != nil {
-		return fmt.Errorf("failed to execute template: %w", err)
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
 	}
+	return buf.Bytes(), nil
+}
+
+// writeGeneratedFile writes generated content, creating the directory if it doesn't exist.
+// Files of projects with a layout preset are placed in the package of the layout.
+func (g *SchemaGenerator) writeGeneratedFile(outputPath string, content []byte) error {
+	files := []placedFile{{path: outputPath, content: content}}
+	if layout := findProjectLayout(filepath.Dir(outputPath)).withResource(g.resource); layout != nil {
+		var err error
+		if files, err = layout.place(outputPath, content); err != nil {
+			return err
+		}
+	}
+
+	for _, file := range files {
+		dir := filepath.Dir(file.path)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", dir, err)
+		}
 
+		if err := os.WriteFile(file.path, file.content, 0644); err != nil {
+			return fmt.Errorf("failed to create file %s: %w", file.path, err)
+		}
+	}
 	return nil
 }
 
 // generateMigration generates database migration file
-func (g *SchemaGenerator) generateMigration(schema *models.ResourceSchema, outputPath, dbProvider string) error {
+func (g *SchemaGenerator) generateMigration(schema *models.ResourceSchema, outputPath, module, dbProvider string) error {
 	// Skip migration generation for MongoDB as it doesn't require schema migrations
 	if dbProvider == "mongodb" {
 		return nil
@@ -426,6 +613,12 @@ import (
 
 // Migration{{.Names.PascalCase}} migrates {{.DisplayName}} table
 func Migration{{.Names.PascalCase}}(db *gorm.DB) error {
+{{- with .Geo}}{{if .Geography}}
+	// Coordinates are stored as PostGIS geography points
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS postgis").Error; err != nil {
+		return err
+	}
+{{- end}}{{end}}
 	return db.AutoMigrate(&models.{{.Names.PascalCase}}{})
 }
 
@@ -435,7 +628,7 @@ func Rollback{{.Names.PascalCase}}(db *gorm.DB) error {
 }
 `
 
-	data := g.prepareTemplateData(schema, "", dbProvider)
+	data := g.prepareTemplateData(schema, module, dbProvider)
 	migrationPath := filepath.Join(outputPath, "migrations", fmt.Sprintf("%s_migration.go", schema.Names.SnakeCase))
 	
 	return g.generateFile(migrationTemplate, data, migrationPath)
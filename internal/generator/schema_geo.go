@@ -0,0 +1,87 @@
+package generator
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/vibercode/cli/internal/models"
+	"github.com/vibercode/cli/internal/templates"
+	"github.com/vibercode/cli/pkg/ui"
+)
+
+// GeoField is the coordinates field the near and bbox list filters apply to
+type GeoField struct {
+	Name      string
+	GoName    string
+	Column    string
+	Key       string
+	Geography bool
+}
+
+// usesGeography reports whether coordinates are stored as a PostGIS geography column,
+// other SQL databases store them as latitude and longitude columns
+func usesGeography(dbProvider string) bool {
+	return dbProvider == "postgres" || dbProvider == "supabase"
+}
+
+// prepareGeoField returns the coordinates field geographic filters apply to, the first
+// one of the schema, or nil without coordinates fields
+func prepareGeoField(schema *models.ResourceSchema, dbProvider string) *GeoField {
+	fields := schema.GetGeoFields()
+	if len(fields) == 0 {
+		return nil
+	}
+	field := fields[0]
+	column := toSnakeCase(field.Name)
+	if field.Database != nil && field.Database.ColumnName != "" {
+		column = field.Database.ColumnName
+	}
+	goName := toPascalCase(field.Name)
+	return &GeoField{
+		Name:      toSnakeCase(field.Name),
+		GoName:    goName,
+		Column:    column,
+		Key:       strings.ToLower(goName),
+		Geography: usesGeography(dbProvider),
+	}
+}
+
+// generateGeo generates the geo package and the Coordinates type of coordinates fields,
+// if the schema has any
+func (g *SchemaGenerator) generateGeo(data *EnhancedSchema, outputPath string) error {
+	if data.Geo == nil {
+		return nil
+	}
+	if len(data.ResourceSchema.GetGeoFields()) > 1 {
+		ui.PrintWarning("Geographic list filters apply to " + data.Geo.Name + ", the first coordinates field of " + data.Name)
+	}
+
+	files := []struct {
+		template string
+		path     string
+	}{
+		{templates.GeoPackageTemplate, filepath.Join("internal", "geo", "geo.go")},
+		{templates.GeoTestTemplate, filepath.Join("internal", "geo", "geo_test.go")},
+		{templates.CoordinatesTemplate, filepath.Join("internal", "models", "coordinates.go")},
+		{templates.CoordinatesTestTemplate, filepath.Join("internal", "models", "coordinates_test.go")},
+	}
+
+	for _, file := range files {
+		if err := g.generateGoFile(file.template, data, filepath.Join(outputPath, file.path)); err != nil {
+			return err
+		}
+	}
+
+	// The default repository stores documents in MongoDB whatever the provider
+	if data.DataLayer == models.DataLayerGORM {
+		ui.PrintInfo("Call EnsureIndexes on the " + data.Names.PascalCase + " repository at startup, near and bbox filters require its 2dsphere index")
+	}
+	switch {
+	case data.DBProvider == "mongodb":
+	case data.Geo.Geography:
+		ui.PrintInfo("Coordinates are stored as PostGIS geography points, the database needs the postgis extension")
+	default:
+		ui.PrintInfo("Coordinates are stored as " + data.Geo.Column + "_lat and " + data.Geo.Column + "_lng columns, near filters are refined by distance in Go")
+	}
+	return nil
+}
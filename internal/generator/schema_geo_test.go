@@ -0,0 +1,87 @@
+package generator
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/vibercode/cli/internal/models"
+)
+
+func TestSchemaGenerator_GeoFields(t *testing.T) {
+	schema := newTestProductSchema()
+	schema.Fields = append(schema.Fields,
+		models.SchemaField{Name: "location", Type: string(models.FieldTypeCoordinates), DisplayName: "Location", Required: true},
+	)
+
+	dir := generateTestProject(t, NewSchemaGenerator(newMemorySchemaStorage(schema)), "postgres", schema)
+
+	assertGeneratedFiles(t, dir,
+		generatedFile{path: "internal/geo/geo.go"},
+		generatedFile{path: "internal/geo/geo_test.go"},
+		generatedFile{path: "internal/models/coordinates.go"},
+		generatedFile{path: "internal/models/coordinates_test.go"},
+		generatedFile{
+			path: "internal/models/product.go",
+			contains: []string{
+				`Location *Coordinates ` + "`" + `json:"location" bson:"location,omitempty" gorm:"column:location;type:geography;index:,type:gist"`,
+				`Near     string ` + "`" + `json:"near,omitempty" form:"near" query:"near"`,
+				"func (f *ProductFilter) GeoQuery() (*geo.Query, error)",
+				"if r.Location == nil {",
+			},
+		},
+		generatedFile{
+			path:     "internal/repositories/product_repository.go",
+			contains: []string{`"$geoNear": bson.M{`, `mongo.IndexModel{Keys: bson.M{"location": "2dsphere"}}`},
+		},
+		generatedFile{path: "internal/handlers/product_handler.go", contains: []string{"filter.GeoQuery()"}},
+		generatedFile{path: "migrations/product_migration.go", contains: []string{`db.Exec("CREATE EXTENSION IF NOT EXISTS postgis")`}},
+	)
+
+	assertGoFilesParse(t, filepath.Join(dir, "internal"))
+}
+
+func TestSchemaGenerator_GeoFieldsDataLayer(t *testing.T) {
+	schema := newTestProductSchema()
+	schema.Fields = append(schema.Fields,
+		models.SchemaField{Name: "location", Type: string(models.FieldTypeCoordinates), DisplayName: "Location"},
+	)
+
+	tests := []struct {
+		provider   string
+		layer      models.DataLayer
+		repository []string
+		migration  []string
+	}{
+		{
+			provider:   "postgres",
+			layer:      models.DataLayerPGX,
+			repository: []string{`geo.PostGISConditions("location", geoQuery)`, `geo.PostGISDistance("location", *geoQuery.Near)`, "product.SetDistance(geoQuery)"},
+			migration:  []string{"CREATE EXTENSION IF NOT EXISTS postgis;", "location geography(Point,4326)\n", "ON products USING GIST (location);"},
+		},
+		{
+			provider:   "sqlite",
+			layer:      models.DataLayerSQLX,
+			repository: []string{`geo.LatLngConditions("location_lat", "location_lng", geoQuery)`, "return r.nearest(ctx, clause, args, geoQuery, filter)", "row.LocationLat, row.LocationLng = &product.Location.Latitude, &product.Location.Longitude"},
+			migration:  []string{"location_lat REAL,", "location_lng REAL", "ON products (location_lat, location_lng);"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			gen := NewSchemaGenerator(newMemorySchemaStorage(schema)).WithDataLayer(tt.layer)
+			dir := generateTestProject(t, gen, tt.provider, schema)
+
+			assertGeneratedFiles(t, dir,
+				generatedFile{
+					path:     "internal/repositories/product_repository.go",
+					contains: tt.repository,
+					// coordinates are not sortable
+					excludes: []string{`"location": "location"`},
+				},
+				generatedFile{path: "migrations/*_create_products.sql", contains: tt.migration},
+			)
+
+			assertGoFilesParse(t, filepath.Join(dir, "internal"))
+		})
+	}
+}
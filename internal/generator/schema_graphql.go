@@ -0,0 +1,565 @@
+package generator
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/vibercode/cli/internal/models"
+	"github.com/vibercode/cli/internal/templates"
+	"github.com/vibercode/cli/pkg/ui"
+)
+
+// graphQLResourceMarker prefixes the first line of every generated resource SDL file
+const graphQLResourceMarker = "# resource: "
+
+// GraphQLField describes how a schema field is exposed through GraphQL
+type GraphQLField struct {
+	Name         string // GraphQL field name
+	GoName       string
+	Type         string // GraphQL output type
+	GoType       string // Go type returned by the field resolver
+	Output       string // Go expression returning the field value
+	InputType    string // GraphQL input type without nullability
+	InputGoType  string // Go type of a non-null input value
+	CreateAssign string // Go statements copying the create input into the request
+	UpdateAssign string // Go statements copying the update input into the request
+	Required     bool
+	Sensitive    bool // Write-only, never part of the output type
+	Sample       string
+	Variable     string // Go expression of the input variable of the i-th test item
+}
+
+// GraphQLEnum is a GraphQL enum generated from the allowed values of a field
+type GraphQLEnum struct {
+	Name      string
+	NamesVar  string
+	ValuesVar string
+	Values    []GraphQLEnumValue
+}
+
+// GraphQLEnumValue maps a GraphQL enum value to the stored value
+type GraphQLEnumValue struct {
+	Name  string
+	Value string
+}
+
+// GraphQLFilterField is a list filter exposed on the filter input
+type GraphQLFilterField struct {
+	Name      string
+	GoName    string
+	InputType string
+	GoType    string
+	Assign    string
+}
+
+// GraphQLSortField maps a sort enum value to the list sort field
+type GraphQLSortField struct {
+	Name  string
+	Field string
+}
+
+// GraphQLRelation is a related resource resolved through a request scoped dataloader
+type GraphQLRelation struct {
+	Name         string
+	GoName       string
+	Target       string // Target resource PascalCase name
+	TargetCamel  string
+	Many         bool
+	KeyExpr      string // Go expression of the lookup key on the resolved item
+	Column       string // Foreign key column on the target (has-many)
+	TargetKeyExp string // Go expression of the foreign key on a target item (has-many)
+}
+
+// GraphQLTestTarget is a relation target queried by the generated GraphQL test
+type GraphQLTestTarget struct {
+	PascalCase string
+	Queries    int // Batched queries expected while resolving a page
+}
+
+// GraphQLResource is a resource that has a generated GraphQL schema
+type GraphQLResource struct {
+	PascalCase string
+}
+
+// GraphQLTemplateData contains the template data for the GraphQL feature
+type GraphQLTemplateData struct {
+	*EnhancedSchema
+	GraphQLFields []GraphQLField
+	Enums         []GraphQLEnum
+	FilterFields  []GraphQLFilterField
+	SortFields    []GraphQLSortField
+	Relations     []GraphQLRelation
+	Resources     []GraphQLResource
+	UniqueFields  []EnhancedField
+	UsesUUID      bool
+	UsesMoney     bool
+	UpdateSample  *GraphQLField
+	TestSupported bool
+	TestTargets   []GraphQLTestTarget
+}
+
+// generateGraphQLFeature generates the GraphQL schema, resolvers and dataloaders for a schema
+func (g *SchemaGenerator) generateGraphQLFeature(data *EnhancedSchema, outputPath string) error {
+	gqlData := g.prepareGraphQLData(data, outputPath)
+	snake := data.Names.SnakeCase
+
+	// The resource SDL is written first so the root files can discover it
+	resourceFiles := []struct {
+		template string
+		path     string
+	}{
+		{templates.GraphQLResourceSchemaTemplate, filepath.Join("internal", "graphql", "schema", snake+".graphql")},
+		{templates.SchemaGraphQLResolverTemplate, filepath.Join("internal", "graphql", snake+"_resolver.go")},
+		{templates.SchemaBatchRepositoryTemplate, filepath.Join("internal", "repositories", snake+"_batch_repository.go")},
+	}
+	for _, file := range resourceFiles {
+		if err := g.generateSchemaFile(file.template, gqlData, filepath.Join(outputPath, file.path)); err != nil {
+			return err
+		}
+	}
+
+	resources, err := discoverGraphQLResources(outputPath)
+	if err != nil {
+		return err
+	}
+	gqlData.Resources = resources
+
+	files := []struct {
+		template string
+		path     string
+	}{
+		{templates.GraphQLRootSchemaTemplate, filepath.Join("internal", "graphql", "schema", "schema.graphql")},
+		{templates.GraphQLPackageTemplate, filepath.Join("internal", "graphql", "graphql.go")},
+		{templates.GraphQLDataloaderTemplate, filepath.Join("internal", "graphql", "dataloader.go")},
+		{templates.GraphQLRoutesTemplate, filepath.Join("internal", "handlers", "graphql_handler.go")},
+	}
+	if gqlData.TestSupported {
+		files = append(files, struct {
+			template string
+			path     string
+		}{templates.SchemaGraphQLTestTemplate, filepath.Join("internal", "graphql", snake+"_resolver_test.go")})
+	}
+
+	for _, file := range files {
+		if err := g.generateSchemaFile(file.template, gqlData, filepath.Join(outputPath, file.path)); err != nil {
+			return err
+		}
+	}
+
+	if gqlData.TestSupported {
+		ui.PrintInfo("GraphQL requires github.com/graph-gophers/graphql-go and its test gorm.io/driver/sqlite, run 'go mod tidy' after generation")
+	} else {
+		ui.PrintInfo("GraphQL requires github.com/graph-gophers/graphql-go, run 'go mod tidy' after generation")
+	}
+	return nil
+}
+
+// prepareGraphQLData maps schema fields and relations onto GraphQL types
+func (g *SchemaGenerator) prepareGraphQLData(data *EnhancedSchema, outputPath string) *GraphQLTemplateData {
+	gqlData := &GraphQLTemplateData{
+		EnhancedSchema: data,
+		TestSupported:  data.DBProvider != "mongodb",
+	}
+
+	for _, field := range data.Fields {
+		if field.Database != nil && field.Database.Unique {
+			gqlData.UniqueFields = append(gqlData.UniqueFields, field)
+		}
+		if field.ReadOnly {
+			continue
+		}
+
+		kind := fieldKind(field.SchemaField)
+		if kind == "" {
+			if field.Required {
+				// Resources can't be created through GraphQL without the field
+				gqlData.TestSupported = false
+			}
+			continue
+		}
+
+		gqlField := g.graphQLField(data, field, kind)
+		switch kind {
+		case "uuid":
+			gqlData.UsesUUID = true
+		case "money":
+			gqlData.UsesMoney = true
+		case "enum":
+			gqlData.Enums = append(gqlData.Enums, graphQLEnum(data, field))
+		}
+		gqlData.GraphQLFields = append(gqlData.GraphQLFields, gqlField)
+
+		if field.Filterable && field.GoFilterQuery != "" {
+			if filter, ok := graphQLFilterField(field, kind); ok {
+				gqlData.FilterFields = append(gqlData.FilterFields, filter)
+			}
+		}
+		if !gqlField.Sensitive && kind != "json" {
+			gqlData.SortFields = append(gqlData.SortFields, GraphQLSortField{
+				Name:  strings.ToUpper(field.Names.SnakeCase),
+				Field: field.Names.SnakeCase,
+			})
+		}
+	}
+	gqlData.SortFields = append(gqlData.SortFields,
+		GraphQLSortField{Name: "CREATED_AT", Field: "created_at"},
+		GraphQLSortField{Name: "UPDATED_AT", Field: "updated_at"},
+	)
+
+	for i := range gqlData.GraphQLFields {
+		if gqlData.GraphQLFields[i].InputType == "String" && !gqlData.GraphQLFields[i].Sensitive {
+			gqlData.UpdateSample = &gqlData.GraphQLFields[i]
+			break
+		}
+	}
+
+	gqlData.Relations = g.graphQLRelations(data, outputPath)
+	gqlData.TestTargets = graphQLTestTargets(gqlData.Relations)
+	return gqlData
+}
+
+// TestVariablesUseFmt reports whether building the test input variables calls fmt
+func (d *GraphQLTemplateData) TestVariablesUseFmt() bool {
+	for _, field := range d.GraphQLFields {
+		if strings.HasPrefix(field.Variable, "fmt.") {
+			return true
+		}
+	}
+	return false
+}
+
+// graphQLTestTargets lists the relation targets with the batched queries resolving a page of items issues.
+// To-one relations of a target share its ID loader, each to-many relation has a loader of its own.
+func graphQLTestTargets(relations []GraphQLRelation) []GraphQLTestTarget {
+	var targets []GraphQLTestTarget
+	index := make(map[string]int)
+	loadsByID := make(map[string]bool)
+
+	for _, relation := range relations {
+		i, ok := index[relation.Target]
+		if !ok {
+			i = len(targets)
+			index[relation.Target] = i
+			targets = append(targets, GraphQLTestTarget{PascalCase: relation.Target})
+		}
+		if relation.Many {
+			targets[i].Queries++
+		} else if !loadsByID[relation.Target] {
+			loadsByID[relation.Target] = true
+			targets[i].Queries++
+		}
+	}
+	return targets
+}
+
+// graphQLField builds the type mapping and conversion code for a field
+func (g *SchemaGenerator) graphQLField(data *EnhancedSchema, field EnhancedField, kind string) GraphQLField {
+	goName := field.Names.PascalCase
+	item := "r.item." + goName
+	gqlField := GraphQLField{
+		Name:     field.Names.CamelCase,
+		GoName:   goName,
+		Required: field.Required,
+	}
+
+	// assign holds the conversion of the input value %[1]s into the request field
+	var assign string
+	switch kind {
+	case "string", "secret":
+		gqlField.Type, gqlField.GoType, gqlField.Output = "String!", "string", item
+		gqlField.InputType, gqlField.InputGoType = "String", "string"
+		assign = "req.%[2]s = %[1]s"
+		gqlField.Sample = fmt.Sprintf("%q", "sample-"+field.Names.KebabCase)
+		gqlField.Variable = fmt.Sprintf(`fmt.Sprintf("sample-%s-%%d", i)`, field.Names.KebabCase)
+		if kind == "secret" {
+			gqlField.Sensitive = true
+			gqlField.Sample = `"s3cret-value"`
+			gqlField.Variable = gqlField.Sample
+		}
+	case "int":
+		gqlField.Type, gqlField.GoType, gqlField.Output = "Int!", "int32", "int32("+item+")"
+		gqlField.InputType, gqlField.InputGoType = "Int", "int32"
+		assign = "req.%[2]s = int64(%[1]s)"
+		gqlField.Sample = "1"
+		gqlField.Variable = "i + 1"
+	case "float":
+		gqlField.Type, gqlField.GoType, gqlField.Output = "Float!", "float64", item
+		gqlField.InputType, gqlField.InputGoType = "Float", "float64"
+		assign = "req.%[2]s = %[1]s"
+		gqlField.Sample = "1.5"
+	case "bool":
+		gqlField.Type, gqlField.GoType, gqlField.Output = "Boolean!", "bool", item
+		gqlField.InputType, gqlField.InputGoType = "Boolean", "bool"
+		assign = "req.%[2]s = %[1]s"
+		gqlField.Sample = "true"
+	case "time":
+		gqlField.Type, gqlField.GoType, gqlField.Output = "Time!", "gql.Time", "gql.Time{Time: "+item+"}"
+		gqlField.InputType, gqlField.InputGoType = "Time", "gql.Time"
+		assign = "req.%[2]s = %[1]s.Time"
+		gqlField.Sample = `"2024-01-02T15:04:05Z"`
+	case "json":
+		gqlField.Type, gqlField.GoType, gqlField.Output = "JSON", "*JSON", "jsonValue("+item+")"
+		gqlField.InputType, gqlField.InputGoType = "JSON", "JSON"
+		assign = "req.%[2]s = %[1]s.RawMessage()"
+		gqlField.Sample = `{enabled: true}`
+		gqlField.Variable = `map[string]interface{}{"enabled": true}`
+	case "uuid":
+		gqlField.Type, gqlField.GoType, gqlField.Output = "ID!", "gql.ID", "gql.ID("+item+".String())"
+		gqlField.InputType, gqlField.InputGoType = "ID", "gql.ID"
+		assign = `parsed%[2]s, err := uuid.Parse(string(%[1]s))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %[3]s: %%w", err)
+	}
+	req.%[2]s = parsed%[2]s`
+		gqlField.Sample = `"00000000-0000-0000-0000-000000000001"`
+		gqlField.Variable = `fmt.Sprintf("00000000-0000-0000-0000-%012d", i+1)`
+	case "money":
+		// Amounts are strings such as "12.50 EUR", floats would lose their precision
+		gqlField.Type, gqlField.GoType, gqlField.Output = "String!", "string", item+".String()"
+		gqlField.InputType, gqlField.InputGoType = "String", "string"
+		assign = `parsed%[2]s, err := money.Parse(%[1]s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %[3]s: %%w", err)
+	}
+	req.%[2]s = parsed%[2]s`
+		gqlField.Sample = `"` + moneySample(field.SchemaField) + `"`
+	case "enum":
+		enum := graphQLEnum(data, field)
+		gqlField.Type, gqlField.GoType = enum.Name, "*string"
+		gqlField.Output = fmt.Sprintf("enumName(%s, %s)", enum.NamesVar, item)
+		gqlField.InputType, gqlField.InputGoType = enum.Name, "string"
+		assign = "req.%[2]s = " + enum.ValuesVar + "[%[1]s]"
+		gqlField.Sample = enum.Values[0].Name
+		gqlField.Variable = fmt.Sprintf("%q", gqlField.Sample)
+	}
+
+	if gqlField.Variable == "" {
+		// The GraphQL literal is a valid Go constant of the same value
+		gqlField.Variable = gqlField.Sample
+	}
+
+	src := "args.Input." + goName
+	convert := func(value string) string {
+		return fmt.Sprintf(assign, value, goName, gqlField.Name)
+	}
+	optional := func() string {
+		value := "*" + src
+		if strings.Contains(assign, "%[1]s.") {
+			value = "(" + value + ")"
+		}
+		body := strings.ReplaceAll(convert(value), "\n", "\n\t")
+		return fmt.Sprintf("if %s != nil {\n\t\t%s\n\t}", src, body)
+	}
+
+	if field.Required {
+		gqlField.CreateAssign = convert(src)
+	} else {
+		gqlField.CreateAssign = optional()
+	}
+	gqlField.UpdateAssign = optional()
+
+	return gqlField
+}
+
+// graphQLEnum builds the enum type and lookup tables for a field with allowed values
+func graphQLEnum(data *EnhancedSchema, field EnhancedField) GraphQLEnum {
+	enum := GraphQLEnum{
+		Name:      data.Names.PascalCase + field.Names.PascalCase,
+		NamesVar:  data.Names.CamelCase + field.Names.PascalCase + "Names",
+		ValuesVar: data.Names.CamelCase + field.Names.PascalCase + "Values",
+	}
+	for _, value := range field.Validation.AllowedValues {
+		enum.Values = append(enum.Values, GraphQLEnumValue{
+			Name:  enumValueName(value),
+			Value: value,
+		})
+	}
+	return enum
+}
+
+// graphQLFilterField maps a filterable field onto the filter input
+func graphQLFilterField(field EnhancedField, kind string) (GraphQLFilterField, bool) {
+	filter := GraphQLFilterField{
+		Name:   field.Names.CamelCase,
+		GoName: field.Names.PascalCase,
+	}
+	src := "args.Filter." + filter.GoName
+	dst := "filter." + filter.GoName
+
+	switch kind {
+	case "string":
+		filter.InputType, filter.GoType = "String", "*string"
+		filter.Assign = fmt.Sprintf("%s = %s", dst, src)
+	case "float":
+		filter.InputType, filter.GoType = "Float", "*float64"
+		filter.Assign = fmt.Sprintf("%s = %s", dst, src)
+	case "bool":
+		filter.InputType, filter.GoType = "Boolean", "*bool"
+		filter.Assign = fmt.Sprintf("%s = %s", dst, src)
+	case "int":
+		filter.InputType, filter.GoType = "Int", "*int32"
+		filter.Assign = fmt.Sprintf("if %[1]s != nil {\n\t\t\tvalue := int64(*%[1]s)\n\t\t\t%[2]s = &value\n\t\t}", src, dst)
+	case "time":
+		filter.InputType, filter.GoType = "Time", "*gql.Time"
+		filter.Assign = fmt.Sprintf("if %[1]s != nil {\n\t\t\tvalue := %[1]s.Time\n\t\t\t%[2]s = &value\n\t\t}", src, dst)
+	default:
+		return filter, false
+	}
+	return filter, true
+}
+
+// graphQLRelations resolves the relations that can be loaded through dataloaders.
+// Both sides need a stored schema, a string or UUID foreign key field and a generated GraphQL schema.
+func (g *SchemaGenerator) graphQLRelations(data *EnhancedSchema, outputPath string) []GraphQLRelation {
+	var relations []GraphQLRelation
+
+	for _, field := range data.Fields {
+		relation := field.Relation
+		if relation == nil || !isRelationField(field.SchemaField) {
+			continue
+		}
+
+		target, err := g.relationTarget(data, relation.Target)
+		if err != nil || !hasGraphQLSchema(outputPath, data, target) {
+			ui.PrintInfo(fmt.Sprintf("Skipping GraphQL relation %s.%s, generate %s with GraphQL first", data.Name, field.Name, relation.Target))
+			continue
+		}
+
+		gqlRelation := GraphQLRelation{
+			Name:        field.Names.CamelCase,
+			GoName:      field.Names.PascalCase,
+			Target:      target.Names.PascalCase,
+			TargetCamel: target.Names.CamelCase,
+		}
+
+		switch {
+		case field.Type == "relation":
+			key, ok := foreignKeyExpr(data.ResourceSchema, relation.ForeignKey, "r.item.")
+			if !ok {
+				ui.PrintInfo(fmt.Sprintf("Skipping GraphQL relation %s.%s, no %s foreign key field", data.Name, field.Name, relation.ForeignKey))
+				continue
+			}
+			gqlRelation.KeyExpr = key
+		case relation.Type == "one_to_many":
+			key, ok := foreignKeyExpr(target, relation.ForeignKey, "item.")
+			if !ok {
+				ui.PrintInfo(fmt.Sprintf("Skipping GraphQL relation %s.%s, %s has no %s field", data.Name, field.Name, target.Name, relation.ForeignKey))
+				continue
+			}
+			gqlRelation.Many = true
+			gqlRelation.KeyExpr = "r.item.ID.Hex()"
+			gqlRelation.TargetKeyExp = key
+			gqlRelation.Column = foreignKeyColumn(target, relation.ForeignKey, data.DBProvider)
+		default:
+			ui.PrintInfo(fmt.Sprintf("Skipping GraphQL relation %s.%s, %s relations are not supported", data.Name, field.Name, relation.Type))
+			continue
+		}
+
+		relations = append(relations, gqlRelation)
+	}
+
+	return relations
+}
+
+// relationTarget loads the schema of a relation target
+func (g *SchemaGenerator) relationTarget(data *EnhancedSchema, name string) (*models.ResourceSchema, error) {
+	if name == data.Name {
+		return data.ResourceSchema, nil
+	}
+	return g.storage.LoadByName(name)
+}
+
+// findForeignKeyField returns the field of schema stored under the foreign key name
+func findForeignKeyField(schema *models.ResourceSchema, foreignKey string) (*models.SchemaField, bool) {
+	for i := range schema.Fields {
+		field := &schema.Fields[i]
+		if toSnakeCase(field.Name) != toSnakeCase(foreignKey) {
+			continue
+		}
+		switch fieldKind(field) {
+		case "string", "uuid":
+			return field, true
+		}
+	}
+	return nil, false
+}
+
+// foreignKeyExpr returns the Go expression reading the foreign key of schema as a string
+func foreignKeyExpr(schema *models.ResourceSchema, foreignKey, receiver string) (string, bool) {
+	field, ok := findForeignKeyField(schema, foreignKey)
+	if !ok {
+		return "", false
+	}
+	expr := receiver + toPascalCase(field.Name)
+	if field.Type == "uuid" {
+		expr += ".String()"
+	}
+	return expr, true
+}
+
+// foreignKeyColumn returns the column or document key holding the foreign key
+func foreignKeyColumn(schema *models.ResourceSchema, foreignKey, dbProvider string) string {
+	field, _ := findForeignKeyField(schema, foreignKey)
+	if dbProvider == "mongodb" {
+		// The Mongo driver lowercases untagged struct field names
+		return strings.ToLower(toPascalCase(field.Name))
+	}
+	return columnName(field)
+}
+
+// hasGraphQLSchema reports whether the target resource already has a generated GraphQL schema
+func hasGraphQLSchema(outputPath string, data *EnhancedSchema, target *models.ResourceSchema) bool {
+	if target.Name == data.Name {
+		return true
+	}
+	path := layoutPath(outputPath, "internal", "graphql", "schema", target.Names.SnakeCase+".graphql")
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// discoverGraphQLResources lists the resources with a generated GraphQL schema in the output directory
+func discoverGraphQLResources(outputPath string) ([]GraphQLResource, error) {
+	paths, err := filepath.Glob(filepath.Join(layoutPath(outputPath, "internal", "graphql", "schema"), "*.graphql"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list GraphQL schemas: %w", err)
+	}
+
+	var resources []GraphQLResource
+	for _, path := range paths {
+		name, err := readGraphQLResourceName(path)
+		if err != nil {
+			return nil, err
+		}
+		if name != "" {
+			resources = append(resources, GraphQLResource{PascalCase: name})
+		}
+	}
+
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].PascalCase < resources[j].PascalCase
+	})
+	return resources, nil
+}
+
+// readGraphQLResourceName reads the resource marker from the first line of a schema file
+func readGraphQLResourceName(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read GraphQL schema %s: %w", path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	if !scanner.Scan() {
+		return "", scanner.Err()
+	}
+	line := scanner.Text()
+	if !strings.HasPrefix(line, graphQLResourceMarker) {
+		return "", nil
+	}
+	return strings.TrimSpace(strings.TrimPrefix(line, graphQLResourceMarker)), nil
+}
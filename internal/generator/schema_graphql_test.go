@@ -0,0 +1,97 @@
+package generator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vibercode/cli/internal/models"
+)
+
+func TestSchemaGenerator_GraphQLFeature(t *testing.T) {
+	schema := newTestProductSchema()
+	schema.Fields = append(schema.Fields,
+		models.SchemaField{Name: "status", Type: "enum", DisplayName: "Status", Validation: &models.FieldValidation{AllowedValues: []string{"draft", "in-stock"}}},
+		models.SchemaField{Name: "secret", Type: "password", DisplayName: "Secret"},
+	)
+
+	gen := NewSchemaGenerator(newMemorySchemaStorage(schema)).WithFeatures(models.FeatureGraphQL)
+	dir := generateTestProject(t, gen, "sqlite", schema)
+
+	assertGeneratedFiles(t, dir,
+		generatedFile{path: "internal/graphql/schema/schema.graphql"},
+		generatedFile{
+			path: "internal/graphql/schema/product.graphql",
+			contains: []string{
+				"enum ProductStatus {\n  DRAFT\n  IN_STOCK\n}",
+				"type ProductConnection {",
+				"  sku: String!\n  name: String!\n  description: String",
+				"input ProductCreateInput {\n  sku: String!",
+				// password fields are write-only inputs
+				"  secret: String\n}",
+				"products(first: Int, after: String, filter: ProductFilter, sort: ProductSortField, order: SortOrder): ProductConnection!",
+			},
+		},
+		generatedFile{path: "internal/graphql/graphql.go", contains: []string{"Product ProductBackend"}},
+		generatedFile{path: "internal/graphql/dataloader.go"},
+		generatedFile{path: "internal/graphql/product_resolver.go"},
+		generatedFile{path: "internal/graphql/product_resolver_test.go", excludes: []string{"BatchesRelations"}},
+		generatedFile{path: "internal/repositories/product_batch_repository.go"},
+		generatedFile{path: "internal/handlers/graphql_handler.go"},
+	)
+
+	sdl := readGeneratedFile(t, dir, "internal/graphql/schema/product.graphql")
+	assert.True(t, strings.HasPrefix(sdl, "# resource: Product\n"))
+	assert.NotContains(t, sdl[:strings.Index(sdl, "type ProductEdge")], "secret", "password fields must not be readable")
+
+	assertGoFilesParse(t, filepath.Join(dir, "internal"))
+}
+
+func TestSchemaGenerator_GraphQLRelations(t *testing.T) {
+	category := &models.ResourceSchema{
+		ID:          "category-1",
+		Name:        "Category",
+		DisplayName: "Category",
+		Names:       models.CreateResourceNames("Category"),
+		Fields: []models.SchemaField{
+			{Name: "name", Type: "string", DisplayName: "Name", Required: true},
+			{Name: "products", Type: "relation_array", DisplayName: "Products", Relation: &models.RelationConfig{Type: "one_to_many", Target: "Product", ForeignKey: "category_id"}},
+		},
+		Database: &models.DatabaseConfig{Provider: "postgres", TableName: "categories"},
+	}
+	product := newTestProductSchema()
+	product.Fields = append(product.Fields,
+		models.SchemaField{Name: "category_id", Type: "string", DisplayName: "Category ID"},
+		models.SchemaField{Name: "category", Type: "relation", DisplayName: "Category", Relation: &models.RelationConfig{Type: "many_to_one", Target: "Category", ForeignKey: "category_id"}},
+	)
+
+	gen := NewSchemaGenerator(newMemorySchemaStorage(category, product)).WithFeatures(models.FeatureGraphQL)
+	dir := generateTestProject(t, gen, "postgres", product, category)
+
+	assertGeneratedFiles(t, dir,
+		generatedFile{
+			path: "internal/graphql/schema/product.graphql",
+			// Category had no GraphQL schema yet
+			excludes: []string{"category: Category"},
+		},
+		generatedFile{path: "internal/graphql/schema/category.graphql", contains: []string{"products: [Product!]!"}},
+		generatedFile{
+			path:     "internal/graphql/category_resolver.go",
+			contains: []string{`ListByColumn(ctx, "category_id", keys)`, "grouped[item.CategoryId]"},
+		},
+		generatedFile{path: "internal/graphql/graphql.go", contains: []string{"Category CategoryBackend\n\tProduct ProductBackend"}},
+		generatedFile{
+			path: "internal/graphql/category_resolver_test.go",
+			contains: []string{
+				"db.AutoMigrate(&models.Category{}, &models.Product{})",
+				"Product: ProductBackend{Batch: repositories.NewProductBatchRepository(db)}",
+				`"name": fmt.Sprintf("sample-name-%d", i),`,
+				"nodes { id products { id } }",
+				"if got := queries[models.Product{}.TableName()]; got != 1 {",
+			},
+		},
+	)
+
+	assertGoFilesParse(t, filepath.Join(dir, "internal"))
+}
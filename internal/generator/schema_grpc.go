@@ -0,0 +1,626 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/vibercode/cli/internal/templates"
+	"github.com/vibercode/cli/pkg/ui"
+	"gopkg.in/yaml.v3"
+)
+
+// ProtoField is a numbered field of a generated protobuf message
+type ProtoField struct {
+	Name     string
+	Type     string
+	Number   int
+	Optional bool
+}
+
+// ProtoMessage is a generated protobuf message with its reserved numbers
+type ProtoMessage struct {
+	Name          string
+	Fields        []ProtoField
+	Reserved      []int
+	ReservedNames []string
+}
+
+// ReservedNumbers lists the reserved field numbers for a reserved statement
+func (m ProtoMessage) ReservedNumbers() string {
+	return joinReservedNumbers(m.Reserved)
+}
+
+// ReservedNamesList lists the reserved field names for a reserved statement
+func (m ProtoMessage) ReservedNamesList() string {
+	return joinReservedNames(m.ReservedNames)
+}
+
+// ProtoEnum is a protobuf enum generated from the allowed values of a field
+type ProtoEnum struct {
+	Name          string
+	Unspecified   string
+	ToProtoVar    string
+	FromProtoVar  string
+	Values        []ProtoEnumValue
+	Reserved      []int
+	ReservedNames []string
+}
+
+// ReservedNumbers lists the reserved value numbers for a reserved statement
+func (e ProtoEnum) ReservedNumbers() string {
+	return joinReservedNumbers(e.Reserved)
+}
+
+// ReservedNamesList lists the reserved value names for a reserved statement
+func (e ProtoEnum) ReservedNamesList() string {
+	return joinReservedNames(e.ReservedNames)
+}
+
+// ProtoEnumValue maps a protobuf enum value to the stored value
+type ProtoEnumValue struct {
+	Name   string
+	Number int
+	Value  string
+}
+
+// GRPCField describes how a schema field is converted between protobuf and the service layer
+type GRPCField struct {
+	Name         string // Protobuf field name
+	GoName       string // Field name in the generated protobuf Go code
+	Output       string // Go expression reading the model value, empty for write-only fields
+	CreateAssign string // Go statements copying the create request into the service request
+	UpdateAssign string // Go statements copying the update request into the service request
+	Validate     []string
+}
+
+// GRPCFilterField is a list filter of the list request
+type GRPCFilterField struct {
+	Assign string
+}
+
+// GRPCPattern is a compiled validation pattern
+type GRPCPattern struct {
+	Var     string
+	Pattern string
+}
+
+// GRPCTemplateData contains the template data for the gRPC feature
+type GRPCTemplateData struct {
+	*EnhancedSchema
+	Package       string // Protobuf package, e.g. shop.v1
+	GoPackage     string // Import path of the generated protobuf Go code
+	GoPackageName string
+	ItemGoName    string // Go name of the resource field in responses
+	ItemsGoName   string // Go name of the repeated resource field in list responses
+	Resource      ProtoMessage
+	CreateRequest ProtoMessage
+	UpdateRequest ProtoMessage
+	ListRequest   ProtoMessage
+	Enums         []ProtoEnum
+	GRPCFields    []GRPCField
+	FilterFields  []GRPCFilterField
+	SortFields    []string
+	Patterns      []GRPCPattern
+	UsesTimestamp bool
+	UsesStruct    bool
+	UsesUUID      bool
+	UsesMoney     bool
+	Gateway       bool
+}
+
+// grpcGatewayConfig is the grpc-gateway HTTP rule configuration
+type grpcGatewayConfig struct {
+	Type          string `yaml:"type"`
+	ConfigVersion int    `yaml:"config_version"`
+	HTTP          struct {
+		Rules []grpcGatewayRule `yaml:"rules"`
+	} `yaml:"http"`
+}
+
+// grpcGatewayRule maps a gRPC method onto an HTTP route
+type grpcGatewayRule struct {
+	Selector string `yaml:"selector"`
+	Get      string `yaml:"get,omitempty"`
+	Post     string `yaml:"post,omitempty"`
+	Patch    string `yaml:"patch,omitempty"`
+	Delete   string `yaml:"delete,omitempty"`
+	Body     string `yaml:"body,omitempty"`
+}
+
+// WithGRPCGateway enables the grpc-gateway REST mapping for every generated gRPC service
+func (g *SchemaGenerator) WithGRPCGateway() *SchemaGenerator {
+	g.grpcGateway = true
+	return g
+}
+
+// generateGRPCFeature generates the protobuf definition, gRPC server and optional gateway for a schema
+func (g *SchemaGenerator) generateGRPCFeature(data *EnhancedSchema, outputPath string) error {
+	config := data.GetGRPCConfig()
+	pkg := config.Package
+	if pkg == "" {
+		pkg = protoPackageName(data.Module)
+	}
+	protoDir := filepath.Join(outputPath, "proto", filepath.FromSlash(strings.ReplaceAll(pkg, ".", "/")), "v1")
+	lockPath := filepath.Join(protoDir, data.Names.SnakeCase+".lock.json")
+
+	lock, err := loadProtoLock(lockPath)
+	if err != nil {
+		return err
+	}
+
+	grpcData := g.prepareGRPCData(data, pkg, lock)
+	grpcData.Gateway = config.Gateway || g.grpcGateway
+
+	if err := os.MkdirAll(protoDir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", protoDir, err)
+	}
+	if err := lock.save(lockPath); err != nil {
+		return err
+	}
+
+	gatewayPath := filepath.Join(protoDir, "gateway.yaml")
+	hasGateway, err := updateGatewayConfig(gatewayPath, grpcData)
+	if err != nil {
+		return err
+	}
+
+	snake := data.Names.SnakeCase
+	files := []struct {
+		template string
+		path     string
+	}{
+		{templates.SchemaProtoTemplate, filepath.Join(protoDir, snake+".proto")},
+		{templates.BufTemplate, filepath.Join(outputPath, "proto", "buf.yaml")},
+		{templates.GRPCPackageTemplate, filepath.Join(outputPath, "internal", "rpc", "rpc.go")},
+		{templates.SchemaGRPCServerTemplate, filepath.Join(outputPath, "internal", "rpc", snake+"_server.go")},
+	}
+	if hasGateway {
+		files = append(files, struct {
+			template string
+			path     string
+		}{templates.GRPCGatewayTemplate, filepath.Join(outputPath, "internal", "rpc", "gateway.go")})
+	}
+
+	for _, file := range files {
+		if err := g.generateFile(file.template, grpcData, file.path); err != nil {
+			return err
+		}
+	}
+
+	bufGen := struct {
+		Gateway     bool
+		GatewayPath string
+	}{hasGateway, filepath.ToSlash(filepath.Join("proto", strings.ReplaceAll(pkg, ".", "/"), "v1", "gateway.yaml"))}
+	if err := g.generateFile(templates.BufGenTemplate, bufGen, filepath.Join(outputPath, "buf.gen.yaml")); err != nil {
+		return err
+	}
+
+	ui.PrintInfo("Run 'buf generate proto' to compile the protobuf definitions, then 'go mod tidy'")
+	return nil
+}
+
+// prepareGRPCData numbers the protobuf messages through the lock and builds the conversion code
+func (g *SchemaGenerator) prepareGRPCData(data *EnhancedSchema, pkg string, lock *protoLock) *GRPCTemplateData {
+	goPackageName := strings.ReplaceAll(pkg, ".", "") + "v1"
+	grpcData := &GRPCTemplateData{
+		EnhancedSchema: data,
+		Package:        pkg + ".v1",
+		GoPackage:      data.Module + "/gen/proto/" + strings.ReplaceAll(pkg, ".", "/") + "/v1",
+		GoPackageName:  goPackageName,
+		ItemGoName:     protoGoName(data.Names.SnakeCase),
+		ItemsGoName:    protoGoName(data.Names.SnakePlural),
+	}
+
+	resource := []ProtoField{{Name: "id", Type: "string"}}
+	var create []ProtoField
+	update := []ProtoField{{Name: "id", Type: "string"}}
+	list := []ProtoField{
+		{Name: "page", Type: "int32"},
+		{Name: "page_size", Type: "int32"},
+		{Name: "sort", Type: "string"},
+		{Name: "order", Type: "string"},
+		{Name: "search", Type: "string"},
+	}
+
+	for _, field := range data.Fields {
+		if field.ReadOnly || isRelationField(field.SchemaField) {
+			continue
+		}
+		kind := fieldKind(field.SchemaField)
+		if kind == "" {
+			continue
+		}
+
+		protoType := protoFieldType(kind)
+		if kind == "enum" {
+			enum := g.protoEnum(data, field, lock)
+			grpcData.Enums = append(grpcData.Enums, enum)
+			protoType = enum.Name
+		}
+		switch kind {
+		case "time":
+			grpcData.UsesTimestamp = true
+		case "json":
+			grpcData.UsesStruct = true
+		case "uuid":
+			grpcData.UsesUUID = true
+		case "money":
+			grpcData.UsesMoney = true
+		}
+
+		name := field.Names.SnakeCase
+		if kind != "secret" {
+			resource = append(resource, ProtoField{Name: name, Type: protoType})
+		}
+		create = append(create, ProtoField{Name: name, Type: protoType})
+		update = append(update, ProtoField{Name: name, Type: protoType, Optional: !isProtoMessageType(protoType)})
+
+		grpcField := grpcFieldConversion(field, kind, grpcData.enumFor(field))
+		grpcField.Validate, grpcData.Patterns = grpcValidation(data, field, kind, grpcData.Patterns)
+		grpcData.GRPCFields = append(grpcData.GRPCFields, grpcField)
+
+		if field.Filterable && field.GoFilterQuery != "" {
+			if filter, ok := grpcFilterField(field, kind); ok {
+				list = append(list, ProtoField{Name: name, Type: protoType, Optional: !isProtoMessageType(protoType)})
+				grpcData.FilterFields = append(grpcData.FilterFields, filter)
+			}
+		}
+		if kind != "secret" && kind != "json" {
+			grpcData.SortFields = append(grpcData.SortFields, columnName(field.SchemaField))
+		}
+	}
+	resource = append(resource,
+		ProtoField{Name: "created_at", Type: "google.protobuf.Timestamp"},
+		ProtoField{Name: "updated_at", Type: "google.protobuf.Timestamp"},
+	)
+	grpcData.UsesTimestamp = true
+	grpcData.SortFields = append(grpcData.SortFields, "created_at", "updated_at")
+
+	grpcData.Resource = numberProtoMessage(lock, data.Names.PascalCase, resource)
+	grpcData.CreateRequest = numberProtoMessage(lock, "Create"+data.Names.PascalCase+"Request", create)
+	grpcData.UpdateRequest = numberProtoMessage(lock, "Update"+data.Names.PascalCase+"Request", update)
+	grpcData.ListRequest = numberProtoMessage(lock, "List"+data.Names.PascalPlural+"Request", list)
+
+	return grpcData
+}
+
+// enumFor returns the enum generated for a field, nil when the field is not an enum
+func (d *GRPCTemplateData) enumFor(field EnhancedField) *ProtoEnum {
+	name := d.Names.PascalCase + field.Names.PascalCase
+	for i := range d.Enums {
+		if d.Enums[i].Name == name {
+			return &d.Enums[i]
+		}
+	}
+	return nil
+}
+
+// numberProtoMessage assigns locked numbers to the fields of a message
+func numberProtoMessage(lock *protoLock, name string, fields []ProtoField) ProtoMessage {
+	slots := make([]protoSlot, len(fields))
+	for i, field := range fields {
+		slots[i] = protoSlot{Name: field.Name, Type: field.Type}
+	}
+	numbers, entry := lock.message(name, slots)
+
+	message := ProtoMessage{Name: name, Reserved: entry.Reserved, ReservedNames: entry.ReservedNames}
+	for _, field := range fields {
+		field.Number = numbers[field.Name]
+		message.Fields = append(message.Fields, field)
+	}
+	return message
+}
+
+// protoEnum builds a protobuf enum with locked value numbers from the allowed values of a field
+func (g *SchemaGenerator) protoEnum(data *EnhancedSchema, field EnhancedField, lock *protoLock) ProtoEnum {
+	name := data.Names.PascalCase + field.Names.PascalCase
+	prefix := strings.ToUpper(toSnakeCase(data.Names.PascalCase) + "_" + field.Names.SnakeCase)
+	enum := ProtoEnum{
+		Name:         name,
+		Unspecified:  prefix + "_UNSPECIFIED",
+		ToProtoVar:   data.Names.CamelCase + field.Names.PascalCase + "ToProto",
+		FromProtoVar: data.Names.CamelCase + field.Names.PascalCase + "FromProto",
+	}
+
+	var slots []protoSlot
+	for _, value := range field.Validation.AllowedValues {
+		slots = append(slots, protoSlot{Name: prefix + "_" + enumValueName(value)})
+	}
+	numbers, entry := lock.enum(name, slots)
+	enum.Reserved, enum.ReservedNames = entry.Reserved, entry.ReservedNames
+
+	for i, value := range field.Validation.AllowedValues {
+		enum.Values = append(enum.Values, ProtoEnumValue{
+			Name:   slots[i].Name,
+			Number: numbers[slots[i].Name],
+			Value:  value,
+		})
+	}
+	return enum
+}
+
+// protoFieldType returns the protobuf type of a field kind
+func protoFieldType(kind string) string {
+	switch kind {
+	case "int":
+		return "int64"
+	case "float":
+		return "double"
+	case "bool":
+		return "bool"
+	case "time":
+		return "google.protobuf.Timestamp"
+	case "json":
+		return "google.protobuf.Value"
+	default:
+		return "string"
+	}
+}
+
+// isProtoMessageType reports whether a protobuf type is a message, which already tracks presence
+func isProtoMessageType(protoType string) bool {
+	return strings.HasPrefix(protoType, "google.protobuf.")
+}
+
+// grpcFieldConversion builds the code converting a field between protobuf and the service layer
+func grpcFieldConversion(field EnhancedField, kind string, enum *ProtoEnum) GRPCField {
+	grpcField := GRPCField{
+		Name:   field.Names.SnakeCase,
+		GoName: protoGoName(field.Names.SnakeCase),
+	}
+	model := "m." + field.Names.PascalCase
+
+	// assign converts the value %[1]s into the request field %[2]s, reporting problems on %[3]s
+	assign := "req.%[2]s = %[1]s"
+	guard := ""
+	switch kind {
+	case "string", "int", "float", "bool":
+		grpcField.Output = model
+	case "secret":
+	case "time":
+		grpcField.Output = "timestamppb.New(" + model + ")"
+		assign = "req.%[2]s = %[1]s.AsTime()"
+		guard = "nil"
+	case "json":
+		grpcField.Output = "jsonValue(" + model + ")"
+		assign = `raw, err := valueJSON(%[1]s)
+	if err != nil {
+		v.add("%[3]s", "must be a JSON value")
+	}
+	req.%[2]s = raw`
+		guard = "nil"
+	case "uuid":
+		grpcField.Output = model + ".String()"
+		assign = `parsed, err := uuid.Parse(%[1]s)
+	if err != nil {
+		v.add("%[3]s", "must be a UUID")
+	}
+	req.%[2]s = parsed`
+		guard = `""`
+	case "money":
+		grpcField.Output = model + ".String()"
+		assign = `parsed, err := money.Parse(%[1]s)
+	if err != nil {
+		v.add("%[3]s", "must be an amount and a currency code such as \"12.50 EUR\"")
+	}
+	req.%[2]s = parsed`
+		guard = `""`
+	case "enum":
+		grpcField.Output = enum.ToProtoVar + "[" + model + "]"
+		assign = "req.%[2]s = " + enum.FromProtoVar + "[%[1]s]"
+	}
+
+	src := "in." + grpcField.GoName
+	convert := func(value string) string {
+		return fmt.Sprintf(assign, value, field.Names.PascalCase, grpcField.Name)
+	}
+	wrap := func(condition, value string) string {
+		body := strings.ReplaceAll(convert(value), "\n", "\n\t")
+		return fmt.Sprintf("if %s {\n\t\t%s\n\t}", condition, body)
+	}
+
+	switch guard {
+	case "":
+		grpcField.CreateAssign = convert(src)
+	default:
+		grpcField.CreateAssign = wrap(src+" != "+guard, src)
+	}
+	if guard == "nil" {
+		grpcField.UpdateAssign = wrap(src+" != nil", src)
+	} else {
+		grpcField.UpdateAssign = wrap(src+" != nil", "*"+src)
+	}
+
+	return grpcField
+}
+
+// grpcValidation builds the field violation checks of a field from its schema validation rules
+func grpcValidation(data *EnhancedSchema, field EnhancedField, kind string, patterns []GRPCPattern) ([]string, []GRPCPattern) {
+	value := "r." + field.Names.PascalCase
+	name := field.Names.SnakeCase
+	check := func(condition, description string) string {
+		return fmt.Sprintf("if %s {\n\t\tv.add(%q, %q)\n\t}", condition, name, description)
+	}
+
+	var checks []string
+	if field.Required {
+		switch {
+		case kind == "secret" && field.GetGoType() != "string":
+			checks = append(checks, check(value+` == nil || `+value+` == ""`, "is required"))
+		case kind == "string" || kind == "secret" || kind == "enum":
+			checks = append(checks, check(value+` == ""`, "is required"))
+		case kind == "int":
+			checks = append(checks, check(value+" <= 0", "must be greater than 0"))
+		case kind == "time":
+			checks = append(checks, check(value+".IsZero()", "is required"))
+		case kind == "uuid":
+			checks = append(checks, check(value+" == uuid.Nil", "is required"))
+		case kind == "money":
+			checks = append(checks, check(value+`.Currency == ""`, "is required"))
+		case kind == "json":
+			checks = append(checks, check("len("+value+") == 0", "is required"))
+		}
+	}
+
+	rules := field.Validation
+	if rules == nil {
+		return checks, patterns
+	}
+
+	if field.GetGoType() == "string" {
+		if rules.MinLength != nil {
+			checks = append(checks, check(fmt.Sprintf(`%[1]s != "" && len([]rune(%[1]s)) < %[2]d`, value, *rules.MinLength),
+				fmt.Sprintf("must be at least %d characters", *rules.MinLength)))
+		}
+		if rules.MaxLength != nil {
+			checks = append(checks, check(fmt.Sprintf(`len([]rune(%s)) > %d`, value, *rules.MaxLength),
+				fmt.Sprintf("must be at most %d characters", *rules.MaxLength)))
+		}
+		if rules.Pattern != "" {
+			pattern := GRPCPattern{
+				Var:     data.Names.CamelCase + field.Names.PascalCase + "Pattern",
+				Pattern: fmt.Sprintf("%q", rules.Pattern),
+			}
+			patterns = append(patterns, pattern)
+			checks = append(checks, check(fmt.Sprintf(`%[1]s != "" && !%[2]s.MatchString(%[1]s)`, value, pattern.Var),
+				"must match "+rules.Pattern))
+		}
+	}
+
+	if kind == "int" || kind == "float" {
+		if rules.Min != nil {
+			checks = append(checks, check(fmt.Sprintf("float64(%s) < %v", value, *rules.Min), fmt.Sprintf("must be at least %v", *rules.Min)))
+		}
+		if rules.Max != nil {
+			checks = append(checks, check(fmt.Sprintf("float64(%s) > %v", value, *rules.Max), fmt.Sprintf("must be at most %v", *rules.Max)))
+		}
+	}
+
+	return checks, patterns
+}
+
+// grpcFilterField maps a filterable field onto the list request
+func grpcFilterField(field EnhancedField, kind string) (GRPCFilterField, bool) {
+	src := "in." + protoGoName(field.Names.SnakeCase)
+	dst := "filter." + field.Names.PascalCase
+
+	switch kind {
+	case "string", "int", "float", "bool":
+		return GRPCFilterField{Assign: fmt.Sprintf("%s = %s", dst, src)}, true
+	case "time":
+		return GRPCFilterField{Assign: fmt.Sprintf("if %[1]s != nil {\n\t\tvalue := %[1]s.AsTime()\n\t\t%[2]s = &value\n\t}", src, dst)}, true
+	}
+	return GRPCFilterField{}, false
+}
+
+// updateGatewayConfig replaces the HTTP rules of the resource in the shared gateway configuration.
+// It reports whether the configuration has any rules left.
+func updateGatewayConfig(path string, data *GRPCTemplateData) (bool, error) {
+	config := grpcGatewayConfig{Type: "google.api.Service", ConfigVersion: 3}
+	if content, err := os.ReadFile(path); err == nil {
+		if err := yaml.Unmarshal(content, &config); err != nil {
+			return false, fmt.Errorf("invalid gateway configuration %s: %w", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return false, fmt.Errorf("failed to read gateway configuration %s: %w", path, err)
+	}
+
+	service := data.Package + "." + data.Names.PascalCase + "Service."
+	var rules []grpcGatewayRule
+	for _, rule := range config.HTTP.Rules {
+		if !strings.HasPrefix(rule.Selector, service) {
+			rules = append(rules, rule)
+		}
+	}
+
+	if data.Gateway {
+		collection := "/v1/" + data.Names.KebabPlural
+		item := collection + "/{id}"
+		rules = append(rules,
+			grpcGatewayRule{Selector: service + "Create" + data.Names.PascalCase, Post: collection, Body: "*"},
+			grpcGatewayRule{Selector: service + "Get" + data.Names.PascalCase, Get: item},
+			grpcGatewayRule{Selector: service + "List" + data.Names.PascalPlural, Get: collection},
+			grpcGatewayRule{Selector: service + "Update" + data.Names.PascalCase, Patch: item, Body: "*"},
+			grpcGatewayRule{Selector: service + "Delete" + data.Names.PascalCase, Delete: item},
+		)
+	}
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].Selector < rules[j].Selector
+	})
+
+	if len(rules) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return false, fmt.Errorf("failed to remove gateway configuration %s: %w", path, err)
+		}
+		return false, nil
+	}
+
+	config.HTTP.Rules = rules
+	content, err := yaml.Marshal(&config)
+	if err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return false, fmt.Errorf("failed to write gateway configuration %s: %w", path, err)
+	}
+	return true, nil
+}
+
+func joinReservedNumbers(numbers []int) string {
+	parts := make([]string, len(numbers))
+	for i, number := range numbers {
+		parts[i] = fmt.Sprint(number)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func joinReservedNames(names []string) string {
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%q", name)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// protoPackageName derives a protobuf package name from the last element of a Go module path
+func protoPackageName(module string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(module[strings.LastIndex(module, "/")+1:]) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	name := b.String()
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "api" + name
+	}
+	return name
+}
+
+// protoGoName returns the Go field name protoc-gen-go generates for a snake case field name
+func protoGoName(name string) string {
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c == '_' && i == 0:
+			b.WriteByte('X')
+		case c == '_' && i+1 < len(name) && name[i+1] >= 'a' && name[i+1] <= 'z':
+			// Dropped, the next letter is capitalized
+		case c >= '0' && c <= '9':
+			b.WriteByte(c)
+		default:
+			if c >= 'a' && c <= 'z' {
+				c -= 'a' - 'A'
+			}
+			b.WriteByte(c)
+			for ; i+1 < len(name) && name[i+1] >= 'a' && name[i+1] <= 'z'; i++ {
+				b.WriteByte(name[i+1])
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,164 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// protoLock records the protobuf numbers handed out for a resource so that
+// regenerating keeps the wire format compatible with existing clients
+type protoLock struct {
+	Messages map[string]*protoLockEntry `json:"messages"`
+	Enums    map[string]*protoLockEntry `json:"enums,omitempty"`
+}
+
+// protoLockEntry holds the numbers of one message or enum
+type protoLockEntry struct {
+	Fields        map[string]protoLockField `json:"fields"`
+	Reserved      []int                     `json:"reserved,omitempty"`
+	ReservedNames []string                  `json:"reserved_names,omitempty"`
+}
+
+// protoLockField is a numbered field or enum value and the type it was numbered for
+type protoLockField struct {
+	Number int    `json:"number"`
+	Type   string `json:"type,omitempty"`
+}
+
+// protoSlot is a field or enum value that needs a number
+type protoSlot struct {
+	Name string
+	Type string
+}
+
+// loadProtoLock reads a lock file, returning an empty lock when it does not exist yet
+func loadProtoLock(path string) (*protoLock, error) {
+	lock := &protoLock{
+		Messages: make(map[string]*protoLockEntry),
+		Enums:    make(map[string]*protoLockEntry),
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return lock, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read proto lock %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("invalid proto lock %s: %w", path, err)
+	}
+	if lock.Messages == nil {
+		lock.Messages = make(map[string]*protoLockEntry)
+	}
+	if lock.Enums == nil {
+		lock.Enums = make(map[string]*protoLockEntry)
+	}
+	return lock, nil
+}
+
+// save writes the lock file
+func (l *protoLock) save(path string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write proto lock %s: %w", path, err)
+	}
+	return nil
+}
+
+// message numbers the fields of a message
+func (l *protoLock) message(name string, slots []protoSlot) (map[string]int, *protoLockEntry) {
+	return assignProtoNumbers(l.Messages, name, slots)
+}
+
+// enum numbers the values of an enum, 0 stays reserved for the unspecified value
+func (l *protoLock) enum(name string, slots []protoSlot) (map[string]int, *protoLockEntry) {
+	return assignProtoNumbers(l.Enums, name, slots)
+}
+
+// assignProtoNumbers keeps the numbers of known slots and hands out fresh numbers to new ones.
+// Numbers of removed slots, or of slots whose type changed, are reserved and never reused.
+func assignProtoNumbers(entries map[string]*protoLockEntry, name string, slots []protoSlot) (map[string]int, *protoLockEntry) {
+	entry, ok := entries[name]
+	if !ok {
+		entry = &protoLockEntry{}
+		entries[name] = entry
+	}
+	if entry.Fields == nil {
+		entry.Fields = make(map[string]protoLockField)
+	}
+
+	wanted := make(map[string]string, len(slots))
+	for _, slot := range slots {
+		wanted[slot.Name] = slot.Type
+	}
+
+	highest := 0
+	for _, number := range entry.Reserved {
+		if number > highest {
+			highest = number
+		}
+	}
+	for fieldName, field := range entry.Fields {
+		if field.Number > highest {
+			highest = field.Number
+		}
+
+		typ, keep := wanted[fieldName]
+		if keep && typ == field.Type {
+			continue
+		}
+		entry.Reserved = appendUniqueInt(entry.Reserved, field.Number)
+		if !keep {
+			entry.ReservedNames = appendUniqueString(entry.ReservedNames, fieldName)
+		}
+		delete(entry.Fields, fieldName)
+	}
+
+	numbers := make(map[string]int, len(slots))
+	for _, slot := range slots {
+		field, ok := entry.Fields[slot.Name]
+		if !ok {
+			highest++
+			field = protoLockField{Number: highest, Type: slot.Type}
+			entry.Fields[slot.Name] = field
+		}
+		numbers[slot.Name] = field.Number
+	}
+
+	// A field that comes back after removal gets a new number, its name is free again
+	var names []string
+	for _, reserved := range entry.ReservedNames {
+		if _, ok := wanted[reserved]; !ok {
+			names = append(names, reserved)
+		}
+	}
+	entry.ReservedNames = names
+
+	sort.Ints(entry.Reserved)
+	sort.Strings(entry.ReservedNames)
+	return numbers, entry
+}
+
+func appendUniqueInt(values []int, value int) []int {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+func appendUniqueString(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,92 @@
+package generator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vibercode/cli/internal/models"
+)
+
+// protoMessage returns the Product message of a generated proto file
+func protoMessage(proto string) string {
+	return proto[strings.Index(proto, "message Product {"):strings.Index(proto, "message CreateProductRequest")]
+}
+
+func TestSchemaGenerator_GRPCFeature(t *testing.T) {
+	schema := newTestProductSchema()
+	schema.Fields = append(schema.Fields,
+		models.SchemaField{Name: "status", Type: "enum", DisplayName: "Status", Validation: &models.FieldValidation{AllowedValues: []string{"draft", "in-stock"}}},
+		models.SchemaField{Name: "secret", Type: "password", DisplayName: "Secret"},
+	)
+
+	gen := NewSchemaGenerator(newMemorySchemaStorage(schema)).WithFeatures(models.FeatureGRPC).WithGRPCGateway()
+	dir := generateTestProject(t, gen, "postgres", schema)
+
+	assertGeneratedFiles(t, dir,
+		generatedFile{path: "proto/buf.yaml"},
+		generatedFile{
+			path: "proto/shop/v1/product.proto",
+			contains: []string{
+				"package shop.v1;",
+				`option go_package = "example.com/shop/gen/proto/shop/v1;shopv1";`,
+				"message Product {\n  string id = 1;\n  string sku = 2;",
+				"  PRODUCT_STATUS_UNSPECIFIED = 0;\n  PRODUCT_STATUS_DRAFT = 1;\n  PRODUCT_STATUS_IN_STOCK = 2;",
+				// update fields track presence
+				"  optional string sku = 2;",
+				"rpc ListProducts(ListProductsRequest) returns (ListProductsResponse);",
+			},
+		},
+		generatedFile{path: "proto/shop/v1/product.lock.json"},
+		generatedFile{
+			path:     "proto/shop/v1/gateway.yaml",
+			contains: []string{"selector: shop.v1.ProductService.GetProduct", "get: /v1/products/{id}"},
+		},
+		generatedFile{path: "buf.gen.yaml", contains: []string{"grpc_api_configuration=proto/shop/v1/gateway.yaml"}},
+		generatedFile{path: "internal/rpc/rpc.go"},
+		generatedFile{path: "internal/rpc/gateway.go"},
+		generatedFile{path: "internal/rpc/product_server.go"},
+	)
+
+	product := protoMessage(readGeneratedFile(t, dir, "proto/shop/v1/product.proto"))
+	assert.NotContains(t, product, "secret", "password fields must not be readable")
+
+	assertGoFilesParse(t, filepath.Join(dir, "internal"))
+}
+
+func TestSchemaGenerator_GRPCFieldNumbersStable(t *testing.T) {
+	schema := newTestProductSchema()
+	gen := NewSchemaGenerator(newMemorySchemaStorage(schema)).WithFeatures(models.FeatureGRPC)
+	dir := generateTestProject(t, gen, "postgres", schema)
+
+	// Drop description, retype stock and add a new field before regenerating
+	schema.Fields = []models.SchemaField{
+		{Name: "weight", Type: "float", DisplayName: "Weight"},
+		{Name: "sku", Type: "string", DisplayName: "SKU", Required: true, Database: &models.DatabaseFieldConfig{Unique: true}},
+		{Name: "name", Type: "string", DisplayName: "Name", Required: true},
+		{Name: "stock", Type: "float", DisplayName: "Stock"},
+		{Name: "active", Type: "boolean", DisplayName: "Active"},
+	}
+	require.NoError(t, gen.GenerateFromSchema(schema.ID, dir, "example.com/shop", "postgres"))
+
+	proto := readGeneratedFile(t, dir, "proto/shop/v1/product.proto")
+	product := protoMessage(proto)
+	for _, line := range []string{
+		"reserved 4, 5;",
+		`reserved "description";`,
+		"double weight = 9;",
+		"string sku = 2;",
+		"string name = 3;",
+		"double stock = 10;",
+		"bool active = 6;",
+		"google.protobuf.Timestamp created_at = 7;",
+	} {
+		assert.Contains(t, product, line)
+	}
+
+	// Regenerating an unchanged schema must not move anything
+	require.NoError(t, gen.GenerateFromSchema(schema.ID, dir, "example.com/shop", "postgres"))
+	assert.Equal(t, proto, readGeneratedFile(t, dir, "proto/shop/v1/product.proto"))
+}
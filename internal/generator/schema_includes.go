@@ -0,0 +1,169 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/vibercode/cli/internal/models"
+	"github.com/vibercode/cli/internal/templates"
+	"github.com/vibercode/cli/pkg/ui"
+)
+
+// IncludeRelation is a relation field the list and get endpoints expand with ?include
+type IncludeRelation struct {
+	Key    string // JSON key and name in ?include
+	GoName string
+	Kind   string // many_to_one, one_to_many or many_to_many
+	Target *models.NamingConventions
+	// Depth is the longest include path starting at the relation, from PopulateDepth
+	Depth int
+	// Nested reports whether the target has includes of its own to expand deeper paths
+	Nested bool
+	// LocalKey reads the foreign key held by an item (many-to-one), RemoteKey the foreign key
+	// held by a child (one-to-many), both as strings
+	LocalKey  string
+	RemoteKey string
+	// Column is the column or document key of the foreign key
+	Column string
+	// Pivot, ParentKey and TargetKey locate the pivot rows (many-to-many)
+	Pivot     string
+	ParentKey string
+	TargetKey string
+}
+
+// ViewTemplateData contains the template data for the include graph and sparse fieldsets of a resource
+type ViewTemplateData struct {
+	*EnhancedSchema
+	// ViewFields lists the JSON keys sparse fieldsets may select
+	ViewFields []string
+}
+
+// prepareIncludes resolves the relation fields that can be expanded with ?include. Relations
+// need Populate, a stored schema and a generated repository of the target.
+func (g *SchemaGenerator) prepareIncludes(data *EnhancedSchema, outputPath string) []IncludeRelation {
+	var includes []IncludeRelation
+
+	for _, field := range data.Fields {
+		relation := field.Relation
+		if relation == nil || !relation.Populate || !isRelationField(field.SchemaField) {
+			continue
+		}
+		if relation.Target != data.Name && !g.hasSchema(relation.Target) {
+			ui.PrintInfo(fmt.Sprintf("%s.%s can't be included, %s has no schema", data.Name, field.Name, relation.Target))
+			continue
+		}
+		target, err := g.relationTarget(data, relation.Target)
+		if err != nil || !hasGeneratedRepository(outputPath, target) {
+			ui.PrintInfo(fmt.Sprintf("%s.%s can't be included, generate %s first", data.Name, field.Name, relation.Target))
+			continue
+		}
+
+		include := IncludeRelation{
+			Key:    field.Names.SnakeCase,
+			GoName: field.Names.PascalCase,
+			Kind:   relation.Type,
+			Target: target.Names,
+			Depth:  relation.PopulateDepth,
+		}
+		switch {
+		case field.Type == "relation" && relation.Type == "many_to_one":
+			expr, ok := foreignKeyExpr(data.ResourceSchema, relation.ForeignKey, "item.")
+			if !ok {
+				ui.PrintInfo(fmt.Sprintf("%s.%s can't be included, %s has no %s field", data.Name, field.Name, data.Name, relation.ForeignKey))
+				continue
+			}
+			include.LocalKey = expr
+			include.Column = foreignKeyColumn(data.ResourceSchema, relation.ForeignKey, data.DBProvider)
+		case field.Type == "relation_array" && relation.Type == "one_to_many":
+			expr, ok := foreignKeyExpr(target, relation.ForeignKey, "child.")
+			if !ok {
+				ui.PrintInfo(fmt.Sprintf("%s.%s can't be included, %s has no %s field", data.Name, field.Name, target.Name, relation.ForeignKey))
+				continue
+			}
+			include.RemoteKey = expr
+			include.Column = foreignKeyColumn(target, relation.ForeignKey, data.DBProvider)
+		case field.Type == "relation_array" && relation.Type == "many_to_many":
+			include.Pivot, include.ParentKey, include.TargetKey = pivotKeys(data, field.SchemaField, target)
+		default:
+			ui.PrintInfo(fmt.Sprintf("%s.%s can't be included, %s %s fields are not supported", data.Name, field.Name, relation.Type, field.Type))
+			continue
+		}
+
+		if include.Depth < 1 {
+			include.Depth = 1
+		}
+		include.Nested = target.Name == data.Name || hasGeneratedIncludes(outputPath, target)
+		if include.Depth > 1 && !include.Nested {
+			ui.PrintInfo(fmt.Sprintf("Includes of %s.%s stop at %s, generate %s again once %s has includes", data.Name, field.Name, target.Names.Plural, data.Name, target.Name))
+			include.Depth = 1
+		}
+		includes = append(includes, include)
+	}
+
+	return includes
+}
+
+// generateIncludes generates the include graph and sparse fieldsets of a schema, and the
+// loader of its includable relations if it has any
+func (g *SchemaGenerator) generateIncludes(data *EnhancedSchema, outputPath string) error {
+	viewData := &ViewTemplateData{EnhancedSchema: data, ViewFields: []string{"id", "created_at", "updated_at"}}
+	for _, field := range data.Fields {
+		viewData.ViewFields = append(viewData.ViewFields, field.Names.SnakeCase)
+	}
+	if data.Geo != nil {
+		viewData.ViewFields = append(viewData.ViewFields, "distance")
+	}
+
+	snake := data.Names.SnakeCase
+	files := []struct {
+		template string
+		path     string
+	}{
+		{templates.ViewTemplate, filepath.Join("internal", "models", "view.go")},
+		{templates.ViewTestTemplate, filepath.Join("internal", "models", "view_test.go")},
+		{templates.SchemaViewTemplate, filepath.Join("internal", "models", snake+"_view.go")},
+	}
+	if len(data.Includes) > 0 {
+		includeTemplates := map[string]string{
+			"gorm":  templates.SchemaIncludeGORMTemplate,
+			"mongo": templates.SchemaIncludeMongoTemplate,
+		}
+		files = append(files, struct {
+			template string
+			path     string
+		}{includeTemplates[relationBackend(data)], filepath.Join("internal", "repositories", snake+"_includes.go")})
+	}
+
+	for _, file := range files {
+		if err := g.generateGoFile(file.template, viewData, filepath.Join(outputPath, file.path)); err != nil {
+			return err
+		}
+	}
+
+	if len(data.Includes) > 0 {
+		ui.PrintInfo("Wire New" + data.Names.PascalCase + "Service(repo).WithIncludes(repositories.New" + data.Names.PascalCase + "Includes(db)) to load ?include")
+	}
+	return nil
+}
+
+// pivotKeys returns the pivot table or collection of a many-to-many relation field and
+// the keys referencing the schema and the target
+func pivotKeys(data *EnhancedSchema, field *models.SchemaField, target *models.ResourceSchema) (pivot, parentKey, targetKey string) {
+	pivot = field.Relation.PivotTable
+	if pivot == "" {
+		pivot = data.Names.SnakeCase + "_" + toSnakeCase(field.Name)
+	}
+	parentKey = data.Names.SnakeCase + "_id"
+	targetKey = target.Names.SnakeCase + "_id"
+	if targetKey == parentKey {
+		targetKey = "related_" + targetKey
+	}
+	return pivot, parentKey, targetKey
+}
+
+// hasGeneratedIncludes reports whether the include loader of a schema was generated in the output directory
+func hasGeneratedIncludes(outputPath string, schema *models.ResourceSchema) bool {
+	_, err := os.Stat(layoutPath(outputPath, "internal", "repositories", schema.Names.SnakeCase+"_includes.go"))
+	return err == nil
+}
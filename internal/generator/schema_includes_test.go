@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/vibercode/cli/internal/models"
+)
+
+func TestSchemaGenerator_Includes(t *testing.T) {
+	category := &models.ResourceSchema{
+		ID:          "category-1",
+		Name:        "Category",
+		DisplayName: "Category",
+		Names:       models.CreateResourceNames("Category"),
+		Fields: []models.SchemaField{
+			{Name: "name", Type: "string", DisplayName: "Name", Required: true},
+			{Name: "products", Type: "relation_array", DisplayName: "Products", Relation: &models.RelationConfig{Type: "one_to_many", Target: "Product", ForeignKey: "category_id", Populate: true, PopulateDepth: 2}},
+		},
+		Database: &models.DatabaseConfig{Provider: "postgres", TableName: "categories"},
+	}
+	tag := &models.ResourceSchema{
+		ID:          "tag-1",
+		Name:        "Tag",
+		DisplayName: "Tag",
+		Names:       models.CreateResourceNames("Tag"),
+		Fields:      []models.SchemaField{{Name: "label", Type: "string", DisplayName: "Label", Required: true}},
+		Database:    &models.DatabaseConfig{Provider: "postgres", TableName: "tags"},
+	}
+	product := newTestProductSchema()
+	product.Fields = append(product.Fields,
+		models.SchemaField{Name: "category_id", Type: "string", DisplayName: "Category ID"},
+		models.SchemaField{Name: "tags", Type: "relation_array", DisplayName: "Tags", Relation: &models.RelationConfig{Type: "many_to_many", Target: "Tag", PivotTable: "product_tags", Populate: true, PopulateDepth: 3}},
+	)
+
+	tests := []struct {
+		provider string
+		loader   string
+	}{
+		{provider: "postgres", loader: `Where("category_id IN ?", ids).Order("created_at DESC, id")`},
+		{provider: "mongodb", loader: `"$lookup": bson.M{`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			gen := NewSchemaGenerator(newMemorySchemaStorage(category, tag, product))
+			dir := generateTestProject(t, gen, tt.provider, tag, product, category)
+
+			assertGeneratedFiles(t, dir,
+				// Relations set to populate are included without ?include
+				generatedFile{path: "internal/models/view.go", contains: []string{"include = view.defaultIncludes()"}},
+				generatedFile{path: "internal/models/view_test.go"},
+				// Products have includes to expand deeper paths, tags have none and paths stop at them
+				generatedFile{path: "internal/models/category_view.go", contains: []string{`"products": {Type: "product", Depth: 2}`}},
+				generatedFile{path: "internal/models/product_view.go", contains: []string{`"tags": {Type: "tag", Depth: 1}`}},
+				generatedFile{
+					path: "internal/handlers/category_handler.go",
+					contains: []string{
+						`models.ParseView("category", c.Request.URL.RawQuery)`,
+						"view.Shape(responses)",
+						"h.service.Include(c.Request.Context(), categories, view.Include)",
+					},
+				},
+				generatedFile{
+					path:     "internal/repositories/category_includes.go",
+					contains: []string{tt.loader, "NewProductIncludes(r.db).Include(ctx, related, "},
+				},
+			)
+
+			assertGoFilesParse(t, filepath.Join(dir, "internal"))
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package generator
+
+import (
+	"path/filepath"
+
+	"github.com/vibercode/cli/internal/models"
+	"github.com/vibercode/cli/internal/templates"
+	"github.com/vibercode/cli/pkg/ui"
+)
+
+// generateMoney generates the money package holding the amounts of currency fields,
+// if the schema has any
+func (g *SchemaGenerator) generateMoney(data *EnhancedSchema, outputPath string) error {
+	if !data.UsesMoney() {
+		return nil
+	}
+
+	files := []struct {
+		template string
+		path     string
+	}{
+		{templates.MoneyPackageTemplate, filepath.Join("internal", "money", "money.go")},
+		{templates.MoneyTestTemplate, filepath.Join("internal", "money", "money_test.go")},
+	}
+
+	for _, file := range files {
+		if err := g.generateGoFile(file.template, data, filepath.Join(outputPath, file.path)); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case data.DBProvider == "mongodb":
+	case data.UsesGORM():
+		// GORM reads the column types of embedded structs from their own tags
+		for _, field := range data.GetMoneyFields() {
+			if field.Database != nil && field.Database.Precision > 0 {
+				ui.PrintWarning("GORM creates DECIMAL(19,4) amount columns, edit the money.Money tags for the precision of " + field.Name)
+				break
+			}
+		}
+	case data.DBProvider == "sqlite":
+		ui.PrintInfo("SQLite stores amounts as integer minor units, 1250 for 12.50 EUR")
+	}
+	return nil
+}
+
+// moneySample returns a sample amount of a currency field in its first allowed currency,
+// without decimals which some currencies don't have
+func moneySample(field *models.SchemaField) string {
+	currency := "USD"
+	if codes := field.AllowedCurrencies(); len(codes) > 0 {
+		currency = codes[0]
+	}
+	return "10 " + currency
+}
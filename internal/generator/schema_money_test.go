@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/vibercode/cli/internal/models"
+)
+
+func TestSchemaGenerator_MoneyFields(t *testing.T) {
+	schema := newTestProductSchema()
+	schema.Fields = append(schema.Fields,
+		models.SchemaField{Name: "price", Type: string(models.FieldTypeCurrency), DisplayName: "Price", Required: true,
+			Validation: &models.FieldValidation{AllowedValues: []string{"eur", "usd"}}, Database: &models.DatabaseFieldConfig{Precision: 12, Scale: 2}},
+	)
+
+	tests := []struct {
+		provider   string
+		layer      models.DataLayer
+		repository []string
+		migration  []string
+	}{
+		{provider: "postgres"},
+		{
+			provider:   "postgres",
+			layer:      models.DataLayerSQLX,
+			repository: []string{"Amount: row.PriceAmount, Currency: row.PriceCurrency"},
+			migration:  []string{"price_amount NUMERIC(12,2) NOT NULL,", "price_currency VARCHAR(3) NOT NULL"},
+		},
+		{
+			provider:   "sqlite",
+			layer:      models.DataLayerSQLX,
+			repository: []string{"money.FromMinor(row.PriceAmount, row.PriceCurrency)", "product.Price.MinorUnits()"},
+			migration:  []string{"price_amount INTEGER NOT NULL,", "price_currency VARCHAR(3) NOT NULL"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider+"/"+string(tt.layer), func(t *testing.T) {
+			gen := NewSchemaGenerator(newMemorySchemaStorage(schema))
+			if tt.layer != "" {
+				gen = gen.WithDataLayer(tt.layer)
+			}
+			dir := generateTestProject(t, gen, tt.provider, schema)
+
+			model := generatedFile{
+				path: "internal/models/product.go",
+				contains: []string{
+					`"example.com/shop/internal/money"`,
+					"Price money.Money",
+					`if r.Price.Currency != "" && !r.Price.In("EUR", "USD") {`,
+				},
+			}
+			if tt.layer == "" {
+				model.contains = append(model.contains, `gorm:"embedded;embeddedPrefix:price_"`)
+			}
+			files := []generatedFile{
+				{path: "internal/money/money.go"},
+				{path: "internal/money/money_test.go"},
+				model,
+			}
+			if tt.layer != "" {
+				files = append(files,
+					generatedFile{path: "internal/repositories/product_repository.go", contains: tt.repository},
+					generatedFile{path: "migrations/*_create_products.sql", contains: tt.migration},
+				)
+			}
+			assertGeneratedFiles(t, dir, files...)
+
+			assertGoFilesParse(t, filepath.Join(dir, "internal"))
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package generator
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/vibercode/cli/internal/models"
+	"github.com/vibercode/cli/internal/templates"
+	"github.com/vibercode/cli/pkg/ui"
+)
+
+// ObservabilityTemplateData contains the template data for the observability feature
+type ObservabilityTemplateData struct {
+	*EnhancedSchema
+	Name string // Project name, the service and docker-compose network of the API
+	Port string // Port of the API, scraped by Prometheus
+}
+
+// generateObservabilityFeature generates the observability package tracing HTTP handlers,
+// database and cache calls and outbound requests with OpenTelemetry, exposing RED metrics by
+// route for Prometheus and adding trace IDs to structured logs, along with an optional
+// docker-compose override running the collector, Jaeger, Prometheus and Grafana
+func (g *SchemaGenerator) generateObservabilityFeature(data *EnhancedSchema, outputPath string) error {
+	obsData, wiring, err := g.writeObservability(data, outputPath)
+	if err != nil {
+		return err
+	}
+
+	ui.PrintInfo("Observability uses OpenTelemetry and the Prometheus client, run 'go mod tidy' after generation")
+	ui.PrintInfo("Wire shutdown, err := observability.Setup(ctx, \"" + obsData.Name + "\") in main.go, deferring shutdown, register " + observabilityMiddleware(data.HTTP) + " and serve observability.MetricsHandler() on /metrics")
+	for _, hook := range wiring {
+		ui.PrintInfo("Wire " + hook + " where the client is created")
+	}
+	ui.PrintInfo("Send outbound requests with observability.NewHTTPClient or observability.NewTransport to propagate the trace")
+	ui.PrintInfo("Start the collector, Jaeger, Prometheus and Grafana with docker compose -f docker-compose.yml -f docker-compose.observability.yml up")
+	return nil
+}
+
+// writeObservability writes the observability package and the deployment files that do not
+// exist yet, returning the template data and the hooks instrumenting the database and cache
+// clients of the project
+func (g *SchemaGenerator) writeObservability(data *EnhancedSchema, outputPath string) (*ObservabilityTemplateData, []string, error) {
+	obsData := &ObservabilityTemplateData{
+		EnhancedSchema: data,
+		Name:           path.Base(data.Module),
+		Port:           "8080",
+	}
+	if manifest, err := LoadManifest(outputPath); err == nil {
+		if manifest.Name != "" {
+			obsData.Name = manifest.Name
+		}
+		if manifest.Port != "" {
+			obsData.Port = manifest.Port
+		}
+	}
+
+	type file struct {
+		template string
+		path     string
+	}
+	files := []file{
+		{templates.ObservabilityPackageTemplate, filepath.Join("internal", "observability", "observability.go")},
+		{templates.ObservabilityMetricsTemplate, filepath.Join("internal", "observability", "metrics.go")},
+		{templates.ObservabilityHTTPTemplate, filepath.Join("internal", "observability", "http.go")},
+		{templates.ObservabilityClientTemplate, filepath.Join("internal", "observability", "client.go")},
+		{templates.ObservabilityLogTemplate, filepath.Join("internal", "observability", "log.go")},
+		{templates.ObservabilityTestTemplate, filepath.Join("internal", "observability", "observability_test.go")},
+	}
+
+	// Database and cache calls are instrumented with the hooks of their client
+	var wiring []string
+	if data.UsesGORM() {
+		files = append(files, file{templates.ObservabilityGORMTemplate, filepath.Join("internal", "observability", "gorm.go")})
+		wiring = append(wiring, "observability.InstrumentGORM(db)")
+	}
+	if data.DBProvider == "mongodb" {
+		files = append(files, file{templates.ObservabilityMongoTemplate, filepath.Join("internal", "observability", "mongo.go")})
+		wiring = append(wiring, "options.Client().SetMonitor(observability.NewMongoMonitor())")
+	}
+	if data.DBProvider == "redis" || data.HasFeature(models.FeatureCaching) {
+		files = append(files, file{templates.ObservabilityRedisTemplate, filepath.Join("internal", "observability", "redis.go")})
+		wiring = append(wiring, "client.AddHook(observability.RedisHook{})")
+	}
+
+	for _, file := range files {
+		if err := g.generateGoFile(file.template, obsData, filepath.Join(outputPath, file.path)); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// The deployment files are only written once, they are tuned along with the project
+	deployment := []struct {
+		template string
+		path     string
+		static   bool
+	}{
+		{templates.ObservabilityComposeTemplate, "docker-compose.observability.yml", false},
+		{templates.ObservabilityPrometheusTemplate, filepath.Join("deployment", "observability", "prometheus.yml"), false},
+		{templates.ObservabilityCollectorConfig, filepath.Join("deployment", "observability", "otel-collector.yaml"), true},
+		{templates.ObservabilityGrafanaDatasources, filepath.Join("deployment", "observability", "grafana", "provisioning", "datasources", "datasources.yml"), true},
+		{templates.ObservabilityGrafanaDashboards, filepath.Join("deployment", "observability", "grafana", "provisioning", "dashboards", "dashboards.yml"), true},
+		{templates.ObservabilityGrafanaREDDashboard, filepath.Join("deployment", "observability", "grafana", "dashboards", "red.json"), true},
+	}
+	for _, file := range deployment {
+		target := filepath.Join(outputPath, file.path)
+		if _, err := os.Stat(target); err == nil {
+			continue
+		}
+		var err error
+		if file.static {
+			err = g.writeGeneratedFile(target, []byte(file.template))
+		} else {
+			err = g.generateFile(file.template, obsData, target)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return obsData, wiring, nil
+}
+
+// observabilityMiddleware returns how the request middleware is registered on the framework
+func observabilityMiddleware(dialect *HTTPDialect) string {
+	switch {
+	case dialect.Is("chi"):
+		return "router.Use(observability.Middleware)"
+	case dialect.Is("stdlib"):
+		return "observability.Middleware(api) as the handler of the API routes"
+	case dialect.Is("echo"):
+		return "e.Use(observability.Middleware())"
+	case dialect.Is("fiber"):
+		return "app.Use(observability.Middleware())"
+	default:
+		return "r.Use(observability.Middleware())"
+	}
+}
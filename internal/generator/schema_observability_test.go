@@ -0,0 +1,68 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vibercode/cli/internal/models"
+)
+
+func TestSchemaGenerator_ObservabilityFeature(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, SaveManifest(dir, &VibercodeManifest{Name: "shop", Port: "3000", Module: "example.com/shop"}))
+	schema := newTestProductSchema()
+
+	gen := NewSchemaGenerator(newMemorySchemaStorage(schema)).
+		WithFeatures(models.FeatureObservability).
+		WithHTTPFramework(models.HTTPChi)
+	require.NoError(t, gen.GenerateFromSchema(schema.ID, dir, "example.com/shop", "postgres"))
+
+	assertGeneratedFiles(t, dir,
+		generatedFile{path: "internal/observability/observability.go"},
+		generatedFile{path: "internal/observability/metrics.go"},
+		generatedFile{
+			path:     "internal/observability/http.go",
+			contains: []string{"func Middleware(next http.Handler) http.Handler {", "route = routes.RoutePattern()"},
+		},
+		generatedFile{path: "internal/observability/client.go"},
+		generatedFile{path: "internal/observability/log.go"},
+		generatedFile{path: "internal/observability/observability_test.go"},
+		generatedFile{path: "internal/observability/gorm.go"},
+		generatedFile{path: "internal/observability/mongo.go", missing: true},
+		generatedFile{path: "internal/observability/redis.go", missing: true},
+		// The project name and port come from the manifest
+		generatedFile{
+			path:     "docker-compose.observability.yml",
+			contains: []string{"OTEL_EXPORTER_OTLP_ENDPOINT=http://otel-collector:4318", "      - shop-network\n"},
+		},
+		generatedFile{path: "deployment/observability/prometheus.yml", contains: []string{`targets: ["app:3000"]`}},
+		generatedFile{path: "deployment/observability/otel-collector.yaml"},
+		generatedFile{path: "deployment/observability/grafana/provisioning/datasources/datasources.yml"},
+		generatedFile{path: "deployment/observability/grafana/provisioning/dashboards/dashboards.yml"},
+		generatedFile{path: "deployment/observability/grafana/dashboards/red.json", contains: []string{`"legendFormat": "{{route}}"`}},
+	)
+
+	assertGoFilesParse(t, filepath.Join(dir, "internal"))
+
+	// Edited deployment files are kept when the schema is generated again
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "deployment", "observability", "prometheus.yml"), []byte("edited"), 0644))
+	require.NoError(t, gen.GenerateFromSchema(schema.ID, dir, "example.com/shop", "postgres"))
+	assert.Equal(t, "edited", readGeneratedFile(t, dir, "deployment/observability/prometheus.yml"))
+
+	// MongoDB projects with caching instrument the MongoDB and Redis clients
+	mongoGen := NewSchemaGenerator(newMemorySchemaStorage(newTestProductSchema())).
+		WithFeatures(models.FeatureObservability, models.FeatureCaching).
+		WithHTTPFramework(models.HTTPFiber)
+	mongoDir := generateTestProject(t, mongoGen, "mongodb", schema)
+
+	assertGeneratedFiles(t, mongoDir,
+		generatedFile{path: "internal/observability/gorm.go", missing: true},
+		generatedFile{path: "internal/observability/mongo.go", contains: []string{"func NewMongoMonitor() *event.CommandMonitor {"}},
+		generatedFile{path: "internal/observability/redis.go", contains: []string{"var _ redis.Hook = RedisHook{}"}},
+		generatedFile{path: "internal/observability/http.go", contains: []string{"func Middleware() fiber.Handler {"}},
+	)
+	assertGoFilesParse(t, filepath.Join(mongoDir, "internal"))
+}
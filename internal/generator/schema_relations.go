@@ -0,0 +1,382 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vibercode/cli/internal/models"
+	"github.com/vibercode/cli/internal/templates"
+	"github.com/vibercode/cli/pkg/ui"
+)
+
+// RelationTemplateData contains the template data for the nested routes of relation fields
+type RelationTemplateData struct {
+	*EnhancedSchema
+	// Backend is the library of the relation repository: mongo, gorm, sqlx, pgx or sqlc
+	Backend   string
+	Relations []RelationRoute
+}
+
+// RelationRoute is a one-to-many or many-to-many relation served below the routes of its resource
+type RelationRoute struct {
+	Name    string
+	GoName  string
+	Route   string
+	Label   string // Plural noun of the related records in messages
+	Many    bool   // Many-to-many, associated through a pivot table
+	Cascade bool
+	Target  *models.NamingConventions
+	// TargetParam is the route parameter of the associated record
+	TargetParam string
+	// ForeignKey is the column or document key of the foreign key on the target (one-to-many)
+	ForeignKey string
+	// Pivot is the pivot table or collection, ParentKey and TargetKey its key columns (many-to-many)
+	Pivot       string
+	PivotRow    string
+	ParentKey   string
+	TargetKey   string
+	PivotFields []PivotField
+	// TargetRowType, ListQuery and CountQuery are the row struct and the Go expressions
+	// of the base list statements of the target in the sqlx, pgx and sqlc repositories
+	TargetRowType string
+	ListQuery     string
+	CountQuery    string
+	SQL           RelationSQL
+	TestPayload   string
+}
+
+// PivotField is an attribute stored on the pivot rows of a many-to-many relation
+type PivotField struct {
+	Name         string // Column or document key
+	GoName       string
+	GoType       string
+	SQLType      string
+	RequestField string
+	Validation   string
+}
+
+// RelationSQL holds the statements of a relation in the SQL repositories
+type RelationSQL struct {
+	ChildrenWhere    string // Condition appended to the target list statements (one-to-many)
+	ChildrenPage     string
+	ListPivots       string // Page of pivot rows whose target exists (many-to-many)
+	CountPivots      string
+	TargetsIn        string // Condition appended to the target list statement to load the page
+	TargetExists     string
+	Attach           string
+	Detach           string
+	CountDependents  string
+	DeleteDependents string
+}
+
+// pivotGoTypes lists the Go types pivot attributes may have
+var pivotGoTypes = map[string]bool{"string": true, "int64": true, "float64": true, "bool": true, "time.Time": true}
+
+// HasManyToMany reports whether a relation is associated through a pivot table
+func (d *RelationTemplateData) HasManyToMany() bool {
+	for _, relation := range d.Relations {
+		if relation.Many {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRestricted reports whether a relation without cascade blocks deletes
+func (d *RelationTemplateData) HasRestricted() bool {
+	for _, relation := range d.Relations {
+		if !relation.Cascade {
+			return true
+		}
+	}
+	return false
+}
+
+// RowTag returns the struct tag mapping a pivot row field onto its column
+func (d *RelationTemplateData) RowTag(column string) string {
+	switch d.Backend {
+	case "gorm":
+		return "`gorm:\"column:" + column + "\"`"
+	case "mongo":
+		return "`bson:\"" + column + "\"`"
+	default:
+		return "`db:\"" + column + "\"`"
+	}
+}
+
+// generateRelations generates the nested routes of the one-to-many and many-to-many
+// relation fields of a schema, if it has any
+func (g *SchemaGenerator) generateRelations(data *EnhancedSchema, outputPath string) error {
+	relationData := g.prepareRelationData(data, outputPath)
+	if len(relationData.Relations) == 0 {
+		return nil
+	}
+	data.RelationRoutes = relationData.Relations
+	snake := data.Names.SnakeCase
+
+	repositoryTemplates := map[string]string{
+		"mongo": templates.SchemaRelationMongoRepositoryTemplate,
+		"gorm":  templates.SchemaRelationGORMRepositoryTemplate,
+		"sqlx":  templates.SchemaRelationSQLXRepositoryTemplate,
+		"pgx":   templates.SchemaRelationPGXRepositoryTemplate,
+		"sqlc":  templates.SchemaRelationPGXRepositoryTemplate,
+	}
+
+	files := []struct {
+		template string
+		path     string
+	}{
+		{templates.RelationsTemplate, filepath.Join("internal", "models", "relations.go")},
+		{repositoryTemplates[relationData.Backend], filepath.Join("internal", "repositories", snake+"_relation_repository.go")},
+		{templates.SchemaRelationServiceTemplate, filepath.Join("internal", "services", snake+"_relation_service.go")},
+		{templates.SchemaRelationHandlerTemplate, filepath.Join("internal", "handlers", snake+"_relation_handler.go")},
+		{templates.SchemaRelationHandlerTestTemplate, filepath.Join("internal", "handlers", snake+"_relation_handler_test.go")},
+	}
+	if relationData.HasManyToMany() {
+		files = append(files, struct {
+			template string
+			path     string
+		}{templates.SchemaRelationModelTemplate, filepath.Join("internal", "models", snake+"_relations.go")})
+	}
+
+	for _, file := range files {
+		if err := g.generateGoFile(file.template, relationData, filepath.Join(outputPath, file.path)); err != nil {
+			return err
+		}
+	}
+
+	if data.DBProvider != "mongodb" {
+		for _, relation := range relationData.Relations {
+			if !relation.Many {
+				continue
+			}
+			if err := g.writeSQLMigration(outputPath, "create_"+relation.Pivot, fmt.Sprintf("Create the %s pivot table", relation.Pivot),
+				sqlPivotTable(relation, data.DBProvider), fmt.Sprintf("DROP TABLE IF EXISTS %s;", relation.Pivot)); err != nil {
+				return fmt.Errorf("failed to generate pivot migration: %w", err)
+			}
+		}
+	} else if relationData.HasManyToMany() {
+		ui.PrintInfo("Call " + data.Names.PascalCase + "RelationRepository.EnsureRelationIndexes at startup to create the unique pivot indexes")
+	}
+
+	ui.PrintInfo("Wire New" + data.Names.PascalCase + "Service(repositories.New" + data.Names.PascalCase + "RelationRepository(repo, db)) so deletes follow the cascade rules, and Setup" +
+		data.Names.PascalCase + "RelationRoutes with services.New" + data.Names.PascalCase + "RelationService(relationRepo)")
+	return nil
+}
+
+// prepareRelationData resolves the relations served below the routes of the schema. The
+// target needs a stored schema and a generated repository, one-to-many relations a string
+// foreign key field on the target.
+func (g *SchemaGenerator) prepareRelationData(data *EnhancedSchema, outputPath string) *RelationTemplateData {
+	relationData := &RelationTemplateData{EnhancedSchema: data, Backend: relationBackend(data)}
+
+	for _, field := range data.Fields {
+		relation := field.Relation
+		if relation == nil || field.Type != "relation_array" {
+			continue
+		}
+		if relation.Type != "one_to_many" && relation.Type != "many_to_many" {
+			ui.PrintInfo(fmt.Sprintf("Skipping relation routes of %s.%s, %s relations are not supported", data.Name, field.Name, relation.Type))
+			continue
+		}
+		if relation.Target != data.Name && !g.hasSchema(relation.Target) {
+			ui.PrintInfo(fmt.Sprintf("Skipping relation routes of %s.%s, %s has no schema", data.Name, field.Name, relation.Target))
+			continue
+		}
+		target, err := g.relationTarget(data, relation.Target)
+		if err != nil || !hasGeneratedRepository(outputPath, target) {
+			ui.PrintInfo(fmt.Sprintf("Skipping relation routes of %s.%s, generate %s first", data.Name, field.Name, relation.Target))
+			continue
+		}
+
+		route := RelationRoute{
+			Name:        field.Names.SnakeCase,
+			GoName:      field.Names.PascalCase,
+			Route:       field.Names.KebabCase,
+			Label:       strings.ReplaceAll(field.Names.SnakeCase, "_", " "),
+			Many:        relation.Type == "many_to_many",
+			Cascade:     relation.Cascade,
+			Target:      target.Names,
+			TargetParam: target.Names.SnakeCase + "_id",
+		}
+		if data.DataLayer == models.DataLayerSQLC {
+			route.TargetRowType = "db." + sqlcIdentifier(target.Names.TableName)
+			route.ListQuery = target.Names.CamelCase + "ListQuery"
+			route.CountQuery = target.Names.CamelCase + "CountQuery"
+		} else {
+			route.TargetRowType = target.Names.CamelCase + "Row"
+			route.ListQuery = fmt.Sprintf("%sQueries[%q]", target.Names.CamelCase, "List"+target.Names.PascalPlural)
+			route.CountQuery = fmt.Sprintf("%sQueries[%q]", target.Names.CamelCase, "Count"+target.Names.PascalPlural)
+		}
+
+		if route.Many {
+			route.Pivot, route.ParentKey, route.TargetKey = pivotKeys(data, field.SchemaField, target)
+			route.PivotRow = data.Names.CamelCase + field.Names.PascalCase + "PivotRow"
+			route.PivotFields = g.pivotFields(data, field.Name, relation.PivotFields)
+			route.TestPayload = pivotTestPayload(relation.PivotFields)
+		} else {
+			if _, ok := findForeignKeyField(target, relation.ForeignKey); !ok {
+				ui.PrintInfo(fmt.Sprintf("Skipping relation routes of %s.%s, %s has no %s field", data.Name, field.Name, target.Name, relation.ForeignKey))
+				continue
+			}
+			route.ForeignKey = foreignKeyColumn(target, relation.ForeignKey, data.DBProvider)
+		}
+		if relationData.Backend != "mongo" {
+			route.SQL = relationSQL(route, relationData.Backend, data.DBProvider)
+		}
+
+		relationData.Relations = append(relationData.Relations, route)
+	}
+
+	return relationData
+}
+
+// pivotFields converts the pivot attributes of a many-to-many relation, skipping the
+// attributes whose type can't be stored on pivot rows
+func (g *SchemaGenerator) pivotFields(data *EnhancedSchema, relation string, fields []models.SchemaField) []PivotField {
+	var pivotFields []PivotField
+	for i := range fields {
+		field := &fields[i]
+		goType := field.GetGoType()
+		if !pivotGoTypes[goType] || field.IsSensitive() {
+			ui.PrintWarning(fmt.Sprintf("Skipping pivot attribute %s of %s.%s, %s attributes are not supported", field.Name, data.Name, relation, field.Type))
+			continue
+		}
+		enhanced := g.enhanceField(field, data.DBProvider)
+		pivotFields = append(pivotFields, PivotField{
+			Name:         columnName(field),
+			GoName:       enhanced.Names.PascalCase,
+			GoType:       goType,
+			SQLType:      sqlColumnType(field, data.DBProvider),
+			RequestField: enhanced.GoRequestField,
+			Validation:   enhanced.GoValidation,
+		})
+	}
+	return pivotFields
+}
+
+// pivotTestPayload builds an attach request body passing the binding rules of the pivot attributes
+func pivotTestPayload(fields []models.SchemaField) string {
+	var pairs []string
+	for i := range fields {
+		value, ok := handlerTestValue(&fields[i])
+		if !ok {
+			continue
+		}
+		pairs = append(pairs, fmt.Sprintf("%q:%s", toSnakeCase(fields[i].Name), value))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// relationBackend returns the library of the relation repository
+func relationBackend(data *EnhancedSchema) string {
+	if data.DBProvider == "mongodb" {
+		return "mongo"
+	}
+	switch data.DataLayer {
+	case models.DataLayerSQLX:
+		return "sqlx"
+	case models.DataLayerPGX:
+		return "pgx"
+	case models.DataLayerSQLC:
+		return "sqlc"
+	default:
+		return "gorm"
+	}
+}
+
+// hasGeneratedRepository reports whether the repository of a schema was generated in the output directory
+func hasGeneratedRepository(outputPath string, schema *models.ResourceSchema) bool {
+	_, err := os.Stat(layoutPath(outputPath, "internal", "repositories", schema.Names.SnakeCase+"_repository.go"))
+	return err == nil
+}
+
+// relationSQL builds the statements of a relation. GORM and sqlx take ? parameters,
+// pgx and sqlc PostgreSQL positional parameters.
+func relationSQL(route RelationRoute, backend, provider string) RelationSQL {
+	param := func(n int) string {
+		if backend == "gorm" || backend == "sqlx" {
+			return "?"
+		}
+		return fmt.Sprintf("$%d", n)
+	}
+	target := route.Target.TableName
+
+	if !route.Many {
+		return RelationSQL{
+			ChildrenWhere:    fmt.Sprintf(" WHERE %s = %s", route.ForeignKey, param(1)),
+			ChildrenPage:     fmt.Sprintf(" ORDER BY created_at DESC, id LIMIT %s OFFSET %s", param(2), param(3)),
+			CountDependents:  fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s = %s", target, route.ForeignKey, param(1)),
+			DeleteDependents: fmt.Sprintf("DELETE FROM %s WHERE %s = %s", target, route.ForeignKey, param(1)),
+		}
+	}
+
+	columns := []string{route.ParentKey, route.TargetKey, "created_at"}
+	selected := []string{"p." + route.TargetKey, "p.created_at"}
+	var updates []string
+	for _, field := range route.PivotFields {
+		columns = append(columns, field.Name)
+		selected = append(selected, "p."+field.Name)
+		if provider == "mysql" {
+			updates = append(updates, fmt.Sprintf("%s = VALUES(%s)", field.Name, field.Name))
+		} else {
+			updates = append(updates, fmt.Sprintf("%s = excluded.%s", field.Name, field.Name))
+		}
+	}
+	values := make([]string, len(columns))
+	for i := range columns {
+		values[i] = param(i + 1)
+	}
+
+	// Re-attaching updates the attributes and keeps the time of the first attach
+	upsert := fmt.Sprintf(" ON CONFLICT (%s, %s) DO NOTHING", route.ParentKey, route.TargetKey)
+	switch {
+	case provider == "mysql" && len(updates) == 0:
+		upsert = fmt.Sprintf(" ON DUPLICATE KEY UPDATE %s = %s", route.ParentKey, route.ParentKey)
+	case provider == "mysql":
+		upsert = " ON DUPLICATE KEY UPDATE " + strings.Join(updates, ", ")
+	case len(updates) > 0:
+		upsert = fmt.Sprintf(" ON CONFLICT (%s, %s) DO UPDATE SET %s", route.ParentKey, route.TargetKey, strings.Join(updates, ", "))
+	}
+
+	targetsIn := " WHERE id IN (?)"
+	if backend == "pgx" || backend == "sqlc" {
+		targetsIn = " WHERE id = ANY($1)"
+	}
+
+	// Pivot rows of deleted targets are left out until they are detached
+	join := fmt.Sprintf("FROM %s p JOIN %s t ON t.id = p.%s WHERE p.%s = %s", route.Pivot, target, route.TargetKey, route.ParentKey, param(1))
+	return RelationSQL{
+		ListPivots:       fmt.Sprintf("SELECT %s %s ORDER BY p.created_at DESC, p.%s LIMIT %s OFFSET %s", strings.Join(selected, ", "), join, route.TargetKey, param(2), param(3)),
+		CountPivots:      "SELECT COUNT(*) " + join,
+		TargetsIn:        targetsIn,
+		TargetExists:     fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE id = %s", target, param(1)),
+		Attach:           fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)%s", route.Pivot, strings.Join(columns, ", "), strings.Join(values, ", "), upsert),
+		Detach:           fmt.Sprintf("DELETE FROM %s WHERE %s = %s AND %s = %s", route.Pivot, route.ParentKey, param(1), route.TargetKey, param(2)),
+		CountDependents:  fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s = %s", route.Pivot, route.ParentKey, param(1)),
+		DeleteDependents: fmt.Sprintf("DELETE FROM %s WHERE %s = %s", route.Pivot, route.ParentKey, param(1)),
+	}
+}
+
+// sqlPivotTable returns the statements creating the pivot table of a many-to-many relation
+func sqlPivotTable(route RelationRoute, provider string) string {
+	lines := []string{
+		route.ParentKey + " VARCHAR(24) NOT NULL",
+		route.TargetKey + " VARCHAR(24) NOT NULL",
+		"created_at " + sqlTimestampType(provider) + " NOT NULL",
+	}
+	for _, field := range route.PivotFields {
+		lines = append(lines, field.Name+" "+field.SQLType+" NOT NULL")
+	}
+	lines = append(lines, fmt.Sprintf("PRIMARY KEY (%s, %s)", route.ParentKey, route.TargetKey))
+
+	// MySQL has no IF NOT EXISTS for indexes
+	ifNotExists := " IF NOT EXISTS"
+	if provider == "mysql" {
+		ifNotExists = ""
+	}
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n    %s\n);\n\nCREATE INDEX%s idx_%s_%s ON %s (%s);",
+		route.Pivot, strings.Join(lines, ",\n    "), ifNotExists, route.Pivot, route.TargetKey, route.Pivot, route.TargetKey)
+}
@@ -0,0 +1,98 @@
+package generator
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/vibercode/cli/internal/models"
+)
+
+func TestSchemaGenerator_RelationRoutes(t *testing.T) {
+	category := &models.ResourceSchema{
+		ID:          "category-1",
+		Name:        "Category",
+		DisplayName: "Category",
+		Names:       models.CreateResourceNames("Category"),
+		Fields: []models.SchemaField{
+			{Name: "name", Type: "string", DisplayName: "Name", Required: true},
+			{Name: "products", Type: "relation_array", DisplayName: "Products", Relation: &models.RelationConfig{Type: "one_to_many", Target: "Product", ForeignKey: "category_id"}},
+		},
+		Database: &models.DatabaseConfig{Provider: "postgres", TableName: "categories"},
+	}
+	tag := &models.ResourceSchema{
+		ID:          "tag-1",
+		Name:        "Tag",
+		DisplayName: "Tag",
+		Names:       models.CreateResourceNames("Tag"),
+		Fields:      []models.SchemaField{{Name: "label", Type: "string", DisplayName: "Label", Required: true}},
+		Database:    &models.DatabaseConfig{Provider: "postgres", TableName: "tags"},
+	}
+	product := newTestProductSchema()
+	product.Fields = append(product.Fields,
+		models.SchemaField{Name: "category_id", Type: "string", DisplayName: "Category ID"},
+		models.SchemaField{Name: "category", Type: "relation", DisplayName: "Category", Relation: &models.RelationConfig{Type: "many_to_one", Target: "Category", ForeignKey: "category_id"}},
+		models.SchemaField{Name: "tags", Type: "relation_array", DisplayName: "Tags", Relation: &models.RelationConfig{
+			Type: "many_to_many", Target: "Tag", PivotTable: "product_tags", Cascade: true,
+			PivotFields: []models.SchemaField{{Name: "position", Type: "number", DisplayName: "Position"}},
+		}},
+	)
+
+	tests := []struct {
+		provider string
+		layer    models.DataLayer
+		attach   string
+		restrict string
+	}{
+		{provider: "postgres", attach: "ON CONFLICT (product_id, tag_id) DO UPDATE SET position = excluded.position", restrict: `r.restrict(tx, "SELECT COUNT(*) FROM products WHERE category_id = ?"`},
+		{provider: "mysql", layer: models.DataLayerSQLX, attach: "ON DUPLICATE KEY UPDATE position = VALUES(position)", restrict: `r.restrict(ctx, "SELECT COUNT(*) FROM products WHERE category_id = ?"`},
+		{provider: "postgres", layer: models.DataLayerPGX, attach: "VALUES ($1, $2, $3, $4)", restrict: `r.restrict(ctx, "SELECT COUNT(*) FROM products WHERE category_id = $1"`},
+		{provider: "mongodb", attach: "options.Update().SetUpsert(true)", restrict: `r.restrict(ctx, "products", bson.M{"categoryid": id}, "products")`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider+"/"+string(tt.layer), func(t *testing.T) {
+			gen := NewSchemaGenerator(newMemorySchemaStorage(category, tag, product))
+			if tt.layer != "" {
+				gen = gen.WithDataLayer(tt.layer)
+			}
+			dir := generateTestProject(t, gen, tt.provider, tag, product, category)
+
+			var model []string
+			if tt.layer == "" && tt.provider != "mongodb" {
+				model = append(model, "Category *Category `json:\"category\" gorm:\"-\"`")
+			}
+
+			assertGeneratedFiles(t, dir,
+				generatedFile{path: "internal/models/relations.go"},
+				generatedFile{path: "internal/models/product_relations.go"},
+				generatedFile{path: "internal/services/product_relation_service.go"},
+				generatedFile{path: "internal/handlers/product_relation_handler_test.go"},
+				generatedFile{path: "internal/services/category_relation_service.go"},
+				// One-to-many relations have no pivot
+				generatedFile{path: "internal/models/category_relations.go", missing: true},
+				generatedFile{
+					path:     "internal/repositories/product_relation_repository.go",
+					contains: []string{tt.attach, "AttachTags(ctx context.Context, id, tagID string, pivot *models.ProductTagsPivot) error"},
+				},
+				generatedFile{path: "internal/repositories/category_relation_repository.go", contains: []string{tt.restrict}},
+				generatedFile{
+					path:     "internal/handlers/product_relation_handler.go",
+					contains: []string{`r.PUT("/products/:id/tags/:tag_id", handler.AttachTags)`},
+				},
+				generatedFile{
+					path:     "migrations/*_create_product_tags.sql",
+					contains: []string{"PRIMARY KEY (product_id, tag_id)"},
+					missing:  tt.provider == "mongodb",
+				},
+				// Associations are served by the relation routes, not stored as columns
+				generatedFile{
+					path:     "internal/models/product.go",
+					contains: model,
+					excludes: []string{"many2many", "foreignKey"},
+				},
+			)
+
+			assertGoFilesParse(t, filepath.Join(dir, "internal"))
+		})
+	}
+}
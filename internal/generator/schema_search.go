@@ -0,0 +1,355 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/vibercode/cli/internal/models"
+	"github.com/vibercode/cli/internal/templates"
+	"github.com/vibercode/cli/pkg/ui"
+)
+
+// SearchField is a field matched by full-text search
+type SearchField struct {
+	Name   string
+	GoName string
+	// Column is the table column, or the document key with MongoDB
+	Column string
+	Weight string
+	// Highlight is the result column of the field snippet
+	Highlight      string
+	HighlightField string
+}
+
+// SearchTemplateData contains the template data for the search feature
+type SearchTemplateData struct {
+	*EnhancedSchema
+	// Backend is the search implementation: gorm, sqlx, pgx, sqlc or mongo
+	Backend  string
+	Engine   string
+	Language string
+	Fields   []SearchField
+	// RowType is the row embedded by search rows, RowField its field name
+	RowType    string
+	RowField   string
+	Query      string
+	CountQuery string
+	// MatchArg is the expression of the query argument
+	MatchArg string
+}
+
+// Tag returns the struct tag mapping a search row field onto a result column
+func (d *SearchTemplateData) Tag(column string) string {
+	if d.Backend == "gorm" {
+		return fmt.Sprintf(`gorm:"column:%s"`, column)
+	}
+	return fmt.Sprintf(`db:"%s"`, column)
+}
+
+// MongoWeight returns the MongoDB text index weight of the field
+func (f SearchField) MongoWeight() int {
+	return int(searchWeightValue(f.Weight)*10 + 0.5)
+}
+
+// generateSearchFeature generates the full-text search index, repository, service and handler of a schema
+func (g *SchemaGenerator) generateSearchFeature(data *EnhancedSchema, outputPath string) error {
+	if data.DBProvider == "mysql" {
+		ui.PrintWarning("Full-text search supports PostgreSQL, SQLite and MongoDB, skipping for " + data.Name)
+		return nil
+	}
+
+	searchData := g.prepareSearchData(data)
+	if len(searchData.Fields) == 0 {
+		ui.PrintWarning("Full-text search requires string or text fields, skipping for " + data.Name)
+		return nil
+	}
+
+	repositoryTemplates := map[string]string{
+		"gorm":  templates.SchemaSearchGORMRepositoryTemplate,
+		"sqlx":  templates.SchemaSearchSQLXRepositoryTemplate,
+		"pgx":   templates.SchemaSearchPGXRepositoryTemplate,
+		"sqlc":  templates.SchemaSearchPGXRepositoryTemplate,
+		"mongo": templates.SchemaSearchMongoRepositoryTemplate,
+	}
+
+	snake := data.Names.SnakeCase
+	files := []struct {
+		template string
+		path     string
+	}{
+		{templates.SearchPackageTemplate, filepath.Join("internal", "search", "search.go")},
+		{templates.SearchTestTemplate, filepath.Join("internal", "search", "search_test.go")},
+		{repositoryTemplates[searchData.Backend], filepath.Join("internal", "repositories", snake+"_search_repository.go")},
+		{templates.SchemaSearchServiceTemplate, filepath.Join("internal", "services", snake+"_search_service.go")},
+		{templates.SchemaSearchHandlerTemplate, filepath.Join("internal", "handlers", snake+"_search_handler.go")},
+	}
+
+	for _, file := range files {
+		if err := g.generateGoFile(file.template, searchData, filepath.Join(outputPath, file.path)); err != nil {
+			return err
+		}
+	}
+
+	switch data.DBProvider {
+	case "mongodb":
+		ui.PrintInfo("Call " + data.Names.PascalCase + "SearchRepository.EnsureIndexes at startup to create the text index")
+	case "sqlite":
+		up, down := sqliteSearchMigration(searchData)
+		if err := g.writeSQLMigration(outputPath, "add_"+data.Names.TableName+"_search", "Add the full-text search table of "+data.Names.TableName, up, down); err != nil {
+			return fmt.Errorf("failed to generate search migration: %w", err)
+		}
+		ui.PrintInfo("SQLite full-text search uses FTS5, build with '-tags sqlite_fts5' for github.com/mattn/go-sqlite3")
+	default:
+		up, down := postgresSearchMigration(searchData)
+		if err := g.writeSQLMigration(outputPath, "add_"+data.Names.TableName+"_search", "Add the full-text search vector of "+data.Names.TableName, up, down); err != nil {
+			return fmt.Errorf("failed to generate search migration: %w", err)
+		}
+	}
+	if data.HTTP.Is("fiber") {
+		ui.PrintInfo("Fiber matches routes in order, call Setup" + data.Names.PascalCase + "SearchRoutes before Setup" + data.Names.PascalCase + "Routes")
+	}
+	data.Endpoints = append(data.Endpoints, models.FeatureSearch)
+	return nil
+}
+
+// prepareSearchData collects the searchable fields and builds the search queries of the backend
+func (g *SchemaGenerator) prepareSearchData(data *EnhancedSchema) *SearchTemplateData {
+	searchData := &SearchTemplateData{
+		EnhancedSchema: data,
+		Language:       data.GetSearchConfig().Language,
+	}
+
+	candidates := data.ResourceSchema.GetSearchFields()
+	if len(candidates) == 0 {
+		// Without marked fields every text field is searched, as the list search does
+		for _, field := range data.ResourceSchema.Fields {
+			if field.Type == "string" || field.Type == "text" {
+				candidates = append(candidates, field)
+			}
+		}
+	}
+
+	for i := range candidates {
+		field := &candidates[i]
+		if field.GetGoType() != "string" || field.IsSensitive() || isRelationField(field) {
+			ui.PrintWarning("Field " + field.Name + " of " + data.Name + " can't be searched, ignoring")
+			continue
+		}
+		column := columnName(field)
+		if data.DBProvider == "mongodb" {
+			// Models have no bson tags, the driver stores fields under their lower case name
+			column = strings.ToLower(toPascalCase(field.Name))
+		}
+		searchData.Fields = append(searchData.Fields, SearchField{
+			Name:           toSnakeCase(field.Name),
+			GoName:         toPascalCase(field.Name),
+			Column:         column,
+			Weight:         field.SearchWeight(),
+			Highlight:      column + "_highlight",
+			HighlightField: toPascalCase(column) + "Highlight",
+		})
+	}
+
+	switch {
+	case data.DBProvider == "mongodb":
+		searchData.Backend = "mongo"
+		return searchData
+	case data.DataLayer == models.DataLayerGORM:
+		searchData.Backend = "gorm"
+		searchData.RowType = "models." + data.Names.PascalCase
+		searchData.RowField = data.Names.PascalCase
+	default:
+		searchData.Backend = string(data.DataLayer)
+		searchData.RowType = g.prepareDataLayerData(data).RowType
+		searchData.RowField = searchData.RowType[strings.LastIndex(searchData.RowType, ".")+1:]
+	}
+
+	// Every query takes the search text as its single argument
+	placeholder := "?"
+	if searchData.Backend == "pgx" || searchData.Backend == "sqlc" {
+		placeholder = "$1"
+	}
+	columns := data.Names.TableName + ".*"
+	if searchData.Backend != "gorm" {
+		columns = sqlQualifiedColumns(g.prepareDataLayerData(data))
+	}
+
+	if data.DBProvider == "sqlite" {
+		searchData.Engine = "SQLite FTS5"
+		searchData.MatchArg = "search.MatchExpression(query)"
+		searchData.Query, searchData.CountQuery = sqliteSearchQueries(searchData, columns, placeholder)
+	} else {
+		searchData.Engine = "PostgreSQL full-text search"
+		searchData.MatchArg = "query"
+		searchData.Query, searchData.CountQuery = postgresSearchQueries(searchData, columns, placeholder)
+	}
+	return searchData
+}
+
+// sqlQualifiedColumns returns the columns of the resource table qualified by the table name
+func sqlQualifiedColumns(data *DataLayerTemplateData) string {
+	columns := append([]string{"id", "created_at", "updated_at"}, data.columnNames()...)
+	for i, column := range columns {
+		columns[i] = data.Table + "." + column
+	}
+	return strings.Join(columns, ", ")
+}
+
+// searchWeightValue returns the relevance of a search weight, the PostgreSQL ts_rank defaults
+func searchWeightValue(weight string) float64 {
+	switch weight {
+	case models.SearchWeightA:
+		return 1.0
+	case models.SearchWeightB:
+		return 0.4
+	case models.SearchWeightC:
+		return 0.2
+	default:
+		return 0.1
+	}
+}
+
+// searchSnippetMarks returns the SQL expressions of the highlight delimiters, the private use
+// characters of the generated search package
+func searchSnippetMarks(function string) (string, string) {
+	return function + "(57344)", function + "(57345)"
+}
+
+// postgresSearchQueries returns the ranked search and count queries over the tsvector column
+func postgresSearchQueries(data *SearchTemplateData, columns, placeholder string) (string, string) {
+	table := data.Names.TableName
+	start, stop := searchSnippetMarks("chr")
+	options := "'StartSel=' || " + start + " || ', StopSel=' || " + stop + " || ', MaxWords=35, MinWords=15'"
+
+	selects := []string{columns, fmt.Sprintf("ts_rank_cd(%s.search_vector, search_query) AS search_rank", table)}
+	for _, field := range data.Fields {
+		selects = append(selects, fmt.Sprintf("ts_headline('%s', coalesce(%s.%s, ''), search_query, %s) AS %s",
+			data.Language, table, field.Column, options, field.Highlight))
+	}
+
+	query := fmt.Sprintf(`SELECT %s
+FROM %s, websearch_to_tsquery('%s', %s) AS search_query
+WHERE %s.search_vector @@ search_query
+ORDER BY search_rank DESC, %s.created_at DESC`,
+		strings.Join(selects, ",\n    "), table, data.Language, placeholder, table, table)
+	count := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE search_vector @@ websearch_to_tsquery('%s', %s)",
+		table, data.Language, placeholder)
+	return query, count
+}
+
+// sqliteSearchQueries returns the ranked search and count queries over the FTS5 table
+func sqliteSearchQueries(data *SearchTemplateData, columns, placeholder string) (string, string) {
+	table := data.Names.TableName
+	fts := table + "_fts"
+	start, stop := searchSnippetMarks("char")
+
+	// The first FTS5 column is the unindexed id
+	weights := []string{"0"}
+	for _, field := range data.Fields {
+		weights = append(weights, fmt.Sprint(searchWeightValue(field.Weight)))
+	}
+	selects := []string{columns, fmt.Sprintf("-bm25(%s, %s) AS search_rank", fts, strings.Join(weights, ", "))}
+	for i, field := range data.Fields {
+		selects = append(selects, fmt.Sprintf("coalesce(snippet(%s, %d, %s, %s, '…', 16), '') AS %s",
+			fts, i+1, start, stop, field.Highlight))
+	}
+
+	query := fmt.Sprintf(`SELECT %s
+FROM %s
+JOIN %s ON %s.id = %s.id
+WHERE %s MATCH %s
+ORDER BY search_rank DESC, %s.created_at DESC`,
+		strings.Join(selects, ",\n    "), fts, table, table, fts, fts, placeholder, table)
+	count := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s MATCH %s", fts, fts, placeholder)
+	return query, count
+}
+
+// postgresSearchMigration returns the statements maintaining the weighted tsvector column,
+// its GIN index and the trigger updating it
+func postgresSearchMigration(data *SearchTemplateData) (string, string) {
+	table := data.Names.TableName
+	vector := func(row string) string {
+		var parts []string
+		for _, field := range data.Fields {
+			parts = append(parts, fmt.Sprintf("setweight(to_tsvector('%s', coalesce(%s%s, '')), '%s')",
+				data.Language, row, field.Column, field.Weight))
+		}
+		return strings.Join(parts, " ||\n        ")
+	}
+
+	up := fmt.Sprintf(`ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+CREATE OR REPLACE FUNCTION %[1]s_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        %[2]s;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS %[1]s_search_vector_trigger ON %[1]s;
+CREATE TRIGGER %[1]s_search_vector_trigger
+    BEFORE INSERT OR UPDATE ON %[1]s
+    FOR EACH ROW EXECUTE FUNCTION %[1]s_search_vector_update();
+
+UPDATE %[1]s SET search_vector =
+        %[3]s;
+
+CREATE INDEX IF NOT EXISTS idx_%[1]s_search_vector ON %[1]s USING GIN (search_vector);`, table, vector("NEW."), vector(""))
+
+	down := fmt.Sprintf(`DROP INDEX IF EXISTS idx_%[1]s_search_vector;
+DROP TRIGGER IF EXISTS %[1]s_search_vector_trigger ON %[1]s;
+DROP FUNCTION IF EXISTS %[1]s_search_vector_update();
+ALTER TABLE %[1]s DROP COLUMN IF EXISTS search_vector;`, table)
+	return up, down
+}
+
+// sqliteSearchMigration returns the statements creating the FTS5 table and the triggers
+// keeping it in sync. The table keeps the id of the rows, which SQLite may renumber.
+func sqliteSearchMigration(data *SearchTemplateData) (string, string) {
+	table := data.Names.TableName
+	fts := table + "_fts"
+
+	columns := []string{"id"}
+	for _, field := range data.Fields {
+		columns = append(columns, field.Column)
+	}
+	values := func(row string) string {
+		prefixed := make([]string, len(columns))
+		for i, column := range columns {
+			prefixed[i] = row + column
+		}
+		return strings.Join(prefixed, ", ")
+	}
+
+	tokenizer := "unicode61 remove_diacritics 2"
+	if data.Language == models.DefaultSearchLanguage {
+		tokenizer = "porter unicode61"
+	}
+	insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s);", fts, values(""), values("new."))
+
+	up := fmt.Sprintf(`CREATE VIRTUAL TABLE IF NOT EXISTS %[1]s USING fts5(id UNINDEXED, %[3]s, tokenize='%[4]s');
+
+CREATE TRIGGER IF NOT EXISTS %[1]s_insert AFTER INSERT ON %[2]s BEGIN
+    %[5]s
+END;
+
+CREATE TRIGGER IF NOT EXISTS %[1]s_update AFTER UPDATE ON %[2]s BEGIN
+    DELETE FROM %[1]s WHERE id = old.id;
+    %[5]s
+END;
+
+CREATE TRIGGER IF NOT EXISTS %[1]s_delete AFTER DELETE ON %[2]s BEGIN
+    DELETE FROM %[1]s WHERE id = old.id;
+END;
+
+INSERT INTO %[1]s (%[6]s) SELECT %[6]s FROM %[2]s;`,
+		fts, table, strings.Join(columns[1:], ", "), tokenizer, insert, values(""))
+
+	down := fmt.Sprintf(`DROP TRIGGER IF EXISTS %[1]s_insert;
+DROP TRIGGER IF EXISTS %[1]s_update;
+DROP TRIGGER IF EXISTS %[1]s_delete;
+DROP TABLE IF EXISTS %[1]s;`, fts)
+	return up, down
+}
@@ -0,0 +1,107 @@
+package generator
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/vibercode/cli/internal/models"
+)
+
+func TestSchemaGenerator_SearchFeature(t *testing.T) {
+	schema := newTestProductSchema()
+	schema.Fields[1].Search = &models.SearchFieldConfig{Weight: "a"}
+	schema.Fields = append(schema.Fields, models.SchemaField{Name: "secret", Type: "password", DisplayName: "Secret"})
+	schema.Indexes = []models.IndexConfig{{Name: "idx_products_search", Type: "gin", Fields: []string{"description", "secret"}}}
+	schema.Options = &models.GenerationOptions{Features: []string{models.FeatureSearch}}
+
+	dir := generateTestProject(t, NewSchemaGenerator(newMemorySchemaStorage(schema)), "postgres", schema)
+
+	assertGeneratedFiles(t, dir,
+		generatedFile{path: "internal/search/search.go"},
+		generatedFile{path: "internal/search/search_test.go"},
+		generatedFile{
+			path: "internal/repositories/product_search_repository.go",
+			contains: []string{
+				"func NewProductSearchRepository(db *gorm.DB) *ProductSearchRepository {",
+				"FROM products, websearch_to_tsquery('english', ?) AS search_query",
+				"ts_headline('english', coalesce(products.name, ''), search_query",
+			},
+			// sensitive fields must not be searchable
+			excludes: []string{"secret"},
+		},
+		generatedFile{path: "internal/services/product_search_service.go"},
+		generatedFile{
+			path:     "internal/handlers/product_search_handler.go",
+			contains: []string{`c.Query("q")`, `"/products/search"`},
+		},
+		generatedFile{
+			path: "migrations/*_add_products_search.sql",
+			contains: []string{
+				"setweight(to_tsvector('english', coalesce(NEW.name, '')), 'A') ||",
+				"setweight(to_tsvector('english', coalesce(NEW.description, '')), 'D')",
+				"CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (search_vector);",
+			},
+		},
+	)
+
+	assertGoFilesParse(t, filepath.Join(dir, "internal"))
+}
+
+func TestSchemaGenerator_SearchFeatureBackends(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		layer    models.DataLayer
+		file     generatedFile
+	}{
+		{
+			name:     "sqlite sqlx",
+			provider: "sqlite",
+			layer:    models.DataLayerSQLX,
+			file: generatedFile{
+				path:     "internal/repositories/product_search_repository.go",
+				contains: []string{"match := search.MatchExpression(query)", "-bm25(products_fts, 0, 0.1, 0.1, 0.1) AS search_rank", "productRow"},
+			},
+		},
+		{
+			name:     "sqlite migration",
+			provider: "sqlite",
+			layer:    models.DataLayerSQLX,
+			file: generatedFile{
+				path:     "migrations/*_add_products_search.sql",
+				contains: []string{"USING fts5(id UNINDEXED, sku, name, description, tokenize='porter unicode61')", "DELETE FROM products_fts WHERE id = old.id;"},
+			},
+		},
+		{
+			name:     "postgres pgx",
+			provider: "postgres",
+			layer:    models.DataLayerPGX,
+			file: generatedFile{
+				path:     "internal/repositories/product_search_repository.go",
+				contains: []string{"websearch_to_tsquery('english', $1)", "pgx.RowToStructByName[productSearchRow]"},
+			},
+		},
+		{
+			name:     "mongodb",
+			provider: "mongodb",
+			layer:    models.DataLayerGORM,
+			file: generatedFile{
+				path:     "internal/repositories/product_search_repository.go",
+				contains: []string{`{Key: "name", Value: "text"}`, `{Key: "description", Value: 1}`, "search.Mark(product.Name, terms, productSearchSnippetWords)"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := newTestProductSchema()
+			gen := NewSchemaGenerator(newMemorySchemaStorage(schema)).
+				WithFeatures(models.FeatureSearch).
+				WithDataLayer(tt.layer)
+			dir := generateTestProject(t, gen, tt.provider, schema)
+
+			assertGeneratedFiles(t, dir, tt.file)
+			assertGoFilesParse(t, filepath.Join(dir, "internal"))
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package generator
+
+import (
+	"path/filepath"
+
+	"github.com/vibercode/cli/internal/models"
+	"github.com/vibercode/cli/internal/templates"
+	"github.com/vibercode/cli/pkg/ui"
+)
+
+// UploadTemplateData contains the template data for the uploads of file and image fields
+type UploadTemplateData struct {
+	*EnhancedSchema
+	Uploads []UploadField
+}
+
+// UploadField is a file or image field stored in blob storage
+type UploadField struct {
+	Name         string
+	GoName       string
+	Route        string
+	MaxSize      int64
+	AllowedTypes []string
+	Thumbnail    *models.ThumbnailConfig
+}
+
+// thumbnailTypes lists the image types thumbnails can be created for
+var thumbnailTypes = map[string]bool{"image/jpeg": true, "image/png": true, "image/gif": true}
+
+// generateUploads generates the blob storage package and the upload endpoints of the file
+// and image fields of a schema, if it has any
+func (g *SchemaGenerator) generateUploads(data *EnhancedSchema, outputPath string) error {
+	uploadData := prepareUploadData(data)
+	if len(uploadData.Uploads) == 0 {
+		return nil
+	}
+	snake := data.Names.SnakeCase
+
+	files := []struct {
+		template string
+		path     string
+	}{
+		{templates.BlobPackageTemplate, filepath.Join("internal", "blob", "blob.go")},
+		{templates.BlobLocalTemplate, filepath.Join("internal", "blob", "local.go")},
+		{templates.BlobS3Template, filepath.Join("internal", "blob", "s3.go")},
+		{templates.BlobThumbnailTemplate, filepath.Join("internal", "blob", "thumbnail.go")},
+		{templates.BlobTestTemplate, filepath.Join("internal", "blob", "blob_test.go")},
+		{templates.SchemaUploadModelTemplate, filepath.Join("internal", "models", snake+"_uploads.go")},
+		{templates.SchemaBlobRepositoryTemplate, filepath.Join("internal", "repositories", snake+"_blob_repository.go")},
+		{templates.SchemaUploadServiceTemplate, filepath.Join("internal", "services", snake+"_upload_service.go")},
+		{templates.SchemaUploadServiceTestTemplate, filepath.Join("internal", "services", snake+"_upload_service_test.go")},
+		{templates.SchemaUploadHandlerTemplate, filepath.Join("internal", "handlers", snake+"_upload_handler.go")},
+	}
+
+	for _, file := range files {
+		if err := g.generateGoFile(file.template, uploadData, filepath.Join(outputPath, file.path)); err != nil {
+			return err
+		}
+	}
+
+	if data.HTTP.Is(string(models.HTTPFiber)) {
+		ui.PrintInfo("Fiber rejects request bodies above 4MB by default, raise fiber.Config.BodyLimit for larger uploads")
+	}
+	ui.PrintInfo("Uploads are stored on local disk or in S3 following STORAGE_PROVIDER, STORAGE_LOCAL_PATH and the S3_* variables")
+	ui.PrintInfo("Wire New" + data.Names.PascalCase + "Service(repositories.New" + data.Names.PascalCase + "BlobRepository(repo, store)) so deletes remove uploaded files, and Setup" +
+		data.Names.PascalCase + "UploadRoutes with services.New" + data.Names.PascalCase + "UploadService(repo, store, config) from blob.FromEnv()")
+	return nil
+}
+
+// prepareUploadData collects the file and image fields of a schema with their upload rules
+func prepareUploadData(data *EnhancedSchema) *UploadTemplateData {
+	uploadData := &UploadTemplateData{EnhancedSchema: data}
+
+	for _, field := range data.ResourceSchema.GetUploadFields() {
+		config := field.GetUploadConfig()
+		if config.Thumbnail != nil {
+			for _, contentType := range config.AllowedTypes {
+				if !thumbnailTypes[contentType] {
+					ui.PrintWarning("Thumbnails are created for JPEG, PNG and GIF images, " + field.Name + " uploads of type " + contentType + " will be rejected")
+					break
+				}
+			}
+		}
+
+		uploadData.Uploads = append(uploadData.Uploads, UploadField{
+			Name:         toSnakeCase(field.Name),
+			GoName:       toPascalCase(field.Name),
+			Route:        toKebabCase(field.Name),
+			MaxSize:      config.MaxSize,
+			AllowedTypes: config.AllowedTypes,
+			Thumbnail:    config.Thumbnail,
+		})
+	}
+	return uploadData
+}
@@ -0,0 +1,65 @@
+package generator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vibercode/cli/internal/models"
+)
+
+func TestSchemaGenerator_UploadFields(t *testing.T) {
+	schema := newTestProductSchema()
+	schema.Fields = append(schema.Fields,
+		models.SchemaField{Name: "cover_image", Type: models.FieldTypeImageUpload, DisplayName: "Cover Image",
+			Upload: &models.UploadFieldConfig{Thumbnail: &models.ThumbnailConfig{Width: 64, Height: 64}}},
+		models.SchemaField{Name: "manual", Type: models.FieldTypeFileUpload, DisplayName: "Manual",
+			Upload: &models.UploadFieldConfig{MaxSize: 1 << 20, AllowedTypes: []string{"application/pdf", "text/*"}}},
+	)
+
+	dir := generateTestProject(t, NewSchemaGenerator(newMemorySchemaStorage(schema)), "postgres", schema)
+
+	assertGeneratedFiles(t, dir,
+		generatedFile{path: "internal/blob/blob.go"},
+		generatedFile{path: "internal/blob/local.go"},
+		generatedFile{path: "internal/blob/s3.go"},
+		generatedFile{path: "internal/blob/thumbnail.go"},
+		generatedFile{path: "internal/blob/blob_test.go"},
+		generatedFile{
+			path: "internal/models/product_uploads.go",
+			contains: []string{
+				`Types: []string{"image/jpeg", "image/png", "image/gif"}`,
+				"ThumbnailWidth:  64,",
+				`Rules: blob.Rules{MaxSize: 1048576, Types: []string{"application/pdf", "text/*"}},`,
+			},
+		},
+		generatedFile{path: "internal/repositories/product_blob_repository.go"},
+		generatedFile{path: "internal/services/product_upload_service.go"},
+		generatedFile{path: "internal/services/product_upload_service_test.go"},
+		generatedFile{
+			path: "internal/handlers/product_upload_handler.go",
+			contains: []string{
+				`"/products/:id/cover-image", handler.Upload("cover_image")`,
+				`"/products/:id/manual", handler.Remove("manual")`,
+			},
+		},
+	)
+
+	model := readGeneratedFile(t, dir, "internal/models/product.go")
+	_, request, found := strings.Cut(model, "type ProductRequest struct")
+	require.True(t, found)
+	request, _, _ = strings.Cut(request, "}")
+	assert.NotContains(t, request, "CoverImage", "upload fields are written through their upload endpoints")
+
+	assertGoFilesParse(t, filepath.Join(dir, "internal"))
+}
+
+func TestSchemaGenerator_NoUploadFields(t *testing.T) {
+	schema := newTestProductSchema()
+	dir := generateTestProject(t, NewSchemaGenerator(newMemorySchemaStorage(schema)), "postgres", schema)
+
+	assert.NoDirExists(t, filepath.Join(dir, "internal", "blob"))
+	assertGeneratedFiles(t, dir, generatedFile{path: "internal/handlers/product_upload_handler.go", missing: true})
+}
@@ -0,0 +1,1046 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vibercode/cli/internal/models"
+	"github.com/vibercode/cli/internal/templates"
+	"github.com/vibercode/cli/pkg/ui"
+)
+
+// apiVersionPattern matches API version names
+var apiVersionPattern = regexp.MustCompile(`^v[1-9][0-9]*$`)
+
+// Endpoints recorded for the OpenAPI documents besides the bulk, export and search features:
+// the batch upsert of schemas with a natural key and the import of schemas enabling it
+const (
+	endpointBulkUpsert = "bulk_upsert"
+	endpointImport     = "import"
+)
+
+// WithAPIVersion generates schemas as the given API version (v1, v2, ...). Without it a
+// resource keeps the latest version it was generated as, new resources join the latest
+// version of the project.
+func (g *SchemaGenerator) WithAPIVersion(version string) *SchemaGenerator {
+	g.apiVersion = version
+	return g
+}
+
+// WithDeprecation deprecates an older API version, sunset is the date (YYYY-MM-DD) it stops
+// being served and may be empty
+func (g *SchemaGenerator) WithDeprecation(version, sunset string) *SchemaGenerator {
+	if g.deprecations == nil {
+		g.deprecations = make(map[string]string)
+	}
+	g.deprecations[version] = sunset
+	return g
+}
+
+// apiVersionPlan is the API version a schema is generated as and the manifest recording it
+type apiVersionPlan struct {
+	Version  string
+	Manifest *VibercodeManifest
+	// Older lists the earlier versions of the resource, still served from their snapshot
+	Older []string
+}
+
+// VersionTemplateData contains the template data of the DTOs and handler of an older API
+// version of a resource, mapped from the shared model
+type VersionTemplateData struct {
+	*EnhancedSchema
+	Version string // v1
+	Suffix  string // V1
+	// Contract is the schema the version was generated from
+	Contract *EnhancedSchema
+	// RequestFields and ResponseFields tell which fields of the contract map onto the current
+	// request and model, they exist with the same Go type
+	RequestFields  map[string]bool
+	ResponseFields map[string]bool
+}
+
+// APIVersionsTemplateData contains the template data of the version table of the handlers
+type APIVersionsTemplateData struct {
+	Versions []APIVersionInfo
+}
+
+// APIVersionInfo is the lifecycle of an API version
+type APIVersionInfo struct {
+	Version    string
+	Deprecated int64 // Unix time, 0 while supported
+	Sunset     int64 // Unix time, 0 without a planned removal
+	Successor  string
+}
+
+// planAPIVersion resolves the API version a schema is generated as and applies the
+// deprecations, before any file is written
+func (g *SchemaGenerator) planAPIVersion(schema *models.ResourceSchema, outputPath, module string) (*apiVersionPlan, error) {
+	manifest, err := LoadManifest(outputPath)
+	if os.IsNotExist(err) {
+		now := time.Now().Format(time.RFC3339)
+		manifest = &VibercodeManifest{
+			Version:     "1.0.0",
+			ProjectType: "api",
+			Name:        filepath.Base(outputPath),
+			Module:      module,
+			GeneratedAt: now,
+			CLI:         VibercodeManifestCLI{Version: "1.0.0", Command: "vibercode schema generate"},
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	latest := ""
+	for _, version := range manifest.APIVersions {
+		if version.resource(schema.Name) != nil {
+			latest = version.Version
+		}
+	}
+
+	version := g.apiVersion
+	switch {
+	case version == "" && latest != "":
+		version = latest
+	case version == "" && len(manifest.APIVersions) > 0:
+		version = manifest.APIVersions[len(manifest.APIVersions)-1].Version
+	case version == "":
+		version = "v1"
+	case !apiVersionPattern.MatchString(version):
+		return nil, fmt.Errorf("invalid API version %q, expected v1, v2, ...", version)
+	case latest != "" && apiVersionNumber(version) < apiVersionNumber(latest):
+		return nil, fmt.Errorf("%s is served as %s, the contract of %s can't be changed anymore", schema.Name, latest, version)
+	}
+
+	plan := &apiVersionPlan{Version: version, Manifest: manifest}
+	for _, recorded := range manifest.APIVersions {
+		if apiVersionNumber(recorded.Version) < apiVersionNumber(version) && recorded.resource(schema.Name) != nil {
+			plan.Older = append(plan.Older, recorded.Version)
+		}
+	}
+
+	entry := manifest.apiVersion(version)
+	if entry.Deprecated != "" {
+		return nil, fmt.Errorf("%s is deprecated, generate %s as a newer version", version, schema.Name)
+	}
+
+	var deprecated []string
+	for name := range g.deprecations {
+		deprecated = append(deprecated, name)
+	}
+	sort.Strings(deprecated)
+	for _, name := range deprecated {
+		sunset := g.deprecations[name]
+		target := manifest.findAPIVersion(name)
+		if target == nil {
+			return nil, fmt.Errorf("can't deprecate %s, the project has no such API version", name)
+		}
+		if apiVersionNumber(name) >= apiVersionNumber(manifest.APIVersions[len(manifest.APIVersions)-1].Version) {
+			return nil, fmt.Errorf("can't deprecate %s, it is the latest API version", name)
+		}
+		if sunset != "" {
+			if _, err := time.Parse("2006-01-02", sunset); err != nil {
+				return nil, fmt.Errorf("invalid sunset date %q of %s, expected YYYY-MM-DD", sunset, name)
+			}
+			target.Sunset = sunset
+		}
+		if target.Deprecated == "" {
+			target.Deprecated = time.Now().UTC().Format(time.RFC3339)
+			manifest.recordEvent("deprecate_api_version", fmt.Sprintf("Deprecated API %s", name))
+		}
+	}
+
+	return plan, nil
+}
+
+// generateAPIVersions records the schema as its API version and generates the DTOs and
+// handlers of its older versions, the version table of the handlers and the OpenAPI
+// document of every version
+func (g *SchemaGenerator) generateAPIVersions(data *EnhancedSchema, outputPath string, plan *apiVersionPlan) error {
+	manifest := plan.Manifest
+	snapshot := filepath.Join("api", plan.Version, data.Names.SnakeCase+".json")
+	content, err := data.ResourceSchema.ToJSON()
+	if err != nil {
+		return fmt.Errorf("failed to snapshot schema: %w", err)
+	}
+	if err := g.writeGeneratedFile(filepath.Join(outputPath, ".vibercode", snapshot), append(content, '\n')); err != nil {
+		return err
+	}
+
+	entry := manifest.apiVersion(plan.Version)
+	resource := entry.resource(data.Name)
+	if resource == nil {
+		entry.Resources = append(entry.Resources, VibercodeAPIVersionResource{Name: data.Name})
+		resource = &entry.Resources[len(entry.Resources)-1]
+		manifest.recordEvent("api_version", fmt.Sprintf("Generated %s as API %s", data.Name, plan.Version))
+	}
+	resource.SchemaID = data.ID
+	resource.SchemaVersion = data.ResourceSchema.Version
+	resource.SchemaUpdatedAt = ""
+	if !data.UpdatedAt.IsZero() {
+		resource.SchemaUpdatedAt = data.UpdatedAt.Format(time.RFC3339)
+	}
+	resource.Snapshot = filepath.ToSlash(snapshot)
+	resource.GeneratedAt = time.Now().Format(time.RFC3339)
+	resource.Endpoints = data.Endpoints
+	resource.Relations, resource.Includes = nil, nil
+	for _, relation := range data.RelationRoutes {
+		resource.Relations = append(resource.Relations, relation.Name)
+	}
+	for _, include := range data.Includes {
+		resource.Includes = append(resource.Includes, include.Key)
+	}
+	manifest.UpdatedAt = resource.GeneratedAt
+	if err := SaveManifest(outputPath, manifest); err != nil {
+		return err
+	}
+
+	for _, version := range plan.Older {
+		if err := g.generateOlderAPIVersion(data, outputPath, version); err != nil {
+			return err
+		}
+	}
+	if len(manifest.APIVersions) > 1 {
+		versionsPath := filepath.Join(outputPath, "internal", "handlers", "api_versions.go")
+		if err := g.generateGoFile(templates.APIVersionsTemplate, newAPIVersionsTemplateData(manifest), versionsPath); err != nil {
+			return err
+		}
+	}
+
+	for i := range manifest.APIVersions {
+		if err := g.writeOpenAPIDocument(manifest, &manifest.APIVersions[i], outputPath); err != nil {
+			return err
+		}
+	}
+
+	if len(plan.Older) > 0 {
+		mounts := make([]string, len(plan.Older))
+		for i, version := range plan.Older {
+			mounts[i] = fmt.Sprintf("Setup%s%sRoutes under /api/%s", data.Names.PascalCase, strings.ToUpper(version), version)
+		}
+		ui.PrintInfo(fmt.Sprintf("Mount Setup%sRoutes under /api/%s and %s", data.Names.PascalCase, plan.Version, strings.Join(mounts, ", ")))
+	}
+	return nil
+}
+
+// generateOlderAPIVersion generates the DTOs and handler serving the contract an older API
+// version of a resource was generated from
+func (g *SchemaGenerator) generateOlderAPIVersion(data *EnhancedSchema, outputPath, version string) error {
+	snapshot, err := loadSchemaSnapshot(outputPath, version, data.Names.SnakeCase)
+	if err != nil {
+		return err
+	}
+
+	contract := g.prepareTemplateData(snapshot, data.Module, data.DBProvider)
+	contract.Names = data.Names
+	versionData := &VersionTemplateData{
+		EnhancedSchema: data,
+		Version:        version,
+		Suffix:         strings.ToUpper(version),
+		Contract:       contract,
+		RequestFields:  make(map[string]bool),
+		ResponseFields: make(map[string]bool),
+	}
+	for _, field := range contract.Fields {
+		for _, current := range data.Fields {
+			if current.Names.PascalCase != field.Names.PascalCase || current.GoType != field.GoType {
+				continue
+			}
+			versionData.ResponseFields[field.Name] = true
+			versionData.RequestFields[field.Name] = !current.ReadOnly && !field.ReadOnly
+		}
+	}
+
+	base := data.Names.SnakeCase + "_" + version
+	if err := g.generateGoFile(templates.SchemaVersionModelTemplate, versionData, filepath.Join(outputPath, "internal", "models", base+".go")); err != nil {
+		return err
+	}
+	return g.generateHandlerFile(templates.SchemaVersionHandlerTemplate, versionData, filepath.Join(outputPath, "internal", "handlers", base+"_handler.go"))
+}
+
+// newAPIVersionsTemplateData lists the lifecycle of the API versions of a project
+func newAPIVersionsTemplateData(manifest *VibercodeManifest) *APIVersionsTemplateData {
+	data := &APIVersionsTemplateData{}
+	for i, version := range manifest.APIVersions {
+		info := APIVersionInfo{Version: version.Version}
+		if deprecated, err := time.Parse(time.RFC3339, version.Deprecated); err == nil {
+			info.Deprecated = deprecated.Unix()
+		}
+		if sunset, err := time.Parse("2006-01-02", version.Sunset); err == nil {
+			info.Sunset = sunset.Unix()
+		}
+		if i+1 < len(manifest.APIVersions) {
+			info.Successor = manifest.APIVersions[len(manifest.APIVersions)-1].Version
+		}
+		data.Versions = append(data.Versions, info)
+	}
+	return data
+}
+
+// writeOpenAPIDocument writes docs/openapi/<version>.json, describing the resources of an API
+// version from the schemas they were generated from
+func (g *SchemaGenerator) writeOpenAPIDocument(manifest *VibercodeManifest, version *VibercodeAPIVersion, outputPath string) error {
+	paths := map[string]interface{}{}
+	schemas, responses := openAPIProblemComponents()
+	deprecated := version.Deprecated != ""
+
+	for i := range version.Resources {
+		resource := &version.Resources[i]
+		data, err := os.ReadFile(filepath.Join(outputPath, ".vibercode", filepath.FromSlash(resource.Snapshot)))
+		if err != nil {
+			ui.PrintWarning(fmt.Sprintf("%s is left out of the %s OpenAPI document, its schema snapshot is missing", resource.Name, version.Version))
+			continue
+		}
+		schema, err := models.FromJSON(data)
+		if err != nil {
+			return fmt.Errorf("invalid schema snapshot %s: %w", resource.Snapshot, err)
+		}
+		// Older versions of a resource are served by CRUD handlers
+		served := resource
+		if manifest.latestAPIVersion(resource.Name) != version {
+			served = nil
+		}
+		g.addOpenAPIResource(paths, schemas, schema, served, deprecated)
+	}
+
+	description := fmt.Sprintf("API %s of %s", version.Version, manifest.Name)
+	if deprecated {
+		description += ", deprecated"
+		if version.Sunset != "" {
+			description += " and served until " + version.Sunset
+		}
+	}
+	document := map[string]interface{}{
+		"openapi": "3.1.0",
+		"info": map[string]interface{}{
+			"title":       manifest.Name + " API",
+			"version":     version.Version,
+			"description": description,
+		},
+		"servers":    []interface{}{map[string]interface{}{"url": "/api/" + version.Version}},
+		"paths":      paths,
+		"components": map[string]interface{}{"schemas": schemas, "responses": responses},
+	}
+
+	content, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return err
+	}
+	return g.writeGeneratedFile(filepath.Join(outputPath, "docs", "openapi", version.Version+".json"), append(content, '\n'))
+}
+
+// openAPIProblemComponents returns the Problem and FieldError schemas of the problem details
+// (RFC 7807) error responses, and the responses operations reference by status
+func openAPIProblemComponents() (schemas, responses map[string]interface{}) {
+	str := map[string]interface{}{"type": "string"}
+	schemas = map[string]interface{}{
+		"FieldError": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"field":   map[string]interface{}{"type": "string", "description": "JSON path of the rejected field"},
+				"code":    map[string]interface{}{"type": "string", "description": "Failed rule, e.g. required or max"},
+				"message": str,
+			},
+			"required": []string{"field", "code", "message"},
+		},
+		"Problem": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"type":     map[string]interface{}{"type": "string", "format": "uri-reference"},
+				"title":    str,
+				"status":   map[string]interface{}{"type": "integer"},
+				"detail":   str,
+				"instance": str,
+				"code": map[string]interface{}{
+					"type":        "string",
+					"description": "Stable error code",
+					"enum":        []string{"bad_request", "validation_failed", "unauthorized", "forbidden", "not_found", "conflict", "internal_error"},
+				},
+				"errors": map[string]interface{}{"type": "array", "items": map[string]interface{}{"$ref": "#/components/schemas/FieldError"}},
+			},
+			"required": []string{"type", "title", "status", "code"},
+		},
+	}
+
+	responses = map[string]interface{}{
+		"BadRequest":    openAPIProblem("Malformed request or rejected fields", "bad_request", "validation_failed"),
+		"NotFound":      openAPIProblem("Resource not found", "not_found"),
+		"Conflict":      openAPIProblem("Resource already exists", "conflict"),
+		"InternalError": openAPIProblem("Unexpected error, the detail is not exposed", "internal_error"),
+	}
+	return schemas, responses
+}
+
+// openAPIProblem returns a problem details response carrying one of the given codes
+func openAPIProblem(description string, codes ...string) map[string]interface{} {
+	schema := map[string]interface{}{
+		"allOf": []interface{}{
+			openAPIRef("Problem"),
+			map[string]interface{}{"properties": map[string]interface{}{"code": map[string]interface{}{"enum": codes}}},
+		},
+	}
+	return map[string]interface{}{
+		"description": description,
+		"content":     map[string]interface{}{"application/problem+json": map[string]interface{}{"schema": schema}},
+	}
+}
+
+// openAPIOperation builds an operation of a resource from its ID, summary and responses
+type openAPIOperation func(id, summary string, responses map[string]interface{}) map[string]interface{}
+
+// addOpenAPIResource adds the operations and payloads of a resource to an OpenAPI document.
+// served is the manifest entry of a resource the version serves the current routes of, whose
+// feature endpoints, relation routes and includes are documented too. It is nil for older
+// versions, served by CRUD handlers.
+func (g *SchemaGenerator) addOpenAPIResource(paths, schemas map[string]interface{}, schema *models.ResourceSchema, served *VibercodeAPIVersionResource, deprecated bool) {
+	names := schema.Names
+	if names == nil {
+		names = models.CreateResourceNames(schema.Name)
+	}
+	pascal := names.PascalCase
+
+	request := map[string]interface{}{}
+	response := map[string]interface{}{
+		"id":         map[string]interface{}{"type": "string", "pattern": "^[0-9a-f]{24}$"},
+		"created_at": map[string]interface{}{"type": "string", "format": "date-time"},
+		"updated_at": map[string]interface{}{"type": "string", "format": "date-time"},
+	}
+	var required []string
+	for i := range schema.Fields {
+		field := &schema.Fields[i]
+		name := toSnakeCase(field.Name)
+		property := openAPIProperty(field)
+		// Secrets are write-only
+		if !field.IsSensitive() {
+			response[name] = property
+		}
+		if field.Type == "relation_array" {
+			continue
+		}
+		request[name] = property
+		if field.Required {
+			required = append(required, name)
+		}
+	}
+	requestSchema := map[string]interface{}{"type": "object", "properties": request}
+	if len(required) > 0 {
+		requestSchema["required"] = required
+	}
+	schemas[pascal+"Request"] = requestSchema
+	schemas[pascal+"Response"] = map[string]interface{}{"type": "object", "properties": response}
+
+	operation := func(id, summary string, responses map[string]interface{}) map[string]interface{} {
+		op := map[string]interface{}{
+			"operationId": id,
+			"summary":     summary,
+			"tags":        []string{names.Plural},
+			"responses":   responses,
+		}
+		if deprecated {
+			op["deprecated"] = true
+		}
+		return op
+	}
+	filters := g.openAPIFilterParameters(schema)
+	var views []interface{}
+	if served != nil {
+		views = openAPIViewParameters(schema, names, served.Includes)
+	}
+
+	list := operation("list"+names.PascalPlural, "List "+names.Plural, openAPIProblems(map[string]interface{}{
+		"200": openAPIReply("A page of "+names.Plural, openAPIPage(openAPIRef(pascal+"Response"))),
+	}, "BadRequest", "InternalError"))
+	list["parameters"] = append(append(openAPIPageParameters(), filters...), views...)
+	create := operation("create"+pascal, "Create a "+names.Singular, openAPIProblems(map[string]interface{}{
+		"201": openAPIReply("The created "+names.Singular, openAPIRef(pascal+"Response")),
+	}, "BadRequest", "Conflict", "InternalError"))
+	create["requestBody"] = map[string]interface{}{"required": true, "content": openAPIContent(openAPIRef(pascal + "Request"))}
+	get := operation("get"+pascal, "Get a "+names.Singular, openAPIProblems(map[string]interface{}{
+		"200": openAPIReply("The "+names.Singular, openAPIRef(pascal+"Response")),
+	}, "BadRequest", "NotFound", "InternalError"))
+	if len(views) > 0 {
+		get["parameters"] = views
+	}
+	update := operation("update"+pascal, "Update a "+names.Singular, openAPIProblems(map[string]interface{}{
+		"200": openAPIReply("The updated "+names.Singular, openAPIRef(pascal+"Response")),
+	}, "BadRequest", "NotFound", "Conflict", "InternalError"))
+	update["requestBody"] = map[string]interface{}{"required": true, "content": openAPIContent(openAPIRef(pascal + "Request"))}
+
+	paths["/"+names.KebabPlural] = map[string]interface{}{"get": list, "post": create}
+	paths["/"+names.KebabPlural+"/{id}"] = map[string]interface{}{
+		"parameters": []interface{}{openAPIPathParameter("id")},
+		"get":        get,
+		"put":        update,
+		"delete": operation("delete"+pascal, "Delete a "+names.Singular, openAPIProblems(map[string]interface{}{
+			"200": openAPIReply(strings.Title(names.Singular)+" deleted", openAPIMessage()),
+		}, "BadRequest", "NotFound", "InternalError")),
+	}
+
+	if served == nil {
+		return
+	}
+	for _, endpoint := range served.Endpoints {
+		switch endpoint {
+		case models.FeatureBulk:
+			addOpenAPIBulk(paths, schemas, names, operation, filters)
+		case endpointBulkUpsert:
+			upsert := operation("upsert"+names.PascalPlural, "Create or update "+names.Plural+" by their natural key", openAPIBulkResponses())
+			upsert["requestBody"] = openAPIBulkBody(pascal)
+			openAPIPath(paths, "/"+names.KebabPlural+"/bulk")["put"] = upsert
+		case endpointImport:
+			addOpenAPIImport(paths, schemas, names, operation)
+		case models.FeatureExport:
+			addOpenAPIExport(paths, names, operation, filters)
+		case models.FeatureSearch:
+			addOpenAPISearch(paths, names, operation)
+		}
+	}
+	addOpenAPIUploads(paths, schema, names, operation)
+	addOpenAPIRelations(paths, schema, names, operation, served.Relations)
+}
+
+// openAPIFilterParameters returns the query parameters filtering lists of a resource: sorting,
+// the search, the geographic filters of coordinates fields and one per filterable field
+func (g *SchemaGenerator) openAPIFilterParameters(schema *models.ResourceSchema) []interface{} {
+	str := map[string]interface{}{"type": "string"}
+	parameters := []interface{}{
+		openAPIQueryParameter("sort", "JSON name of the field the list is sorted by", str),
+		openAPIQueryParameter("order", "Sort order", map[string]interface{}{"type": "string", "enum": []string{"asc", "desc"}}),
+		openAPIQueryParameter("search", "Text the listed records contain", str),
+	}
+	if len(schema.GetGeoFields()) > 0 {
+		parameters = append(parameters,
+			openAPIQueryParameter("near", "Position \"lat,lng\" the nearest records are listed first from", str),
+			openAPIQueryParameter("radius", "Distance from near, e.g. 500m, 5km or 3mi", str),
+			openAPIQueryParameter("bbox", "Bounding box \"minLng,minLat,maxLng,maxLat\" the records lie in", str),
+		)
+	}
+	for i := range schema.Fields {
+		field := &schema.Fields[i]
+		if !g.isFieldFilterable(field) {
+			continue
+		}
+		name := toSnakeCase(field.Name)
+		parameters = append(parameters, openAPIQueryParameter(name, "Lists the records whose "+name+" is the value", openAPIQueryType(field)))
+	}
+	return parameters
+}
+
+// openAPIViewParameters returns the ?include and ?fields[<type>] query parameters of the list
+// and get operations of a resource
+func openAPIViewParameters(schema *models.ResourceSchema, names *models.NamingConventions, includes []string) []interface{} {
+	str := map[string]interface{}{"type": "string"}
+	parameters := []interface{}{}
+	types := []string{names.SnakeCase}
+	if len(includes) > 0 {
+		parameters = append(parameters, openAPIQueryParameter("include",
+			"Comma-separated relations to expand, nested with dots: "+strings.Join(includes, ", "), str))
+		for _, include := range includes {
+			for i := range schema.Fields {
+				field := &schema.Fields[i]
+				if toSnakeCase(field.Name) == include && field.Relation != nil {
+					types = append(types, models.CreateResourceNames(field.Relation.Target).SnakeCase)
+				}
+			}
+		}
+	}
+	seen := make(map[string]bool)
+	for _, resourceType := range types {
+		if seen[resourceType] {
+			continue
+		}
+		seen[resourceType] = true
+		parameters = append(parameters, openAPIQueryParameter("fields["+resourceType+"]",
+			"Comma-separated fields of the "+resourceType+" records in the response", str))
+	}
+	return parameters
+}
+
+// addOpenAPIBulk adds the batch create, patch and delete operations of a resource
+func addOpenAPIBulk(paths, schemas map[string]interface{}, names *models.NamingConventions, operation openAPIOperation, filters []interface{}) {
+	schemas["BulkItemResult"] = map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"index":  map[string]interface{}{"type": "integer"},
+			"id":     map[string]interface{}{"type": "string"},
+			"status": map[string]interface{}{"type": "string"},
+			"error":  map[string]interface{}{"type": "string"},
+		},
+		"required": []string{"index", "status"},
+	}
+	schemas["BulkReport"] = map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"total":     map[string]interface{}{"type": "integer"},
+			"succeeded": map[string]interface{}{"type": "integer"},
+			"failed":    map[string]interface{}{"type": "integer"},
+			"committed": map[string]interface{}{"type": "boolean"},
+			"items":     map[string]interface{}{"type": "array", "items": openAPIRef("BulkItemResult")},
+		},
+		"required": []string{"total", "succeeded", "failed", "committed", "items"},
+	}
+
+	filter := map[string]interface{}{}
+	for _, parameter := range filters {
+		parameter := parameter.(map[string]interface{})
+		filter[parameter["name"].(string)] = parameter["schema"]
+	}
+	path := openAPIPath(paths, "/"+names.KebabPlural+"/bulk")
+	create := operation("bulkCreate"+names.PascalPlural, "Create "+names.Plural+" in one batch", openAPIBulkResponses())
+	create["requestBody"] = openAPIBulkBody(names.PascalCase)
+	path["post"] = create
+	patch := operation("bulkPatch"+names.PascalPlural, "Set fields of the "+names.Plural+" matching a filter", map[string]interface{}{
+		"200": openAPIReply("Number of updated "+names.Plural, map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{"updated": map[string]interface{}{"type": "integer"}},
+		}),
+		"400": openAPIProblem("Malformed request or empty filter", "bad_request"),
+		"422": openAPIProblem("Rejected fields", "unprocessable_entity"),
+		"500": map[string]interface{}{"$ref": "#/components/responses/InternalError"},
+	})
+	patch["requestBody"] = map[string]interface{}{"required": true, "content": openAPIContent(map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"filter": map[string]interface{}{"type": "object", "properties": filter},
+			"set":    map[string]interface{}{"type": "object", "description": "Values of the fields to set, by JSON name"},
+		},
+		"required": []string{"set"},
+	})}
+	path["patch"] = patch
+	remove := operation("bulkDelete"+names.PascalPlural, "Delete "+names.Plural+" by ID", openAPIBulkResponses())
+	remove["requestBody"] = map[string]interface{}{"required": true, "content": openAPIContent(map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"ids": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}}},
+		"required":   []string{"ids"},
+	})}
+	path["delete"] = remove
+}
+
+// openAPIBulkBody returns the request body of the batch create and upsert operations
+func openAPIBulkBody(pascal string) map[string]interface{} {
+	return map[string]interface{}{"required": true, "content": openAPIContent(map[string]interface{}{
+		"type":  "array",
+		"items": openAPIRef(pascal + "Request"),
+	})}
+}
+
+// openAPIBulkResponses returns the responses of the batch operations answering with a report
+func openAPIBulkResponses() map[string]interface{} {
+	report := openAPIRef("BulkReport")
+	return map[string]interface{}{
+		"200": openAPIReply("Every item succeeded", report),
+		"400": openAPIProblem("Malformed request or empty batch", "bad_request"),
+		"409": openAPIReply("The batch failed and was rolled back", report),
+		"413": openAPIProblem("The batch has too many items", "request_entity_too_large"),
+		"422": openAPIReply("Items were rejected", report),
+		"500": map[string]interface{}{"$ref": "#/components/responses/InternalError"},
+	}
+}
+
+// addOpenAPIImport adds the CSV and NDJSON import operation of a resource
+func addOpenAPIImport(paths, schemas map[string]interface{}, names *models.NamingConventions, operation openAPIOperation) {
+	schemas["ImportReport"] = map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"imported": map[string]interface{}{"type": "integer"},
+			"rejected": map[string]interface{}{"type": "integer"},
+			"errors": map[string]interface{}{"type": "array", "items": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"line":  map[string]interface{}{"type": "integer"},
+					"error": map[string]interface{}{"type": "string"},
+				},
+			}},
+			"truncated": map[string]interface{}{"type": "boolean"},
+		},
+		"required": []string{"imported", "rejected"},
+	}
+
+	report := openAPIRef("ImportReport")
+	str := map[string]interface{}{"type": "string"}
+	imports := operation("import"+names.PascalPlural, "Import "+names.Plural+" from CSV or NDJSON", map[string]interface{}{
+		"200": openAPIReply("Every row was imported", report),
+		"400": openAPIProblem("Malformed body", "bad_request"),
+		"415": openAPIProblem("The body is neither CSV nor NDJSON", "unsupported_media_type"),
+		"422": openAPIReply("Rows were rejected", report),
+		"500": map[string]interface{}{"$ref": "#/components/responses/InternalError"},
+	})
+	imports["requestBody"] = map[string]interface{}{"required": true, "content": map[string]interface{}{
+		"text/csv":             map[string]interface{}{"schema": str},
+		"application/x-ndjson": map[string]interface{}{"schema": str},
+	}}
+	openAPIPath(paths, "/"+names.KebabPlural+"/import")["post"] = imports
+}
+
+// addOpenAPIExport adds the streaming export operation of a resource
+func addOpenAPIExport(paths map[string]interface{}, names *models.NamingConventions, operation openAPIOperation, filters []interface{}) {
+	export := operation("export"+names.PascalPlural, "Export the "+names.Plural+" matching the list filters", map[string]interface{}{
+		"200": map[string]interface{}{
+			"description": "The " + names.Plural + " as a file",
+			"content": map[string]interface{}{
+				"text/csv":             map[string]interface{}{"schema": map[string]interface{}{"type": "string"}},
+				"application/x-ndjson": map[string]interface{}{"schema": map[string]interface{}{"type": "string"}},
+				"application/json":     map[string]interface{}{"schema": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "object"}}},
+			},
+		},
+		"400": map[string]interface{}{"$ref": "#/components/responses/BadRequest"},
+		"406": openAPIProblem("Unsupported export format", "not_acceptable"),
+	})
+	export["parameters"] = append(append([]interface{}{}, filters...),
+		openAPIQueryParameter("fields", "Comma-separated fields of the exported "+names.Plural, map[string]interface{}{"type": "string"}),
+		openAPIQueryParameter("format", "Export format, the Accept header is negotiated without it",
+			map[string]interface{}{"type": "string", "enum": []string{"csv", "ndjson", "json"}}),
+	)
+	openAPIPath(paths, "/"+names.KebabPlural+"/export")["get"] = export
+}
+
+// addOpenAPISearch adds the full-text search operation of a resource
+func addOpenAPISearch(paths map[string]interface{}, names *models.NamingConventions, operation openAPIOperation) {
+	result := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"item": openAPIRef(names.PascalCase + "Response"),
+			"rank": map[string]interface{}{"type": "number"},
+			"highlights": map[string]interface{}{
+				"type":                 "object",
+				"description":          "HTML snippets of the matched fields, the matched terms in <mark> tags",
+				"additionalProperties": map[string]interface{}{"type": "string"},
+			},
+		},
+	}
+	search := operation("search"+names.PascalPlural, "Search "+names.Plural+", best match first", map[string]interface{}{
+		"200": openAPIReply("A page of matching "+names.Plural, openAPIPage(result)),
+		"400": openAPIProblem("Empty query", "bad_request"),
+		"500": map[string]interface{}{"$ref": "#/components/responses/InternalError"},
+	})
+	q := openAPIQueryParameter("q", "Text to search for", map[string]interface{}{"type": "string"})
+	q["required"] = true
+	search["parameters"] = append([]interface{}{q}, openAPIPageParameters()...)
+	openAPIPath(paths, "/"+names.KebabPlural+"/search")["get"] = search
+}
+
+// addOpenAPIUploads adds the upload, download and removal operations of the file and image
+// fields of a resource
+func addOpenAPIUploads(paths map[string]interface{}, schema *models.ResourceSchema, names *models.NamingConventions, operation openAPIOperation) {
+	for _, field := range schema.GetUploadFields() {
+		config := field.GetUploadConfig()
+		fieldNames := generateFieldNamingConventions(field.Name)
+		item := openAPIReply("The "+names.Singular, openAPIRef(names.PascalCase+"Response"))
+		rejections := map[string]interface{}{
+			"404": map[string]interface{}{"$ref": "#/components/responses/NotFound"},
+			"500": map[string]interface{}{"$ref": "#/components/responses/InternalError"},
+		}
+		responses := func(extra map[string]interface{}) map[string]interface{} {
+			merged := map[string]interface{}{}
+			for _, set := range []map[string]interface{}{rejections, extra} {
+				for status, response := range set {
+					merged[status] = response
+				}
+			}
+			return merged
+		}
+
+		upload := operation("upload"+names.PascalCase+fieldNames.PascalCase, "Upload the "+fieldNames.SnakeCase+" of a "+names.Singular, responses(map[string]interface{}{
+			"200": item,
+			"400": openAPIProblem("Missing or empty file", "bad_request"),
+			"413": openAPIProblem(fmt.Sprintf("The file exceeds %d bytes", config.MaxSize), "request_entity_too_large"),
+			"415": openAPIProblem("The file type is not allowed", "unsupported_media_type"),
+		}))
+		file := map[string]interface{}{"type": "string", "format": "binary"}
+		if len(config.AllowedTypes) > 0 {
+			file["description"] = "One of " + strings.Join(config.AllowedTypes, ", ")
+		}
+		upload["requestBody"] = map[string]interface{}{"required": true, "content": map[string]interface{}{
+			"multipart/form-data": map[string]interface{}{"schema": map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{"file": file},
+				"required":   []string{"file"},
+			}},
+		}}
+		download := operation("download"+names.PascalCase+fieldNames.PascalCase, "Download the "+fieldNames.SnakeCase+" of a "+names.Singular, responses(map[string]interface{}{
+			"200": map[string]interface{}{
+				"description": "The uploaded file",
+				"content":     map[string]interface{}{"*/*": map[string]interface{}{"schema": map[string]interface{}{"type": "string", "format": "binary"}}},
+			},
+		}))
+		if config.Thumbnail != nil {
+			download["parameters"] = []interface{}{openAPIQueryParameter("thumbnail", "Serve the thumbnail of the image", map[string]interface{}{"type": "boolean"})}
+		}
+		remove := operation("remove"+names.PascalCase+fieldNames.PascalCase, "Remove the "+fieldNames.SnakeCase+" of a "+names.Singular, responses(map[string]interface{}{
+			"200": item,
+		}))
+
+		paths["/"+names.KebabPlural+"/{id}/"+fieldNames.KebabCase] = map[string]interface{}{
+			"parameters": []interface{}{openAPIPathParameter("id")},
+			"post":       upload,
+			"get":        download,
+			"delete":     remove,
+		}
+	}
+}
+
+// addOpenAPIRelations adds the nested routes of the one-to-many and many-to-many relation
+// fields of a resource
+func addOpenAPIRelations(paths map[string]interface{}, schema *models.ResourceSchema, names *models.NamingConventions, operation openAPIOperation, relations []string) {
+	for _, relation := range relations {
+		var field *models.SchemaField
+		for i := range schema.Fields {
+			if toSnakeCase(schema.Fields[i].Name) == relation && schema.Fields[i].Relation != nil {
+				field = &schema.Fields[i]
+			}
+		}
+		if field == nil {
+			continue
+		}
+		fieldNames := generateFieldNamingConventions(field.Name)
+		target := models.CreateResourceNames(field.Relation.Target)
+		many := field.Relation.Type == "many_to_many"
+		notFound := map[string]interface{}{"$ref": "#/components/responses/NotFound"}
+		internal := map[string]interface{}{"$ref": "#/components/responses/InternalError"}
+
+		item := openAPIRef(target.PascalCase + "Response")
+		if many {
+			attached := map[string]interface{}{"attached_at": map[string]interface{}{"type": "string", "format": "date-time"}}
+			if pivot := openAPIPivot(field.Relation.PivotFields); pivot != nil {
+				attached["pivot"] = pivot
+			}
+			item = map[string]interface{}{"allOf": []interface{}{item, map[string]interface{}{"type": "object", "properties": attached}}}
+		}
+		list := operation("list"+names.PascalCase+fieldNames.PascalCase, "List the "+strings.ReplaceAll(fieldNames.SnakeCase, "_", " ")+" of a "+names.Singular, map[string]interface{}{
+			"200": openAPIReply("A page of "+target.Plural, openAPIPage(item)),
+			"404": notFound,
+			"500": internal,
+		})
+		list["parameters"] = openAPIPageParameters()
+		paths["/"+names.KebabPlural+"/{id}/"+fieldNames.KebabCase] = map[string]interface{}{
+			"parameters": []interface{}{openAPIPathParameter("id")},
+			"get":        list,
+		}
+		if !many {
+			continue
+		}
+
+		attach := operation("attach"+names.PascalCase+fieldNames.PascalCase, "Attach a "+target.Singular+" to a "+names.Singular, map[string]interface{}{
+			"200": openAPIReply(strings.Title(target.Singular)+" attached", openAPIMessage()),
+			"400": openAPIProblem("Rejected pivot attributes", "bad_request"),
+			"404": notFound,
+			"500": internal,
+		})
+		if pivot := openAPIPivot(field.Relation.PivotFields); pivot != nil {
+			attach["requestBody"] = map[string]interface{}{"required": true, "content": openAPIContent(pivot)}
+		}
+		detach := operation("detach"+names.PascalCase+fieldNames.PascalCase, "Detach a "+target.Singular+" from a "+names.Singular, map[string]interface{}{
+			"200": openAPIReply(strings.Title(target.Singular)+" detached", openAPIMessage()),
+			"404": notFound,
+			"500": internal,
+		})
+		paths["/"+names.KebabPlural+"/{id}/"+fieldNames.KebabCase+"/{"+target.SnakeCase+"_id}"] = map[string]interface{}{
+			"parameters": []interface{}{openAPIPathParameter("id"), openAPIPathParameter(target.SnakeCase + "_id")},
+			"put":        attach,
+			"delete":     detach,
+		}
+	}
+}
+
+// openAPIPivot returns the schema of the pivot attributes of a many-to-many relation, nil
+// without attributes
+func openAPIPivot(fields []models.SchemaField) map[string]interface{} {
+	if len(fields) == 0 {
+		return nil
+	}
+	properties := map[string]interface{}{}
+	for i := range fields {
+		properties[toSnakeCase(fields[i].Name)] = openAPIProperty(&fields[i])
+	}
+	return map[string]interface{}{"type": "object", "properties": properties}
+}
+
+// openAPIPath returns the operations of a path, adding the path to the document
+func openAPIPath(paths map[string]interface{}, path string) map[string]interface{} {
+	if operations, ok := paths[path].(map[string]interface{}); ok {
+		return operations
+	}
+	operations := map[string]interface{}{}
+	paths[path] = operations
+	return operations
+}
+
+// openAPIPage returns the schema of a page of items
+func openAPIPage(item interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"data":      map[string]interface{}{"type": "array", "items": item},
+			"total":     map[string]interface{}{"type": "integer"},
+			"page":      map[string]interface{}{"type": "integer"},
+			"page_size": map[string]interface{}{"type": "integer"},
+		},
+	}
+}
+
+// openAPIMessage returns the schema of the responses confirming an operation with a message
+func openAPIMessage() map[string]interface{} {
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"message": map[string]interface{}{"type": "string"}},
+	}
+}
+
+// openAPIPageParameters returns the page and page_size query parameters of paginated operations
+func openAPIPageParameters() []interface{} {
+	return []interface{}{
+		openAPIQueryParameter("page", "", map[string]interface{}{"type": "integer", "minimum": 1}),
+		openAPIQueryParameter("page_size", "", map[string]interface{}{"type": "integer", "minimum": 1}),
+	}
+}
+
+// openAPIQueryParameter returns a query parameter, described unless description is empty
+func openAPIQueryParameter(name, description string, schema map[string]interface{}) map[string]interface{} {
+	parameter := map[string]interface{}{"name": name, "in": "query", "schema": schema}
+	if description != "" {
+		parameter["description"] = description
+	}
+	return parameter
+}
+
+// openAPIPathParameter returns a string path parameter
+func openAPIPathParameter(name string) map[string]interface{} {
+	return map[string]interface{}{"name": name, "in": "path", "required": true, "schema": map[string]interface{}{"type": "string"}}
+}
+
+// openAPIQueryType returns the schema of a query parameter filtering on a field
+func openAPIQueryType(field *models.SchemaField) map[string]interface{} {
+	switch field.GetGoType() {
+	case "int64":
+		return map[string]interface{}{"type": "integer"}
+	case "float64":
+		return map[string]interface{}{"type": "number"}
+	case "bool":
+		return map[string]interface{}{"type": "boolean"}
+	case "time.Time":
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+	return map[string]interface{}{"type": "string"}
+}
+
+// openAPIRef returns a reference to a schema of the components
+func openAPIRef(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
+
+// openAPIContent returns the JSON content of a request or response
+func openAPIContent(schema interface{}) map[string]interface{} {
+	return map[string]interface{}{"application/json": map[string]interface{}{"schema": schema}}
+}
+
+// openAPIReply returns a JSON response
+func openAPIReply(description string, schema interface{}) map[string]interface{} {
+	return map[string]interface{}{"description": description, "content": openAPIContent(schema)}
+}
+
+// openAPIProblems adds references to the problem responses of the components, by name
+func openAPIProblems(responses map[string]interface{}, names ...string) map[string]interface{} {
+	statuses := map[string]string{"BadRequest": "400", "NotFound": "404", "Conflict": "409", "InternalError": "500"}
+	for _, name := range names {
+		responses[statuses[name]] = map[string]interface{}{"$ref": "#/components/responses/" + name}
+	}
+	return responses
+}
+
+// openAPIProperty returns the schema of a field in OpenAPI documents
+func openAPIProperty(field *models.SchemaField) map[string]interface{} {
+	switch {
+	case field.Type == "relation":
+		return map[string]interface{}{"type": []string{"object", "null"}}
+	case field.Type == "relation_array":
+		return map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "object"}}
+	case field.IsGeo():
+		return map[string]interface{}{
+			"type": []string{"object", "null"},
+			"properties": map[string]interface{}{
+				"latitude":  map[string]interface{}{"type": "number", "minimum": -90, "maximum": 90},
+				"longitude": map[string]interface{}{"type": "number", "minimum": -180, "maximum": 180},
+			},
+			"required": []string{"latitude", "longitude"},
+		}
+	}
+	return eventSchemaProperty(field)
+}
+
+// loadSchemaSnapshot reads the schema a resource of an API version was generated from
+func loadSchemaSnapshot(outputPath, version, snake string) (*models.ResourceSchema, error) {
+	path := filepath.Join(outputPath, ".vibercode", "api", version, snake+".json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the %s schema snapshot: %w", version, err)
+	}
+	schema, err := models.FromJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema snapshot %s: %w", path, err)
+	}
+	return schema, nil
+}
+
+// apiVersionNumber returns the number of a version name, v2 gives 2
+func apiVersionNumber(version string) int {
+	number, _ := strconv.Atoi(strings.TrimPrefix(version, "v"))
+	return number
+}
+
+// findAPIVersion returns the recorded API version with the given name, nil if there is none
+func (m *VibercodeManifest) findAPIVersion(name string) *VibercodeAPIVersion {
+	for i := range m.APIVersions {
+		if m.APIVersions[i].Version == name {
+			return &m.APIVersions[i]
+		}
+	}
+	return nil
+}
+
+// latestAPIVersion returns the latest API version of a resource, nil when no version has it
+func (m *VibercodeManifest) latestAPIVersion(resource string) *VibercodeAPIVersion {
+	for i := len(m.APIVersions) - 1; i >= 0; i-- {
+		if m.APIVersions[i].resource(resource) != nil {
+			return &m.APIVersions[i]
+		}
+	}
+	return nil
+}
+
+// apiVersion returns the recorded API version with the given name, recording it if needed
+func (m *VibercodeManifest) apiVersion(name string) *VibercodeAPIVersion {
+	if version := m.findAPIVersion(name); version != nil {
+		return version
+	}
+	m.APIVersions = append(m.APIVersions, VibercodeAPIVersion{Version: name})
+	sort.Slice(m.APIVersions, func(i, j int) bool {
+		return apiVersionNumber(m.APIVersions[i].Version) < apiVersionNumber(m.APIVersions[j].Version)
+	})
+	return m.findAPIVersion(name)
+}
+
+// recordEvent appends an event to the history of the manifest
+func (m *VibercodeManifest) recordEvent(eventType, description string) {
+	m.History = append(m.History, VibercodeManifestEvent{
+		Type:        eventType,
+		Description: description,
+		Timestamp:   time.Now().Format(time.RFC3339),
+		CLI:         VibercodeManifestCLI{Version: "1.0.0", Command: "vibercode schema generate"},
+	})
+}
+
+// resource returns the resource of the version with the given name, nil if there is none
+func (v *VibercodeAPIVersion) resource(name string) *VibercodeAPIVersionResource {
+	for i := range v.Resources {
+		if v.Resources[i].Name == name {
+			return &v.Resources[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package generator
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vibercode/cli/internal/models"
+)
+
+func TestSchemaGenerator_APIVersions(t *testing.T) {
+	product := newTestProductSchema()
+	dir := generateTestProject(t, NewSchemaGenerator(newMemorySchemaStorage(product)), "postgres", product)
+	assertGeneratedFiles(t, dir,
+		generatedFile{path: "docs/openapi/v1.json"},
+		// A single version has no lifecycle to serve
+		generatedFile{path: "internal/handlers/api_versions.go", missing: true},
+	)
+
+	// v2 drops stock and retypes active
+	product.Version = "2.0.0"
+	product.Fields = append(product.Fields[:3:3], models.SchemaField{Name: "active", Type: "string", DisplayName: "Active"})
+	gen := NewSchemaGenerator(newMemorySchemaStorage(product)).WithAPIVersion("v2").WithDeprecation("v1", "2027-01-31")
+	require.NoError(t, gen.GenerateFromSchema(product.ID, dir, "example.com/shop", "postgres"))
+
+	manifest, err := LoadManifest(dir)
+	require.NoError(t, err)
+	require.Len(t, manifest.APIVersions, 2)
+	assert.Equal(t, "v1", manifest.APIVersions[0].Version)
+	assert.NotEmpty(t, manifest.APIVersions[0].Deprecated)
+	assert.Equal(t, "2027-01-31", manifest.APIVersions[0].Sunset)
+	assert.Equal(t, "2.0.0", manifest.APIVersions[1].Resources[0].SchemaVersion)
+	assert.Equal(t, "api/v2/product.json", manifest.APIVersions[1].Resources[0].Snapshot)
+
+	assertGeneratedFiles(t, dir,
+		generatedFile{
+			path: "internal/models/product_v1.go",
+			contains: []string{
+				// v1 keeps its contract
+				"Stock       int64  `json:\"stock\"`",
+				"Description: r.Description,",
+				"// Active was removed or retyped since v1 and stays empty",
+			},
+			excludes: []string{"Stock: m.Stock"},
+		},
+		generatedFile{
+			path:     "internal/handlers/product_v1_handler.go",
+			contains: []string{`for name, value := range apiVersionHeaders("v1")`, "func SetupProductV1Routes("},
+		},
+		generatedFile{path: "internal/handlers/api_versions.go", contains: []string{`Successor: "v2"`, `"v2": {},`}},
+		generatedFile{path: "docs/openapi/v1.json", contains: []string{`"deprecated": true`, `"stock"`}},
+		generatedFile{path: "docs/openapi/v2.json", contains: []string{`"url": "/api/v2"`}, excludes: []string{`"stock"`}},
+	)
+
+	// Regenerating keeps the resource on v2, older contracts can't be changed
+	require.NoError(t, NewSchemaGenerator(newMemorySchemaStorage(product)).GenerateFromSchema(product.ID, dir, "example.com/shop", "postgres"))
+	err = NewSchemaGenerator(newMemorySchemaStorage(product)).WithAPIVersion("v1").GenerateFromSchema(product.ID, dir, "example.com/shop", "postgres")
+	assert.ErrorContains(t, err, "served as v2")
+	err = NewSchemaGenerator(newMemorySchemaStorage(product)).WithDeprecation("v2", "").GenerateFromSchema(product.ID, dir, "example.com/shop", "postgres")
+	assert.ErrorContains(t, err, "latest API version")
+
+	assertGoFilesParse(t, filepath.Join(dir, "internal"))
+}
+
+func TestSchemaGenerator_OpenAPIProperties(t *testing.T) {
+	product := newTestProductSchema()
+	product.Fields = append(product.Fields,
+		models.SchemaField{Name: "location", Type: "coordinates", DisplayName: "Location"},
+		models.SchemaField{Name: "secret", Type: "password", DisplayName: "Secret"},
+	)
+	dir := generateTestProject(t, NewSchemaGenerator(newMemorySchemaStorage(product)), "postgres", product)
+
+	var document struct {
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]struct {
+					Properties map[string]interface{} `json:"properties"`
+					Required   []string               `json:"required"`
+				} `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(readGeneratedFile(t, dir, "docs/openapi/v1.json")), &document))
+
+	// The keys match the JSON tags of the generated Coordinates type
+	location := document.Components.Schemas["ProductResponse"].Properties["location"]
+	assert.Contains(t, location.Properties, "latitude")
+	assert.Contains(t, location.Properties, "longitude")
+	assert.Equal(t, []string{"latitude", "longitude"}, location.Required)
+
+	// Secrets are written, never read
+	assert.Contains(t, document.Components.Schemas["ProductRequest"].Properties, "secret")
+	assert.NotContains(t, document.Components.Schemas["ProductResponse"].Properties, "secret")
+}
+
+func TestSchemaGenerator_OpenAPIPaths(t *testing.T) {
+	category := &models.ResourceSchema{
+		ID:          "category-1",
+		Name:        "Category",
+		DisplayName: "Category",
+		Names:       models.CreateResourceNames("Category"),
+		Fields:      []models.SchemaField{{Name: "name", Type: "string", DisplayName: "Name", Required: true}},
+		Database:    &models.DatabaseConfig{Provider: "postgres", TableName: "categories"},
+	}
+	tag := &models.ResourceSchema{
+		ID:          "tag-1",
+		Name:        "Tag",
+		DisplayName: "Tag",
+		Names:       models.CreateResourceNames("Tag"),
+		Fields:      []models.SchemaField{{Name: "label", Type: "string", DisplayName: "Label", Required: true}},
+		Database:    &models.DatabaseConfig{Provider: "postgres", TableName: "tags"},
+	}
+	product := newTestProductSchema()
+	product.Fields = append(product.Fields,
+		models.SchemaField{Name: "location", Type: "coordinates", DisplayName: "Location"},
+		models.SchemaField{Name: "cover_image", Type: models.FieldTypeImageUpload, DisplayName: "Cover Image",
+			Upload: &models.UploadFieldConfig{Thumbnail: &models.ThumbnailConfig{Width: 64, Height: 64}}},
+		models.SchemaField{Name: "category_id", Type: "string", DisplayName: "Category ID"},
+		models.SchemaField{Name: "category", Type: "relation", DisplayName: "Category", Relation: &models.RelationConfig{Type: "many_to_one", Target: "Category", ForeignKey: "category_id", Populate: true}},
+		models.SchemaField{Name: "tags", Type: "relation_array", DisplayName: "Tags", Relation: &models.RelationConfig{Type: "many_to_many", Target: "Tag", PivotTable: "product_tags"}},
+	)
+	product.Options = &models.GenerationOptions{
+		Features: []string{models.FeatureBulk, models.FeatureExport, models.FeatureSearch},
+		Bulk:     &models.BulkConfig{MaxBatchSize: 100, Import: true},
+	}
+	dir := generateTestProject(t, NewSchemaGenerator(newMemorySchemaStorage(category, tag, product)), "postgres", category, tag, product)
+
+	manifest, err := LoadManifest(dir)
+	require.NoError(t, err)
+	resource := manifest.APIVersions[0].resource("Product")
+	require.NotNil(t, resource)
+	assert.Equal(t, []string{models.FeatureBulk, endpointBulkUpsert, endpointImport, models.FeatureExport, models.FeatureSearch}, resource.Endpoints)
+	assert.Equal(t, []string{"tags"}, resource.Relations)
+	assert.Equal(t, []string{"category"}, resource.Includes)
+
+	var document struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(readGeneratedFile(t, dir, "docs/openapi/v1.json")), &document))
+	operations := func(path string) []string {
+		var methods []string
+		for method := range document.Paths[path] {
+			methods = append(methods, method)
+		}
+		return methods
+	}
+	parameters := func(path, method string) []string {
+		var operation struct {
+			Parameters []struct {
+				Name string `json:"name"`
+			} `json:"parameters"`
+		}
+		require.NoError(t, json.Unmarshal(document.Paths[path][method], &operation))
+		var names []string
+		for _, parameter := range operation.Parameters {
+			names = append(names, parameter.Name)
+		}
+		return names
+	}
+
+	assert.ElementsMatch(t, []string{"post", "put", "patch", "delete"}, operations("/products/bulk"))
+	assert.ElementsMatch(t, []string{"post"}, operations("/products/import"))
+	assert.Subset(t, parameters("/products/export", "get"), []string{"fields", "format", "sku", "near"})
+	assert.Subset(t, parameters("/products/search", "get"), []string{"q", "page", "page_size"})
+	assert.ElementsMatch(t, []string{"parameters", "post", "get", "delete"}, operations("/products/{id}/cover-image"))
+	assert.Equal(t, []string{"thumbnail"}, parameters("/products/{id}/cover-image", "get"))
+	assert.ElementsMatch(t, []string{"parameters", "get"}, operations("/products/{id}/tags"))
+	assert.ElementsMatch(t, []string{"parameters", "put", "delete"}, operations("/products/{id}/tags/{tag_id}"))
+	assert.Subset(t, parameters("/products", "get"), []string{
+		"page", "page_size", "sort", "order", "search", "near", "radius", "bbox", "sku", "active",
+		"include", "fields[product]", "fields[category]",
+	})
+	assert.Equal(t, []string{"include", "fields[product]", "fields[category]"}, parameters("/products/{id}", "get"))
+
+	// Categories have no opt-in routes
+	assert.NotContains(t, document.Paths, "/categories/export")
+	assert.Equal(t, []string{"fields[category]"}, parameters("/categories/{id}", "get"))
+}
@@ -0,0 +1,102 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/vibercode/cli/internal/models"
+	"github.com/vibercode/cli/internal/templates"
+	"github.com/vibercode/cli/pkg/ui"
+)
+
+// generateWebhooksFeature generates the webhook subscriptions, the signed deliveries with their
+// retry worker and delivery log, and registers the event types of the schema. Deliveries are
+// recorded from the events of the events feature, which the webhooks feature requires.
+func (g *SchemaGenerator) generateWebhooksFeature(data *EnhancedSchema, outputPath string) error {
+	type file struct {
+		template string
+		path     string
+		handler  bool
+	}
+	files := []file{
+		{templates.WebhooksPackageTemplate, filepath.Join("internal", "webhooks", "webhooks.go"), false},
+		{templates.WebhooksSignatureTemplate, filepath.Join("internal", "webhooks", "signature.go"), false},
+		{templates.WebhooksGuardTemplate, filepath.Join("internal", "webhooks", "guard.go"), false},
+		{templates.WebhooksDispatcherTemplate, filepath.Join("internal", "webhooks", "dispatcher.go"), false},
+		{templates.WebhooksWorkerTemplate, filepath.Join("internal", "webhooks", "worker.go"), false},
+		{templates.WebhooksMemoryStoreTemplate, filepath.Join("internal", "webhooks", "memory.go"), false},
+		{templates.WebhooksTestTemplate, filepath.Join("internal", "webhooks", "webhooks_test.go"), false},
+		{templates.SchemaWebhooksTemplate, filepath.Join("internal", "webhooks", data.Names.SnakeCase+".go"), false},
+		{templates.WebhookHandlerTemplate, filepath.Join("internal", "handlers", "webhook_handler.go"), true},
+		{templates.WebhookHandlerTestTemplate, filepath.Join("internal", "handlers", "webhook_handler_test.go"), true},
+	}
+
+	// The store follows the data layer of the events outbox
+	repositoryPath := filepath.Join("internal", "repositories", "webhook_repository.go")
+	switch data.DataLayer {
+	case models.DataLayerSQLX:
+		files = append(files, file{templates.SQLXWebhookRepositoryTemplate, repositoryPath, false})
+	case models.DataLayerSQLC, models.DataLayerPGX:
+		files = append(files, file{templates.PGXWebhookRepositoryTemplate, repositoryPath, false})
+	default:
+		files = append(files, file{templates.MongoWebhookRepositoryTemplate, repositoryPath, false})
+	}
+
+	for _, file := range files {
+		generate := g.generateGoFile
+		if file.handler {
+			generate = g.generateHandlerFile
+		}
+		if err := generate(file.template, data, filepath.Join(outputPath, file.path)); err != nil {
+			return err
+		}
+	}
+
+	if data.DataLayer != models.DataLayerGORM {
+		if err := g.writeSQLMigration(outputPath, "create_webhooks", "Create the webhook_subscriptions and webhook_deliveries tables",
+			sqlWebhookTables(data.DBProvider), "DROP TABLE IF EXISTS webhook_deliveries;\nDROP TABLE IF EXISTS webhook_subscriptions;"); err != nil {
+			return fmt.Errorf("failed to generate webhooks migration: %w", err)
+		}
+	}
+
+	ui.PrintInfo("Webhook deliveries to private addresses are refused unless their network is listed in WEBHOOK_ALLOWED_NETWORKS")
+	ui.PrintInfo("Wire SetupWebhookRoutes with NewWebhookHandler(store, guard, worker) for store := repositories.NewWebhookRepository(db), relay the outbox to webhooks.NewDispatcher(store) and run the worker in the background")
+	return nil
+}
+
+// sqlWebhookTables returns the statements creating the subscription and delivery tables
+func sqlWebhookTables(provider string) string {
+	timestamp := sqlTimestampType(provider)
+	index := "CREATE INDEX IF NOT EXISTS"
+	if provider == "mysql" {
+		index = "CREATE INDEX"
+	}
+
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id VARCHAR(36) PRIMARY KEY,
+    url TEXT NOT NULL,
+    events TEXT NOT NULL,
+    secret VARCHAR(255) NOT NULL,
+    active BOOLEAN NOT NULL,
+    created_at %[1]s NOT NULL,
+    updated_at %[1]s NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id VARCHAR(36) PRIMARY KEY,
+    subscription_id VARCHAR(36) NOT NULL,
+    event_id VARCHAR(36) NOT NULL,
+    event_type VARCHAR(255) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    attempts INTEGER NOT NULL,
+    next_attempt_at %[1]s NOT NULL,
+    last_error TEXT NOT NULL,
+    response_status INTEGER NOT NULL,
+    created_at %[1]s NOT NULL,
+    delivered_at %[1]s
+);
+
+%[2]s idx_webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
+%[2]s idx_webhook_deliveries_subscription ON webhook_deliveries (subscription_id, created_at);`, timestamp, index)
+}
@@ -0,0 +1,66 @@
+package generator
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/vibercode/cli/internal/models"
+)
+
+func TestSchemaGenerator_WebhooksFeature(t *testing.T) {
+	schema := newTestProductSchema()
+	gen := NewSchemaGenerator(newMemorySchemaStorage(schema)).
+		WithFeatures(models.FeatureWebhooks).
+		WithDataLayer(models.DataLayerPGX)
+	dir := generateTestProject(t, gen, "postgres", schema)
+
+	assertGeneratedFiles(t, dir,
+		generatedFile{path: "internal/webhooks/webhooks.go"},
+		generatedFile{path: "internal/webhooks/signature.go"},
+		generatedFile{path: "internal/webhooks/guard.go"},
+		generatedFile{path: "internal/webhooks/dispatcher.go"},
+		generatedFile{path: "internal/webhooks/worker.go"},
+		generatedFile{path: "internal/webhooks/memory.go"},
+		generatedFile{path: "internal/webhooks/webhooks_test.go"},
+		generatedFile{path: "internal/webhooks/product.go", contains: []string{"events.ProductCreated,"}},
+		generatedFile{
+			path:     "internal/handlers/webhook_handler.go",
+			contains: []string{`webhooks.POST("/:id/deliveries/:delivery_id/redeliver", handler.Redeliver)`},
+		},
+		generatedFile{path: "internal/handlers/webhook_handler_test.go"},
+		generatedFile{
+			path: "internal/repositories/webhook_repository.go",
+			contains: []string{
+				"func NewWebhookRepository(pool *pgxpool.Pool) *WebhookRepository {",
+				"WHERE status = $1 AND next_attempt_at <= $2",
+			},
+		},
+		// Webhooks are delivered from the events of the events feature
+		generatedFile{path: "internal/events/events.go"},
+		generatedFile{path: "internal/repositories/outbox_repository.go"},
+		generatedFile{
+			path: "migrations/*_create_webhooks.sql",
+			contains: []string{
+				"CREATE TABLE IF NOT EXISTS webhook_deliveries (",
+				"next_attempt_at TIMESTAMPTZ NOT NULL,",
+			},
+		},
+	)
+
+	assertGoFilesParse(t, filepath.Join(dir, "internal"))
+
+	// MongoDB projects store subscriptions and deliveries in collections
+	mongoGen := NewSchemaGenerator(newMemorySchemaStorage(newTestProductSchema())).
+		WithFeatures(models.FeatureWebhooks).
+		WithHTTPFramework(models.HTTPChi)
+	mongoDir := generateTestProject(t, mongoGen, "mongodb", schema)
+
+	assertGeneratedFiles(t, mongoDir,
+		generatedFile{path: "internal/repositories/webhook_repository.go", contains: []string{`db.Collection("webhook_deliveries")`}},
+		generatedFile{
+			path:     "internal/handlers/webhook_handler.go",
+			contains: []string{`r.Post("/webhooks/{id}/deliveries/{delivery_id}/redeliver", handler.Redeliver)`},
+		},
+	)
+	assertGoFilesParse(t, filepath.Join(mongoDir, "internal"))
+}
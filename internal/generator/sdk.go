@@ -0,0 +1,501 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/vibercode/cli/internal/models"
+	"github.com/vibercode/cli/internal/templates"
+	"github.com/vibercode/cli/pkg/ui"
+)
+
+// SDK languages
+const (
+	SDKLangGo         = "go"
+	SDKLangTypeScript = "ts"
+)
+
+// SDKOptions contains configuration for client SDK generation. Empty options are read from
+// the .vibercode/manifest.vibe of the project.
+type SDKOptions struct {
+	OutputPath string
+	Module     string
+	Lang       string
+	HTTP       models.HTTPFramework
+}
+
+// SDKGenerator generates typed client SDKs of the resources of a project
+type SDKGenerator struct {
+	options SDKOptions
+	files   *SchemaGenerator
+}
+
+// SDKTemplateData holds the data of the SDK templates
+type SDKTemplateData struct {
+	Title     string
+	Name      string // Kebab case name of the project
+	Module    string
+	HTTP      *HTTPDialect
+	Money     bool
+	Geo       bool
+	Resources []*SDKResource
+	Versions  []SDKVersion
+	Test      *SDKTest
+}
+
+// SDKVersion lists the resources the SDK calls through an API version
+type SDKVersion struct {
+	Version   string
+	Resources []*SDKResource
+}
+
+// SDKResource holds the template data of the client of a resource
+type SDKResource struct {
+	Names    *models.NamingConventions
+	Version  string
+	Path     string // Path of the endpoints below the base path, e.g. /v1/products
+	Fields   []SDKField
+	Request  []SDKField
+	Enums    []SDKEnum
+	Includes []string
+	Filters  []SDKFilter
+	Imports  []SDKImport // Resources whose types the TypeScript types refer to
+	Money    bool
+	Geo      bool
+}
+
+// SDKField is a field of the types of a resource
+type SDKField struct {
+	Name      string // Go field name
+	JSON      string
+	GoType    string
+	TSType    string
+	OmitEmpty bool
+	Optional  bool // The TypeScript property may be missing
+	kind      string
+	schema    *models.SchemaField
+}
+
+// SDKEnum lists the allowed values of a field
+type SDKEnum struct {
+	Field  string
+	Type   string // TypeScript union type
+	Values []SDKEnumValue
+}
+
+// SDKEnumValue is an allowed value and the Go constant holding it
+type SDKEnumValue struct {
+	Name  string
+	Value string
+}
+
+// SDKFilter is a list filter of the filter builder of a resource
+type SDKFilter struct {
+	Method   string
+	TSMethod string
+	Doc      string
+	JSON     string
+	GoType   string
+	TSType   string
+	Format   string // Go expression converting value into the query parameter
+	TSFormat string // TypeScript expression converting value into the query parameter
+}
+
+// SDKImport is a resource module imported by the TypeScript types of another
+type SDKImport struct {
+	Type string
+	File string
+}
+
+// SDKTest holds the resource the generated Go SDK tests exercise
+type SDKTest struct {
+	Resource *SDKResource
+	Payload  string // JSON of a valid request
+	Field    *SDKTestValue
+	Filter   *SDKTestFilter
+	Required *SDKField
+}
+
+// SDKTestValue is a field of the test payload and its value as a Go literal
+type SDKTestValue struct {
+	Name  string
+	Value string
+}
+
+// SDKTestFilter is a filter method, a value matching the test payload and one that does not
+type SDKTestFilter struct {
+	Name  string
+	JSON  string
+	Value string
+	Other string
+}
+
+// sdkFilterTypes maps the types of the filterable fields onto the Go and TypeScript types of
+// their filter method and the expressions formatting them
+var sdkFilterTypes = map[string]SDKFilter{
+	"string": {GoType: "string", TSType: "string", Format: "value", TSFormat: "value"},
+	"int":    {GoType: "int64", TSType: "number", Format: "strconv.FormatInt(value, 10)", TSFormat: "String(value)"},
+	"float":  {GoType: "float64", TSType: "number", Format: "strconv.FormatFloat(value, 'f', -1, 64)", TSFormat: "String(value)"},
+	"bool":   {GoType: "bool", TSType: "boolean", Format: "strconv.FormatBool(value)", TSFormat: "String(value)"},
+	"time":   {GoType: "time.Time", TSType: "Date", Format: "value.Format(time.RFC3339)", TSFormat: "value.toISOString()"},
+}
+
+// NewSDKGenerator creates a new SDK generator
+func NewSDKGenerator() *SDKGenerator {
+	return &SDKGenerator{files: NewSchemaGenerator(nil)}
+}
+
+// Generate generates the client SDK of the project in the given language, with a client for
+// every resource recorded in its API versions
+func (g *SDKGenerator) Generate(options SDKOptions) error {
+	g.options = options
+	if g.options.OutputPath == "" {
+		g.options.OutputPath = "."
+	}
+	if g.options.Lang == "" {
+		g.options.Lang = SDKLangGo
+	}
+	if g.options.Lang != SDKLangGo && g.options.Lang != SDKLangTypeScript {
+		return fmt.Errorf("unsupported SDK language %q, expected go or ts", g.options.Lang)
+	}
+	outputPath := g.options.OutputPath
+
+	ui.PrintStep(1, 3, "Reading project schemas...")
+	manifest, err := LoadManifest(outputPath)
+	if err != nil {
+		return fmt.Errorf("the SDK is generated from the schemas of the project, run the command in a project: %w", err)
+	}
+	schemas, err := projectSchemas(outputPath, manifest)
+	if err != nil {
+		return err
+	}
+	if len(schemas) == 0 {
+		return fmt.Errorf("the project has no resources, generate them with 'vibercode schema generate' first")
+	}
+	data, err := g.templateData(manifest, schemas)
+	if err != nil {
+		return err
+	}
+
+	ui.PrintStep(2, 3, "Generating SDK...")
+	var description string
+	if g.options.Lang == SDKLangTypeScript {
+		err = g.generateTypeScript(data)
+		description = "TypeScript"
+	} else {
+		err = g.generateGo(data)
+		description = "Go"
+	}
+	if err != nil {
+		return err
+	}
+
+	ui.PrintStep(3, 3, "Updating manifest...")
+	now := time.Now().Format(time.RFC3339)
+	manifest.History = append(manifest.History, VibercodeManifestEvent{
+		Type:        "generate_sdk",
+		Description: fmt.Sprintf("Generated the %s SDK of %d resources", description, len(data.Resources)),
+		Timestamp:   now,
+		CLI:         VibercodeManifestCLI{Version: "1.0.0", Command: "vibercode generate sdk --lang " + g.options.Lang},
+	})
+	manifest.UpdatedAt = now
+	if err := SaveManifest(outputPath, manifest); err != nil {
+		return err
+	}
+
+	ui.PrintSuccess(description + " SDK generated successfully!")
+	if g.options.Lang == SDKLangTypeScript {
+		ui.PrintInfo("Build the SDK with npm install && npm run build in sdk/typescript, it runs on Node 18+ and browsers")
+	} else {
+		ui.PrintInfo("Run go test ./pkg/sdk to test the SDK against the handlers of the project")
+	}
+	ui.PrintInfo("Regenerate the SDK after changing a schema, the SDK mirrors the latest API version of each resource")
+	return nil
+}
+
+// generateGo generates the Go SDK in pkg/sdk, the test serves the handlers of the project
+func (g *SDKGenerator) generateGo(data *SDKTemplateData) error {
+	type file struct {
+		template string
+		data     interface{}
+		path     string
+	}
+	dir := filepath.Join("pkg", "sdk")
+	files := []file{
+		{templates.SDKClientTemplate, data, filepath.Join(dir, "client.go")},
+		{templates.SDKErrorsTemplate, data, filepath.Join(dir, "errors.go")},
+	}
+	for _, resource := range data.Resources {
+		files = append(files, file{templates.SDKResourceTemplate, resource, filepath.Join(dir, resource.Names.SnakeCase+".go")})
+	}
+	if data.Test != nil {
+		files = append(files, file{templates.SDKTestTemplate, data, filepath.Join(dir, "sdk_test.go")})
+	}
+	for _, file := range files {
+		if err := g.files.generateGoFile(file.template, file.data, filepath.Join(g.options.OutputPath, file.path)); err != nil {
+			return err
+		}
+		ui.PrintFileCreated(file.path)
+	}
+	return nil
+}
+
+// generateTypeScript generates the TypeScript SDK in sdk/typescript, a package without
+// dependencies built on fetch
+func (g *SDKGenerator) generateTypeScript(data *SDKTemplateData) error {
+	type file struct {
+		template string
+		data     interface{}
+		path     string
+	}
+	dir := filepath.Join("sdk", "typescript")
+	files := []file{
+		{templates.SDKPackageJSONTemplate, data, filepath.Join(dir, "package.json")},
+		{templates.SDKTSConfigTemplate, data, filepath.Join(dir, "tsconfig.json")},
+		{templates.SDKTSClientTemplate, data, filepath.Join(dir, "src", "client.ts")},
+		{templates.SDKTSErrorsTemplate, data, filepath.Join(dir, "src", "errors.ts")},
+		{templates.SDKTSIndexTemplate, data, filepath.Join(dir, "src", "index.ts")},
+	}
+	for _, resource := range data.Resources {
+		files = append(files, file{templates.SDKTSResourceTemplate, resource, filepath.Join(dir, "src", resource.Names.KebabCase+".ts")})
+	}
+	for _, file := range files {
+		if err := g.files.generateFile(file.template, file.data, filepath.Join(g.options.OutputPath, file.path)); err != nil {
+			return err
+		}
+		ui.PrintFileCreated(file.path)
+	}
+	return nil
+}
+
+// templateData resolves the module and HTTP framework of the project and the clients of its
+// resources, each calling the latest API version that serves it
+func (g *SDKGenerator) templateData(manifest *VibercodeManifest, schemas []*models.ResourceSchema) (*SDKTemplateData, error) {
+	name := manifest.Name
+	if name == "" {
+		name = filepath.Base(absPath(g.options.OutputPath))
+	}
+	data := &SDKTemplateData{
+		Title:  strings.Title(strings.ReplaceAll(name, "-", " ")),
+		Name:   toKebabCase(name),
+		Module: g.options.Module,
+	}
+	if data.Module == "" {
+		data.Module = manifest.Module
+	}
+	if data.Module == "" {
+		data.Module = readGoModule(g.options.OutputPath)
+	}
+	if data.Module == "" && g.options.Lang == SDKLangGo {
+		return nil, fmt.Errorf("could not find the Go module of %s, pass --module", g.options.OutputPath)
+	}
+	framework := g.options.HTTP
+	if framework == "" {
+		framework = manifest.HTTPFramework
+	}
+	data.HTTP = newHTTPDialect(framework)
+
+	versions := make(map[string]string)
+	for _, version := range manifest.APIVersions {
+		for _, resource := range version.Resources {
+			versions[resource.Name] = version.Version
+		}
+	}
+	targets := make(map[string]*models.NamingConventions, len(schemas))
+	for _, schema := range schemas {
+		targets[schema.Name] = schema.Names
+	}
+
+	byVersion := make(map[string]int)
+	for _, schema := range schemas {
+		resource := newSDKResource(schema, versions[schema.Name], targets)
+		data.Resources = append(data.Resources, resource)
+		data.Money = data.Money || resource.Money
+		data.Geo = data.Geo || resource.Geo
+
+		i, ok := byVersion[resource.Version]
+		if !ok {
+			i = len(data.Versions)
+			byVersion[resource.Version] = i
+			data.Versions = append(data.Versions, SDKVersion{Version: resource.Version})
+		}
+		data.Versions[i].Resources = append(data.Versions[i].Resources, resource)
+
+		// The tests exercise the resource with the most request fields
+		if test := newSDKTest(resource); test != nil && (data.Test == nil || len(resource.Request) > len(data.Test.Resource.Request)) {
+			data.Test = test
+		}
+	}
+	return data, nil
+}
+
+// newSDKResource describes the types, filters and endpoints of a resource in an API version
+func newSDKResource(schema *models.ResourceSchema, version string, targets map[string]*models.NamingConventions) *SDKResource {
+	if version == "" {
+		version = "v1"
+	}
+	resource := &SDKResource{
+		Names:   schema.Names,
+		Version: version,
+		Path:    "/" + version + "/" + schema.Names.KebabPlural,
+	}
+	imported := make(map[string]bool)
+
+	for i := range schema.Fields {
+		field := &schema.Fields[i]
+		sdkField, ok := newSDKField(field, targets)
+		if !ok {
+			continue
+		}
+		resource.Money = resource.Money || sdkField.kind == "money"
+		resource.Geo = resource.Geo || field.IsGeo()
+
+		if isRelationField(field) {
+			if target, ok := targets[field.Relation.Target]; ok && target.PascalCase != schema.Names.PascalCase && !imported[target.PascalCase] {
+				imported[target.PascalCase] = true
+				resource.Imports = append(resource.Imports, SDKImport{Type: target.PascalCase, File: target.KebabCase})
+			}
+			if field.Relation.Populate {
+				resource.Includes = append(resource.Includes, sdkField.JSON)
+			}
+		}
+
+		if sdkField.kind == "enum" {
+			enum := SDKEnum{Field: sdkField.Name, Type: schema.Names.PascalCase + sdkField.Name}
+			for _, value := range field.Validation.AllowedValues {
+				enum.Values = append(enum.Values, SDKEnumValue{
+					Name:  schema.Names.PascalCase + sdkField.Name + toPascalCase(toSnakeCase(value)),
+					Value: value,
+				})
+			}
+			resource.Enums = append(resource.Enums, enum)
+			// The TypeScript types accept the allowed values only
+			sdkField.TSType = enum.Type
+		}
+
+		// Secrets are write-only
+		if !field.IsSensitive() {
+			response := sdkField
+			response.Optional = isRelationField(field)
+			resource.Fields = append(resource.Fields, response)
+		}
+		// Relations are written through their foreign keys, uploads through their endpoints
+		if !isRelationField(field) && !field.IsUpload() {
+			request := sdkField
+			request.Optional = !field.Required
+			resource.Request = append(resource.Request, request)
+		}
+
+		if filter, ok := newSDKFilter(field, sdkField); ok {
+			resource.Filters = append(resource.Filters, filter)
+		}
+	}
+	return resource
+}
+
+// newSDKField maps a schema field onto the Go and TypeScript types of its JSON value, false
+// for fields without a JSON value
+func newSDKField(field *models.SchemaField, targets map[string]*models.NamingConventions) (SDKField, bool) {
+	sdkField := SDKField{
+		Name:   toPascalCase(field.Name),
+		JSON:   toSnakeCase(field.Name),
+		kind:   fieldKind(field),
+		schema: field,
+	}
+
+	if field.IsGeo() {
+		sdkField.GoType, sdkField.TSType, sdkField.OmitEmpty = "*Coordinates", "Coordinates | null", true
+		return sdkField, true
+	}
+	if isRelationField(field) {
+		target, ok := targets[field.Relation.Target]
+		switch {
+		case !ok:
+			sdkField.GoType, sdkField.TSType = "json.RawMessage", "unknown"
+		case field.Type == "relation_array":
+			sdkField.GoType, sdkField.TSType = "[]*"+target.PascalCase, target.PascalCase+"[]"
+		default:
+			sdkField.GoType, sdkField.TSType = "*"+target.PascalCase, target.PascalCase+" | null"
+		}
+		sdkField.OmitEmpty = true
+		return sdkField, true
+	}
+
+	switch sdkField.kind {
+	case "secret", "enum", "uuid", "string":
+		sdkField.GoType, sdkField.TSType = "string", "string"
+	case "money":
+		sdkField.GoType, sdkField.TSType, sdkField.OmitEmpty = "*Money", "Money", true
+	case "int":
+		sdkField.GoType, sdkField.TSType = "int64", "number"
+	case "float":
+		sdkField.GoType, sdkField.TSType = "float64", "number"
+	case "bool":
+		sdkField.GoType, sdkField.TSType = "bool", "boolean"
+	case "time":
+		// RFC 3339 strings in TypeScript, JSON has no dates
+		sdkField.GoType, sdkField.TSType = "time.Time", "string"
+	case "json":
+		sdkField.GoType, sdkField.TSType, sdkField.OmitEmpty = "json.RawMessage", "unknown", true
+	default:
+		return sdkField, false
+	}
+	return sdkField, true
+}
+
+// newSDKFilter describes the filter method of a field the list endpoint filters on
+func newSDKFilter(field *models.SchemaField, sdkField SDKField) (SDKFilter, bool) {
+	switch field.Type {
+	case "string", "text", "email", "url", "number", "integer", "float", "decimal", "boolean", "date", "datetime", "timestamp":
+	default:
+		return SDKFilter{}, false
+	}
+	filter, ok := sdkFilterTypes[sdkField.kind]
+	if !ok {
+		return SDKFilter{}, false
+	}
+	filter.Method = "Where" + sdkField.Name
+	filter.TSMethod = "where" + sdkField.Name
+	filter.JSON = sdkField.JSON
+	filter.Doc = "whose " + sdkField.JSON + " is value"
+	if sdkField.kind == "time" {
+		filter.Doc = "whose " + sdkField.JSON + " is on the day of value"
+	}
+	return filter, true
+}
+
+// newSDKTest builds a valid request of a resource for the tests of the Go SDK, nil when a
+// request field has no sample value
+func newSDKTest(resource *SDKResource) *SDKTest {
+	test := &SDKTest{Resource: resource}
+	var payload []string
+	for i, field := range resource.Request {
+		value, ok := handlerTestValue(field.schema)
+		if !ok {
+			return nil
+		}
+		payload = append(payload, fmt.Sprintf("%q:%s", field.JSON, value))
+
+		if field.kind != "string" {
+			continue
+		}
+		if test.Required == nil && field.schema.Required {
+			switch field.schema.Type {
+			case "string", "text", "email", "url":
+				test.Required = &resource.Request[i]
+			}
+		}
+		if test.Field == nil {
+			test.Field = &SDKTestValue{Name: field.Name, Value: value}
+			for _, filter := range resource.Filters {
+				if filter.JSON == field.JSON {
+					test.Filter = &SDKTestFilter{Name: filter.Method, JSON: filter.JSON, Value: value, Other: `"missing"`}
+				}
+			}
+		}
+	}
+	test.Payload = "{" + strings.Join(payload, ",") + "}"
+	return test
+}
@@ -0,0 +1,90 @@
+package generator
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vibercode/cli/internal/models"
+)
+
+func TestSDKGenerator(t *testing.T) {
+	assert.ErrorContains(t, NewSDKGenerator().Generate(SDKOptions{OutputPath: t.TempDir()}), "run the command in a project")
+
+	category := &models.ResourceSchema{
+		ID: "category-1", Name: "Category", DisplayName: "Category", Names: models.CreateResourceNames("Category"),
+		Fields: []models.SchemaField{
+			{Name: "title", Type: "string", DisplayName: "Title", Required: true},
+		},
+		Database: &models.DatabaseConfig{Provider: "postgres", TableName: "categories"},
+	}
+	product := newTestProductSchema()
+	product.Fields = append(product.Fields,
+		models.SchemaField{Name: "status", Type: "enum", DisplayName: "Status", Validation: &models.FieldValidation{AllowedValues: []string{"draft", "published"}}},
+		models.SchemaField{Name: "password", Type: "password", DisplayName: "Password"},
+		models.SchemaField{Name: "category_id", Type: "string", DisplayName: "Category ID"},
+		models.SchemaField{Name: "category", Type: "relation", DisplayName: "Category", Relation: &models.RelationConfig{Type: "many_to_one", Target: "Category", ForeignKey: "category_id", Populate: true}},
+	)
+	gen := NewSchemaGenerator(newMemorySchemaStorage(category, product)).WithHTTPFramework(models.HTTPEcho)
+	dir := generateTestProject(t, gen, "postgres", category, product)
+	assert.ErrorContains(t, NewSDKGenerator().Generate(SDKOptions{OutputPath: dir, Lang: "python"}), "unsupported SDK language")
+
+	require.NoError(t, NewSDKGenerator().Generate(SDKOptions{OutputPath: dir, HTTP: models.HTTPEcho}))
+	assertGeneratedFiles(t, dir,
+		generatedFile{path: "pkg/sdk/client.go"},
+		generatedFile{path: "pkg/sdk/errors.go"},
+		generatedFile{
+			path: "pkg/sdk/product.go",
+			contains: []string{
+				`ProductStatusPublished = "published"`,
+				"func (f *ProductFilter) WhereStock(value int64) *ProductFilter {",
+				"func (c *ProductClient) Get(ctx context.Context, id string, include ...string) (*Product, error) {",
+				`"/v1/products"+"/"+url.PathEscape(id)`,
+			},
+		},
+		generatedFile{path: "pkg/sdk/category.go"},
+		generatedFile{
+			path: "pkg/sdk/sdk_test.go",
+			contains: []string{
+				`v1 := router.Group("/api/v1")`,
+				"func TestProductValidationError(t *testing.T) {",
+				`sdk.NewProductFilter().WhereSku("sample-sku")`,
+			},
+		},
+	)
+
+	resource := readGeneratedFile(t, dir, "pkg/sdk/product.go")
+	assert.Regexp(t, `Category +\*Category +`+"`"+`json:"category,omitempty"`, resource)
+	assert.Regexp(t, `(?s)type ProductRequest struct \{.*Password +string.*\}\n\n// Values`, resource, "Secrets are written")
+	assert.NotRegexp(t, `(?s)type Product struct \{[^}]*Password`, resource, "Secrets are never read")
+
+	require.NoError(t, NewSDKGenerator().Generate(SDKOptions{OutputPath: dir, Lang: SDKLangTypeScript}))
+	assertGeneratedFiles(t, dir,
+		generatedFile{path: "sdk/typescript/package.json"},
+		generatedFile{path: "sdk/typescript/tsconfig.json"},
+		generatedFile{path: "sdk/typescript/src/client.ts"},
+		generatedFile{path: "sdk/typescript/src/errors.ts"},
+		generatedFile{path: "sdk/typescript/src/index.ts"},
+		generatedFile{
+			path: "sdk/typescript/src/product.ts",
+			contains: []string{
+				"import type { Category } from './category';",
+				"export type ProductStatus = 'draft' | 'published';",
+				"  category?: Category | null;",
+				"  status?: ProductStatus;",
+			},
+		},
+		generatedFile{path: "sdk/typescript/src/category.ts"},
+	)
+
+	typescript := readGeneratedFile(t, dir, "sdk/typescript/src/product.ts")
+	assert.Regexp(t, `(?s)export interface ProductRequest \{[^}]*  password\?: string;`, typescript, "Secrets are written")
+	assert.NotRegexp(t, `(?s)export interface Product \{[^}]*password`, typescript, "Secrets are never read")
+
+	manifest, err := LoadManifest(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "generate_sdk", manifest.History[len(manifest.History)-1].Type)
+
+	assertGoFilesParse(t, filepath.Join(dir, "pkg"))
+}
@@ -0,0 +1,153 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/vibercode/cli/internal/templates"
+	"github.com/vibercode/cli/pkg/ui"
+)
+
+// templateOverrideDir is the directory, in a project or the home directory of the user,
+// holding the <name>.tmpl files overriding the built-in schema templates
+var templateOverrideDir = filepath.Join(".vibercode", "templates")
+
+// Ejected templates start with a comment recording the checksum of the built-in template
+// they were copied from. The comment trims the newline following it, it is not rendered.
+const ejectHeaderFormat = "{{- /* vibercode:eject %s %s */ -}}\n"
+
+var ejectHeaderPattern = regexp.MustCompile(`^\{\{- /\* vibercode:eject (\S+) ([0-9a-f]+) \*/ -\}\}\n`)
+
+// TemplateOverride is a file overriding a built-in schema template
+type TemplateOverride struct {
+	Name     string
+	Path     string
+	Content  string
+	Outdated bool // The built-in template changed since the file was ejected
+}
+
+// SchemaTemplateNames returns the names of the built-in schema templates that can be overridden
+func SchemaTemplateNames() []string {
+	var names []string
+	for name := range templates.GetSchemaTemplates() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// templateOverrideDirs returns the directories searched for overrides, the project first
+func templateOverrideDirs(projectDir string) []string {
+	dirs := []string{filepath.Join(projectDir, templateOverrideDir)}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, templateOverrideDir))
+	}
+	return dirs
+}
+
+// builtinSchemaTemplate returns the built-in schema template name
+func builtinSchemaTemplate(name string) (string, error) {
+	builtin, ok := templates.GetSchemaTemplates()[name]
+	if !ok {
+		return "", fmt.Errorf("unknown template %q (available: %s)", name, strings.Join(SchemaTemplateNames(), ", "))
+	}
+	return builtin, nil
+}
+
+// templateChecksum identifies a version of a built-in template
+func templateChecksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:8])
+}
+
+// FindTemplateOverride returns the file overriding the built-in schema template name in the
+// project at projectDir, or else in the home directory of the user, nil when there is none
+func FindTemplateOverride(name, projectDir string) (*TemplateOverride, error) {
+	builtin, err := builtinSchemaTemplate(name)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dir := range templateOverrideDirs(projectDir) {
+		path := filepath.Join(dir, name+".tmpl")
+		content, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template override: %w", err)
+		}
+
+		override := &TemplateOverride{Name: name, Path: path, Content: string(content)}
+		// Templates written by hand have no checksum to compare with
+		if match := ejectHeaderPattern.FindStringSubmatch(override.Content); match != nil {
+			override.Outdated = match[2] != templateChecksum(builtin)
+		}
+		return override, nil
+	}
+	return nil, nil
+}
+
+// EjectTemplate copies the built-in schema template name to the templates directory of the
+// project at projectDir, or of the home directory of the user when global is set, and returns
+// the path of the copy. An existing override is only replaced when force is set.
+func EjectTemplate(name, projectDir string, global, force bool) (string, error) {
+	builtin, err := builtinSchemaTemplate(name)
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(projectDir, templateOverrideDir)
+	if global {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to find the home directory: %w", err)
+		}
+		dir = filepath.Join(home, templateOverrideDir)
+	}
+	path := filepath.Join(dir, name+".tmpl")
+	if _, err := os.Stat(path); err == nil && !force {
+		return "", fmt.Errorf("%s already exists, use --force to replace it with the built-in template", path)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create templates directory: %w", err)
+	}
+	content := fmt.Sprintf(ejectHeaderFormat, name, templateChecksum(builtin)) + builtin
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return "", fmt.Errorf("failed to write template: %w", err)
+	}
+	return path, nil
+}
+
+// schemaTemplate returns the schema template name used for the project at outputPath, the
+// built-in template unless the project or the user overrides it
+func (g *SchemaGenerator) schemaTemplate(name, outputPath string) (string, error) {
+	override, err := FindTemplateOverride(name, outputPath)
+	if err != nil {
+		return "", err
+	}
+	if override == nil {
+		return builtinSchemaTemplate(name)
+	}
+
+	// Report overrides that do not parse with their path rather than the file being generated
+	if _, err := template.New(name).Funcs(templates.SchemaHelperFunctions).Parse(override.Content); err != nil {
+		return "", fmt.Errorf("template override %s: %w", override.Path, err)
+	}
+	if override.Outdated && !g.warnedTemplates[override.Path] {
+		ui.PrintWarning(fmt.Sprintf("The built-in %s template changed since %s was ejected, compare it with `vibercode template eject %s --stdout`", name, override.Path, name))
+		if g.warnedTemplates == nil {
+			g.warnedTemplates = make(map[string]bool)
+		}
+		g.warnedTemplates[override.Path] = true
+	}
+	return override.Content, nil
+}
@@ -0,0 +1,64 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vibercode/cli/internal/templates"
+)
+
+func TestSchemaGenerator_TemplateOverrides(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := t.TempDir()
+	schema := newTestProductSchema()
+	generate := func() string {
+		gen := NewSchemaGenerator(newMemorySchemaStorage(schema))
+		require.NoError(t, gen.GenerateFromSchema(schema.ID, dir, "example.com/shop", "postgres"))
+		return readGeneratedFile(t, dir, "internal/services/product_service.go")
+	}
+	builtin := generate()
+
+	path, err := EjectTemplate("service", dir, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, ".vibercode", "templates", "service.tmpl"), path)
+	_, err = EjectTemplate("service", dir, false, false)
+	assert.Error(t, err, "Overrides are only replaced with force")
+	_, err = EjectTemplate("routes", dir, false, false)
+	assert.Error(t, err)
+	assert.Equal(t, builtin, generate(), "The eject header is not rendered")
+
+	// The project template takes precedence over the global one
+	_, err = EjectTemplate("service", dir, true, false)
+	require.NoError(t, err)
+	global := filepath.Join(home, ".vibercode", "templates", "service.tmpl")
+	require.NoError(t, os.WriteFile(global, []byte("package services\n\n// Global\n"), 0644))
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte(strings.Replace(string(content), "package services\n", "// Package services is customized\npackage services\n", 1)), 0644))
+	assert.Contains(t, generate(), "// Package services is customized\npackage services\n")
+
+	override, err := FindTemplateOverride("service", dir)
+	require.NoError(t, err)
+	assert.False(t, override.Outdated)
+
+	// Overrides ejected from another version of the built-in template are reported
+	require.NoError(t, os.WriteFile(path, []byte(strings.Replace(string(content), templateChecksum(templates.SchemaServiceTemplate), "0000000000000000", 1)), 0644))
+	override, err = FindTemplateOverride("service", dir)
+	require.NoError(t, err)
+	assert.True(t, override.Outdated)
+	assert.Equal(t, builtin, generate())
+
+	require.NoError(t, os.Remove(path))
+	assert.Contains(t, generate(), "// Global")
+
+	require.NoError(t, os.WriteFile(global, []byte("{{.Missing"), 0644))
+	gen := NewSchemaGenerator(newMemorySchemaStorage(schema))
+	err = gen.GenerateFromSchema(schema.ID, dir, "example.com/shop", "postgres")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), global)
+}
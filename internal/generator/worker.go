@@ -0,0 +1,255 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/vibercode/cli/internal/models"
+	"github.com/vibercode/cli/internal/templates"
+	"github.com/vibercode/cli/pkg/ui"
+)
+
+// WorkerOptions contains configuration for worker generation. Empty options are read from
+// the .vibercode/manifest.vibe of the project.
+type WorkerOptions struct {
+	OutputPath string
+	Module     string
+	Database   string
+	HTTP       models.HTTPFramework
+}
+
+// WorkerGenerator generates the background job queue, its scheduler and worker entrypoint
+type WorkerGenerator struct {
+	options WorkerOptions
+	files   *SchemaGenerator
+}
+
+// WorkerTemplateData holds the data of the worker templates
+type WorkerTemplateData struct {
+	Name     string
+	Module   string
+	Provider string
+	Dialect  string // Dialect of the jobs SQL store
+	HTTP     *HTTPDialect
+}
+
+// NewWorkerGenerator creates a new worker generator
+func NewWorkerGenerator() *WorkerGenerator {
+	return &WorkerGenerator{files: NewSchemaGenerator(nil)}
+}
+
+// Generate generates the job queue of the project in the output path
+func (g *WorkerGenerator) Generate(options WorkerOptions) error {
+	g.options = options
+	if g.options.OutputPath == "" {
+		g.options.OutputPath = "."
+	}
+	outputPath := g.options.OutputPath
+
+	ui.PrintStep(1, 3, "Reading project configuration...")
+	manifest, _ := LoadManifest(outputPath)
+	data, err := g.templateData(manifest)
+	if err != nil {
+		return err
+	}
+
+	ui.PrintStep(2, 3, "Generating job queue and worker...")
+	type file struct {
+		template string
+		path     string
+		handler  bool
+	}
+	files := []file{
+		{templates.JobsPackageTemplate, filepath.Join("internal", "jobs", "jobs.go"), false},
+		{templates.JobsQueueTemplate, filepath.Join("internal", "jobs", "queue.go"), false},
+		{templates.JobsScheduleTemplate, filepath.Join("internal", "jobs", "schedule.go"), false},
+		{templates.JobsSQLStoreTemplate, filepath.Join("internal", "jobs", "sql.go"), false},
+		{templates.JobsMemoryStoreTemplate, filepath.Join("internal", "jobs", "memory.go"), false},
+		{templates.JobsTestTemplate, filepath.Join("internal", "jobs", "jobs_test.go"), false},
+		{templates.JobHandlerTemplate, filepath.Join("internal", "handlers", "job_handler.go"), true},
+		{templates.JobHandlerTestTemplate, filepath.Join("internal", "handlers", "job_handler_test.go"), true},
+		{templates.WorkerMainTemplate, filepath.Join("cmd", "worker", "main.go"), false},
+	}
+
+	// The handlers and schedules of the project are only written once
+	handlersPath := filepath.Join("internal", "jobs", "handlers.go")
+	if _, err := os.Stat(filepath.Join(outputPath, handlersPath)); os.IsNotExist(err) {
+		files = append(files, file{templates.JobsHandlersTemplate, handlersPath, false})
+	} else {
+		ui.PrintInfo(fmt.Sprintf("Keeping the job handlers of %s", handlersPath))
+	}
+
+	// The admin endpoints of frameworks other than Gin use the request and response helpers
+	helpersPath := filepath.Join("internal", "handlers", "http_helpers.go")
+	if _, err := os.Stat(layoutPath(outputPath, helpersPath)); !data.HTTP.Validates() && os.IsNotExist(err) {
+		files = append(files, file{templates.HTTPHelpersTemplate, helpersPath, true})
+	}
+	for _, problemFile := range problemFiles {
+		files = append(files, file{problemFile.template, problemFile.path, true})
+	}
+
+	for _, file := range files {
+		generate := g.files.generateGoFile
+		if file.handler {
+			generate = g.files.generateHandlerFile
+		}
+		if err := generate(file.template, data, filepath.Join(outputPath, file.path)); err != nil {
+			return err
+		}
+		ui.PrintFileCreated(file.path)
+	}
+
+	if err := g.files.writeSQLMigration(outputPath, "create_jobs", "Create the jobs table of the background job queue",
+		sqlJobsTable(data.Provider), "DROP TABLE IF EXISTS jobs;"); err != nil {
+		return fmt.Errorf("failed to generate jobs migration: %w", err)
+	}
+
+	ui.PrintStep(3, 3, "Generating worker deployment...")
+	if err := g.generateDeployment(data); err != nil {
+		return err
+	}
+
+	if manifest != nil {
+		now := time.Now().Format(time.RFC3339)
+		manifest.History = append(manifest.History, VibercodeManifestEvent{
+			Type:        "generate_worker",
+			Description: "Added the background job queue and worker",
+			Timestamp:   now,
+			CLI:         VibercodeManifestCLI{Version: "1.0.0", Command: "vibercode generate worker"},
+		})
+		manifest.UpdatedAt = now
+		if err := SaveManifest(outputPath, manifest); err != nil {
+			return err
+		}
+	}
+
+	ui.PrintSuccess("Background worker generated successfully!")
+	ui.PrintInfo("Register job handlers and schedules in internal/jobs/handlers.go and run the worker with go run ./cmd/worker")
+	ui.PrintInfo("Wire SetupJobRoutes with NewJobHandler(jobs.NewSQLStore(db, jobs." + data.Dialect + ")) behind admin authentication to inspect the queues")
+	return nil
+}
+
+// templateData resolves the module, database and HTTP framework of the project
+func (g *WorkerGenerator) templateData(manifest *VibercodeManifest) (*WorkerTemplateData, error) {
+	data := &WorkerTemplateData{
+		Name:     filepath.Base(absPath(g.options.OutputPath)),
+		Module:   g.options.Module,
+		Provider: g.options.Database,
+	}
+	framework := g.options.HTTP
+
+	if manifest != nil {
+		if manifest.Name != "" {
+			data.Name = manifest.Name
+		}
+		if data.Module == "" {
+			data.Module = manifest.Module
+		}
+		if data.Provider == "" && manifest.Database != nil {
+			data.Provider = manifest.Database.Type
+		}
+		if framework == "" {
+			framework = manifest.HTTPFramework
+		}
+	}
+	if data.Module == "" {
+		data.Module = readGoModule(g.options.OutputPath)
+	}
+	if data.Module == "" {
+		return nil, fmt.Errorf("could not find the Go module of %s, run the command in a project or pass --module", g.options.OutputPath)
+	}
+
+	switch data.Provider {
+	case "", "postgres", "supabase":
+		if data.Provider == "" {
+			data.Provider = "postgres"
+		}
+		data.Dialect = "Postgres"
+	case "mysql":
+		data.Dialect = "MySQL"
+	case "sqlite":
+		data.Dialect = "SQLite"
+	default:
+		return nil, fmt.Errorf("the job queue is stored in a SQL database, %s projects are not supported (postgres, mysql, sqlite)", data.Provider)
+	}
+	data.HTTP = newHTTPDialect(framework)
+	return data, nil
+}
+
+// generateDeployment writes the worker entries of the deployment templates
+func (g *WorkerGenerator) generateDeployment(data *WorkerTemplateData) error {
+	config := models.DeploymentConfig{
+		AppName:     data.Name,
+		Version:     "v1.0.0",
+		Environment: "production",
+		Namespace:   "default",
+		Replicas:    2,
+		WithWorker:  true,
+	}
+
+	files := []struct{ path, content string }{
+		{filepath.Join("deployment", "kubernetes", "worker-deployment.yaml"), templates.GetKubernetesTemplate("worker-deployment.yaml", config)},
+		{filepath.Join("deployment", "docker", "docker-compose.worker.yml"), templates.GetDockerTemplate("docker-compose.worker.yml", config)},
+	}
+	for _, file := range files {
+		if err := g.files.writeGeneratedFile(filepath.Join(g.options.OutputPath, file.path), []byte(file.content)); err != nil {
+			return err
+		}
+		ui.PrintFileCreated(file.path)
+	}
+	return nil
+}
+
+// sqlJobsTable returns the statements creating the jobs table
+func sqlJobsTable(provider string) string {
+	timestamp := sqlTimestampType(provider)
+	index := "CREATE INDEX IF NOT EXISTS"
+	if provider == "mysql" {
+		index = "CREATE INDEX"
+	}
+
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS jobs (
+    id VARCHAR(36) PRIMARY KEY,
+    queue VARCHAR(255) NOT NULL,
+    job_type VARCHAR(255) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    attempts INTEGER NOT NULL,
+    max_attempts INTEGER NOT NULL,
+    run_at %[1]s NOT NULL,
+    locked_until %[1]s,
+    last_error TEXT NOT NULL,
+    unique_key VARCHAR(255) UNIQUE,
+    created_at %[1]s NOT NULL,
+    updated_at %[1]s NOT NULL,
+    finished_at %[1]s
+);
+
+%[2]s idx_jobs_due ON jobs (queue, status, run_at);
+%[2]s idx_jobs_created ON jobs (created_at);`, timestamp, index)
+}
+
+// readGoModule returns the module declared in the go.mod of dir, empty without one
+func readGoModule(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "module ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "module"))
+		}
+	}
+	return ""
+}
+
+// absPath returns the absolute form of a path, the path itself when it can't be resolved
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
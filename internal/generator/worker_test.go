@@ -0,0 +1,86 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vibercode/cli/internal/models"
+	"github.com/vibercode/cli/internal/templates"
+)
+
+func TestWorkerGenerator(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, SaveManifest(dir, &VibercodeManifest{
+		Name:          "shop",
+		Module:        "example.com/shop",
+		Database:      &models.DatabaseProvider{Type: "postgres"},
+		HTTPFramework: models.HTTPChi,
+	}))
+	require.NoError(t, NewWorkerGenerator().Generate(WorkerOptions{OutputPath: dir}))
+
+	assertGeneratedFiles(t, dir,
+		generatedFile{path: "internal/jobs/jobs.go"},
+		generatedFile{path: "internal/jobs/queue.go"},
+		generatedFile{path: "internal/jobs/schedule.go"},
+		generatedFile{path: "internal/jobs/sql.go", contains: []string{"FOR UPDATE SKIP LOCKED"}},
+		generatedFile{path: "internal/jobs/memory.go"},
+		generatedFile{path: "internal/jobs/handlers.go"},
+		generatedFile{path: "internal/jobs/jobs_test.go"},
+		generatedFile{path: "internal/handlers/job_handler.go", contains: []string{`r.Post("/jobs/{id}/retry", handler.Retry)`}},
+		generatedFile{path: "internal/handlers/job_handler_test.go"},
+		generatedFile{
+			path:     "cmd/worker/main.go",
+			contains: []string{"jobs.NewSQLStore(db, jobs.Postgres)", `"example.com/shop/pkg/database"`},
+		},
+		generatedFile{
+			path:     "deployment/kubernetes/worker-deployment.yaml",
+			contains: []string{"name: shop-worker", `command: ["./worker"]`},
+		},
+		generatedFile{path: "deployment/docker/docker-compose.worker.yml"},
+		generatedFile{path: "migrations/*_create_jobs.sql", contains: []string{"run_at TIMESTAMPTZ NOT NULL,"}},
+	)
+
+	manifest, err := LoadManifest(dir)
+	require.NoError(t, err)
+	require.NotEmpty(t, manifest.History)
+	assert.Equal(t, "generate_worker", manifest.History[len(manifest.History)-1].Type)
+
+	assertGoFilesParse(t, dir)
+
+	// The job handlers belong to the project once generated
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "internal", "jobs", "handlers.go"), []byte("package jobs\n"), 0644))
+	require.NoError(t, NewWorkerGenerator().Generate(WorkerOptions{OutputPath: dir}))
+	assert.Equal(t, "package jobs\n", readGeneratedFile(t, dir, "internal/jobs/handlers.go"))
+	assertGeneratedFiles(t, dir, generatedFile{path: "migrations/*_create_jobs.sql"})
+
+	// Without a manifest the module comes from go.mod
+	sqliteDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(sqliteDir, "go.mod"), []byte("module example.com/notes\n\ngo 1.21\n"), 0644))
+	require.NoError(t, NewWorkerGenerator().Generate(WorkerOptions{OutputPath: sqliteDir, Database: "sqlite"}))
+	assertGeneratedFiles(t, sqliteDir, generatedFile{
+		path:     "cmd/worker/main.go",
+		contains: []string{"jobs.NewSQLStore(db, jobs.SQLite)", `"example.com/notes/internal/jobs"`},
+	})
+
+	// The queue is stored in SQL
+	mongoDir := t.TempDir()
+	err = NewWorkerGenerator().Generate(WorkerOptions{OutputPath: mongoDir, Module: "example.com/shop", Database: "mongodb"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "mongodb projects are not supported")
+}
+
+func TestDeploymentTemplates_Worker(t *testing.T) {
+	config := models.DeploymentConfig{AppName: "shop", Port: 8080, Environment: "production", Replicas: 2, MultiStage: true}
+	assert.NotContains(t, config.GetKubernetesFiles(), "worker-deployment.yaml")
+	assert.NotContains(t, templates.GetDockerTemplate("docker-compose.yml", config), "shop-worker")
+
+	config.WithWorker = true
+	assert.Contains(t, config.GetKubernetesFiles(), "worker-deployment.yaml")
+	assert.Contains(t, templates.GetDockerTemplate("docker-compose.yml", config), "  shop-worker:\n")
+	assert.Contains(t, templates.GetDockerTemplate("docker-compose.production.yml", config), "  shop-worker:\n")
+	assert.Contains(t, templates.GetDockerTemplate("Dockerfile.multi-stage", config), "-o worker ./cmd/worker")
+	assert.Contains(t, templates.GetKubernetesTemplate("worker-deployment.yaml", config), "app: shop-worker")
+}
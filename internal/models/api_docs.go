@@ -499,7 +499,7 @@ func GetGoTypeForOpenAPI(fieldType FieldType) (string, string) {
 	case FieldTypeFloat:
 		return "number", "float"
 	case FieldTypeCurrency:
-		return "number", "double"
+		return "object", ""
 	case FieldTypeBoolean:
 		return "boolean", ""
 	case FieldTypeDate:
@@ -574,6 +574,22 @@ func GenerateSchemaFromField(field Field) *SchemaObject {
 			},
 			Required: []string{"latitude", "longitude"},
 		}
+	case FieldTypeCurrency:
+		// Amounts are decimal strings, numbers would lose their precision
+		schema = &SchemaObject{
+			Type: "object",
+			Properties: map[string]*SchemaObject{
+				"amount": {
+					Type:    "string",
+					Pattern: `^-?[0-9]+(\.[0-9]+)?$`,
+				},
+				"currency": {
+					Type:    "string",
+					Pattern: "^[A-Z]{3}$",
+				},
+			},
+			Required: []string{"amount", "currency"},
+		}
 	}
 	
 	// Set default value
@@ -613,7 +629,10 @@ func GenerateExampleFromField(field Field) interface{} {
 	case FieldTypeFloat:
 		return 3.14
 	case FieldTypeCurrency:
-		return 99.99
+		return map[string]interface{}{
+			"amount":   "99.99",
+			"currency": "USD",
+		}
 	case FieldTypeBoolean:
 		return true
 	case FieldTypeDate:
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DataLayer identifies the data access library generated repositories are written with
+type DataLayer string
+
+const (
+	DataLayerGORM DataLayer = "gorm"
+	DataLayerSQLC DataLayer = "sqlc"
+	DataLayerSQLX DataLayer = "sqlx"
+	DataLayerPGX  DataLayer = "pgx"
+)
+
+// DefaultDataLayer is used when no data layer is selected
+const DefaultDataLayer = DataLayerGORM
+
+// SupportedDataLayers returns all supported data layers
+func SupportedDataLayers() []string {
+	return []string{
+		string(DataLayerGORM),
+		string(DataLayerSQLC),
+		string(DataLayerSQLX),
+		string(DataLayerPGX),
+	}
+}
+
+// ParseDataLayer parses a data layer name, an empty name selects the default data layer
+func ParseDataLayer(name string) (DataLayer, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return DefaultDataLayer, nil
+	}
+
+	layer := DataLayer(name)
+	if !layer.IsValid() {
+		return "", fmt.Errorf("unsupported data layer %q (supported: %s)", name, strings.Join(SupportedDataLayers(), ", "))
+	}
+	return layer, nil
+}
+
+// IsValid checks if the data layer is supported
+func (l DataLayer) IsValid() bool {
+	for _, layer := range SupportedDataLayers() {
+		if string(l) == layer {
+			return true
+		}
+	}
+	return false
+}
+
+// OrDefault returns the data layer, or the default data layer when it is not set
+func (l DataLayer) OrDefault() DataLayer {
+	if l == "" {
+		return DefaultDataLayer
+	}
+	return l
+}
+
+// GetDisplayName returns a human readable data layer name
+func (l DataLayer) GetDisplayName() string {
+	switch l.OrDefault() {
+	case DataLayerSQLC:
+		return "sqlc"
+	case DataLayerSQLX:
+		return "sqlx"
+	case DataLayerPGX:
+		return "pgx"
+	default:
+		return "GORM"
+	}
+}
+
+// SupportsProvider reports whether repositories of the data layer can target a database
+// provider. GORM supports every provider, sqlx every SQL provider and sqlc and pgx PostgreSQL only.
+func (l DataLayer) SupportsProvider(provider string) bool {
+	switch l.OrDefault() {
+	case DataLayerGORM:
+		return true
+	case DataLayerSQLX:
+		return provider == "postgres" || provider == "supabase" || provider == "mysql" || provider == "sqlite"
+	default:
+		return provider == "postgres" || provider == "supabase"
+	}
+}
+
+// SupportsFeature reports whether the schema feature can be generated on repositories of the
+// data layer. Bulk, export and GraphQL build on GORM repositories.
+func (l DataLayer) SupportsFeature(feature string) bool {
+	switch feature {
+	case FeatureBulk, FeatureExport, FeatureGraphQL:
+		return l.OrDefault() == DataLayerGORM
+	default:
+		return true
+	}
+}
+
+// ModulePaths returns the Go modules required by repositories of the data layer
+func (l DataLayer) ModulePaths() []string {
+	switch l.OrDefault() {
+	case DataLayerSQLX:
+		return []string{"github.com/jmoiron/sqlx"}
+	case DataLayerSQLC, DataLayerPGX:
+		return []string{"github.com/jackc/pgx/v5"}
+	default:
+		return []string{"gorm.io/gorm"}
+	}
+}
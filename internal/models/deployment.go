@@ -95,6 +95,9 @@ type DeploymentConfig struct {
 	WithMonitoring    bool             `json:"with_monitoring"`
 	WithLogging       bool             `json:"with_logging"`
 	
+	// Background worker generated by "generate worker" under cmd/worker
+	WithWorker        bool             `json:"with_worker"`
+	
 	// Full suite
 	FullSuite         bool             `json:"full_suite"`
 }
@@ -135,6 +138,9 @@ func (dc *DeploymentConfig) GetKubernetesFiles() []string {
 	if dc.WithHPA {
 		files = append(files, "hpa.yaml")
 	}
+	if dc.WithWorker {
+		files = append(files, "worker-deployment.yaml")
+	}
 	
 	return files
 }
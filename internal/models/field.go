@@ -78,8 +78,10 @@ func (f *Field) GoType() string {
 		return "string"
 	case FieldTypeNumber:
 		return "int"
-	case FieldTypeFloat, FieldTypeCurrency:
+	case FieldTypeFloat:
 		return "float64"
+	case FieldTypeCurrency:
+		return "money.Money" // Exact amount with its ISO 4217 currency
 	case FieldTypeBoolean:
 		return "bool"
 	case FieldTypeDate:
@@ -149,6 +151,11 @@ func (f *Field) buildGORMTags() string {
 		gormParts = append(gormParts, "foreignKey:"+f.Reference+"ID")
 	}
 	
+	// Amounts of money are stored in amount and currency columns
+	if f.Type == FieldTypeCurrency {
+		gormParts = append(gormParts, "embedded;embeddedPrefix:"+strcase.ToSnake(f.Name)+"_")
+	}
+	
 	// Unique constraint
 	if f.Unique {
 		gormParts = append(gormParts, "unique")
@@ -403,7 +410,7 @@ func (r *Resource) RequiredImports() []string {
 		case FieldTypeFile, FieldTypeImage:
 			imports = append(imports, "mime/multipart")
 		case FieldTypeCurrency:
-			imports = append(imports, "math")
+			imports = append(imports, r.Module+"/internal/money")
 		}
 		
 		// Check for pattern validation
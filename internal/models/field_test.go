@@ -39,7 +39,7 @@ func TestField_GoType(t *testing.T) {
 		{
 			name:     "Currency type",
 			field:    Field{Type: FieldTypeCurrency},
-			expected: "float64",
+			expected: "money.Money",
 		},
 		{
 			name:     "Boolean type",
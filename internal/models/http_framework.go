@@ -0,0 +1,123 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HTTPFramework identifies the HTTP framework generated handlers, routes and middleware target
+type HTTPFramework string
+
+const (
+	HTTPGin    HTTPFramework = "gin"
+	HTTPStdlib HTTPFramework = "stdlib"
+	HTTPChi    HTTPFramework = "chi"
+	HTTPEcho   HTTPFramework = "echo"
+	HTTPFiber  HTTPFramework = "fiber"
+)
+
+// DefaultHTTPFramework is used when a project or manifest does not name a framework
+const DefaultHTTPFramework = HTTPGin
+
+// SupportedHTTPFrameworks returns all supported HTTP frameworks
+func SupportedHTTPFrameworks() []string {
+	return []string{
+		string(HTTPGin),
+		string(HTTPStdlib),
+		string(HTTPChi),
+		string(HTTPEcho),
+		string(HTTPFiber),
+	}
+}
+
+// ParseHTTPFramework parses a framework name, an empty name selects the default framework
+func ParseHTTPFramework(name string) (HTTPFramework, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	switch name {
+	case "":
+		return DefaultHTTPFramework, nil
+	case "net/http", "nethttp", "std":
+		return HTTPStdlib, nil
+	}
+
+	framework := HTTPFramework(name)
+	if !framework.IsValid() {
+		return "", fmt.Errorf("unsupported HTTP framework %q (supported: %s)", name, strings.Join(SupportedHTTPFrameworks(), ", "))
+	}
+	return framework, nil
+}
+
+// IsValid checks if the framework is supported
+func (f HTTPFramework) IsValid() bool {
+	for _, framework := range SupportedHTTPFrameworks() {
+		if string(f) == framework {
+			return true
+		}
+	}
+	return false
+}
+
+// OrDefault returns the framework, or the default framework when it is not set
+func (f HTTPFramework) OrDefault() HTTPFramework {
+	if f == "" {
+		return DefaultHTTPFramework
+	}
+	return f
+}
+
+// GetDisplayName returns a human readable framework name
+func (f HTTPFramework) GetDisplayName() string {
+	switch f.OrDefault() {
+	case HTTPStdlib:
+		return "net/http"
+	case HTTPChi:
+		return "Chi"
+	case HTTPEcho:
+		return "Echo"
+	case HTTPFiber:
+		return "Fiber"
+	default:
+		return "Gin"
+	}
+}
+
+// ModulePath returns the Go module of the framework, empty for the standard library
+func (f HTTPFramework) ModulePath() string {
+	switch f.OrDefault() {
+	case HTTPChi:
+		return "github.com/go-chi/chi/v5"
+	case HTTPEcho:
+		return "github.com/labstack/echo/v4"
+	case HTTPFiber:
+		return "github.com/gofiber/fiber/v2"
+	case HTTPStdlib:
+		return ""
+	default:
+		return "github.com/gin-gonic/gin"
+	}
+}
+
+// ModuleVersion returns the framework version required by generated projects
+func (f HTTPFramework) ModuleVersion() string {
+	switch f.OrDefault() {
+	case HTTPChi:
+		return "v5.1.0"
+	case HTTPEcho:
+		return "v4.12.0"
+	case HTTPFiber:
+		return "v2.52.5"
+	case HTTPStdlib:
+		return ""
+	default:
+		return "v1.9.1"
+	}
+}
+
+// GoVersion returns the minimum Go version of generated projects. The standard
+// library target relies on the method and wildcard patterns of Go 1.22 ServeMux.
+func (f HTTPFramework) GoVersion() string {
+	if f == HTTPStdlib {
+		return "1.22"
+	}
+	return "1.21"
+}
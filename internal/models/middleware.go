@@ -147,7 +147,7 @@ func (m *MiddlewareConfig) GetImports() []string {
 		)
 		
 	case CORSMiddleware:
-		imports = append(imports, "strings")
+		imports = append(imports, "fmt", "strings")
 		
 	case RateLimitMiddleware:
 		imports = append(imports, 
@@ -157,6 +157,7 @@ func (m *MiddlewareConfig) GetImports() []string {
 		if m.Options.UseRedis {
 			imports = append(imports, 
 				"github.com/go-redis/redis/v8",
+				"fmt",
 				"strconv",
 			)
 		}
@@ -0,0 +1,245 @@
+package models
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// ProjectLayout identifies the preset placing the packages of generated projects
+type ProjectLayout string
+
+const (
+	LayoutStandard  ProjectLayout = "standard"
+	LayoutClean     ProjectLayout = "clean"
+	LayoutHexagonal ProjectLayout = "hexagonal"
+	LayoutFlat      ProjectLayout = "flat"
+	LayoutFeature   ProjectLayout = "feature"
+)
+
+// DefaultProjectLayout is used when no layout is selected
+const DefaultProjectLayout = LayoutStandard
+
+// Packages of the standard layout that layouts place, named after their directory in internal
+const (
+	PackageModels       = "models"
+	PackageRepositories = "repositories"
+	PackageServices     = "services"
+	PackageHandlers     = "handlers"
+	PackageEvents       = "events"
+	PackageWebhooks     = "webhooks"
+	PackageGraphQL      = "graphql"
+)
+
+// LayoutPackages returns the packages of the standard layout that layouts place
+func LayoutPackages() []string {
+	return []string{PackageModels, PackageRepositories, PackageServices, PackageHandlers, PackageEvents, PackageWebhooks, PackageGraphQL}
+}
+
+// layoutDirs maps the packages of the standard layout to their directory in each layout.
+// Layouts packaging by feature split the repositories, services and handlers packages, their
+// packages hold the files shared by the resources.
+var layoutDirs = map[ProjectLayout]map[string]string{
+	LayoutStandard: {
+		PackageModels:       "internal/models",
+		PackageRepositories: "internal/repositories",
+		PackageServices:     "internal/services",
+		PackageHandlers:     "internal/handlers",
+		PackageEvents:       "internal/events",
+		PackageWebhooks:     "internal/webhooks",
+		PackageGraphQL:      "internal/graphql",
+	},
+	LayoutClean: {
+		PackageModels:       "internal/entity",
+		PackageRepositories: "internal/repository",
+		PackageServices:     "internal/usecase",
+		PackageHandlers:     "internal/controller",
+		PackageEvents:       "internal/events",
+		PackageWebhooks:     "internal/webhooks",
+		PackageGraphQL:      "internal/graphql",
+	},
+	LayoutHexagonal: {
+		PackageModels:       "internal/domain",
+		PackageRepositories: "internal/adapters/persistence",
+		PackageServices:     "internal/application",
+		PackageHandlers:     "internal/adapters/rest",
+		PackageEvents:       "internal/events",
+		PackageWebhooks:     "internal/webhooks",
+		PackageGraphQL:      "internal/graphql",
+	},
+	LayoutFlat: {
+		PackageModels:       "internal/app",
+		PackageRepositories: "internal/app",
+		PackageServices:     "internal/app",
+		PackageHandlers:     "internal/app",
+		PackageEvents:       "internal/app",
+		PackageWebhooks:     "internal/app",
+		PackageGraphQL:      "internal/app",
+	},
+	LayoutFeature: {
+		PackageModels:       "internal/common",
+		PackageRepositories: "internal/repositories",
+		PackageServices:     "internal/services",
+		PackageHandlers:     "internal/handlers",
+		PackageEvents:       "internal/events",
+		PackageWebhooks:     "internal/webhooks",
+		PackageGraphQL:      "internal/graphql",
+	},
+}
+
+// portsDirs maps the layouts keeping the repository and service interfaces in a package of
+// their own, which the application and the adapters depend on, to the directory of the package
+var portsDirs = map[ProjectLayout]string{
+	LayoutHexagonal: "internal/ports",
+}
+
+// FeaturePackages are the packages of the standard layout whose resource files layouts
+// packaging by feature move to the package of their resource
+func FeaturePackages() []string {
+	return []string{PackageRepositories, PackageServices, PackageHandlers}
+}
+
+// reservedPackages are the packages generated in internal besides the packages of the layouts,
+// which the package of a resource must not be named after
+var reservedPackages = []string{
+	"admin", "apperrors", "auth", "blob", "bulk", "cache", "config", "db", "export", "geo",
+	"jobs", "middleware", "money", "observability", "routes", "rpc", "search",
+}
+
+// SupportedProjectLayouts returns all supported layouts
+func SupportedProjectLayouts() []string {
+	return []string{
+		string(LayoutStandard),
+		string(LayoutClean),
+		string(LayoutHexagonal),
+		string(LayoutFlat),
+		string(LayoutFeature),
+	}
+}
+
+// ParseProjectLayout parses a layout name, an empty name selects the default layout
+func ParseProjectLayout(name string) (ProjectLayout, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return DefaultProjectLayout, nil
+	}
+
+	layout := ProjectLayout(name)
+	if !layout.IsValid() {
+		return "", fmt.Errorf("unsupported project layout %q (supported: %s)", name, strings.Join(SupportedProjectLayouts(), ", "))
+	}
+	return layout, nil
+}
+
+// IsValid checks if the layout is supported
+func (l ProjectLayout) IsValid() bool {
+	_, ok := layoutDirs[l]
+	return ok
+}
+
+// OrDefault returns the layout, or the default layout when it is not set
+func (l ProjectLayout) OrDefault() ProjectLayout {
+	if l == "" {
+		return DefaultProjectLayout
+	}
+	return l
+}
+
+// GetDisplayName returns a human readable layout name
+func (l ProjectLayout) GetDisplayName() string {
+	switch l.OrDefault() {
+	case LayoutClean:
+		return "Clean architecture"
+	case LayoutHexagonal:
+		return "Hexagonal"
+	case LayoutFlat:
+		return "Flat"
+	case LayoutFeature:
+		return "Package by feature"
+	default:
+		return "Standard"
+	}
+}
+
+// GetDescription describes where the layout places the packages
+func (l ProjectLayout) GetDescription() string {
+	switch l.OrDefault() {
+	case LayoutClean:
+		return "Entities, use cases, repositories and controllers"
+	case LayoutHexagonal:
+		return "Domain and application core, ports, persistence and REST adapters"
+	case LayoutFlat:
+		return "Models, repositories, services and handlers in a single app package"
+	case LayoutFeature:
+		return "Repository, service and handler of each resource in its package, models in a common package"
+	default:
+		return "Models, repositories, services and handlers packages"
+	}
+}
+
+// Layered reports whether the layout keeps models, repositories, services and handlers in
+// separate packages. The admin package imports the services and is mounted by the handlers,
+// which a single package could not do without an import cycle. The events, webhooks and
+// GraphQL packages are placed with the layers instead.
+func (l ProjectLayout) Layered() bool {
+	return l.OrDefault() != LayoutFlat
+}
+
+// Dir returns the slash separated directory, relative to the project, of a package of the
+// standard layout, e.g. internal/domain for models in the hexagonal layout
+func (l ProjectLayout) Dir(pkg string) string {
+	dirs, ok := layoutDirs[l.OrDefault()]
+	if !ok {
+		dirs = layoutDirs[DefaultProjectLayout]
+	}
+	return dirs[pkg]
+}
+
+// PortsDir returns the slash separated directory, relative to the project, of the package
+// holding the repository and service interfaces, empty when the layout keeps them with their
+// implementation
+func (l ProjectLayout) PortsDir() string {
+	return portsDirs[l.OrDefault()]
+}
+
+// PackageName returns the name of the package a package of the standard layout is placed in
+func (l ProjectLayout) PackageName(pkg string) string {
+	return path.Base(l.Dir(pkg))
+}
+
+// ByFeature reports whether the layout packages the repository, service and handler of each
+// resource by feature. The models of the resources reference each other through their
+// relations, which packages by resource could not do without import cycles, so they stay in
+// a common package with the loaders of the included relations.
+func (l ProjectLayout) ByFeature() bool {
+	return l.OrDefault() == LayoutFeature
+}
+
+// ResourceDir returns the slash separated directory, relative to the project, of the package
+// of a resource in layouts packaging by feature, e.g. internal/orderitem, empty in the others
+func (l ProjectLayout) ResourceDir(names *NamingConventions) string {
+	if !l.ByFeature() {
+		return ""
+	}
+	return "internal/" + strings.ReplaceAll(names.SnakeCase, "_", "")
+}
+
+// CheckResource checks that the package of a resource does not collide with a package of the
+// project in layouts packaging by feature
+func (l ProjectLayout) CheckResource(names *NamingConventions) error {
+	dir := l.ResourceDir(names)
+	if dir == "" {
+		return nil
+	}
+	name := path.Base(dir)
+	for _, pkg := range LayoutPackages() {
+		if l.Dir(pkg) == dir {
+			return fmt.Errorf("the package of %s would be the %s package of the %s layout, rename the resource", names.PascalCase, pkg, l)
+		}
+	}
+	if slices.Contains(reservedPackages, name) {
+		return fmt.Errorf("the package of %s would be the generated %s package, rename the resource", names.PascalCase, name)
+	}
+	return nil
+}
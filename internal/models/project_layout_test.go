@@ -0,0 +1,72 @@
+package models
+
+import "testing"
+
+func TestParseProjectLayout(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected ProjectLayout
+		wantErr  bool
+	}{
+		{name: "", expected: LayoutStandard},
+		{name: "hexagonal", expected: LayoutHexagonal},
+		{name: " Clean ", expected: LayoutClean},
+		{name: "onion", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		layout, err := ParseProjectLayout(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseProjectLayout(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if layout != tt.expected {
+			t.Errorf("ParseProjectLayout(%q) = %v, want %v", tt.name, layout, tt.expected)
+		}
+	}
+}
+
+func TestProjectLayout_Dir(t *testing.T) {
+	if dir := LayoutHexagonal.Dir(PackageRepositories); dir != "internal/adapters/persistence" {
+		t.Errorf("Dir() = %v, want internal/adapters/persistence", dir)
+	}
+	if name := LayoutClean.PackageName(PackageServices); name != "usecase" {
+		t.Errorf("PackageName() = %v, want usecase", name)
+	}
+	if dir := ProjectLayout("").Dir(PackageModels); dir != "internal/models" {
+		t.Errorf("Dir() = %v, want internal/models", dir)
+	}
+	if dir := LayoutHexagonal.PortsDir(); dir != "internal/ports" {
+		t.Errorf("PortsDir() = %v, want internal/ports", dir)
+	}
+	if dir := LayoutClean.PortsDir(); dir != "" {
+		t.Errorf("PortsDir() = %v, want no ports package", dir)
+	}
+	if LayoutFlat.Layered() || !LayoutStandard.Layered() {
+		t.Errorf("only the flat layout merges the packages")
+	}
+}
+
+func TestProjectLayout_ResourceDir(t *testing.T) {
+	names := CreateResourceNames("Product")
+	if dir := LayoutFeature.ResourceDir(names); dir != "internal/product" {
+		t.Errorf("ResourceDir() = %v, want internal/product", dir)
+	}
+	if dir := LayoutStandard.ResourceDir(names); dir != "" {
+		t.Errorf("ResourceDir() = %v, want no resource package", dir)
+	}
+	if dir := LayoutFeature.Dir(PackageModels); dir != "internal/common" {
+		t.Errorf("Dir() = %v, want internal/common", dir)
+	}
+	if err := LayoutFeature.CheckResource(names); err != nil {
+		t.Errorf("CheckResource() error = %v", err)
+	}
+	for _, name := range []string{"Cache", "Handlers", "Common"} {
+		if err := LayoutFeature.CheckResource(CreateResourceNames(name)); err == nil {
+			t.Errorf("CheckResource(%s) should collide with a package of the project", name)
+		}
+	}
+	if err := LayoutStandard.CheckResource(CreateResourceNames("Cache")); err != nil {
+		t.Errorf("CheckResource() error = %v, resources have no package in the standard layout", err)
+	}
+}
@@ -19,6 +19,8 @@ type SchemaField struct {
 	UI           *FieldUI               `json:"ui,omitempty"`
 	Relation     *RelationConfig        `json:"relation,omitempty"`
 	Database     *DatabaseFieldConfig   `json:"database,omitempty"`
+	Search       *SearchFieldConfig     `json:"search,omitempty"`
+	Upload       *UploadFieldConfig     `json:"upload,omitempty"`
 	Frontend     *FrontendFieldConfig   `json:"frontend,omitempty"`
 	Metadata     map[string]interface{} `json:"metadata,omitempty"`
 }
@@ -64,6 +66,7 @@ type RelationConfig struct {
 	ForeignKey   string   `json:"foreign_key"`   // Foreign key field name
 	LocalKey     string   `json:"local_key"`     // Local key field name
 	PivotTable   string   `json:"pivot_table,omitempty"`
+	PivotFields  []SchemaField `json:"pivot_fields,omitempty"` // Attributes stored on many_to_many pivot rows
 	Cascade      bool     `json:"cascade,omitempty"`
 	Populate     bool     `json:"populate,omitempty"`
 	PopulateDepth int     `json:"populate_depth,omitempty"`
@@ -194,7 +197,16 @@ type GenerationOptions struct {
 	GenerateMocks    bool     `json:"generate_mocks"`
 	GenerateDocs     bool     `json:"generate_docs"`
 	GenerateFrontend bool     `json:"generate_frontend"`
-	Features         []string `json:"features,omitempty"` // "auth", "validation", "caching", etc.
+	Features         []string `json:"features,omitempty"` // "auth", "validation", "caching", "bulk", "export", "graphql", "grpc", "events", "search", etc.
+
+	// Feature configuration
+	Bulk   *BulkConfig   `json:"bulk,omitempty"`
+	Export *ExportConfig `json:"export,omitempty"`
+	GRPC   *GRPCConfig   `json:"grpc,omitempty"`
+	Events *EventsConfig `json:"events,omitempty"`
+	Cache  *CacheConfig  `json:"cache,omitempty"`
+	Search *SearchConfig `json:"search,omitempty"`
+	Admin  *AdminConfig  `json:"admin,omitempty"`
 }
 
 // DatabaseConfig contains database-specific configuration
@@ -299,9 +311,9 @@ func (f *SchemaField) GetGoType() string {
 		}
 		return "[]interface{}"
 	case "location", "coordinates":
-		return "*Location"
+		return "*Coordinates"
 	case "currency":
-		return "decimal.Decimal"
+		return "money.Money"
 	case "enum":
 		return "string" // Could be custom enum type
 	default:
@@ -315,6 +327,21 @@ func (f *SchemaField) GetGORMTag(dbProvider string) string {
 		f.Database = &DatabaseFieldConfig{}
 	}
 	
+	// Coordinates map onto a geography column or onto latitude and longitude columns
+	if f.IsGeo() {
+		return f.geoGORMTag(dbProvider)
+	}
+
+	// Amounts of money map onto amount and currency columns
+	if f.IsMoney() {
+		return f.moneyGORMTag()
+	}
+
+	// Related records are loaded by includes and the nested relation routes, not stored with the model
+	if f.Type == "relation" || f.Type == "relation_array" {
+		return "-"
+	}
+
 	var tags []string
 	
 	// Column name
@@ -376,16 +403,6 @@ func (f *SchemaField) GetGORMTag(dbProvider string) string {
 		tags = append(tags, fmt.Sprintf("default:%v", f.Database.Default))
 	}
 	
-	// Relations
-	if f.Type == "relation" && f.Relation != nil {
-		tags = append(tags, "foreignKey:"+f.Relation.ForeignKey)
-	}
-	
-	// Many-to-many
-	if f.Type == "relation_array" && f.Relation != nil && f.Relation.Type == "many_to_many" {
-		tags = append(tags, "many2many:"+f.Relation.PivotTable)
-	}
-	
 	return strings.Join(tags, ";")
 }
 
@@ -430,7 +447,7 @@ func (f *SchemaField) getPostgresType() string {
 	case "json", "mixed":
 		return "jsonb"
 	case "location", "coordinates":
-		return "geometry(Point,4326)"
+		return "geography(Point,4326)"
 	default:
 		return "text"
 	}
@@ -0,0 +1,419 @@
+package models
+
+import "strings"
+
+// Schema features that can be enabled through GenerationOptions.Features
+const (
+	FeatureBulk          = "bulk"
+	FeatureExport        = "export"
+	FeatureGraphQL       = "graphql"
+	FeatureGRPC          = "grpc"
+	FeatureEvents        = "events"
+	FeatureCaching       = "caching"
+	FeatureSearch        = "search"
+	FeatureWebhooks      = "webhooks"
+	FeatureObservability = "observability"
+)
+
+// Event publishers that can be generated next to the in-process and webhook publishers
+const (
+	EventPublisherNATS  = "nats"
+	EventPublisherKafka = "kafka"
+)
+
+// SensitiveFieldTypes lists field types that are never exposed through exports
+var SensitiveFieldTypes = []string{"password"}
+
+// DefaultBulkMaxBatchSize is the batch size limit used when none is configured
+const DefaultBulkMaxBatchSize = 500
+
+// BulkConfig contains configuration for generated batch endpoints
+type BulkConfig struct {
+	MaxBatchSize int      `json:"max_batch_size,omitempty"`
+	UpsertKeys   []string `json:"upsert_keys,omitempty"` // Natural key fields used by bulk upsert
+	Import       bool     `json:"import"`                // Generate the streaming NDJSON/CSV import endpoint
+}
+
+// DefaultBulkConfig returns the default bulk configuration
+func DefaultBulkConfig() *BulkConfig {
+	return &BulkConfig{
+		MaxBatchSize: DefaultBulkMaxBatchSize,
+		Import:       true,
+	}
+}
+
+// ExportConfig contains configuration for generated export endpoints
+type ExportConfig struct {
+	Fields  []string `json:"fields,omitempty"`  // Default columns, all exportable fields when empty
+	Exclude []string `json:"exclude,omitempty"` // Fields never exported in addition to sensitive types
+}
+
+// GRPCConfig contains configuration for generated gRPC services
+type GRPCConfig struct {
+	Package string `json:"package,omitempty"` // Protobuf package, derived from the module path when empty
+	Gateway bool   `json:"gateway"`           // Generate the grpc-gateway REST mapping
+}
+
+// EventsConfig contains configuration for generated domain events
+type EventsConfig struct {
+	Publishers []string `json:"publishers,omitempty"` // Broker publishers to generate: "nats", "kafka"
+}
+
+// Cache TTLs used when none are configured, in seconds
+const (
+	DefaultCacheTTL     = 300
+	DefaultCacheListTTL = 60
+)
+
+// CacheConfig contains configuration for generated read-through caching
+type CacheConfig struct {
+	TTL         int      `json:"ttl,omitempty"`         // Seconds single records stay cached
+	ListTTL     int      `json:"list_ttl,omitempty"`    // Seconds list and lookup results stay cached
+	Invalidates []string `json:"invalidates,omitempty"` // Resources whose cached reads include this resource, in addition to relations
+}
+
+// Full-text search weights, from the most to the least relevant as in PostgreSQL
+const (
+	SearchWeightA = "A"
+	SearchWeightB = "B"
+	SearchWeightC = "C"
+	SearchWeightD = "D"
+)
+
+// DefaultSearchLanguage is the text search configuration used when none is configured
+const DefaultSearchLanguage = "english"
+
+// SearchFieldConfig marks a field as matched by full-text search
+type SearchFieldConfig struct {
+	Weight string `json:"weight,omitempty"` // "A" to "D", defaults to "D"
+}
+
+// SearchConfig contains configuration for generated full-text search
+type SearchConfig struct {
+	Language string `json:"language,omitempty"` // Stemming language of the PostgreSQL, SQLite and MongoDB indexes
+}
+
+// Field types whose values are uploaded files kept in blob storage
+const (
+	FieldTypeFileUpload  = "file"
+	FieldTypeImageUpload = "image"
+)
+
+// DefaultUploadMaxSize is the size limit of uploaded files when none is configured, in bytes
+const DefaultUploadMaxSize = 10 << 20
+
+// DefaultImageTypes lists the MIME types accepted by image fields when none are configured
+var DefaultImageTypes = []string{"image/jpeg", "image/png", "image/gif"}
+
+// UploadFieldConfig contains configuration for the uploads of a file or image field
+type UploadFieldConfig struct {
+	MaxSize      int64            `json:"max_size,omitempty"`      // Size limit in bytes
+	AllowedTypes []string         `json:"allowed_types,omitempty"` // Accepted MIME types, "type/*" matches a whole type
+	Thumbnail    *ThumbnailConfig `json:"thumbnail,omitempty"`     // Thumbnail of image fields
+}
+
+// ThumbnailConfig contains the bounding box of generated image thumbnails, in pixels
+type ThumbnailConfig struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// Actions of the generated admin panel roles can be required for
+const (
+	AdminActionView   = "view"
+	AdminActionCreate = "create"
+	AdminActionEdit   = "edit"
+	AdminActionDelete = "delete"
+)
+
+// AdminConfig contains configuration for the pages of a resource in the generated admin panel
+type AdminConfig struct {
+	Group string `json:"group,omitempty"` // Menu section listing the resource
+	// Roles allowed to perform an action, by action. Actions without roles are open to
+	// every user of the panel.
+	Roles map[string][]string `json:"roles,omitempty"`
+}
+
+// FieldTypeLocation is an alias of the coordinates field type
+const FieldTypeLocation = "location"
+
+// IsSensitive reports whether the field holds secrets that must not leave the API
+func (f *SchemaField) IsSensitive() bool {
+	for _, t := range SensitiveFieldTypes {
+		if f.Type == t {
+			return true
+		}
+	}
+	return false
+}
+
+// HasFeature reports whether a generation feature is enabled for the schema
+func (s *ResourceSchema) HasFeature(feature string) bool {
+	if s.Options == nil {
+		return false
+	}
+	for _, f := range s.Options.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// GetBulkConfig returns the bulk configuration with defaults applied
+func (s *ResourceSchema) GetBulkConfig() *BulkConfig {
+	config := DefaultBulkConfig()
+	if s.Options == nil || s.Options.Bulk == nil {
+		return config
+	}
+
+	custom := *s.Options.Bulk
+	if custom.MaxBatchSize <= 0 {
+		custom.MaxBatchSize = config.MaxBatchSize
+	}
+	return &custom
+}
+
+// GetUpsertKeyFields returns the fields forming the natural key used for upserts.
+// Explicit UpsertKeys take precedence, otherwise unique fields are used.
+func (s *ResourceSchema) GetUpsertKeyFields() []SchemaField {
+	var keys []SchemaField
+
+	if s.Options != nil && s.Options.Bulk != nil && len(s.Options.Bulk.UpsertKeys) > 0 {
+		for _, name := range s.Options.Bulk.UpsertKeys {
+			for _, field := range s.Fields {
+				if field.Name == name {
+					keys = append(keys, field)
+				}
+			}
+		}
+		return keys
+	}
+
+	for _, field := range s.Fields {
+		if field.Database != nil && field.Database.Unique {
+			keys = append(keys, field)
+		}
+	}
+	return keys
+}
+
+// GetExportConfig returns the export configuration, never nil
+func (s *ResourceSchema) GetExportConfig() *ExportConfig {
+	if s.Options == nil || s.Options.Export == nil {
+		return &ExportConfig{}
+	}
+	return s.Options.Export
+}
+
+// GetExportableFields returns the fields that may be exported, skipping sensitive,
+// excluded and relation fields
+func (s *ResourceSchema) GetExportableFields() []SchemaField {
+	excluded := make(map[string]bool)
+	for _, name := range s.GetExportConfig().Exclude {
+		excluded[name] = true
+	}
+
+	var fields []SchemaField
+	for _, field := range s.Fields {
+		if field.IsSensitive() || excluded[field.Name] {
+			continue
+		}
+		if field.Type == "relation" || field.Type == "relation_array" {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// GetGRPCConfig returns the gRPC configuration, never nil
+func (s *ResourceSchema) GetGRPCConfig() *GRPCConfig {
+	if s.Options == nil || s.Options.GRPC == nil {
+		return &GRPCConfig{}
+	}
+	return s.Options.GRPC
+}
+
+// GetEventsConfig returns the events configuration, never nil
+func (s *ResourceSchema) GetEventsConfig() *EventsConfig {
+	if s.Options == nil || s.Options.Events == nil {
+		return &EventsConfig{}
+	}
+	return s.Options.Events
+}
+
+// GetCacheConfig returns the caching configuration with defaults applied
+func (s *ResourceSchema) GetCacheConfig() *CacheConfig {
+	config := &CacheConfig{TTL: DefaultCacheTTL, ListTTL: DefaultCacheListTTL}
+	if s.Options == nil || s.Options.Cache == nil {
+		return config
+	}
+	if s.Options.Cache.TTL > 0 {
+		config.TTL = s.Options.Cache.TTL
+	}
+	if s.Options.Cache.ListTTL > 0 {
+		config.ListTTL = s.Options.Cache.ListTTL
+	}
+	config.Invalidates = s.Options.Cache.Invalidates
+	return config
+}
+
+// GetSearchConfig returns the full-text search configuration with defaults applied
+func (s *ResourceSchema) GetSearchConfig() *SearchConfig {
+	config := &SearchConfig{Language: DefaultSearchLanguage}
+	if s.Options != nil && s.Options.Search != nil && s.Options.Search.Language != "" {
+		config.Language = strings.ToLower(s.Options.Search.Language)
+	}
+	return config
+}
+
+// GetSearchFields returns the fields matched by full-text search: fields with a search
+// configuration and the fields of gin indexes, in schema order
+func (s *ResourceSchema) GetSearchFields() []SchemaField {
+	indexed := make(map[string]bool)
+	for _, index := range s.Indexes {
+		if strings.EqualFold(index.Type, "gin") {
+			for _, name := range index.Fields {
+				indexed[name] = true
+			}
+		}
+	}
+
+	var fields []SchemaField
+	for _, field := range s.Fields {
+		if field.Search != nil || indexed[field.Name] {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// SearchWeight returns the full-text search weight of the field, "D" when none is set
+func (f *SchemaField) SearchWeight() string {
+	if f.Search == nil {
+		return SearchWeightD
+	}
+	switch weight := strings.ToUpper(strings.TrimSpace(f.Search.Weight)); weight {
+	case SearchWeightA, SearchWeightB, SearchWeightC:
+		return weight
+	default:
+		return SearchWeightD
+	}
+}
+
+// IsUpload reports whether the field holds a file uploaded to blob storage
+func (f *SchemaField) IsUpload() bool {
+	return f.Type == FieldTypeFileUpload || f.Type == FieldTypeImageUpload
+}
+
+// GetUploadConfig returns the upload configuration of a file or image field with defaults
+// applied. Only image fields get thumbnails.
+func (f *SchemaField) GetUploadConfig() *UploadFieldConfig {
+	config := &UploadFieldConfig{MaxSize: DefaultUploadMaxSize}
+	if f.Type == FieldTypeImageUpload {
+		config.AllowedTypes = DefaultImageTypes
+	}
+	if f.Upload == nil {
+		return config
+	}
+	if f.Upload.MaxSize > 0 {
+		config.MaxSize = f.Upload.MaxSize
+	}
+	if len(f.Upload.AllowedTypes) > 0 {
+		config.AllowedTypes = f.Upload.AllowedTypes
+	}
+	if f.Type == FieldTypeImageUpload && f.Upload.Thumbnail != nil && f.Upload.Thumbnail.Width > 0 && f.Upload.Thumbnail.Height > 0 {
+		config.Thumbnail = f.Upload.Thumbnail
+	}
+	return config
+}
+
+// GetUploadFields returns the file and image fields of the schema
+func (s *ResourceSchema) GetUploadFields() []SchemaField {
+	var fields []SchemaField
+	for _, field := range s.Fields {
+		if field.IsUpload() {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// IsGeo reports whether the field holds geographic coordinates
+func (f *SchemaField) IsGeo() bool {
+	return f.Type == string(FieldTypeCoordinates) || f.Type == FieldTypeLocation
+}
+
+// GetGeoFields returns the coordinates fields of the schema
+func (s *ResourceSchema) GetGeoFields() []SchemaField {
+	var fields []SchemaField
+	for _, field := range s.Fields {
+		if field.IsGeo() {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// geoGORMTag returns the GORM tag of a coordinates field: a PostGIS geography column with a
+// GiST index, or the latitude and longitude columns of the embedded struct elsewhere
+func (f *SchemaField) geoGORMTag(dbProvider string) string {
+	column := ToSnakeCase(f.Name)
+	if f.Database != nil && f.Database.ColumnName != "" {
+		column = f.Database.ColumnName
+	}
+	switch dbProvider {
+	case "postgres", "supabase":
+		return "column:" + column + ";type:geography;index:,type:gist"
+	default:
+		return "embedded;embeddedPrefix:" + column + "_"
+	}
+}
+
+// IsMoney reports whether the field holds an amount of money with its currency
+func (f *SchemaField) IsMoney() bool {
+	return f.Type == string(FieldTypeCurrency)
+}
+
+// GetMoneyFields returns the currency fields of the schema
+func (s *ResourceSchema) GetMoneyFields() []SchemaField {
+	var fields []SchemaField
+	for _, field := range s.Fields {
+		if field.IsMoney() {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// AllowedCurrencies returns the currency codes a currency field accepts, its allowed values,
+// or nil when any ISO 4217 currency is accepted
+func (f *SchemaField) AllowedCurrencies() []string {
+	if f.Validation == nil || len(f.Validation.AllowedValues) == 0 {
+		return nil
+	}
+	codes := make([]string, len(f.Validation.AllowedValues))
+	for i, code := range f.Validation.AllowedValues {
+		codes[i] = strings.ToUpper(code)
+	}
+	return codes
+}
+
+// moneyGORMTag returns the GORM tag of a currency field, stored in the amount and currency
+// columns of the embedded struct
+func (f *SchemaField) moneyGORMTag() string {
+	column := ToSnakeCase(f.Name)
+	if f.Database != nil && f.Database.ColumnName != "" {
+		column = f.Database.ColumnName
+	}
+	return "embedded;embeddedPrefix:" + column + "_"
+}
+
+// GetAdminConfig returns the admin panel configuration of the schema
+func (s *ResourceSchema) GetAdminConfig() *AdminConfig {
+	if s.Options == nil || s.Options.Admin == nil {
+		return &AdminConfig{}
+	}
+	return s.Options.Admin
+}
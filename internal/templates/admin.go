@@ -0,0 +1,1742 @@
+package templates
+
+// AdminPackageTemplate generates internal/admin/admin.go, the http.Handler serving the pages of the
+// admin panel with the RBAC checks and CSRF protection of its forms
+const AdminPackageTemplate = `package admin
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"{{.Module}}/internal/apperrors"
+)
+
+//go:embed templates/*.html static/admin.css
+var assets embed.FS
+
+// Actions roles can be required for
+const (
+	ActionView   = "view"
+	ActionCreate = "create"
+	ActionEdit   = "edit"
+	ActionDelete = "delete"
+)
+
+// DefaultPrefix is the path the admin panel is served below
+const DefaultPrefix = "/admin"
+
+// DefaultPageSize is the number of records per page of the tables
+const DefaultPageSize = 20
+
+// PickerSize is the number of records relation pickers offer
+const PickerSize = 200
+
+// AdminRole is the role allowed to perform every action
+const AdminRole = "admin"
+
+const csrfCookie = "admin_csrf"
+
+// notices are the messages shown after a record is saved or deleted
+var notices = map[string]string{
+	"created": "The record was created.",
+	"updated": "The record was saved.",
+	"deleted": "The record was deleted.",
+}
+
+// Admin serves the server-rendered admin panel of the resources. It is an http.Handler
+// mounted on Prefix, behind the authentication of the application.
+type Admin struct {
+	Title  string
+	Prefix string
+	// Roles returns the roles of the user of a request, by default the roles the JWT
+	// middleware stores in the request context
+	Roles func(r *http.Request) []string
+
+	resources []*Resource
+	paths     map[string]*Resource
+	pages     map[string]*template.Template
+}
+
+// New creates the admin panel of the resources
+func New(title string, resources ...*Resource) *Admin {
+	a := &Admin{
+		Title:     title,
+		Prefix:    DefaultPrefix,
+		Roles:     contextRoles,
+		resources: resources,
+		paths:     make(map[string]*Resource, len(resources)),
+		pages:     make(map[string]*template.Template),
+	}
+	for _, resource := range resources {
+		a.paths[resource.Path] = resource
+	}
+	for _, page := range []string{"index", "list", "detail", "form", "error"} {
+		a.pages[page] = template.Must(template.ParseFS(assets, "templates/layout.html", "templates/"+page+".html"))
+	}
+	return a
+}
+
+// contextRoles returns the roles the JWT middleware stores in the request context
+func contextRoles(r *http.Request) []string {
+	roles, _ := r.Context().Value("roles").([]string)
+	return roles
+}
+
+// Can reports whether the user of the request may perform an action on a resource
+func (a *Admin) Can(r *http.Request, resource *Resource, action string) bool {
+	allowed, ok := resource.Roles[action]
+	if !ok {
+		return true
+	}
+	for _, role := range a.Roles(r) {
+		if role == AdminRole {
+			return true
+		}
+		for _, name := range allowed {
+			if role == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ServeHTTP routes the pages of the panel:
+//
+//	GET  /                    resources the user may view
+//	GET  /{resource}          table of records
+//	GET  /{resource}/new      creation form
+//	POST /{resource}          creates a record
+//	GET  /{resource}/{id}     detail page
+//	GET  /{resource}/{id}/edit edit form
+//	POST /{resource}/{id}     saves a record
+//	POST /{resource}/{id}/delete deletes a record
+func (a *Admin) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, a.Prefix), "/")
+	if path == "admin.css" {
+		css, _ := assets.ReadFile("static/admin.css")
+		w.Header().Set("Content-Type", "text/css; charset=utf-8")
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		w.Write(css)
+		return
+	}
+
+	p := &page{Admin: a, request: r, CSRF: a.csrfToken(w, r), Notice: notices[r.URL.Query().Get("notice")]}
+	if r.Method == http.MethodPost && !a.validCSRF(r) {
+		a.fail(w, p, http.StatusForbidden, "The form expired, reload the page and try again.")
+		return
+	}
+
+	segments := strings.Split(path, "/")
+	if path == "" {
+		a.index(w, p)
+		return
+	}
+	resource := a.paths[segments[0]]
+	if resource == nil || len(segments) > 3 {
+		a.fail(w, p, http.StatusNotFound, "Page not found.")
+		return
+	}
+	p.Resource = resource
+
+	switch {
+	case len(segments) == 1 && r.Method == http.MethodGet:
+		a.list(w, p)
+	case len(segments) == 1 && r.Method == http.MethodPost:
+		a.save(w, p, "")
+	case len(segments) == 2 && segments[1] == "new" && r.Method == http.MethodGet:
+		a.form(w, p, "", nil, nil)
+	case len(segments) == 2 && r.Method == http.MethodGet:
+		a.detail(w, p, segments[1])
+	case len(segments) == 2 && r.Method == http.MethodPost:
+		a.save(w, p, segments[1])
+	case len(segments) == 3 && segments[2] == "edit" && r.Method == http.MethodGet:
+		a.edit(w, p, segments[1])
+	case len(segments) == 3 && segments[2] == "delete" && r.Method == http.MethodPost:
+		a.delete(w, p, segments[1])
+	case r.Method != http.MethodGet && r.Method != http.MethodPost:
+		a.fail(w, p, http.StatusMethodNotAllowed, "Method not allowed.")
+	default:
+		a.fail(w, p, http.StatusNotFound, "Page not found.")
+	}
+}
+
+// page is the data of the rendered pages
+type page struct {
+	*Admin
+	request  *http.Request
+	Resource *Resource
+	Heading  string
+	CSRF     string
+	Notice   string
+	Error    string
+	Status   int
+
+	// Table
+	Columns []column
+	Rows    []row
+	Search  string
+	Filters []filter
+	Prev    string
+	Next    string
+	Summary string
+
+	// Detail page and forms
+	Record    Record
+	Sections  []section
+	Action    string
+	EditURL   string
+	DeleteURL string
+}
+
+type menuGroup struct {
+	Name  string
+	Items []menuItem
+}
+
+type menuItem struct {
+	Label  string
+	URL    string
+	Active bool
+}
+
+type column struct {
+	Label string
+	URL   string // Sorts the table, empty when the column does not sort it
+	Arrow string
+}
+
+type row struct {
+	Cells     []cell
+	ViewURL   string
+	EditURL   string
+	DeleteURL string
+}
+
+type cell struct {
+	Value string
+	URL   string
+}
+
+type filter struct {
+	Name  string
+	Label string
+	Value string
+}
+
+type section struct {
+	Name    string
+	Entries []entry // Values of the detail page
+	Inputs  []input // Controls of forms
+}
+
+type entry struct {
+	Label string
+	cell
+}
+
+type input struct {
+	*Field
+	Widget   string
+	Value    string
+	Checked  bool
+	Disabled bool
+	Required bool
+	Step     string
+	Error    string
+	Choices  []choice
+}
+
+type choice struct {
+	Value    string
+	Label    string
+	Selected bool
+}
+
+// Menu lists the resources the user may view, by section
+func (p *page) Menu() []menuGroup {
+	var groups []menuGroup
+	for _, resource := range p.resources {
+		if !p.Can(p.request, resource, ActionView) {
+			continue
+		}
+		item := menuItem{Label: resource.PluralLabel, URL: p.url(resource), Active: resource == p.Resource}
+		i := 0
+		for i < len(groups) && groups[i].Name != resource.Group {
+			i++
+		}
+		if i == len(groups) {
+			groups = append(groups, menuGroup{Name: resource.Group})
+		}
+		groups[i].Items = append(groups[i].Items, item)
+	}
+	return groups
+}
+
+// Allowed reports whether the user may perform an action on the resource of the page
+func (p *page) Allowed(action string) bool {
+	return p.Resource != nil && p.Can(p.request, p.Resource, action)
+}
+
+// Span returns the number of columns of the table, including the actions
+func (p *page) Span() int {
+	return len(p.Columns) + 1
+}
+
+// Base returns the URL of the table of the resource of the page
+func (p *page) Base() string {
+	return p.url(p.Resource)
+}
+
+// url returns the URL of a resource page
+func (a *Admin) url(resource *Resource, segments ...string) string {
+	u := a.Prefix + "/" + resource.Path
+	for _, segment := range segments {
+		u += "/" + url.PathEscape(segment)
+	}
+	return u
+}
+
+func (a *Admin) index(w http.ResponseWriter, p *page) {
+	p.Heading = a.Title
+	a.render(w, p, http.StatusOK, "index")
+}
+
+func (a *Admin) list(w http.ResponseWriter, p *page) {
+	resource, r := p.Resource, p.request
+	if !a.Can(r, resource, ActionView) {
+		a.fail(w, p, http.StatusForbidden, "You are not allowed to view "+strings.ToLower(resource.PluralLabel)+".")
+		return
+	}
+
+	params := r.URL.Query()
+	query := Query{Page: 1, PageSize: DefaultPageSize, Filters: Record{}}
+	if n, err := strconv.Atoi(params.Get("page")); err == nil && n > 0 {
+		query.Page = n
+	}
+	if resource.Searchable {
+		query.Search = params.Get("search")
+	}
+	if field := resource.field(params.Get("sort")); resource.Sortable && field != nil && field.visible() {
+		query.Sort = field.Name
+		query.Order = "asc"
+		if params.Get("order") == "desc" {
+			query.Order = "desc"
+		}
+	}
+	// Fields filter the table, links to the records referencing another record use them
+	for _, field := range resource.Fields {
+		value := params.Get(field.Name)
+		if value == "" || field.Type == "relation" || field.Type == "relation_array" {
+			continue
+		}
+		parsed, err := field.parse(value)
+		if err != nil {
+			a.fail(w, p, http.StatusBadRequest, field.Label+" "+err.Error()+".")
+			return
+		}
+		query.Filters[field.Name] = parsed
+		p.Filters = append(p.Filters, filter{Name: field.Name, Label: field.Label, Value: value})
+	}
+
+	records, total, err := resource.Store.List(r.Context(), query)
+	if err != nil {
+		a.failWith(w, p, err)
+		return
+	}
+
+	link := func(changes map[string]string) string {
+		values := url.Values{}
+		for key, value := range params {
+			values[key] = value
+		}
+		values.Del("notice")
+		for key, value := range changes {
+			if value == "" {
+				values.Del(key)
+			} else {
+				values.Set(key, value)
+			}
+		}
+		if encoded := values.Encode(); encoded != "" {
+			return p.Base() + "?" + encoded
+		}
+		return p.Base()
+	}
+
+	titles := a.relationTitles(r, resource)
+	for _, name := range resource.Columns {
+		field := resource.field(name)
+		if field == nil || !field.visible() {
+			continue
+		}
+		col := column{Label: field.Label}
+		if resource.Sortable {
+			order := "asc"
+			if query.Sort == field.Name && query.Order == "asc" {
+				order = "desc"
+			}
+			if query.Sort == field.Name {
+				col.Arrow = map[string]string{"asc": "▲", "desc": "▼"}[query.Order]
+			}
+			col.URL = link(map[string]string{"sort": field.Name, "order": order, "page": ""})
+		}
+		p.Columns = append(p.Columns, col)
+	}
+	for _, record := range records {
+		row := row{}
+		for _, name := range resource.Columns {
+			field := resource.field(name)
+			if field == nil || !field.visible() {
+				continue
+			}
+			row.Cells = append(row.Cells, a.cell(field, record, titles))
+		}
+		id := record.ID()
+		for _, action := range resource.Actions {
+			if !a.Can(r, resource, action) {
+				continue
+			}
+			switch action {
+			case ActionView:
+				row.ViewURL = a.url(resource, id)
+			case ActionEdit:
+				row.EditURL = a.url(resource, id, "edit")
+			case ActionDelete:
+				row.DeleteURL = a.url(resource, id, "delete")
+			}
+		}
+		p.Rows = append(p.Rows, row)
+	}
+
+	p.Heading = resource.PluralLabel
+	p.Search = query.Search
+	pages := int((total + int64(query.PageSize) - 1) / int64(query.PageSize))
+	if query.Page > 1 {
+		p.Prev = link(map[string]string{"page": strconv.Itoa(query.Page - 1)})
+	}
+	if query.Page < pages {
+		p.Next = link(map[string]string{"page": strconv.Itoa(query.Page + 1)})
+	}
+	p.Summary = strconv.FormatInt(total, 10) + " " + strings.ToLower(resource.PluralLabel)
+	if total == 1 {
+		p.Summary = "1 " + strings.ToLower(resource.Label)
+	}
+	if pages > 1 {
+		p.Summary += ", page " + strconv.Itoa(query.Page) + " of " + strconv.Itoa(pages)
+	}
+	a.render(w, p, http.StatusOK, "list")
+}
+
+func (a *Admin) detail(w http.ResponseWriter, p *page, id string) {
+	resource, r := p.Resource, p.request
+	if !a.Can(r, resource, ActionView) {
+		a.fail(w, p, http.StatusForbidden, "You are not allowed to view "+strings.ToLower(resource.PluralLabel)+".")
+		return
+	}
+	record, err := resource.Store.Get(r.Context(), id)
+	if err != nil {
+		a.failWith(w, p, err)
+		return
+	}
+
+	titles := a.relationTitles(r, resource)
+	for _, field := range resource.Fields {
+		value := a.cell(field, record, titles)
+		if field.children() {
+			target := a.paths[field.Relation]
+			if target == nil || !a.Can(r, target, ActionView) {
+				continue
+			}
+			value = cell{Value: "View " + strings.ToLower(field.Label), URL: a.url(target) + "?" + url.Values{field.ForeignKey: {id}}.Encode()}
+		} else if !field.visible() {
+			continue
+		}
+		s := p.section(field.Group)
+		s.Entries = append(s.Entries, entry{Label: field.Label, cell: value})
+	}
+
+	if a.Can(r, resource, ActionEdit) {
+		p.EditURL = a.url(resource, id, "edit")
+	}
+	if a.Can(r, resource, ActionDelete) {
+		p.DeleteURL = a.url(resource, id, "delete")
+	}
+	p.Record = record
+	p.Heading = resource.Label + " " + resource.title(record)
+	a.render(w, p, http.StatusOK, "detail")
+}
+
+func (a *Admin) edit(w http.ResponseWriter, p *page, id string) {
+	if !a.Can(p.request, p.Resource, ActionEdit) {
+		a.fail(w, p, http.StatusForbidden, "You are not allowed to edit "+strings.ToLower(p.Resource.PluralLabel)+".")
+		return
+	}
+	record, err := p.Resource.Store.Get(p.request.Context(), id)
+	if err != nil {
+		a.failWith(w, p, err)
+		return
+	}
+	a.form(w, p, id, record, nil)
+}
+
+// form renders the creation form, or the edit form of the record with the given id, with
+// the errors of the submitted values
+func (a *Admin) form(w http.ResponseWriter, p *page, id string, record Record, errs map[string]string) {
+	resource, r := p.Resource, p.request
+	action := ActionCreate
+	if id != "" {
+		action = ActionEdit
+	}
+	if !a.Can(r, resource, action) {
+		a.fail(w, p, http.StatusForbidden, "You are not allowed to "+action+" "+strings.ToLower(resource.PluralLabel)+".")
+		return
+	}
+	if record == nil {
+		record = Record{}
+	}
+
+	for _, field := range resource.Fields {
+		if !field.editable() {
+			continue
+		}
+		in := input{
+			Field:    field,
+			Widget:   field.Widget,
+			Value:    field.input(record[field.Name]),
+			Checked:  record[field.Name] == true,
+			Disabled: field.ReadOnly,
+			Required: field.Required && !field.ReadOnly && !(field.Type == "password" && id != ""),
+			Error:    errs[field.Name],
+		}
+		switch field.Type {
+		case "number", "integer":
+			in.Step = "1"
+		case "float", "decimal":
+			in.Step = "any"
+		}
+		for _, option := range field.Options {
+			in.Choices = append(in.Choices, choice{Value: option, Label: option, Selected: option == in.Value})
+		}
+		if target := a.paths[field.Relation]; target != nil {
+			choices, err := a.choices(r, target, in.Value)
+			if err != nil {
+				a.failWith(w, p, err)
+				return
+			}
+			in.Choices = choices
+		} else if field.Relation != "" {
+			// The referenced resource is not part of the panel, its id is typed
+			in.Widget = "text"
+		}
+		s := p.section(field.Group)
+		s.Inputs = append(s.Inputs, in)
+	}
+
+	status := http.StatusOK
+	if errs != nil {
+		status = http.StatusUnprocessableEntity
+	}
+	if id == "" {
+		p.Heading = "New " + strings.ToLower(resource.Label)
+		p.Action = a.url(resource)
+	} else {
+		p.Heading = "Edit " + strings.ToLower(resource.Label) + " " + resource.title(record)
+		p.Action = a.url(resource, id)
+	}
+	p.Record = record
+	a.render(w, p, status, "form")
+}
+
+// save creates a record from the submitted form, or updates the record with the given id
+func (a *Admin) save(w http.ResponseWriter, p *page, id string) {
+	resource, r := p.Resource, p.request
+	action := ActionCreate
+	if id != "" {
+		action = ActionEdit
+	}
+	if !a.Can(r, resource, action) {
+		a.fail(w, p, http.StatusForbidden, "You are not allowed to "+action+" "+strings.ToLower(resource.PluralLabel)+".")
+		return
+	}
+
+	// Updates start from the current record, keeping the fields the form does not edit
+	values := Record{}
+	if id != "" {
+		current, err := resource.Store.Get(r.Context(), id)
+		if err != nil {
+			a.failWith(w, p, err)
+			return
+		}
+		for name, value := range current {
+			if field := resource.field(name); field == nil || (field.Type != "relation" && field.Type != "relation_array") {
+				values[name] = value
+			}
+		}
+	}
+
+	errs := map[string]string{}
+	for _, field := range resource.Fields {
+		if !field.editable() || field.ReadOnly {
+			continue
+		}
+		value := r.PostForm.Get(field.Name)
+		if field.Type == "password" && value == "" && id != "" {
+			continue
+		}
+		parsed, err := field.parse(value)
+		if err != nil {
+			errs[field.Name] = field.Label + " " + err.Error()
+			continue
+		}
+		values[field.Name] = parsed
+	}
+	if len(errs) > 0 {
+		a.form(w, p, id, formRecord(resource, r.PostForm, values), errs)
+		return
+	}
+
+	var record Record
+	var err error
+	if id == "" {
+		record, err = resource.Store.Create(r.Context(), values)
+	} else {
+		record, err = resource.Store.Update(r.Context(), id, values)
+	}
+	var appErr *apperrors.Error
+	if err != nil && errors.As(err, &appErr) && (appErr.Code == apperrors.CodeValidation || appErr.Code == apperrors.CodeConflict || appErr.Code == apperrors.CodeBadRequest) {
+		var unmatched []string
+		for _, fieldErr := range appErr.Fields {
+			if field := resource.field(fieldErr.Field); field != nil && field.editable() {
+				errs[fieldErr.Field] = fieldErr.Message
+			} else {
+				unmatched = append(unmatched, fieldErr.Message)
+			}
+		}
+		if len(appErr.Fields) == 0 {
+			p.Error = appErr.Error()
+		} else if len(unmatched) > 0 {
+			p.Error = strings.Join(unmatched, "; ")
+		}
+		a.form(w, p, id, formRecord(resource, r.PostForm, values), errs)
+		return
+	}
+	if err != nil {
+		a.failWith(w, p, err)
+		return
+	}
+
+	notice := "created"
+	if id != "" {
+		notice = "updated"
+	}
+	http.Redirect(w, r, a.url(resource, record.ID())+"?notice="+notice, http.StatusSeeOther)
+}
+
+// formRecord returns the record a rejected form is rendered again with, holding the
+// submitted values as they were typed
+func formRecord(resource *Resource, form url.Values, values Record) Record {
+	record := Record{}
+	for name, value := range values {
+		record[name] = value
+	}
+	for _, field := range resource.Fields {
+		if _, ok := form[field.Name]; ok && field.Widget != "checkbox" {
+			record[field.Name] = form.Get(field.Name)
+		}
+	}
+	return record
+}
+
+func (a *Admin) delete(w http.ResponseWriter, p *page, id string) {
+	resource, r := p.Resource, p.request
+	if !a.Can(r, resource, ActionDelete) {
+		a.fail(w, p, http.StatusForbidden, "You are not allowed to delete "+strings.ToLower(resource.PluralLabel)+".")
+		return
+	}
+	if err := resource.Store.Delete(r.Context(), id); err != nil {
+		a.failWith(w, p, err)
+		return
+	}
+	http.Redirect(w, r, a.url(resource)+"?notice=deleted", http.StatusSeeOther)
+}
+
+// cell returns the displayed value of a field, foreign keys link to the record they reference
+func (a *Admin) cell(field *Field, record Record, titles map[string]map[string]string) cell {
+	value := field.format(record[field.Name])
+	target := a.paths[field.Relation]
+	if target == nil || field.children() || value == "" {
+		return cell{Value: value}
+	}
+	c := cell{Value: value, URL: a.url(target, value)}
+	if title, ok := titles[field.Name][value]; ok {
+		c.Value = title
+	}
+	return c
+}
+
+// relationTitles returns the titles of the records the foreign keys of a resource reference,
+// by field and id
+func (a *Admin) relationTitles(r *http.Request, resource *Resource) map[string]map[string]string {
+	titles := map[string]map[string]string{}
+	for _, field := range resource.Fields {
+		target := a.paths[field.Relation]
+		if target == nil || field.children() || !a.Can(r, target, ActionView) {
+			continue
+		}
+		choices, err := a.choices(r, target, "")
+		if err != nil {
+			continue
+		}
+		titles[field.Name] = make(map[string]string, len(choices))
+		for _, choice := range choices {
+			titles[field.Name][choice.Value] = choice.Label
+		}
+	}
+	return titles
+}
+
+// choices lists the records a relation picker offers
+func (a *Admin) choices(r *http.Request, target *Resource, selected string) ([]choice, error) {
+	records, _, err := target.Store.List(r.Context(), Query{Page: 1, PageSize: PickerSize})
+	if err != nil {
+		return nil, err
+	}
+	choices := make([]choice, len(records))
+	for i, record := range records {
+		choices[i] = choice{Value: record.ID(), Label: target.title(record), Selected: record.ID() == selected}
+	}
+	return choices, nil
+}
+
+// section returns the section of the page with the given name, adding it when missing
+func (p *page) section(name string) *section {
+	for i := range p.Sections {
+		if p.Sections[i].Name == name {
+			return &p.Sections[i]
+		}
+	}
+	p.Sections = append(p.Sections, section{Name: name})
+	return &p.Sections[len(p.Sections)-1]
+}
+
+// failWith renders the error page of an error of a store
+func (a *Admin) failWith(w http.ResponseWriter, p *page, err error) {
+	problem := apperrors.ProblemFor(err, p.request.URL.Path)
+	message := problem.Detail
+	if message == "" {
+		message = problem.Title + "."
+	}
+	a.fail(w, p, problem.Status, message)
+}
+
+func (a *Admin) fail(w http.ResponseWriter, p *page, status int, message string) {
+	p.Heading = http.StatusText(status)
+	p.Status = status
+	p.Error = message
+	a.render(w, p, status, "error")
+}
+
+func (a *Admin) render(w http.ResponseWriter, p *page, status int, name string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.WriteHeader(status)
+	if err := a.pages[name].ExecuteTemplate(w, "layout", p); err != nil {
+		log.Printf("admin: failed to render %s: %v", name, err)
+	}
+}
+
+// csrfToken returns the token forms of the user are submitted with, kept in a cookie
+func (a *Admin) csrfToken(w http.ResponseWriter, r *http.Request) string {
+	if cookie, err := r.Cookie(csrfCookie); err == nil && len(cookie.Value) == 64 {
+		return cookie.Value
+	}
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	token := hex.EncodeToString(b)
+	http.SetCookie(w, &http.Cookie{
+		Name:     csrfCookie,
+		Value:    token,
+		Path:     a.Prefix + "/",
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+	return token
+}
+
+// validCSRF reports whether a submitted form carries the token of the cookie
+func (a *Admin) validCSRF(r *http.Request) bool {
+	cookie, err := r.Cookie(csrfCookie)
+	if err != nil || cookie.Value == "" {
+		return false
+	}
+	if err := r.ParseForm(); err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(r.PostForm.Get("csrf"))) == 1
+}
+`
+
+// AdminResourceTemplate generates internal/admin/resource.go, describing resources and adapting the
+// generated services to the records the pages display and edit
+const AdminResourceTemplate = `package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"{{.Module}}/internal/apperrors"
+)
+
+// Record is a record of a resource as the JSON object of its model
+type Record map[string]any
+
+// ID returns the identifier of the record
+func (r Record) ID() string {
+	id, _ := r["id"].(string)
+	return id
+}
+
+// Query selects a page of records
+type Query struct {
+	Page     int
+	PageSize int
+	Sort     string
+	Order    string
+	Search   string
+	// Filters holds the field values listed records must have
+	Filters Record
+}
+
+// Store reads and writes the records of a resource
+type Store interface {
+	List(ctx context.Context, query Query) ([]Record, int64, error)
+	Get(ctx context.Context, id string) (Record, error)
+	Create(ctx context.Context, values Record) (Record, error)
+	Update(ctx context.Context, id string, values Record) (Record, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// Resource describes how the records of a resource are listed, shown and edited
+type Resource struct {
+	Name        string // Singular name used in messages
+	Path        string // Path segment of the pages of the resource
+	Label       string
+	PluralLabel string
+	Group       string   // Menu section listing the resource, the main section when empty
+	Display     string   // Field naming records in titles and relation pickers
+	Fields      []*Field // Fields in the order pages show them
+	Columns     []string // Fields listed in the table
+	Actions     []string // Row actions of the table: ActionView, ActionEdit, ActionDelete
+	Sortable    bool     // Columns sort the table
+	Searchable  bool     // The table has a search box
+	// Roles lists the roles allowed to perform an action, actions without roles are open to
+	// every user of the panel
+	Roles map[string][]string
+	Store Store
+}
+
+// Field describes how a field is displayed and edited
+type Field struct {
+	Name        string // JSON name of the field
+	Label       string
+	Type        string // Schema type of the field
+	Widget      string // Form control: text, textarea, number, checkbox, select, date, ...
+	Group       string // Form section of the field
+	Width       string // Share of the form row: full, half, third or quarter
+	Placeholder string
+	Help        string
+	Hidden      bool
+	ReadOnly    bool
+	Required    bool
+	Options     []string // Values of select widgets
+	// Relation is the path of the resource the field references. Foreign keys are edited
+	// with a picker, with a ForeignKey the detail page links to the records referencing it.
+	Relation   string
+	ForeignKey string
+}
+
+// field returns the field with the given name, nil when there is none
+func (r *Resource) field(name string) *Field {
+	for _, field := range r.Fields {
+		if field.Name == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// title returns the name of a record in titles and pickers
+func (r *Resource) title(record Record) string {
+	if field := r.field(r.Display); field != nil {
+		if value := field.format(record[field.Name]); value != "" {
+			return value
+		}
+	}
+	return record.ID()
+}
+
+// visible reports whether the field is shown on the detail page and in the table
+func (f *Field) visible() bool {
+	return !f.Hidden && f.Type != "relation" && f.Type != "password" && !f.children()
+}
+
+// editable reports whether the field is part of forms
+func (f *Field) editable() bool {
+	return !f.Hidden && f.Type != "relation" && f.Type != "relation_array"
+}
+
+// children reports whether the field lists the records of another resource referencing it
+func (f *Field) children() bool {
+	return f.Type == "relation_array" && f.Relation != "" && f.ForeignKey != ""
+}
+
+// format returns a value of the field as displayed text
+func (f *Field) format(value any) string {
+	if value == nil {
+		return ""
+	}
+	switch f.Type {
+	case "password":
+		return ""
+	case "boolean":
+		if value == true {
+			return "Yes"
+		}
+		return "No"
+	case "date":
+		return formatTime(value, "2006-01-02")
+	case "datetime", "timestamp":
+		return formatTime(value, "2006-01-02 15:04")
+	}
+	return f.input(value)
+}
+
+// input returns a value of the field as the value of its form control
+func (f *Field) input(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		switch f.Type {
+		case "password":
+			return ""
+		case "date":
+			return formatTime(v, "2006-01-02")
+		case "datetime", "timestamp":
+			return formatTime(v, "2006-01-02T15:04")
+		}
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	case map[string]any:
+		// Money amounts and coordinates are written the way forms read them back
+		if amount, ok := v["amount"]; ok {
+			return fmt.Sprintf("%v %v", amount, v["currency"])
+		}
+		if latitude, ok := v["latitude"]; ok {
+			return fmt.Sprintf("%v,%v", latitude, v["longitude"])
+		}
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// parse converts the value of a form control to the JSON value of the field, the zero
+// value of the field when the control is empty
+func (f *Field) parse(value string) (any, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		switch f.Type {
+		case "number", "integer", "float", "decimal":
+			return 0, nil
+		case "boolean":
+			return false, nil
+		case "date", "datetime", "timestamp":
+			return time.Time{}.Format(time.RFC3339), nil
+		case "location", "coordinates", "currency", "json", "mixed":
+			return nil, nil
+		}
+		return "", nil
+	}
+
+	switch f.Type {
+	case "number", "integer":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, errors.New("must be a whole number")
+		}
+		return n, nil
+	case "float", "decimal":
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, errors.New("must be a number")
+		}
+		return n, nil
+	case "boolean":
+		return value == "on" || value == "true", nil
+	case "date":
+		t, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return nil, errors.New("must be a date")
+		}
+		return t.Format(time.RFC3339), nil
+	case "datetime", "timestamp":
+		t, err := time.Parse("2006-01-02T15:04", value)
+		if err != nil {
+			return nil, errors.New("must be a date and time")
+		}
+		return t.Format(time.RFC3339), nil
+	case "location", "coordinates":
+		latitude, longitude, found := strings.Cut(value, ",")
+		lat, latErr := strconv.ParseFloat(strings.TrimSpace(latitude), 64)
+		lng, lngErr := strconv.ParseFloat(strings.TrimSpace(longitude), 64)
+		if !found || latErr != nil || lngErr != nil {
+			return nil, errors.New("must be written as latitude,longitude")
+		}
+		return map[string]any{"latitude": lat, "longitude": lng}, nil
+	case "json", "mixed":
+		if !json.Valid([]byte(value)) {
+			return nil, errors.New("must be valid JSON")
+		}
+		return json.RawMessage(value), nil
+	}
+	return value, nil
+}
+
+// formatTime reformats an RFC 3339 time, empty for the zero time
+func formatTime(value any, layout string) string {
+	s, _ := value.(string)
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return s
+	}
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(layout)
+}
+
+// Service is the interface of the generated services of a model M, with its request R and
+// filter F
+type Service[M, R, F any] interface {
+	Create(ctx context.Context, req *R) (*M, error)
+	GetByID(ctx context.Context, id string) (*M, error)
+	GetAll(ctx context.Context, filter *F) ([]*M, int64, error)
+	Update(ctx context.Context, id string, req *R) (*M, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// ServiceStore manages records through a generated service, converting them to the models,
+// requests and filters of the service through their JSON encoding
+func ServiceStore[M, R, F any](service Service[M, R, F]) Store {
+	return &serviceStore[M, R, F]{service: service}
+}
+
+type serviceStore[M, R, F any] struct {
+	service Service[M, R, F]
+}
+
+func (s *serviceStore[M, R, F]) List(ctx context.Context, query Query) ([]Record, int64, error) {
+	values := Record{"page": query.Page, "page_size": query.PageSize, "search": query.Search}
+	if query.Sort != "" {
+		values["sort"] = query.Sort
+		values["order"] = query.Order
+	}
+	for name, value := range query.Filters {
+		values[name] = value
+	}
+	var filter F
+	if err := decodeRecord(values, &filter); err != nil {
+		return nil, 0, err
+	}
+
+	items, total, err := s.service.GetAll(ctx, &filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	records := make([]Record, len(items))
+	for i, item := range items {
+		if records[i], err = encodeRecord(item); err != nil {
+			return nil, 0, err
+		}
+	}
+	return records, total, nil
+}
+
+func (s *serviceStore[M, R, F]) Get(ctx context.Context, id string) (Record, error) {
+	item, err := s.service.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return encodeRecord(item)
+}
+
+func (s *serviceStore[M, R, F]) Create(ctx context.Context, values Record) (Record, error) {
+	var req R
+	if err := decodeRecord(values, &req); err != nil {
+		return nil, err
+	}
+	item, err := s.service.Create(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	return encodeRecord(item)
+}
+
+func (s *serviceStore[M, R, F]) Update(ctx context.Context, id string, values Record) (Record, error) {
+	var req R
+	if err := decodeRecord(values, &req); err != nil {
+		return nil, err
+	}
+	item, err := s.service.Update(ctx, id, &req)
+	if err != nil {
+		return nil, err
+	}
+	return encodeRecord(item)
+}
+
+func (s *serviceStore[M, R, F]) Delete(ctx context.Context, id string) error {
+	return s.service.Delete(ctx, id)
+}
+
+// encodeRecord converts a model to a record, keeping numbers exact
+func encodeRecord(model any) (Record, error) {
+	data, err := json.Marshal(model)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var record Record
+	if err := decoder.Decode(&record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// decodeRecord sets the fields of target from a record one value at a time, so that a
+// value the target rejects is reported as an error of its field
+func decodeRecord(values Record, target any) error {
+	var fields []apperrors.FieldError
+	for name, value := range values {
+		data, err := json.Marshal(map[string]any{name: value})
+		if err == nil {
+			err = json.Unmarshal(data, target)
+		}
+		if err != nil {
+			message := err.Error()
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) {
+				message = "invalid value"
+			}
+			fields = append(fields, apperrors.Field(name, "invalid", message))
+		}
+	}
+	return apperrors.Invalid(fields...)
+}
+`
+
+// AdminTestTemplate generates the tests of the admin panel against an in-memory store
+const AdminTestTemplate = `package admin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"{{.Module}}/internal/apperrors"
+)
+
+// memoryStore keeps records in memory, rejecting records without a name
+type memoryStore struct {
+	records map[string]Record
+	order   []string
+}
+
+func newMemoryStore(records ...Record) *memoryStore {
+	s := &memoryStore{records: make(map[string]Record)}
+	for _, record := range records {
+		s.records[record.ID()] = record
+		s.order = append(s.order, record.ID())
+	}
+	return s
+}
+
+func (s *memoryStore) List(ctx context.Context, query Query) ([]Record, int64, error) {
+	var records []Record
+	for _, id := range s.order {
+		record, ok := s.records[id]
+		if !ok {
+			continue
+		}
+		match := true
+		for name, value := range query.Filters {
+			match = match && record[name] == value
+		}
+		if match {
+			records = append(records, record)
+		}
+	}
+	return records, int64(len(records)), nil
+}
+
+func (s *memoryStore) Get(ctx context.Context, id string) (Record, error) {
+	record, ok := s.records[id]
+	if !ok {
+		return nil, apperrors.NotFound("record %s not found", id)
+	}
+	return record, nil
+}
+
+func (s *memoryStore) Create(ctx context.Context, values Record) (Record, error) {
+	if values["name"] == "" {
+		return nil, apperrors.Invalid(apperrors.Field("name", "required", "Name is required"))
+	}
+	id := strconv.Itoa(len(s.order) + 1)
+	values["id"] = id
+	s.records[id] = values
+	s.order = append(s.order, id)
+	return values, nil
+}
+
+func (s *memoryStore) Update(ctx context.Context, id string, values Record) (Record, error) {
+	if _, err := s.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	s.records[id] = values
+	return values, nil
+}
+
+func (s *memoryStore) Delete(ctx context.Context, id string) error {
+	delete(s.records, id)
+	return nil
+}
+
+// newTestAdmin creates a panel of authors and their books, deleting books requires the editor role
+func newTestAdmin() (*Admin, *memoryStore) {
+	authors := &Resource{
+		Name: "author", Path: "authors", Label: "Author", PluralLabel: "Authors", Display: "name",
+		Columns: []string{"name"},
+		Actions: []string{ActionView, ActionEdit, ActionDelete},
+		Fields: []*Field{
+			{Name: "name", Label: "Name", Type: "string", Widget: "text", Required: true},
+			{Name: "books", Label: "Books", Type: "relation_array", Relation: "books", ForeignKey: "author_id"},
+		},
+		Store: newMemoryStore(Record{"id": "a1", "name": "Ursula"}),
+	}
+	books := newMemoryStore(Record{"id": "b1", "name": "Earthsea", "pages": "250", "author_id": "a1"})
+	return New("Library", authors, &Resource{
+		Name: "book", Path: "books", Label: "Book", PluralLabel: "Books", Display: "name",
+		Columns:  []string{"name", "author_id"},
+		Actions:  []string{ActionView, ActionEdit, ActionDelete},
+		Sortable: true,
+		Roles:    map[string][]string{ActionDelete: {"editor"}},
+		Fields: []*Field{
+			{Name: "name", Label: "Name", Type: "string", Widget: "text", Required: true},
+			{Name: "pages", Label: "Pages", Type: "number", Widget: "number", Group: "Details"},
+			{Name: "author_id", Label: "Author", Type: "string", Widget: "select", Relation: "authors"},
+		},
+		Store: books,
+	}), books
+}
+
+// serve sends a request to the panel, forms are submitted with a valid CSRF token
+func serve(a *Admin, method, target string, form url.Values, roles ...string) *httptest.ResponseRecorder {
+	var req *http.Request
+	if form != nil {
+		form.Set("csrf", strings.Repeat("a", 64))
+		req = httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(&http.Cookie{Name: csrfCookie, Value: strings.Repeat("a", 64)})
+	} else {
+		req = httptest.NewRequest(method, target, nil)
+	}
+	req = req.WithContext(context.WithValue(req.Context(), "roles", roles))
+	rec := httptest.NewRecorder()
+	a.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestListShowsColumnsAndRelations(t *testing.T) {
+	a, _ := newTestAdmin()
+	rec := serve(a, http.MethodGet, "/admin/books", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{"Earthsea", "<a href=\"/admin/authors/a1\">Ursula</a>", "sort=name", "href=\"/admin/books/b1/edit\""} {
+		if !strings.Contains(body, want) {
+			t.Errorf("list does not contain %q", want)
+		}
+	}
+	if strings.Contains(body, "/admin/books/b1/delete") {
+		t.Error("delete is offered without the editor role")
+	}
+}
+
+func TestDetailLinksChildren(t *testing.T) {
+	a, _ := newTestAdmin()
+	rec := serve(a, http.MethodGet, "/admin/authors/a1", nil)
+	if !strings.Contains(rec.Body.String(), "href=\"/admin/books?author_id=a1\"") {
+		t.Errorf("detail does not link the books of the author:\n%s", rec.Body.String())
+	}
+	if rec := serve(a, http.MethodGet, "/admin/authors/missing", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("missing record status = %d, want 404", rec.Code)
+	}
+}
+
+func TestFormOffersRelationPicker(t *testing.T) {
+	a, _ := newTestAdmin()
+	rec := serve(a, http.MethodGet, "/admin/books/b1/edit", nil)
+	body := rec.Body.String()
+	if !strings.Contains(body, "<option value=\"a1\" selected>Ursula</option>") {
+		t.Errorf("form does not offer the authors:\n%s", body)
+	}
+	if !strings.Contains(body, "<legend>Details</legend>") {
+		t.Error("form does not group the fields")
+	}
+}
+
+func TestCreateRedirectsToRecord(t *testing.T) {
+	a, books := newTestAdmin()
+	rec := serve(a, http.MethodPost, "/admin/books", url.Values{"name": {"Tehanu"}, "pages": {"300"}, "author_id": {"a1"}})
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/admin/books/2?notice=created" {
+		t.Fatalf("create = %d %s, want a redirect to the record", rec.Code, rec.Header().Get("Location"))
+	}
+	if pages := books.records["2"]["pages"]; pages != int64(300) {
+		t.Errorf("pages = %#v, want 300", pages)
+	}
+}
+
+func TestCreateRendersErrors(t *testing.T) {
+	a, _ := newTestAdmin()
+	rec := serve(a, http.MethodPost, "/admin/books", url.Values{"name": {""}, "pages": {"many"}})
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want 422", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "Pages must be a whole number") {
+		t.Error("form does not report the invalid number")
+	}
+
+	rec = serve(a, http.MethodPost, "/admin/books", url.Values{"name": {""}})
+	if !strings.Contains(rec.Body.String(), "Name is required") {
+		t.Error("form does not report the errors of the store")
+	}
+}
+
+func TestUpdateKeepsUneditedFields(t *testing.T) {
+	a, books := newTestAdmin()
+	books.records["b1"]["isbn"] = "978-0"
+	rec := serve(a, http.MethodPost, "/admin/books/b1", url.Values{"name": {"A Wizard of Earthsea"}, "pages": {"250"}, "author_id": {"a1"}})
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("status = %d, want 303", rec.Code)
+	}
+	if books.records["b1"]["name"] != "A Wizard of Earthsea" || books.records["b1"]["isbn"] != "978-0" {
+		t.Errorf("record = %v", books.records["b1"])
+	}
+}
+
+func TestRolesGuardActions(t *testing.T) {
+	a, books := newTestAdmin()
+	if rec := serve(a, http.MethodPost, "/admin/books/b1/delete", url.Values{}); rec.Code != http.StatusForbidden {
+		t.Errorf("delete without role = %d, want 403", rec.Code)
+	}
+	if rec := serve(a, http.MethodPost, "/admin/books/b1/delete", url.Values{}, "editor"); rec.Code != http.StatusSeeOther {
+		t.Errorf("delete as editor = %d, want 303", rec.Code)
+	}
+	if _, ok := books.records["b1"]; ok {
+		t.Error("book was not deleted")
+	}
+}
+
+func TestFormsRequireCSRFToken(t *testing.T) {
+	a, _ := newTestAdmin()
+	req := httptest.NewRequest(http.MethodPost, "/admin/books", strings.NewReader("name=Tehanu"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	a.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want 403", rec.Code)
+	}
+}
+`
+
+// AdminSchemaTemplate generates the resource of a schema, with the labels, order, groups
+// and widths of its fields, its table columns and actions and the roles of its actions
+const AdminSchemaTemplate = `package admin
+
+import (
+	"{{.Module}}/internal/models"
+	"{{.Module}}/internal/services"
+)
+
+// {{.Names.PascalCase}}Resource describes the admin pages of {{.Names.Plural}}
+func {{.Names.PascalCase}}Resource(service services.{{.Names.PascalCase}}ServiceInterface) *Resource {
+	return &Resource{
+		Name:        {{printf "%q" .Names.Singular}},
+		Path:        {{printf "%q" .Path}},
+		Label:       {{printf "%q" .Label}},
+		PluralLabel: {{printf "%q" .PluralLabel}},
+{{- if .Group}}
+		Group:       {{printf "%q" .Group}},
+{{- end}}
+		Display:     {{printf "%q" .Display}},
+		Columns:     []string{ {{- range $i, $column := .Columns}}{{if $i}}, {{end}}{{printf "%q" $column}}{{end -}} },
+		Actions:     []string{ {{- range $i, $action := .Actions}}{{if $i}}, {{end}}{{$action}}{{end -}} },
+		Sortable:    {{.Sortable}},
+		Searchable:  {{.Searchable}},
+{{- if .Roles}}
+		Roles: map[string][]string{
+{{- range .Roles}}
+			{{.Action}}: { {{- range $i, $role := .Roles}}{{if $i}}, {{end}}{{printf "%q" $role}}{{end -}} },
+{{- end}}
+		},
+{{- end}}
+		Fields: []*Field{
+{{- range .Fields}}
+			{{.Literal}},
+{{- end}}
+		},
+		Store: ServiceStore[models.{{.Names.PascalCase}}, models.{{.Names.PascalCase}}Request, models.{{.Names.PascalCase}}Filter](service),
+	}
+}
+`
+
+// AdminPanelTemplate generates the constructor of the admin panel of every resource of the project
+const AdminPanelTemplate = `package admin
+
+import (
+	"{{.Module}}/internal/services"
+)
+
+// Services holds the services of the resources the panel manages, resources without a
+// service are left out
+type Services struct {
+{{- range .Resources}}
+	{{.Names.PascalCase}} services.{{.Names.PascalCase}}ServiceInterface
+{{- end}}
+}
+
+// NewPanel creates the admin panel of the resources of the project
+func NewPanel(s Services) *Admin {
+	var resources []*Resource
+{{- range .Resources}}
+	if s.{{.Names.PascalCase}} != nil {
+		resources = append(resources, {{.Names.PascalCase}}Resource(s.{{.Names.PascalCase}}))
+	}
+{{- end}}
+	return New({{printf "%q" .Title}}, resources...)
+}
+`
+
+// AdminRoutesTemplate generates the route mounting the admin panel on the router of the framework
+const AdminRoutesTemplate = `package handlers
+
+import (
+	"net/http"
+{{range .HTTP.Imports}}	"{{.}}"
+{{end}}
+	"{{.Module}}/internal/admin"
+)
+
+// SetupAdminRoutes serves the admin panel below /admin. Register it on the root router,
+// behind the authentication middleware storing the roles of the user in the request context.
+func SetupAdminRoutes({{.HTTP.Router}}, panel *admin.Admin) {
+	{{.HTTP.Mount "/admin" "panel"}}
+}
+`
+
+// AdminLayoutHTML is the page layout of the admin panel, with the RBAC-aware menu
+const AdminLayoutHTML = `{{define "layout"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{if .Heading}}{{.Heading}} · {{end}}{{.Title}}</title>
+<link rel="stylesheet" href="{{.Prefix}}/admin.css">
+</head>
+<body>
+<header class="topbar"><a class="brand" href="{{.Prefix}}/">{{.Title}}</a></header>
+<div class="shell">
+<nav class="menu">
+{{- range .Menu}}
+<section>
+{{- if .Name}}<h2>{{.Name}}</h2>{{end}}
+<ul>
+{{- range .Items}}
+<li><a href="{{.URL}}"{{if .Active}} class="active"{{end}}>{{.Label}}</a></li>
+{{- end}}
+</ul>
+</section>
+{{- end}}
+</nav>
+<main>
+{{- if .Notice}}
+<p class="notice">{{.Notice}}</p>
+{{- end}}
+{{template "content" .}}
+</main>
+</div>
+</body>
+</html>
+{{end}}
+`
+
+// AdminIndexHTML is the dashboard of the admin panel
+const AdminIndexHTML = `{{define "content"}}
+<h1>{{.Title}}</h1>
+{{- range .Menu}}
+<section class="card">
+{{- if .Name}}<h2>{{.Name}}</h2>{{end}}
+<ul class="resources">
+{{- range .Items}}
+<li><a href="{{.URL}}">{{.Label}}</a></li>
+{{- end}}
+</ul>
+</section>
+{{- else}}
+<p class="empty">There is nothing you are allowed to manage.</p>
+{{- end}}
+{{end}}
+`
+
+// AdminListHTML is the table of the records of a resource
+const AdminListHTML = `{{define "content"}}
+<div class="page-header">
+<h1>{{.Heading}}</h1>
+{{- if .Allowed "create"}}
+<a class="button primary" href="{{.Base}}/new">New {{.Resource.Label}}</a>
+{{- end}}
+</div>
+{{- if .Resource.Searchable}}
+<form class="search" method="get" action="{{.Base}}">
+{{- range .Filters}}
+<input type="hidden" name="{{.Name}}" value="{{.Value}}">
+{{- end}}
+<input type="search" name="search" value="{{.Search}}" placeholder="Search {{.Resource.PluralLabel}}">
+<button class="button" type="submit">Search</button>
+</form>
+{{- end}}
+{{- if .Filters}}
+<p class="filters">Filtered by{{range .Filters}} {{.Label}} {{.Value}}{{end}} · <a href="{{.Base}}">Show all</a></p>
+{{- end}}
+<table>
+<thead>
+<tr>
+{{- range .Columns}}
+<th>{{if .URL}}<a href="{{.URL}}">{{.Label}}{{if .Arrow}} {{.Arrow}}{{end}}</a>{{else}}{{.Label}}{{end}}</th>
+{{- end}}
+<th></th>
+</tr>
+</thead>
+<tbody>
+{{- range .Rows}}
+<tr>
+{{- range .Cells}}
+<td>{{if .URL}}<a href="{{.URL}}">{{.Value}}</a>{{else}}{{.Value}}{{end}}</td>
+{{- end}}
+<td class="actions">
+{{- if .ViewURL}}<a href="{{.ViewURL}}">View</a>{{end}}
+{{- if .EditURL}}<a href="{{.EditURL}}">Edit</a>{{end}}
+{{- if .DeleteURL}}
+<form method="post" action="{{.DeleteURL}}" onsubmit="return confirm('Delete this record?')">
+<input type="hidden" name="csrf" value="{{$.CSRF}}">
+<button class="link danger" type="submit">Delete</button>
+</form>
+{{- end}}
+</td>
+</tr>
+{{- else}}
+<tr><td class="empty" colspan="{{.Span}}">No {{.Resource.PluralLabel}} found.</td></tr>
+{{- end}}
+</tbody>
+</table>
+<div class="pagination">
+<span>{{.Summary}}</span>
+{{- if .Prev}} <a class="button" href="{{.Prev}}">Previous</a>{{end}}
+{{- if .Next}} <a class="button" href="{{.Next}}">Next</a>{{end}}
+</div>
+{{end}}
+`
+
+// AdminDetailHTML is the detail page of a record
+const AdminDetailHTML = `{{define "content"}}
+<div class="page-header">
+<h1>{{.Heading}}</h1>
+<div class="actions">
+{{- if .EditURL}}
+<a class="button primary" href="{{.EditURL}}">Edit</a>
+{{- end}}
+{{- if .DeleteURL}}
+<form method="post" action="{{.DeleteURL}}" onsubmit="return confirm('Delete this record?')">
+<input type="hidden" name="csrf" value="{{.CSRF}}">
+<button class="button danger" type="submit">Delete</button>
+</form>
+{{- end}}
+</div>
+</div>
+{{- range .Sections}}
+<section class="card">
+{{- if .Name}}<h2>{{.Name}}</h2>{{end}}
+<dl>
+{{- range .Entries}}
+<dt>{{.Label}}</dt>
+<dd>{{if .URL}}<a href="{{.URL}}">{{.Value}}</a>{{else}}{{.Value}}{{end}}</dd>
+{{- end}}
+</dl>
+</section>
+{{- end}}
+<p><a href="{{.Base}}">Back to {{.Resource.PluralLabel}}</a></p>
+{{end}}
+`
+
+// AdminFormHTML is the creation and edit form of a record
+const AdminFormHTML = `{{define "content"}}
+<h1>{{.Heading}}</h1>
+{{- if .Error}}
+<p class="error">{{.Error}}</p>
+{{- end}}
+<form class="record" method="post" action="{{.Action}}">
+<input type="hidden" name="csrf" value="{{.CSRF}}">
+{{- range .Sections}}
+<fieldset class="card">
+{{- if .Name}}<legend>{{.Name}}</legend>{{end}}
+<div class="fields">
+{{- range .Inputs}}
+<div class="field {{.Width}}{{if .Error}} invalid{{end}}">
+{{- if eq .Widget "checkbox"}}
+<label><input type="checkbox" name="{{.Name}}"{{if .Checked}} checked{{end}}{{if .Disabled}} disabled{{end}}> {{.Label}}</label>
+{{- else}}
+<label for="field-{{.Name}}">{{.Label}}{{if .Required}} *{{end}}</label>
+{{- if eq .Widget "textarea"}}
+<textarea id="field-{{.Name}}" name="{{.Name}}" rows="5" placeholder="{{.Placeholder}}"{{if .Required}} required{{end}}{{if .Disabled}} disabled{{end}}>{{.Value}}</textarea>
+{{- else if eq .Widget "select"}}
+<select id="field-{{.Name}}" name="{{.Name}}"{{if .Required}} required{{end}}{{if .Disabled}} disabled{{end}}>
+<option value=""></option>
+{{- range .Choices}}
+<option value="{{.Value}}"{{if .Selected}} selected{{end}}>{{.Label}}</option>
+{{- end}}
+</select>
+{{- else}}
+<input id="field-{{.Name}}" type="{{.Widget}}" name="{{.Name}}" value="{{.Value}}" placeholder="{{.Placeholder}}"{{if .Step}} step="{{.Step}}"{{end}}{{if .Required}} required{{end}}{{if .Disabled}} disabled{{end}}>
+{{- end}}
+{{- end}}
+{{- if .Help}}
+<small>{{.Help}}</small>
+{{- end}}
+{{- if .Error}}
+<p class="field-error">{{.Error}}</p>
+{{- end}}
+</div>
+{{- end}}
+</div>
+</fieldset>
+{{- end}}
+<div class="form-actions">
+<button class="button primary" type="submit">Save</button>
+<a href="{{.Base}}">Cancel</a>
+</div>
+</form>
+{{end}}
+`
+
+// AdminErrorHTML is the error page of the admin panel
+const AdminErrorHTML = `{{define "content"}}
+<h1>{{.Heading}}</h1>
+<p class="error">{{.Error}}</p>
+<p><a href="{{.Prefix}}/">Back to the dashboard</a></p>
+{{end}}
+`
+
+// AdminCSS is the stylesheet of the admin panel, served by the panel itself
+const AdminCSS = `*, *::before, *::after { box-sizing: border-box; }
+body { margin: 0; font: 15px/1.5 system-ui, -apple-system, "Segoe UI", Roboto, sans-serif; color: #1f2933; background: #f5f7fa; }
+a { color: #2563eb; text-decoration: none; }
+a:hover { text-decoration: underline; }
+h1 { font-size: 1.5rem; margin: 0 0 1rem; }
+h2 { font-size: .8rem; text-transform: uppercase; letter-spacing: .05em; color: #616e7c; margin: 0 0 .5rem; }
+.topbar { background: #1f2933; padding: .75rem 1.5rem; }
+.topbar .brand { color: #fff; font-weight: 600; }
+.shell { display: flex; min-height: calc(100vh - 48px); }
+.menu { width: 220px; flex-shrink: 0; padding: 1.5rem 1rem; background: #fff; border-right: 1px solid #e4e7eb; }
+.menu section + section { margin-top: 1.5rem; }
+.menu ul, .resources { list-style: none; margin: 0; padding: 0; }
+.menu a { display: block; padding: .3rem .6rem; border-radius: 4px; color: #323f4b; }
+.menu a.active, .menu a:hover { background: #e6f0ff; color: #1d4ed8; text-decoration: none; }
+main { flex: 1; padding: 1.5rem 2rem; min-width: 0; }
+.page-header { display: flex; align-items: center; justify-content: space-between; gap: 1rem; margin-bottom: 1rem; }
+.page-header h1 { margin: 0; }
+div.actions { display: flex; gap: .75rem; align-items: center; }
+.actions form { display: inline; margin: 0; }
+td.actions > * + * { margin-left: .75rem; }
+.card { background: #fff; border: 1px solid #e4e7eb; border-radius: 6px; padding: 1rem 1.25rem; margin: 0 0 1rem; }
+.button { display: inline-block; padding: .4rem .9rem; border: 1px solid #cbd2d9; border-radius: 4px; background: #fff; color: #1f2933; font: inherit; cursor: pointer; }
+.button:hover { text-decoration: none; background: #f5f7fa; }
+.button.primary { background: #2563eb; border-color: #2563eb; color: #fff; }
+.button.danger { border-color: #dc2626; color: #dc2626; }
+.link { border: 0; background: none; padding: 0; font: inherit; color: #2563eb; cursor: pointer; }
+.danger { color: #dc2626; }
+.search { display: flex; gap: .5rem; margin-bottom: 1rem; }
+.search input { flex: 1; max-width: 360px; }
+table { width: 100%; border-collapse: collapse; background: #fff; border: 1px solid #e4e7eb; border-radius: 6px; }
+th, td { padding: .55rem .75rem; text-align: left; border-bottom: 1px solid #e4e7eb; vertical-align: top; }
+th { font-size: .8rem; text-transform: uppercase; letter-spacing: .04em; color: #616e7c; }
+th a { color: inherit; }
+td.empty { text-align: center; color: #7b8794; padding: 2rem; }
+.pagination { display: flex; gap: .5rem; align-items: center; margin-top: 1rem; color: #616e7c; }
+.pagination span { margin-right: auto; }
+dl { display: grid; grid-template-columns: minmax(120px, 220px) 1fr; gap: .5rem 1rem; margin: 0; }
+dt { color: #616e7c; }
+dd { margin: 0; white-space: pre-wrap; word-break: break-word; }
+fieldset.card legend { font-weight: 600; padding: 0 .25rem; }
+.fields { display: grid; grid-template-columns: repeat(12, 1fr); gap: 1rem; }
+.field { grid-column: span 12; display: flex; flex-direction: column; gap: .3rem; }
+.field.half { grid-column: span 6; }
+.field.third { grid-column: span 4; }
+.field.quarter { grid-column: span 3; }
+.field label { font-weight: 500; }
+.field small { color: #7b8794; }
+input, select, textarea { font: inherit; padding: .4rem .55rem; border: 1px solid #cbd2d9; border-radius: 4px; background: #fff; width: 100%; }
+input[type="checkbox"] { width: auto; }
+input[type="color"] { padding: .1rem; height: 2.2rem; }
+input:disabled, select:disabled, textarea:disabled { background: #f5f7fa; color: #7b8794; }
+.field.invalid input, .field.invalid select, .field.invalid textarea { border-color: #dc2626; }
+.field-error { color: #dc2626; margin: 0; font-size: .9rem; }
+.notice { background: #ecfdf5; border: 1px solid #a7f3d0; color: #065f46; padding: .6rem .9rem; border-radius: 4px; }
+.error { background: #fef2f2; border: 1px solid #fecaca; color: #991b1b; padding: .6rem .9rem; border-radius: 4px; }
+.filters { color: #616e7c; }
+.form-actions { display: flex; gap: 1rem; align-items: center; }
+@media (max-width: 800px) {
+  .shell { flex-direction: column; }
+  .menu { width: auto; border-right: 0; border-bottom: 1px solid #e4e7eb; }
+  .field.half, .field.third, .field.quarter { grid-column: span 12; }
+}
+`
@@ -295,4 +295,91 @@ func (h *DocsHandler) ServeSwaggerUI(c *gin.Context) {
 func (h *DocsHandler) ServeOpenAPISpec(c *gin.Context) {
 	c.File("./docs/openapi.yaml")
 }
-`
\ No newline at end of file
+`
+
+// DocsPackageTemplate generates docs/docs.go, embedding the OpenAPI documents of the API
+// versions and serving them with Swagger UI on every HTTP framework
+const DocsPackageTemplate = `package docs
+
+import (
+	"embed"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// OpenAPI holds the OpenAPI document of every API version, written by vibercode schema generate
+//
+//go:embed openapi/*.json
+var OpenAPI embed.FS
+
+// Handler serves Swagger UI at the path it is mounted on, e.g. /api/v1/docs/, and the
+// OpenAPI document of every API version below it, e.g. /api/v1/docs/v1.json
+func Handler() http.Handler {
+	documents, err := fs.Sub(OpenAPI, "openapi")
+	if err != nil {
+		panic(err)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		// Frameworks pass the full or the stripped request path, the last element names the document
+		name := path.Base(r.URL.Path)
+		if strings.HasSuffix(name, ".json") {
+			content, err := fs.ReadFile(documents, name)
+			if err != nil {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write(content)
+			return
+		}
+
+		versions, err := fs.Glob(documents, "*.json")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for i, version := range versions {
+			versions[i] = strings.TrimSuffix(version, ".json")
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := swaggerUI.Execute(w, versions); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// swaggerUI lists the documents relative to the page, which is served below the API prefix
+var swaggerUI = template.Must(template.New("docs").Parse(` + "`" + `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>{{.Title}} API documentation</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-standalone-preset.js"></script>
+  <script>
+    const base = window.location.pathname.replace(/\/?$/, '/');
+    const versions = {{"{{"}}.{{"}}"}};
+    window.ui = SwaggerUIBundle({
+      urls: versions.slice().reverse().map((version) => ({ url: base + version + '.json', name: version })),
+      dom_id: '#swagger-ui',
+      presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+      layout: 'StandaloneLayout',
+    });
+  </script>
+</body>
+</html>
+` + "`" + `))
+`
@@ -0,0 +1,533 @@
+package templates
+
+// AuthPackageTemplate generates the accounts and JWT tokens of the auth feature
+const AuthPackageTemplate = `// Package auth registers and logs in the users of the API and authenticates their requests
+// with bearer tokens, JWTs signed with HMAC-SHA256.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"{{.Module}}/internal/apperrors"
+)
+
+const (
+	// MinSecretLength is the minimum length of the secret signing the tokens
+	MinSecretLength = 32
+	// MinPasswordLength is the minimum length of passwords
+	MinPasswordLength = 8
+	// MaxPasswordLength is the maximum length of passwords, bcrypt ignores longer ones
+	MaxPasswordLength = 72
+	// TokenTTL is how long tokens are valid by default
+	TokenTTL = 24 * time.Hour
+	// AdminRole is the role allowed everything
+	AdminRole = "admin"
+)
+
+// User is an account of the API. Roles are granted in the database, as a comma-separated
+// list in the roles column.
+type User struct {
+	ID           uint      ` + "`" + `json:"id" gorm:"primaryKey"` + "`" + `
+	Email        string    ` + "`" + `json:"email" gorm:"uniqueIndex;size:255;not null"` + "`" + `
+	PasswordHash string    ` + "`" + `json:"-" gorm:"not null"` + "`" + `
+	RoleList     string    ` + "`" + `json:"-" gorm:"column:roles;not null;default:''"` + "`" + `
+	CreatedAt    time.Time ` + "`" + `json:"created_at"` + "`" + `
+	UpdatedAt    time.Time ` + "`" + `json:"updated_at"` + "`" + `
+}
+
+// TableName keeps the accounts apart from a users resource of the API
+func (User) TableName() string {
+	return "auth_users"
+}
+
+// Roles returns the roles granted to the user
+func (u *User) Roles() []string {
+	var roles []string
+	for _, role := range strings.Split(u.RoleList, ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// Claims are the claims of the tokens, their subject is the ID of the user
+type Claims struct {
+	Email string   ` + "`" + `json:"email"` + "`" + `
+	Roles []string ` + "`" + `json:"roles,omitempty"` + "`" + `
+	jwt.RegisteredClaims
+}
+
+// UserID returns the ID of the user the token was issued to
+func (c *Claims) UserID() uint {
+	id, _ := strconv.ParseUint(c.Subject, 10, 64)
+	return uint(id)
+}
+
+// HasRole reports whether the user was granted role or is an admin
+func (c *Claims) HasRole(role string) bool {
+	for _, granted := range c.Roles {
+		if granted == role || granted == AdminRole {
+			return true
+		}
+	}
+	return false
+}
+
+// Token is an issued bearer token
+type Token struct {
+	AccessToken string    ` + "`" + `json:"access_token"` + "`" + `
+	TokenType   string    ` + "`" + `json:"token_type"` + "`" + `
+	ExpiresAt   time.Time ` + "`" + `json:"expires_at"` + "`" + `
+}
+
+// Service registers users, issues their tokens and verifies them
+type Service struct {
+	db     *gorm.DB
+	secret []byte
+	// TTL is how long issued tokens are valid
+	TTL time.Duration
+}
+
+// Migrate creates or updates the table of the accounts
+func Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(&User{})
+}
+
+// NewService creates a service signing tokens with secret, which holds at least
+// MinSecretLength bytes, e.g. the output of openssl rand -base64 32
+func NewService(db *gorm.DB, secret string) (*Service, error) {
+	if len(secret) < MinSecretLength {
+		return nil, fmt.Errorf("the JWT secret must hold at least %d bytes, set JWT_SECRET", MinSecretLength)
+	}
+	return &Service{db: db, secret: []byte(secret), TTL: TokenTTL}, nil
+}
+
+// Register creates the account of email
+func (s *Service) Register(ctx context.Context, email, password string) (*User, error) {
+	email = normalizeEmail(email)
+	var fields []apperrors.FieldError
+	if !strings.Contains(email, "@") {
+		fields = append(fields, apperrors.Field("email", "email", "email must be a valid email address"))
+	}
+	if len(password) < MinPasswordLength || len(password) > MaxPasswordLength {
+		fields = append(fields, apperrors.Field("password", "len", fmt.Sprintf("password must hold %d to %d characters", MinPasswordLength, MaxPasswordLength)))
+	}
+	if err := apperrors.Invalid(fields...); err != nil {
+		return nil, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	user := &User{Email: email, PasswordHash: string(hash)}
+	if err := s.db.WithContext(ctx).Create(user).Error; err != nil {
+		return nil, apperrors.FromDatabase(err, "user")
+	}
+	return user, nil
+}
+
+var (
+	dummyHash     []byte
+	dummyHashOnce sync.Once
+)
+
+// Login checks the password of email and issues a token. Unknown emails are compared with a
+// dummy hash, so that they take as long as wrong passwords.
+func (s *Service) Login(ctx context.Context, email, password string) (*Token, error) {
+	var user User
+	err := s.db.WithContext(ctx).Where("email = ?", normalizeEmail(email)).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		dummyHashOnce.Do(func() {
+			dummyHash, _ = bcrypt.GenerateFromPassword([]byte("not a password"), bcrypt.DefaultCost)
+		})
+		_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return nil, apperrors.Unauthorized("invalid email or password")
+	}
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return nil, apperrors.Unauthorized("invalid email or password")
+	}
+	return s.Issue(&user)
+}
+
+// User returns the account of id
+func (s *Service) User(ctx context.Context, id uint) (*User, error) {
+	var user User
+	err := s.db.WithContext(ctx).First(&user, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperrors.NotFound("user %d not found", id)
+	}
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	return &user, nil
+}
+
+// Issue issues a token to user, holding the roles granted to the user at that time
+func (s *Service) Issue(user *User) (*Token, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.TTL)
+	claims := Claims{
+		Email: user.Email,
+		Roles: user.Roles(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatUint(uint64(user.ID), 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	return &Token{AccessToken: signed, TokenType: "Bearer", ExpiresAt: expiresAt}, nil
+}
+
+// Parse verifies a token and returns its claims. Tokens without expiry or signed with another
+// algorithm are refused.
+func (s *Service) Parse(token string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, apperrors.Unauthorized("invalid or expired token")
+	}
+	return claims, nil
+}
+
+// authorize returns the claims of the bearer token of an Authorization header. With roles,
+// the user must hold one of them.
+func (s *Service) authorize(header string, roles []string) (*Claims, error) {
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok || token == "" {
+		return nil, apperrors.Unauthorized("missing bearer token")
+	}
+	claims, err := s.Parse(token)
+	if err != nil || len(roles) == 0 {
+		return claims, err
+	}
+	for _, role := range roles {
+		if claims.HasRole(role) {
+			return claims, nil
+		}
+	}
+	return nil, apperrors.Forbidden("requires one of the roles %s", strings.Join(roles, ", "))
+}
+
+type claimsKey struct{}
+
+// WithClaims returns a copy of ctx holding the claims of an authenticated request
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// FromContext returns the claims of the authenticated request of ctx
+func FromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*Claims)
+	return claims, ok
+}
+
+// Roles returns the roles of the user of an authenticated request, e.g. as the Roles of the
+// admin panel
+func Roles(r *http.Request) []string {
+	if claims, ok := FromContext(r.Context()); ok {
+		return claims.Roles
+	}
+	return nil
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+`
+
+// AuthHTTPTemplate generates the account endpoints and the authentication middleware of the
+// auth feature
+const AuthHTTPTemplate = `package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+
+{{- if .HTTP.Is "fiber"}}
+
+	"github.com/gofiber/fiber/v2"
+{{- else if .HTTP.Is "echo"}}
+
+	"github.com/labstack/echo/v4"
+{{- else if .HTTP.Is "gin"}}
+
+	"github.com/gin-gonic/gin"
+{{- end}}
+
+	"{{.Module}}/internal/apperrors"
+)
+
+// credentials is the body of the register and login requests
+type credentials struct {
+	Email    string ` + "`" + `json:"email"` + "`" + `
+	Password string ` + "`" + `json:"password"` + "`" + `
+}
+
+// Handler serves the account endpoints below the path it is mounted on: POST register,
+// POST login and GET me
+func (s *Service) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch name := path.Base(r.URL.Path); {
+		case name == "register" && r.Method == http.MethodPost:
+			s.register(w, r)
+		case name == "login" && r.Method == http.MethodPost:
+			s.login(w, r)
+		case name == "me" && r.Method == http.MethodGet:
+			s.me(w, r)
+		default:
+			writeProblem(w, r, apperrors.NotFound("%s %s not found", r.Method, r.URL.Path))
+		}
+	})
+}
+
+func (s *Service) register(w http.ResponseWriter, r *http.Request) {
+	var body credentials
+	if err := decode(w, r, &body); err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+	user, err := s.Register(r.Context(), body.Email, body.Password)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, user)
+}
+
+func (s *Service) login(w http.ResponseWriter, r *http.Request) {
+	var body credentials
+	if err := decode(w, r, &body); err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+	token, err := s.Login(r.Context(), body.Email, body.Password)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, token)
+}
+
+func (s *Service) me(w http.ResponseWriter, r *http.Request) {
+	claims, err := s.authorize(r.Header.Get("Authorization"), nil)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+	user, err := s.User(r.Context(), claims.UserID())
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, user)
+}
+
+// decode reads the JSON body of a request, up to 1 MiB
+func decode(w http.ResponseWriter, r *http.Request, target interface{}) error {
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(target); err != nil {
+		return apperrors.BadRequest("invalid request body: %v", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func writeProblem(w http.ResponseWriter, r *http.Request, err error) {
+	problem := apperrors.ProblemFor(err, r.URL.Path)
+	w.Header().Set("Content-Type", apperrors.ContentType)
+	w.WriteHeader(problem.Status)
+	_ = json.NewEncoder(w).Encode(problem)
+}
+{{- if .HTTP.Is "gin"}}
+
+// Middleware authenticates requests with their bearer token and, with roles, requires the
+// user to hold one of them. The claims are available through FromContext.
+func (s *Service) Middleware(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, err := s.authorize(c.GetHeader("Authorization"), roles)
+		if err != nil {
+			problem := apperrors.ProblemFor(err, c.Request.URL.Path)
+			c.Header("Content-Type", apperrors.ContentType)
+			c.AbortWithStatusJSON(problem.Status, problem)
+			return
+		}
+		c.Request = c.Request.WithContext(WithClaims(c.Request.Context(), claims))
+		c.Next()
+	}
+}
+{{- else if .HTTP.Is "echo"}}
+
+// Middleware authenticates requests with their bearer token and, with roles, requires the
+// user to hold one of them. The claims are available through FromContext.
+func (s *Service) Middleware(roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims, err := s.authorize(c.Request().Header.Get("Authorization"), roles)
+			if err != nil {
+				problem := apperrors.ProblemFor(err, c.Request().URL.Path)
+				c.Response().Header().Set(echo.HeaderContentType, apperrors.ContentType)
+				return c.JSON(problem.Status, problem)
+			}
+			c.SetRequest(c.Request().WithContext(WithClaims(c.Request().Context(), claims)))
+			return next(c)
+		}
+	}
+}
+{{- else if .HTTP.Is "fiber"}}
+
+// Middleware authenticates requests with their bearer token and, with roles, requires the
+// user to hold one of them. The claims are available through FromContext(c.UserContext()).
+func (s *Service) Middleware(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, err := s.authorize(c.Get(fiber.HeaderAuthorization), roles)
+		if err != nil {
+			problem := apperrors.ProblemFor(err, c.Path())
+			return c.Status(problem.Status).JSON(problem, apperrors.ContentType)
+		}
+		c.SetUserContext(WithClaims(c.UserContext(), claims))
+		return c.Next()
+	}
+}
+{{- else}}
+
+// Middleware authenticates requests with their bearer token and, with roles, requires the
+// user to hold one of them. The claims are available through FromContext.
+func (s *Service) Middleware(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, err := s.authorize(r.Header.Get("Authorization"), roles)
+			if err != nil {
+				writeProblem(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(WithClaims(r.Context(), claims)))
+		})
+	}
+}
+{{- end}}
+`
+
+// AuthTestTemplate generates the tests of the tokens of the auth feature
+const AuthTestTemplate = `package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"{{.Module}}/internal/apperrors"
+)
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	service, err := NewService(nil, strings.Repeat("s", MinSecretLength))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return service
+}
+
+func TestShortSecretRefused(t *testing.T) {
+	if _, err := NewService(nil, "secret"); err == nil {
+		t.Fatal("short secret accepted")
+	}
+}
+
+func TestIssuedTokenAuthorizes(t *testing.T) {
+	service := newTestService(t)
+	token, err := service.Issue(&User{ID: 7, Email: "ada@example.com", RoleList: "editor, billing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := service.authorize("Bearer "+token.AccessToken, []string{"editor"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.UserID() != 7 || claims.Email != "ada@example.com" || len(claims.Roles) != 2 {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+	if _, err := service.authorize("Bearer "+token.AccessToken, []string{AdminRole}); !errors.Is(err, apperrors.ErrForbidden) {
+		t.Errorf("missing role: got %v, want forbidden", err)
+	}
+}
+
+func TestAdminHoldsEveryRole(t *testing.T) {
+	claims := &Claims{Roles: []string{AdminRole}}
+	if !claims.HasRole("editor") {
+		t.Error("admin does not hold the editor role")
+	}
+}
+
+func TestInvalidTokensRefused(t *testing.T) {
+	service := newTestService(t)
+	expired := newTestService(t)
+	expired.TTL = -time.Minute
+	expiredToken, err := expired.Issue(&User{ID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreign, err := NewService(nil, strings.Repeat("f", MinSecretLength))
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreignToken, err := foreign.Issue(&User{ID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, header := range map[string]string{
+		"missing":  "",
+		"basic":    "Basic dXNlcjpwYXNz",
+		"expired":  "Bearer " + expiredToken.AccessToken,
+		"foreign":  "Bearer " + foreignToken.AccessToken,
+		"malformed": "Bearer not.a.token",
+	} {
+		if _, err := service.authorize(header, nil); !errors.Is(err, apperrors.ErrUnauthorized) {
+			t.Errorf("%s token: got %v, want unauthorized", name, err)
+		}
+	}
+}
+
+func TestMeRequiresToken(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newTestService(t).Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/auth/me", nil))
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	if contentType := rec.Header().Get("Content-Type"); contentType != apperrors.ContentType {
+		t.Errorf("content type %q, want %q", contentType, apperrors.ContentType)
+	}
+}
+`
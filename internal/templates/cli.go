@@ -0,0 +1,1186 @@
+package templates
+
+// CLIMainTemplate generates cmd/<name>/main.go, the entrypoint of the command line client
+const CLIMainTemplate = `// Command {{.Name}} is the command line client of the {{.Title}} API
+package main
+
+import (
+	"os"
+
+	"{{.Module}}/internal/cli"
+)
+
+func main() {
+	os.Exit(cli.Execute())
+}
+`
+
+// CLIRootTemplate generates internal/cli/cli.go, the root command with the global flags, the
+// resolution of the profile and the helpers of the resource commands
+const CLIRootTemplate = `// Package cli is the {{.Name}} command line client of the {{.Title}} API, built on the Go SDK of
+// pkg/sdk. Commands are grouped by resource:
+//
+//	{{.Name}} config set default --base-url https://api.example.com --token "$TOKEN"
+//	{{.Name}} {{(index .Resources 0).Names.KebabPlural}} list --filter ... -o table
+//	{{.Name}} {{(index .Resources 0).Names.KebabPlural}} create -f {{(index .Resources 0).Names.KebabCase}}.json
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"{{.Module}}/pkg/sdk"
+)
+
+// Name is the name of the binary
+const Name = "{{.Name}}"
+
+// DefaultBaseURL is the URL of the API when no flag, environment variable or profile sets one
+const DefaultBaseURL = "{{.BaseURL}}"
+
+// Environment variables overriding the config file
+const (
+	EnvConfig  = "{{.EnvPrefix}}_CONFIG"
+	EnvProfile = "{{.EnvPrefix}}_PROFILE"
+	EnvBaseURL = "{{.EnvPrefix}}_BASE_URL"
+	EnvToken   = "{{.EnvPrefix}}_TOKEN"
+)
+
+// App holds the global flags of a command line and the client they configure
+type App struct {
+	ConfigPath string
+	Profile    string
+	BaseURL    string
+	Token      string
+	Output     string
+	HTTPClient *http.Client // http.DefaultClient when nil
+
+	client *sdk.Client
+}
+
+// Execute runs the command line of os.Args, printing errors to stderr, and returns the exit code
+func Execute() int {
+	cmd := NewRootCommand(&App{})
+	if err := cmd.Execute(); err != nil {
+		printError(cmd.ErrOrStderr(), err)
+		return 1
+	}
+	return 0
+}
+
+// NewRootCommand creates the command line of the API
+func NewRootCommand(app *App) *cobra.Command {
+	root := &cobra.Command{
+		Use:           Name,
+		Short:         "Command line client of the {{.Title}} API",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	flags := root.PersistentFlags()
+	flags.StringVar(&app.ConfigPath, "config", "", "Config file holding the profiles (default $"+EnvConfig+" or "+DefaultConfigPath()+")")
+	flags.StringVar(&app.Profile, "profile", "", "Profile of the config file (default $"+EnvProfile+" or the current profile)")
+	flags.StringVar(&app.BaseURL, "base-url", "", "URL of the API (default $"+EnvBaseURL+" or the URL of the profile)")
+	flags.StringVar(&app.Token, "token", "", "Bearer token (default $"+EnvToken+" or the token of the profile)")
+	flags.StringVarP(&app.Output, "output", "o", "table", "Output format: table, json or yaml")
+	_ = root.RegisterFlagCompletionFunc("output", completeValues(outputFormats))
+	_ = root.RegisterFlagCompletionFunc("profile", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		config, err := LoadConfig(app.configPath())
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return config.names(), cobra.ShellCompDirectiveNoFileComp
+	})
+
+	root.AddCommand(newConfigCommand(app))
+{{- range .Resources}}
+	root.AddCommand(new{{.Names.PascalCase}}Command(app))
+{{- end}}
+	return root
+}
+
+// Client returns the SDK client of the resolved profile
+func (a *App) Client() (*sdk.Client, error) {
+	if a.client != nil {
+		return a.client, nil
+	}
+	profile, err := a.resolve()
+	if err != nil {
+		return nil, err
+	}
+	var options []sdk.Option
+	if profile.Token != "" {
+		options = append(options, sdk.WithBearerToken(profile.Token))
+	}
+	if a.HTTPClient != nil {
+		options = append(options, sdk.WithHTTPClient(a.HTTPClient))
+	}
+	a.client = sdk.New(profile.BaseURL, options...)
+	return a.client, nil
+}
+
+// resolve merges the selected profile of the config file with the environment and the flags,
+// the flags winning
+func (a *App) resolve() (Profile, error) {
+	profile := Profile{BaseURL: DefaultBaseURL}
+	config, err := LoadConfig(a.configPath())
+	if err != nil {
+		return profile, err
+	}
+	name := firstValue(a.Profile, os.Getenv(EnvProfile))
+	if name != "" {
+		selected, ok := config.Profiles[name]
+		if !ok {
+			return profile, fmt.Errorf("unknown profile %q, create it with '%s config set %s --base-url <url>'", name, Name, name)
+		}
+		profile = selected
+	} else if selected, ok := config.Profiles[config.Current]; ok {
+		profile = selected
+	}
+	profile.BaseURL = firstValue(a.BaseURL, os.Getenv(EnvBaseURL), profile.BaseURL, DefaultBaseURL)
+	profile.Token = firstValue(a.Token, os.Getenv(EnvToken), profile.Token)
+	return profile, nil
+}
+
+func (a *App) configPath() string {
+	return firstValue(a.ConfigPath, os.Getenv(EnvConfig), DefaultConfigPath())
+}
+
+// listFlags are the flags of the list commands
+type listFlags struct {
+	filters  []string
+	include  []string
+	sort     string
+	order    string
+	search   string
+	page     int
+	pageSize int
+	all      bool
+}
+
+// register adds the flags to a list command, completing the names of the filters and relations
+func (f *listFlags) register(cmd *cobra.Command, filters, includes []string) {
+	flags := cmd.Flags()
+	flags.StringArrayVar(&f.filters, "filter", nil, "Filter as field=value, repeatable ("+strings.Join(filters, ", ")+")")
+	flags.StringVar(&f.sort, "sort", "", "Field to sort by")
+	flags.StringVar(&f.order, "order", string(sdk.Asc), "Sort order: asc or desc")
+	flags.StringVar(&f.search, "search", "", "Full text search")
+	flags.IntVar(&f.page, "page", 0, "Page to list, starting at 1")
+	flags.IntVar(&f.pageSize, "page-size", 0, "Number of records of a page")
+	flags.BoolVar(&f.all, "all", false, "List the records of every page")
+	if len(includes) > 0 {
+		flags.StringSliceVar(&f.include, "include", nil, "Related records to embed ("+strings.Join(includes, ", ")+")")
+		_ = cmd.RegisterFlagCompletionFunc("include", completeValues(includes))
+	}
+	_ = cmd.RegisterFlagCompletionFunc("order", completeValues([]string{string(sdk.Asc), string(sdk.Desc)}))
+	_ = cmd.RegisterFlagCompletionFunc("filter", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		completions := make([]string, len(filters))
+		for i, name := range filters {
+			completions[i] = name + "="
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	})
+}
+
+// sortOrder validates the order flag
+func (f *listFlags) sortOrder() (sdk.Order, error) {
+	switch order := sdk.Order(strings.ToLower(f.order)); order {
+	case sdk.Asc, sdk.Desc:
+		return order, nil
+	default:
+		return "", fmt.Errorf("invalid order %q, expected asc or desc", f.order)
+	}
+}
+
+// splitFilter splits a filter flag into the field and its value
+func splitFilter(filter string, names []string) (string, string, error) {
+	name, value, ok := strings.Cut(filter, "=")
+	if !ok || name == "" {
+		return "", "", fmt.Errorf("invalid filter %q, expected field=value", filter)
+	}
+	for _, known := range names {
+		if name == known {
+			return name, value, nil
+		}
+	}
+	return "", "", fmt.Errorf("unknown filter %q, expected one of: %s", name, strings.Join(names, ", "))
+}
+
+func parseInt(name, value string) (int64, error) {
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q of filter %s, expected an integer", value, name)
+	}
+	return n, nil
+}
+
+func parseFloat(name, value string) (float64, error) {
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q of filter %s, expected a number", value, name)
+	}
+	return n, nil
+}
+
+func parseBool(name, value string) (bool, error) {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value %q of filter %s, expected true or false", value, name)
+	}
+	return b, nil
+}
+
+// parseTime parses an RFC 3339 time or a YYYY-MM-DD date
+func parseTime(name, value string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid value %q of filter %s, expected a date (2006-01-02) or an RFC 3339 time", value, name)
+}
+
+// readRequest decodes the JSON file of a create or update command into req, - reads stdin
+func readRequest(cmd *cobra.Command, path string, req interface{}) error {
+	var r io.Reader
+	if path == "-" {
+		r = cmd.InOrStdin()
+	} else {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+	decoder := json.NewDecoder(r)
+	// Misspelled fields would be silently dropped
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(req); err != nil {
+		return fmt.Errorf("invalid request %s: %w", path, err)
+	}
+	return nil
+}
+
+// printError prints an error, with the problem details and rejected fields of API errors
+func printError(w io.Writer, err error) {
+	var apiErr *sdk.Error
+	if !errors.As(err, &apiErr) {
+		fmt.Fprintf(w, "Error: %v\n", err)
+		return
+	}
+	message := apiErr.Detail
+	if message == "" {
+		message = apiErr.Title
+	}
+	fmt.Fprintf(w, "Error: %s (%d %s)\n", message, apiErr.Status, apiErr.Code)
+	for _, field := range apiErr.Errors {
+		fmt.Fprintf(w, "  %s: %s\n", field.Field, field.Message)
+	}
+}
+
+func completeValues(values []string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return values, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func firstValue(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+`
+
+// CLIConfigTemplate generates internal/cli/config.go, the config file of the profiles and the
+// config commands managing them
+const CLIConfigTemplate = `package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Profile is an API the command line calls and the token it calls it with
+type Profile struct {
+	BaseURL string ` + "`" + `yaml:"base_url"` + "`" + `
+	Token   string ` + "`" + `yaml:"token,omitempty"` + "`" + `
+}
+
+// Config is the config file holding the profiles
+type Config struct {
+	Current  string             ` + "`" + `yaml:"current,omitempty"` + "`" + `
+	Profiles map[string]Profile ` + "`" + `yaml:"profiles,omitempty"` + "`" + `
+}
+
+// DefaultConfigPath returns the config file in the user config directory
+func DefaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return Name + ".yaml"
+	}
+	return filepath.Join(dir, Name, "config.yaml")
+}
+
+// LoadConfig reads a config file, a missing file has no profiles
+func LoadConfig(path string) (*Config, error) {
+	config := &Config{Profiles: make(map[string]Profile)}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(content, config); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	if config.Profiles == nil {
+		config.Profiles = make(map[string]Profile)
+	}
+	return config, nil
+}
+
+// Save writes the config file, readable by the user only since it holds tokens
+func (c *Config) Save(path string) error {
+	content, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0o600)
+}
+
+func (c *Config) names() []string {
+	return sortedKeys(c.Profiles)
+}
+
+// newConfigCommand creates the commands managing the profiles of the config file
+func newConfigCommand(app *App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage the profiles of the API URLs and tokens",
+	}
+	cmd.AddCommand(newConfigSetCommand(app), newConfigUseCommand(app), newConfigListCommand(app), newConfigDeleteCommand(app))
+	return cmd
+}
+
+func newConfigSetCommand(app *App) *cobra.Command {
+	var profile Profile
+	cmd := &cobra.Command{
+		Use:   "set <profile>",
+		Short: "Create or update a profile, the first profile becomes the current one",
+		Example: "  " + Name + " config set production --base-url https://api.example.com --token \"$TOKEN\"",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := LoadConfig(app.configPath())
+			if err != nil {
+				return err
+			}
+			updated := config.Profiles[args[0]]
+			if cmd.Flags().Changed("base-url") {
+				updated.BaseURL = profile.BaseURL
+			}
+			if cmd.Flags().Changed("token") {
+				updated.Token = profile.Token
+			}
+			if updated.BaseURL == "" {
+				return fmt.Errorf("profile %s has no URL, pass --base-url", args[0])
+			}
+			config.Profiles[args[0]] = updated
+			if config.Current == "" {
+				config.Current = args[0]
+			}
+			if err := config.Save(app.configPath()); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Profile %s saved\n", args[0])
+			return nil
+		},
+	}
+	// The global flags configure the client, these configure the profile
+	cmd.Flags().StringVar(&profile.BaseURL, "base-url", "", "URL of the API")
+	cmd.Flags().StringVar(&profile.Token, "token", "", "Bearer token")
+	return cmd
+}
+
+func newConfigUseCommand(app *App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "use <profile>",
+		Short: "Select the profile commands use by default",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := LoadConfig(app.configPath())
+			if err != nil {
+				return err
+			}
+			if _, ok := config.Profiles[args[0]]; !ok {
+				return fmt.Errorf("unknown profile %q", args[0])
+			}
+			config.Current = args[0]
+			if err := config.Save(app.configPath()); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Using profile %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+func newConfigListCommand(app *App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the profiles, tokens are masked",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := LoadConfig(app.configPath())
+			if err != nil {
+				return err
+			}
+			type row struct {
+				Name    string ` + "`" + `json:"name"` + "`" + `
+				Current bool   ` + "`" + `json:"current"` + "`" + `
+				BaseURL string ` + "`" + `json:"base_url"` + "`" + `
+				Token   string ` + "`" + `json:"token"` + "`" + `
+			}
+			rows := []row{}
+			for _, name := range config.names() {
+				profile := config.Profiles[name]
+				token := ""
+				if profile.Token != "" {
+					token = "********"
+				}
+				rows = append(rows, row{Name: name, Current: name == config.Current, BaseURL: profile.BaseURL, Token: token})
+			}
+			return app.print(cmd.OutOrStdout(), rows, []string{"name", "current", "base_url", "token"})
+		},
+	}
+}
+
+func newConfigDeleteCommand(app *App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <profile>",
+		Short: "Delete a profile",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := LoadConfig(app.configPath())
+			if err != nil {
+				return err
+			}
+			if _, ok := config.Profiles[args[0]]; !ok {
+				return fmt.Errorf("unknown profile %q", args[0])
+			}
+			delete(config.Profiles, args[0])
+			if config.Current == args[0] {
+				config.Current = ""
+			}
+			if err := config.Save(app.configPath()); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Profile %s deleted\n", args[0])
+			return nil
+		},
+	}
+}
+`
+
+// CLIOutputTemplate generates internal/cli/output.go, printing records as tables, JSON or YAML
+const CLIOutputTemplate = `package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// outputFormats are the values of the output flag
+var outputFormats = []string{"table", "json", "yaml"}
+
+// maxCellWidth truncates the long values of tables
+const maxCellWidth = 48
+
+// print prints a record or a list of records in the output format, tables have the given
+// columns of the records
+func (a *App) print(w io.Writer, value interface{}, columns []string) error {
+	switch a.Output {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	case "yaml":
+		// Through JSON so that keys are the JSON names of the fields
+		generic, err := toGeneric(value)
+		if err != nil {
+			return err
+		}
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(generic); err != nil {
+			return err
+		}
+		return encoder.Close()
+	case "table", "":
+		return printTable(w, value, columns)
+	default:
+		return fmt.Errorf("unknown output format %q, expected %s", a.Output, strings.Join(outputFormats, ", "))
+	}
+}
+
+// printPage prints a page of records, tables end with the number of records listed
+func (a *App) printPage(w io.Writer, page interface{}, records interface{}, total int64, columns []string) error {
+	if a.Output != "table" && a.Output != "" {
+		return a.print(w, page, columns)
+	}
+	if err := printTable(w, records, columns); err != nil {
+		return err
+	}
+	rows, err := toRows(records)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "\n%d of %d\n", len(rows), total)
+	return err
+}
+
+func printTable(w io.Writer, value interface{}, columns []string) error {
+	rows, err := toRows(value)
+	if err != nil {
+		return err
+	}
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = strings.ToUpper(column)
+	}
+	fmt.Fprintln(table, strings.Join(header, "\t"))
+	for _, row := range rows {
+		cells := make([]string, len(columns))
+		for i, column := range columns {
+			cells[i] = cell(row[column])
+		}
+		fmt.Fprintln(table, strings.Join(cells, "\t"))
+	}
+	return table.Flush()
+}
+
+// toRows converts a record or a list of records to JSON objects
+func toRows(value interface{}) ([]map[string]interface{}, error) {
+	generic, err := toGeneric(value)
+	if err != nil {
+		return nil, err
+	}
+	switch generic := generic.(type) {
+	case map[string]interface{}:
+		return []map[string]interface{}{generic}, nil
+	case []interface{}:
+		rows := make([]map[string]interface{}, 0, len(generic))
+		for _, item := range generic {
+			if row, ok := item.(map[string]interface{}); ok {
+				rows = append(rows, row)
+			}
+		}
+		return rows, nil
+	}
+	return nil, nil
+}
+
+// toGeneric converts a value to its JSON objects, keeping numbers exact
+func toGeneric(value interface{}) (interface{}, error) {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return numbers(generic), nil
+}
+
+// numbers converts the json.Number values, which YAML would print as strings
+func numbers(value interface{}) interface{} {
+	switch value := value.(type) {
+	case json.Number:
+		if n, err := value.Int64(); err == nil {
+			return n
+		}
+		if f, err := value.Float64(); err == nil {
+			return f
+		}
+		return value.String()
+	case map[string]interface{}:
+		for key, item := range value {
+			value[key] = numbers(item)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = numbers(item)
+		}
+	}
+	return value
+}
+
+// cell formats a value of a table, objects and lists as compact JSON
+func cell(value interface{}) string {
+	var s string
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		s = value
+	case map[string]interface{}, []interface{}:
+		content, _ := json.Marshal(value)
+		s = string(content)
+	default:
+		s = fmt.Sprint(value)
+	}
+	s = strings.Join(strings.Fields(s), " ")
+	if runes := []rune(s); len(runes) > maxCellWidth {
+		s = string(runes[:maxCellWidth-1]) + "…"
+	}
+	return s
+}
+`
+
+// CLIResourceTemplate generates the list, get, create, update, delete and relation commands of
+// a resource
+const CLIResourceTemplate = `package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"{{.Module}}/pkg/sdk"
+)
+
+// {{.Names.CamelCase}}Columns are the fields of the {{.Names.Plural}} in tables
+var {{.Names.CamelCase}}Columns = []string{ {{- range $i, $column := .Columns}}{{if $i}}, {{end}}{{printf "%q" $column}}{{end -}} }
+
+// {{.Names.CamelCase}}Filters are the fields the {{.Names.Plural}} are filtered on
+var {{.Names.CamelCase}}Filters = []string{ {{- range $i, $filter := .Filters}}{{if $i}}, {{end}}{{printf "%q" $filter.JSON}}{{end -}} }
+
+// {{.Names.CamelCase}}Includes are the relations embedded in the {{.Names.Plural}}
+var {{.Names.CamelCase}}Includes = []string{ {{- range $i, $include := .Includes}}{{if $i}}, {{end}}{{printf "%q" $include}}{{end -}} }
+
+// new{{.Names.PascalCase}}Command creates the commands of the {{.Names.Plural}}
+func new{{.Names.PascalCase}}Command(app *App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "{{.Names.KebabPlural}}",
+{{- if ne .Names.KebabCase .Names.KebabPlural}}
+		Aliases: []string{"{{.Names.KebabCase}}"},
+{{- end}}
+		Short: "Manage {{.Names.Plural}}",
+	}
+	cmd.AddCommand(
+		new{{.Names.PascalCase}}ListCommand(app),
+		new{{.Names.PascalCase}}GetCommand(app),
+		new{{.Names.PascalCase}}CreateCommand(app),
+		new{{.Names.PascalCase}}UpdateCommand(app),
+		new{{.Names.PascalCase}}DeleteCommand(app),
+{{- range .Children}}
+		new{{$.Names.PascalCase}}{{.Relation}}Command(app),
+{{- end}}
+{{- range .Parents}}
+		new{{$.Names.PascalCase}}{{.Relation}}Command(app),
+{{- end}}
+	)
+	return cmd
+}
+
+func new{{.Names.PascalCase}}ListCommand(app *App) *cobra.Command {
+	var flags listFlags
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List {{.Names.Plural}}",
+		Example: "  " + Name + " {{.Names.KebabPlural}} list{{with .Filters}} --filter {{(index . 0).JSON}}=...{{end}} --page-size 20 -o json",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter, err := new{{.Names.PascalCase}}Filter(&flags)
+			if err != nil {
+				return err
+			}
+			return app.list{{.Names.PascalPlural}}(cmd, filter, flags.all)
+		},
+	}
+	flags.register(cmd, {{.Names.CamelCase}}Filters, {{.Names.CamelCase}}Includes)
+	return cmd
+}
+
+// new{{.Names.PascalCase}}Filter builds the filter of the flags of a list command
+func new{{.Names.PascalCase}}Filter(flags *listFlags) (*sdk.{{.Names.PascalCase}}Filter, error) {
+	filter := sdk.New{{.Names.PascalCase}}Filter()
+	if flags.page > 0 {
+		filter.Page(flags.page)
+	}
+	if flags.pageSize > 0 {
+		filter.PageSize(flags.pageSize)
+	}
+	if flags.sort != "" {
+		order, err := flags.sortOrder()
+		if err != nil {
+			return nil, err
+		}
+		filter.Sort(flags.sort, order)
+	}
+	if flags.search != "" {
+		filter.Search(flags.search)
+	}
+{{- if .Includes}}
+	if len(flags.include) > 0 {
+		filter.Include(flags.include...)
+	}
+{{- end}}
+{{- if .Filters}}
+	for _, expr := range flags.filters {
+		name, value, err := splitFilter(expr, {{.Names.CamelCase}}Filters)
+		if err != nil {
+			return nil, err
+		}
+		switch name {
+{{- range .Filters}}
+		case {{printf "%q" .JSON}}:
+{{- if .Parse}}
+			parsed, err := {{.Parse}}(name, value)
+			if err != nil {
+				return nil, err
+			}
+			filter.{{.Method}}(parsed)
+{{- else}}
+			filter.{{.Method}}(value)
+{{- end}}
+{{- end}}
+		}
+	}
+{{- else}}
+	if len(flags.filters) > 0 {
+		return nil, fmt.Errorf("{{.Names.Plural}} have no filters")
+	}
+{{- end}}
+	return filter, nil
+}
+
+// list{{.Names.PascalPlural}} prints a page of the {{.Names.Plural}} selected by filter, or all of them
+func (a *App) list{{.Names.PascalPlural}}(cmd *cobra.Command, filter *sdk.{{.Names.PascalCase}}Filter, all bool) error {
+	client, err := a.Client()
+	if err != nil {
+		return err
+	}
+	if all {
+		{{.Names.CamelPlural}} := []*sdk.{{.Names.PascalCase}}{}
+		it := client.{{.Names.PascalPlural}}.Iterate(cmd.Context(), filter)
+		for it.Next() {
+			{{.Names.CamelPlural}} = append({{.Names.CamelPlural}}, it.Item())
+		}
+		if err := it.Err(); err != nil {
+			return err
+		}
+		return a.print(cmd.OutOrStdout(), {{.Names.CamelPlural}}, {{.Names.CamelCase}}Columns)
+	}
+	page, err := client.{{.Names.PascalPlural}}.List(cmd.Context(), filter)
+	if err != nil {
+		return err
+	}
+	return a.printPage(cmd.OutOrStdout(), page, page.Data, page.Total, {{.Names.CamelCase}}Columns)
+}
+
+func new{{.Names.PascalCase}}GetCommand(app *App) *cobra.Command {
+{{- if .Includes}}
+	var include []string
+{{- end}}
+	cmd := &cobra.Command{
+		Use:   "get <id>",
+		Short: "Show a {{.Names.Singular}}",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := app.Client()
+			if err != nil {
+				return err
+			}
+			{{.Names.CamelCase}}, err := client.{{.Names.PascalPlural}}.Get(cmd.Context(), args[0]{{if .Includes}}, include...{{end}})
+			if err != nil {
+				return err
+			}
+			return app.print(cmd.OutOrStdout(), {{.Names.CamelCase}}, {{.Names.CamelCase}}Columns)
+		},
+	}
+{{- if .Includes}}
+	cmd.Flags().StringSliceVar(&include, "include", nil, "Related records to embed ({{join .Includes ", "}})")
+	_ = cmd.RegisterFlagCompletionFunc("include", completeValues({{.Names.CamelCase}}Includes))
+{{- end}}
+	return cmd
+}
+
+func new{{.Names.PascalCase}}CreateCommand(app *App) *cobra.Command {
+	var file string
+	cmd := &cobra.Command{
+		Use:     "create -f <file>",
+		Short:   "Create a {{.Names.Singular}} from a JSON file",
+		Example: "  " + Name + " {{.Names.KebabPlural}} create -f {{.Names.KebabCase}}.json",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var req sdk.{{.Names.PascalCase}}Request
+			if err := readRequest(cmd, file, &req); err != nil {
+				return err
+			}
+			client, err := app.Client()
+			if err != nil {
+				return err
+			}
+			{{.Names.CamelCase}}, err := client.{{.Names.PascalPlural}}.Create(cmd.Context(), &req)
+			if err != nil {
+				return err
+			}
+			return app.print(cmd.OutOrStdout(), {{.Names.CamelCase}}, {{.Names.CamelCase}}Columns)
+		},
+	}
+	cmd.Flags().StringVarP(&file, "filename", "f", "", "JSON file of the {{.Names.Singular}}, - reads stdin")
+	_ = cmd.MarkFlagRequired("filename")
+	return cmd
+}
+
+func new{{.Names.PascalCase}}UpdateCommand(app *App) *cobra.Command {
+	var file string
+	cmd := &cobra.Command{
+		Use:   "update <id> -f <file>",
+		Short: "Replace the fields of a {{.Names.Singular}} with a JSON file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var req sdk.{{.Names.PascalCase}}Request
+			if err := readRequest(cmd, file, &req); err != nil {
+				return err
+			}
+			client, err := app.Client()
+			if err != nil {
+				return err
+			}
+			{{.Names.CamelCase}}, err := client.{{.Names.PascalPlural}}.Update(cmd.Context(), args[0], &req)
+			if err != nil {
+				return err
+			}
+			return app.print(cmd.OutOrStdout(), {{.Names.CamelCase}}, {{.Names.CamelCase}}Columns)
+		},
+	}
+	cmd.Flags().StringVarP(&file, "filename", "f", "", "JSON file of the {{.Names.Singular}}, - reads stdin")
+	_ = cmd.MarkFlagRequired("filename")
+	return cmd
+}
+
+func new{{.Names.PascalCase}}DeleteCommand(app *App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <id>...",
+		Short: "Delete {{.Names.Plural}}",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := app.Client()
+			if err != nil {
+				return err
+			}
+			for _, id := range args {
+				if err := client.{{.Names.PascalPlural}}.Delete(cmd.Context(), id); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "{{.Names.PascalCase}} %s deleted\n", id)
+			}
+			return nil
+		},
+	}
+}
+{{- range .Children}}
+
+// new{{$.Names.PascalCase}}{{.Relation}}Command lists the {{.Names.Plural}} of a {{$.Names.Singular}}
+func new{{$.Names.PascalCase}}{{.Relation}}Command(app *App) *cobra.Command {
+	var flags listFlags
+	cmd := &cobra.Command{
+		Use:   "{{.Command}} <id>",
+		Short: "List the {{.Names.Plural}} of a {{$.Names.Singular}}",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter, err := new{{.Names.PascalCase}}Filter(&flags)
+			if err != nil {
+				return err
+			}
+			return app.list{{.Names.PascalPlural}}(cmd, filter.{{.Method}}(args[0]), flags.all)
+		},
+	}
+	flags.register(cmd, {{.Names.CamelCase}}Filters, {{.Names.CamelCase}}Includes)
+	return cmd
+}
+{{- end}}
+{{- range .Parents}}
+
+// new{{$.Names.PascalCase}}{{.Relation}}Command shows the {{.Names.Singular}} of a {{$.Names.Singular}}
+func new{{$.Names.PascalCase}}{{.Relation}}Command(app *App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "{{.Command}} <id>",
+		Short: "Show the {{.Names.Singular}} of a {{$.Names.Singular}}",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := app.Client()
+			if err != nil {
+				return err
+			}
+			{{$.Names.CamelCase}}, err := client.{{$.Names.PascalPlural}}.Get(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			if {{$.Names.CamelCase}}.{{.Field}} == "" {
+				return fmt.Errorf("{{$.Names.Singular}} %s has no {{.Command}}", args[0])
+			}
+			related, err := client.{{.Names.PascalPlural}}.Get(cmd.Context(), {{$.Names.CamelCase}}.{{.Field}})
+			if err != nil {
+				return err
+			}
+			return app.print(cmd.OutOrStdout(), related, {{.Names.CamelCase}}Columns)
+		},
+	}
+}
+{{- end}}
+`
+
+// CLITestTemplate generates internal/cli/cli_test.go, running the command line against a fake API
+const CLITestTemplate = `package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"{{.Module}}/pkg/sdk"
+)
+
+// {{.Test.Resource.Names.CamelCase}}Payload is a valid {{.Test.Resource.Names.PascalCase}}Request
+const {{.Test.Resource.Names.CamelCase}}Payload = {{printf "%q" .Test.Payload}}
+
+const recordID = "65f1c0ffee0000000000cafe"
+
+// fakeAPI serves a {{.Test.Resource.Names.Singular}} and records the requests it receives
+type fakeAPI struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   []string
+}
+
+func newFakeAPI(t *testing.T) (*fakeAPI, string) {
+	t.Helper()
+
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte({{.Test.Resource.Names.CamelCase}}Payload), &record); err != nil {
+		t.Fatalf("invalid payload: %v", err)
+	}
+	record["id"], record["created_at"], record["updated_at"] = recordID, "2024-01-02T15:04:05Z", "2024-01-02T15:04:05Z"
+
+	api := &fakeAPI{}
+	collection := "/api{{.Test.Resource.Path}}"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		api.mu.Lock()
+		api.requests = append(api.requests, r)
+		api.bodies = append(api.bodies, string(body))
+		api.mu.Unlock()
+
+		var response interface{}
+		status := http.StatusOK
+		switch {
+		case r.URL.Path == collection && r.Method == http.MethodGet:
+			response = map[string]interface{}{"data": []interface{}{record}, "total": 1, "page": 1, "page_size": 10}
+		case r.URL.Path == collection && r.Method == http.MethodPost:
+			response, status = record, http.StatusCreated
+		case r.URL.Path == collection+"/"+recordID && r.Method == http.MethodDelete:
+			response = map[string]string{"message": "deleted"}
+		case r.URL.Path == collection+"/"+recordID:
+			response = record
+		default:
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(` + "`" + `{"type":"about:blank","title":"Not Found","status":404,"detail":"record not found","code":"not_found"}` + "`" + `))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+	return api, server.URL
+}
+
+func (a *fakeAPI) last() (*http.Request, string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.requests[len(a.requests)-1], a.bodies[len(a.bodies)-1]
+}
+
+// run runs a command line with a config file in a temporary directory
+func run(t *testing.T, config string, stdin io.Reader, args ...string) (string, error) {
+	t.Helper()
+
+	var out bytes.Buffer
+	cmd := NewRootCommand(&App{})
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	if stdin != nil {
+		cmd.SetIn(stdin)
+	}
+	cmd.SetArgs(append([]string{"--config", config}, args...))
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestListOutputs(t *testing.T) {
+	api, baseURL := newFakeAPI(t)
+	config := filepath.Join(t.TempDir(), "config.yaml")
+
+	for format, want := range map[string]string{
+		"table": "ID",
+		"json":  ` + "`" + `"id": "` + "`" + ` + recordID,
+		"yaml":  "id: " + recordID,
+	} {
+		out, err := run(t, config, nil, "--base-url", baseURL, "{{.Test.Resource.Names.KebabPlural}}", "list", "--page-size", "10", "-o", format)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if !strings.Contains(out, want) || !strings.Contains(out, recordID) {
+			t.Errorf("%s output does not contain %q:\n%s", format, want, out)
+		}
+	}
+	if req, _ := api.last(); req.URL.Query().Get("page_size") != "10" {
+		t.Errorf("query = %s", req.URL.RawQuery)
+	}
+{{- with .Test.Filter}}
+
+	if _, err := run(t, config, nil, "--base-url", baseURL, "{{$.Test.Resource.Names.KebabPlural}}", "list", "--filter", {{printf "%q" .JSON}}+"="+{{.Value}}, "--all"); err != nil {
+		t.Fatalf("filter: %v", err)
+	}
+	if req, _ := api.last(); req.URL.Query().Get({{printf "%q" .JSON}}) != {{.Value}} {
+		t.Errorf("filter: query = %s", req.URL.RawQuery)
+	}
+{{- end}}
+
+	if _, err := run(t, config, nil, "--base-url", baseURL, "{{.Test.Resource.Names.KebabPlural}}", "list", "--filter", "unknown=1"); err == nil || !strings.Contains(err.Error(), "unknown filter") {
+		t.Errorf("unknown filter: err = %v", err)
+	}
+	if _, err := run(t, config, nil, "--base-url", baseURL, "{{.Test.Resource.Names.KebabPlural}}", "get", recordID, "-o", "xml"); err == nil {
+		t.Error("unknown output format: no error")
+	}
+}
+
+func TestCreateFromFile(t *testing.T) {
+	api, baseURL := newFakeAPI(t)
+	dir := t.TempDir()
+	file := filepath.Join(dir, "{{.Test.Resource.Names.KebabCase}}.json")
+	if err := os.WriteFile(file, []byte({{.Test.Resource.Names.CamelCase}}Payload), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	config := filepath.Join(dir, "config.yaml")
+
+	out, err := run(t, config, nil, "--base-url", baseURL, "{{.Test.Resource.Names.KebabPlural}}", "create", "-f", file, "-o", "json")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	req, body := api.last()
+	if req.Method != http.MethodPost || !json.Valid([]byte(body)) || !strings.Contains(out, recordID) {
+		t.Errorf("create: %s %s %s\n%s", req.Method, req.URL.Path, body, out)
+	}
+
+	if _, err := run(t, config, strings.NewReader({{.Test.Resource.Names.CamelCase}}Payload), "--base-url", baseURL, "{{.Test.Resource.Names.KebabPlural}}", "update", recordID, "-f", "-"); err != nil {
+		t.Fatalf("update from stdin: %v", err)
+	}
+	if req, _ := api.last(); req.Method != http.MethodPut {
+		t.Errorf("update: method = %s", req.Method)
+	}
+
+	if _, err := run(t, config, strings.NewReader(` + "`" + `{"misspelled": true}` + "`" + `), "--base-url", baseURL, "{{.Test.Resource.Names.KebabPlural}}", "create", "-f", "-"); err == nil {
+		t.Error("unknown field: no error")
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	api, baseURL := newFakeAPI(t)
+	config := filepath.Join(t.TempDir(), "config.yaml")
+
+	if _, err := run(t, config, nil, "config", "set", "staging", "--base-url", baseURL, "--token", "t0ken"); err != nil {
+		t.Fatalf("config set: %v", err)
+	}
+	out, err := run(t, config, nil, "config", "list", "-o", "json")
+	if err != nil || strings.Contains(out, "t0ken") || !strings.Contains(out, ` + "`" + `"current": true` + "`" + `) {
+		t.Errorf("config list: %v\n%s", err, out)
+	}
+
+	if _, err := run(t, config, nil, "{{.Test.Resource.Names.KebabPlural}}", "get", recordID); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if req, _ := api.last(); req.Header.Get("Authorization") != "Bearer t0ken" {
+		t.Errorf("Authorization = %q", req.Header.Get("Authorization"))
+	}
+	if _, err := run(t, config, nil, "--token", "other", "{{.Test.Resource.Names.KebabPlural}}", "delete", recordID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if req, _ := api.last(); req.Header.Get("Authorization") != "Bearer other" {
+		t.Errorf("--token does not override the profile: %q", req.Header.Get("Authorization"))
+	}
+
+	if _, err := run(t, config, nil, "--profile", "production", "{{.Test.Resource.Names.KebabPlural}}", "get", recordID); err == nil || !strings.Contains(err.Error(), "unknown profile") {
+		t.Errorf("unknown profile: err = %v", err)
+	}
+}
+
+func TestAPIErrors(t *testing.T) {
+	_, baseURL := newFakeAPI(t)
+	config := filepath.Join(t.TempDir(), "config.yaml")
+
+	_, err := run(t, config, nil, "--base-url", baseURL, "{{.Test.Resource.Names.KebabPlural}}", "get", "missing")
+	if !errors.Is(err, sdk.ErrNotFound) {
+		t.Fatalf("err = %v, want not_found", err)
+	}
+	var out bytes.Buffer
+	printError(&out, err)
+	if !strings.Contains(out.String(), "record not found (404 not_found)") {
+		t.Errorf("printed error = %q", out.String())
+	}
+}
+`
@@ -60,7 +60,8 @@ const ConfigTemplate = `{
     "local_path": "{{.Storage.LocalPath}}"{{end}}{{if eq .Storage.Provider "s3"}},
     "s3": {
       "bucket": "{{.Storage.S3.Bucket}}",
-      "region": "{{.Storage.S3.Region}}"
+      "region": "{{.Storage.S3.Region}}"{{if .Storage.S3.Endpoint}},
+      "endpoint": "{{.Storage.S3.Endpoint}}"{{end}}
     }{{end}}{{if eq .Storage.Provider "supabase"}},
     "supabase": {
       "bucket_name": "{{.Storage.Supabase.BucketName}}"
@@ -303,6 +304,8 @@ OAUTH2_REDIRECT_URL={{.Auth.OAuth2.RedirectURL}}
 
 # Storage Configuration
 STORAGE_PROVIDER={{.Storage.Provider}}
+STORAGE_MAX_FILE_SIZE={{.Storage.MaxFileSize}}
+STORAGE_ALLOWED_TYPES={{range $i, $type := .Storage.AllowedTypes}}{{if $i}},{{end}}{{$type}}{{end}}
 {{if eq .Storage.Provider "local"}}
 STORAGE_LOCAL_PATH={{.Storage.LocalPath}}
 {{end}}
@@ -311,6 +314,7 @@ S3_BUCKET={{.Storage.S3.Bucket}}
 S3_REGION={{.Storage.S3.Region}}
 S3_ACCESS_KEY={{.Storage.S3.AccessKey}}
 S3_SECRET_KEY={{.Storage.S3.SecretKey}}
+S3_ENDPOINT={{.Storage.S3.Endpoint}}
 {{end}}
 
 # Cache Configuration
@@ -21,6 +21,8 @@ func GetDockerTemplate(fileName string, config models.DeploymentConfig) string {
 		return getDockerCompose(config)
 	case "docker-compose.production.yml":
 		return getDockerComposeProduction(config)
+	case "docker-compose.worker.yml":
+		return getDockerComposeWorker(config)
 	case ".dockerignore":
 		return getDockerIgnore()
 	default:
@@ -47,11 +49,11 @@ COPY . .
 
 # Build the application
 RUN CGO_ENABLED=0 GOOS=linux go build -a -installsuffix cgo -ldflags="-w -s" -o main .
-
+%s
 # Final stage
 FROM %s
 
-`, baseImage, finalImage)
+`, baseImage, workerBuild(config, `RUN CGO_ENABLED=0 GOOS=linux go build -a -installsuffix cgo -ldflags="-w -s" -o worker ./cmd/worker`), finalImage)
 
 	if config.Security {
 		dockerfile += `# Security hardening
@@ -66,7 +68,7 @@ WORKDIR /home/appuser
 
 # Copy binary from builder stage
 COPY --from=builder --chown=appuser:appuser /app/main .
-COPY --from=builder --chown=appuser:appuser /app/config ./config
+` + workerBuild(config, "COPY --from=builder --chown=appuser:appuser /app/worker .") + `COPY --from=builder --chown=appuser:appuser /app/config ./config
 
 # Switch to non-root user
 USER appuser
@@ -77,7 +79,7 @@ USER appuser
 
 # Copy binary from builder stage
 COPY --from=builder /app/main .
-COPY --from=builder /app/config ./config
+` + workerBuild(config, "COPY --from=builder /app/worker .") + `COPY --from=builder /app/config ./config
 
 `
 	}
@@ -112,7 +114,7 @@ COPY . .
 
 # Build application
 RUN go build -o main .
-
+%s
 # Expose port
 EXPOSE %d
 
@@ -122,7 +124,16 @@ HEALTHCHECK --interval=30s --timeout=3s --start-period=5s --retries=3 \
 
 # Run application
 CMD ["./main"]
-`, config.GetBaseImage(), config.Port, config.Port)
+`, config.GetBaseImage(), workerBuild(config, "RUN go build -o worker ./cmd/worker"), config.Port, config.Port)
+}
+
+// workerBuild returns the Dockerfile line building or copying the worker binary, empty
+// without a worker
+func workerBuild(config models.DeploymentConfig, line string) string {
+	if !config.WithWorker {
+		return ""
+	}
+	return line + "\n"
 }
 
 // getDockerCompose generates docker-compose.yml
@@ -151,7 +162,7 @@ services:
       timeout: 10s
       retries: 3
       start_period: 40s
-
+%s
   db:
     image: postgres:15-alpine
     environment:
@@ -180,7 +191,7 @@ networks:
   default:
     name: %s-network
 `, config.AppName, config.GetDockerFileName(), config.Port, config.Port, 
-   config.Environment, config.Port, config.Port, config.AppName, config.AppName)
+   config.Environment, config.Port, config.Port, getDockerComposeWorkerService(config), config.AppName, config.AppName)
 }
 
 // getDockerComposeProduction generates production docker-compose.yml
@@ -220,13 +231,77 @@ services:
         delay: 5s
         max_attempts: 3
         window: 120s
-
+%s
 networks:
   default:
     name: %s-production-network
     external: true
 `, config.AppName, config.AppName, config.Port, config.Port, config.Port, 
-   config.Port, config.AppName)
+   config.Port, getDockerComposeProductionWorkerService(config), config.AppName)
+}
+
+// getDockerComposeWorkerService generates the docker-compose service of the background
+// worker, empty without a worker
+func getDockerComposeWorkerService(config models.DeploymentConfig) string {
+	if !config.WithWorker {
+		return ""
+	}
+	return fmt.Sprintf(`
+  %s-worker:
+    build:
+      context: .
+      dockerfile: %s
+    command: ["./worker"]
+    environment:
+      - ENV=%s
+      - WORKER_CONCURRENCY=4
+    depends_on:
+      - db
+    restart: unless-stopped
+    healthcheck:
+      test: ["CMD", "wget", "--no-verbose", "--tries=1", "--spider", "http://localhost:8081/health"]
+      interval: 30s
+      timeout: 10s
+      retries: 3
+`, config.AppName, config.GetDockerFileName(), config.Environment)
+}
+
+// getDockerComposeProductionWorkerService generates the production docker-compose service of
+// the background worker, empty without a worker
+func getDockerComposeProductionWorkerService(config models.DeploymentConfig) string {
+	if !config.WithWorker {
+		return ""
+	}
+	return fmt.Sprintf(`
+  %s-worker:
+    image: ${DOCKER_REGISTRY}/%s:${VERSION}
+    command: ["./worker"]
+    environment:
+      - ENV=production
+      - DATABASE_URL=${DATABASE_URL}
+      - WORKER_CONCURRENCY=${WORKER_CONCURRENCY:-4}
+    restart: always
+    healthcheck:
+      test: ["CMD", "wget", "--no-verbose", "--tries=1", "--spider", "http://localhost:8081/health"]
+      interval: 30s
+      timeout: 10s
+      retries: 5
+    deploy:
+      replicas: 2
+      restart_policy:
+        condition: on-failure
+        delay: 5s
+`, config.AppName, config.AppName)
+}
+
+// getDockerComposeWorker generates a docker-compose override adding the background worker to
+// an existing docker-compose.yml
+func getDockerComposeWorker(config models.DeploymentConfig) string {
+	config.WithWorker = true
+	return `# Run with: docker compose -f docker-compose.yml -f docker-compose.worker.yml up
+version: '3.8'
+
+services:` + getDockerComposeWorkerService(config)
 }
 
 // getDockerIgnore generates .dockerignore
@@ -297,6 +372,8 @@ func GetKubernetesTemplate(fileName string, config models.DeploymentConfig) stri
 		return getKubernetesSecret(config)
 	case "hpa.yaml":
 		return getKubernetesHPA(config)
+	case "worker-deployment.yaml":
+		return getKubernetesWorkerDeployment(config)
 	default:
 		return ""
 	}
@@ -394,6 +471,81 @@ spec:
 		limits["memory"], limits["cpu"], config.Port, config.Port)
 }
 
+// getKubernetesWorkerDeployment generates the deployment manifest of the background worker,
+// running the worker binary of the application image
+func getKubernetesWorkerDeployment(config models.DeploymentConfig) string {
+	labels := config.GetLabels()
+	envVars := config.GetEnvironmentVariables()
+	requests := config.GetResourceRequests()
+	limits := config.GetResourceLimits()
+
+	return fmt.Sprintf(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: %[1]s-worker
+  namespace: %[2]s
+  labels:
+    app: %[3]s-worker
+    version: %[4]s
+    environment: %[5]s
+spec:
+  replicas: %[6]d
+  selector:
+    matchLabels:
+      app: %[3]s-worker
+  template:
+    metadata:
+      labels:
+        app: %[3]s-worker
+        version: %[4]s
+        environment: %[5]s
+    spec:
+      terminationGracePeriodSeconds: 60
+      containers:
+      - name: %[1]s-worker
+        image: %[1]s:%[7]s
+        command: ["./worker"]
+        ports:
+        - containerPort: 8081
+          name: health
+        env:
+        - name: ENV
+          value: %[8]s
+        - name: WORKER_CONCURRENCY
+          value: "4"
+        - name: WORKER_HEALTH_PORT
+          value: "8081"
+        resources:
+          requests:
+            memory: %[9]s
+            cpu: %[10]s
+          limits:
+            memory: %[11]s
+            cpu: %[12]s
+        livenessProbe:
+          httpGet:
+            path: /health
+            port: 8081
+          initialDelaySeconds: 10
+          periodSeconds: 10
+          timeoutSeconds: 5
+          failureThreshold: 3
+        securityContext:
+          allowPrivilegeEscalation: false
+          runAsNonRoot: true
+          runAsUser: 1001
+          capabilities:
+            drop:
+            - ALL
+          readOnlyRootFilesystem: true
+      securityContext:
+        fsGroup: 1001
+`,
+		config.AppName, config.Namespace, labels["app"], labels["version"], labels["environment"],
+		config.Replicas, config.Version, envVars["ENV"], requests["memory"], requests["cpu"],
+		limits["memory"], limits["cpu"])
+}
+
 // getKubernetesService generates service manifest
 func getKubernetesService(config models.DeploymentConfig) string {
 	return fmt.Sprintf(`apiVersion: v1
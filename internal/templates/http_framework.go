@@ -0,0 +1,724 @@
+package templates
+
+import "github.com/vibercode/cli/internal/models"
+
+// HTTPHelpersTemplate generates the request binding helpers of handlers targeting frameworks
+// other than Gin, plus the JSON response and query string helpers of net/http and chi handlers.
+// Error responses are written by the problem details helpers of ProblemsTemplate.
+const HTTPHelpersTemplate = `package handlers
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+{{range .HTTP.Imports}}	"{{.}}"
+{{end}}	"{{.Module}}/internal/apperrors"
+)
+
+// validate checks the binding tags of request structs the way Gin does when binding
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.SetTagName("binding")
+	return v
+}
+{{- if .HTTP.NetHTTP}}
+
+// writeJSON writes v as the JSON response body with the given status
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes a problem details response of the given status
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeProblemDetails(w, apperrors.ProblemFor(apperrors.New(status, "%s", message), ""))
+}
+
+// decodeJSON decodes the JSON request body into v and validates it
+func decodeJSON(r *http.Request, v interface{}) error {
+	if r.Body == nil || r.Body == http.NoBody {
+		return errors.New("request body is empty")
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return err
+	}
+	return validate.Struct(v)
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// decodeQuery fills the struct v points to from the query string, matching fields by
+// their query tag. Pointer fields stay nil when their parameter is absent.
+func decodeQuery(r *http.Request, v interface{}) error {
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Ptr || target.Elem().Kind() != reflect.Struct {
+		return errors.New("decodeQuery expects a pointer to a struct")
+	}
+
+	values := r.URL.Query()
+	elem := target.Elem()
+	for i := 0; i < elem.NumField(); i++ {
+		name := strings.Split(elem.Type().Field(i).Tag.Get("query"), ",")[0]
+		if name == "" || name == "-" || !values.Has(name) {
+			continue
+		}
+		if err := setQueryValue(elem.Field(i), values.Get(name)); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// setQueryValue parses raw into field, allocating pointer fields
+func setQueryValue(field reflect.Value, raw string) error {
+	if field.Kind() == reflect.Ptr {
+		value := reflect.New(field.Type().Elem())
+		if err := setQueryValue(value.Elem(), raw); err != nil {
+			return err
+		}
+		field.Set(value)
+		return nil
+	}
+
+	if field.Type() == reflect.TypeOf(time.Time{}) {
+		for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+			if parsed, err := time.Parse(layout, raw); err == nil {
+				field.Set(reflect.ValueOf(parsed))
+				return nil
+			}
+		}
+		return fmt.Errorf("%q is not an RFC 3339 time or a date", raw)
+	}
+	if field.Addr().Type().Implements(textUnmarshalerType) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw))
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(parsed)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+{{- else}}
+
+// bindJSON binds the JSON request body into v and validates it
+func bindJSON({{.HTTP.HandlerParams}}, v interface{}) error {
+{{- if .HTTP.Is "echo"}}
+	if err := c.Bind(v); err != nil {
+{{- else}}
+	if err := c.BodyParser(v); err != nil {
+{{- end}}
+		return err
+	}
+	return validate.Struct(v)
+}
+{{- end}}
+`
+
+// SchemaHandlerTestTemplate generates HTTP tests of the CRUD routes against an in-memory service
+const SchemaHandlerTestTemplate = `package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+{{range .HTTP.Imports}}	"{{.}}"
+{{end}}	"go.mongodb.org/mongo-driver/bson/primitive"
+	"{{.Module}}/internal/apperrors"
+	"{{.Module}}/internal/models"
+	"{{.Module}}/internal/services"
+)
+
+// {{.Names.CamelCase}}TestPayload is a valid {{.Names.PascalCase}}Request body
+const {{.Names.CamelCase}}TestPayload = {{printf "%q" .Payload}}
+
+// fake{{.Names.PascalCase}}Service keeps {{.Names.Plural}} in memory so the routes can be tested without a database
+type fake{{.Names.PascalCase}}Service struct {
+	mu    sync.Mutex
+	items map[string]*models.{{.Names.PascalCase}}
+}
+
+func newFake{{.Names.PascalCase}}Service() *fake{{.Names.PascalCase}}Service {
+	return &fake{{.Names.PascalCase}}Service{items: make(map[string]*models.{{.Names.PascalCase}})}
+}
+
+func (s *fake{{.Names.PascalCase}}Service) Create(ctx context.Context, req *models.{{.Names.PascalCase}}Request) (*models.{{.Names.PascalCase}}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	item := &models.{{.Names.PascalCase}}{ID: primitive.NewObjectID(), CreatedAt: now, UpdatedAt: now}
+	s.items[item.ID.Hex()] = item
+	return item, nil
+}
+
+func (s *fake{{.Names.PascalCase}}Service) GetByID(ctx context.Context, id string) (*models.{{.Names.PascalCase}}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.items[id]
+	if !ok {
+		return nil, apperrors.NotFound("{{.Names.Singular}} %s not found", id)
+	}
+	return item, nil
+}
+
+func (s *fake{{.Names.PascalCase}}Service) GetAll(ctx context.Context, filter *models.{{.Names.PascalCase}}Filter) ([]*models.{{.Names.PascalCase}}, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items := make([]*models.{{.Names.PascalCase}}, 0, len(s.items))
+	for _, item := range s.items {
+		items = append(items, item)
+	}
+	return items, int64(len(items)), nil
+}
+
+func (s *fake{{.Names.PascalCase}}Service) Update(ctx context.Context, id string, req *models.{{.Names.PascalCase}}Request) (*models.{{.Names.PascalCase}}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.items[id]
+	if !ok {
+		return nil, apperrors.NotFound("{{.Names.Singular}} %s not found", id)
+	}
+	item.UpdatedAt = time.Now()
+	return item, nil
+}
+
+func (s *fake{{.Names.PascalCase}}Service) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.items[id]; !ok {
+		return apperrors.NotFound("{{.Names.Singular}} %s not found", id)
+	}
+	delete(s.items, id)
+	return nil
+}
+{{- if .Includes}}
+
+func (s *fake{{.Names.PascalCase}}Service) Include(ctx context.Context, items []*models.{{.Names.PascalCase}}, include models.IncludeTree) error {
+	return nil
+}
+{{- end}}
+
+// new{{.Names.PascalCase}}TestServer mounts the {{.Names.PascalCase}} routes under /api/v1 on a {{.HTTP.Framework.GetDisplayName}} router
+// and returns a function sending a request through it
+func new{{.Names.PascalCase}}TestServer(t *testing.T, service services.{{.Names.PascalCase}}ServiceInterface) func(method, path, body string) (int, map[string]interface{}) {
+	handler := New{{.Names.PascalCase}}Handler(service)
+{{- if .HTTP.Is "fiber"}}
+	app := fiber.New()
+	Setup{{.Names.PascalCase}}Routes(app.Group("/api/v1"), handler)
+{{- else if .HTTP.Is "echo"}}
+	router := echo.New()
+	Setup{{.Names.PascalCase}}Routes(router.Group("/api/v1"), handler)
+{{- else if .HTTP.Is "chi"}}
+	router := chi.NewRouter()
+	router.Route("/api/v1", func(r chi.Router) {
+		Setup{{.Names.PascalCase}}Routes(r, handler)
+	})
+{{- else if .HTTP.Is "stdlib"}}
+	api := http.NewServeMux()
+	Setup{{.Names.PascalCase}}Routes(api, handler)
+	router := http.NewServeMux()
+	router.Handle("/api/v1/", http.StripPrefix("/api/v1", api))
+{{- else}}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	Setup{{.Names.PascalCase}}Routes(router.Group("/api/v1"), handler)
+{{- end}}
+
+	return func(method, path, body string) (int, map[string]interface{}) {
+		t.Helper()
+
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+{{- if .HTTP.Is "fiber"}}
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		defer resp.Body.Close()
+
+		status := resp.StatusCode
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("%s %s: failed to read body: %v", method, path, err)
+		}
+{{- else}}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		status, data := rec.Code, rec.Body.Bytes()
+{{- end}}
+
+		var decoded map[string]interface{}
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("%s %s: response is not a JSON object: %s", method, path, data)
+			}
+		}
+		return status, decoded
+	}
+}
+
+func Test{{.Names.PascalCase}}Handler_CRUD(t *testing.T) {
+	do := new{{.Names.PascalCase}}TestServer(t, newFake{{.Names.PascalCase}}Service())
+
+	status, created := do(http.MethodPost, "/api/v1/{{.Names.KebabPlural}}", {{.Names.CamelCase}}TestPayload)
+	if status != http.StatusCreated {
+		t.Fatalf("create: expected %d, got %d: %v", http.StatusCreated, status, created)
+	}
+	id, _ := created["id"].(string)
+	if id == "" {
+		t.Fatalf("create: response has no id: %v", created)
+	}
+
+	status, body := do(http.MethodGet, "/api/v1/{{.Names.KebabPlural}}/"+id, "")
+	if status != http.StatusOK || body["id"] != id {
+		t.Fatalf("get: expected %d with id %s, got %d: %v", http.StatusOK, id, status, body)
+	}
+
+	status, body = do(http.MethodGet, "/api/v1/{{.Names.KebabPlural}}?page=1&page_size=10", "")
+	if status != http.StatusOK {
+		t.Fatalf("list: expected %d, got %d: %v", http.StatusOK, status, body)
+	}
+	if body["total"] != float64(1) || body["page_size"] != float64(10) {
+		t.Fatalf("list: expected one {{.Names.Singular}} on a page of 10, got %v", body)
+	}
+
+	status, body = do(http.MethodPut, "/api/v1/{{.Names.KebabPlural}}/"+id, {{.Names.CamelCase}}TestPayload)
+	if status != http.StatusOK {
+		t.Fatalf("update: expected %d, got %d: %v", http.StatusOK, status, body)
+	}
+
+	status, body = do(http.MethodDelete, "/api/v1/{{.Names.KebabPlural}}/"+id, "")
+	if status != http.StatusOK {
+		t.Fatalf("delete: expected %d, got %d: %v", http.StatusOK, status, body)
+	}
+
+	status, body = do(http.MethodGet, "/api/v1/{{.Names.KebabPlural}}/"+id, "")
+	if status != http.StatusNotFound || body["code"] != apperrors.CodeNotFound {
+		t.Fatalf("get after delete: expected %d %s, got %d: %v", http.StatusNotFound, apperrors.CodeNotFound, status, body)
+	}
+}
+
+func Test{{.Names.PascalCase}}Handler_InvalidBody(t *testing.T) {
+	do := new{{.Names.PascalCase}}TestServer(t, newFake{{.Names.PascalCase}}Service())
+
+	status, body := do(http.MethodPost, "/api/v1/{{.Names.KebabPlural}}", "{\"")
+	if status != http.StatusBadRequest {
+		t.Fatalf("expected %d, got %d: %v", http.StatusBadRequest, status, body)
+	}
+	if body["code"] != apperrors.CodeBadRequest || body["status"] != float64(http.StatusBadRequest) {
+		t.Fatalf("expected a %s problem, got %v", apperrors.CodeBadRequest, body)
+	}
+	if detail, _ := body["detail"].(string); detail == "" {
+		t.Fatalf("expected a problem detail, got %v", body)
+	}
+}
+
+func Test{{.Names.PascalCase}}Handler_View(t *testing.T) {
+	do := new{{.Names.PascalCase}}TestServer(t, newFake{{.Names.PascalCase}}Service())
+
+	status, created := do(http.MethodPost, "/api/v1/{{.Names.KebabPlural}}", {{.Names.CamelCase}}TestPayload)
+	if status != http.StatusCreated {
+		t.Fatalf("create: expected %d, got %d: %v", http.StatusCreated, status, created)
+	}
+
+	status, body := do(http.MethodGet, "/api/v1/{{.Names.KebabPlural}}?include=unknown", "")
+	if status != http.StatusBadRequest {
+		t.Fatalf("unknown include: expected %d, got %d: %v", http.StatusBadRequest, status, body)
+	}
+
+	status, body = do(http.MethodGet, "/api/v1/{{.Names.KebabPlural}}?include=&fields%5B{{.Names.SnakeCase}}%5D=created_at", "")
+	if status != http.StatusOK {
+		t.Fatalf("fields: expected %d, got %d: %v", http.StatusOK, status, body)
+	}
+	data, _ := body["data"].([]interface{})
+	if len(data) != 1 {
+		t.Fatalf("fields: expected one {{.Names.Singular}}, got %v", body)
+	}
+	item, _ := data[0].(map[string]interface{})
+	if len(item) != 2 || item["id"] != created["id"] || item["created_at"] == nil {
+		t.Fatalf("fields: expected id and created_at only, got %v", item)
+	}
+}
+{{- if .HasRequired}}
+
+func Test{{.Names.PascalCase}}Handler_MissingRequiredFields(t *testing.T) {
+	do := new{{.Names.PascalCase}}TestServer(t, newFake{{.Names.PascalCase}}Service())
+
+	status, body := do(http.MethodPost, "/api/v1/{{.Names.KebabPlural}}", "{}")
+	if status != http.StatusBadRequest || body["code"] != apperrors.CodeValidation {
+		t.Fatalf("expected %d %s, got %d: %v", http.StatusBadRequest, apperrors.CodeValidation, status, body)
+	}
+	fields, _ := body["errors"].([]interface{})
+	if len(fields) == 0 {
+		t.Fatalf("expected the rejected fields, got %v", body)
+	}
+	for _, field := range fields {
+		if name, _ := field.(map[string]interface{})["field"].(string); name == "" || strings.ContainsAny(name, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") {
+			t.Fatalf("expected fields named after their JSON key, got %v", body)
+		}
+	}
+}
+{{- end}}
+`
+
+// GetAPIMainTemplate returns the cmd/server/main.go template of an API project
+func GetAPIMainTemplate(framework models.HTTPFramework) string {
+	switch framework {
+	case models.HTTPStdlib:
+		return apiMainStdlibTemplate
+	case models.HTTPChi:
+		return apiMainChiTemplate
+	case models.HTTPEcho:
+		return apiMainEchoTemplate
+	case models.HTTPFiber:
+		return apiMainFiberTemplate
+	default:
+		return apiMainGinTemplate
+	}
+}
+
+// apiMainImports lists the imports every API project main.go shares
+const apiMainImports = `	"github.com/joho/godotenv"
+	"{{.Module}}/internal/handlers"
+	"{{.Module}}/pkg/database"{{- if eq .Database.Type "mongodb"}}
+	_ "go.mongodb.org/mongo-driver/mongo"{{- else if eq .Database.Type "redis"}}
+	_ "github.com/go-redis/redis/v8"{{- end}}
+)
+
+func main() {
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found")
+	}
+
+	// Connect to database
+	db, err := database.Connect()
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+`
+
+// apiMainPort reads the listening port
+const apiMainPort = `
+	// Start server
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "{{.Port}}"
+	}
+
+	log.Printf("Server starting on port %s", port)
+`
+
+const apiMainGinTemplate = `package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+` + apiMainImports + `
+	// Initialize Gin router
+	r := gin.Default()
+
+	// Add middleware
+	r.Use(gin.Logger())
+	r.Use(gin.Recovery())
+
+	// Setup routes
+	api := r.Group("/api/v1")
+	handlers.SetupRoutes(api, db)
+
+	// Health check endpoint
+	r.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"status": "ok",
+			"service": "{{.Name}}",
+		})
+	})
+` + apiMainPort + `	log.Fatal(http.ListenAndServe(":"+port, r))
+}
+`
+
+const apiMainEchoTemplate = `package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+` + apiMainImports + `
+	// Initialize Echo
+	e := echo.New()
+	e.HideBanner = true
+
+	// Add middleware
+	e.Use(middleware.Logger())
+	e.Use(middleware.Recover())
+
+	// Setup routes
+	api := e.Group("/api/v1")
+	handlers.SetupRoutes(api, db)
+
+	// Health check endpoint
+	e.GET("/health", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, echo.Map{
+			"status":  "ok",
+			"service": "{{.Name}}",
+		})
+	})
+` + apiMainPort + `	log.Fatal(e.Start(":" + port))
+}
+`
+
+const apiMainFiberTemplate = `package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+` + apiMainImports + `
+	// Initialize Fiber
+	app := fiber.New()
+
+	// Add middleware
+	app.Use(logger.New())
+	app.Use(recover.New())
+
+	// Setup routes
+	api := app.Group("/api/v1")
+	handlers.SetupRoutes(api, db)
+
+	// Health check endpoint
+	app.Get("/health", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{
+			"status":  "ok",
+			"service": "{{.Name}}",
+		})
+	})
+` + apiMainPort + `	log.Fatal(app.Listen(":" + port))
+}
+`
+
+const apiMainChiTemplate = `package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+` + apiMainImports + `
+	// Initialize Chi router
+	router := chi.NewRouter()
+
+	// Add middleware
+	router.Use(middleware.RequestID)
+	router.Use(middleware.Logger)
+	router.Use(middleware.Recoverer)
+
+	// Setup routes
+	router.Route("/api/v1", func(api chi.Router) {
+		handlers.SetupRoutes(api, db)
+	})
+
+	// Health check endpoint
+	router.Get("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"status":  "ok",
+			"service": "{{.Name}}",
+		})
+	})
+` + apiMainPort + `	log.Fatal(http.ListenAndServe(":"+port, router))
+}
+`
+
+const apiMainStdlibTemplate = `package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+
+	"{{.Module}}/internal/middleware"
+` + apiMainImports + `
+	// Setup routes
+	api := http.NewServeMux()
+	handlers.SetupRoutes(api, db)
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/v1/", http.StripPrefix("/api/v1", api))
+
+	// Health check endpoint
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"status":  "ok",
+			"service": "{{.Name}}",
+		})
+	})
+
+	// Add middleware
+	handler := middleware.Chain(mux, middleware.Recoverer, middleware.Logger)
+` + apiMainPort + `	log.Fatal(http.ListenAndServe(":"+port, handler))
+}
+`
+
+// APIRoutesTemplate generates internal/handlers/routes.go of an API project
+const APIRoutesTemplate = `package handlers
+
+import (
+	"net/http"
+
+{{range .HTTP.Imports}}	"{{.}}"
+{{end}}{{if eq .Database.Type "mongodb"}}	"go.mongodb.org/mongo-driver/mongo"
+{{else}}	"gorm.io/gorm"
+{{end}})
+
+// SetupRoutes sets up all API routes
+func SetupRoutes({{.HTTP.Router}}, db {{if eq .Database.Type "mongodb"}}*mongo.Database{{else}}*gorm.DB{{end}}) {
+	// Initialize repositories
+	// userRepo := repositories.NewUserRepository(db)
+
+	// Initialize services
+	// userService := services.NewUserService(userRepo)
+
+	// Initialize handlers
+	// userHandler := NewUserHandler(userService)
+
+	// Setup routes
+	// SetupUserRoutes(r, userHandler)
+
+	// Example endpoint
+	{{.HTTP.Root.Route "GET" "/example" "example"}}
+}
+
+// example handles GET /example
+func example({{.HTTP.HandlerParams}}){{.HTTP.HandlerResult}} {
+	{{.HTTP.Reply "http.StatusOK"}}{{.HTTP.Map}}{
+		"message": "{{.Name}} API is running!",
+	})
+}
+`
+
+// APIStdlibMiddlewareTemplate generates the request logging and panic recovery middleware of net/http projects
+const APIStdlibMiddlewareTemplate = `package middleware
+
+import (
+	"log"
+	"net/http"
+	"runtime/debug"
+	"time"
+)
+
+// Middleware wraps an http.Handler
+type Middleware func(http.Handler) http.Handler
+
+// Chain wraps handler with middlewares, the first middleware runs first
+func Chain(handler http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// statusRecorder captures the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Logger logs the method, path, status and duration of every request
+func Logger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		log.Printf("%s %s %d %s", r.Method, r.URL.Path, recorder.status, time.Since(start))
+	})
+}
+
+// Recoverer turns panics into 500 responses and logs the stack trace
+func Recoverer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if err := recover(); err != nil {
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+				log.Printf("panic: %v\n%s", err, debug.Stack())
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+`
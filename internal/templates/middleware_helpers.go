@@ -7,17 +7,20 @@ import (
 	"github.com/vibercode/cli/internal/models"
 )
 
-// GetMiddlewareRegistryTemplate generates middleware registry template
-func GetMiddlewareRegistryTemplate(middlewares []models.MiddlewareConfig) string {
+// GetMiddlewareRegistryTemplate generates middleware registry template, configured by the
+// config package of module
+func GetMiddlewareRegistryTemplate(module string, middlewares []models.MiddlewareConfig) string {
 	var imports []string
 	var structs []string
 	var initFunctions []string
 	var registrationCalls []string
+	var helpers []string
 
 	// Common imports
 	imports = append(imports,
 		"github.com/gin-gonic/gin",
 		"github.com/sirupsen/logrus",
+		module+"/internal/config",
 	)
 
 	for _, middleware := range middlewares {
@@ -28,29 +31,41 @@ func GetMiddlewareRegistryTemplate(middlewares []models.MiddlewareConfig) string
 
 		switch middleware.Type {
 		case models.AuthMiddleware:
+			method := "BasicAuth"
 			switch middleware.Options.AuthStrategy {
 			case models.JWTAuth:
+				method = "JWT"
 				initFunctions = append(initFunctions, fmt.Sprintf(`
 	// Initialize %s middleware
 	m.%s = New%s(
-		config.JWT.Secret,
-		config.JWT.Issuer,
+		cfg.Auth.JWTSecret,
+		cfg.Auth.JWTIssuer,
 	)`, middleware.Name, fieldName, structName))
 			case models.APIKeyAuth:
+				method = "APIKey"
 				initFunctions = append(initFunctions, fmt.Sprintf(`
 	// Initialize %s middleware
-	validKeys := []string{config.Auth.APIKeys...}
+	validKeys := append([]string{}, cfg.Auth.APIKeys...)
 	m.%s = New%s(validKeys, "%s")`, 
 					middleware.Name, fieldName, structName, middleware.Options.APIKeyHeader))
+			default:
+				initFunctions = append(initFunctions, fmt.Sprintf(`
+	// Initialize %s middleware
+	m.%s = New%s()`, middleware.Name, fieldName, structName))
 			}
-			registrationCalls = append(registrationCalls, fmt.Sprintf("\tr.Use(m.%s.JWT())", fieldName))
+			// Authentication is registered on the protected routes only, not on the whole router
+			helpers = append(helpers, fmt.Sprintf(`
+// RegisterAuthMiddleware registers authentication middleware for protected routes
+func (m *Manager) RegisterAuthMiddleware(r gin.IRouter) {
+	r.Use(m.%s.%s())
+}`, fieldName, method))
 
 		case models.LoggingMiddleware:
 			initFunctions = append(initFunctions, fmt.Sprintf(`
 	// Initialize %s middleware
 	m.%s = New%s(
 		logger,
-		config.Logging.ExcludePaths,
+		cfg.Logging.ExcludePaths,
 	)`, middleware.Name, fieldName, structName))
 			registrationCalls = append(registrationCalls, fmt.Sprintf("\tr.Use(m.%s.RequestLogger())", fieldName))
 
@@ -59,14 +74,34 @@ func GetMiddlewareRegistryTemplate(middlewares []models.MiddlewareConfig) string
 	// Initialize %s middleware
 	m.%s = New%s()`, middleware.Name, fieldName, structName))
 			registrationCalls = append(registrationCalls, fmt.Sprintf("\tr.Use(m.%s.CORS())", fieldName))
+			helpers = append(helpers, fmt.Sprintf(`
+// RegisterCORSMiddleware registers CORS middleware
+func (m *Manager) RegisterCORSMiddleware(r *gin.Engine) {
+	r.Use(m.%s.CORS())
+}`, fieldName))
 
 		case models.RateLimitMiddleware:
-			initFunctions = append(initFunctions, fmt.Sprintf(`
+			if middleware.Options.UseRedis {
+				imports = append(imports, "github.com/go-redis/redis/v8")
+				initFunctions = append(initFunctions, fmt.Sprintf(`
+	// Initialize %s middleware, counting requests in Redis
+	redisOptions, err := redis.ParseURL(cfg.RateLimit.RedisURL)
+	if err != nil {
+		logger.WithError(err).Fatal("invalid rate limit Redis URL")
+	}
+	m.%s = New%s(
+		redis.NewClient(redisOptions),
+		cfg.RateLimit.RequestsPerSecond,
+		cfg.RateLimit.BurstSize,
+	)`, middleware.Name, fieldName, structName))
+			} else {
+				initFunctions = append(initFunctions, fmt.Sprintf(`
 	// Initialize %s middleware
 	m.%s = New%s(
-		config.RateLimit.RequestsPerSecond,
-		config.RateLimit.BurstSize,
+		cfg.RateLimit.RequestsPerSecond,
+		cfg.RateLimit.BurstSize,
 	)`, middleware.Name, fieldName, structName))
+			}
 			registrationCalls = append(registrationCalls, fmt.Sprintf("\tr.Use(m.%s.RateLimit())", fieldName))
 
 		case models.CustomMiddleware:
@@ -89,7 +124,7 @@ type Manager struct {
 }
 
 // NewManager creates a new middleware manager
-func NewManager(config *MiddlewareConfig, logger *logrus.Logger) *Manager {
+func NewManager(cfg *config.MiddlewareConfig, logger *logrus.Logger) *Manager {
 	m := &Manager{}
 %s
 	return m
@@ -99,22 +134,9 @@ func NewManager(config *MiddlewareConfig, logger *logrus.Logger) *Manager {
 func (m *Manager) RegisterMiddleware(r *gin.Engine) {
 %s
 }
-
-// RegisterAuthMiddleware registers authentication middleware for protected routes
-func (m *Manager) RegisterAuthMiddleware(r gin.IRouter) {
-	if m.auth != nil {
-		r.Use(m.auth.JWT())
-	}
-}
-
-// RegisterCORSMiddleware registers CORS middleware
-func (m *Manager) RegisterCORSMiddleware(r *gin.Engine) {
-	if m.cors != nil {
-		r.Use(m.cors.CORS())
-	}
-}
+%s
 `, generateMiddlewareImports(imports), strings.Join(structs, "\n"), 
-		strings.Join(initFunctions, "\n"), strings.Join(registrationCalls, "\n"))
+		strings.Join(initFunctions, "\n"), strings.Join(registrationCalls, "\n"), strings.Join(helpers, "\n"))
 }
 
 // GetMiddlewareConfigTemplate generates middleware configuration template
@@ -215,7 +237,7 @@ func DefaultMiddlewareConfig() *MiddlewareConfig {
 			defaultConfigs = append(defaultConfigs, fmt.Sprintf(`		Auth: AuthConfig{
 			Enabled:   true,
 			Strategy:  "%s",
-			JWTSecret: "your-secret-key",
+			JWTSecret: os.Getenv("JWT_SECRET"),
 			JWTIssuer: "%s",
 			APIKeys:   []string{},
 		},`, strategy, middleware.Options.JWTIssuer))
@@ -262,12 +284,20 @@ func DefaultMiddlewareConfig() *MiddlewareConfig {
 	defaultConfigs = append(defaultConfigs, `	}
 }`)
 
-	return fmt.Sprintf(`package config
+	// The JWT secret is read from the environment rather than written in the source
+	imports := ""
+	for _, middleware := range middlewares {
+		if middleware.Type == models.AuthMiddleware {
+			imports = "\nimport \"os\"\n"
+		}
+	}
 
+	return fmt.Sprintf(`package config
+%s
 %s
 
 %s
-`, strings.Join(configStructs, "\n"), strings.Join(defaultConfigs, "\n"))
+`, imports, strings.Join(configStructs, "\n"), strings.Join(defaultConfigs, "\n"))
 }
 
 // Helper functions
@@ -0,0 +1,534 @@
+package templates
+
+// AppErrorsTemplate generates the domain errors services return and their translation to
+// problem details responses (RFC 7807)
+const AppErrorsTemplate = `// Package apperrors defines the errors services return and their problem details (RFC 7807)
+// representation. Every error carries an HTTP status and a stable code clients can branch
+// on, validation errors carry the rejected fields.
+package apperrors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ContentType is the media type of problem details responses
+const ContentType = "application/problem+json"
+
+// TypeBase prefixes the code of an error to build the type URI of its problem details. Point
+// it at the error documentation of the API to make the types resolvable.
+var TypeBase = "urn:problem-type:"
+
+// Stable error codes, part of the API contract
+const (
+	CodeBadRequest   = "bad_request"
+	CodeValidation   = "validation_failed"
+	CodeUnauthorized = "unauthorized"
+	CodeForbidden    = "forbidden"
+	CodeNotFound     = "not_found"
+	CodeConflict     = "conflict"
+	CodeInternal     = "internal_error"
+)
+
+// Sentinels matching the errors of a kind with errors.Is
+var (
+	ErrBadRequest   = &Error{Status: http.StatusBadRequest, Code: CodeBadRequest}
+	ErrValidation   = &Error{Status: http.StatusBadRequest, Code: CodeValidation}
+	ErrUnauthorized = &Error{Status: http.StatusUnauthorized, Code: CodeUnauthorized}
+	ErrForbidden    = &Error{Status: http.StatusForbidden, Code: CodeForbidden}
+	ErrNotFound     = &Error{Status: http.StatusNotFound, Code: CodeNotFound}
+	ErrConflict     = &Error{Status: http.StatusConflict, Code: CodeConflict}
+	ErrInternal     = &Error{Status: http.StatusInternalServerError, Code: CodeInternal}
+)
+
+// Error is a domain error with the HTTP status and the stable code it is reported with
+type Error struct {
+	Status int
+	Code   string
+	Detail string
+	Fields []FieldError
+	cause  error
+}
+
+// FieldError describes why the value of a request field was rejected
+type FieldError struct {
+	Field   string ` + "`" + `json:"field"` + "`" + `
+	Code    string ` + "`" + `json:"code"` + "`" + `
+	Message string ` + "`" + `json:"message"` + "`" + `
+}
+
+// Error returns the detail of the error
+func (e *Error) Error() string {
+	switch {
+	case e.Detail != "":
+		return e.Detail
+	case e.cause != nil:
+		return e.cause.Error()
+	default:
+		return strings.ToLower(http.StatusText(e.Status))
+	}
+}
+
+// Unwrap returns the error that caused e
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is reports whether target is an error with the same code, so errors match their sentinel
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// NotFound returns a not_found error, formatted like fmt.Errorf
+func NotFound(format string, args ...interface{}) error {
+	return newError(http.StatusNotFound, CodeNotFound, format, args)
+}
+
+// Conflict returns a conflict error, formatted like fmt.Errorf
+func Conflict(format string, args ...interface{}) error {
+	return newError(http.StatusConflict, CodeConflict, format, args)
+}
+
+// Forbidden returns a forbidden error, formatted like fmt.Errorf
+func Forbidden(format string, args ...interface{}) error {
+	return newError(http.StatusForbidden, CodeForbidden, format, args)
+}
+
+// Unauthorized returns an unauthorized error, formatted like fmt.Errorf
+func Unauthorized(format string, args ...interface{}) error {
+	return newError(http.StatusUnauthorized, CodeUnauthorized, format, args)
+}
+
+// BadRequest returns a bad_request error, formatted like fmt.Errorf
+func BadRequest(format string, args ...interface{}) error {
+	return newError(http.StatusBadRequest, CodeBadRequest, format, args)
+}
+
+// New returns an error reported with status, its code is derived from the status
+func New(status int, format string, args ...interface{}) error {
+	return newError(status, CodeFor(status), format, args)
+}
+
+// Internal returns an internal_error wrapping err, its message is never sent to clients
+func Internal(err error) error {
+	return &Error{Status: http.StatusInternalServerError, Code: CodeInternal, cause: err}
+}
+
+// Invalid returns a validation_failed error listing the rejected fields, nil without fields
+func Invalid(fields ...FieldError) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = field.Message
+	}
+	return &Error{
+		Status: http.StatusBadRequest,
+		Code:   CodeValidation,
+		Detail: strings.Join(messages, "; "),
+		Fields: fields,
+	}
+}
+
+// Field returns the error of a rejected request field
+func Field(field, code, message string) FieldError {
+	return FieldError{Field: field, Code: code, Message: message}
+}
+
+func newError(status int, code, format string, args []interface{}) error {
+	err := fmt.Errorf(format, args...)
+	return &Error{Status: status, Code: code, Detail: err.Error(), cause: errors.Unwrap(err)}
+}
+
+// CodeFor returns the code of errors reported with status
+func CodeFor(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusInternalServerError:
+		return CodeInternal
+	}
+	if text := http.StatusText(status); text != "" {
+		return strings.ReplaceAll(strings.ToLower(text), " ", "_")
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
+
+// Problem is the problem details document (RFC 7807) of an error, extended with its stable
+// code and the rejected fields of validation errors
+type Problem struct {
+	Type     string       ` + "`" + `json:"type"` + "`" + `
+	Title    string       ` + "`" + `json:"title"` + "`" + `
+	Status   int          ` + "`" + `json:"status"` + "`" + `
+	Detail   string       ` + "`" + `json:"detail,omitempty"` + "`" + `
+	Instance string       ` + "`" + `json:"instance,omitempty"` + "`" + `
+	Code     string       ` + "`" + `json:"code"` + "`" + `
+	Errors   []FieldError ` + "`" + `json:"errors,omitempty"` + "`" + `
+}
+
+// ProblemFor returns the problem details of err for the request path instance. Errors that
+// are not an *Error are internal errors: they are logged and their message is not exposed.
+func ProblemFor(err error, instance string) *Problem {
+	var appErr *Error
+	if !errors.As(err, &appErr) {
+		appErr = &Error{Status: http.StatusInternalServerError, Code: CodeInternal, cause: err}
+	}
+
+	detail := appErr.Error()
+	if appErr.Code == CodeInternal {
+		log.Printf("%s: %v", instance, err)
+		detail = ""
+	}
+	return &Problem{
+		Type:     TypeBase + appErr.Code,
+		Title:    http.StatusText(appErr.Status),
+		Status:   appErr.Status,
+		Detail:   detail,
+		Instance: instance,
+		Code:     appErr.Code,
+		Errors:   appErr.Fields,
+	}
+}
+
+// FromBinding translates the error of binding a request into target: failed binding rules
+// become validation_failed errors naming the JSON fields, malformed bodies bad_request errors
+func FromBinding(err error, target interface{}) error {
+	var appErr *Error
+	if err == nil || errors.As(err, &appErr) {
+		return err
+	}
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		fields := make([]FieldError, len(validationErrors))
+		for i, fieldErr := range validationErrors {
+			name := jsonPath(reflect.TypeOf(target), fieldErr.StructNamespace())
+			fields[i] = Field(name, fieldErr.Tag(), ruleMessage(name, fieldErr))
+		}
+		return Invalid(fields...)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return Invalid(Field(typeErr.Field, "type", fmt.Sprintf("%s must be a %s", typeErr.Field, typeErr.Type)))
+	}
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return &Error{Status: http.StatusBadRequest, Code: CodeBadRequest, Detail: "request body is not valid JSON", cause: err}
+	}
+	if errors.Is(err, io.EOF) {
+		return &Error{Status: http.StatusBadRequest, Code: CodeBadRequest, Detail: "request body is empty", cause: err}
+	}
+	return &Error{Status: http.StatusBadRequest, Code: CodeBadRequest, Detail: err.Error(), cause: err}
+}
+
+// jsonPath converts the struct namespace of a validated field into its JSON path
+func jsonPath(t reflect.Type, namespace string) string {
+	parts := strings.Split(namespace, ".")
+	if len(parts) > 1 {
+		parts = parts[1:]
+	}
+	for i, part := range parts {
+		name, index := part, ""
+		if at := strings.IndexByte(part, '['); at >= 0 {
+			name, index = part[:at], part[at:]
+		}
+		for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map) {
+			t = t.Elem()
+		}
+		if t == nil || t.Kind() != reflect.Struct {
+			parts[i] = name + index
+			t = nil
+			continue
+		}
+		field, ok := t.FieldByName(name)
+		if !ok {
+			parts[i] = name + index
+			t = nil
+			continue
+		}
+		if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag != "" && tag != "-" {
+			name = tag
+		}
+		parts[i] = name + index
+		t = field.Type
+	}
+	return strings.Join(parts, ".")
+}
+
+// ruleMessage describes a failed binding rule
+func ruleMessage(field string, fieldErr validator.FieldError) string {
+	unit := ""
+	switch fieldErr.Kind() {
+	case reflect.String:
+		unit = " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		unit = " items"
+	}
+
+	param := fieldErr.Param()
+	switch fieldErr.Tag() {
+	case "required", "required_if", "required_unless", "required_with", "required_without":
+		return field + " is required"
+	case "email":
+		return field + " must be a valid email address"
+	case "url", "uri":
+		return field + " must be a valid URL"
+	case "uuid", "uuid4":
+		return field + " must be a valid UUID"
+	case "oneof":
+		return field + " must be one of " + strings.Join(strings.Fields(param), ", ")
+	case "min", "gte":
+		return field + " must be at least " + param + unit
+	case "max", "lte":
+		return field + " must be at most " + param + unit
+	case "gt":
+		return field + " must be greater than " + param + unit
+	case "lt":
+		return field + " must be less than " + param + unit
+	case "len":
+		return field + " must be exactly " + param + unit
+	default:
+		return fmt.Sprintf("%s failed the %s rule", field, fieldErr.Tag())
+	}
+}
+
+// FromDatabase translates the unique constraint violations of PostgreSQL, MySQL, SQLite and
+// MongoDB into a conflict error naming resource, other errors are returned unchanged
+func FromDatabase(err error, resource string) error {
+	if err == nil {
+		return nil
+	}
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return err
+	}
+
+	var state interface{ SQLState() string }
+	duplicate := errors.As(err, &state) && state.SQLState() == "23505"
+	if !duplicate {
+		message := err.Error()
+		for _, marker := range []string{"duplicate key value", "UNIQUE constraint failed", "Error 1062", "E11000", "duplicated key"} {
+			if strings.Contains(message, marker) {
+				duplicate = true
+				break
+			}
+		}
+	}
+	if !duplicate {
+		return err
+	}
+	return &Error{Status: http.StatusConflict, Code: CodeConflict, Detail: resource + " already exists", cause: err}
+}
+`
+
+// AppErrorsTestTemplate generates the tests of the problem details translation
+const AppErrorsTestTemplate = `package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func TestErrorsMatchTheirSentinel(t *testing.T) {
+	err := fmt.Errorf("failed to get order: %w", NotFound("order %s not found", "42"))
+	if !errors.Is(err, ErrNotFound) || errors.Is(err, ErrConflict) {
+		t.Fatalf("expected %v to match ErrNotFound only", err)
+	}
+	if err.Error() != "failed to get order: order 42 not found" {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+}
+
+func TestProblemForDomainError(t *testing.T) {
+	problem := ProblemFor(fmt.Errorf("failed to create order: %w", Conflict("order already exists")), "/api/v1/orders")
+	if problem.Status != http.StatusConflict || problem.Code != CodeConflict || problem.Type != TypeBase+CodeConflict {
+		t.Fatalf("unexpected problem %+v", problem)
+	}
+	if problem.Title != "Conflict" || problem.Detail != "order already exists" || problem.Instance != "/api/v1/orders" {
+		t.Fatalf("unexpected problem %+v", problem)
+	}
+}
+
+func TestProblemForHidesInternalErrors(t *testing.T) {
+	for _, err := range []error{
+		errors.New("dial tcp 10.0.0.1:5432: connection refused"),
+		Internal(errors.New("pq: relation \"orders\" does not exist")),
+		New(http.StatusInternalServerError, "query failed: %s", "syntax error"),
+	} {
+		problem := ProblemFor(err, "/api/v1/orders")
+		if problem.Status != http.StatusInternalServerError || problem.Code != CodeInternal || problem.Detail != "" {
+			t.Fatalf("expected a %d without detail for %v, got %+v", http.StatusInternalServerError, err, problem)
+		}
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	if err := Invalid(); err != nil {
+		t.Fatalf("expected no error without fields, got %v", err)
+	}
+
+	problem := ProblemFor(Invalid(Field("name", "required", "Name is required"), Field("price", "gt", "Price must be greater than 0")), "")
+	if problem.Status != http.StatusBadRequest || problem.Code != CodeValidation || len(problem.Errors) != 2 {
+		t.Fatalf("unexpected problem %+v", problem)
+	}
+	if problem.Errors[1].Field != "price" || problem.Detail != "Name is required; Price must be greater than 0" {
+		t.Fatalf("unexpected problem %+v", problem)
+	}
+}
+
+type bindingAddress struct {
+	City string ` + "`" + `json:"city" binding:"required"` + "`" + `
+}
+
+type bindingRequest struct {
+	Name    string          ` + "`" + `json:"name" binding:"required,min=3"` + "`" + `
+	Email   string          ` + "`" + `json:"contact_email" binding:"omitempty,email"` + "`" + `
+	Address bindingAddress  ` + "`" + `json:"address"` + "`" + `
+	Tags    []bindingTag    ` + "`" + `json:"tags" binding:"dive"` + "`" + `
+}
+
+type bindingTag struct {
+	Label string ` + "`" + `json:"label" binding:"required"` + "`" + `
+}
+
+func TestFromBinding(t *testing.T) {
+	validate := validator.New()
+	validate.SetTagName("binding")
+
+	req := bindingRequest{Name: "ab", Email: "nope", Tags: []bindingTag{ {} }}
+	err := FromBinding(validate.Struct(&req), &req)
+	if !errors.Is(err, ErrValidation) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+
+	var appErr *Error
+	errors.As(err, &appErr)
+	fields := map[string]string{}
+	for _, field := range appErr.Fields {
+		fields[field.Field] = field.Code
+	}
+	expected := map[string]string{"name": "min", "contact_email": "email", "address.city": "required", "tags[0].label": "required"}
+	for field, code := range expected {
+		if fields[field] != code {
+			t.Fatalf("expected %s to fail %s, got %v", field, code, appErr.Fields)
+		}
+	}
+
+	if err := FromBinding(errors.New("unexpected EOF"), &req); !errors.Is(err, ErrBadRequest) {
+		t.Fatalf("expected a bad request, got %v", err)
+	}
+}
+
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return "duplicate" }
+func (e sqlStateError) SQLState() string { return string(e) }
+
+func TestFromDatabase(t *testing.T) {
+	for _, err := range []error{
+		sqlStateError("23505"),
+		errors.New("UNIQUE constraint failed: orders.reference"),
+		errors.New("Error 1062 (23000): Duplicate entry 'A1' for key 'reference'"),
+		errors.New("write exception: E11000 duplicate key error collection: shop.orders"),
+	} {
+		if converted := FromDatabase(fmt.Errorf("insert: %w", err), "Order"); !errors.Is(converted, ErrConflict) || converted.Error() != "Order already exists" {
+			t.Fatalf("expected a conflict for %v, got %v", err, converted)
+		}
+	}
+
+	err := errors.New("connection reset")
+	if converted := FromDatabase(err, "Order"); converted != err {
+		t.Fatalf("expected %v unchanged, got %v", err, converted)
+	}
+}
+`
+
+// ProblemsTemplate generates the problem details responses of the handlers
+const ProblemsTemplate = `package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+{{range .HTTP.Imports}}	"{{.}}"
+{{end}}	"{{.Module}}/internal/apperrors"
+)
+{{- if .HTTP.NetHTTP}}
+
+// writeProblem answers with the problem details of err
+func writeProblem(w http.ResponseWriter, r *http.Request, err error) {
+	writeProblemDetails(w, apperrors.ProblemFor(err, r.URL.Path))
+}
+
+// writeProblemDetails writes a problem details response
+func writeProblemDetails(w http.ResponseWriter, problem *apperrors.Problem) {
+	w.Header().Set("Content-Type", apperrors.ContentType)
+	w.WriteHeader(problem.Status)
+	_ = json.NewEncoder(w).Encode(problem)
+}
+{{- else if .HTTP.Is "echo"}}
+
+// writeProblem answers with the problem details of err
+func writeProblem(c echo.Context, err error) error {
+	problem := apperrors.ProblemFor(err, c.Request().URL.Path)
+	c.Response().Header().Set(echo.HeaderContentType, apperrors.ContentType)
+	return c.JSON(problem.Status, problem)
+}
+
+// writeError answers with a problem of the given status
+func writeError(c echo.Context, status int, message string) error {
+	return writeProblem(c, apperrors.New(status, "%s", message))
+}
+{{- else if .HTTP.Is "fiber"}}
+
+// writeProblem answers with the problem details of err
+func writeProblem(c *fiber.Ctx, err error) error {
+	problem := apperrors.ProblemFor(err, c.Path())
+	return c.Status(problem.Status).JSON(problem, apperrors.ContentType)
+}
+
+// writeError answers with a problem of the given status
+func writeError(c *fiber.Ctx, status int, message string) error {
+	return writeProblem(c, apperrors.New(status, "%s", message))
+}
+{{- else}}
+
+// writeProblem answers with the problem details of err
+func writeProblem(c *gin.Context, err error) {
+	problem := apperrors.ProblemFor(err, c.Request.URL.Path)
+	c.Header("Content-Type", apperrors.ContentType)
+	c.JSON(problem.Status, problem)
+}
+
+// writeError answers with a problem of the given status
+func writeError(c *gin.Context, status int, message string) {
+	writeProblem(c, apperrors.New(status, "%s", message))
+}
+{{- end}}
+`
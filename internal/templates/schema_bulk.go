@@ -0,0 +1,1041 @@
+package templates
+
+// BulkPackageTemplate generates the shared batch helpers used by bulk endpoints
+const BulkPackageTemplate = `package bulk
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+{{if .HTTP.Validates}}
+	"github.com/gin-gonic/gin/binding"
+{{- else}}
+	"github.com/go-playground/validator/v10"
+{{- end}}
+)
+
+var (
+	// ErrEmptyBatch is returned when a batch contains no items
+	ErrEmptyBatch = errors.New("batch is empty")
+	// ErrBatchTooLarge is returned when a batch exceeds the configured maximum size
+	ErrBatchTooLarge = errors.New("batch exceeds maximum size")
+	// ErrValidation is returned when one or more batch items fail validation
+	ErrValidation = errors.New("batch contains invalid items")
+	// ErrEmptyFilter is returned when a bulk patch has no filter conditions
+	ErrEmptyFilter = errors.New("bulk patch requires at least one filter")
+	// ErrUnsupportedFormat is returned for import bodies that are neither CSV nor NDJSON
+	ErrUnsupportedFormat = errors.New("unsupported import format, use text/csv or application/x-ndjson")
+)
+
+// Item statuses reported per batch item
+const (
+	StatusOK       = "ok"
+	StatusInvalid  = "invalid"
+	StatusFailed   = "failed"
+	StatusNotFound = "not_found"
+	StatusSkipped  = "skipped"
+)
+
+// MaxReportedRowErrors caps the number of row errors kept in an import report
+const MaxReportedRowErrors = 1000
+
+// Config contains batch endpoint settings
+type Config struct {
+	MaxBatchSize int
+}
+
+// NewConfig returns a Config using BULK_MAX_BATCH_SIZE when set, falling back to defaultSize
+func NewConfig(defaultSize int) Config {
+	size := defaultSize
+	if value := os.Getenv("BULK_MAX_BATCH_SIZE"); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 {
+			size = n
+		}
+	}
+	return Config{MaxBatchSize: size}
+}
+
+// CheckSize validates the number of items in a batch
+func (c Config) CheckSize(n int) error {
+	if n == 0 {
+		return ErrEmptyBatch
+	}
+	if c.MaxBatchSize > 0 && n > c.MaxBatchSize {
+		return fmt.Errorf("%w: %d items, maximum is %d", ErrBatchTooLarge, n, c.MaxBatchSize)
+	}
+	return nil
+}
+
+// ItemResult reports the outcome of a single batch item
+type ItemResult struct {
+	Index  int    ` + "`" + `json:"index"` + "`" + `
+	ID     string ` + "`" + `json:"id,omitempty"` + "`" + `
+	Status string ` + "`" + `json:"status"` + "`" + `
+	Error  string ` + "`" + `json:"error,omitempty"` + "`" + `
+}
+
+// Report summarises a batch operation
+type Report struct {
+	Total     int          ` + "`" + `json:"total"` + "`" + `
+	Succeeded int          ` + "`" + `json:"succeeded"` + "`" + `
+	Failed    int          ` + "`" + `json:"failed"` + "`" + `
+	Committed bool         ` + "`" + `json:"committed"` + "`" + `
+	Items     []ItemResult ` + "`" + `json:"items"` + "`" + `
+}
+
+// NewReport creates a report with every item marked as skipped
+func NewReport(total int) *Report {
+	items := make([]ItemResult, total)
+	for i := range items {
+		items[i] = ItemResult{Index: i, Status: StatusSkipped}
+	}
+	return &Report{Total: total, Items: items}
+}
+
+// OK marks an item as successfully processed
+func (r *Report) OK(index int, id string) {
+	r.Items[index] = ItemResult{Index: index, ID: id, Status: StatusOK}
+}
+
+// Fail marks an item as failed with the given status
+func (r *Report) Fail(index int, status string, err error) {
+	r.Items[index].Status = status
+	if err != nil {
+		r.Items[index].Error = err.Error()
+	}
+}
+
+// HasErrors reports whether any item failed
+func (r *Report) HasErrors() bool {
+	for _, item := range r.Items {
+		if item.Status != StatusOK && item.Status != StatusSkipped {
+			return true
+		}
+	}
+	return false
+}
+
+// Finish computes the counters. Successful items of a rolled back batch are marked as skipped.
+func (r *Report) Finish(committed bool) *Report {
+	r.Committed = committed
+	r.Succeeded, r.Failed = 0, 0
+	for i := range r.Items {
+		switch r.Items[i].Status {
+		case StatusOK:
+			if !committed {
+				r.Items[i].Status = StatusSkipped
+				r.Items[i].ID = ""
+				continue
+			}
+			r.Succeeded++
+		case StatusSkipped:
+		default:
+			r.Failed++
+		}
+	}
+	return r
+}
+
+// Validatable is implemented by generated request types
+type Validatable interface {
+	Validate() error
+}
+
+{{- if not .HTTP.Validates}}
+
+// validate checks binding tags the way Gin does when binding
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.SetTagName("binding")
+	return v
+}
+{{- end}}
+
+// Validate applies the binding tags and the request's own validation rules
+func Validate(v Validatable) error {
+{{- if .HTTP.Validates}}
+	if err := binding.Validator.ValidateStruct(v); err != nil {
+{{- else}}
+	if err := validate.Struct(v); err != nil {
+{{- end}}
+		return err
+	}
+	return v.Validate()
+}
+
+// RowError reports a rejected import row
+type RowError struct {
+	Line  int    ` + "`" + `json:"line"` + "`" + `
+	Error string ` + "`" + `json:"error"` + "`" + `
+}
+
+// ImportReport summarises a streaming import
+type ImportReport struct {
+	Imported  int        ` + "`" + `json:"imported"` + "`" + `
+	Rejected  int        ` + "`" + `json:"rejected"` + "`" + `
+	Errors    []RowError ` + "`" + `json:"errors,omitempty"` + "`" + `
+	Truncated bool       ` + "`" + `json:"truncated,omitempty"` + "`" + `
+}
+
+// Reject records a rejected row
+func (r *ImportReport) Reject(line int, err error) {
+	r.Rejected++
+	if len(r.Errors) >= MaxReportedRowErrors {
+		r.Truncated = true
+		return
+	}
+	r.Errors = append(r.Errors, RowError{Line: line, Error: err.Error()})
+}
+
+// Row is a single decoded import row
+type Row struct {
+	Line int
+	Data json.RawMessage
+}
+
+// Decode unmarshals the row into v
+func (r *Row) Decode(v interface{}) error {
+	return json.Unmarshal(r.Data, v)
+}
+
+// RowDecodeError is returned by a RowReader for a row that cannot be decoded.
+// The reader can continue after it.
+type RowDecodeError struct {
+	Line int
+	Err  error
+}
+
+func (e *RowDecodeError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *RowDecodeError) Unwrap() error {
+	return e.Err
+}
+
+// RowReader streams import rows, returning io.EOF when the input is exhausted
+type RowReader interface {
+	Next() (*Row, error)
+}
+
+// NewRowReader returns a reader for the given content type. Kinds maps CSV columns
+// to their value kind ("int", "float", "bool", "json" or "string").
+func NewRowReader(contentType string, r io.Reader, kinds map[string]string) (RowReader, error) {
+	switch {
+	case strings.HasPrefix(contentType, "text/csv"):
+		reader := csv.NewReader(r)
+		reader.ReuseRecord = true
+		header, err := reader.Read()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV header: %w", err)
+		}
+		columns := make([]string, len(header))
+		for i, column := range header {
+			columns[i] = strings.TrimSpace(column)
+		}
+		return &csvRowReader{reader: reader, header: columns, kinds: kinds, line: 1}, nil
+	case strings.HasPrefix(contentType, "application/x-ndjson"), strings.HasPrefix(contentType, "application/jsonl"):
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		return &ndjsonRowReader{scanner: scanner}, nil
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+type csvRowReader struct {
+	reader *csv.Reader
+	header []string
+	kinds  map[string]string
+	line   int
+}
+
+func (r *csvRowReader) Next() (*Row, error) {
+	record, err := r.reader.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, &RowDecodeError{Line: parseErr.StartLine, Err: parseErr.Err}
+		}
+		return nil, err
+	}
+	r.line, _ = r.reader.FieldPos(0)
+
+	values := make(map[string]interface{}, len(record))
+	for i, raw := range record {
+		if i >= len(r.header) || raw == "" {
+			continue
+		}
+		column := r.header[i]
+		value, err := coerce(r.kinds[column], raw)
+		if err != nil {
+			return nil, &RowDecodeError{Line: r.line, Err: fmt.Errorf("column %s: %w", column, err)}
+		}
+		values[column] = value
+	}
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil, &RowDecodeError{Line: r.line, Err: err}
+	}
+	return &Row{Line: r.line, Data: data}, nil
+}
+
+type ndjsonRowReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (r *ndjsonRowReader) Next() (*Row, error) {
+	for r.scanner.Scan() {
+		r.line++
+		text := strings.TrimSpace(r.scanner.Text())
+		if text == "" {
+			continue
+		}
+		if !json.Valid([]byte(text)) {
+			return nil, &RowDecodeError{Line: r.line, Err: errors.New("invalid JSON")}
+		}
+		return &Row{Line: r.line, Data: json.RawMessage(text)}, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// coerce converts a CSV cell into the JSON value expected by the request type
+func coerce(kind, raw string) (interface{}, error) {
+	switch kind {
+	case "int":
+		return strconv.ParseInt(raw, 10, 64)
+	case "float":
+		return strconv.ParseFloat(raw, 64)
+	case "bool":
+		return strconv.ParseBool(raw)
+	case "json":
+		if !json.Valid([]byte(raw)) {
+			return nil, errors.New("invalid JSON")
+		}
+		return json.RawMessage(raw), nil
+	default:
+		return raw, nil
+	}
+}
+`
+
+// SchemaBulkRepositoryTemplate generates the transactional batch repository for a resource
+const SchemaBulkRepositoryTemplate = `package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+{{- if .UpsertColumns}}
+	"gorm.io/gorm/clause"
+{{- end}}
+	"{{.Module}}/internal/bulk"
+	"{{.Module}}/internal/models"
+)
+
+// {{.Names.PascalCase}}BulkRepository handles batch database operations for {{.DisplayName}}
+type {{.Names.PascalCase}}BulkRepository struct {
+	db *gorm.DB
+}
+
+// New{{.Names.PascalCase}}BulkRepository creates a new {{.Names.PascalCase}} bulk repository
+func New{{.Names.PascalCase}}BulkRepository(db *gorm.DB) *{{.Names.PascalCase}}BulkRepository {
+	return &{{.Names.PascalCase}}BulkRepository{db: db}
+}
+
+// CreateMany inserts all {{.Names.Plural}} in a single transaction
+func (r *{{.Names.PascalCase}}BulkRepository) CreateMany(ctx context.Context, items []*models.{{.Names.PascalCase}}, report *bulk.Report) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, item := range items {
+			if err := tx.Create(item).Error; err != nil {
+				report.Fail(i, bulk.StatusFailed, err)
+				return err
+			}
+			report.OK(i, item.ID.Hex())
+		}
+		return nil
+	})
+}
+{{- if .UpsertColumns}}
+
+// UpsertMany inserts or updates all {{.Names.Plural}} by their natural key in a single transaction
+func (r *{{.Names.PascalCase}}BulkRepository) UpsertMany(ctx context.Context, items []*models.{{.Names.PascalCase}}, report *bulk.Report) error {
+	onConflict := clause.OnConflict{
+		Columns: []clause.Column{
+{{- range .UpsertColumns}}
+			{Name: "{{.}}"},
+{{- end}}
+		},
+		DoUpdates: clause.AssignmentColumns([]string{
+{{- range .UpdateColumns}}
+			"{{.}}",
+{{- end}}
+		}),
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, item := range items {
+			if err := tx.Clauses(onConflict).Create(item).Error; err != nil {
+				report.Fail(i, bulk.StatusFailed, err)
+				return err
+			}
+			report.OK(i, item.ID.Hex())
+		}
+		return nil
+	})
+}
+{{- end}}
+
+{{- if .Bulk.Import}}
+
+// ImportMany inserts the {{.Names.Plural}} of an import batch in a single transaction. Each row is
+// inserted under a savepoint, rows the database rejects are rolled back alone and reported.
+func (r *{{.Names.PascalCase}}BulkRepository) ImportMany(ctx context.Context, items []*models.{{.Names.PascalCase}}, report *bulk.Report) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, item := range items {
+			if err := tx.SavePoint("import_row").Error; err != nil {
+				return err
+			}
+			if err := tx.Create(item).Error; err != nil {
+				if rollbackErr := tx.RollbackTo("import_row").Error; rollbackErr != nil {
+					return rollbackErr
+				}
+				report.Fail(i, bulk.StatusFailed, err)
+				continue
+			}
+			report.OK(i, item.ID.Hex())
+		}
+		return nil
+	})
+}
+{{- end}}
+
+// PatchWhere applies the same column updates to every {{.Names.Singular}} matching the filter
+func (r *{{.Names.PascalCase}}BulkRepository) PatchWhere(ctx context.Context, filter *models.{{.Names.PascalCase}}Filter, updates map[string]interface{}) (int64, error) {
+	var affected int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&models.{{.Names.PascalCase}}{})
+{{- range .Fields}}
+{{- if and .Filterable .GoFilterQuery}}
+		{{.GoFilterQuery}}
+{{- end}}
+{{- end}}
+
+		// Refuse to patch the whole table
+		if _, ok := query.Statement.Clauses["WHERE"]; !ok {
+			return bulk.ErrEmptyFilter
+		}
+
+		result := query.Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		affected = result.RowsAffected
+		return nil
+	})
+	return affected, err
+}
+
+// DeleteByIDs deletes the given {{.Names.Plural}} in a single transaction. Missing IDs are reported but do not abort the batch.
+func (r *{{.Names.PascalCase}}BulkRepository) DeleteByIDs(ctx context.Context, ids []string, report *bulk.Report) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, id := range ids {
+			result := tx.Where("id = ?", id).Delete(&models.{{.Names.PascalCase}}{})
+			if result.Error != nil {
+				report.Fail(i, bulk.StatusFailed, result.Error)
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				report.Fail(i, bulk.StatusNotFound, fmt.Errorf("{{.Names.Singular}} %s not found", id))
+				continue
+			}
+			report.OK(i, id)
+		}
+		return nil
+	})
+}
+`
+
+// SchemaBulkServiceTemplate generates the batch service layer for a resource
+const SchemaBulkServiceTemplate = `package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+{{- if .Bulk.Import}}
+	"io"
+{{- end}}
+{{- if .HasFeature "caching"}}
+	"log"
+{{- end}}
+
+	"{{.Module}}/internal/bulk"
+	"{{.Module}}/internal/models"
+	"{{.Module}}/internal/repositories"
+)
+
+// {{.Names.CamelCase}}PatchableColumns maps JSON field names accepted by bulk patch to their columns
+var {{.Names.CamelCase}}PatchableColumns = map[string]string{
+{{- range .PatchableFields}}
+	"{{.JSONName}}": "{{.Column}}",
+{{- end}}
+}
+{{- if .Bulk.Import}}
+
+// {{.Names.CamelCase}}ImportKinds maps import columns to the value kind used when decoding CSV cells
+var {{.Names.CamelCase}}ImportKinds = map[string]string{
+{{- range .PatchableFields}}
+	"{{.JSONName}}": "{{.Kind}}",
+{{- end}}
+}
+{{- end}}
+
+// {{.Names.PascalCase}}BulkService handles batch operations for {{.DisplayName}}
+type {{.Names.PascalCase}}BulkService struct {
+	repo   *repositories.{{.Names.PascalCase}}BulkRepository
+	config bulk.Config
+{{- if .HasFeature "caching"}}
+	cached *repositories.Cached{{.Names.PascalCase}}Repository
+{{- end}}
+}
+
+// New{{.Names.PascalCase}}BulkService creates a new {{.Names.PascalCase}} bulk service
+func New{{.Names.PascalCase}}BulkService(repo *repositories.{{.Names.PascalCase}}BulkRepository, config bulk.Config) *{{.Names.PascalCase}}BulkService {
+	if config.MaxBatchSize <= 0 {
+		config.MaxBatchSize = {{.Bulk.MaxBatchSize}}
+	}
+	return &{{.Names.PascalCase}}BulkService{repo: repo, config: config}
+}
+{{- if .HasFeature "caching"}}
+
+// WithCache invalidates the {{.Names.Plural}} cached by the repository the {{.Names.PascalCase}} service reads
+// through after every bulk write, which bypasses it
+func (s *{{.Names.PascalCase}}BulkService) WithCache(cached *repositories.Cached{{.Names.PascalCase}}Repository) *{{.Names.PascalCase}}BulkService {
+	s.cached = cached
+	return s
+}
+{{- end}}
+
+// CreateMany validates and creates a batch of {{.Names.Plural}} in one transaction
+func (s *{{.Names.PascalCase}}BulkService) CreateMany(ctx context.Context, reqs []*models.{{.Names.PascalCase}}Request) (*bulk.Report, error) {
+	if err := s.config.CheckSize(len(reqs)); err != nil {
+		return nil, err
+	}
+
+	report := bulk.NewReport(len(reqs))
+	items, err := s.buildItems(reqs, report)
+	if err != nil {
+		return report.Finish(false), err
+	}
+{{- if .HasFeature "caching"}}
+	defer s.invalidate(ctx)
+{{- end}}
+
+	if err := s.repo.CreateMany(ctx, items, report); err != nil {
+		return report.Finish(false), fmt.Errorf("failed to create {{.Names.Plural}}: %w", err)
+	}
+	return report.Finish(true), nil
+}
+{{- if .UpsertColumns}}
+
+// UpsertMany validates and upserts a batch of {{.Names.Plural}} by their natural key in one transaction
+func (s *{{.Names.PascalCase}}BulkService) UpsertMany(ctx context.Context, reqs []*models.{{.Names.PascalCase}}Request) (*bulk.Report, error) {
+	if err := s.config.CheckSize(len(reqs)); err != nil {
+		return nil, err
+	}
+
+	report := bulk.NewReport(len(reqs))
+	items, err := s.buildItems(reqs, report)
+	if err != nil {
+		return report.Finish(false), err
+	}
+{{- if .HasFeature "caching"}}
+	defer s.invalidate(ctx)
+{{- end}}
+
+	if err := s.repo.UpsertMany(ctx, items, report); err != nil {
+		return report.Finish(false), fmt.Errorf("failed to upsert {{.Names.Plural}}: %w", err)
+	}
+	return report.Finish(true), nil
+}
+{{- end}}
+
+// PatchWhere applies the given field values to every {{.Names.Singular}} matching the filter
+func (s *{{.Names.PascalCase}}BulkService) PatchWhere(ctx context.Context, filter *models.{{.Names.PascalCase}}Filter, set map[string]interface{}) (int64, error) {
+	if len(set) == 0 {
+		return 0, fmt.Errorf("%w: no fields to update", bulk.ErrValidation)
+	}
+
+	updates := make(map[string]interface{}, len(set))
+	for field, value := range set {
+		column, ok := {{.Names.CamelCase}}PatchableColumns[field]
+		if !ok {
+			return 0, fmt.Errorf("%w: field %q cannot be patched", bulk.ErrValidation, field)
+		}
+		updates[column] = value
+	}
+{{- if .HasFeature "caching"}}
+	defer s.invalidate(ctx)
+{{- end}}
+
+	affected, err := s.repo.PatchWhere(ctx, filter, updates)
+	if err != nil {
+		return 0, fmt.Errorf("failed to patch {{.Names.Plural}}: %w", err)
+	}
+	return affected, nil
+}
+
+// DeleteByIDs deletes a batch of {{.Names.Plural}} in one transaction
+func (s *{{.Names.PascalCase}}BulkService) DeleteByIDs(ctx context.Context, ids []string) (*bulk.Report, error) {
+	if err := s.config.CheckSize(len(ids)); err != nil {
+		return nil, err
+	}
+
+	report := bulk.NewReport(len(ids))
+{{- if .HasFeature "caching"}}
+	defer s.invalidate(ctx)
+{{- end}}
+	if err := s.repo.DeleteByIDs(ctx, ids, report); err != nil {
+		return report.Finish(false), fmt.Errorf("failed to delete {{.Names.Plural}}: %w", err)
+	}
+	return report.Finish(true), nil
+}
+{{- if .Bulk.Import}}
+
+// Import streams CSV or NDJSON rows, validates each one and inserts valid rows in transactional
+// batches. Rows failing validation or rejected by the database are reported by line.
+func (s *{{.Names.PascalCase}}BulkService) Import(ctx context.Context, contentType string, body io.Reader) (*bulk.ImportReport, error) {
+	reader, err := bulk.NewRowReader(contentType, body, {{.Names.CamelCase}}ImportKinds)
+	if err != nil {
+		return nil, err
+	}
+{{- if .HasFeature "caching"}}
+	// Batches commit as the rows are read, so the cache is invalidated even when a later one fails
+	defer s.invalidate(ctx)
+{{- end}}
+
+	result := &bulk.ImportReport{}
+	batch := make([]*models.{{.Names.PascalCase}}, 0, s.config.MaxBatchSize)
+	lines := make([]int, 0, s.config.MaxBatchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		report := bulk.NewReport(len(batch))
+		if err := s.repo.ImportMany(ctx, batch, report); err != nil {
+			return fmt.Errorf("failed to import batch: %w", err)
+		}
+		for i, item := range report.Items {
+			if item.Status != bulk.StatusOK {
+				result.Reject(lines[i], errors.New(item.Error))
+				continue
+			}
+			result.Imported++
+		}
+		batch = make([]*models.{{.Names.PascalCase}}, 0, s.config.MaxBatchSize)
+		lines = make([]int, 0, s.config.MaxBatchSize)
+		return nil
+	}
+
+	for {
+		row, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var rowErr *bulk.RowDecodeError
+			if errors.As(err, &rowErr) {
+				result.Reject(rowErr.Line, rowErr.Err)
+				continue
+			}
+			return result, err
+		}
+
+		var req models.{{.Names.PascalCase}}Request
+		if err := row.Decode(&req); err != nil {
+			result.Reject(row.Line, err)
+			continue
+		}
+		if err := bulk.Validate(&req); err != nil {
+			result.Reject(row.Line, err)
+			continue
+		}
+
+		batch = append(batch, new{{.Names.PascalCase}}FromRequest(&req))
+		lines = append(lines, row.Line)
+		if len(batch) >= s.config.MaxBatchSize {
+			if err := flush(); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+{{- end}}
+
+{{- if .HasFeature "caching"}}
+
+// invalidate drops the cached {{.Names.Plural}} after a bulk write. Failures are logged, the write
+// already happened.
+func (s *{{.Names.PascalCase}}BulkService) invalidate(ctx context.Context) {
+	if s.cached == nil {
+		return
+	}
+	if err := s.cached.Invalidate(ctx); err != nil {
+		log.Printf("cache: invalidate {{.Names.Plural}}: %v", err)
+	}
+}
+{{- end}}
+
+// buildItems validates every request and converts the valid ones to models
+func (s *{{.Names.PascalCase}}BulkService) buildItems(reqs []*models.{{.Names.PascalCase}}Request, report *bulk.Report) ([]*models.{{.Names.PascalCase}}, error) {
+	items := make([]*models.{{.Names.PascalCase}}, len(reqs))
+	valid := true
+
+	for i, req := range reqs {
+		if req == nil {
+			report.Fail(i, bulk.StatusInvalid, errors.New("item is null"))
+			valid = false
+			continue
+		}
+		if err := bulk.Validate(req); err != nil {
+			report.Fail(i, bulk.StatusInvalid, err)
+			valid = false
+			continue
+		}
+		items[i] = new{{.Names.PascalCase}}FromRequest(req)
+	}
+
+	if !valid {
+		return nil, bulk.ErrValidation
+	}
+	return items, nil
+}
+
+// new{{.Names.PascalCase}}FromRequest converts a request into a model
+func new{{.Names.PascalCase}}FromRequest(req *models.{{.Names.PascalCase}}Request) *models.{{.Names.PascalCase}} {
+	return &models.{{.Names.PascalCase}}{
+{{- range .Fields}}
+{{- if not .ReadOnly}}
+		{{.Names.PascalCase}}: req.{{.Names.PascalCase}},
+{{- end}}
+{{- end}}
+	}
+}
+`
+
+// SchemaBulkImportTestTemplate generates a test importing rows into sqlite, the second row
+// repeating the unique fields of the first one
+const SchemaBulkImportTestTemplate = `package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"{{.Module}}/internal/bulk"
+	"{{.Module}}/internal/models"
+	"{{.Module}}/internal/repositories"
+)
+
+func Test{{.Names.PascalCase}}BulkService_ImportRejectsDuplicateRows(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.{{.Names.PascalCase}}{}); err != nil {
+		t.Fatal(err)
+	}
+	service := New{{.Names.PascalCase}}BulkService(repositories.New{{.Names.PascalCase}}BulkRepository(db), bulk.Config{})
+
+	rows := strings.Join([]string{
+{{- range .ImportTestRows}}
+		{{printf "%q" .}},
+{{- end}}
+	}, "\n")
+	report, err := service.Import(context.Background(), "application/x-ndjson", strings.NewReader(rows))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Imported != 2 || report.Rejected != 1 || len(report.Errors) != 1 || report.Errors[0].Line != 2 {
+		t.Fatalf("expected line 2 to be rejected and the other rows imported, got %+v", report)
+	}
+
+	var count int64
+	if err := db.Model(&models.{{.Names.PascalCase}}{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 {{.Names.Plural}} imported, got %d", count)
+	}
+}
+`
+
+// SchemaBulkHandlerTemplate generates the batch HTTP handlers for a resource
+const SchemaBulkHandlerTemplate = `package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+{{- if .Bulk.Import}}
+	"errors"
+{{- end}}
+	"net/http"
+
+{{range .HTTP.Imports}}	"{{.}}"
+{{end}}{{if .Bulk.Import}}	"{{.Module}}/internal/bulk"
+{{end}}	"{{.Module}}/internal/models"
+	"{{.Module}}/internal/services"
+)
+{{- if .Bulk.Import}}
+
+// {{.Names.CamelCase}}MaxImportBodySize limits the size of streamed import bodies
+const {{.Names.CamelCase}}MaxImportBodySize = 100 << 20
+{{- end}}
+
+// {{.Names.PascalCase}}BulkHandler handles batch HTTP requests for {{.DisplayName}}
+type {{.Names.PascalCase}}BulkHandler struct {
+	service *services.{{.Names.PascalCase}}BulkService
+}
+
+// New{{.Names.PascalCase}}BulkHandler creates a new {{.Names.PascalCase}} bulk handler
+func New{{.Names.PascalCase}}BulkHandler(service *services.{{.Names.PascalCase}}BulkService) *{{.Names.PascalCase}}BulkHandler {
+	return &{{.Names.PascalCase}}BulkHandler{service: service}
+}
+
+// {{.Names.PascalCase}}BulkPatchRequest is the body of PATCH /{{.Names.KebabPlural}}/bulk
+type {{.Names.PascalCase}}BulkPatchRequest struct {
+	Filter models.{{.Names.PascalCase}}Filter ` + "`" + `json:"filter"` + "`" + `
+	Set    map[string]interface{}          ` + "`" + `json:"set" binding:"required"` + "`" + `
+}
+
+// {{.Names.PascalCase}}BulkDeleteRequest is the body of DELETE /{{.Names.KebabPlural}}/bulk
+type {{.Names.PascalCase}}BulkDeleteRequest struct {
+	IDs []string ` + "`" + `json:"ids" binding:"required"` + "`" + `
+}
+
+// BulkCreate handles POST /{{.Names.KebabPlural}}/bulk
+func (h *{{.Names.PascalCase}}BulkHandler) BulkCreate({{.HTTP.HandlerParams}}){{.HTTP.HandlerResult}} {
+	var reqs []*models.{{.Names.PascalCase}}Request
+	if err := {{.HTTP.DecodeJSON "&reqs"}}; err != nil {
+		{{.HTTP.Fail "http.StatusBadRequest" "\"request body must be a JSON array\""}}
+	}
+
+	report, err := h.service.CreateMany({{.HTTP.Context}}, reqs)
+	{{.HTTP.Return (printf "respondBulk(%s, report, err)" .HTTP.HandlerArgs)}}
+}
+{{- if .UpsertColumns}}
+
+// BulkUpsert handles PUT /{{.Names.KebabPlural}}/bulk
+func (h *{{.Names.PascalCase}}BulkHandler) BulkUpsert({{.HTTP.HandlerParams}}){{.HTTP.HandlerResult}} {
+	var reqs []*models.{{.Names.PascalCase}}Request
+	if err := {{.HTTP.DecodeJSON "&reqs"}}; err != nil {
+		{{.HTTP.Fail "http.StatusBadRequest" "\"request body must be a JSON array\""}}
+	}
+
+	report, err := h.service.UpsertMany({{.HTTP.Context}}, reqs)
+	{{.HTTP.Return (printf "respondBulk(%s, report, err)" .HTTP.HandlerArgs)}}
+}
+{{- end}}
+
+// BulkPatch handles PATCH /{{.Names.KebabPlural}}/bulk
+func (h *{{.Names.PascalCase}}BulkHandler) BulkPatch({{.HTTP.HandlerParams}}){{.HTTP.HandlerResult}} {
+	var req {{.Names.PascalCase}}BulkPatchRequest
+	if err := {{.HTTP.BindJSON "&req"}}; err != nil {
+		{{.HTTP.Fail "http.StatusBadRequest" "err.Error()"}}
+	}
+
+	updated, err := h.service.PatchWhere({{.HTTP.Context}}, &req.Filter, req.Set)
+	if err != nil {
+		{{.HTTP.Exit (printf "respondBulk(%s, nil, err)" .HTTP.HandlerArgs)}}
+	}
+
+	{{.HTTP.Reply "http.StatusOK"}}{{.HTTP.Map}}{"updated": updated})
+}
+
+// BulkDelete handles DELETE /{{.Names.KebabPlural}}/bulk
+func (h *{{.Names.PascalCase}}BulkHandler) BulkDelete({{.HTTP.HandlerParams}}){{.HTTP.HandlerResult}} {
+	var req {{.Names.PascalCase}}BulkDeleteRequest
+	if err := {{.HTTP.BindJSON "&req"}}; err != nil {
+		{{.HTTP.Fail "http.StatusBadRequest" "err.Error()"}}
+	}
+
+	report, err := h.service.DeleteByIDs({{.HTTP.Context}}, req.IDs)
+	{{.HTTP.Return (printf "respondBulk(%s, report, err)" .HTTP.HandlerArgs)}}
+}
+{{- if .Bulk.Import}}
+
+// Import handles POST /{{.Names.KebabPlural}}/import with a text/csv or application/x-ndjson body
+func (h *{{.Names.PascalCase}}BulkHandler) Import({{.HTTP.HandlerParams}}){{.HTTP.HandlerResult}} {
+	body := {{.HTTP.LimitBody (printf "%sMaxImportBodySize" .Names.CamelCase)}}
+
+	report, err := h.service.Import({{.HTTP.Context}}, {{.HTTP.ContentType}}, body)
+	switch {
+	case errors.Is(err, bulk.ErrUnsupportedFormat):
+		{{.HTTP.Error "http.StatusUnsupportedMediaType" "err.Error()"}}
+	case err != nil && report == nil:
+		{{.HTTP.Error "http.StatusBadRequest" "err.Error()"}}
+	case err != nil:
+		{{.HTTP.Reply "http.StatusInternalServerError"}}{{.HTTP.Map}}{"error": err.Error(), "report": report})
+	case report.Rejected > 0:
+		{{.HTTP.Respond "http.StatusUnprocessableEntity" "report"}}
+	default:
+		{{.HTTP.Respond "http.StatusOK" "report"}}
+	}
+}
+{{- end}}
+
+// Setup{{.Names.PascalCase}}BulkRoutes sets up batch routes for {{.DisplayName}}
+func Setup{{.Names.PascalCase}}BulkRoutes({{.HTTP.Router}}, handler *{{.Names.PascalCase}}BulkHandler) {
+{{- with .HTTP.Group .Names.CamelPlural (printf "/%s" .Names.KebabPlural)}}
+{{- with .Open}}
+	{{.}}
+{{- end}}
+	{{.Route "POST" "/bulk" "handler.BulkCreate"}}
+{{- if $.UpsertColumns}}
+	{{.Route "PUT" "/bulk" "handler.BulkUpsert"}}
+{{- end}}
+	{{.Route "PATCH" "/bulk" "handler.BulkPatch"}}
+	{{.Route "DELETE" "/bulk" "handler.BulkDelete"}}
+{{- if $.Bulk.Import}}
+	{{.Route "POST" "/import" "handler.Import"}}
+{{- end}}
+{{- with .Close}}
+	{{.}}
+{{- end}}
+{{- end}}
+}
+`
+
+// BulkResponseHelperTemplate generates the shared response mapping for bulk handlers
+const BulkResponseHelperTemplate = `package handlers
+
+import (
+	"errors"
+	"net/http"
+
+{{range .HTTP.Imports}}	"{{.}}"
+{{end}}	"{{.Module}}/internal/bulk"
+)
+
+// respondBulk maps a batch report and error to an HTTP response
+func respondBulk({{.HTTP.HandlerParams}}, report *bulk.Report, err error){{.HTTP.HandlerResult}} {
+	switch {
+	case errors.Is(err, bulk.ErrBatchTooLarge):
+		{{.HTTP.Error "http.StatusRequestEntityTooLarge" "err.Error()"}}
+	case errors.Is(err, bulk.ErrEmptyBatch), errors.Is(err, bulk.ErrEmptyFilter):
+		{{.HTTP.Error "http.StatusBadRequest" "err.Error()"}}
+	case errors.Is(err, bulk.ErrValidation) && report == nil:
+		{{.HTTP.Error "http.StatusUnprocessableEntity" "err.Error()"}}
+	case errors.Is(err, bulk.ErrValidation):
+		{{.HTTP.Respond "http.StatusUnprocessableEntity" "report"}}
+	case err != nil && report != nil:
+		{{.HTTP.Respond "http.StatusConflict" "report"}}
+	case err != nil:
+		{{.HTTP.Error "http.StatusInternalServerError" "err.Error()"}}
+	default:
+		{{.HTTP.Respond "http.StatusOK" "report"}}
+	}
+}
+`
+
+// SchemaBulkCacheTestTemplate generates a test reading a record through the caching repository,
+// patching it in bulk and reading it again, which must return the patched value
+const SchemaBulkCacheTestTemplate = `package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"{{.Module}}/internal/bulk"
+	"{{.Module}}/internal/cache"
+	"{{.Module}}/internal/models"
+	"{{.Module}}/internal/repositories"
+)
+
+// sqlite{{.Names.PascalCase}}Repository reads the {{.Names.Plural}} the bulk repository writes
+type sqlite{{.Names.PascalCase}}Repository struct {
+	repositories.{{.Names.PascalCase}}RepositoryInterface
+	db *gorm.DB
+}
+
+func (r *sqlite{{.Names.PascalCase}}Repository) GetByID(ctx context.Context, id string) (*models.{{.Names.PascalCase}}, error) {
+	var {{.Names.CamelCase}} models.{{.Names.PascalCase}}
+	if err := r.db.WithContext(ctx).First(&{{.Names.CamelCase}}, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &{{.Names.CamelCase}}, nil
+}
+
+func Test{{.Names.PascalCase}}BulkService_InvalidatesCache(t *testing.T) {
+	ctx := context.Background()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.{{.Names.PascalCase}}{}); err != nil {
+		t.Fatal(err)
+	}
+	cached := repositories.NewCached{{.Names.PascalCase}}Repository(&sqlite{{.Names.PascalCase}}Repository{db: db}, cache.NewLRU(100))
+	service := New{{.Names.PascalCase}}BulkService(repositories.New{{.Names.PascalCase}}BulkRepository(db), bulk.Config{}).WithCache(cached)
+
+	var req models.{{.Names.PascalCase}}Request
+	if err := json.Unmarshal([]byte({{printf "%q" .CacheTestPayload}}), &req); err != nil {
+		t.Fatal(err)
+	}
+	report, err := service.CreateMany(ctx, []*models.{{.Names.PascalCase}}Request{&req})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := report.Items[0].ID
+
+	before, err := cached.GetByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+{{- with .CacheTestField}}
+	value := before.{{.Names.PascalCase}}
+	want := {{if eq .GoType "bool"}}!value{{else}}value + 1{{end}}
+	filter := &models.{{$.Names.PascalCase}}Filter{ {{- .Names.PascalCase}}: &value}
+	if _, err := service.PatchWhere(ctx, filter, map[string]interface{}{"{{.Names.SnakeCase}}": want}); err != nil {
+		t.Fatal(err)
+	}
+
+	after, err := cached.GetByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.{{.Names.PascalCase}} != want {
+		t.Fatalf("expected {{.Names.SnakeCase}} %v after the bulk patch, the cache returned %v", want, after.{{.Names.PascalCase}})
+	}
+{{- end}}
+}
+`
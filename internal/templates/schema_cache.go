@@ -0,0 +1,702 @@
+package templates
+
+// CachePackageTemplate generates the shared cache interface, key namespaces and read-through helper
+const CachePackageTemplate = `package cache
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Cache stores encoded values with a TTL. Counters are never evicted by the in-memory
+// backend, they version the keys of a namespace.
+type Cache interface {
+	// Get returns the value of a key, false when it is missing or expired
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	// Incr increments a counter and returns its new value
+	Incr(ctx context.Context, key string) (int64, error)
+}
+
+// KeyPrefix prefixes every key, set it when several applications share a Redis database
+var KeyPrefix = "cache"
+
+// FromEnv returns a Redis cache when REDIS_ADDR is set, and an in-memory LRU cache
+// holding up to size entries otherwise
+func FromEnv(size int) Cache {
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		return NewLRU(size)
+	}
+
+	db, _ := strconv.Atoi(os.Getenv("REDIS_DB"))
+	return NewRedis(redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: os.Getenv("REDIS_PASSWORD"),
+		DB:       db,
+	}))
+}
+
+// Namespace builds the keys of a resource. Keys embed the generation of their group so a
+// whole group is invalidated by incrementing a counter instead of deleting every key.
+type Namespace struct {
+	cache    Cache
+	resource string
+}
+
+// Key groups of a namespace
+const (
+	// GroupItem holds single records, invalidated by writes of resources they include
+	GroupItem = "item"
+	// GroupQuery holds lists and lookups, invalidated by every write
+	GroupQuery = "query"
+)
+
+// NewNamespace creates the key namespace of a resource
+func NewNamespace(cache Cache, resource string) Namespace {
+	return Namespace{cache: cache, resource: resource}
+}
+
+// Key returns the key of parts in a group, at the current generation of the group
+func (n Namespace) Key(ctx context.Context, group string, parts ...string) (string, error) {
+	generation := "0"
+	value, ok, err := n.cache.Get(ctx, n.counter(group))
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		generation = string(value)
+	}
+	return KeyPrefix + ":" + n.resource + ":" + group + ":" + generation + ":" + strings.Join(parts, ":"), nil
+}
+
+// Invalidate drops every key of the groups
+func (n Namespace) Invalidate(ctx context.Context, groups ...string) error {
+	for _, group := range groups {
+		if _, err := n.cache.Incr(ctx, n.counter(group)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InvalidateDependents drops the keys of the resources whose reads include this resource
+func (n Namespace) InvalidateDependents(ctx context.Context) error {
+	for _, dependent := range Dependents(n.resource) {
+		if err := NewNamespace(n.cache, dependent).Invalidate(ctx, GroupItem, GroupQuery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n Namespace) counter(group string) string {
+	return KeyPrefix + ":" + n.resource + ":generation:" + group
+}
+
+var (
+	dependentsMu sync.RWMutex
+	dependents   = make(map[string][]string)
+)
+
+// DependsOn records that cached reads of dependent include target, so writes of target
+// invalidate dependent
+func DependsOn(dependent, target string) {
+	dependentsMu.Lock()
+	defer dependentsMu.Unlock()
+	for _, existing := range dependents[target] {
+		if existing == dependent {
+			return
+		}
+	}
+	dependents[target] = append(dependents[target], dependent)
+}
+
+// Dependents returns the resources whose cached reads include target
+func Dependents(target string) []string {
+	dependentsMu.RLock()
+	defer dependentsMu.RUnlock()
+	return append([]string(nil), dependents[target]...)
+}
+
+// ReadThrough returns the cached value of key, or loads, caches and returns it. Concurrent
+// misses of a key share one load and TTLs are jittered by up to 10% so keys cached
+// together do not expire together. Cache failures fall back to the loader.
+func ReadThrough[T any](ctx context.Context, c Cache, group *Group, key string, ttl time.Duration, load func(ctx context.Context) (T, error)) (T, error) {
+	var value T
+	if data, ok, err := c.Get(ctx, key); err != nil {
+		log.Printf("cache: get %s: %v", key, err)
+	} else if ok && json.Unmarshal(data, &value) == nil {
+		return value, nil
+	}
+
+	result, err := group.Do(key, func() (interface{}, error) {
+		loaded, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if data, err := json.Marshal(loaded); err == nil {
+			if err := c.Set(ctx, key, data, jitter(ttl)); err != nil {
+				log.Printf("cache: set %s: %v", key, err)
+			}
+		}
+		return loaded, nil
+	})
+	if err != nil {
+		return value, err
+	}
+	return result.(T), nil
+}
+
+// jitter shortens a TTL by up to 10%
+func jitter(ttl time.Duration) time.Duration {
+	if ttl < 10 {
+		return ttl
+	}
+	return ttl - time.Duration(rand.Int63n(int64(ttl/10)))
+}
+
+// Group runs one load per key at a time, callers of a key in flight wait for its result
+type Group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+type call struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+// Do runs fn once for concurrent callers of the same key
+func (g *Group) Do(key string, fn func() (interface{}, error)) (interface{}, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		<-c.done
+		return c.value, c.err
+	}
+	c := &call{done: make(chan struct{})}
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(c.done)
+	}()
+	c.value, c.err = fn()
+	return c.value, c.err
+}
+`
+
+// CacheLRUTemplate generates the in-memory LRU backend
+const CacheLRUTemplate = `package cache
+
+import (
+	"container/list"
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// LRU is an in-memory cache evicting the least recently used entries beyond its size
+type LRU struct {
+	mu       sync.Mutex
+	size     int
+	entries  *list.List
+	items    map[string]*list.Element
+	counters map[string]int64
+}
+
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewLRU creates an in-memory cache holding up to size entries
+func NewLRU(size int) *LRU {
+	if size <= 0 {
+		size = 10000
+	}
+	return &LRU{
+		size:     size,
+		entries:  list.New(),
+		items:    make(map[string]*list.Element),
+		counters: make(map[string]int64),
+	}
+}
+
+// Get returns the value of a key, false when it is missing or expired
+func (c *LRU) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if counter, ok := c.counters[key]; ok {
+		return []byte(strconv.FormatInt(counter, 10)), true, nil
+	}
+	element, ok := c.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := element.Value.(*lruEntry)
+	if time.Now().After(entry.expires) {
+		c.remove(element)
+		return nil, false, nil
+	}
+	c.entries.MoveToFront(element)
+	return entry.value, true, nil
+}
+
+// Set stores a value, evicting the least recently used entry when the cache is full
+func (c *LRU) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := time.Now().Add(ttl)
+	if element, ok := c.items[key]; ok {
+		entry := element.Value.(*lruEntry)
+		entry.value, entry.expires = value, expires
+		c.entries.MoveToFront(element)
+		return nil
+	}
+
+	c.items[key] = c.entries.PushFront(&lruEntry{key: key, value: value, expires: expires})
+	for c.entries.Len() > c.size {
+		c.remove(c.entries.Back())
+	}
+	return nil
+}
+
+// Delete removes keys
+func (c *LRU) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		if element, ok := c.items[key]; ok {
+			c.remove(element)
+		}
+	}
+	return nil
+}
+
+// Incr increments a counter and returns its new value
+func (c *LRU) Incr(ctx context.Context, key string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counters[key]++
+	return c.counters[key], nil
+}
+
+func (c *LRU) remove(element *list.Element) {
+	c.entries.Remove(element)
+	delete(c.items, element.Value.(*lruEntry).key)
+}
+`
+
+// CacheRedisTemplate generates the Redis backend
+const CacheRedisTemplate = `package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Redis is a cache backed by Redis, shared by every instance of the service
+type Redis struct {
+	client *redis.Client
+}
+
+// NewRedis creates a cache using a Redis client
+func NewRedis(client *redis.Client) *Redis {
+	return &Redis{client: client}
+}
+
+// Get returns the value of a key, false when it is missing or expired
+func (c *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := c.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Set stores a value with a TTL
+func (c *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.client.Set(ctx, key, value, ttl).Err()
+}
+
+// Delete removes keys
+func (c *Redis) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return c.client.Del(ctx, keys...).Err()
+}
+
+// Incr increments a counter and returns its new value
+func (c *Redis) Incr(ctx context.Context, key string) (int64, error) {
+	return c.client.Incr(ctx, key).Result()
+}
+`
+
+// CacheTestTemplate generates the tests of the in-memory backend and read-through helper
+const CacheTestTemplate = `package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(2)
+	c.Set(ctx, "a", []byte("1"), time.Minute)
+	c.Set(ctx, "b", []byte("2"), time.Minute)
+	c.Get(ctx, "a")
+	c.Set(ctx, "c", []byte("3"), time.Minute)
+
+	if _, ok, _ := c.Get(ctx, "b"); ok {
+		t.Fatal("expected b to be evicted")
+	}
+	if _, ok, _ := c.Get(ctx, "a"); !ok {
+		t.Fatal("expected a to be kept")
+	}
+}
+
+func TestLRUExpires(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(10)
+	c.Set(ctx, "a", []byte("1"), -time.Second)
+	if _, ok, _ := c.Get(ctx, "a"); ok {
+		t.Fatal("expected an expired entry to be missing")
+	}
+}
+
+func TestReadThroughSharesConcurrentLoads(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(10)
+	var group Group
+	var loads int32
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := ReadThrough(ctx, c, &group, "key", time.Minute, func(ctx context.Context) (string, error) {
+				atomic.AddInt32(&loads, 1)
+				<-release
+				return "value", nil
+			})
+			if err != nil || value != "value" {
+				t.Errorf("unexpected result %q (%v)", value, err)
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if loads != 1 {
+		t.Fatalf("expected a single load, got %d", loads)
+	}
+}
+
+func TestNamespaceInvalidation(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(10)
+	DependsOn("dependent", "target")
+
+	dependent := NewNamespace(c, "dependent")
+	before, _ := dependent.Key(ctx, GroupItem, "1")
+	if err := NewNamespace(c, "target").InvalidateDependents(ctx); err != nil {
+		t.Fatal(err)
+	}
+	after, _ := dependent.Key(ctx, GroupItem, "1")
+	if before == after {
+		t.Fatal("expected the dependent keys to change")
+	}
+}
+`
+
+// SchemaCacheRepositoryTemplate generates the read-through caching decorator of a repository
+const SchemaCacheRepositoryTemplate = `package repositories
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"{{.Module}}/internal/cache"
+	"{{.Module}}/internal/models"
+	"{{.Module}}/internal/money"
+)
+
+func init() {
+{{- range .DependsOn}}
+	cache.DependsOn("{{$.Names.SnakeCase}}", "{{.}}")
+{{- end}}
+{{- range .Dependents}}
+	cache.DependsOn("{{.}}", "{{$.Names.SnakeCase}}")
+{{- end}}
+}
+
+// Cached{{.Names.PascalCase}}Repository decorates a {{.Names.PascalCase}} repository with read-through caching.
+// Writes through the decorator invalidate the cached {{.Names.Plural}} and the resources including them,
+// call Invalidate after writes that bypass it.
+type Cached{{.Names.PascalCase}}Repository struct {
+	next  {{.Names.PascalCase}}RepositoryInterface
+	cache cache.Cache
+	keys  cache.Namespace
+	group cache.Group
+
+	TTL     time.Duration
+	ListTTL time.Duration
+}
+
+// NewCached{{.Names.PascalCase}}Repository creates a caching {{.Names.PascalCase}} repository
+func NewCached{{.Names.PascalCase}}Repository(next {{.Names.PascalCase}}RepositoryInterface, c cache.Cache) *Cached{{.Names.PascalCase}}Repository {
+	return &Cached{{.Names.PascalCase}}Repository{
+		next:    next,
+		cache:   c,
+		keys:    cache.NewNamespace(c, "{{.Names.SnakeCase}}"),
+		TTL:     {{.Cache.TTL}} * time.Second,
+		ListTTL: {{.Cache.ListTTL}} * time.Second,
+	}
+}
+
+// Create creates a new {{.Names.Singular}}
+func (r *Cached{{.Names.PascalCase}}Repository) Create(ctx context.Context, {{.Names.CamelCase}} *models.{{.Names.PascalCase}}) error {
+	if err := r.next.Create(ctx, {{.Names.CamelCase}}); err != nil {
+		return err
+	}
+	r.invalidate(ctx, {{.Names.CamelCase}}.ID.Hex())
+	return nil
+}
+
+// GetByID retrieves a {{.Names.Singular}} by ID
+func (r *Cached{{.Names.PascalCase}}Repository) GetByID(ctx context.Context, id string) (*models.{{.Names.PascalCase}}, error) {
+	key, err := r.keys.Key(ctx, cache.GroupItem, id)
+	if err != nil {
+		return r.next.GetByID(ctx, id)
+	}
+	return cache.ReadThrough(ctx, r.cache, &r.group, key, r.TTL, func(ctx context.Context) (*models.{{.Names.PascalCase}}, error) {
+		return r.next.GetByID(ctx, id)
+	})
+}
+
+// cached{{.Names.PascalPlural}} is a cached page of {{.Names.Plural}}
+type cached{{.Names.PascalPlural}} struct {
+	Items []*models.{{.Names.PascalCase}} ` + "`json:\"items\"`" + `
+	Total int64 ` + "`json:\"total\"`" + `
+}
+
+// GetAll retrieves all {{.Names.Plural}} with filtering
+func (r *Cached{{.Names.PascalCase}}Repository) GetAll(ctx context.Context, filter *models.{{.Names.PascalCase}}Filter) ([]*models.{{.Names.PascalCase}}, int64, error) {
+	encoded, err := json.Marshal(filter)
+	if err != nil {
+		return r.next.GetAll(ctx, filter)
+	}
+	hash := sha256.Sum256(encoded)
+	key, err := r.keys.Key(ctx, cache.GroupQuery, "list", hex.EncodeToString(hash[:]))
+	if err != nil {
+		return r.next.GetAll(ctx, filter)
+	}
+
+	page, err := cache.ReadThrough(ctx, r.cache, &r.group, key, r.ListTTL, func(ctx context.Context) (cached{{.Names.PascalPlural}}, error) {
+		items, total, err := r.next.GetAll(ctx, filter)
+		return cached{{.Names.PascalPlural}}{Items: items, Total: total}, err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return page.Items, page.Total, nil
+}
+
+// Update updates a {{.Names.Singular}}
+func (r *Cached{{.Names.PascalCase}}Repository) Update(ctx context.Context, {{.Names.CamelCase}} *models.{{.Names.PascalCase}}) error {
+	if err := r.next.Update(ctx, {{.Names.CamelCase}}); err != nil {
+		return err
+	}
+	r.invalidate(ctx, {{.Names.CamelCase}}.ID.Hex())
+	return nil
+}
+
+// Delete deletes a {{.Names.Singular}}
+func (r *Cached{{.Names.PascalCase}}Repository) Delete(ctx context.Context, id string) error {
+	if err := r.next.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.invalidate(ctx, id)
+	return nil
+}
+
+// HardDelete permanently deletes a {{.Names.Singular}}
+func (r *Cached{{.Names.PascalCase}}Repository) HardDelete(ctx context.Context, id string) error {
+	if err := r.next.HardDelete(ctx, id); err != nil {
+		return err
+	}
+	r.invalidate(ctx, id)
+	return nil
+}
+
+// Exists checks if a {{.Names.Singular}} exists
+func (r *Cached{{.Names.PascalCase}}Repository) Exists(ctx context.Context, id string) (bool, error) {
+	return r.next.Exists(ctx, id)
+}
+{{- range .Lookups}}
+
+// GetBy{{.Field}} retrieves a {{$.Names.Singular}} by {{.Name}}
+func (r *Cached{{$.Names.PascalCase}}Repository) GetBy{{.Field}}(ctx context.Context, {{.Param}} {{.GoType}}) (*models.{{$.Names.PascalCase}}, error) {
+	key, err := r.keys.Key(ctx, cache.GroupQuery, "{{.Name}}", fmt.Sprint({{.Param}}))
+	if err != nil {
+		return r.next.GetBy{{.Field}}(ctx, {{.Param}})
+	}
+	return cache.ReadThrough(ctx, r.cache, &r.group, key, r.ListTTL, func(ctx context.Context) (*models.{{$.Names.PascalCase}}, error) {
+		return r.next.GetBy{{.Field}}(ctx, {{.Param}})
+	})
+}
+{{- end}}
+
+// Invalidate drops every cached {{.Names.Singular}}, list and lookup, and the cached reads of the
+// resources including {{.Names.Plural}}
+func (r *Cached{{.Names.PascalCase}}Repository) Invalidate(ctx context.Context) error {
+	if err := r.keys.Invalidate(ctx, cache.GroupItem, cache.GroupQuery); err != nil {
+		return err
+	}
+	return r.keys.InvalidateDependents(ctx)
+}
+
+// invalidate drops the cached {{.Names.Singular}}, the lists and lookups, and the cached reads of the
+// resources including {{.Names.Plural}}. Failures are logged, the write already succeeded.
+func (r *Cached{{.Names.PascalCase}}Repository) invalidate(ctx context.Context, id string) {
+	if key, err := r.keys.Key(ctx, cache.GroupItem, id); err != nil {
+		log.Printf("cache: invalidate {{.Names.Singular}} %s: %v", id, err)
+	} else if err := r.cache.Delete(ctx, key); err != nil {
+		log.Printf("cache: invalidate {{.Names.Singular}} %s: %v", id, err)
+	}
+	if err := r.keys.Invalidate(ctx, cache.GroupQuery); err != nil {
+		log.Printf("cache: invalidate {{.Names.Plural}}: %v", err)
+	}
+	if err := r.keys.InvalidateDependents(ctx); err != nil {
+		log.Printf("cache: invalidate resources including {{.Names.Plural}}: %v", err)
+	}
+}
+
+var _ {{.Names.PascalCase}}RepositoryInterface = (*Cached{{.Names.PascalCase}}Repository)(nil)
+`
+
+// SchemaCacheRepositoryTestTemplate generates the tests of a caching repository decorator
+const SchemaCacheRepositoryTestTemplate = `package repositories
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"{{.Module}}/internal/cache"
+	"{{.Module}}/internal/models"
+)
+
+// counting{{.Names.PascalCase}}Repository is an in-memory {{.DisplayName}} repository counting reads
+type counting{{.Names.PascalCase}}Repository struct {
+	{{.Names.PascalCase}}RepositoryInterface
+	items map[string]*models.{{.Names.PascalCase}}
+	reads int
+}
+
+func (r *counting{{.Names.PascalCase}}Repository) GetByID(ctx context.Context, id string) (*models.{{.Names.PascalCase}}, error) {
+	r.reads++
+	return r.items[id], nil
+}
+
+func (r *counting{{.Names.PascalCase}}Repository) Update(ctx context.Context, {{.Names.CamelCase}} *models.{{.Names.PascalCase}}) error {
+	r.items[{{.Names.CamelCase}}.ID.Hex()] = {{.Names.CamelCase}}
+	return nil
+}
+
+func TestCached{{.Names.PascalCase}}RepositoryReadThrough(t *testing.T) {
+	ctx := context.Background()
+	{{.Names.CamelCase}} := &models.{{.Names.PascalCase}}{ID: primitive.NewObjectID()}
+	id := {{.Names.CamelCase}}.ID.Hex()
+	next := &counting{{.Names.PascalCase}}Repository{items: map[string]*models.{{.Names.PascalCase}}{id: {{.Names.CamelCase}}}}
+	repo := NewCached{{.Names.PascalCase}}Repository(next, cache.NewLRU(100))
+
+	for i := 0; i < 2; i++ {
+		if _, err := repo.GetByID(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if next.reads != 1 {
+		t.Fatalf("expected 1 read, got %d", next.reads)
+	}
+
+	// Writes invalidate the cached {{.Names.Singular}}
+	if err := repo.Update(ctx, {{.Names.CamelCase}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetByID(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if next.reads != 2 {
+		t.Fatalf("expected a read after the update, got %d reads", next.reads)
+	}
+}
+
+func TestCached{{.Names.PascalCase}}RepositoryInvalidatedByDependencies(t *testing.T) {
+	ctx := context.Background()
+	{{.Names.CamelCase}} := &models.{{.Names.PascalCase}}{ID: primitive.NewObjectID()}
+	id := {{.Names.CamelCase}}.ID.Hex()
+	next := &counting{{.Names.PascalCase}}Repository{items: map[string]*models.{{.Names.PascalCase}}{id: {{.Names.CamelCase}}}}
+	store := cache.NewLRU(100)
+	repo := NewCached{{.Names.PascalCase}}Repository(next, store)
+	cache.DependsOn("{{.Names.SnakeCase}}", "test_dependency")
+
+	if _, err := repo.GetByID(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.NewNamespace(store, "test_dependency").InvalidateDependents(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetByID(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if next.reads != 2 {
+		t.Fatalf("expected a read after the dependency changed, got %d reads", next.reads)
+	}
+}
+`
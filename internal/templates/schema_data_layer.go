@@ -0,0 +1,920 @@
+package templates
+
+// SQLQueriesTemplate generates the named queries of a resource. The "-- name:" blocks
+// are read by sqlc and by the query loader of the sqlx and pgx repositories.
+const SQLQueriesTemplate = `-- {{.DisplayName}} queries for {{.Layer.GetDisplayName}}, regenerated with the schema
+{{- range .Queries}}
+
+-- name: {{.Name}} {{.Command}}
+{{.SQL}};
+{{- end}}
+`
+
+// RepositoryQueriesTemplate generates the loader of the embedded query files used by
+// the sqlx and pgx repositories
+const RepositoryQueriesTemplate = `package repositories
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+//go:embed queries/*.sql
+var queryFiles embed.FS
+
+// loadQueries reads the named queries of a query file. A query starts with a
+// "-- name: <Name>" comment and runs until the next one.
+func loadQueries(file string) map[string]string {
+	content, err := queryFiles.ReadFile("queries/" + file)
+	if err != nil {
+		panic(fmt.Sprintf("failed to read query file %s: %v", file, err))
+	}
+
+	queries := make(map[string]string)
+	var name string
+	var query strings.Builder
+	flush := func() {
+		if name != "" {
+			queries[name] = strings.TrimSuffix(strings.TrimSpace(query.String()), ";")
+		}
+		query.Reset()
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if rest, ok := strings.CutPrefix(trimmed, "-- name:"); ok {
+			flush()
+			name = ""
+			if fields := strings.Fields(rest); len(fields) > 0 {
+				name = fields[0]
+			}
+			continue
+		}
+		if name != "" && !strings.HasPrefix(trimmed, "--") {
+			query.WriteString(line)
+			query.WriteString("\n")
+		}
+	}
+	flush()
+
+	return queries
+}
+
+// jsonText returns a JSON value as text, or nil for an empty value stored as NULL
+func jsonText(value json.RawMessage) *string {
+	if len(value) == 0 {
+		return nil
+	}
+	text := string(value)
+	return &text
+}
+`
+
+// SQLCConfigTemplate generates the sqlc configuration shared by every resource. Column
+// types are mapped onto the model types so repositories convert rows field by field.
+const SQLCConfigTemplate = `version: "2"
+sql:
+  - engine: "postgresql"
+    schema: "migrations"
+    queries: "internal/db/queries"
+    gen:
+      go:
+        package: "db"
+        out: "internal/db"
+        sql_package: "pgx/v5"
+        emit_db_tags: true
+        emit_exact_table_names: true
+        emit_pointers_for_null_types: true
+        overrides:
+          - db_type: "pg_catalog.timestamptz"
+            go_type: "time.Time"
+          - db_type: "pg_catalog.timestamptz"
+            nullable: true
+            go_type:
+              import: "time"
+              type: "Time"
+              pointer: true
+          - db_type: "pg_catalog.timestamp"
+            go_type: "time.Time"
+          - db_type: "pg_catalog.timestamp"
+            nullable: true
+            go_type:
+              import: "time"
+              type: "Time"
+              pointer: true
+          - db_type: "date"
+            go_type: "time.Time"
+          - db_type: "date"
+            nullable: true
+            go_type:
+              import: "time"
+              type: "Time"
+              pointer: true
+          - db_type: "uuid"
+            go_type: "github.com/google/uuid.UUID"
+          - db_type: "uuid"
+            nullable: true
+            go_type:
+              import: "github.com/google/uuid"
+              type: "UUID"
+              pointer: true
+          - db_type: "pg_catalog.numeric"
+            go_type: "github.com/shopspring/decimal.Decimal"
+          - db_type: "pg_catalog.numeric"
+            nullable: true
+            go_type:
+              import: "github.com/shopspring/decimal"
+              type: "Decimal"
+              pointer: true
+          - db_type: "jsonb"
+            go_type: "encoding/json.RawMessage"
+          - db_type: "jsonb"
+            nullable: true
+            go_type: "encoding/json.RawMessage"
+          - db_type: "geography"
+            go_type:
+              import: "{{.Module}}/internal/models"
+              type: "Coordinates"
+              pointer: true
+          - db_type: "geography"
+            nullable: true
+            go_type:
+              import: "{{.Module}}/internal/models"
+              type: "Coordinates"
+              pointer: true
+`
+
+// sqlRowConversions converts the rows of the SQL data layers into models
+const sqlRowConversions = `
+// to{{.Names.PascalCase}} converts a {{.Table}} row into a model
+func to{{.Names.PascalCase}}(row {{.RowType}}) (*models.{{.Names.PascalCase}}, error) {
+	id, err := primitive.ObjectIDFromHex(row.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid {{.Names.Singular}} ID %q: %w", row.ID, err)
+	}
+
+	{{.Names.CamelCase}} := &models.{{.Names.PascalCase}}{
+		ID:        id,
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+{{- range .Columns}}
+{{- if not (or .Nullable .JSONText .Money)}}
+		{{.Field}}: row.{{.RowField}},
+{{- end}}
+{{- end}}
+	}
+{{- range .Columns}}
+{{- if .JSONText}}
+	if row.{{.RowField}} != nil {
+		{{$.Names.CamelCase}}.{{.Field}} = json.RawMessage(*row.{{.RowField}})
+	}
+{{- else if and .Nullable (not .LatLng)}}
+	if row.{{.RowField}} != nil {
+		{{$.Names.CamelCase}}.{{.Field}} = *row.{{.RowField}}
+	}
+{{- end}}
+{{- end}}
+{{- range .LatLngFields}}
+	if row.{{.Lat}} != nil && row.{{.Lng}} != nil {
+		{{$.Names.CamelCase}}.{{.Field}} = &models.Coordinates{Latitude: *row.{{.Lat}}, Longitude: *row.{{.Lng}}}
+	}
+{{- end}}
+{{- range .MoneyFields}}
+{{- if .Minor}}
+	if {{$.Names.CamelCase}}.{{.Field}}, err = money.FromMinor(row.{{.Amount}}, row.{{.Currency}}); err != nil {
+		return nil, fmt.Errorf("invalid {{.Key}} of {{$.Names.Singular}} %s: %w", row.ID, err)
+	}
+{{- else}}
+	{{$.Names.CamelCase}}.{{.Field}} = money.Money{Amount: row.{{.Amount}}, Currency: row.{{.Currency}}}
+{{- end}}
+{{- end}}
+	return {{.Names.CamelCase}}, nil
+}
+
+// to{{.Names.PascalPlural}} converts {{.Table}} rows into models
+func to{{.Names.PascalPlural}}(rows []{{.RowType}}) ([]*models.{{.Names.PascalCase}}, error) {
+	{{.Names.CamelPlural}} := make([]*models.{{.Names.PascalCase}}, 0, len(rows))
+	for _, row := range rows {
+		{{.Names.CamelCase}}, err := to{{.Names.PascalCase}}(row)
+		if err != nil {
+			return nil, err
+		}
+		{{.Names.CamelPlural}} = append({{.Names.CamelPlural}}, {{.Names.CamelCase}})
+	}
+	return {{.Names.CamelPlural}}, nil
+}
+`
+
+// sqlRowStruct declares the row struct of the sqlx and pgx repositories
+const sqlRowStruct = `
+// {{.RowType}} is a row of the {{.Table}} table
+type {{.RowType}} struct {
+	ID        string    ` + "`" + `db:"id"` + "`" + `
+	CreatedAt time.Time ` + "`" + `db:"created_at"` + "`" + `
+	UpdatedAt time.Time ` + "`" + `db:"updated_at"` + "`" + `
+{{- range .Columns}}
+	{{.RowField}} {{.RowFieldType}} ` + "`" + `db:"{{.Name}}"` + "`" + `
+{{- end}}
+}
+
+// new{{.Names.PascalCase}}Row converts a model into a {{.Table}} row
+func new{{.Names.PascalCase}}Row({{.Names.CamelCase}} *models.{{.Names.PascalCase}}) {{.RowType}} {
+{{- if .LatLngFields}}
+	row := ` + sqlRowLiteral + `
+{{- range .LatLngFields}}
+	if {{$.Names.CamelCase}}.{{.Field}} != nil {
+		row.{{.Lat}}, row.{{.Lng}} = &{{$.Names.CamelCase}}.{{.Field}}.Latitude, &{{$.Names.CamelCase}}.{{.Field}}.Longitude
+	}
+{{- end}}
+	return row
+{{- else}}
+	return ` + sqlRowLiteral + `
+{{- end}}
+}
+`
+
+// sqlRowLiteral is the row literal of a model, without the latitude and longitude
+// columns of coordinates fields
+const sqlRowLiteral = `{{.RowType}}{
+		ID:        {{.Names.CamelCase}}.ID.Hex(),
+		CreatedAt: {{.Names.CamelCase}}.CreatedAt,
+		UpdatedAt: {{.Names.CamelCase}}.UpdatedAt,
+{{- range .Columns}}
+{{- if .JSONText}}
+		{{.RowField}}: jsonText({{$.Names.CamelCase}}.{{.Field}}),
+{{- else if not .LatLng}}
+		{{.RowField}}: {{.ModelValue $.Names.CamelCase}},
+{{- end}}
+{{- end}}
+	}`
+
+// sqlListFilters builds the conditions, sorting and paging of a filtered list. The
+// generated code declares the where function adding a condition and its argument.
+const sqlListFilters = `
+{{- if .SearchColumns}}
+	if filter.Search != "" {
+		where("{{.SearchCondition}}", {{.SearchArg}})
+	}
+{{- end}}
+{{- range .Columns}}
+{{- if .Filter}}
+	if filter.{{.Field}} != nil {
+		where("{{$.FilterCondition .}}", {{$.FilterArg .}})
+	}
+{{- end}}
+{{- end}}
+{{- with .Geo}}
+
+	// Geographic conditions are built from the parsed numbers, not from the request text
+	geoQuery, err := filter.GeoQuery()
+	if err != nil {
+		return nil, 0, err
+	}
+{{- if .Geography}}
+	conditions = append(conditions, geo.PostGISConditions("{{.Column}}", geoQuery)...)
+{{- else}}
+	conditions = append(conditions, geo.LatLngConditions("{{.Column}}_lat", "{{.Column}}_lng", geoQuery)...)
+{{- end}}
+{{- end}}
+
+	clause := ""
+	if len(conditions) > 0 {
+		clause = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	orderBy := "created_at DESC"
+	if column, ok := {{.Names.CamelCase}}SortColumns[filter.Sort]; ok {
+		orderBy = column + " DESC"
+		if strings.ToUpper(filter.Order) == "ASC" {
+			orderBy = column + " ASC"
+		}
+	}
+{{- if and .Geo .Geo.Geography}}
+	if geoQuery != nil && geoQuery.Near != nil {
+		orderBy = geo.PostGISDistance("{{.Geo.Column}}", *geoQuery.Near) + " ASC"
+	}
+{{- end}}
+	page := " ORDER BY " + orderBy
+	if filter.Page > 0 && filter.PageSize > 0 {
+		page += fmt.Sprintf(" LIMIT %d OFFSET %d", filter.PageSize, (filter.Page-1)*filter.PageSize)
+	}
+`
+
+// sqlSetDistances sets the distances of listed models to the position of a near filter
+const sqlSetDistances = `
+{{- if .Geo}}
+	for _, {{.Names.CamelCase}} := range {{.Names.CamelPlural}} {
+		{{.Names.CamelCase}}.SetDistance(geoQuery)
+	}
+{{- end}}
+`
+
+// sqlSortColumns declares the columns a list can be sorted by
+const sqlSortColumns = `
+// {{.Names.CamelCase}}SortColumns maps the sortable fields onto their columns
+var {{.Names.CamelCase}}SortColumns = map[string]string{
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+{{- range .Columns}}
+{{- if and (ne .GoType "json.RawMessage") (not .Geo) (ne .Money "currency")}}
+	"{{.Key}}": "{{.Name}}",
+{{- end}}
+{{- end}}
+}
+`
+
+// sqlRepositoryInterface declares the repository interface shared with the GORM and MongoDB repositories
+const sqlRepositoryInterface = `
+// Repository interface for dependency injection
+type {{.Names.PascalCase}}RepositoryInterface interface {
+	Create(ctx context.Context, {{.Names.CamelCase}} *models.{{.Names.PascalCase}}) error
+	GetByID(ctx context.Context, id string) (*models.{{.Names.PascalCase}}, error)
+	GetAll(ctx context.Context, filter *models.{{.Names.PascalCase}}Filter) ([]*models.{{.Names.PascalCase}}, int64, error)
+	Update(ctx context.Context, {{.Names.CamelCase}} *models.{{.Names.PascalCase}}) error
+	Delete(ctx context.Context, id string) error
+	HardDelete(ctx context.Context, id string) error
+	Exists(ctx context.Context, id string) (bool, error)
+{{- range .UniqueColumns}}
+	GetBy{{.Field}}(ctx context.Context, {{.Param}} {{.GoType}}) (*models.{{$.Names.PascalCase}}, error)
+{{- end}}
+}
+`
+
+// SQLXTransactionTemplate generates the transactor of sqlx repositories. The transaction
+// travels in the context so repositories called inside it share it.
+const SQLXTransactionTemplate = `package repositories
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// txKey is the context key of the current transaction
+type txKey struct{}
+
+// Transactor runs functions in a database transaction shared by the repositories
+type Transactor struct {
+	db *sqlx.DB
+}
+
+// NewTransactor creates a new transactor
+func NewTransactor(db *sqlx.DB) *Transactor {
+	return &Transactor{db: db}
+}
+
+// WithinTransaction runs fn in a transaction, committed when fn succeeds and rolled back
+// otherwise. Repositories called with the context passed to fn take part in the
+// transaction, nested calls join the outer transaction.
+func (t *Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := t.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// connFromContext returns the transaction of the context, or the database outside transactions
+func connFromContext(ctx context.Context, db *sqlx.DB) sqlx.ExtContext {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return tx
+	}
+	return db
+}
+`
+
+// PGXTransactionTemplate generates the transactor of pgx and sqlc repositories. The
+// transaction travels in the context so repositories called inside it share it.
+const PGXTransactionTemplate = `package repositories
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// txKey is the context key of the current transaction
+type txKey struct{}
+
+// pgxConn is implemented by the pool and by transactions
+type pgxConn interface {
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// Transactor runs functions in a database transaction shared by the repositories
+type Transactor struct {
+	pool *pgxpool.Pool
+}
+
+// NewTransactor creates a new transactor
+func NewTransactor(pool *pgxpool.Pool) *Transactor {
+	return &Transactor{pool: pool}
+}
+
+// WithinTransaction runs fn in a transaction, committed when fn succeeds and rolled back
+// otherwise. Repositories called with the context passed to fn take part in the
+// transaction, nested calls join the outer transaction.
+func (t *Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := t.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// connFromContext returns the transaction of the context, or the pool outside transactions
+func connFromContext(ctx context.Context, pool *pgxpool.Pool) pgxConn {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return pool
+}
+`
+
+// SchemaSQLXRepositoryTemplate generates a sqlx repository running the embedded queries
+const SchemaSQLXRepositoryTemplate = `package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"{{.Module}}/internal/apperrors"
+	"{{.Module}}/internal/geo"
+	"{{.Module}}/internal/models"
+	"{{.Module}}/internal/money"
+)
+
+// {{.Names.CamelCase}}Queries holds the queries of queries/{{.Names.SnakeCase}}.sql
+var {{.Names.CamelCase}}Queries = loadQueries("{{.Names.SnakeCase}}.sql")
+
+// {{.Names.PascalCase}}Repository handles database operations for {{.DisplayName}} with sqlx
+type {{.Names.PascalCase}}Repository struct {
+	db *sqlx.DB
+}
+
+// New{{.Names.PascalCase}}Repository creates a new {{.Names.PascalCase}} repository
+func New{{.Names.PascalCase}}Repository(db *sqlx.DB) *{{.Names.PascalCase}}Repository {
+	return &{{.Names.PascalCase}}Repository{db: db}
+}
+
+// Create creates a new {{.Names.Singular}}
+func (r *{{.Names.PascalCase}}Repository) Create(ctx context.Context, {{.Names.CamelCase}} *models.{{.Names.PascalCase}}) error {
+	if {{.Names.CamelCase}}.ID.IsZero() {
+		{{.Names.CamelCase}}.ID = primitive.NewObjectID()
+	}
+	{{.Names.CamelCase}}.CreatedAt = time.Now()
+	{{.Names.CamelCase}}.UpdatedAt = {{.Names.CamelCase}}.CreatedAt
+
+	_, err := sqlx.NamedExecContext(ctx, r.conn(ctx), {{.Names.CamelCase}}Queries["Create{{.Names.PascalCase}}"], new{{.Names.PascalCase}}Row({{.Names.CamelCase}}))
+	return err
+}
+
+// GetByID retrieves a {{.Names.Singular}} by ID
+func (r *{{.Names.PascalCase}}Repository) GetByID(ctx context.Context, id string) (*models.{{.Names.PascalCase}}, error) {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return nil, fmt.Errorf("invalid ID format: %w", err)
+	}
+	return r.getOne(ctx, "Get{{.Names.PascalCase}}", id)
+}
+
+// GetAll retrieves all {{.Names.Plural}} with filtering
+func (r *{{.Names.PascalCase}}Repository) GetAll(ctx context.Context, filter *models.{{.Names.PascalCase}}Filter) ([]*models.{{.Names.PascalCase}}, int64, error) {
+	var conditions []string
+	var args []interface{}
+{{- if .HasFilters}}
+	where := func(condition string, arg interface{}) {
+		conditions = append(conditions, condition)
+		for i := strings.Count(condition, "?"); i > 0; i-- {
+			args = append(args, arg)
+		}
+	}
+{{- end}}
+` + sqlListFilters + `
+{{- if and .Geo (not .Geo.Geography)}}
+	if geoQuery != nil && geoQuery.Near != nil {
+		return r.nearest(ctx, clause, args, geoQuery, filter)
+	}
+{{- end}}
+
+	var total int64
+	if err := sqlx.GetContext(ctx, r.conn(ctx), &total, r.db.Rebind({{.Names.CamelCase}}Queries["Count{{.Names.PascalPlural}}"]+clause), args...); err != nil {
+		return nil, 0, err
+	}
+
+	var rows []{{.RowType}}
+	if err := sqlx.SelectContext(ctx, r.conn(ctx), &rows, r.db.Rebind({{.Names.CamelCase}}Queries["List{{.Names.PascalPlural}}"]+clause+page), args...); err != nil {
+		return nil, 0, err
+	}
+
+	{{.Names.CamelPlural}}, err := to{{.Names.PascalPlural}}(rows)
+	if err != nil {
+		return nil, 0, err
+	}` + sqlSetDistances + `	return {{.Names.CamelPlural}}, total, nil
+}
+
+// Update updates a {{.Names.Singular}}
+func (r *{{.Names.PascalCase}}Repository) Update(ctx context.Context, {{.Names.CamelCase}} *models.{{.Names.PascalCase}}) error {
+	{{.Names.CamelCase}}.UpdatedAt = time.Now()
+
+	_, err := sqlx.NamedExecContext(ctx, r.conn(ctx), {{.Names.CamelCase}}Queries["Update{{.Names.PascalCase}}"], new{{.Names.PascalCase}}Row({{.Names.CamelCase}}))
+	return err
+}
+
+// Delete deletes a {{.Names.Singular}}
+func (r *{{.Names.PascalCase}}Repository) Delete(ctx context.Context, id string) error {
+	_, err := r.conn(ctx).ExecContext(ctx, r.db.Rebind({{.Names.CamelCase}}Queries["Delete{{.Names.PascalCase}}"]), id)
+	return err
+}
+
+// HardDelete permanently deletes a {{.Names.Singular}} (same as Delete without a soft delete column)
+func (r *{{.Names.PascalCase}}Repository) HardDelete(ctx context.Context, id string) error {
+	return r.Delete(ctx, id)
+}
+
+// Exists checks if a {{.Names.Singular}} exists
+func (r *{{.Names.PascalCase}}Repository) Exists(ctx context.Context, id string) (bool, error) {
+	var count int64
+	if err := sqlx.GetContext(ctx, r.conn(ctx), &count, r.db.Rebind({{.Names.CamelCase}}Queries["{{.Names.PascalCase}}Exists"]), id); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+{{- range .UniqueColumns}}
+
+// GetBy{{.Field}} retrieves a {{$.Names.Singular}} by {{.Name}}
+func (r *{{$.Names.PascalCase}}Repository) GetBy{{.Field}}(ctx context.Context, {{.Param}} {{.GoType}}) (*models.{{$.Names.PascalCase}}, error) {
+	return r.getOne(ctx, "Get{{$.Names.PascalCase}}By{{.Field}}", {{.Param}})
+}
+{{- end}}
+
+{{- if and .Geo (not .Geo.Geography)}}
+
+// nearest retrieves the page of {{.Names.Plural}} within the radius of a near filter, nearest
+// first. The conditions match the bounding box of the circle, distances are checked here.
+func (r *{{.Names.PascalCase}}Repository) nearest(ctx context.Context, clause string, args []interface{}, query *geo.Query, filter *models.{{.Names.PascalCase}}Filter) ([]*models.{{.Names.PascalCase}}, int64, error) {
+	var rows []{{.RowType}}
+	if err := sqlx.SelectContext(ctx, r.conn(ctx), &rows, r.db.Rebind({{.Names.CamelCase}}Queries["List{{.Names.PascalPlural}}"]+clause), args...); err != nil {
+		return nil, 0, err
+	}
+
+	{{.Names.CamelPlural}}, err := to{{.Names.PascalPlural}}(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, {{.Names.CamelCase}} := range {{.Names.CamelPlural}} {
+		{{.Names.CamelCase}}.SetDistance(query)
+	}
+
+	distance := func({{.Names.CamelCase}} *models.{{.Names.PascalCase}}) *float64 { return {{.Names.CamelCase}}.Distance }
+	page, total := geo.Nearest({{.Names.CamelPlural}}, distance, query.Radius, filter.Page, filter.PageSize)
+	return page, total, nil
+}
+{{- end}}
+
+// conn returns the transaction of the context, or the database outside transactions
+func (r *{{.Names.PascalCase}}Repository) conn(ctx context.Context) sqlx.ExtContext {
+	return connFromContext(ctx, r.db)
+}
+
+// getOne runs a named query returning a single {{.Names.Singular}}
+func (r *{{.Names.PascalCase}}Repository) getOne(ctx context.Context, query string, args ...interface{}) (*models.{{.Names.PascalCase}}, error) {
+	var row {{.RowType}}
+	if err := sqlx.GetContext(ctx, r.conn(ctx), &row, r.db.Rebind({{.Names.CamelCase}}Queries[query]), args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperrors.NotFound("{{.Names.Singular}} not found")
+		}
+		return nil, err
+	}
+	return to{{.Names.PascalCase}}(row)
+}
+` + sqlSortColumns + sqlRowStruct + sqlRowConversions + sqlRepositoryInterface
+
+// SchemaPGXRepositoryTemplate generates a pgx repository running the embedded queries
+const SchemaPGXRepositoryTemplate = `package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"{{.Module}}/internal/apperrors"
+	"{{.Module}}/internal/geo"
+	"{{.Module}}/internal/models"
+	"{{.Module}}/internal/money"
+)
+
+// {{.Names.CamelCase}}Queries holds the queries of queries/{{.Names.SnakeCase}}.sql
+var {{.Names.CamelCase}}Queries = loadQueries("{{.Names.SnakeCase}}.sql")
+
+// {{.Names.PascalCase}}Repository handles database operations for {{.DisplayName}} with pgx
+type {{.Names.PascalCase}}Repository struct {
+	db *pgxpool.Pool
+}
+
+// New{{.Names.PascalCase}}Repository creates a new {{.Names.PascalCase}} repository
+func New{{.Names.PascalCase}}Repository(db *pgxpool.Pool) *{{.Names.PascalCase}}Repository {
+	return &{{.Names.PascalCase}}Repository{db: db}
+}
+
+// Create creates a new {{.Names.Singular}}
+func (r *{{.Names.PascalCase}}Repository) Create(ctx context.Context, {{.Names.CamelCase}} *models.{{.Names.PascalCase}}) error {
+	if {{.Names.CamelCase}}.ID.IsZero() {
+		{{.Names.CamelCase}}.ID = primitive.NewObjectID()
+	}
+	{{.Names.CamelCase}}.CreatedAt = time.Now()
+	{{.Names.CamelCase}}.UpdatedAt = {{.Names.CamelCase}}.CreatedAt
+
+	row := new{{.Names.PascalCase}}Row({{.Names.CamelCase}})
+	_, err := r.conn(ctx).Exec(ctx, {{.Names.CamelCase}}Queries["Create{{.Names.PascalCase}}"],
+		row.ID, row.CreatedAt, row.UpdatedAt{{range .Columns}}, row.{{.RowField}}{{end}})
+	return err
+}
+
+// GetByID retrieves a {{.Names.Singular}} by ID
+func (r *{{.Names.PascalCase}}Repository) GetByID(ctx context.Context, id string) (*models.{{.Names.PascalCase}}, error) {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return nil, fmt.Errorf("invalid ID format: %w", err)
+	}
+	return r.getOne(ctx, "Get{{.Names.PascalCase}}", id)
+}
+
+// GetAll retrieves all {{.Names.Plural}} with filtering
+func (r *{{.Names.PascalCase}}Repository) GetAll(ctx context.Context, filter *models.{{.Names.PascalCase}}Filter) ([]*models.{{.Names.PascalCase}}, int64, error) {
+	var conditions []string
+	var args []interface{}
+{{- if .HasFilters}}
+	where := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, strings.ReplaceAll(condition, "?", fmt.Sprintf("$%d", len(args))))
+	}
+{{- end}}
+` + sqlListFilters + `
+	var total int64
+	if err := r.conn(ctx).QueryRow(ctx, {{.Names.CamelCase}}Queries["Count{{.Names.PascalPlural}}"]+clause, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	result, err := r.conn(ctx).Query(ctx, {{.Names.CamelCase}}Queries["List{{.Names.PascalPlural}}"]+clause+page, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	rows, err := pgx.CollectRows(result, pgx.RowToStructByName[{{.RowType}}])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	{{.Names.CamelPlural}}, err := to{{.Names.PascalPlural}}(rows)
+	if err != nil {
+		return nil, 0, err
+	}` + sqlSetDistances + `	return {{.Names.CamelPlural}}, total, nil
+}
+
+// Update updates a {{.Names.Singular}}
+func (r *{{.Names.PascalCase}}Repository) Update(ctx context.Context, {{.Names.CamelCase}} *models.{{.Names.PascalCase}}) error {
+	{{.Names.CamelCase}}.UpdatedAt = time.Now()
+
+	row := new{{.Names.PascalCase}}Row({{.Names.CamelCase}})
+	_, err := r.conn(ctx).Exec(ctx, {{.Names.CamelCase}}Queries["Update{{.Names.PascalCase}}"],
+		row.ID, row.UpdatedAt{{range .Columns}}, row.{{.RowField}}{{end}})
+	return err
+}
+
+// Delete deletes a {{.Names.Singular}}
+func (r *{{.Names.PascalCase}}Repository) Delete(ctx context.Context, id string) error {
+	_, err := r.conn(ctx).Exec(ctx, {{.Names.CamelCase}}Queries["Delete{{.Names.PascalCase}}"], id)
+	return err
+}
+
+// HardDelete permanently deletes a {{.Names.Singular}} (same as Delete without a soft delete column)
+func (r *{{.Names.PascalCase}}Repository) HardDelete(ctx context.Context, id string) error {
+	return r.Delete(ctx, id)
+}
+
+// Exists checks if a {{.Names.Singular}} exists
+func (r *{{.Names.PascalCase}}Repository) Exists(ctx context.Context, id string) (bool, error) {
+	var exists bool
+	err := r.conn(ctx).QueryRow(ctx, {{.Names.CamelCase}}Queries["{{.Names.PascalCase}}Exists"], id).Scan(&exists)
+	return exists, err
+}
+{{- range .UniqueColumns}}
+
+// GetBy{{.Field}} retrieves a {{$.Names.Singular}} by {{.Name}}
+func (r *{{$.Names.PascalCase}}Repository) GetBy{{.Field}}(ctx context.Context, {{.Param}} {{.GoType}}) (*models.{{$.Names.PascalCase}}, error) {
+	return r.getOne(ctx, "Get{{$.Names.PascalCase}}By{{.Field}}", {{.Param}})
+}
+{{- end}}
+
+// conn returns the transaction of the context, or the pool outside transactions
+func (r *{{.Names.PascalCase}}Repository) conn(ctx context.Context) pgxConn {
+	return connFromContext(ctx, r.db)
+}
+
+// getOne runs a named query returning a single {{.Names.Singular}}
+func (r *{{.Names.PascalCase}}Repository) getOne(ctx context.Context, query string, args ...interface{}) (*models.{{.Names.PascalCase}}, error) {
+	result, err := r.conn(ctx).Query(ctx, {{.Names.CamelCase}}Queries[query], args...)
+	if err != nil {
+		return nil, err
+	}
+	row, err := pgx.CollectOneRow(result, pgx.RowToStructByName[{{.RowType}}])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperrors.NotFound("{{.Names.Singular}} not found")
+		}
+		return nil, err
+	}
+	return to{{.Names.PascalCase}}(row)
+}
+` + sqlSortColumns + sqlRowStruct + sqlRowConversions + sqlRepositoryInterface
+
+// SchemaSQLCRepositoryTemplate generates a repository wrapping the sqlc generated queries.
+// Filtered lists are dynamic and run on the pool with the columns sqlc scans into.
+const SchemaSQLCRepositoryTemplate = `package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"{{.Module}}/internal/apperrors"
+	"{{.Module}}/internal/db"
+	"{{.Module}}/internal/geo"
+	"{{.Module}}/internal/models"
+	"{{.Module}}/internal/money"
+)
+
+// {{.Names.CamelCase}}ListQuery and {{.Names.CamelCase}}CountQuery are the base statements of filtered lists
+const (
+	{{.Names.CamelCase}}ListQuery  = "SELECT {{.SelectColumns}} FROM {{.Table}}"
+	{{.Names.CamelCase}}CountQuery = "SELECT COUNT(*) FROM {{.Table}}"
+)
+
+// {{.Names.PascalCase}}Repository handles database operations for {{.DisplayName}} with the sqlc queries
+type {{.Names.PascalCase}}Repository struct {
+	pool *pgxpool.Pool
+}
+
+// New{{.Names.PascalCase}}Repository creates a new {{.Names.PascalCase}} repository
+func New{{.Names.PascalCase}}Repository(pool *pgxpool.Pool) *{{.Names.PascalCase}}Repository {
+	return &{{.Names.PascalCase}}Repository{pool: pool}
+}
+
+// Create creates a new {{.Names.Singular}}
+func (r *{{.Names.PascalCase}}Repository) Create(ctx context.Context, {{.Names.CamelCase}} *models.{{.Names.PascalCase}}) error {
+	if {{.Names.CamelCase}}.ID.IsZero() {
+		{{.Names.CamelCase}}.ID = primitive.NewObjectID()
+	}
+	{{.Names.CamelCase}}.CreatedAt = time.Now()
+	{{.Names.CamelCase}}.UpdatedAt = {{.Names.CamelCase}}.CreatedAt
+
+	return r.queries(ctx).Create{{.Names.PascalCase}}(ctx, db.Create{{.Names.PascalCase}}Params{
+		ID:        {{.Names.CamelCase}}.ID.Hex(),
+		CreatedAt: {{.Names.CamelCase}}.CreatedAt,
+		UpdatedAt: {{.Names.CamelCase}}.UpdatedAt,
+{{- range .Columns}}
+		{{.RowField}}: {{.ModelValue $.Names.CamelCase}},
+{{- end}}
+	})
+}
+
+// GetByID retrieves a {{.Names.Singular}} by ID
+func (r *{{.Names.PascalCase}}Repository) GetByID(ctx context.Context, id string) (*models.{{.Names.PascalCase}}, error) {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return nil, fmt.Errorf("invalid ID format: %w", err)
+	}
+	return r.one(r.queries(ctx).Get{{.Names.PascalCase}}(ctx, id))
+}
+
+// GetAll retrieves all {{.Names.Plural}} with filtering
+func (r *{{.Names.PascalCase}}Repository) GetAll(ctx context.Context, filter *models.{{.Names.PascalCase}}Filter) ([]*models.{{.Names.PascalCase}}, int64, error) {
+	var conditions []string
+	var args []interface{}
+{{- if .HasFilters}}
+	where := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, strings.ReplaceAll(condition, "?", fmt.Sprintf("$%d", len(args))))
+	}
+{{- end}}
+` + sqlListFilters + `
+	var total int64
+	if err := connFromContext(ctx, r.pool).QueryRow(ctx, {{.Names.CamelCase}}CountQuery+clause, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	result, err := connFromContext(ctx, r.pool).Query(ctx, {{.Names.CamelCase}}ListQuery+clause+page, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	rows, err := pgx.CollectRows(result, pgx.RowToStructByName[{{.RowType}}])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	{{.Names.CamelPlural}}, err := to{{.Names.PascalPlural}}(rows)
+	if err != nil {
+		return nil, 0, err
+	}` + sqlSetDistances + `	return {{.Names.CamelPlural}}, total, nil
+}
+
+// Update updates a {{.Names.Singular}}
+func (r *{{.Names.PascalCase}}Repository) Update(ctx context.Context, {{.Names.CamelCase}} *models.{{.Names.PascalCase}}) error {
+	{{.Names.CamelCase}}.UpdatedAt = time.Now()
+
+	return r.queries(ctx).Update{{.Names.PascalCase}}(ctx, db.Update{{.Names.PascalCase}}Params{
+		ID:        {{.Names.CamelCase}}.ID.Hex(),
+		UpdatedAt: {{.Names.CamelCase}}.UpdatedAt,
+{{- range .Columns}}
+		{{.RowField}}: {{.ModelValue $.Names.CamelCase}},
+{{- end}}
+	})
+}
+
+// Delete deletes a {{.Names.Singular}}
+func (r *{{.Names.PascalCase}}Repository) Delete(ctx context.Context, id string) error {
+	return r.queries(ctx).Delete{{.Names.PascalCase}}(ctx, id)
+}
+
+// HardDelete permanently deletes a {{.Names.Singular}} (same as Delete without a soft delete column)
+func (r *{{.Names.PascalCase}}Repository) HardDelete(ctx context.Context, id string) error {
+	return r.Delete(ctx, id)
+}
+
+// Exists checks if a {{.Names.Singular}} exists
+func (r *{{.Names.PascalCase}}Repository) Exists(ctx context.Context, id string) (bool, error) {
+	return r.queries(ctx).{{.Names.PascalCase}}Exists(ctx, id)
+}
+{{- range .UniqueColumns}}
+
+// GetBy{{.Field}} retrieves a {{$.Names.Singular}} by {{.Name}}
+func (r *{{$.Names.PascalCase}}Repository) GetBy{{.Field}}(ctx context.Context, {{.Param}} {{.GoType}}) (*models.{{$.Names.PascalCase}}, error) {
+	return r.one(r.queries(ctx).Get{{$.Names.PascalCase}}By{{.Field}}(ctx, {{if .Nullable}}&{{end}}{{.Param}}))
+}
+{{- end}}
+
+// queries returns the sqlc queries bound to the transaction of the context, or the pool
+func (r *{{.Names.PascalCase}}Repository) queries(ctx context.Context) *db.Queries {
+	return db.New(connFromContext(ctx, r.pool))
+}
+
+// one converts the result of a query returning a single {{.Names.Singular}}
+func (r *{{.Names.PascalCase}}Repository) one(row {{.RowType}}, err error) (*models.{{.Names.PascalCase}}, error) {
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperrors.NotFound("{{.Names.Singular}} not found")
+		}
+		return nil, err
+	}
+	return to{{.Names.PascalCase}}(row)
+}
+` + sqlSortColumns + sqlRowConversions + sqlRepositoryInterface
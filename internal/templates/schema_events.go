@@ -0,0 +1,993 @@
+package templates
+
+// EventsPackageTemplate generates the shared domain event types of the events feature
+const EventsPackageTemplate = `package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Event is a change of a resource, written to the outbox with the change and
+// delivered to consumers by the relay
+type Event struct {
+	ID          string          ` + "`json:\"id\"`" + `
+	Type        string          ` + "`json:\"type\"`" + `
+	Aggregate   string          ` + "`json:\"aggregate\"`" + `
+	AggregateID string          ` + "`json:\"aggregate_id\"`" + `
+	OccurredAt  time.Time       ` + "`json:\"occurred_at\"`" + `
+	Payload     json.RawMessage ` + "`json:\"payload\"`" + `
+}
+
+// New creates an event with a JSON encoded payload
+func New(eventType, aggregate, aggregateID string, payload interface{}) (Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, fmt.Errorf("failed to encode %s payload: %w", eventType, err)
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return Event{}, fmt.Errorf("failed to generate event ID: %w", err)
+	}
+
+	return Event{
+		ID:          hex.EncodeToString(id),
+		Type:        eventType,
+		Aggregate:   aggregate,
+		AggregateID: aggregateID,
+		OccurredAt:  time.Now().UTC(),
+		Payload:     data,
+	}, nil
+}
+
+// Publisher delivers events to consumers
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// TransactionRunner runs functions in a database transaction carried by the context
+type TransactionRunner interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Outbox stores events in the transaction of the context
+type Outbox interface {
+	Add(ctx context.Context, events ...Event) error
+}
+
+// OutboxStore is the outbox read by the relay
+type OutboxStore interface {
+	Outbox
+	// Pending returns the unpublished events, oldest first
+	Pending(ctx context.Context, limit int) ([]Event, error)
+	MarkPublished(ctx context.Context, id string) error
+	MarkFailed(ctx context.Context, id string, cause error) error
+}
+`
+
+// EventsRelayTemplate generates the background relay publishing the outbox
+const EventsRelayTemplate = `package events
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Relay publishes the events of the outbox. Delivery is at least once, an event may be
+// published again when the relay stops before marking it, consumers deduplicate by event ID.
+// Run a single relay per database to keep events in order.
+type Relay struct {
+	store     OutboxStore
+	publisher Publisher
+
+	Interval  time.Duration
+	BatchSize int
+}
+
+// NewRelay creates a relay polling the outbox every second
+func NewRelay(store OutboxStore, publisher Publisher) *Relay {
+	return &Relay{
+		store:     store,
+		publisher: publisher,
+		Interval:  time.Second,
+		BatchSize: 100,
+	}
+}
+
+// Run publishes pending events until the context is cancelled
+func (r *Relay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.Flush(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("events: relay: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Flush publishes a batch of pending events and returns how many were published. It stops
+// at the first failure so later events are not delivered before the failed one.
+func (r *Relay) Flush(ctx context.Context) (int, error) {
+	pending, err := r.store.Pending(ctx, r.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the outbox: %w", err)
+	}
+
+	for i, event := range pending {
+		if err := r.publisher.Publish(ctx, event); err != nil {
+			if markErr := r.store.MarkFailed(ctx, event.ID, err); markErr != nil {
+				log.Printf("events: relay: failed to record failure of event %s: %v", event.ID, markErr)
+			}
+			return i, fmt.Errorf("failed to publish event %s: %w", event.ID, err)
+		}
+		if err := r.store.MarkPublished(ctx, event.ID); err != nil {
+			return i, fmt.Errorf("failed to mark event %s published: %w", event.ID, err)
+		}
+	}
+	return len(pending), nil
+}
+`
+
+// EventsMemoryStoreTemplate generates the in-memory outbox used by tests and single process setups
+const EventsMemoryStoreTemplate = `package events
+
+import (
+	"context"
+	"sync"
+)
+
+// memoryTxKey is the context key of a MemoryOutbox transaction
+type memoryTxKey struct{}
+
+// memoryTx buffers the events added in a transaction until it commits
+type memoryTx struct {
+	events []Event
+}
+
+// MemoryOutbox is an in-memory outbox and transactor. Events added in a transaction are
+// kept only when the transaction succeeds.
+type MemoryOutbox struct {
+	mu        sync.Mutex
+	events    []Event
+	published map[string]bool
+	attempts  map[string]int
+}
+
+// NewMemoryOutbox creates an empty in-memory outbox
+func NewMemoryOutbox() *MemoryOutbox {
+	return &MemoryOutbox{
+		published: make(map[string]bool),
+		attempts:  make(map[string]int),
+	}
+}
+
+// WithinTransaction runs fn and keeps the events it adds when it succeeds
+func (s *MemoryOutbox) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(memoryTxKey{}).(*memoryTx); ok {
+		return fn(ctx)
+	}
+
+	tx := &memoryTx{}
+	if err := fn(context.WithValue(ctx, memoryTxKey{}, tx)); err != nil {
+		return err
+	}
+	return s.Add(ctx, tx.events...)
+}
+
+// Add stores events, or buffers them in the transaction of the context
+func (s *MemoryOutbox) Add(ctx context.Context, events ...Event) error {
+	if tx, ok := ctx.Value(memoryTxKey{}).(*memoryTx); ok {
+		tx.events = append(tx.events, events...)
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, events...)
+	return nil
+}
+
+// Pending returns the unpublished events, oldest first
+func (s *MemoryOutbox) Pending(ctx context.Context, limit int) ([]Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var pending []Event
+	for _, event := range s.events {
+		if len(pending) == limit {
+			break
+		}
+		if !s.published[event.ID] {
+			pending = append(pending, event)
+		}
+	}
+	return pending, nil
+}
+
+// MarkPublished marks an event as delivered
+func (s *MemoryOutbox) MarkPublished(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.published[id] = true
+	return nil
+}
+
+// MarkFailed records a failed delivery attempt
+func (s *MemoryOutbox) MarkFailed(ctx context.Context, id string, cause error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts[id]++
+	return nil
+}
+
+// Attempts returns the number of failed delivery attempts of an event
+func (s *MemoryOutbox) Attempts(id string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.attempts[id]
+}
+`
+
+// EventsChannelPublisherTemplate generates the in-process publisher
+const EventsChannelPublisherTemplate = `package events
+
+import "context"
+
+// ChannelPublisher delivers events in process through a buffered channel
+type ChannelPublisher struct {
+	events chan Event
+}
+
+// NewChannelPublisher creates a publisher buffering up to buffer events
+func NewChannelPublisher(buffer int) *ChannelPublisher {
+	return &ChannelPublisher{events: make(chan Event, buffer)}
+}
+
+// Publish sends the event to the channel, waiting for room until the context is done
+func (p *ChannelPublisher) Publish(ctx context.Context, event Event) error {
+	select {
+	case p.events <- event:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Events returns the channel consumers receive events from
+func (p *ChannelPublisher) Events() <-chan Event {
+	return p.events
+}
+`
+
+// EventsWebhookPublisherTemplate generates the HTTP webhook publisher
+const EventsWebhookPublisherTemplate = `package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// WebhookPublisher posts events as JSON to an HTTP endpoint. With a secret the body is
+// signed with HMAC-SHA256 in the X-Event-Signature header.
+type WebhookPublisher struct {
+	URL    string
+	Secret string
+	Client *http.Client
+}
+
+// NewWebhookPublisher creates a webhook publisher with a 10 second timeout
+func NewWebhookPublisher(url, secret string) *WebhookPublisher {
+	return &WebhookPublisher{
+		URL:    url,
+		Secret: secret,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Publish posts the event, any status outside 2xx is a failed delivery
+func (p *WebhookPublisher) Publish(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", event.ID)
+	req.Header.Set("X-Event-Type", event.Type)
+	if p.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(p.Secret))
+		mac.Write(body)
+		req.Header.Set("X-Event-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+`
+
+// EventsNATSPublisherTemplate generates the NATS publisher
+const EventsNATSPublisherTemplate = `package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSPublisher publishes events on <prefix>.<aggregate>.<type> subjects. The event ID is
+// sent as Nats-Msg-Id so JetStream streams drop redelivered events.
+type NATSPublisher struct {
+	conn   *nats.Conn
+	prefix string
+}
+
+// NewNATSPublisher creates a NATS publisher, prefix defaults to "events"
+func NewNATSPublisher(conn *nats.Conn, prefix string) *NATSPublisher {
+	if prefix == "" {
+		prefix = "events"
+	}
+	return &NATSPublisher{conn: conn, prefix: prefix}
+}
+
+// Publish sends the event and waits for the server to receive it
+func (p *NATSPublisher) Publish(ctx context.Context, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(p.prefix + "." + event.Aggregate + "." + event.Type)
+	msg.Data = data
+	msg.Header.Set(nats.MsgIdHdr, event.ID)
+	if err := p.conn.PublishMsg(msg); err != nil {
+		return err
+	}
+
+	// FlushWithContext requires a deadline
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	return p.conn.FlushWithContext(ctx)
+}
+`
+
+// EventsKafkaPublisherTemplate generates the Kafka publisher
+const EventsKafkaPublisherTemplate = `package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaPublisher writes events to a topic keyed by aggregate ID, so the events of a
+// resource stay ordered within a partition
+type KafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaPublisher creates a Kafka publisher waiting for all in-sync replicas
+func NewKafkaPublisher(brokers []string, topic string) *KafkaPublisher {
+	return &KafkaPublisher{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Topic:        topic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+			BatchTimeout: 10 * time.Millisecond,
+		},
+	}
+}
+
+// Publish writes the event to the topic
+func (p *KafkaPublisher) Publish(ctx context.Context, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return p.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(event.AggregateID),
+		Value: data,
+		Headers: []kafka.Header{
+			{Key: "event-id", Value: []byte(event.ID)},
+			{Key: "event-type", Value: []byte(event.Type)},
+		},
+	})
+}
+
+// Close flushes pending writes and closes the writer
+func (p *KafkaPublisher) Close() error {
+	return p.writer.Close()
+}
+`
+
+// EventsRelayTestTemplate generates the relay tests using the in-process publisher
+const EventsRelayTestTemplate = `package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// failingPublisher fails every delivery
+type failingPublisher struct{}
+
+func (failingPublisher) Publish(ctx context.Context, event Event) error {
+	return errors.New("broker unavailable")
+}
+
+func TestRelayPublishesInOrder(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryOutbox()
+	publisher := NewChannelPublisher(10)
+
+	for _, eventType := range []string{"First", "Second"} {
+		event, err := New(eventType, "test", "1", map[string]string{"type": eventType})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Add(ctx, event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	relay := NewRelay(store, publisher)
+	published, err := relay.Flush(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if published != 2 {
+		t.Fatalf("expected 2 published events, got %d", published)
+	}
+	for _, expected := range []string{"First", "Second"} {
+		if event := <-publisher.Events(); event.Type != expected {
+			t.Fatalf("expected %s, got %s", expected, event.Type)
+		}
+	}
+
+	// Published events are not delivered again
+	if published, err := relay.Flush(ctx); err != nil || published != 0 {
+		t.Fatalf("expected no pending events, got %d (%v)", published, err)
+	}
+}
+
+func TestRelayKeepsFailedEvents(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryOutbox()
+	event, err := New("Failed", "test", "1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Add(ctx, event); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewRelay(store, failingPublisher{}).Flush(ctx); err == nil {
+		t.Fatal("expected a publish error")
+	}
+	if attempts := store.Attempts(event.ID); attempts != 1 {
+		t.Fatalf("expected 1 failed attempt, got %d", attempts)
+	}
+
+	// The event is delivered once the publisher recovers
+	publisher := NewChannelPublisher(1)
+	if published, err := NewRelay(store, publisher).Flush(ctx); err != nil || published != 1 {
+		t.Fatalf("expected the failed event to be published, got %d (%v)", published, err)
+	}
+	if delivered := <-publisher.Events(); delivered.ID != event.ID {
+		t.Fatalf("expected event %s, got %s", event.ID, delivered.ID)
+	}
+}
+
+func TestMemoryOutboxDiscardsRolledBackEvents(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryOutbox()
+
+	err := store.WithinTransaction(ctx, func(ctx context.Context) error {
+		event, err := New("Discarded", "test", "1", nil)
+		if err != nil {
+			return err
+		}
+		if err := store.Add(ctx, event); err != nil {
+			return err
+		}
+		return errors.New("rollback")
+	})
+	if err == nil {
+		t.Fatal("expected the transaction error")
+	}
+
+	pending, err := store.Pending(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("expected no events after rollback, got %d", len(pending))
+	}
+}
+`
+
+// SchemaEventsTemplate generates the event types and payload of a resource
+const SchemaEventsTemplate = `package events
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"{{.Module}}/internal/models"
+	"{{.Module}}/internal/money"
+)
+
+// {{.DisplayName}} event types
+const (
+	{{.Names.PascalCase}}Created = "{{.Names.PascalCase}}Created"
+	{{.Names.PascalCase}}Updated = "{{.Names.PascalCase}}Updated"
+	{{.Names.PascalCase}}Deleted = "{{.Names.PascalCase}}Deleted"
+)
+
+// {{.Names.PascalCase}}Payload is the payload of {{.DisplayName}} events, described by schemas/{{.Names.SnakeCase}}.json
+type {{.Names.PascalCase}}Payload struct {
+	ID        string    ` + "`json:\"id\"`" + `
+	CreatedAt time.Time ` + "`json:\"created_at\"`" + `
+	UpdatedAt time.Time ` + "`json:\"updated_at\"`" + `
+{{- range .PayloadFields}}
+	{{.GoName}} {{.GoType}} ` + "`json:\"{{.JSONName}}\"`" + `
+{{- end}}
+}
+
+// New{{.Names.PascalCase}}Event creates a {{.DisplayName}} event carrying the state of the {{.Names.Singular}}
+func New{{.Names.PascalCase}}Event(eventType string, {{.Names.CamelCase}} *models.{{.Names.PascalCase}}) (Event, error) {
+	payload := {{.Names.PascalCase}}Payload{
+		ID:        {{.Names.CamelCase}}.ID.Hex(),
+		CreatedAt: {{.Names.CamelCase}}.CreatedAt,
+		UpdatedAt: {{.Names.CamelCase}}.UpdatedAt,
+{{- range .PayloadFields}}
+		{{.GoName}}: {{$.Names.CamelCase}}.{{.GoName}},
+{{- end}}
+	}
+	return New(eventType, "{{.Names.SnakeCase}}", payload.ID, payload)
+}
+
+// Decode{{.Names.PascalCase}}Payload decodes the payload of a {{.DisplayName}} event
+func Decode{{.Names.PascalCase}}Payload(event Event) (*{{.Names.PascalCase}}Payload, error) {
+	var payload {{.Names.PascalCase}}Payload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return nil, err
+	}
+	return &payload, nil
+}
+`
+
+// SchemaEventsServiceTestTemplate generates service tests recording events through the
+// in-memory outbox and delivering them with the in-process publisher
+const SchemaEventsServiceTestTemplate = `package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"{{.Module}}/internal/events"
+	"{{.Module}}/internal/models"
+	"{{.Module}}/internal/repositories"
+)
+
+// {{.Names.CamelCase}}EventsRepository is an in-memory {{.DisplayName}} repository
+type {{.Names.CamelCase}}EventsRepository struct {
+	repositories.{{.Names.PascalCase}}RepositoryInterface
+	items     map[string]*models.{{.Names.PascalCase}}
+	deleteErr error
+}
+
+func (r *{{.Names.CamelCase}}EventsRepository) GetByID(ctx context.Context, id string) (*models.{{.Names.PascalCase}}, error) {
+	item, ok := r.items[id]
+	if !ok {
+		return nil, errors.New("{{.Names.Singular}} not found")
+	}
+	return item, nil
+}
+
+func (r *{{.Names.CamelCase}}EventsRepository) Exists(ctx context.Context, id string) (bool, error) {
+	_, ok := r.items[id]
+	return ok, nil
+}
+
+func (r *{{.Names.CamelCase}}EventsRepository) Delete(ctx context.Context, id string) error {
+	if r.deleteErr != nil {
+		return r.deleteErr
+	}
+	delete(r.items, id)
+	return nil
+}
+
+func new{{.Names.PascalCase}}EventsService(repo *{{.Names.CamelCase}}EventsRepository) (*{{.Names.PascalCase}}Service, *events.MemoryOutbox) {
+	store := events.NewMemoryOutbox()
+	return New{{.Names.PascalCase}}Service(repo).WithEvents(store, store), store
+}
+
+func Test{{.Names.PascalCase}}ServiceDeleteRecordsEvent(t *testing.T) {
+	ctx := context.Background()
+	{{.Names.CamelCase}} := &models.{{.Names.PascalCase}}{ID: primitive.NewObjectID()}
+	repo := &{{.Names.CamelCase}}EventsRepository{items: map[string]*models.{{.Names.PascalCase}}{ {{.Names.CamelCase}}.ID.Hex(): {{.Names.CamelCase}} }}
+	service, store := new{{.Names.PascalCase}}EventsService(repo)
+
+	if err := service.Delete(ctx, {{.Names.CamelCase}}.ID.Hex()); err != nil {
+		t.Fatal(err)
+	}
+
+	publisher := events.NewChannelPublisher(1)
+	if _, err := events.NewRelay(store, publisher).Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	event := <-publisher.Events()
+	if event.Type != events.{{.Names.PascalCase}}Deleted {
+		t.Fatalf("expected %s, got %s", events.{{.Names.PascalCase}}Deleted, event.Type)
+	}
+	payload, err := events.Decode{{.Names.PascalCase}}Payload(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payload.ID != {{.Names.CamelCase}}.ID.Hex() || event.AggregateID != payload.ID {
+		t.Fatalf("expected the payload of %s, got %s", {{.Names.CamelCase}}.ID.Hex(), payload.ID)
+	}
+}
+
+func Test{{.Names.PascalCase}}ServiceFailedDeleteRecordsNoEvent(t *testing.T) {
+	ctx := context.Background()
+	{{.Names.CamelCase}} := &models.{{.Names.PascalCase}}{ID: primitive.NewObjectID()}
+	repo := &{{.Names.CamelCase}}EventsRepository{
+		items:     map[string]*models.{{.Names.PascalCase}}{ {{.Names.CamelCase}}.ID.Hex(): {{.Names.CamelCase}} },
+		deleteErr: errors.New("database unavailable"),
+	}
+	service, store := new{{.Names.PascalCase}}EventsService(repo)
+
+	if err := service.Delete(ctx, {{.Names.CamelCase}}.ID.Hex()); err == nil {
+		t.Fatal("expected the delete error")
+	}
+
+	publisher := events.NewChannelPublisher(1)
+	published, err := events.NewRelay(store, publisher).Flush(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if published != 0 {
+		t.Fatalf("expected no events for a failed delete, got %d", published)
+	}
+}
+`
+
+// MongoTransactionTemplate generates the transactor of MongoDB repositories. Operations
+// run with the session context returned by the transactor take part in the transaction.
+const MongoTransactionTemplate = `package repositories
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Transactor runs functions in a MongoDB transaction shared by the repositories.
+// Transactions require a replica set or sharded cluster.
+type Transactor struct {
+	client *mongo.Client
+}
+
+// NewTransactor creates a new transactor
+func NewTransactor(db *mongo.Database) *Transactor {
+	return &Transactor{client: db.Client()}
+}
+
+// WithinTransaction runs fn in a transaction, committed when fn succeeds and aborted
+// otherwise. fn may be retried on transient transaction errors, nested calls join the
+// outer transaction.
+func (t *Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if mongo.SessionFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	return t.client.UseSession(ctx, func(sc mongo.SessionContext) error {
+		_, err := sc.WithTransaction(sc, func(sc mongo.SessionContext) (interface{}, error) {
+			return nil, fn(sc)
+		})
+		return err
+	})
+}
+`
+
+// MongoOutboxRepositoryTemplate generates the MongoDB outbox of the events feature
+const MongoOutboxRepositoryTemplate = `package repositories
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"{{.Module}}/internal/events"
+)
+
+// outboxDocument is an event stored in the outbox collection
+type outboxDocument struct {
+	ID          string     ` + "`bson:\"_id\"`" + `
+	Type        string     ` + "`bson:\"event_type\"`" + `
+	Aggregate   string     ` + "`bson:\"aggregate\"`" + `
+	AggregateID string     ` + "`bson:\"aggregate_id\"`" + `
+	Payload     string     ` + "`bson:\"payload\"`" + `
+	OccurredAt  time.Time  ` + "`bson:\"occurred_at\"`" + `
+	PublishedAt *time.Time ` + "`bson:\"published_at\"`" + `
+	Attempts    int        ` + "`bson:\"attempts\"`" + `
+	LastError   string     ` + "`bson:\"last_error,omitempty\"`" + `
+}
+
+// OutboxRepository stores events in the outbox_events collection, inside the transaction
+// of the context
+type OutboxRepository struct {
+	collection *mongo.Collection
+}
+
+// NewOutboxRepository creates a new outbox repository
+func NewOutboxRepository(db *mongo.Database) *OutboxRepository {
+	return &OutboxRepository{collection: db.Collection("outbox_events")}
+}
+
+// Add stores events
+func (r *OutboxRepository) Add(ctx context.Context, evts ...events.Event) error {
+	if len(evts) == 0 {
+		return nil
+	}
+
+	documents := make([]interface{}, len(evts))
+	for i, event := range evts {
+		documents[i] = outboxDocument{
+			ID:          event.ID,
+			Type:        event.Type,
+			Aggregate:   event.Aggregate,
+			AggregateID: event.AggregateID,
+			Payload:     string(event.Payload),
+			OccurredAt:  event.OccurredAt,
+		}
+	}
+	_, err := r.collection.InsertMany(ctx, documents)
+	return err
+}
+
+// Pending returns the unpublished events, oldest first
+func (r *OutboxRepository) Pending(ctx context.Context, limit int) ([]events.Event, error) {
+	opts := options.Find().
+		SetSort(bson.D{ {Key: "occurred_at", Value: 1}, {Key: "_id", Value: 1} }).
+		SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, bson.M{"published_at": nil}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var documents []outboxDocument
+	if err := cursor.All(ctx, &documents); err != nil {
+		return nil, err
+	}
+
+	pending := make([]events.Event, len(documents))
+	for i, document := range documents {
+		pending[i] = events.Event{
+			ID:          document.ID,
+			Type:        document.Type,
+			Aggregate:   document.Aggregate,
+			AggregateID: document.AggregateID,
+			OccurredAt:  document.OccurredAt,
+			Payload:     []byte(document.Payload),
+		}
+	}
+	return pending, nil
+}
+
+// MarkPublished marks an event as delivered
+func (r *OutboxRepository) MarkPublished(ctx context.Context, id string) error {
+	_, err := r.collection.UpdateByID(ctx, id, bson.M{"$set": bson.M{"published_at": time.Now()}})
+	return err
+}
+
+// MarkFailed records a failed delivery attempt
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id string, cause error) error {
+	_, err := r.collection.UpdateByID(ctx, id, bson.M{
+		"$inc": bson.M{"attempts": 1},
+		"$set": bson.M{"last_error": cause.Error()},
+	})
+	return err
+}
+`
+
+// outboxRow is the row struct shared by the SQL outbox repositories
+const outboxRow = `
+// outboxRow is an event stored in the outbox_events table
+type outboxRow struct {
+	ID          string    ` + "`db:\"id\"`" + `
+	Type        string    ` + "`db:\"event_type\"`" + `
+	Aggregate   string    ` + "`db:\"aggregate\"`" + `
+	AggregateID string    ` + "`db:\"aggregate_id\"`" + `
+	Payload     string    ` + "`db:\"payload\"`" + `
+	OccurredAt  time.Time ` + "`db:\"occurred_at\"`" + `
+}
+
+// toEvents converts outbox rows to events
+func toEvents(rows []outboxRow) []events.Event {
+	pending := make([]events.Event, len(rows))
+	for i, row := range rows {
+		pending[i] = events.Event{
+			ID:          row.ID,
+			Type:        row.Type,
+			Aggregate:   row.Aggregate,
+			AggregateID: row.AggregateID,
+			OccurredAt:  row.OccurredAt,
+			Payload:     []byte(row.Payload),
+		}
+	}
+	return pending
+}
+`
+
+// SQLXOutboxRepositoryTemplate generates the sqlx outbox of the events feature
+const SQLXOutboxRepositoryTemplate = `package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"{{.Module}}/internal/events"
+)
+
+// OutboxRepository stores events in the outbox_events table, inside the transaction of the context
+type OutboxRepository struct {
+	db *sqlx.DB
+}
+
+// NewOutboxRepository creates a new outbox repository
+func NewOutboxRepository(db *sqlx.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// Add stores events
+func (r *OutboxRepository) Add(ctx context.Context, evts ...events.Event) error {
+	query := r.db.Rebind("INSERT INTO outbox_events (id, event_type, aggregate, aggregate_id, payload, occurred_at) VALUES (?, ?, ?, ?, ?, ?)")
+	for _, event := range evts {
+		if _, err := connFromContext(ctx, r.db).ExecContext(ctx, query,
+			event.ID, event.Type, event.Aggregate, event.AggregateID, string(event.Payload), event.OccurredAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Pending returns the unpublished events, oldest first
+func (r *OutboxRepository) Pending(ctx context.Context, limit int) ([]events.Event, error) {
+	var rows []outboxRow
+	query := r.db.Rebind("SELECT id, event_type, aggregate, aggregate_id, payload, occurred_at FROM outbox_events WHERE published_at IS NULL ORDER BY occurred_at, id LIMIT ?")
+	if err := sqlx.SelectContext(ctx, connFromContext(ctx, r.db), &rows, query, limit); err != nil {
+		return nil, err
+	}
+	return toEvents(rows), nil
+}
+
+// MarkPublished marks an event as delivered
+func (r *OutboxRepository) MarkPublished(ctx context.Context, id string) error {
+	query := r.db.Rebind("UPDATE outbox_events SET published_at = ? WHERE id = ?")
+	_, err := connFromContext(ctx, r.db).ExecContext(ctx, query, time.Now(), id)
+	return err
+}
+
+// MarkFailed records a failed delivery attempt
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id string, cause error) error {
+	query := r.db.Rebind("UPDATE outbox_events SET attempts = attempts + 1, last_error = ? WHERE id = ?")
+	_, err := connFromContext(ctx, r.db).ExecContext(ctx, query, cause.Error(), id)
+	return err
+}
+` + outboxRow
+
+// PGXOutboxRepositoryTemplate generates the pgx outbox of the events feature, used by the
+// pgx and sqlc data layers
+const PGXOutboxRepositoryTemplate = `package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"{{.Module}}/internal/events"
+)
+
+// OutboxRepository stores events in the outbox_events table, inside the transaction of the context
+type OutboxRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewOutboxRepository creates a new outbox repository
+func NewOutboxRepository(pool *pgxpool.Pool) *OutboxRepository {
+	return &OutboxRepository{pool: pool}
+}
+
+// Add stores events
+func (r *OutboxRepository) Add(ctx context.Context, evts ...events.Event) error {
+	for _, event := range evts {
+		if _, err := connFromContext(ctx, r.pool).Exec(ctx,
+			"INSERT INTO outbox_events (id, event_type, aggregate, aggregate_id, payload, occurred_at) VALUES ($1, $2, $3, $4, $5, $6)",
+			event.ID, event.Type, event.Aggregate, event.AggregateID, string(event.Payload), event.OccurredAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Pending returns the unpublished events, oldest first
+func (r *OutboxRepository) Pending(ctx context.Context, limit int) ([]events.Event, error) {
+	result, err := connFromContext(ctx, r.pool).Query(ctx,
+		"SELECT id, event_type, aggregate, aggregate_id, payload::text AS payload, occurred_at FROM outbox_events WHERE published_at IS NULL ORDER BY occurred_at, id LIMIT $1", limit)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := pgx.CollectRows(result, pgx.RowToStructByName[outboxRow])
+	if err != nil {
+		return nil, err
+	}
+	return toEvents(rows), nil
+}
+
+// MarkPublished marks an event as delivered
+func (r *OutboxRepository) MarkPublished(ctx context.Context, id string) error {
+	_, err := connFromContext(ctx, r.pool).Exec(ctx, "UPDATE outbox_events SET published_at = $1 WHERE id = $2", time.Now(), id)
+	return err
+}
+
+// MarkFailed records a failed delivery attempt
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id string, cause error) error {
+	_, err := connFromContext(ctx, r.pool).Exec(ctx, "UPDATE outbox_events SET attempts = attempts + 1, last_error = $1 WHERE id = $2", cause.Error(), id)
+	return err
+}
+` + outboxRow
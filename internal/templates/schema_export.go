@@ -0,0 +1,656 @@
+package templates
+
+// ExportPackageTemplate generates the shared format negotiation and streaming writers used by export endpoints
+const ExportPackageTemplate = `package export
+
+import (
+	"bufio"
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrUnsupportedFormat is returned when no acceptable export format was requested
+	ErrUnsupportedFormat = errors.New("unsupported export format, use text/csv, application/x-ndjson or application/json")
+	// ErrUnknownField is returned when ?fields= names a field that cannot be exported
+	ErrUnknownField = errors.New("unknown export field")
+)
+
+// Format is an export output format
+type Format string
+
+// Supported export formats
+const (
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+	FormatJSON   Format = "json"
+)
+
+// ContentType returns the media type written for the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	default:
+		return "application/json; charset=utf-8"
+	}
+}
+
+// Extension returns the file extension used in the download file name
+func (f Format) Extension() string {
+	return string(f)
+}
+
+// Negotiate picks the export format from an explicit ?format= value or the Accept header.
+// Media ranges are honoured by their q-value; a missing Accept header selects JSON.
+func Negotiate(format, accept string) (Format, error) {
+	if format != "" {
+		switch Format(strings.ToLower(format)) {
+		case FormatCSV, FormatNDJSON, FormatJSON:
+			return Format(strings.ToLower(format)), nil
+		}
+		return "", ErrUnsupportedFormat
+	}
+
+	if strings.TrimSpace(accept) == "" {
+		return FormatJSON, nil
+	}
+
+	var best Format
+	bestQ := 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+
+		var candidate Format
+		switch mediaType {
+		case "text/csv":
+			candidate = FormatCSV
+		case "application/x-ndjson", "application/ndjson":
+			candidate = FormatNDJSON
+		case "application/json", "application/*", "*/*":
+			candidate = FormatJSON
+		default:
+			continue
+		}
+
+		if q > bestQ {
+			best, bestQ = candidate, q
+		}
+	}
+
+	if best == "" {
+		return "", ErrUnsupportedFormat
+	}
+	return best, nil
+}
+
+// SelectFields resolves a comma separated ?fields= value against the exportable fields.
+// An empty value selects the defaults, or every available field when there are none.
+func SelectFields(param string, available, defaults []string) ([]string, error) {
+	if strings.TrimSpace(param) == "" {
+		if len(defaults) > 0 {
+			return defaults, nil
+		}
+		return available, nil
+	}
+
+	allowed := make(map[string]bool, len(available))
+	for _, field := range available {
+		allowed[field] = true
+	}
+
+	seen := make(map[string]bool)
+	var fields []string
+	for _, field := range strings.Split(param, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" || seen[field] {
+			continue
+		}
+		if !allowed[field] {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownField, field)
+		}
+		seen[field] = true
+		fields = append(fields, field)
+	}
+
+	if len(fields) == 0 {
+		return available, nil
+	}
+	return fields, nil
+}
+
+// Writer streams export rows in a specific format
+type Writer interface {
+	WriteHeader(fields []string) error
+	WriteRow(values []interface{}) error
+	Close() error
+}
+
+// NewWriter creates a streaming writer for the given format
+func NewWriter(format Format, w io.Writer) Writer {
+	switch format {
+	case FormatCSV:
+		return &csvWriter{w: csv.NewWriter(w)}
+	case FormatNDJSON:
+		return &jsonWriter{w: bufio.NewWriter(w), lines: true}
+	default:
+		return &jsonWriter{w: bufio.NewWriter(w)}
+	}
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (cw *csvWriter) WriteHeader(fields []string) error {
+	return cw.w.Write(fields)
+}
+
+func (cw *csvWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		record[i] = FormatCSVValue(value)
+	}
+	return cw.w.Write(record)
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// jsonWriter writes a JSON array, or one object per line when lines is set
+type jsonWriter struct {
+	w       *bufio.Writer
+	lines   bool
+	fields  [][]byte
+	started bool
+	rows    int
+}
+
+func (jw *jsonWriter) WriteHeader(fields []string) error {
+	jw.fields = make([][]byte, len(fields))
+	for i, field := range fields {
+		key, err := json.Marshal(field)
+		if err != nil {
+			return err
+		}
+		jw.fields[i] = key
+	}
+
+	jw.started = true
+	if !jw.lines {
+		return jw.w.WriteByte('[')
+	}
+	return nil
+}
+
+// WriteRow writes the object by hand so keys keep the requested column order
+func (jw *jsonWriter) WriteRow(values []interface{}) error {
+	if jw.rows > 0 && !jw.lines {
+		jw.w.WriteByte(',')
+	}
+	jw.rows++
+
+	jw.w.WriteByte('{')
+	for i, value := range values {
+		if i > 0 {
+			jw.w.WriteByte(',')
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		jw.w.Write(jw.fields[i])
+		jw.w.WriteByte(':')
+		jw.w.Write(encoded)
+	}
+	jw.w.WriteByte('}')
+
+	if jw.lines {
+		return jw.w.WriteByte('\n')
+	}
+	return nil
+}
+
+func (jw *jsonWriter) Close() error {
+	if jw.started && !jw.lines {
+		jw.w.WriteByte(']')
+	}
+	return jw.w.Flush()
+}
+
+// FormatCSVValue renders a value as a CSV cell, escaping text that spreadsheets would evaluate
+func FormatCSVValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return EscapeCSVCell(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format(time.RFC3339Nano)
+	case json.RawMessage:
+		return EscapeCSVCell(string(v))
+	case []byte:
+		return EscapeCSVCell(string(v))
+	case encoding.TextMarshaler:
+		text, err := v.MarshalText()
+		if err != nil {
+			return ""
+		}
+		return EscapeCSVCell(string(text))
+	default:
+		return EscapeCSVCell(fmt.Sprint(v))
+	}
+}
+
+// EscapeCSVCell prefixes cells starting with a formula trigger so spreadsheet
+// applications treat them as text (CSV injection)
+func EscapeCSVCell(cell string) string {
+	if cell == "" {
+		return cell
+	}
+	switch cell[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + cell
+	}
+	return cell
+}
+`
+
+// ExportPackageTestTemplate generates tests of the format negotiation, field selection and CSV escaping
+const ExportPackageTestTemplate = `package export
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestEscapeCSVCell(t *testing.T) {
+	tests := map[string]string{
+		"=SUM(A1:A2)":   "'=SUM(A1:A2)",
+		"+1":            "'+1",
+		"-1":            "'-1",
+		"@import":       "'@import",
+		"\tindented":    "'\tindented",
+		"\rreturn":      "'\rreturn",
+		"":              "",
+		"plain":         "plain",
+		"a=b":           "a=b",
+		"2024-01-02":    "2024-01-02",
+		" =not-leading": " =not-leading",
+	}
+	for cell, want := range tests {
+		if got := EscapeCSVCell(cell); got != want {
+			t.Errorf("EscapeCSVCell(%q) = %q, want %q", cell, got, want)
+		}
+	}
+
+	if got := FormatCSVValue("=1+1"); got != "'=1+1" {
+		t.Errorf("expected string values to be escaped, got %q", got)
+	}
+	if got := FormatCSVValue(-5); got != "-5" {
+		t.Errorf("expected numbers to be left alone, got %q", got)
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		format string
+		accept string
+		want   Format
+	}{
+		{"", "", FormatJSON},
+		{"CSV", "application/json", FormatCSV},
+		{"ndjson", "", FormatNDJSON},
+		{"", "text/csv", FormatCSV},
+		{"", "application/ndjson", FormatNDJSON},
+		{"", "application/json;q=0.5, text/csv;q=0.9", FormatCSV},
+		{"", "text/csv;q=0.2, application/x-ndjson", FormatNDJSON},
+		{"", "text/html, */*;q=0.1", FormatJSON},
+		{"", "application/*;q=0.8, text/csv;q=0.5", FormatJSON},
+		// Malformed q-values skip the media range
+		{"", "text/csv;q=high, application/json;q=0.1", FormatJSON},
+	}
+	for _, tt := range tests {
+		got, err := Negotiate(tt.format, tt.accept)
+		if err != nil {
+			t.Errorf("Negotiate(%q, %q): %v", tt.format, tt.accept, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Negotiate(%q, %q) = %s, want %s", tt.format, tt.accept, got, tt.want)
+		}
+	}
+}
+
+func TestNegotiate_Unsupported(t *testing.T) {
+	tests := []struct {
+		format string
+		accept string
+	}{
+		{"xml", ""},
+		{"", "text/html, application/xml"},
+		// q=0 refuses the media range
+		{"", "text/csv;q=0"},
+	}
+	for _, tt := range tests {
+		if _, err := Negotiate(tt.format, tt.accept); !errors.Is(err, ErrUnsupportedFormat) {
+			t.Errorf("Negotiate(%q, %q): expected ErrUnsupportedFormat, got %v", tt.format, tt.accept, err)
+		}
+	}
+}
+
+func TestSelectFields(t *testing.T) {
+	available := []string{"id", "name", "price"}
+	tests := []struct {
+		param    string
+		defaults []string
+		want     []string
+	}{
+		{"", []string{"id", "name"}, []string{"id", "name"}},
+		{"", nil, available},
+		{" price , id,price", nil, []string{"price", "id"}},
+		{",", nil, available},
+	}
+	for _, tt := range tests {
+		got, err := SelectFields(tt.param, available, tt.defaults)
+		if err != nil {
+			t.Errorf("SelectFields(%q): %v", tt.param, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SelectFields(%q) = %v, want %v", tt.param, got, tt.want)
+		}
+	}
+
+	if _, err := SelectFields("id,secret", available, nil); !errors.Is(err, ErrUnknownField) {
+		t.Errorf("expected ErrUnknownField, got %v", err)
+	}
+}
+`
+
+// SchemaExportRepositoryTemplate generates the streaming export query for a resource
+const SchemaExportRepositoryTemplate = `package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"{{.Module}}/internal/models"
+)
+
+// {{.Names.PascalCase}}ExportRepository streams {{.DisplayName}} rows for export
+type {{.Names.PascalCase}}ExportRepository struct {
+	db *gorm.DB
+}
+
+// New{{.Names.PascalCase}}ExportRepository creates a new {{.Names.PascalCase}} export repository
+func New{{.Names.PascalCase}}ExportRepository(db *gorm.DB) *{{.Names.PascalCase}}ExportRepository {
+	return &{{.Names.PascalCase}}ExportRepository{db: db}
+}
+
+// Stream calls fn for every {{.Names.Singular}} matching the filter, one row at a time.
+// Pagination is ignored; only the given columns are selected.
+func (r *{{.Names.PascalCase}}ExportRepository) Stream(ctx context.Context, filter *models.{{.Names.PascalCase}}Filter, columns []string, order string, fn func(*models.{{.Names.PascalCase}}) error) error {
+	query := r.db.WithContext(ctx).Model(&models.{{.Names.PascalCase}}{}).Select(columns)
+{{- range .Fields}}
+{{- if and .Filterable .GoFilterQuery}}
+	{{.GoFilterQuery}}
+{{- end}}
+{{- end}}
+{{- if .HasSearch}}
+	if filter.Search != "" {
+		searchQuery := "%" + filter.Search + "%"
+		query = query.Where("{{.GetSearchFields}}", {{.GetSearchValues}})
+	}
+{{- end}}
+
+	rows, err := query.Order(order).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.{{.Names.PascalCase}}
+		if err := r.db.ScanRows(rows, &item); err != nil {
+			return err
+		}
+		if err := fn(&item); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+`
+
+// SchemaExportServiceTemplate generates the export service for a resource
+const SchemaExportServiceTemplate = `package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"{{.Module}}/internal/export"
+	"{{.Module}}/internal/models"
+	"{{.Module}}/internal/repositories"
+)
+
+// {{.Names.CamelCase}}ExportFields lists the exportable fields in column order. Sensitive fields are never included.
+var {{.Names.CamelCase}}ExportFields = []string{
+	"id",
+{{- range .ExportFields}}
+	"{{.JSONName}}",
+{{- end}}
+	"created_at",
+	"updated_at",
+}
+
+// {{.Names.CamelCase}}ExportDefaultFields are exported when ?fields= is omitted
+var {{.Names.CamelCase}}ExportDefaultFields = []string{
+{{- range .DefaultFields}}
+	"{{.}}",
+{{- end}}
+}
+
+// {{.Names.CamelCase}}ExportColumns maps exportable fields to database columns
+var {{.Names.CamelCase}}ExportColumns = map[string]string{
+	"id":         "id",
+{{- range .ExportFields}}
+	"{{.JSONName}}": "{{.Column}}",
+{{- end}}
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+// {{.Names.PascalCase}}ExportService streams {{.DisplayName}} exports
+type {{.Names.PascalCase}}ExportService struct {
+	repo *repositories.{{.Names.PascalCase}}ExportRepository
+}
+
+// New{{.Names.PascalCase}}ExportService creates a new {{.Names.PascalCase}} export service
+func New{{.Names.PascalCase}}ExportService(repo *repositories.{{.Names.PascalCase}}ExportRepository) *{{.Names.PascalCase}}ExportService {
+	return &{{.Names.PascalCase}}ExportService{repo: repo}
+}
+
+// Fields resolves a ?fields= value to the exported field names
+func (s *{{.Names.PascalCase}}ExportService) Fields(param string) ([]string, error) {
+	return export.SelectFields(param, {{.Names.CamelCase}}ExportFields, {{.Names.CamelCase}}ExportDefaultFields)
+}
+
+// Export writes every {{.Names.Singular}} matching the filter to w and returns the number of rows written
+func (s *{{.Names.PascalCase}}ExportService) Export(ctx context.Context, filter *models.{{.Names.PascalCase}}Filter, fields []string, w export.Writer) (int, error) {
+	columns := make([]string, len(fields))
+	for i, field := range fields {
+		column, ok := {{.Names.CamelCase}}ExportColumns[field]
+		if !ok {
+			return 0, fmt.Errorf("%w: %s", export.ErrUnknownField, field)
+		}
+		columns[i] = column
+	}
+
+	if err := w.WriteHeader(fields); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	values := make([]interface{}, len(fields))
+	err := s.repo.Stream(ctx, filter, columns, {{.Names.CamelCase}}ExportOrder(filter), func(item *models.{{.Names.PascalCase}}) error {
+		for i, field := range fields {
+			values[i] = {{.Names.CamelCase}}ExportValue(item, field)
+		}
+		count++
+		return w.WriteRow(values)
+	})
+	if err != nil {
+		return count, fmt.Errorf("failed to export {{.Names.Plural}}: %w", err)
+	}
+
+	return count, w.Close()
+}
+
+// {{.Names.CamelCase}}ExportOrder builds a stable ORDER BY clause from the list sort options
+func {{.Names.CamelCase}}ExportOrder(filter *models.{{.Names.PascalCase}}Filter) string {
+	column, ok := {{.Names.CamelCase}}ExportColumns[filter.Sort]
+	if !ok {
+		return "id ASC"
+	}
+	if strings.EqualFold(filter.Order, "desc") {
+		return column + " DESC, id DESC"
+	}
+	return column + " ASC, id ASC"
+}
+
+// {{.Names.CamelCase}}ExportValue returns the value of an exportable field
+func {{.Names.CamelCase}}ExportValue(item *models.{{.Names.PascalCase}}, field string) interface{} {
+	switch field {
+	case "id":
+		return item.ID
+{{- range .ExportFields}}
+	case "{{.JSONName}}":
+		return item.{{.GoName}}
+{{- end}}
+	case "created_at":
+		return item.CreatedAt
+	case "updated_at":
+		return item.UpdatedAt
+	}
+	return nil
+}
+`
+
+// SchemaExportHandlerTemplate generates the export HTTP handler for a resource
+const SchemaExportHandlerTemplate = `package handlers
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"net/http"
+
+{{range .HTTP.Imports}}	"{{.}}"
+{{end}}	"{{.Module}}/internal/export"
+	"{{.Module}}/internal/models"
+	"{{.Module}}/internal/services"
+)
+
+// {{.Names.PascalCase}}ExportHandler handles export requests for {{.DisplayName}}
+type {{.Names.PascalCase}}ExportHandler struct {
+	service *services.{{.Names.PascalCase}}ExportService
+}
+
+// New{{.Names.PascalCase}}ExportHandler creates a new {{.Names.PascalCase}} export handler
+func New{{.Names.PascalCase}}ExportHandler(service *services.{{.Names.PascalCase}}ExportService) *{{.Names.PascalCase}}ExportHandler {
+	return &{{.Names.PascalCase}}ExportHandler{service: service}
+}
+
+// Export handles GET /{{.Names.KebabPlural}}/export. It accepts the list filters, ?fields= and
+// ?format= (or an Accept header of text/csv, application/x-ndjson or application/json).
+func (h *{{.Names.PascalCase}}ExportHandler) Export({{.HTTP.HandlerParams}}){{.HTTP.HandlerResult}} {
+	var filter models.{{.Names.PascalCase}}Filter
+	if err := {{.HTTP.BindQuery "&filter"}}; err != nil {
+		{{.HTTP.Fail "http.StatusBadRequest" "err.Error()"}}
+	}
+
+	format, err := export.Negotiate({{.HTTP.Query "format"}}, {{.HTTP.Header "Accept"}})
+	if err != nil {
+		{{.HTTP.Fail "http.StatusNotAcceptable" "err.Error()"}}
+	}
+
+	fields, err := h.service.Fields({{.HTTP.Query "fields"}})
+	if err != nil {
+		{{.HTTP.Fail "http.StatusBadRequest" "err.Error()"}}
+	}
+
+	{{.HTTP.SetHeader "\"Content-Type\"" "format.ContentType()"}}
+	{{.HTTP.SetHeader "\"Content-Disposition\"" (printf "fmt.Sprintf(\"attachment; filename=\\\"%s.%%s\\\"\", format.Extension())" .Names.SnakePlural)}}
+	{{.HTTP.SetHeader "\"X-Content-Type-Options\"" "\"nosniff\""}}
+	{{.HTTP.WriteStatus "http.StatusOK"}}
+{{- if .HTTP.Is "fiber"}}
+
+	// Fiber sends the body after the handler returns, so the rows are streamed from the body writer
+	ctx := {{.HTTP.Context}}
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if _, err := h.service.Export(ctx, &filter, fields, export.NewWriter(format, w)); err != nil {
+			// The status line is already sent, so stop writing and leave the body truncated
+			log.Printf("export {{.Names.SnakePlural}}: %v", err)
+		}
+		_ = w.Flush()
+	})
+	return nil
+{{- else}}
+
+	if _, err := h.service.Export({{.HTTP.Context}}, &filter, fields, export.NewWriter(format, {{.HTTP.Writer}})); err != nil {
+		// The status line is already sent, so stop writing and leave the body truncated
+{{- if .HTTP.Is "gin"}}
+		_ = c.Error(err)
+		c.Abort()
+{{- else}}
+		log.Printf("export {{.Names.SnakePlural}}: %v", err)
+{{- end}}
+	}
+{{- if .HTTP.Is "echo"}}
+	return nil
+{{- end}}
+{{- end}}
+}
+
+// Setup{{.Names.PascalCase}}ExportRoutes sets up export routes for {{.DisplayName}}
+func Setup{{.Names.PascalCase}}ExportRoutes({{.HTTP.Router}}, handler *{{.Names.PascalCase}}ExportHandler) {
+	{{.HTTP.Root.Route "GET" (printf "/%s/export" .Names.KebabPlural) "handler.Export"}}
+}
+`
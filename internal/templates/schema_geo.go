@@ -0,0 +1,737 @@
+package templates
+
+// GeoPackageTemplate generates the geo package parsing the near, radius and bbox list
+// filters and building their PostGIS and latitude/longitude conditions
+const GeoPackageTemplate = `package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EarthRadius is the mean radius of the Earth in meters, the sphere PostGIS measures
+// geography distances on when no spheroid is used
+const EarthRadius = 6371008.8
+
+// DefaultRadius is the radius of near filters without one, in meters
+const DefaultRadius = 50000
+
+// ErrInvalidQuery is returned for malformed geographic filters
+var ErrInvalidQuery = errors.New("invalid geographic filter")
+
+// Point is a WGS 84 position in degrees
+type Point struct {
+	Lat float64
+	Lng float64
+}
+
+// Box is a bounding box in degrees
+type Box struct {
+	MinLng float64
+	MinLat float64
+	MaxLng float64
+	MaxLat float64
+}
+
+// Query holds the geographic filters of a list: the positions within Radius meters of
+// Near, sorted by distance, and the positions within BBox
+type Query struct {
+	Near   *Point
+	Radius float64
+	BBox   *Box
+}
+
+// ParseQuery parses the near, radius and bbox list filters. near is "lat,lng", radius a
+// distance such as "500m", "5km" or "3mi" and bbox "minLng,minLat,maxLng,maxLat". It
+// returns nil without geographic filters.
+func ParseQuery(near, radius, bbox string) (*Query, error) {
+	if near == "" && radius == "" && bbox == "" {
+		return nil, nil
+	}
+
+	query := &Query{}
+	if near != "" {
+		point, err := ParsePoint(near)
+		if err != nil {
+			return nil, err
+		}
+		query.Near = &point
+		query.Radius = DefaultRadius
+	}
+	if radius != "" {
+		if query.Near == nil {
+			return nil, fmt.Errorf("%w: radius requires near", ErrInvalidQuery)
+		}
+		distance, err := ParseDistance(radius)
+		if err != nil {
+			return nil, err
+		}
+		query.Radius = distance
+	}
+	if bbox != "" {
+		box, err := ParseBox(bbox)
+		if err != nil {
+			return nil, err
+		}
+		query.BBox = &box
+	}
+	return query, nil
+}
+
+// ParsePoint parses a "lat,lng" position
+func ParsePoint(s string) (Point, error) {
+	values, ok := parseNumbers(s, 2)
+	if !ok {
+		return Point{}, fmt.Errorf("%w: near must be lat,lng", ErrInvalidQuery)
+	}
+	point := Point{Lat: values[0], Lng: values[1]}
+	if !point.Valid() {
+		return Point{}, fmt.Errorf("%w: near is out of range", ErrInvalidQuery)
+	}
+	return point, nil
+}
+
+// units maps the suffixes of distances onto meters, longer suffixes first
+var units = []struct {
+	suffix string
+	meters float64
+}{
+	{"km", 1000},
+	{"mi", 1609.344},
+	{"m", 1},
+}
+
+// ParseDistance parses a distance in meters, kilometers or miles into meters. Numbers
+// without a unit are meters. Distances are capped to half the circumference of the Earth.
+func ParseDistance(s string) (float64, error) {
+	value := strings.ToLower(strings.TrimSpace(s))
+	scale := 1.0
+	for _, unit := range units {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			scale = unit.meters
+			break
+		}
+	}
+
+	distance, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(distance) || math.IsInf(distance, 0) || distance <= 0 {
+		return 0, fmt.Errorf("%w: radius must be a positive distance such as 500m, 5km or 3mi", ErrInvalidQuery)
+	}
+	return math.Min(distance*scale, math.Pi*EarthRadius), nil
+}
+
+// ParseBox parses a "minLng,minLat,maxLng,maxLat" bounding box
+func ParseBox(s string) (Box, error) {
+	values, ok := parseNumbers(s, 4)
+	if !ok {
+		return Box{}, fmt.Errorf("%w: bbox must be minLng,minLat,maxLng,maxLat", ErrInvalidQuery)
+	}
+	box := Box{MinLng: values[0], MinLat: values[1], MaxLng: values[2], MaxLat: values[3]}
+	min, max := Point{Lat: box.MinLat, Lng: box.MinLng}, Point{Lat: box.MaxLat, Lng: box.MaxLng}
+	if !min.Valid() || !max.Valid() || box.MinLng > box.MaxLng || box.MinLat > box.MaxLat {
+		return Box{}, fmt.Errorf("%w: bbox must be minLng,minLat,maxLng,maxLat within range", ErrInvalidQuery)
+	}
+	return box, nil
+}
+
+// parseNumbers parses n comma separated numbers
+func parseNumbers(s string, n int) ([]float64, bool) {
+	parts := strings.Split(s, ",")
+	if len(parts) != n {
+		return nil, false
+	}
+	values := make([]float64, n)
+	for i, part := range parts {
+		value, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, false
+		}
+		values[i] = value
+	}
+	return values, true
+}
+
+// Valid checks that the point is within the latitude and longitude ranges
+func (p Point) Valid() bool {
+	return p.Lat >= -90 && p.Lat <= 90 && p.Lng >= -180 && p.Lng <= 180
+}
+
+// GeoJSON returns the GeoJSON geometry of the point
+func (p Point) GeoJSON() map[string]interface{} {
+	return map[string]interface{}{"type": "Point", "coordinates": []float64{p.Lng, p.Lat}}
+}
+
+// GeoJSON returns the GeoJSON polygon of the box
+func (b Box) GeoJSON() map[string]interface{} {
+	ring := [][]float64{
+		{b.MinLng, b.MinLat},
+		{b.MaxLng, b.MinLat},
+		{b.MaxLng, b.MaxLat},
+		{b.MinLng, b.MaxLat},
+		{b.MinLng, b.MinLat},
+	}
+	return map[string]interface{}{"type": "Polygon", "coordinates": [][][]float64{ring}}
+}
+
+// Distance returns the great-circle distance between two points in meters
+func Distance(a, b Point) float64 {
+	lat1, lat2 := radians(a.Lat), radians(b.Lat)
+	dLat := lat2 - lat1
+	dLng := radians(b.Lng - a.Lng)
+
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dLng/2), 2)
+	return 2 * EarthRadius * math.Asin(math.Sqrt(math.Min(1, h)))
+}
+
+// Bounds returns the bounding box of the circle of radius meters around center. Boxes
+// reaching a pole or crossing the antimeridian span every longitude.
+func Bounds(center Point, radius float64) Box {
+	angle := radius / EarthRadius
+	box := Box{
+		MinLat: center.Lat - degrees(angle),
+		MaxLat: center.Lat + degrees(angle),
+		MinLng: -180,
+		MaxLng: 180,
+	}
+	if box.MinLat <= -90 || box.MaxLat >= 90 {
+		box.MinLat, box.MaxLat = math.Max(box.MinLat, -90), math.Min(box.MaxLat, 90)
+		return box
+	}
+
+	dLng := degrees(math.Asin(math.Sin(angle) / math.Cos(radians(center.Lat))))
+	if center.Lng-dLng >= -180 && center.Lng+dLng <= 180 {
+		box.MinLng, box.MaxLng = center.Lng-dLng, center.Lng+dLng
+	}
+	return box
+}
+
+// Nearest keeps the items within radius meters sorted by distance, and returns the page
+// of them with their count. distance returns the distance of an item, nil without position.
+func Nearest[T any](items []T, distance func(T) *float64, radius float64, page, pageSize int) ([]T, int64) {
+	var kept []T
+	for _, item := range items {
+		if d := distance(item); d != nil && *d <= radius {
+			kept = append(kept, item)
+		}
+	}
+	sort.SliceStable(kept, func(i, j int) bool {
+		return *distance(kept[i]) < *distance(kept[j])
+	})
+
+	total := int64(len(kept))
+	if page > 0 && pageSize > 0 {
+		start := (page - 1) * pageSize
+		if start > len(kept) {
+			start = len(kept)
+		}
+		end := start + pageSize
+		if end > len(kept) {
+			end = len(kept)
+		}
+		kept = kept[start:end]
+	}
+	return kept, total
+}
+
+// PostGISConditions returns the SQL conditions matching a query on a geography column.
+// Distances are measured on the sphere Distance uses, so filters, sorting and the
+// distances of results agree.
+func PostGISConditions(column string, query *Query) []string {
+	if query == nil {
+		return nil
+	}
+	var conditions []string
+	if query.BBox != nil {
+		conditions = append(conditions, fmt.Sprintf("ST_Intersects(%s, ST_MakeEnvelope(%s, %s, %s, %s, 4326)::geography)",
+			column, number(query.BBox.MinLng), number(query.BBox.MinLat), number(query.BBox.MaxLng), number(query.BBox.MaxLat)))
+	}
+	if query.Near != nil {
+		conditions = append(conditions, fmt.Sprintf("ST_DWithin(%s, %s, %s, false)", column, postGISPoint(*query.Near), number(query.Radius)))
+	}
+	return conditions
+}
+
+// PostGISDistance returns the SQL expression of the distance between a geography column and a point
+func PostGISDistance(column string, point Point) string {
+	return fmt.Sprintf("ST_Distance(%s, %s, false)", column, postGISPoint(point))
+}
+
+// LatLngConditions returns the SQL conditions matching the bounding boxes of a query on
+// latitude and longitude columns. The positions in the box around the near circle still
+// have to be filtered by distance, see Nearest.
+func LatLngConditions(lat, lng string, query *Query) []string {
+	if query == nil {
+		return nil
+	}
+	var conditions []string
+	if query.BBox != nil {
+		conditions = append(conditions, between(lat, lng, *query.BBox))
+	}
+	if query.Near != nil {
+		conditions = append(conditions, between(lat, lng, Bounds(*query.Near, query.Radius)))
+	}
+	return conditions
+}
+
+// between returns the condition matching latitude and longitude columns within a box
+func between(lat, lng string, box Box) string {
+	return fmt.Sprintf("%s BETWEEN %s AND %s AND %s BETWEEN %s AND %s",
+		lat, number(box.MinLat), number(box.MaxLat), lng, number(box.MinLng), number(box.MaxLng))
+}
+
+// postGISPoint returns the SQL expression of a geography point
+func postGISPoint(point Point) string {
+	return fmt.Sprintf("ST_SetSRID(ST_MakePoint(%s, %s), 4326)::geography", number(point.Lng), number(point.Lat))
+}
+
+// number formats a coordinate or a distance for SQL. Query values are numbers parsed by
+// ParseQuery, request text never reaches the SQL.
+func number(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+func degrees(radians float64) float64 {
+	return radians * 180 / math.Pi
+}
+`
+
+// GeoTestTemplate generates the tests of the geo package
+const GeoTestTemplate = `package geo
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	query, err := ParseQuery("48.8566, 2.3522", "5km", "2.2,48.8,2.5,48.9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.Near == nil || query.Near.Lat != 48.8566 || query.Near.Lng != 2.3522 {
+		t.Fatalf("near = %+v", query.Near)
+	}
+	if query.Radius != 5000 {
+		t.Fatalf("radius = %v, want 5000", query.Radius)
+	}
+	if query.BBox == nil || *query.BBox != (Box{MinLng: 2.2, MinLat: 48.8, MaxLng: 2.5, MaxLat: 48.9}) {
+		t.Fatalf("bbox = %+v", query.BBox)
+	}
+
+	query, err = ParseQuery("", "", "")
+	if err != nil || query != nil {
+		t.Fatalf("empty filters = %+v, %v, want nil", query, err)
+	}
+
+	query, err = ParseQuery("10,20", "", "")
+	if err != nil || query.Radius != DefaultRadius {
+		t.Fatalf("near without radius = %+v, %v", query, err)
+	}
+}
+
+func TestParseQueryRejectsInvalidFilters(t *testing.T) {
+	tests := []struct {
+		near, radius, bbox string
+	}{
+		{near: "48.8566"},
+		{near: "91,0"},
+		{near: "0,181"},
+		{near: "north,east"},
+		{radius: "5km"},
+		{near: "0,0", radius: "-5km"},
+		{near: "0,0", radius: "NaN"},
+		{near: "0,0", radius: "5 parsecs"},
+		{bbox: "1,2,3"},
+		{bbox: "3,2,1,4"},
+		{bbox: "0,-91,1,1"},
+	}
+	for _, tt := range tests {
+		if _, err := ParseQuery(tt.near, tt.radius, tt.bbox); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("ParseQuery(%q, %q, %q) error = %v, want ErrInvalidQuery", tt.near, tt.radius, tt.bbox, err)
+		}
+	}
+}
+
+func TestParseDistance(t *testing.T) {
+	tests := map[string]float64{
+		"250":    250,
+		"500m":   500,
+		"1.5km":  1500,
+		"2 KM":   2000,
+		"3mi":    4828.032,
+		"1e9km":  math.Pi * EarthRadius,
+	}
+	for input, want := range tests {
+		got, err := ParseDistance(input)
+		if err != nil {
+			t.Fatalf("ParseDistance(%q): %v", input, err)
+		}
+		if math.Abs(got-want) > 1e-6 {
+			t.Errorf("ParseDistance(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	paris := Point{Lat: 48.8566, Lng: 2.3522}
+	london := Point{Lat: 51.5074, Lng: -0.1278}
+
+	if d := Distance(paris, london); math.Abs(d-343_560) > 500 {
+		t.Fatalf("Distance(paris, london) = %.0f, want about 343560", d)
+	}
+	if d := Distance(paris, paris); d != 0 {
+		t.Fatalf("Distance(paris, paris) = %v, want 0", d)
+	}
+}
+
+func TestBounds(t *testing.T) {
+	center := Point{Lat: 48.8566, Lng: 2.3522}
+	box := Bounds(center, 10_000)
+
+	for _, bearing := range []float64{0, 45, 90, 135, 180, 225, 270, 315} {
+		// The point 10km away in that direction must be in the box
+		lat, lng := radians(center.Lat), radians(center.Lng)
+		angle, theta := 10_000/EarthRadius, radians(bearing)
+		lat2 := math.Asin(math.Sin(lat)*math.Cos(angle) + math.Cos(lat)*math.Sin(angle)*math.Cos(theta))
+		lng2 := lng + math.Atan2(math.Sin(theta)*math.Sin(angle)*math.Cos(lat), math.Cos(angle)-math.Sin(lat)*math.Sin(lat2))
+		point := Point{Lat: degrees(lat2), Lng: degrees(lng2)}
+		if point.Lat < box.MinLat || point.Lat > box.MaxLat || point.Lng < box.MinLng || point.Lng > box.MaxLng {
+			t.Errorf("point at bearing %v (%+v) is outside %+v", bearing, point, box)
+		}
+	}
+
+	if polar := Bounds(Point{Lat: 89.99, Lng: 10}, 10_000); polar.MinLng != -180 || polar.MaxLng != 180 || polar.MaxLat != 90 {
+		t.Errorf("polar bounds = %+v, want every longitude", polar)
+	}
+	if antimeridian := Bounds(Point{Lat: 0, Lng: 179.99}, 10_000); antimeridian.MinLng != -180 || antimeridian.MaxLng != 180 {
+		t.Errorf("antimeridian bounds = %+v, want every longitude", antimeridian)
+	}
+}
+
+func TestNearest(t *testing.T) {
+	distances := []float64{300, 100, 900, 200}
+	items := []*float64{&distances[0], &distances[1], nil, &distances[2], &distances[3]}
+	identity := func(d *float64) *float64 { return d }
+
+	page, total := Nearest(items, identity, 500, 1, 2)
+	if total != 3 {
+		t.Fatalf("total = %d, want 3", total)
+	}
+	if len(page) != 2 || *page[0] != 100 || *page[1] != 200 {
+		t.Fatalf("first page = %v", page)
+	}
+
+	page, _ = Nearest(items, identity, 500, 2, 2)
+	if len(page) != 1 || *page[0] != 300 {
+		t.Fatalf("second page = %v", page)
+	}
+	if page, _ = Nearest(items, identity, 500, 5, 2); len(page) != 0 {
+		t.Fatalf("page past the end = %v", page)
+	}
+}
+
+func TestSQLConditions(t *testing.T) {
+	query, err := ParseQuery("48.8566,2.3522", "2km", "2.2,48.8,2.5,48.9")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	postgis := strings.Join(PostGISConditions("location", query), " AND ")
+	for _, want := range []string{
+		"ST_Intersects(location, ST_MakeEnvelope(2.2, 48.8, 2.5, 48.9, 4326)::geography)",
+		"ST_DWithin(location, ST_SetSRID(ST_MakePoint(2.3522, 48.8566), 4326)::geography, 2000, false)",
+	} {
+		if !strings.Contains(postgis, want) {
+			t.Errorf("PostGIS conditions %q lack %q", postgis, want)
+		}
+	}
+
+	latlng := LatLngConditions("location_lat", "location_lng", query)
+	if len(latlng) != 2 || latlng[0] != "location_lat BETWEEN 48.8 AND 48.9 AND location_lng BETWEEN 2.2 AND 2.5" {
+		t.Errorf("lat/lng conditions = %q", latlng)
+	}
+	if PostGISConditions("location", nil) != nil || LatLngConditions("lat", "lng", nil) != nil {
+		t.Error("a nil query must have no conditions")
+	}
+}
+`
+
+// CoordinatesTemplate generates the Coordinates type of coordinates fields, stored as a
+// PostGIS geography point, as latitude and longitude columns or as a GeoJSON point
+const CoordinatesTemplate = `package models
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+
+	"{{.Module}}/internal/geo"
+)
+
+// ErrInvalidCoordinates is returned for coordinates out of the latitude and longitude ranges
+var ErrInvalidCoordinates = errors.New("coordinates require a latitude within [-90, 90] and a longitude within [-180, 180]")
+
+// Coordinates is a WGS 84 position in degrees. It is stored as a PostGIS geography point,
+// as latitude and longitude columns on other SQL databases, and as a GeoJSON point in MongoDB.
+type Coordinates struct {
+	Latitude  float64 ` + "`" + `json:"latitude" gorm:"column:lat"` + "`" + `
+	Longitude float64 ` + "`" + `json:"longitude" gorm:"column:lng"` + "`" + `
+}
+
+// String returns the coordinates as "latitude,longitude"
+func (c Coordinates) String() string {
+	return fmt.Sprintf("%.6f,%.6f", c.Latitude, c.Longitude)
+}
+
+// IsValid checks that the coordinates are within the latitude and longitude ranges
+func (c Coordinates) IsValid() bool {
+	return c.Point().Valid()
+}
+
+// Point returns the coordinates as a geo point
+func (c Coordinates) Point() geo.Point {
+	return geo.Point{Lat: c.Latitude, Lng: c.Longitude}
+}
+
+// UnmarshalJSON decodes {"latitude": ..., "longitude": ...}, both are required and must be in range
+func (c *Coordinates) UnmarshalJSON(data []byte) error {
+	var decoded struct {
+		Latitude  *float64 ` + "`" + `json:"latitude"` + "`" + `
+		Longitude *float64 ` + "`" + `json:"longitude"` + "`" + `
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if decoded.Latitude == nil || decoded.Longitude == nil {
+		return ErrInvalidCoordinates
+	}
+
+	coordinates := Coordinates{Latitude: *decoded.Latitude, Longitude: *decoded.Longitude}
+	if !coordinates.IsValid() {
+		return ErrInvalidCoordinates
+	}
+	*c = coordinates
+	return nil
+}
+
+// GormDataType keeps GORM from reading Coordinates through Value, so they can be embedded
+// as latitude and longitude columns
+func (Coordinates) GormDataType() string {
+	return "geography"
+}
+
+// Value writes the coordinates as a PostGIS geography point in extended WKT
+func (c Coordinates) Value() (driver.Value, error) {
+	return "SRID=4326;POINT(" + strconv.FormatFloat(c.Longitude, 'f', -1, 64) + " " + strconv.FormatFloat(c.Latitude, 'f', -1, 64) + ")", nil
+}
+
+// Scan reads a PostGIS point, returned as hex encoded extended WKB by text results and as
+// WKB by binary ones. Databases without geography types return the text Value wrote.
+func (c *Coordinates) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("unsupported coordinates column value %T", src)
+	}
+
+	if text := strings.ToUpper(string(data)); strings.HasPrefix(text, "SRID=") || strings.HasPrefix(text, "POINT") {
+		return c.decodeWKT(text)
+	}
+
+	// WKB starts with its byte order, 0 or 1, hex text with a digit
+	if len(data) > 0 && data[0] > 1 {
+		decoded, err := hex.DecodeString(string(data))
+		if err != nil {
+			return fmt.Errorf("invalid coordinates column value: %w", err)
+		}
+		data = decoded
+	}
+	return c.decodeWKB(data)
+}
+
+// decodeWKT decodes a WKT or extended WKT point
+func (c *Coordinates) decodeWKT(text string) error {
+	if _, rest, ok := strings.Cut(text, ";"); ok {
+		text = rest
+	}
+	var lng, lat float64
+	if _, err := fmt.Sscanf(strings.TrimSpace(text), "POINT(%g %g)", &lng, &lat); err != nil {
+		return fmt.Errorf("invalid coordinates column value: %w", err)
+	}
+	c.Latitude, c.Longitude = lat, lng
+	return nil
+}
+
+// decodeWKB decodes a WKB or extended WKB point
+func (c *Coordinates) decodeWKB(data []byte) error {
+	if len(data) < 5 {
+		return errors.New("invalid coordinates column value: truncated WKB")
+	}
+	var order binary.ByteOrder = binary.BigEndian
+	if data[0] == 1 {
+		order = binary.LittleEndian
+	}
+	kind := order.Uint32(data[1:5])
+	data = data[5:]
+
+	// Extended WKB flags an SRID following the type
+	if kind&0x20000000 != 0 {
+		if len(data) < 4 {
+			return errors.New("invalid coordinates column value: truncated WKB")
+		}
+		data = data[4:]
+	}
+	if kind&0xffff != 1 || len(data) < 16 {
+		return errors.New("invalid coordinates column value: not a point")
+	}
+
+	c.Longitude = math.Float64frombits(order.Uint64(data[0:8]))
+	c.Latitude = math.Float64frombits(order.Uint64(data[8:16]))
+	return nil
+}
+
+// geoJSONPoint is the GeoJSON point Coordinates are stored as in MongoDB
+type geoJSONPoint struct {
+	Type        string    ` + "`" + `bson:"type"` + "`" + `
+	Coordinates []float64 ` + "`" + `bson:"coordinates"` + "`" + `
+}
+
+// MarshalBSONValue stores the coordinates as a GeoJSON point, which 2dsphere indexes require
+func (c Coordinates) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return bson.MarshalValue(geoJSONPoint{Type: "Point", Coordinates: []float64{c.Longitude, c.Latitude}})
+}
+
+// UnmarshalBSONValue reads a GeoJSON point
+func (c *Coordinates) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	if t == bsontype.Null {
+		return nil
+	}
+	var point geoJSONPoint
+	if err := bson.UnmarshalValue(t, data, &point); err != nil {
+		return err
+	}
+	if point.Type != "Point" || len(point.Coordinates) < 2 {
+		return errors.New("invalid coordinates document: not a GeoJSON point")
+	}
+	c.Longitude, c.Latitude = point.Coordinates[0], point.Coordinates[1]
+	return nil
+}
+`
+
+// CoordinatesTestTemplate generates the tests of the Coordinates encodings
+const CoordinatesTestTemplate = `package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestCoordinatesJSON(t *testing.T) {
+	var coordinates Coordinates
+	if err := json.Unmarshal([]byte(` + "`" + `{"latitude": 48.8566, "longitude": 2.3522}` + "`" + `), &coordinates); err != nil {
+		t.Fatal(err)
+	}
+	if coordinates != (Coordinates{Latitude: 48.8566, Longitude: 2.3522}) {
+		t.Fatalf("coordinates = %+v", coordinates)
+	}
+
+	for _, invalid := range []string{
+		` + "`" + `{"latitude": 91, "longitude": 0}` + "`" + `,
+		` + "`" + `{"latitude": 0, "longitude": -181}` + "`" + `,
+		` + "`" + `{"latitude": 10}` + "`" + `,
+	} {
+		if err := json.Unmarshal([]byte(invalid), &coordinates); !errors.Is(err, ErrInvalidCoordinates) {
+			t.Errorf("Unmarshal(%s) error = %v, want ErrInvalidCoordinates", invalid, err)
+		}
+	}
+}
+
+func TestCoordinatesSQL(t *testing.T) {
+	value, err := Coordinates{Latitude: 48.8566, Longitude: 2.3522}.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != "SRID=4326;POINT(2.3522 48.8566)" {
+		t.Fatalf("Value() = %v", value)
+	}
+
+	// SELECT 'SRID=4326;POINT(2.3522 48.8566)'::geography
+	ewkb := "0101000020E6100000A835CD3B4ED1024076E09C11A56D4840"
+	for _, src := range []interface{}{ewkb, []byte(ewkb)} {
+		var coordinates Coordinates
+		if err := coordinates.Scan(src); err != nil {
+			t.Fatalf("Scan(%T): %v", src, err)
+		}
+		if coordinates != (Coordinates{Latitude: 48.8566, Longitude: 2.3522}) {
+			t.Fatalf("Scan(%T) = %+v", src, coordinates)
+		}
+	}
+
+	var coordinates Coordinates
+	if err := coordinates.Scan("SRID=4326;POINT(2.3522 48.8566)"); err != nil || coordinates != (Coordinates{Latitude: 48.8566, Longitude: 2.3522}) {
+		t.Fatalf("Scan(EWKT) = %+v, %v", coordinates, err)
+	}
+	if err := coordinates.Scan("0102000020E6100000"); err == nil {
+		t.Fatal("Scan of a line string must fail")
+	}
+}
+
+func TestCoordinatesBSON(t *testing.T) {
+	type document struct {
+		Location *Coordinates ` + "`" + `bson:"location,omitempty"` + "`" + `
+		Missing  *Coordinates ` + "`" + `bson:"missing,omitempty"` + "`" + `
+	}
+
+	data, err := bson.Marshal(document{Location: &Coordinates{Latitude: 48.8566, Longitude: 2.3522}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var raw bson.M
+	if err := bson.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := raw["missing"]; ok {
+		t.Fatal("missing coordinates must be left out of the document")
+	}
+	location := raw["location"].(bson.M)
+	if location["type"] != "Point" || len(location["coordinates"].(bson.A)) != 2 || location["coordinates"].(bson.A)[0] != 2.3522 {
+		t.Fatalf("location = %v, want a GeoJSON point", location)
+	}
+
+	var decoded document
+	if err := bson.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Location == nil || *decoded.Location != (Coordinates{Latitude: 48.8566, Longitude: 2.3522}) || decoded.Missing != nil {
+		t.Fatalf("decoded = %+v", decoded)
+	}
+}
+`
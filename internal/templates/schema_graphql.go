@@ -0,0 +1,1205 @@
+package templates
+
+// GraphQLRootSchemaTemplate generates the root GraphQL schema extended by every resource
+const GraphQLRootSchemaTemplate = `schema {
+  query: Query
+  mutation: Mutation
+}
+
+"RFC 3339 timestamp"
+scalar Time
+
+"Arbitrary JSON value"
+scalar JSON
+
+enum SortOrder {
+  ASC
+  DESC
+}
+
+type PageInfo {
+  hasNextPage: Boolean!
+  hasPreviousPage: Boolean!
+  startCursor: String
+  endCursor: String
+}
+
+# Resources extend the root types, _empty only keeps them valid on their own
+type Query {
+  _empty: Boolean
+}
+
+type Mutation {
+  _empty: Boolean
+}
+`
+
+// GraphQLResourceSchemaTemplate generates the GraphQL types, inputs and root fields of a resource
+const GraphQLResourceSchemaTemplate = `# resource: {{.Names.PascalCase}}
+{{- range .Enums}}
+
+enum {{.Name}} {
+{{- range .Values}}
+  {{.Name}}
+{{- end}}
+}
+{{- end}}
+
+"{{if .Description}}{{.Description}}{{else}}{{.DisplayName}}{{end}}"
+type {{.Names.PascalCase}} {
+  id: ID!
+{{- range .GraphQLFields}}
+{{- if not .Sensitive}}
+  {{.Name}}: {{.Type}}
+{{- end}}
+{{- end}}
+{{- range .Relations}}
+  {{.Name}}: {{if .Many}}[{{.Target}}!]!{{else}}{{.Target}}{{end}}
+{{- end}}
+  createdAt: Time!
+  updatedAt: Time!
+}
+
+type {{.Names.PascalCase}}Edge {
+  cursor: String!
+  node: {{.Names.PascalCase}}!
+}
+
+type {{.Names.PascalCase}}Connection {
+  edges: [{{.Names.PascalCase}}Edge!]!
+  nodes: [{{.Names.PascalCase}}!]!
+  pageInfo: PageInfo!
+  totalCount: Int!
+}
+
+enum {{.Names.PascalCase}}SortField {
+{{- range .SortFields}}
+  {{.Name}}
+{{- end}}
+}
+
+input {{.Names.PascalCase}}Filter {
+  search: String
+{{- range .FilterFields}}
+  {{.Name}}: {{.InputType}}
+{{- end}}
+}
+
+input {{.Names.PascalCase}}CreateInput {
+{{- range .GraphQLFields}}
+  {{.Name}}: {{.InputType}}{{if .Required}}!{{end}}
+{{- end}}
+}
+
+input {{.Names.PascalCase}}UpdateInput {
+{{- range .GraphQLFields}}
+  {{.Name}}: {{.InputType}}
+{{- end}}
+}
+
+extend type Query {
+  {{.Names.CamelCase}}(id: ID!): {{.Names.PascalCase}}
+  {{.Names.CamelPlural}}(first: Int, after: String, filter: {{.Names.PascalCase}}Filter, sort: {{.Names.PascalCase}}SortField, order: SortOrder): {{.Names.PascalCase}}Connection!
+}
+
+extend type Mutation {
+  create{{.Names.PascalCase}}(input: {{.Names.PascalCase}}CreateInput!): {{.Names.PascalCase}}!
+  update{{.Names.PascalCase}}(id: ID!, input: {{.Names.PascalCase}}UpdateInput!): {{.Names.PascalCase}}!
+  delete{{.Names.PascalCase}}(id: ID!): Boolean!
+}
+`
+
+// GraphQLPackageTemplate generates the root resolver, HTTP handler and shared GraphQL types
+const GraphQLPackageTemplate = `package graphql
+
+import (
+	"embed"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	gql "github.com/graph-gophers/graphql-go"
+)
+
+//go:embed schema/*.graphql
+var schemaFiles embed.FS
+
+const (
+	// DefaultPageSize is used when a connection is requested without first
+	DefaultPageSize = 20
+	// MaxPageSize is the largest accepted value of first
+	MaxPageSize = 100
+	// MaxDepth limits the nesting of queries
+	MaxDepth = 10
+)
+
+// Backends groups the services and batch loaders used by the resolvers
+type Backends struct {
+{{- range .Resources}}
+	{{.PascalCase}} {{.PascalCase}}Backend
+{{- end}}
+}
+
+// Resolver is the root GraphQL resolver
+type Resolver struct {
+	backends Backends
+}
+
+// NewResolver creates the root resolver
+func NewResolver(backends Backends) *Resolver {
+	return &Resolver{backends: backends}
+}
+
+// Empty resolves the _empty placeholder of the root types
+func (r *Resolver) Empty() *bool {
+	return nil
+}
+
+// SchemaString returns the GraphQL schema document, root schema first
+func SchemaString() (string, error) {
+	names, err := fs.Glob(schemaFiles, "schema/*.graphql")
+	if err != nil {
+		return "", err
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if names[i] == "schema/schema.graphql" || names[j] == "schema/schema.graphql" {
+			return names[i] == "schema/schema.graphql"
+		}
+		return names[i] < names[j]
+	})
+
+	var doc strings.Builder
+	for _, name := range names {
+		data, err := schemaFiles.ReadFile(name)
+		if err != nil {
+			return "", err
+		}
+		doc.Write(data)
+		doc.WriteString("\n")
+	}
+	return doc.String(), nil
+}
+
+// NewSchema parses the GraphQL schema and binds it to the resolver
+func NewSchema(resolver *Resolver) (*gql.Schema, error) {
+	doc, err := SchemaString()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GraphQL schema: %w", err)
+	}
+
+	// Resolving a whole page in parallel lets dataloaders batch its relations in one query
+	return gql.ParseSchema(doc, resolver, gql.MaxParallelism(MaxPageSize), gql.MaxDepth(MaxDepth))
+}
+
+// Handler serves GraphQL requests over HTTP
+type Handler struct {
+	schema *gql.Schema
+}
+
+// NewHandler creates the GraphQL HTTP handler
+func NewHandler(resolver *Resolver) (*Handler, error) {
+	schema, err := NewSchema(resolver)
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{schema: schema}, nil
+}
+
+type request struct {
+	Query         string                 ` + "`" + `json:"query"` + "`" + `
+	OperationName string                 ` + "`" + `json:"operationName"` + "`" + `
+	Variables     map[string]interface{} ` + "`" + `json:"variables"` + "`" + `
+}
+
+// ServeHTTP executes a POSTed GraphQL request with request scoped dataloaders
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "GraphQL requests must use POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid GraphQL request body", http.StatusBadRequest)
+		return
+	}
+
+	response := h.schema.Exec(withLoaders(r.Context()), req.Query, req.OperationName, req.Variables)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// JSON is the JSON scalar, carrying raw JSON values
+type JSON json.RawMessage
+
+// ImplementsGraphQLType maps JSON to the JSON scalar
+func (JSON) ImplementsGraphQLType(name string) bool {
+	return name == "JSON"
+}
+
+// UnmarshalGraphQL stores any input value as JSON
+func (j *JSON) UnmarshalGraphQL(input interface{}) error {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	*j = data
+	return nil
+}
+
+// MarshalJSON writes the raw JSON value
+func (j JSON) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
+
+// jsonValue wraps a stored JSON value, nil when it is empty
+func jsonValue(raw json.RawMessage) *JSON {
+	if len(raw) == 0 {
+		return nil
+	}
+	value := JSON(raw)
+	return &value
+}
+
+// RawMessage returns the value as json.RawMessage
+func (j JSON) RawMessage() json.RawMessage {
+	return json.RawMessage(j)
+}
+
+// enumName returns the GraphQL enum value of a stored value, nil when it is not a known value
+func enumName(names map[string]string, value string) *string {
+	name, ok := names[value]
+	if !ok {
+		return nil
+	}
+	return &name
+}
+
+// pageWindow maps Relay first/after arguments onto the page based service filters
+type pageWindow struct {
+	Offset   int
+	Page     int
+	PageSize int
+	skip     int
+}
+
+func newPageWindow(first *int32, after *string) (*pageWindow, error) {
+	size := DefaultPageSize
+	if first != nil {
+		if *first < 1 || *first > MaxPageSize {
+			return nil, fmt.Errorf("first must be between 1 and %d", MaxPageSize)
+		}
+		size = int(*first)
+	}
+
+	offset := 0
+	if after != nil {
+		position, err := decodeCursor(*after)
+		if err != nil {
+			return nil, err
+		}
+		offset = position + 1
+	}
+
+	return &pageWindow{
+		Offset:   offset,
+		Page:     offset/size + 1,
+		PageSize: size,
+		skip:     offset % size,
+	}, nil
+}
+
+// cursor returns the cursor of the i-th item of the window
+func (w *pageWindow) cursor(i int) string {
+	return base64.StdEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(w.Offset+i)))
+}
+
+// trimPage drops the items of the service page that come before the requested offset
+func trimPage[T any](w *pageWindow, items []T) []T {
+	if w.skip >= len(items) {
+		return nil
+	}
+	return items[w.skip:]
+}
+
+func decodeCursor(cursor string) (int, error) {
+	data, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(data), "offset:") {
+		return 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(data), "offset:"))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	return offset, nil
+}
+
+// PageInfo resolves the Relay PageInfo type
+type PageInfo struct {
+	window *pageWindow
+	count  int
+	total  int64
+}
+
+// HasNextPage reports whether more items follow the connection
+func (p *PageInfo) HasNextPage() bool {
+	return int64(p.window.Offset+p.count) < p.total
+}
+
+// HasPreviousPage reports whether items precede the connection
+func (p *PageInfo) HasPreviousPage() bool {
+	return p.window.Offset > 0
+}
+
+// StartCursor returns the cursor of the first edge
+func (p *PageInfo) StartCursor() *string {
+	if p.count == 0 {
+		return nil
+	}
+	cursor := p.window.cursor(0)
+	return &cursor
+}
+
+// EndCursor returns the cursor of the last edge
+func (p *PageInfo) EndCursor() *string {
+	if p.count == 0 {
+		return nil
+	}
+	cursor := p.window.cursor(p.count - 1)
+	return &cursor
+}
+`
+
+// GraphQLDataloaderTemplate generates the request scoped batching loader used for relations
+const GraphQLDataloaderTemplate = `package graphql
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// BatchWait is how long a loader collects keys before fetching them
+const BatchWait = 2 * time.Millisecond
+
+// BatchFunc fetches the values of a batch of keys. Keys without a value are left out of the map.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Loader batches and caches lookups made while resolving a single request
+type Loader[K comparable, V any] struct {
+	fetch   BatchFunc[K, V]
+	mu      sync.Mutex
+	cache   map[K]*loadResult[V]
+	pending *loadBatch[K, V]
+}
+
+type loadResult[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+type loadBatch[K comparable, V any] struct {
+	keys    []K
+	results []*loadResult[V]
+	sent    bool
+}
+
+// NewLoader creates a loader fetching keys with fetch
+func NewLoader[K comparable, V any](fetch BatchFunc[K, V]) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch: fetch,
+		cache: make(map[K]*loadResult[V]),
+	}
+}
+
+// Load returns the value of key, fetching it together with the other keys requested within BatchWait
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	result, ok := l.cache[key]
+	if !ok {
+		result = &loadResult[V]{done: make(chan struct{})}
+		l.cache[key] = result
+
+		if l.pending == nil {
+			batch := &loadBatch[K, V]{}
+			l.pending = batch
+			time.AfterFunc(BatchWait, func() { l.dispatch(ctx, batch) })
+		}
+		l.pending.keys = append(l.pending.keys, key)
+		l.pending.results = append(l.pending.results, result)
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-result.done:
+		return result.value, result.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// Prime stores a value that was loaded elsewhere, such as an item of a list query
+func (l *Loader[K, V]) Prime(key K, value V) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.cache[key]; ok {
+		return
+	}
+	result := &loadResult[V]{done: make(chan struct{}), value: value}
+	close(result.done)
+	l.cache[key] = result
+}
+
+func (l *Loader[K, V]) dispatch(ctx context.Context, batch *loadBatch[K, V]) {
+	l.mu.Lock()
+	if batch.sent {
+		l.mu.Unlock()
+		return
+	}
+	batch.sent = true
+	if l.pending == batch {
+		l.pending = nil
+	}
+	l.mu.Unlock()
+
+	values, err := l.fetch(ctx, batch.keys)
+	for i, key := range batch.keys {
+		result := batch.results[i]
+		if err != nil {
+			result.err = err
+		} else {
+			result.value = values[key]
+		}
+		close(result.done)
+	}
+}
+
+type loadersKey struct{}
+
+// loaderRegistry holds the loaders of one request by name
+type loaderRegistry struct {
+	mu      sync.Mutex
+	loaders map[string]interface{}
+}
+
+// withLoaders attaches an empty loader registry to a request context
+func withLoaders(ctx context.Context) context.Context {
+	return context.WithValue(ctx, loadersKey{}, &loaderRegistry{loaders: make(map[string]interface{})})
+}
+
+// loaderFor returns the request scoped loader registered under name, creating it on first use.
+// Outside a request every call gets a fresh loader.
+func loaderFor[K comparable, V any](ctx context.Context, name string, fetch BatchFunc[K, V]) *Loader[K, V] {
+	registry, ok := ctx.Value(loadersKey{}).(*loaderRegistry)
+	if !ok {
+		return NewLoader(fetch)
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if loader, ok := registry.loaders[name].(*Loader[K, V]); ok {
+		return loader
+	}
+	loader := NewLoader(fetch)
+	registry.loaders[name] = loader
+	return loader
+}
+`
+
+// SchemaGraphQLResolverTemplate generates the query, mutation and type resolvers of a resource
+const SchemaGraphQLResolverTemplate = `package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+{{- if .UsesUUID}}
+	"github.com/google/uuid"
+{{- end}}
+	gql "github.com/graph-gophers/graphql-go"
+	"{{.Module}}/internal/apperrors"
+	"{{.Module}}/internal/models"
+{{- if .UsesMoney}}
+	"{{.Module}}/internal/money"
+{{- end}}
+	"{{.Module}}/internal/services"
+)
+
+// {{.Names.PascalCase}}BatchLoader loads {{.Names.Plural}} in batches for dataloaders
+type {{.Names.PascalCase}}BatchLoader interface {
+	GetByIDs(ctx context.Context, ids []string) ([]*models.{{.Names.PascalCase}}, error)
+	ListByColumn(ctx context.Context, column string, values []string) ([]*models.{{.Names.PascalCase}}, error)
+}
+
+// {{.Names.PascalCase}}Backend groups the dependencies of the {{.DisplayName}} resolvers
+type {{.Names.PascalCase}}Backend struct {
+	Service services.{{.Names.PascalCase}}ServiceInterface
+	Batch   {{.Names.PascalCase}}BatchLoader
+}
+{{- range .Enums}}
+
+var {{.NamesVar}} = map[string]string{
+{{- range .Values}}
+	"{{.Value}}": "{{.Name}}",
+{{- end}}
+}
+
+var {{.ValuesVar}} = map[string]string{
+{{- range .Values}}
+	"{{.Name}}": "{{.Value}}",
+{{- end}}
+}
+{{- end}}
+
+// {{.Names.CamelCase}}SortFields maps sort enum values to list sort fields
+var {{.Names.CamelCase}}SortFields = map[string]string{
+{{- range .SortFields}}
+	"{{.Name}}": "{{.Field}}",
+{{- end}}
+}
+
+// {{.Names.PascalCase}}FilterInput is the {{.Names.PascalCase}}Filter input
+type {{.Names.PascalCase}}FilterInput struct {
+	Search *string
+{{- range .FilterFields}}
+	{{.GoName}} {{.GoType}}
+{{- end}}
+}
+
+// {{.Names.PascalCase}}CreateInput is the {{.Names.PascalCase}}CreateInput input
+type {{.Names.PascalCase}}CreateInput struct {
+{{- range .GraphQLFields}}
+	{{.GoName}} {{if not .Required}}*{{end}}{{.InputGoType}}
+{{- end}}
+}
+
+// {{.Names.PascalCase}}UpdateInput is the {{.Names.PascalCase}}UpdateInput input
+type {{.Names.PascalCase}}UpdateInput struct {
+{{- range .GraphQLFields}}
+	{{.GoName}} *{{.InputGoType}}
+{{- end}}
+}
+
+// {{.Names.PascalCase}}sArgs are the arguments of the {{.Names.CamelPlural}} query
+type {{.Names.PascalCase}}sArgs struct {
+	First  *int32
+	After  *string
+	Filter *{{.Names.PascalCase}}FilterInput
+	Sort   *string
+	Order  *string
+}
+
+// {{.Names.CamelCase}}Loader returns the request scoped loader fetching {{.Names.Plural}} by ID
+func (r *Resolver) {{.Names.CamelCase}}Loader(ctx context.Context) *Loader[string, *models.{{.Names.PascalCase}}] {
+	return loaderFor(ctx, "{{.Names.SnakeCase}}", func(ctx context.Context, ids []string) (map[string]*models.{{.Names.PascalCase}}, error) {
+		items, err := r.backends.{{.Names.PascalCase}}.Batch.GetByIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		byID := make(map[string]*models.{{.Names.PascalCase}}, len(items))
+		for _, item := range items {
+			byID[item.ID.Hex()] = item
+		}
+		return byID, nil
+	})
+}
+
+// {{.Names.PascalCase}} resolves the {{.Names.CamelCase}} query
+func (r *Resolver) {{.Names.PascalCase}}(ctx context.Context, args struct{ ID gql.ID }) (*{{.Names.PascalCase}}Resolver, error) {
+	item, err := r.backends.{{.Names.PascalCase}}.Service.GetByID(ctx, string(args.ID))
+	if errors.Is(err, apperrors.ErrNotFound) {
+		// Missing {{.Names.Plural}} resolve to null
+		return nil, nil
+	}
+	if err != nil || item == nil {
+		return nil, err
+	}
+	return &{{.Names.PascalCase}}Resolver{root: r, item: item}, nil
+}
+
+// {{.Names.PascalPlural}} resolves the {{.Names.CamelPlural}} connection with the list filters
+func (r *Resolver) {{.Names.PascalPlural}}(ctx context.Context, args {{.Names.PascalCase}}sArgs) (*{{.Names.PascalCase}}ConnectionResolver, error) {
+	window, err := newPageWindow(args.First, args.After)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &models.{{.Names.PascalCase}}Filter{Page: window.Page, PageSize: window.PageSize}
+	if args.Filter != nil {
+		if args.Filter.Search != nil {
+			filter.Search = *args.Filter.Search
+		}
+{{- range .FilterFields}}
+		{{.Assign}}
+{{- end}}
+	}
+	if args.Sort != nil {
+		filter.Sort = {{.Names.CamelCase}}SortFields[*args.Sort]
+	}
+	if args.Order != nil {
+		filter.Order = *args.Order
+	}
+
+	items, total, err := r.backends.{{.Names.PascalCase}}.Service.GetAll(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Relations pointing back at these {{.Names.Plural}} are served from the loader cache
+	loader := r.{{.Names.CamelCase}}Loader(ctx)
+	for _, item := range items {
+		loader.Prime(item.ID.Hex(), item)
+	}
+
+	return &{{.Names.PascalCase}}ConnectionResolver{root: r, items: trimPage(window, items), window: window, total: total}, nil
+}
+
+// Create{{.Names.PascalCase}} resolves the create{{.Names.PascalCase}} mutation
+func (r *Resolver) Create{{.Names.PascalCase}}(ctx context.Context, args struct{ Input {{.Names.PascalCase}}CreateInput }) (*{{.Names.PascalCase}}Resolver, error) {
+	req := &models.{{.Names.PascalCase}}Request{}
+{{- range .GraphQLFields}}
+	{{.CreateAssign}}
+{{- end}}
+
+	item, err := r.backends.{{.Names.PascalCase}}.Service.Create(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &{{.Names.PascalCase}}Resolver{root: r, item: item}, nil
+}
+
+// Update{{.Names.PascalCase}} resolves the update{{.Names.PascalCase}} mutation, keeping fields missing from the input
+func (r *Resolver) Update{{.Names.PascalCase}}(ctx context.Context, args struct {
+	ID    gql.ID
+	Input {{.Names.PascalCase}}UpdateInput
+}) (*{{.Names.PascalCase}}Resolver, error) {
+	existing, err := r.backends.{{.Names.PascalCase}}.Service.GetByID(ctx, string(args.ID))
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, fmt.Errorf("{{.Names.Singular}} %s not found", args.ID)
+	}
+
+	req := &models.{{.Names.PascalCase}}Request{
+{{- range .Fields}}
+{{- if not .ReadOnly}}
+		{{.Names.PascalCase}}: existing.{{.Names.PascalCase}},
+{{- end}}
+{{- end}}
+	}
+{{- range .GraphQLFields}}
+	{{.UpdateAssign}}
+{{- end}}
+
+	item, err := r.backends.{{.Names.PascalCase}}.Service.Update(ctx, string(args.ID), req)
+	if err != nil {
+		return nil, err
+	}
+	return &{{.Names.PascalCase}}Resolver{root: r, item: item}, nil
+}
+
+// Delete{{.Names.PascalCase}} resolves the delete{{.Names.PascalCase}} mutation
+func (r *Resolver) Delete{{.Names.PascalCase}}(ctx context.Context, args struct{ ID gql.ID }) (bool, error) {
+	if err := r.backends.{{.Names.PascalCase}}.Service.Delete(ctx, string(args.ID)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// {{.Names.PascalCase}}Resolver resolves the {{.Names.PascalCase}} type
+type {{.Names.PascalCase}}Resolver struct {
+	root *Resolver
+	item *models.{{.Names.PascalCase}}
+}
+
+// ID resolves the id field
+func (r *{{.Names.PascalCase}}Resolver) ID() gql.ID {
+	return gql.ID(r.item.ID.Hex())
+}
+{{- range .GraphQLFields}}
+{{- if not .Sensitive}}
+
+// {{.GoName}} resolves the {{.Name}} field
+func (r *{{$.Names.PascalCase}}Resolver) {{.GoName}}() {{.GoType}} {
+	return {{.Output}}
+}
+{{- end}}
+{{- end}}
+
+// CreatedAt resolves the createdAt field
+func (r *{{.Names.PascalCase}}Resolver) CreatedAt() gql.Time {
+	return gql.Time{Time: r.item.CreatedAt}
+}
+
+// UpdatedAt resolves the updatedAt field
+func (r *{{.Names.PascalCase}}Resolver) UpdatedAt() gql.Time {
+	return gql.Time{Time: r.item.UpdatedAt}
+}
+{{- range .Relations}}
+{{- if .Many}}
+
+// {{.GoName}} resolves the {{.Name}} relation, batching lookups of all {{$.Names.Plural}} in the request
+func (r *{{$.Names.PascalCase}}Resolver) {{.GoName}}(ctx context.Context) ([]*{{.Target}}Resolver, error) {
+	loader := loaderFor(ctx, "{{$.Names.SnakeCase}}.{{.Name}}", func(ctx context.Context, keys []string) (map[string][]*models.{{.Target}}, error) {
+		items, err := r.root.backends.{{.Target}}.Batch.ListByColumn(ctx, "{{.Column}}", keys)
+		if err != nil {
+			return nil, err
+		}
+		grouped := make(map[string][]*models.{{.Target}}, len(keys))
+		for _, item := range items {
+			grouped[{{.TargetKeyExp}}] = append(grouped[{{.TargetKeyExp}}], item)
+		}
+		return grouped, nil
+	})
+
+	items, err := loader.Load(ctx, {{.KeyExpr}})
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*{{.Target}}Resolver, len(items))
+	for i, item := range items {
+		resolvers[i] = &{{.Target}}Resolver{root: r.root, item: item}
+	}
+	return resolvers, nil
+}
+{{- else}}
+
+// {{.GoName}} resolves the {{.Name}} relation, batching lookups of all {{$.Names.Plural}} in the request
+func (r *{{$.Names.PascalCase}}Resolver) {{.GoName}}(ctx context.Context) (*{{.Target}}Resolver, error) {
+	key := {{.KeyExpr}}
+	if key == "" {
+		return nil, nil
+	}
+	item, err := r.root.{{.TargetCamel}}Loader(ctx).Load(ctx, key)
+	if err != nil || item == nil {
+		return nil, err
+	}
+	return &{{.Target}}Resolver{root: r.root, item: item}, nil
+}
+{{- end}}
+{{- end}}
+
+// {{.Names.PascalCase}}ConnectionResolver resolves the {{.Names.PascalCase}}Connection type
+type {{.Names.PascalCase}}ConnectionResolver struct {
+	root   *Resolver
+	items  []*models.{{.Names.PascalCase}}
+	window *pageWindow
+	total  int64
+}
+
+// Edges resolves the edges of the connection
+func (c *{{.Names.PascalCase}}ConnectionResolver) Edges() []*{{.Names.PascalCase}}EdgeResolver {
+	edges := make([]*{{.Names.PascalCase}}EdgeResolver, len(c.items))
+	for i, item := range c.items {
+		edges[i] = &{{.Names.PascalCase}}EdgeResolver{
+			cursor: c.window.cursor(i),
+			node:   &{{.Names.PascalCase}}Resolver{root: c.root, item: item},
+		}
+	}
+	return edges
+}
+
+// Nodes resolves the nodes of the connection
+func (c *{{.Names.PascalCase}}ConnectionResolver) Nodes() []*{{.Names.PascalCase}}Resolver {
+	nodes := make([]*{{.Names.PascalCase}}Resolver, len(c.items))
+	for i, item := range c.items {
+		nodes[i] = &{{.Names.PascalCase}}Resolver{root: c.root, item: item}
+	}
+	return nodes
+}
+
+// PageInfo resolves the page info of the connection
+func (c *{{.Names.PascalCase}}ConnectionResolver) PageInfo() *PageInfo {
+	return &PageInfo{window: c.window, count: len(c.items), total: c.total}
+}
+
+// TotalCount resolves the number of {{.Names.Plural}} matching the filter
+func (c *{{.Names.PascalCase}}ConnectionResolver) TotalCount() int32 {
+	return int32(c.total)
+}
+
+// {{.Names.PascalCase}}EdgeResolver resolves the {{.Names.PascalCase}}Edge type
+type {{.Names.PascalCase}}EdgeResolver struct {
+	cursor string
+	node   *{{.Names.PascalCase}}Resolver
+}
+
+// Cursor resolves the cursor of the edge
+func (e *{{.Names.PascalCase}}EdgeResolver) Cursor() string {
+	return e.cursor
+}
+
+// Node resolves the node of the edge
+func (e *{{.Names.PascalCase}}EdgeResolver) Node() *{{.Names.PascalCase}}Resolver {
+	return e.node
+}
+`
+
+// SchemaBatchRepositoryTemplate generates the batch queries backing GraphQL dataloaders
+const SchemaBatchRepositoryTemplate = `package repositories
+
+import (
+	"context"
+{{- if eq .DBProvider "mongodb"}}
+
+	"{{.Module}}/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// {{.Names.PascalCase}}BatchRepository loads {{.Names.Plural}} in batches
+type {{.Names.PascalCase}}BatchRepository struct {
+	collection *mongo.Collection
+}
+
+// New{{.Names.PascalCase}}BatchRepository creates a new {{.Names.PascalCase}} batch repository
+func New{{.Names.PascalCase}}BatchRepository(db *mongo.Database) *{{.Names.PascalCase}}BatchRepository {
+	return &{{.Names.PascalCase}}BatchRepository{collection: db.Collection("{{.Names.TableName}}")}
+}
+
+// GetByIDs loads the {{.Names.Plural}} with the given IDs in one query, skipping malformed IDs
+func (r *{{.Names.PascalCase}}BatchRepository) GetByIDs(ctx context.Context, ids []string) ([]*models.{{.Names.PascalCase}}, error) {
+	objectIDs := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
+			objectIDs = append(objectIDs, objectID)
+		}
+	}
+	return r.find(ctx, bson.M{"_id": bson.M{"$in": objectIDs}})
+}
+
+// ListByColumn loads the {{.Names.Plural}} whose field matches any of the values in one query.
+// The field name comes from generated code, never from user input.
+func (r *{{.Names.PascalCase}}BatchRepository) ListByColumn(ctx context.Context, column string, values []string) ([]*models.{{.Names.PascalCase}}, error) {
+	return r.find(ctx, bson.M{column: bson.M{"$in": values}})
+}
+
+func (r *{{.Names.PascalCase}}BatchRepository) find(ctx context.Context, filter bson.M) ([]*models.{{.Names.PascalCase}}, error) {
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var items []*models.{{.Names.PascalCase}}
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+{{- else}}
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"{{.Module}}/internal/models"
+)
+
+// {{.Names.PascalCase}}BatchRepository loads {{.Names.Plural}} in batches
+type {{.Names.PascalCase}}BatchRepository struct {
+	db *gorm.DB
+}
+
+// New{{.Names.PascalCase}}BatchRepository creates a new {{.Names.PascalCase}} batch repository
+func New{{.Names.PascalCase}}BatchRepository(db *gorm.DB) *{{.Names.PascalCase}}BatchRepository {
+	return &{{.Names.PascalCase}}BatchRepository{db: db}
+}
+
+// GetByIDs loads the {{.Names.Plural}} with the given IDs in one query
+func (r *{{.Names.PascalCase}}BatchRepository) GetByIDs(ctx context.Context, ids []string) ([]*models.{{.Names.PascalCase}}, error) {
+	return r.ListByColumn(ctx, "id", ids)
+}
+
+// ListByColumn loads the {{.Names.Plural}} whose column matches any of the values in one query.
+// The column name comes from generated code, never from user input.
+func (r *{{.Names.PascalCase}}BatchRepository) ListByColumn(ctx context.Context, column string, values []string) ([]*models.{{.Names.PascalCase}}, error) {
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		args[i] = value
+	}
+
+	var items []*models.{{.Names.PascalCase}}
+	err := r.db.WithContext(ctx).
+		Where(clause.IN{Column: clause.Column{Name: column}, Values: args}).
+		Order("id").
+		Find(&items).Error
+	return items, err
+}
+{{- end}}
+`
+
+// GraphQLRoutesTemplate generates the route mounting the GraphQL endpoint next to the REST handlers
+const GraphQLRoutesTemplate = `package handlers
+
+import (
+	"net/http"
+
+{{range .HTTP.Imports}}	"{{.}}"
+{{end}}	"{{.Module}}/internal/graphql"
+)
+
+// SetupGraphQLRoutes mounts POST /graphql on the router group
+func SetupGraphQLRoutes({{.HTTP.Router}}, resolver *graphql.Resolver) error {
+	handler, err := graphql.NewHandler(resolver)
+	if err != nil {
+		return err
+	}
+
+	{{.HTTP.Root.Handle "POST" "/graphql" "handler"}}
+	return nil
+}
+`
+
+// SchemaGraphQLTestTemplate generates an end-to-end GraphQL test running the real service against sqlite
+const SchemaGraphQLTestTemplate = `package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+{{- if and .Relations .TestVariablesUseFmt}}
+	"fmt"
+{{- end}}
+	"net/http"
+	"net/http/httptest"
+{{- if .Relations}}
+	"sync"
+{{- end}}
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+{{- if .Relations}}
+	"gorm.io/gorm/clause"
+{{- end}}
+	"{{.Module}}/internal/models"
+	"{{.Module}}/internal/repositories"
+	"{{.Module}}/internal/services"
+)
+
+// sqlite{{.Names.PascalCase}}Repository is a minimal GORM repository used to run the service against sqlite
+type sqlite{{.Names.PascalCase}}Repository struct {
+	db *gorm.DB
+}
+
+var _ repositories.{{.Names.PascalCase}}RepositoryInterface = (*sqlite{{.Names.PascalCase}}Repository)(nil)
+
+func (r *sqlite{{.Names.PascalCase}}Repository) Create(ctx context.Context, item *models.{{.Names.PascalCase}}) error {
+	return r.db.WithContext(ctx).Create(item).Error
+}
+
+func (r *sqlite{{.Names.PascalCase}}Repository) GetByID(ctx context.Context, id string) (*models.{{.Names.PascalCase}}, error) {
+	var item models.{{.Names.PascalCase}}
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&item).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *sqlite{{.Names.PascalCase}}Repository) GetAll(ctx context.Context, filter *models.{{.Names.PascalCase}}Filter) ([]*models.{{.Names.PascalCase}}, int64, error) {
+	var total int64
+	query := r.db.WithContext(ctx).Model(&models.{{.Names.PascalCase}}{})
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var items []*models.{{.Names.PascalCase}}
+	err := query.Order("created_at, id").Offset((filter.Page - 1) * filter.PageSize).Limit(filter.PageSize).Find(&items).Error
+	return items, total, err
+}
+
+func (r *sqlite{{.Names.PascalCase}}Repository) Update(ctx context.Context, item *models.{{.Names.PascalCase}}) error {
+	return r.db.WithContext(ctx).Save(item).Error
+}
+
+func (r *sqlite{{.Names.PascalCase}}Repository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.{{.Names.PascalCase}}{}).Error
+}
+
+func (r *sqlite{{.Names.PascalCase}}Repository) HardDelete(ctx context.Context, id string) error {
+	return r.Delete(ctx, id)
+}
+
+func (r *sqlite{{.Names.PascalCase}}Repository) Exists(ctx context.Context, id string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.{{.Names.PascalCase}}{}).Where("id = ?", id).Count(&count).Error
+	return count > 0, err
+}
+{{- range .UniqueFields}}
+
+func (r *sqlite{{$.Names.PascalCase}}Repository) GetBy{{.Names.PascalCase}}(ctx context.Context, value {{.GoType}}) (*models.{{$.Names.PascalCase}}, error) {
+	var item models.{{$.Names.PascalCase}}
+	if err := r.db.WithContext(ctx).Where("{{.Names.SnakeCase}} = ?", value).First(&item).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+{{- end}}
+
+func new{{.Names.PascalCase}}GraphQLTestServer(t *testing.T) (*httptest.Server, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&models.{{.Names.PascalCase}}{}
+{{- range .TestTargets}}{{if ne .PascalCase $.Names.PascalCase}}, &models.{{.PascalCase}}{}{{end}}{{end}}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	resolver := NewResolver(Backends{
+		{{.Names.PascalCase}}: {{.Names.PascalCase}}Backend{
+			Service: services.New{{.Names.PascalCase}}Service(&sqlite{{.Names.PascalCase}}Repository{db: db}),
+			Batch:   repositories.New{{.Names.PascalCase}}BatchRepository(db),
+		},
+{{- range .TestTargets}}
+{{- if ne .PascalCase $.Names.PascalCase}}
+		// Relations only load {{.PascalCase}} rows through its batch repository
+		{{.PascalCase}}: {{.PascalCase}}Backend{Batch: repositories.New{{.PascalCase}}BatchRepository(db)},
+{{- end}}
+{{- end}}
+	})
+	handler, err := NewHandler(resolver)
+	if err != nil {
+		t.Fatalf("failed to build schema: %v", err)
+	}
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server, db
+}
+
+func exec{{.Names.PascalCase}}Query(t *testing.T, server *httptest.Server, query string, variables map[string]interface{}) map[string]interface{} {
+	t.Helper()
+
+	body, _ := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	resp, err := http.Post(server.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Data   map[string]interface{}   ` + "`" + `json:"data"` + "`" + `
+		Errors []map[string]interface{} ` + "`" + `json:"errors"` + "`" + `
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if len(result.Errors) > 0 {
+		t.Fatalf("query returned errors: %v", result.Errors)
+	}
+	return result.Data
+}
+
+func Test{{.Names.PascalCase}}GraphQL(t *testing.T) {
+	server, _ := new{{.Names.PascalCase}}GraphQLTestServer(t)
+
+	created := exec{{.Names.PascalCase}}Query(t, server, ` + "`" + `mutation {
+  create{{.Names.PascalCase}}(input: { {{- range .GraphQLFields}} {{.Name}}: {{.Sample}}{{end}} }) { id }
+}` + "`" + `, nil)
+	id := created["create{{.Names.PascalCase}}"].(map[string]interface{})["id"].(string)
+
+	list := exec{{.Names.PascalCase}}Query(t, server, ` + "`" + `{
+  {{.Names.CamelPlural}}(first: 10) { totalCount edges { cursor node { id } } pageInfo { hasNextPage } }
+}` + "`" + `, nil)
+	connection := list["{{.Names.CamelPlural}}"].(map[string]interface{})
+	if connection["totalCount"].(float64) != 1 {
+		t.Fatalf("expected 1 {{.Names.Singular}}, got %v", connection["totalCount"])
+	}
+	if connection["pageInfo"].(map[string]interface{})["hasNextPage"].(bool) {
+		t.Fatal("expected no next page")
+	}
+{{- if .UpdateSample}}
+
+	updated := exec{{.Names.PascalCase}}Query(t, server, ` + "`" + `mutation($id: ID!) {
+  update{{.Names.PascalCase}}(id: $id, input: { {{.UpdateSample.Name}}: "updated" }) { {{.UpdateSample.Name}} }
+}` + "`" + `, map[string]interface{}{"id": id})
+	if value := updated["update{{.Names.PascalCase}}"].(map[string]interface{})["{{.UpdateSample.Name}}"]; value != "updated" {
+		t.Fatalf("expected updated {{.UpdateSample.Name}}, got %v", value)
+	}
+{{- end}}
+
+	deleted := exec{{.Names.PascalCase}}Query(t, server, ` + "`" + `mutation($id: ID!) { delete{{.Names.PascalCase}}(id: $id) }` + "`" + `, map[string]interface{}{"id": id})
+	if deleted["delete{{.Names.PascalCase}}"] != true {
+		t.Fatal("expected {{.Names.Singular}} to be deleted")
+	}
+
+	fetched := exec{{.Names.PascalCase}}Query(t, server, ` + "`" + `query($id: ID!) { {{.Names.CamelCase}}(id: $id) { id } }` + "`" + `, map[string]interface{}{"id": id})
+	if fetched["{{.Names.CamelCase}}"] != nil {
+		t.Fatalf("expected deleted {{.Names.Singular}} to be gone, got %v", fetched["{{.Names.CamelCase}}"])
+	}
+}
+{{- if .Relations}}
+
+func Test{{.Names.PascalCase}}GraphQL_BatchesRelations(t *testing.T) {
+	server, db := new{{.Names.PascalCase}}GraphQLTestServer(t)
+
+	const count = 3
+	for i := 0; i < count; i++ {
+		exec{{.Names.PascalCase}}Query(t, server, ` + "`" + `mutation($input: {{.Names.PascalCase}}CreateInput!) { create{{.Names.PascalCase}}(input: $input) { id } }` + "`" + `, map[string]interface{}{
+			"input": map[string]interface{}{
+{{- range .GraphQLFields}}
+				"{{.Name}}": {{.Variable}},
+{{- end}}
+			},
+		})
+	}
+
+	// Dataloaders fetch the relations of every {{.Names.Singular}} of the page with one IN query
+	var mu sync.Mutex
+	queries := make(map[string]int)
+	err := db.Callback().Query().After("gorm:query").Register("test:count_batches", func(tx *gorm.DB) {
+		where, _ := tx.Statement.Clauses["WHERE"].Expression.(clause.Where)
+		for _, expr := range where.Exprs {
+			if _, ok := expr.(clause.IN); ok {
+				mu.Lock()
+				queries[tx.Statement.Table]++
+				mu.Unlock()
+				return
+			}
+		}
+	})
+	if err != nil {
+		t.Fatalf("failed to register query counter: %v", err)
+	}
+
+	list := exec{{.Names.PascalCase}}Query(t, server, ` + "`" + `{
+  {{.Names.CamelPlural}}(first: 10) { nodes { id{{range .Relations}} {{.Name}} { id }{{end}} } }
+}` + "`" + `, nil)
+	if nodes := list["{{.Names.CamelPlural}}"].(map[string]interface{})["nodes"].([]interface{}); len(nodes) != count {
+		t.Fatalf("expected %d {{.Names.Plural}}, got %d", count, len(nodes))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+{{- range .TestTargets}}
+	if got := queries[models.{{.PascalCase}}{}.TableName()]; got != {{.Queries}} {
+		t.Errorf("expected {{.Queries}} batched {{.PascalCase}} queries for %d {{$.Names.Plural}}, got %d", count, got)
+	}
+{{- end}}
+}
+{{- end}}
+`
@@ -0,0 +1,501 @@
+package templates
+
+// SchemaProtoTemplate generates the protobuf messages and CRUD service of a resource
+const SchemaProtoTemplate = `{{define "message" -}}
+message {{.Name}} {
+{{- if .Reserved}}
+  reserved {{.ReservedNumbers}};
+{{- end}}
+{{- if .ReservedNames}}
+  reserved {{.ReservedNamesList}};
+{{- end}}
+{{- range .Fields}}
+  {{if .Optional}}optional {{end}}{{.Type}} {{.Name}} = {{.Number}};
+{{- end}}
+}
+{{- end -}}
+// Code generated by vibercode from the {{.Name}} schema.
+// Field numbers are kept in {{.Names.SnakeCase}}.lock.json, commit it together with this file.
+
+syntax = "proto3";
+
+package {{.Package}};
+
+{{- if .UsesStruct}}
+
+import "google/protobuf/struct.proto";
+{{- end}}
+import "google/protobuf/timestamp.proto";
+
+option go_package = "{{.GoPackage}};{{.GoPackageName}}";
+
+// {{.Names.PascalCase}}Service manages {{.Names.Plural}}
+service {{.Names.PascalCase}}Service {
+  rpc Create{{.Names.PascalCase}}(Create{{.Names.PascalCase}}Request) returns (Create{{.Names.PascalCase}}Response);
+  rpc Get{{.Names.PascalCase}}(Get{{.Names.PascalCase}}Request) returns (Get{{.Names.PascalCase}}Response);
+  rpc List{{.Names.PascalPlural}}(List{{.Names.PascalPlural}}Request) returns (List{{.Names.PascalPlural}}Response);
+  rpc Update{{.Names.PascalCase}}(Update{{.Names.PascalCase}}Request) returns (Update{{.Names.PascalCase}}Response);
+  rpc Delete{{.Names.PascalCase}}(Delete{{.Names.PascalCase}}Request) returns (Delete{{.Names.PascalCase}}Response);
+}
+{{- range .Enums}}
+
+enum {{.Name}} {
+{{- if .Reserved}}
+  reserved {{.ReservedNumbers}};
+{{- end}}
+{{- if .ReservedNames}}
+  reserved {{.ReservedNamesList}};
+{{- end}}
+  {{.Unspecified}} = 0;
+{{- range .Values}}
+  {{.Name}} = {{.Number}};
+{{- end}}
+}
+{{- end}}
+
+// {{.Names.PascalCase}} {{if .Description}}{{.Description}}{{else}}is a {{.Names.Singular}} resource{{end}}
+{{template "message" .Resource}}
+
+{{template "message" .CreateRequest}}
+
+message Create{{.Names.PascalCase}}Response {
+  {{.Names.PascalCase}} {{.Names.SnakeCase}} = 1;
+}
+
+message Get{{.Names.PascalCase}}Request {
+  string id = 1;
+}
+
+message Get{{.Names.PascalCase}}Response {
+  {{.Names.PascalCase}} {{.Names.SnakeCase}} = 1;
+}
+
+// List{{.Names.PascalPlural}}Request filters, sorts and paginates {{.Names.Plural}}
+{{template "message" .ListRequest}}
+
+message List{{.Names.PascalPlural}}Response {
+  repeated {{.Names.PascalCase}} {{.Names.SnakePlural}} = 1;
+  int64 total = 2;
+  int32 page = 3;
+  int32 page_size = 4;
+}
+
+// Update{{.Names.PascalCase}}Request changes the fields that are set, others keep their value
+{{template "message" .UpdateRequest}}
+
+message Update{{.Names.PascalCase}}Response {
+  {{.Names.PascalCase}} {{.Names.SnakeCase}} = 1;
+}
+
+message Delete{{.Names.PascalCase}}Request {
+  string id = 1;
+}
+
+message Delete{{.Names.PascalCase}}Response {}
+`
+
+// BufTemplate generates the buf module configuration of the proto directory
+const BufTemplate = `version: v1
+breaking:
+  use:
+    - FILE
+lint:
+  use:
+    - DEFAULT
+`
+
+// BufGenTemplate generates the buf code generation configuration
+const BufGenTemplate = `version: v1
+plugins:
+  - plugin: go
+    out: gen/proto
+    opt: paths=source_relative
+  - plugin: go-grpc
+    out: gen/proto
+    opt: paths=source_relative
+{{- if .Gateway}}
+  - plugin: grpc-gateway
+    out: gen/proto
+    opt:
+      - paths=source_relative
+      - grpc_api_configuration={{.GatewayPath}}
+{{- end}}
+`
+
+// GRPCPackageTemplate generates the shared gRPC server setup and error mapping
+const GRPCPackageTemplate = `package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"{{.Module}}/internal/apperrors"
+)
+
+// MaxPageSize is the largest page size accepted by list methods
+const MaxPageSize = 100
+
+// NewServer creates a gRPC server with panic recovery and reflection.
+// Register resources on it with the generated Register*Server functions.
+func NewServer(opts ...grpc.ServerOption) *grpc.Server {
+	opts = append([]grpc.ServerOption{grpc.ChainUnaryInterceptor(recoverInterceptor)}, opts...)
+	server := grpc.NewServer(opts...)
+	reflection.Register(server)
+	return server
+}
+
+func recoverInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("rpc: panic in %s: %v", info.FullMethod, r)
+			err = status.Error(codes.Internal, "internal error")
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// violations collects invalid request fields, reported as a BadRequest detail
+type violations struct {
+	fields []*errdetails.BadRequest_FieldViolation
+}
+
+func (v *violations) add(field, description string) {
+	v.fields = append(v.fields, &errdetails.BadRequest_FieldViolation{Field: field, Description: description})
+}
+
+// err returns an InvalidArgument status carrying the violations, nil when there are none
+func (v *violations) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return invalidArgument(v.fields)
+}
+
+func invalidArgument(fields []*errdetails.BadRequest_FieldViolation) error {
+	st := status.New(codes.InvalidArgument, "invalid request")
+	detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: fields})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// statusFromError maps service errors onto gRPC status codes by their apperrors code, the
+// rejected fields of validation errors become field violations. Other errors are logged and
+// hidden behind Internal.
+func statusFromError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+
+	var appErr *apperrors.Error
+	if !errors.As(err, &appErr) || appErr.Code == apperrors.CodeInternal {
+		log.Printf("rpc: %v", err)
+		return status.Error(codes.Internal, "internal error")
+	}
+	switch appErr.Code {
+	case apperrors.CodeValidation:
+		fields := make([]*errdetails.BadRequest_FieldViolation, len(appErr.Fields))
+		for i, field := range appErr.Fields {
+			fields[i] = &errdetails.BadRequest_FieldViolation{Field: field.Field, Description: field.Message}
+		}
+		return invalidArgument(fields)
+	case apperrors.CodeNotFound:
+		return status.Error(codes.NotFound, appErr.Error())
+	case apperrors.CodeConflict:
+		return status.Error(codes.AlreadyExists, appErr.Error())
+	case apperrors.CodeUnauthorized:
+		return status.Error(codes.Unauthenticated, appErr.Error())
+	case apperrors.CodeForbidden:
+		return status.Error(codes.PermissionDenied, appErr.Error())
+	default:
+		return status.Error(codes.InvalidArgument, appErr.Error())
+	}
+}
+
+// jsonValue converts stored JSON into a protobuf Value, nil when it is empty or invalid
+func jsonValue(raw json.RawMessage) *structpb.Value {
+	if len(raw) == 0 {
+		return nil
+	}
+	value := &structpb.Value{}
+	if err := protojson.Unmarshal(raw, value); err != nil {
+		return nil
+	}
+	return value
+}
+
+// valueJSON converts a protobuf Value into JSON
+func valueJSON(value *structpb.Value) (json.RawMessage, error) {
+	return protojson.Marshal(value)
+}
+`
+
+// GRPCGatewayTemplate generates the REST gateway proxying to the gRPC server
+const GRPCGatewayTemplate = `package rpc
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// GatewayRegistration registers a generated gateway handler, such as {{.GoPackageName}}.Register{{.Names.PascalCase}}ServiceHandlerFromEndpoint
+type GatewayRegistration func(ctx context.Context, mux *runtime.ServeMux, endpoint string, opts []grpc.DialOption) error
+
+// NewGateway creates a REST gateway proxying to the gRPC server at endpoint. Without dial
+// options it connects in plaintext, meant for a gateway running next to the server.
+// gRPC status details such as field violations are returned in the JSON error body.
+func NewGateway(ctx context.Context, endpoint string, opts []grpc.DialOption, registrations ...GatewayRegistration) (http.Handler, error) {
+	if len(opts) == 0 {
+		opts = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	}
+
+	mux := runtime.NewServeMux()
+	for _, register := range registrations {
+		if err := register(ctx, mux, endpoint, opts); err != nil {
+			return nil, err
+		}
+	}
+	return mux, nil
+}
+`
+
+// SchemaGRPCServerTemplate generates the gRPC server of a resource delegating to its service
+const SchemaGRPCServerTemplate = `package rpc
+
+import (
+	"context"
+	"fmt"
+{{- if .Patterns}}
+	"regexp"
+{{- end}}
+	"strings"
+
+{{- if .UsesUUID}}
+	"github.com/google/uuid"
+{{- end}}
+	{{.GoPackageName}} "{{.GoPackage}}"
+	"{{.Module}}/internal/models"
+{{- if .UsesMoney}}
+	"{{.Module}}/internal/money"
+{{- end}}
+	"{{.Module}}/internal/services"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// {{.Names.PascalCase}}Server implements {{.GoPackageName}}.{{.Names.PascalCase}}ServiceServer on top of the {{.DisplayName}} service
+type {{.Names.PascalCase}}Server struct {
+	{{.GoPackageName}}.Unimplemented{{.Names.PascalCase}}ServiceServer
+	service services.{{.Names.PascalCase}}ServiceInterface
+}
+
+// New{{.Names.PascalCase}}Server creates a new {{.Names.PascalCase}} gRPC server
+func New{{.Names.PascalCase}}Server(service services.{{.Names.PascalCase}}ServiceInterface) *{{.Names.PascalCase}}Server {
+	return &{{.Names.PascalCase}}Server{service: service}
+}
+
+// Register{{.Names.PascalCase}}Server registers the {{.Names.PascalCase}} gRPC service
+func Register{{.Names.PascalCase}}Server(s *grpc.Server, service services.{{.Names.PascalCase}}ServiceInterface) {
+	{{.GoPackageName}}.Register{{.Names.PascalCase}}ServiceServer(s, New{{.Names.PascalCase}}Server(service))
+}
+{{- range $enum := .Enums}}
+
+var {{$enum.ToProtoVar}} = map[string]{{$.GoPackageName}}.{{$enum.Name}}{
+{{- range $enum.Values}}
+	"{{.Value}}": {{$.GoPackageName}}.{{$enum.Name}}_{{.Name}},
+{{- end}}
+}
+
+var {{$enum.FromProtoVar}} = map[{{$.GoPackageName}}.{{$enum.Name}}]string{
+{{- range $enum.Values}}
+	{{$.GoPackageName}}.{{$enum.Name}}_{{.Name}}: "{{.Value}}",
+{{- end}}
+}
+{{- end}}
+
+// {{.Names.CamelCase}}SortFields lists the fields list requests may sort by
+var {{.Names.CamelCase}}SortFields = map[string]bool{
+{{- range .SortFields}}
+	"{{.}}": true,
+{{- end}}
+}
+{{- if .Patterns}}
+
+var (
+{{- range .Patterns}}
+	{{.Var}} = regexp.MustCompile({{.Pattern}})
+{{- end}}
+)
+{{- end}}
+
+// Create{{.Names.PascalCase}} creates a {{.Names.Singular}}
+func (s *{{.Names.PascalCase}}Server) Create{{.Names.PascalCase}}(ctx context.Context, in *{{.GoPackageName}}.Create{{.Names.PascalCase}}Request) (*{{.GoPackageName}}.Create{{.Names.PascalCase}}Response, error) {
+	v := &violations{}
+	req := &models.{{.Names.PascalCase}}Request{}
+{{- range .GRPCFields}}
+	{{.CreateAssign}}
+{{- end}}
+	validate{{.Names.PascalCase}}Request(req, v)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	item, err := s.service.Create(ctx, req)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	return &{{.GoPackageName}}.Create{{.Names.PascalCase}}Response{ {{- .ItemGoName}}: {{.Names.CamelCase}}ToProto(item)}, nil
+}
+
+// Get{{.Names.PascalCase}} returns a {{.Names.Singular}} by ID
+func (s *{{.Names.PascalCase}}Server) Get{{.Names.PascalCase}}(ctx context.Context, in *{{.GoPackageName}}.Get{{.Names.PascalCase}}Request) (*{{.GoPackageName}}.Get{{.Names.PascalCase}}Response, error) {
+	item, err := s.get(ctx, in.Id)
+	if err != nil {
+		return nil, err
+	}
+	return &{{.GoPackageName}}.Get{{.Names.PascalCase}}Response{ {{- .ItemGoName}}: {{.Names.CamelCase}}ToProto(item)}, nil
+}
+
+// List{{.Names.PascalPlural}} returns a page of {{.Names.Plural}} matching the filters
+func (s *{{.Names.PascalCase}}Server) List{{.Names.PascalPlural}}(ctx context.Context, in *{{.GoPackageName}}.List{{.Names.PascalPlural}}Request) (*{{.GoPackageName}}.List{{.Names.PascalPlural}}Response, error) {
+	v := &violations{}
+	if in.Page < 0 {
+		v.add("page", "must not be negative")
+	}
+	if in.PageSize < 0 || in.PageSize > MaxPageSize {
+		v.add("page_size", fmt.Sprintf("must be between 0 and %d", MaxPageSize))
+	}
+	if in.Sort != "" && !{{.Names.CamelCase}}SortFields[in.Sort] {
+		v.add("sort", "is not a sortable field")
+	}
+	if order := strings.ToLower(in.Order); order != "" && order != "asc" && order != "desc" {
+		v.add("order", "must be asc or desc")
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	filter := &models.{{.Names.PascalCase}}Filter{
+		Page:     int(in.Page),
+		PageSize: int(in.PageSize),
+		Sort:     in.Sort,
+		Order:    in.Order,
+		Search:   in.Search,
+	}
+{{- range .FilterFields}}
+	{{.Assign}}
+{{- end}}
+
+	items, total, err := s.service.GetAll(ctx, filter)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+
+	resp := &{{.GoPackageName}}.List{{.Names.PascalPlural}}Response{
+		Total:    total,
+		Page:     int32(filter.Page),
+		PageSize: int32(filter.PageSize),
+	}
+	for _, item := range items {
+		resp.{{.ItemsGoName}} = append(resp.{{.ItemsGoName}}, {{.Names.CamelCase}}ToProto(item))
+	}
+	return resp, nil
+}
+
+// Update{{.Names.PascalCase}} changes the fields set on the request, other fields keep their value
+func (s *{{.Names.PascalCase}}Server) Update{{.Names.PascalCase}}(ctx context.Context, in *{{.GoPackageName}}.Update{{.Names.PascalCase}}Request) (*{{.GoPackageName}}.Update{{.Names.PascalCase}}Response, error) {
+	existing, err := s.get(ctx, in.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &violations{}
+	req := &models.{{.Names.PascalCase}}Request{
+{{- range .Fields}}
+{{- if not .ReadOnly}}
+		{{.Names.PascalCase}}: existing.{{.Names.PascalCase}},
+{{- end}}
+{{- end}}
+	}
+{{- range .GRPCFields}}
+	{{.UpdateAssign}}
+{{- end}}
+	validate{{.Names.PascalCase}}Request(req, v)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	item, err := s.service.Update(ctx, in.Id, req)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	return &{{.GoPackageName}}.Update{{.Names.PascalCase}}Response{ {{- .ItemGoName}}: {{.Names.CamelCase}}ToProto(item)}, nil
+}
+
+// Delete{{.Names.PascalCase}} deletes a {{.Names.Singular}}
+func (s *{{.Names.PascalCase}}Server) Delete{{.Names.PascalCase}}(ctx context.Context, in *{{.GoPackageName}}.Delete{{.Names.PascalCase}}Request) (*{{.GoPackageName}}.Delete{{.Names.PascalCase}}Response, error) {
+	if err := s.service.Delete(ctx, in.Id); err != nil {
+		return nil, statusFromError(err)
+	}
+	return &{{.GoPackageName}}.Delete{{.Names.PascalCase}}Response{}, nil
+}
+
+// get loads a {{.Names.Singular}}, reporting NotFound when it does not exist
+func (s *{{.Names.PascalCase}}Server) get(ctx context.Context, id string) (*models.{{.Names.PascalCase}}, error) {
+	item, err := s.service.GetByID(ctx, id)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	if item == nil {
+		return nil, status.Errorf(codes.NotFound, "{{.Names.Singular}} %s not found", id)
+	}
+	return item, nil
+}
+
+// validate{{.Names.PascalCase}}Request reports the schema validation rules violated by a request
+func validate{{.Names.PascalCase}}Request(r *models.{{.Names.PascalCase}}Request, v *violations) {
+{{- range .GRPCFields}}
+{{- range .Validate}}
+	{{.}}
+{{- end}}
+{{- end}}
+}
+
+// {{.Names.CamelCase}}ToProto converts a {{.Names.Singular}} into its protobuf message
+func {{.Names.CamelCase}}ToProto(m *models.{{.Names.PascalCase}}) *{{.GoPackageName}}.{{.Names.PascalCase}} {
+	return &{{.GoPackageName}}.{{.Names.PascalCase}}{
+		Id:        m.ID.Hex(),
+{{- range .GRPCFields}}
+{{- if .Output}}
+		{{.GoName}}: {{.Output}},
+{{- end}}
+{{- end}}
+		CreatedAt: timestamppb.New(m.CreatedAt),
+		UpdatedAt: timestamppb.New(m.UpdatedAt),
+	}
+}
+`
@@ -0,0 +1,600 @@
+package templates
+
+// ViewTemplate generates the parsing of ?include and sparse fieldsets shared by list and get endpoints
+const ViewTemplate = `package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidView is returned for include or fields parameters the resource can't serve
+var ErrInvalidView = errors.New("invalid include or fields parameter")
+
+// IncludeRelation is a relation of a resource that can be expanded with ?include
+type IncludeRelation struct {
+	Type  string // Resource type of the related records
+	Depth int    // Longest include path starting at the relation
+}
+
+// IncludeTree holds the requested include paths, ?include=comments.author,tags gives
+// {"comments": {"author": {}}, "tags": {}}
+type IncludeTree map[string]IncludeTree
+
+// resourceView describes the fields and includable relations of a resource type
+type resourceView struct {
+	fields    map[string]bool
+	relations map[string]IncludeRelation
+}
+
+// views holds the resource types registered by the generated <resource>_view.go files
+var views = map[string]*resourceView{}
+
+// registerView registers the fields and includable relations of a resource type
+func registerView(resource string, fields []string, relations map[string]IncludeRelation) {
+	view := &resourceView{fields: make(map[string]bool, len(fields)), relations: relations}
+	for _, field := range fields {
+		view.fields[field] = true
+	}
+	views[resource] = view
+}
+
+// View holds the include paths and sparse fieldsets of a request
+type View struct {
+	Resource string
+	Include  IncludeTree
+	// Fields lists the fields to return by resource type, types without fields return them all
+	Fields map[string]map[string]bool
+}
+
+// ParseView reads ?include=a,b.c and ?fields[type]=x,y from the query string of a request on
+// the given resource type, checking them against the registered relations and fields.
+// Without ?include, the relations set to populate are included one level deep, ?include=
+// includes none.
+func ParseView(resource, rawQuery string) (*View, error) {
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidView, err)
+	}
+
+	view := &View{Resource: resource, Include: IncludeTree{}, Fields: map[string]map[string]bool{}}
+	include, ok := query["include"]
+	if !ok {
+		include = view.defaultIncludes()
+	}
+	for _, path := range splitList(strings.Join(include, ",")) {
+		if err := view.addInclude(strings.Split(path, ".")); err != nil {
+			return nil, err
+		}
+	}
+
+	for key, values := range query {
+		if !strings.HasPrefix(key, "fields[") {
+			continue
+		}
+		resourceType := strings.TrimSuffix(strings.TrimPrefix(key, "fields["), "]")
+		registered, ok := views[resourceType]
+		if !ok || !strings.HasSuffix(key, "]") {
+			return nil, fmt.Errorf("%w: unknown resource type in %s", ErrInvalidView, key)
+		}
+		fields := map[string]bool{}
+		for _, field := range splitList(strings.Join(values, ",")) {
+			if !registered.fields[field] && registered.relations[field].Type == "" {
+				return nil, fmt.Errorf("%w: %s has no field %q", ErrInvalidView, resourceType, field)
+			}
+			fields[field] = true
+		}
+		view.Fields[resourceType] = fields
+	}
+	return view, nil
+}
+
+// defaultIncludes returns the relations of the resource included when the request has no ?include
+func (v *View) defaultIncludes() []string {
+	registered, ok := views[v.Resource]
+	if !ok {
+		return nil
+	}
+	var names []string
+	for name := range registered.relations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// addInclude adds an include path, each relation of the path caps the length of what follows
+func (v *View) addInclude(path []string) error {
+	resource, tree := v.Resource, v.Include
+	for i, name := range path {
+		registered, ok := views[resource]
+		if !ok {
+			return fmt.Errorf("%w: %s has no includable relations", ErrInvalidView, resource)
+		}
+		relation, ok := registered.relations[name]
+		if !ok {
+			return fmt.Errorf("%w: %s has no includable relation %q", ErrInvalidView, resource, name)
+		}
+		if len(path)-i > relation.Depth {
+			return fmt.Errorf("%w: %s can be included %d level(s) deep", ErrInvalidView, strings.Join(path[:i+1], "."), relation.Depth)
+		}
+		if tree[name] == nil {
+			tree[name] = IncludeTree{}
+		}
+		resource, tree = relation.Type, tree[name]
+	}
+	return nil
+}
+
+// Paths returns the include paths of the tree in dotted form
+func (t IncludeTree) Paths() []string {
+	var paths []string
+	for name, nested := range t {
+		paths = append(paths, name)
+		for _, path := range nested.Paths() {
+			paths = append(paths, name+"."+path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Shape applies the sparse fieldsets to a response, leaving it untouched without fieldsets.
+// The ID and included relations are always kept.
+func (v *View) Shape(response interface{}) (interface{}, error) {
+	if len(v.Fields) == 0 {
+		return response, nil
+	}
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	return v.shape(decoded, v.Resource, v.Include), nil
+}
+
+func (v *View) shape(value interface{}, resource string, include IncludeTree) interface{} {
+	switch value := value.(type) {
+	case []interface{}:
+		for i := range value {
+			value[i] = v.shape(value[i], resource, include)
+		}
+	case map[string]interface{}:
+		fields := v.Fields[resource]
+		for key, item := range value {
+			if nested, ok := include[key]; ok {
+				value[key] = v.shape(item, views[resource].relations[key].Type, nested)
+				continue
+			}
+			if fields != nil && key != "id" && !fields[key] {
+				delete(value, key)
+			}
+		}
+	}
+	return value
+}
+
+// splitList splits a comma separated parameter, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+`
+
+// ViewTestTemplate generates tests of the include and sparse fieldset parsing
+const ViewTestTemplate = `package models
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func init() {
+	registerView("view_test_post", []string{"id", "title", "body"}, map[string]IncludeRelation{
+		"author":   {Type: "view_test_author", Depth: 1},
+		"comments": {Type: "view_test_comment", Depth: 2},
+	})
+	registerView("view_test_comment", []string{"id", "text"}, map[string]IncludeRelation{
+		"author": {Type: "view_test_author", Depth: 1},
+	})
+	registerView("view_test_author", []string{"id", "name"}, nil)
+}
+
+func TestParseView_Include(t *testing.T) {
+	view, err := ParseView("view_test_post", "include=author,comments.author")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"author", "comments", "comments.author"}
+	if paths := view.Include.Paths(); !reflect.DeepEqual(paths, want) {
+		t.Fatalf("expected %v, got %v", want, paths)
+	}
+}
+
+func TestParseView_DefaultInclude(t *testing.T) {
+	view, err := ParseView("view_test_post", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if paths, want := view.Include.Paths(), []string{"author", "comments"}; !reflect.DeepEqual(paths, want) {
+		t.Fatalf("expected %v, got %v", want, paths)
+	}
+
+	view, err = ParseView("view_test_post", "include=")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if paths := view.Include.Paths(); len(paths) != 0 {
+		t.Fatalf("expected no includes, got %v", paths)
+	}
+}
+
+func TestParseView_RejectsInvalidParameters(t *testing.T) {
+	for _, query := range []string{
+		"include=unknown",
+		"include=author.posts",
+		"include=comments.author.name",
+		"fields[view_test_post]=secret",
+		"fields[unknown]=id",
+	} {
+		if _, err := ParseView("view_test_post", query); !errors.Is(err, ErrInvalidView) {
+			t.Errorf("%s: expected ErrInvalidView, got %v", query, err)
+		}
+	}
+}
+
+func TestView_Shape(t *testing.T) {
+	view, err := ParseView("view_test_post", "include=author&fields[view_test_post]=title&fields[view_test_author]=name")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shaped, err := view.Shape(map[string]interface{}{
+		"id":     "1",
+		"title":  "Hello",
+		"body":   "World",
+		"author": map[string]interface{}{"id": "2", "name": "Ada", "email": "ada@example.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	post := shaped.(map[string]interface{})
+	if _, ok := post["body"]; ok {
+		t.Errorf("expected body to be left out, got %v", post)
+	}
+	if post["id"] != "1" || post["title"] != "Hello" {
+		t.Errorf("expected id and title, got %v", post)
+	}
+	author := post["author"].(map[string]interface{})
+	if _, ok := author["email"]; ok || author["name"] != "Ada" {
+		t.Errorf("expected the author name only, got %v", author)
+	}
+}
+`
+
+// SchemaViewTemplate registers the fields and includable relations of a resource
+const SchemaViewTemplate = `package models
+
+func init() {
+	registerView("{{.Names.SnakeCase}}", []string{
+{{- range .ViewFields}}
+		"{{.}}",
+{{- end}}
+	}, map[string]IncludeRelation{
+{{- range .Includes}}
+		"{{.Key}}": {Type: "{{.Target.SnakeCase}}", Depth: {{.Depth}}},
+{{- end}}
+	})
+}
+`
+
+// includerInterface declares the loader interface of includable relations
+const includerInterface = `
+// {{.Names.PascalCase}}Includer loads the relations of {{.Names.Plural}} requested with ?include
+type {{.Names.PascalCase}}Includer interface {
+	Include(ctx context.Context, items []*models.{{.Names.PascalCase}}, include models.IncludeTree) error
+}
+`
+
+// SchemaIncludeGORMTemplate generates the relation loader of GORM projects, preloading
+// each included relation of a page with one query
+const SchemaIncludeGORMTemplate = `package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"{{.Module}}/internal/models"
+)
+` + includerInterface + `
+// {{.Names.PascalCase}}Includes preloads the relations of {{.Names.Plural}}
+type {{.Names.PascalCase}}Includes struct {
+	db *gorm.DB
+}
+
+// New{{.Names.PascalCase}}Includes creates the relation loader of {{.Names.Plural}}
+func New{{.Names.PascalCase}}Includes(db *gorm.DB) *{{.Names.PascalCase}}Includes {
+	return &{{.Names.PascalCase}}Includes{db: db}
+}
+
+// Include loads the requested relations of {{.Names.Plural}} and their nested includes
+func (r *{{.Names.PascalCase}}Includes) Include(ctx context.Context, items []*models.{{.Names.PascalCase}}, include models.IncludeTree) error {
+	if len(items) == 0 {
+		return nil
+	}
+{{- range .Includes}}
+	if nested, ok := include["{{.Key}}"]; ok {
+		if err := r.preload{{.GoName}}(ctx, items, nested); err != nil {
+			return fmt.Errorf("failed to include {{.Key}}: %w", err)
+		}
+	}
+{{- end}}
+	return nil
+}
+{{- range .Includes}}
+{{- if eq .Kind "many_to_one"}}
+
+// preload{{.GoName}} loads the {{.Target.Singular}} of each {{$.Names.Singular}}
+func (r *{{$.Names.PascalCase}}Includes) preload{{.GoName}}(ctx context.Context, items []*models.{{$.Names.PascalCase}}, include models.IncludeTree) error {
+	var ids []string
+	for _, item := range items {
+		if id := {{.LocalKey}}; id != "" {
+			ids = append(ids, id)
+		}
+	}
+	var related []*models.{{.Target.PascalCase}}
+	if len(ids) > 0 {
+		if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&related).Error; err != nil {
+			return err
+		}
+	}
+
+	byID := make(map[string]*models.{{.Target.PascalCase}}, len(related))
+	for _, target := range related {
+		byID[target.ID.Hex()] = target
+	}
+	for _, item := range items {
+		item.{{.GoName}} = byID[{{.LocalKey}}]
+	}
+{{- template "includeNestedGORM" .}}
+}
+{{- else if eq .Kind "one_to_many"}}
+
+// preload{{.GoName}} loads the {{.Target.Plural}} of each {{$.Names.Singular}}, latest first
+func (r *{{$.Names.PascalCase}}Includes) preload{{.GoName}}(ctx context.Context, items []*models.{{$.Names.PascalCase}}, include models.IncludeTree) error {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ID.Hex()
+	}
+	var related []*models.{{.Target.PascalCase}}
+	if err := r.db.WithContext(ctx).Where("{{.Column}} IN ?", ids).Order("created_at DESC, id").Find(&related).Error; err != nil {
+		return err
+	}
+
+	grouped := make(map[string][]*models.{{.Target.PascalCase}}, len(items))
+	for _, child := range related {
+		grouped[{{.RemoteKey}}] = append(grouped[{{.RemoteKey}}], child)
+	}
+	for _, item := range items {
+		item.{{.GoName}} = append([]*models.{{.Target.PascalCase}}{}, grouped[item.ID.Hex()]...)
+	}
+{{- template "includeNestedGORM" .}}
+}
+{{- else}}
+
+// preload{{.GoName}} loads the {{.Target.Plural}} associated with each {{$.Names.Singular}}, latest attached first
+func (r *{{$.Names.PascalCase}}Includes) preload{{.GoName}}(ctx context.Context, items []*models.{{$.Names.PascalCase}}, include models.IncludeTree) error {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ID.Hex()
+	}
+	var pivots []struct {
+		ParentID string ` + "`" + `gorm:"column:{{.ParentKey}}"` + "`" + `
+		TargetID string ` + "`" + `gorm:"column:{{.TargetKey}}"` + "`" + `
+	}
+	if err := r.db.WithContext(ctx).Table("{{.Pivot}}").Select("{{.ParentKey}}, {{.TargetKey}}").
+		Where("{{.ParentKey}} IN ?", ids).Order("created_at DESC").Scan(&pivots).Error; err != nil {
+		return err
+	}
+
+	targetIDs := make([]string, len(pivots))
+	for i, pivot := range pivots {
+		targetIDs[i] = pivot.TargetID
+	}
+	var related []*models.{{.Target.PascalCase}}
+	if len(targetIDs) > 0 {
+		if err := r.db.WithContext(ctx).Where("id IN ?", targetIDs).Find(&related).Error; err != nil {
+			return err
+		}
+	}
+
+	byID := make(map[string]*models.{{.Target.PascalCase}}, len(related))
+	for _, target := range related {
+		byID[target.ID.Hex()] = target
+	}
+	grouped := make(map[string][]*models.{{.Target.PascalCase}}, len(items))
+	for _, pivot := range pivots {
+		if target, ok := byID[pivot.TargetID]; ok {
+			grouped[pivot.ParentID] = append(grouped[pivot.ParentID], target)
+		}
+	}
+	for _, item := range items {
+		item.{{.GoName}} = append([]*models.{{.Target.PascalCase}}{}, grouped[item.ID.Hex()]...)
+	}
+{{- template "includeNestedGORM" .}}
+}
+{{- end}}
+{{- end}}
+{{- define "includeNestedGORM"}}
+{{- if .Nested}}
+	if len(include) == 0 {
+		return nil
+	}
+	return New{{.Target.PascalCase}}Includes(r.db).Include(ctx, related, include)
+{{- else}}
+	return nil
+{{- end}}
+{{- end}}
+`
+
+// SchemaIncludeMongoTemplate generates the relation loader of MongoDB projects, joining
+// the included relations of a page with $lookup stages of one aggregation
+const SchemaIncludeMongoTemplate = `package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"{{.Module}}/internal/models"
+)
+` + includerInterface + `
+// {{.Names.PascalCase}}Includes joins the relations of {{.Names.Plural}}
+type {{.Names.PascalCase}}Includes struct {
+	db *mongo.Database
+}
+
+// New{{.Names.PascalCase}}Includes creates the relation loader of {{.Names.Plural}}
+func New{{.Names.PascalCase}}Includes(db *mongo.Database) *{{.Names.PascalCase}}Includes {
+	return &{{.Names.PascalCase}}Includes{db: db}
+}
+
+// {{.Names.CamelCase}}IncludeStages returns the $lookup stage of each includable relation
+var {{.Names.CamelCase}}IncludeStages = map[string]bson.M{
+{{- range .Includes}}
+{{- if eq .Kind "many_to_one"}}
+	"{{.Key}}": {"$lookup": bson.M{
+		"from": "{{.Target.TableName}}",
+		"let":  bson.M{"fk": "${{.Column}}"},
+		"pipeline": bson.A{
+			bson.M{"$match": bson.M{"$expr": bson.M{"$eq": bson.A{bson.M{"$toString": "$_id"}, "$$fk"}}}},
+		},
+		"as": "{{.Key}}",
+	}},
+{{- else if eq .Kind "one_to_many"}}
+	"{{.Key}}": {"$lookup": bson.M{
+		"from": "{{.Target.TableName}}",
+		"let":  bson.M{"id": bson.M{"$toString": "$_id"}},
+		"pipeline": bson.A{
+			bson.M{"$match": bson.M{"$expr": bson.M{"$eq": bson.A{"${{.Column}}", "$$id"}}}},
+			bson.M{"$sort": bson.D{ {Key: "created_at", Value: -1}, {Key: "_id", Value: -1} }},
+		},
+		"as": "{{.Key}}",
+	}},
+{{- else}}
+	"{{.Key}}": {"$lookup": bson.M{
+		"from": "{{.Pivot}}",
+		"let":  bson.M{"id": "$_id"},
+		"pipeline": bson.A{
+			bson.M{"$match": bson.M{"$expr": bson.M{"$eq": bson.A{"${{.ParentKey}}", "$$id"}}}},
+			bson.M{"$sort": bson.D{ {Key: "created_at", Value: -1}, {Key: "_id", Value: -1} }},
+			bson.M{"$lookup": bson.M{"from": "{{.Target.TableName}}", "localField": "{{.TargetKey}}", "foreignField": "_id", "as": "target"}},
+			bson.M{"$unwind": "$target"},
+			bson.M{"$replaceRoot": bson.M{"newRoot": "$target"}},
+		},
+		"as": "{{.Key}}",
+	}},
+{{- end}}
+{{- end}}
+}
+
+// Include loads the requested relations of {{.Names.Plural}} and their nested includes
+func (r *{{.Names.PascalCase}}Includes) Include(ctx context.Context, items []*models.{{.Names.PascalCase}}, include models.IncludeTree) error {
+	if len(items) == 0 || len(include) == 0 {
+		return nil
+	}
+
+	ids := make([]primitive.ObjectID, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	pipeline := bson.A{bson.M{"$match": bson.M{"_id": bson.M{"$in": ids}}}}
+	project := bson.M{"_id": 1}
+	for name := range include {
+		if stage, ok := {{.Names.CamelCase}}IncludeStages[name]; ok {
+			pipeline = append(pipeline, stage)
+			project[name] = 1
+		}
+	}
+	pipeline = append(pipeline, bson.M{"$project": project})
+
+	cursor, err := r.db.Collection("{{.Names.TableName}}").Aggregate(ctx, pipeline)
+	if err != nil {
+		return fmt.Errorf("failed to include relations: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var docs []struct {
+		ID primitive.ObjectID ` + "`" + `bson:"_id"` + "`" + `
+{{- range .Includes}}
+		{{.GoName}} []*models.{{.Target.PascalCase}} ` + "`" + `bson:"{{.Key}}"` + "`" + `
+{{- end}}
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return fmt.Errorf("failed to include relations: %w", err)
+	}
+
+	byID := make(map[primitive.ObjectID]int, len(docs))
+	for i, doc := range docs {
+		byID[doc.ID] = i
+	}
+{{- range .Includes}}
+	if {{if .Nested}}nested{{else}}_{{end}}, ok := include["{{.Key}}"]; ok {
+{{- if .Nested}}
+		var related []*models.{{.Target.PascalCase}}
+{{- end}}
+		for _, item := range items {
+			i, found := byID[item.ID]
+			if !found {
+				continue
+			}
+{{- if eq .Kind "many_to_one"}}
+			item.{{.GoName}} = nil
+			if len(docs[i].{{.GoName}}) > 0 {
+				item.{{.GoName}} = docs[i].{{.GoName}}[0]
+{{- if .Nested}}
+				related = append(related, item.{{.GoName}})
+{{- end}}
+			}
+{{- else}}
+			item.{{.GoName}} = append([]*models.{{.Target.PascalCase}}{}, docs[i].{{.GoName}}...)
+{{- if .Nested}}
+			related = append(related, item.{{.GoName}}...)
+{{- end}}
+{{- end}}
+		}
+{{- if .Nested}}
+		if len(nested) > 0 {
+			if err := New{{.Target.PascalCase}}Includes(r.db).Include(ctx, related, nested); err != nil {
+				return err
+			}
+		}
+{{- end}}
+	}
+{{- end}}
+	return nil
+}
+`
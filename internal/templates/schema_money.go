@@ -0,0 +1,646 @@
+package templates
+
+// MoneyPackageTemplate generates the money package holding the amounts of currency fields
+// as exact decimals with their ISO 4217 currency
+const MoneyPackageTemplate = `package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	// ErrUnknownCurrency is returned for codes that are not active ISO 4217 currencies
+	ErrUnknownCurrency = errors.New("unknown currency")
+	// ErrCurrencyMismatch is returned when combining amounts of different currencies
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	// ErrPrecision is returned for amounts with more decimals than the minor unit of their currency
+	ErrPrecision = errors.New("amount is more precise than its currency")
+)
+
+// currencies maps the active ISO 4217 codes onto the number of decimals of their minor unit
+var currencies = func() map[string]int32 {
+	byDecimals := map[int32]string{
+		0: "BIF CLP DJF GNF ISK JPY KMF KRW PYG RWF UGX UYI VND VUV XAF XOF XPF",
+		2: "AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BMD BND BOB BOV BRL BSD BTN BWP " +
+			"BYN BZD CAD CDF CHE CHF CHW CNY COP COU CRC CUP CVE CZK DKK DOP DZD EGP ERN ETB EUR FJD " +
+			"FKP GBP GEL GHS GIP GMD GTQ GYD HKD HNL HTG HUF IDR ILS INR IRR JMD KES KGS KHR KPW KYD " +
+			"KZT LAK LBP LKR LRD LSL MAD MDL MGA MKD MMK MNT MOP MRU MUR MVR MWK MXN MXV MYR MZN NAD " +
+			"NGN NIO NOK NPR NZD PAB PEN PGK PHP PKR PLN QAR RON RSD RUB SAR SBD SCR SDG SEK SGD SHP " +
+			"SLE SOS SRD SSP STN SVC SYP SZL THB TJS TMT TOP TRY TTD TWD TZS UAH USD USN UYU UZS VED " +
+			"VES WST XCD YER ZAR ZMW ZWG",
+		3: "BHD IQD JOD KWD LYD OMR TND",
+		4: "CLF UYW",
+	}
+	codes := make(map[string]int32)
+	for decimals, list := range byDecimals {
+		for _, code := range strings.Fields(list) {
+			codes[code] = decimals
+		}
+	}
+	return codes
+}()
+
+// ValidCurrency reports whether code is an active ISO 4217 currency code
+func ValidCurrency(code string) bool {
+	_, ok := currencies[code]
+	return ok
+}
+
+// MinorUnit returns the number of decimals of the minor unit of a currency, 2 for the cents of EUR or USD
+func MinorUnit(code string) (int32, bool) {
+	decimals, ok := currencies[code]
+	return decimals, ok
+}
+
+// RoundingMode selects how amounts are rounded to the minor unit of their currency
+type RoundingMode int
+
+const (
+	// HalfEven rounds to the nearest minor unit and ties to the even one, the banker's rounding
+	HalfEven RoundingMode = iota
+	// HalfUp rounds to the nearest minor unit and ties away from zero
+	HalfUp
+	// Up rounds away from zero
+	Up
+	// Down rounds towards zero
+	Down
+	// Ceiling rounds towards positive infinity
+	Ceiling
+	// Floor rounds towards negative infinity
+	Floor
+)
+
+// Money is an exact amount of an ISO 4217 currency. The zero value has no currency and
+// stands for a missing amount, it is encoded as null.
+type Money struct {
+	Amount   decimal.Decimal ` + "`" + `gorm:"column:amount;type:decimal(19,4)"` + "`" + `
+	Currency string          ` + "`" + `gorm:"column:currency;size:3"` + "`" + `
+}
+
+// New returns an amount of currency. Currency codes are case insensitive, amounts with
+// more decimals than the minor unit of the currency are rejected.
+func New(amount decimal.Decimal, currency string) (Money, error) {
+	m := Money{Amount: amount, Currency: strings.ToUpper(currency)}
+	if err := m.Validate(); err != nil {
+		return Money{}, err
+	}
+	return m, nil
+}
+
+// Parse parses an amount followed or preceded by its currency code, such as "12.50 EUR"
+func Parse(s string) (Money, error) {
+	parts := strings.Fields(s)
+	if len(parts) != 2 {
+		return Money{}, fmt.Errorf("invalid amount %q, expected an amount and a currency code", s)
+	}
+	amount, currency := parts[0], parts[1]
+	// The currency code comes first in "EUR 12.50"
+	if strings.IndexFunc(amount, unicode.IsLetter) == 0 {
+		amount, currency = currency, amount
+	}
+	value, err := decimal.NewFromString(amount)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid amount %q", s)
+	}
+	return New(value, currency)
+}
+
+// MustParse is like Parse but panics on invalid amounts, for constants and tests
+func MustParse(s string) Money {
+	m, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// FromMinor returns the amount of units of the minor unit of a currency, FromMinor(1250, "EUR")
+// is 12.50 EUR. Zero units without a currency return the zero Money.
+func FromMinor(units int64, currency string) (Money, error) {
+	if units == 0 && currency == "" {
+		return Money{}, nil
+	}
+	decimals, ok := MinorUnit(currency)
+	if !ok {
+		return Money{}, fmt.Errorf("%w %q", ErrUnknownCurrency, currency)
+	}
+	return Money{Amount: decimal.New(units, -decimals), Currency: currency}, nil
+}
+
+// Validate checks the currency code and that the amount fits its minor unit. The zero Money is valid.
+func (m Money) Validate() error {
+	if m.Currency == "" {
+		if m.Amount.IsZero() {
+			return nil
+		}
+		return fmt.Errorf("%w: amount %s has no currency", ErrUnknownCurrency, m.Amount)
+	}
+	decimals, ok := MinorUnit(m.Currency)
+	if !ok {
+		return fmt.Errorf("%w %q", ErrUnknownCurrency, m.Currency)
+	}
+	if !m.Amount.Equal(m.Amount.Truncate(decimals)) {
+		return fmt.Errorf("%w: %s has more than %d decimals", ErrPrecision, m.Amount, decimals)
+	}
+	return nil
+}
+
+// In reports whether the currency of m is one of codes
+func (m Money) In(codes ...string) bool {
+	for _, code := range codes {
+		if m.Currency == code {
+			return true
+		}
+	}
+	return false
+}
+
+// IsZero reports whether the amount is zero, whatever its currency
+func (m Money) IsZero() bool {
+	return m.Amount.IsZero()
+}
+
+// IsNegative reports whether the amount is below zero
+func (m Money) IsNegative() bool {
+	return m.Amount.IsNegative()
+}
+
+// MinorUnits returns the amount in units of the minor unit of its currency, 1250 for 12.50 EUR.
+// Amounts more precise than the minor unit are rounded half to even.
+func (m Money) MinorUnits() int64 {
+	return m.Amount.Shift(m.decimals()).RoundBank(0).IntPart()
+}
+
+// Add returns m + other, both amounts must have the same currency
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount.Add(other.Amount), Currency: m.currency(other)}, nil
+}
+
+// Sub returns m - other, both amounts must have the same currency
+func (m Money) Sub(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount.Sub(other.Amount), Currency: m.currency(other)}, nil
+}
+
+// Neg returns -m
+func (m Money) Neg() Money {
+	return Money{Amount: m.Amount.Neg(), Currency: m.Currency}
+}
+
+// Mul returns m multiplied by factor, such as a quantity or a tax rate. The product is exact,
+// Round it to the minor unit of the currency before storing it.
+func (m Money) Mul(factor decimal.Decimal) Money {
+	return Money{Amount: m.Amount.Mul(factor), Currency: m.Currency}
+}
+
+// Round rounds the amount to the minor unit of its currency
+func (m Money) Round(mode RoundingMode) Money {
+	decimals := m.decimals()
+	amount := m.Amount
+	switch mode {
+	case HalfUp:
+		amount = amount.Round(decimals)
+	case Up:
+		amount = amount.RoundUp(decimals)
+	case Down:
+		amount = amount.RoundDown(decimals)
+	case Ceiling:
+		amount = amount.RoundCeil(decimals)
+	case Floor:
+		amount = amount.RoundFloor(decimals)
+	default:
+		amount = amount.RoundBank(decimals)
+	}
+	return Money{Amount: amount, Currency: m.Currency}
+}
+
+// Allocate splits m into shares proportional to ratios without losing a minor unit: the
+// units left over by rounding down go to the first shares, one each. Allocate(1, 1, 1) of
+// 10.00 EUR returns 3.34, 3.33 and 3.33 EUR.
+func (m Money) Allocate(ratios ...int) ([]Money, error) {
+	var total int64
+	for _, ratio := range ratios {
+		if ratio < 0 {
+			return nil, fmt.Errorf("invalid allocation ratio %d", ratio)
+		}
+		total += int64(ratio)
+	}
+	if total == 0 {
+		return nil, errors.New("allocation ratios must not all be zero")
+	}
+
+	decimals := m.decimals()
+	units := m.Amount.Shift(decimals).RoundBank(0)
+	shares := make([]Money, len(ratios))
+	left := units
+	for i, ratio := range ratios {
+		share, _ := units.Mul(decimal.NewFromInt(int64(ratio))).QuoRem(decimal.NewFromInt(total), 0)
+		shares[i] = Money{Amount: share, Currency: m.Currency}
+		left = left.Sub(share)
+	}
+
+	step := decimal.NewFromInt(int64(left.Sign()))
+	for i := range shares {
+		if left.IsZero() {
+			break
+		}
+		if ratios[i] == 0 {
+			continue
+		}
+		shares[i].Amount = shares[i].Amount.Add(step)
+		left = left.Sub(step)
+	}
+	for i := range shares {
+		shares[i].Amount = shares[i].Amount.Shift(-decimals)
+	}
+	return shares, nil
+}
+
+// Cmp compares m and other: -1 if m is less, 0 if they are equal, +1 if m is greater.
+// Both amounts must have the same currency.
+func (m Money) Cmp(other Money) (int, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return 0, err
+	}
+	return m.Amount.Cmp(other.Amount), nil
+}
+
+// String returns the amount and its currency, "12.50 EUR", or an empty string for the zero Money
+func (m Money) String() string {
+	if m.isEmpty() {
+		return ""
+	}
+	return m.format() + " " + m.Currency
+}
+
+// jsonMoney is the JSON encoding of Money, the amount is a string to keep its precision
+type jsonMoney struct {
+	Amount   json.RawMessage ` + "`" + `json:"amount"` + "`" + `
+	Currency string          ` + "`" + `json:"currency"` + "`" + `
+}
+
+// MarshalJSON encodes m as {"amount":"12.50","currency":"EUR"}, or null for the zero Money
+func (m Money) MarshalJSON() ([]byte, error) {
+	if m.isEmpty() {
+		return []byte("null"), nil
+	}
+	amount, err := json.Marshal(m.format())
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonMoney{Amount: amount, Currency: m.Currency})
+}
+
+// UnmarshalJSON decodes an object with a string or number amount and a currency code, or
+// a string such as "12.50 EUR". The currency and the precision of the amount are validated.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*m = Money{}
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		parsed, err := Parse(s)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	}
+
+	var raw jsonMoney
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if len(raw.Amount) == 0 {
+		return errors.New("money amount is required")
+	}
+	amount, err := decimal.NewFromString(strings.Trim(string(raw.Amount), ` + "`" + `"` + "`" + `))
+	if err != nil {
+		return fmt.Errorf("invalid money amount %s", raw.Amount)
+	}
+	parsed, err := New(amount, raw.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// bsonMoney is the document Money is stored as in MongoDB
+type bsonMoney struct {
+	Amount   primitive.Decimal128 ` + "`" + `bson:"amount"` + "`" + `
+	Currency string               ` + "`" + `bson:"currency"` + "`" + `
+}
+
+// MarshalBSONValue stores m as a document with a Decimal128 amount, or null for the zero Money
+func (m Money) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	if m.isEmpty() {
+		return bson.TypeNull, nil, nil
+	}
+	amount, err := primitive.ParseDecimal128(m.Amount.String())
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid money amount %s: %w", m.Amount, err)
+	}
+	return bson.MarshalValue(bsonMoney{Amount: amount, Currency: m.Currency})
+}
+
+// UnmarshalBSONValue decodes the documents written by MarshalBSONValue
+func (m *Money) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	if t == bson.TypeNull {
+		*m = Money{}
+		return nil
+	}
+	var doc bsonMoney
+	if err := (bson.RawValue{Type: t, Value: data}).Unmarshal(&doc); err != nil {
+		return err
+	}
+	amount, err := decimal.NewFromString(doc.Amount.String())
+	if err != nil {
+		return fmt.Errorf("invalid money amount %s: %w", doc.Amount, err)
+	}
+	*m = Money{Amount: amount, Currency: doc.Currency}
+	return nil
+}
+
+// isEmpty reports whether m is the zero Money
+func (m Money) isEmpty() bool {
+	return m.Currency == "" && m.Amount.IsZero()
+}
+
+// decimals returns the number of decimals of the minor unit of the currency, 2 for unknown ones
+func (m Money) decimals() int32 {
+	if decimals, ok := MinorUnit(m.Currency); ok {
+		return decimals
+	}
+	return 2
+}
+
+// format returns the amount with the decimals of its currency, or all of them for more precise amounts
+func (m Money) format() string {
+	decimals := m.decimals()
+	if m.Amount.Equal(m.Amount.Truncate(decimals)) {
+		return m.Amount.StringFixed(decimals)
+	}
+	return m.Amount.String()
+}
+
+// sameCurrency checks that m and other can be combined, the zero Money combines with any currency
+func (m Money) sameCurrency(other Money) error {
+	if m.Currency == other.Currency || m.isEmpty() || other.isEmpty() {
+		return nil
+	}
+	return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+}
+
+// currency returns the currency of the result of combining m and other
+func (m Money) currency(other Money) string {
+	if m.Currency != "" {
+		return m.Currency
+	}
+	return other.Currency
+}
+`
+
+// MoneyTestTemplate generates the tests of the money package
+const MoneyTestTemplate = `package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	price := MustParse("12.5 EUR")
+	encoded, err := json.Marshal(price)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(encoded) != ` + "`" + `{"amount":"12.50","currency":"EUR"}` + "`" + ` {
+		t.Fatalf("unexpected encoding %s", encoded)
+	}
+
+	var decoded Money
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.String() != "12.50 EUR" {
+		t.Fatalf("expected 12.50 EUR, got %s", decoded)
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		err   error
+	}{
+		{` + "`" + `{"amount":19.99,"currency":"usd"}` + "`" + `, "19.99 USD", nil},
+		{` + "`" + `"1500 JPY"` + "`" + `, "1500 JPY", nil},
+		{` + "`" + `null` + "`" + `, "", nil},
+		{` + "`" + `{"amount":"1.999","currency":"EUR"}` + "`" + `, "", ErrPrecision},
+		{` + "`" + `{"amount":"1.5","currency":"JPY"}` + "`" + `, "", ErrPrecision},
+		{` + "`" + `{"amount":"1","currency":"XYZ"}` + "`" + `, "", ErrUnknownCurrency},
+	}
+	for _, tt := range tests {
+		var m Money
+		err := json.Unmarshal([]byte(tt.input), &m)
+		if tt.err != nil {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("%s: expected %v, got %v", tt.input, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.input, err)
+			continue
+		}
+		if m.String() != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.want, m)
+		}
+	}
+
+	var m Money
+	if err := json.Unmarshal([]byte(` + "`" + `{"currency":"EUR"}` + "`" + `), &m); err == nil {
+		t.Error("expected an error without amount")
+	}
+}
+
+func TestZeroMoneyIsNull(t *testing.T) {
+	encoded, err := json.Marshal(struct {
+		Price Money ` + "`" + `json:"price"` + "`" + `
+	}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(encoded) != ` + "`" + `{"price":null}` + "`" + ` {
+		t.Fatalf("unexpected encoding %s", encoded)
+	}
+	if err := (Money{}).Validate(); err != nil {
+		t.Fatalf("expected the zero Money to be valid, got %v", err)
+	}
+}
+
+func TestMinorUnits(t *testing.T) {
+	m, err := FromMinor(1250, "EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.String() != "12.50 EUR" || m.MinorUnits() != 1250 {
+		t.Fatalf("unexpected amount %s", m)
+	}
+	if units := MustParse("1.234 KWD").MinorUnits(); units != 1234 {
+		t.Fatalf("expected 1234 fils, got %d", units)
+	}
+	if units := MustParse("1500 JPY").MinorUnits(); units != 1500 {
+		t.Fatalf("expected 1500 yen, got %d", units)
+	}
+	if _, err := FromMinor(1, "XYZ"); !errors.Is(err, ErrUnknownCurrency) {
+		t.Fatalf("expected ErrUnknownCurrency, got %v", err)
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	sum, err := MustParse("10.10 USD").Add(MustParse("0.20 USD"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.String() != "10.30 USD" {
+		t.Fatalf("expected 10.30 USD, got %s", sum)
+	}
+
+	diff, err := MustParse("1.00 USD").Sub(MustParse("1.01 USD"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.IsNegative() || diff.String() != "-0.01 USD" {
+		t.Fatalf("expected -0.01 USD, got %s", diff)
+	}
+
+	if _, err := MustParse("1 USD").Add(MustParse("1 EUR")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Fatalf("expected ErrCurrencyMismatch, got %v", err)
+	}
+	if cmp, err := MustParse("2 EUR").Cmp(MustParse("1.99 EUR")); err != nil || cmp != 1 {
+		t.Fatalf("expected 2 EUR > 1.99 EUR, got %d, %v", cmp, err)
+	}
+
+	vat := MustParse("19.99 EUR").Mul(decimal.RequireFromString("0.2"))
+	if vat.Round(HalfEven).String() != "4.00 EUR" {
+		t.Fatalf("expected 4.00 EUR of VAT, got %s", vat.Round(HalfEven))
+	}
+}
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		amount string
+		mode   RoundingMode
+		want   string
+	}{
+		{"2.345", HalfEven, "2.34"},
+		{"2.355", HalfEven, "2.36"},
+		{"2.345", HalfUp, "2.35"},
+		{"-2.345", HalfUp, "-2.35"},
+		{"2.341", Up, "2.35"},
+		{"2.349", Down, "2.34"},
+		{"-2.341", Ceiling, "-2.34"},
+		{"-2.341", Floor, "-2.35"},
+	}
+	for _, tt := range tests {
+		m := Money{Amount: decimal.RequireFromString(tt.amount), Currency: "EUR"}
+		if got := m.Round(tt.mode).format(); got != tt.want {
+			t.Errorf("Round(%s, %d): expected %s, got %s", tt.amount, tt.mode, tt.want, got)
+		}
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	shares, err := MustParse("10.00 EUR").Allocate(1, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"3.34 EUR", "3.33 EUR", "3.33 EUR"}
+	total := Money{}
+	for i, share := range shares {
+		if share.String() != want[i] {
+			t.Errorf("share %d: expected %s, got %s", i, want[i], share)
+		}
+		if total, err = total.Add(share); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if total.String() != "10.00 EUR" {
+		t.Fatalf("expected the shares to add up to 10.00 EUR, got %s", total)
+	}
+
+	shares, err = MustParse("-0.05 USD").Allocate(70, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shares[0].String() != "-0.04 USD" || shares[1].String() != "-0.01 USD" {
+		t.Fatalf("unexpected shares %v", shares)
+	}
+	if _, err := MustParse("1 EUR").Allocate(0, 0); err == nil {
+		t.Fatal("expected an error for zero ratios")
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, input := range []string{"12.50 EUR", "EUR 12.50", "12.5 eur"} {
+		m, err := Parse(input)
+		if err != nil {
+			t.Fatalf("%s: %v", input, err)
+		}
+		if m.String() != "12.50 EUR" {
+			t.Fatalf("%s: expected 12.50 EUR, got %s", input, m)
+		}
+	}
+	for _, input := range []string{"", "12.50", "twelve EUR", "12.50 EUR USD"} {
+		if _, err := Parse(input); err == nil {
+			t.Errorf("%q: expected an error", input)
+		}
+	}
+}
+
+func TestBSONRoundTrip(t *testing.T) {
+	type document struct {
+		Price Money ` + "`" + `bson:"price"` + "`" + `
+		Fee   Money ` + "`" + `bson:"fee"` + "`" + `
+	}
+	encoded, err := bson.Marshal(document{Price: MustParse("1234567890.12 EUR")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded document
+	if err := bson.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Price.String() != "1234567890.12 EUR" {
+		t.Fatalf("expected 1234567890.12 EUR, got %s", decoded.Price)
+	}
+	if !decoded.Fee.isEmpty() {
+		t.Fatalf("expected a zero fee, got %v", decoded.Fee)
+	}
+}
+`
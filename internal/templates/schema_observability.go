@@ -0,0 +1,1291 @@
+package templates
+
+// ObservabilityPackageTemplate generates the tracer provider setup of the observability feature
+const ObservabilityPackageTemplate = `package observability
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the spans of the generated instrumentation
+const instrumentationName = "{{.Module}}/internal/observability"
+
+// TraceIDHeader is the response header carrying the trace ID of a request
+const TraceIDHeader = "X-Trace-Id"
+
+// Setup installs the tracer provider, the W3C trace context propagator and the default slog
+// logger. Spans are exported over OTLP/HTTP when OTEL_EXPORTER_OTLP_ENDPOINT or
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT is set and only correlate logs otherwise. The service
+// name defaults to serviceName unless OTEL_SERVICE_NAME is set, and sampling follows
+// OTEL_TRACES_SAMPLER and OTEL_TRACES_SAMPLER_ARG. The returned function flushes the
+// pending spans and must be called before the process exits.
+func Setup(ctx context.Context, serviceName string) (func(context.Context) error, error) {
+	if name := os.Getenv("OTEL_SERVICE_NAME"); name != "" {
+		serviceName = name
+	}
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(attribute.String("service.name", serviceName)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	options := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "" {
+		exporter, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+
+	provider := sdktrace.NewTracerProvider(options...)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	slog.SetDefault(NewLogger(os.Stdout, logLevel(os.Getenv("LOG_LEVEL"))))
+
+	return func(ctx context.Context) error {
+		return errors.Join(provider.ForceFlush(ctx), provider.Shutdown(ctx))
+	}, nil
+}
+
+// tracer returns the tracer of the generated instrumentation. It is looked up on every use
+// so that spans follow the tracer provider installed last.
+func tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// TraceID returns the trace ID of the span in ctx, empty without one
+func TraceID(ctx context.Context) string {
+	if span := trace.SpanContextFromContext(ctx); span.HasTraceID() {
+		return span.TraceID().String()
+	}
+	return ""
+}
+
+// logLevel parses LOG_LEVEL, info when unset or unknown
+func logLevel(value string) slog.Level {
+	switch strings.ToLower(value) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+`
+
+// ObservabilityMetricsTemplate generates the Prometheus registry and RED metrics of the observability feature
+const ObservabilityMetricsTemplate = `package observability
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry holds the metrics exposed by MetricsHandler, along with the Go runtime and process metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_server_requests_total",
+		Help: "Handled HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_server_request_duration_seconds",
+		Help:    "Duration of the handled HTTP requests by method and route.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	httpInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "http_server_requests_in_flight",
+		Help: "HTTP requests being handled.",
+	})
+
+	clientDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_client_request_duration_seconds",
+		Help:    "Duration of outbound HTTP requests by method, host and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "host", "status"})
+
+	dbDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_client_operation_duration_seconds",
+		Help:    "Duration of database and cache operations by system, operation and outcome.",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"system", "operation", "outcome"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpDuration, httpInFlight, clientDuration, dbDuration,
+	)
+}
+
+// MetricsHandler serves the metrics of Registry in the Prometheus exposition format
+func MetricsHandler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// observeOperation records the duration of a database or cache operation
+func observeOperation(system, operation string, start time.Time, err error) {
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+	}
+	dbDuration.WithLabelValues(system, operation, outcome).Observe(time.Since(start).Seconds())
+}
+`
+
+// ObservabilityHTTPTemplate generates the request tracing and metrics middleware of the observability feature
+const ObservabilityHTTPTemplate = `package observability
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+{{- if .HTTP.Is "fiber"}}
+
+	"github.com/gofiber/fiber/v2"
+{{- else if .HTTP.Is "echo"}}
+
+	"github.com/labstack/echo/v4"
+{{- else if .HTTP.Is "chi"}}
+
+	"github.com/go-chi/chi/v5"
+{{- else if .HTTP.Is "gin"}}
+
+	"github.com/gin-gonic/gin"
+{{- end}}
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// unmatchedRoute labels the requests no route matched, which keeps the route label bounded
+const unmatchedRoute = "unmatched"
+
+// serverRequest is a request being handled, recorded as a server span and RED metrics
+type serverRequest struct {
+	ctx    context.Context
+	span   trace.Span
+	method string
+	path   string
+	start  time.Time
+}
+
+// startRequest starts the span of a request, continuing the trace propagated by the caller
+func startRequest(ctx context.Context, carrier propagation.TextMapCarrier, method, path string) *serverRequest {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, carrier)
+	ctx, span := tracer().Start(ctx, method,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("http.request.method", method),
+			attribute.String("url.path", path),
+		),
+	)
+	httpInFlight.Inc()
+	return &serverRequest{ctx: ctx, span: span, method: method, path: path, start: time.Now()}
+}
+
+// traceID returns the trace ID of the request span
+func (r *serverRequest) traceID() string {
+	return r.span.SpanContext().TraceID().String()
+}
+
+// finish names the span after the matched route, records the metrics of the request and
+// logs it. A recovered panic is recorded as a 500 response.
+func (r *serverRequest) finish(route string, status int, recovered interface{}) {
+	httpInFlight.Dec()
+	if route == "" {
+		route = unmatchedRoute
+	}
+	if recovered != nil {
+		status = http.StatusInternalServerError
+		r.span.RecordError(fmt.Errorf("panic: %v", recovered))
+	}
+	duration := time.Since(r.start)
+
+	r.span.SetName(r.method + " " + route)
+	r.span.SetAttributes(
+		attribute.String("http.route", route),
+		attribute.Int("http.response.status_code", status),
+	)
+	if status >= http.StatusInternalServerError {
+		r.span.SetStatus(codes.Error, http.StatusText(status))
+	}
+	r.span.End()
+
+	httpRequests.WithLabelValues(r.method, route, strconv.Itoa(status)).Inc()
+	httpDuration.WithLabelValues(r.method, route).Observe(duration.Seconds())
+
+	level := slog.LevelInfo
+	if status >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+	slog.LogAttrs(r.ctx, level, "request",
+		slog.String("method", r.method),
+		slog.String("route", route),
+		slog.String("path", r.path),
+		slog.Int("status", status),
+		slog.Duration("duration", duration),
+	)
+}
+{{- if .HTTP.Is "gin"}}
+
+// Middleware traces every request and records its RED metrics by route. Register it after
+// gin.Recovery so that panics are recorded as 500 responses.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		request := startRequest(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header), c.Request.Method, c.Request.URL.Path)
+		c.Request = c.Request.WithContext(request.ctx)
+		c.Header(TraceIDHeader, request.traceID())
+
+		defer func() {
+			recovered := recover()
+			request.finish(c.FullPath(), c.Writer.Status(), recovered)
+			if recovered != nil {
+				panic(recovered)
+			}
+		}()
+		c.Next()
+	}
+}
+{{- else if .HTTP.Is "echo"}}
+
+// Middleware traces every request and records its RED metrics by route. Register it after
+// middleware.Recover so that panics are recorded as 500 responses.
+func Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			req := c.Request()
+			request := startRequest(req.Context(), propagation.HeaderCarrier(req.Header), req.Method, req.URL.Path)
+			c.SetRequest(req.WithContext(request.ctx))
+			c.Response().Header().Set(TraceIDHeader, request.traceID())
+
+			defer func() {
+				recovered := recover()
+				request.finish(c.Path(), c.Response().Status, recovered)
+				if recovered != nil {
+					panic(recovered)
+				}
+			}()
+			// The error handler writes the response of failed requests, which sets their status
+			if err = next(c); err != nil {
+				request.span.RecordError(err)
+				c.Error(err)
+			}
+			return err
+		}
+	}
+}
+{{- else if .HTTP.Is "fiber"}}
+
+// Middleware traces every request and records its RED metrics by route. Register it after
+// recover.New so that panics are recorded as 500 responses.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// Fiber reuses the memory of request values once the handler returns
+		method := strings.Clone(c.Method())
+		request := startRequest(c.UserContext(), fiberCarrier{c}, method, strings.Clone(c.Path()))
+		c.SetUserContext(request.ctx)
+		c.Set(TraceIDHeader, request.traceID())
+
+		defer func() {
+			recovered := recover()
+			request.finish(c.Route().Path, c.Response().StatusCode(), recovered)
+			if recovered != nil {
+				panic(recovered)
+			}
+		}()
+		// The error handler writes the response of failed requests, which sets their status
+		if err := c.Next(); err != nil {
+			request.span.RecordError(err)
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				return c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+		return nil
+	}
+}
+
+// fiberCarrier reads the propagated trace context from the request headers
+type fiberCarrier struct {
+	c *fiber.Ctx
+}
+
+func (f fiberCarrier) Get(key string) string {
+	return f.c.Get(key)
+}
+
+func (f fiberCarrier) Set(key, value string) {
+	f.c.Request().Header.Set(key, value)
+}
+
+func (f fiberCarrier) Keys() []string {
+	var keys []string
+	f.c.Request().Header.VisitAll(func(key, _ []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}
+{{- else}}
+
+// statusRecorder captures the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+{{- if .HTTP.Is "chi"}}
+
+// Middleware traces every request and records its RED metrics by route pattern. Register it
+// after middleware.Recoverer so that panics are recorded as 500 responses.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := startRequest(r.Context(), propagation.HeaderCarrier(r.Header), r.Method, r.URL.Path)
+		w.Header().Set(TraceIDHeader, request.traceID())
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		defer func() {
+			recovered := recover()
+			route := ""
+			if routes := chi.RouteContext(r.Context()); routes != nil {
+				route = routes.RoutePattern()
+			}
+			request.finish(route, recorder.status, recovered)
+			if recovered != nil {
+				panic(recovered)
+			}
+		}()
+		next.ServeHTTP(recorder, r.WithContext(request.ctx))
+	})
+}
+{{- else}}
+
+// Middleware traces every request and records its RED metrics by route. Routes are the
+// patterns of the *http.ServeMux next is, so wrap the mux of the API routes rather than a mux
+// mounting it under a prefix. Register it after middleware.Recoverer so that panics are
+// recorded as 500 responses.
+func Middleware(next http.Handler) http.Handler {
+	mux, _ := next.(*http.ServeMux)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := startRequest(r.Context(), propagation.HeaderCarrier(r.Header), r.Method, r.URL.Path)
+		w.Header().Set(TraceIDHeader, request.traceID())
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		route := ""
+		if mux != nil {
+			_, route = mux.Handler(r)
+			// Patterns registered with a method start with it, the span name already does
+			if i := strings.IndexByte(route, ' '); i >= 0 {
+				route = route[i+1:]
+			}
+		}
+
+		defer func() {
+			recovered := recover()
+			request.finish(route, recorder.status, recovered)
+			if recovered != nil {
+				panic(recovered)
+			}
+		}()
+		next.ServeHTTP(recorder, r.WithContext(request.ctx))
+	})
+}
+{{- end}}
+{{- end}}
+`
+
+// ObservabilityClientTemplate generates the instrumented outbound HTTP transport of the observability feature
+const ObservabilityClientTemplate = `package observability
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Transport traces outbound requests, propagates the trace context to the called service and
+// records the request durations by host
+type Transport struct {
+	Base http.RoundTripper
+}
+
+// NewTransport instruments base, http.DefaultTransport when nil
+func NewTransport(base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{Base: base}
+}
+
+// NewHTTPClient returns a client with an instrumented default transport
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: NewTransport(nil)}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := tracer().Start(req.Context(), "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("server.address", req.URL.Hostname()),
+			attribute.String("url.full", req.URL.Scheme+"://"+req.URL.Host+req.URL.Path),
+		),
+	)
+	defer span.End()
+
+	// Round trippers must not modify the request they are given
+	req = req.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	start := time.Now()
+	res, err := t.Base.RoundTrip(req)
+	status := "error"
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else {
+		status = strconv.Itoa(res.StatusCode)
+		span.SetAttributes(attribute.Int("http.response.status_code", res.StatusCode))
+		if res.StatusCode >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(res.StatusCode))
+		}
+	}
+	clientDuration.WithLabelValues(req.Method, req.URL.Host, status).Observe(time.Since(start).Seconds())
+	return res, err
+}
+`
+
+// ObservabilityLogTemplate generates the trace-correlated structured logger of the observability feature
+const ObservabilityLogTemplate = `package observability
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewLogger returns a JSON logger adding the trace and span IDs of the context to its records.
+// Log with the Context variants, such as slog.InfoContext, to correlate records with traces.
+func NewLogger(w io.Writer, level slog.Leveler) *slog.Logger {
+	return slog.New(NewLogHandler(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})))
+}
+
+// LogHandler adds the trace_id and span_id attributes of the span in the context to records
+type LogHandler struct {
+	slog.Handler
+}
+
+// NewLogHandler wraps handler
+func NewLogHandler(handler slog.Handler) *LogHandler {
+	return &LogHandler{Handler: handler}
+}
+
+// Handle implements slog.Handler
+func (h *LogHandler) Handle(ctx context.Context, record slog.Record) error {
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(
+			slog.String("trace_id", span.TraceID().String()),
+			slog.String("span_id", span.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs implements slog.Handler
+func (h *LogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &LogHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup implements slog.Handler
+func (h *LogHandler) WithGroup(name string) slog.Handler {
+	return &LogHandler{Handler: h.Handler.WithGroup(name)}
+}
+`
+
+// ObservabilityGORMTemplate generates the GORM query instrumentation of the observability feature
+const ObservabilityGORMTemplate = `package observability
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// gormCallKey stores the call of a statement in its instance values
+const gormCallKey = "observability:call"
+
+// gormCall is a GORM operation being executed
+type gormCall struct {
+	span  trace.Span
+	start time.Time
+}
+
+// InstrumentGORM records a span and the duration of every query of db. Queries need a context,
+// passed with db.WithContext, to be part of the trace of a request.
+func InstrumentGORM(db *gorm.DB) error {
+	system := db.Dialector.Name()
+	callbacks := db.Callback()
+
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register("observability:before_create", beforeGORM(system, "create")),
+		callbacks.Create().After("gorm:create").Register("observability:after_create", afterGORM(system, "create")),
+		callbacks.Query().Before("gorm:query").Register("observability:before_query", beforeGORM(system, "query")),
+		callbacks.Query().After("gorm:query").Register("observability:after_query", afterGORM(system, "query")),
+		callbacks.Update().Before("gorm:update").Register("observability:before_update", beforeGORM(system, "update")),
+		callbacks.Update().After("gorm:update").Register("observability:after_update", afterGORM(system, "update")),
+		callbacks.Delete().Before("gorm:delete").Register("observability:before_delete", beforeGORM(system, "delete")),
+		callbacks.Delete().After("gorm:delete").Register("observability:after_delete", afterGORM(system, "delete")),
+		callbacks.Row().Before("gorm:row").Register("observability:before_row", beforeGORM(system, "row")),
+		callbacks.Row().After("gorm:row").Register("observability:after_row", afterGORM(system, "row")),
+		callbacks.Raw().Before("gorm:raw").Register("observability:before_raw", beforeGORM(system, "raw")),
+		callbacks.Raw().After("gorm:raw").Register("observability:after_raw", afterGORM(system, "raw")),
+	)
+}
+
+// beforeGORM starts the span of an operation
+func beforeGORM(system, operation string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		ctx := tx.Statement.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		ctx, span := tracer().Start(ctx, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system", system),
+				attribute.String("db.operation", operation),
+			),
+		)
+		tx.Statement.Context = ctx
+		tx.InstanceSet(gormCallKey, &gormCall{span: span, start: time.Now()})
+	}
+}
+
+// afterGORM ends the span of an operation and records its duration
+func afterGORM(system, operation string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		value, ok := tx.InstanceGet(gormCallKey)
+		if !ok {
+			return
+		}
+		call := value.(*gormCall)
+
+		call.span.SetAttributes(
+			attribute.String("db.sql.table", tx.Statement.Table),
+			attribute.String("db.statement", tx.Statement.SQL.String()),
+			attribute.Int64("db.rows_affected", tx.Statement.RowsAffected),
+		)
+		err := tx.Error
+		// A missing record is a result rather than a failure of the database
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = nil
+		}
+		if err != nil {
+			call.span.RecordError(err)
+			call.span.SetStatus(codes.Error, err.Error())
+		}
+		call.span.End()
+		observeOperation(system, operation, call.start, err)
+	}
+}
+`
+
+// ObservabilityMongoTemplate generates the MongoDB command instrumentation of the observability feature
+const ObservabilityMongoTemplate = `package observability
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// mongoCall is a MongoDB command being executed
+type mongoCall struct {
+	span  trace.Span
+	start time.Time
+}
+
+// NewMongoMonitor returns a command monitor recording a span and the duration of every
+// command, set on the client with options.Client().SetMonitor
+func NewMongoMonitor() *event.CommandMonitor {
+	var calls sync.Map // Request ID to *mongoCall
+
+	finish := func(requestID int64, command string, err error) {
+		value, ok := calls.LoadAndDelete(requestID)
+		if !ok {
+			return
+		}
+		call := value.(*mongoCall)
+		if err != nil {
+			call.span.RecordError(err)
+			call.span.SetStatus(codes.Error, err.Error())
+		}
+		call.span.End()
+		observeOperation("mongodb", command, call.start, err)
+	}
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			attributes := []attribute.KeyValue{
+				attribute.String("db.system", "mongodb"),
+				attribute.String("db.name", e.DatabaseName),
+				attribute.String("db.operation", e.CommandName),
+			}
+			if collection, ok := e.Command.Lookup(e.CommandName).StringValueOK(); ok {
+				attributes = append(attributes, attribute.String("db.mongodb.collection", collection))
+			}
+			_, span := tracer().Start(ctx, "mongodb."+e.CommandName,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(attributes...),
+			)
+			calls.Store(e.RequestID, &mongoCall{span: span, start: time.Now()})
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			finish(e.RequestID, e.CommandName, nil)
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			finish(e.RequestID, e.CommandName, errors.New(e.Failure))
+		},
+	}
+}
+`
+
+// ObservabilityRedisTemplate generates the Redis command instrumentation of the observability feature
+const ObservabilityRedisTemplate = `package observability
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// redisCallKey stores the call of a command in its context
+type redisCallKey struct{}
+
+// redisCall is a Redis command or pipeline being executed
+type redisCall struct {
+	span  trace.Span
+	start time.Time
+}
+
+// RedisHook records a span and the duration of every command and pipeline, added to a client
+// with AddHook
+type RedisHook struct{}
+
+var _ redis.Hook = RedisHook{}
+
+// BeforeProcess implements redis.Hook
+func (RedisHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return startRedis(ctx, cmd.Name(), attribute.String("db.operation", cmd.Name())), nil
+}
+
+// AfterProcess implements redis.Hook
+func (RedisHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	finishRedis(ctx, cmd.Name(), cmd.Err())
+	return nil
+}
+
+// BeforeProcessPipeline implements redis.Hook
+func (RedisHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	return startRedis(ctx, "pipeline", attribute.Int("db.redis.pipeline_length", len(cmds))), nil
+}
+
+// AfterProcessPipeline implements redis.Hook
+func (RedisHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if cmd.Err() != nil && !errors.Is(cmd.Err(), redis.Nil) {
+			err = cmd.Err()
+			break
+		}
+	}
+	finishRedis(ctx, "pipeline", err)
+	return nil
+}
+
+// startRedis starts the span of a command
+func startRedis(ctx context.Context, operation string, attributes ...attribute.KeyValue) context.Context {
+	attributes = append(attributes, attribute.String("db.system", "redis"))
+	ctx, span := tracer().Start(ctx, "redis."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attributes...),
+	)
+	return context.WithValue(ctx, redisCallKey{}, &redisCall{span: span, start: time.Now()})
+}
+
+// finishRedis ends the span of a command and records its duration. A missing key is a result
+// rather than a failure of the command.
+func finishRedis(ctx context.Context, operation string, err error) {
+	call, ok := ctx.Value(redisCallKey{}).(*redisCall)
+	if !ok {
+		return
+	}
+	if errors.Is(err, redis.Nil) {
+		err = nil
+	}
+	if err != nil {
+		call.span.RecordError(err)
+		call.span.SetStatus(codes.Error, err.Error())
+	}
+	call.span.End()
+	observeOperation("redis", operation, call.start, err)
+}
+`
+
+// ObservabilityTestTemplate generates the tests of the observability package
+const ObservabilityTestTemplate = `package observability
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+{{- if .HTTP.Is "fiber"}}
+
+	"github.com/gofiber/fiber/v2"
+{{- else if .HTTP.Is "echo"}}
+
+	"github.com/labstack/echo/v4"
+{{- else if .HTTP.Is "chi"}}
+
+	"github.com/go-chi/chi/v5"
+{{- else if .HTTP.Is "gin"}}
+
+	"github.com/gin-gonic/gin"
+{{- end}}
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// testRoute is the parameterized route of the test server
+const testRoute = "{{if .HTTP.NetHTTP}}/things/{id}{{else}}/things/:id{{end}}"
+
+// recordSpans installs a tracer provider recording the ended spans and a logger writing to the
+// returned buffer
+func recordSpans(t *testing.T) (*tracetest.SpanRecorder, *bytes.Buffer) {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous, previousLogger := otel.GetTracerProvider(), slog.Default()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	logs := &bytes.Buffer{}
+	slog.SetDefault(NewLogger(logs, slog.LevelInfo))
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		slog.SetDefault(previousLogger)
+	})
+	return recorder, logs
+}
+
+// newTestServer serves testRoute, answering 200 and logging with the request context, and
+// /fail, answering 500
+func newTestServer() func(*http.Request) *http.Response {
+{{- if .HTTP.Is "gin"}}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Middleware())
+	router.GET(testRoute, func(c *gin.Context) {
+		slog.InfoContext(c.Request.Context(), "loading thing")
+		c.JSON(http.StatusOK, gin.H{"id": c.Param("id")})
+	})
+	router.GET("/fail", func(c *gin.Context) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed"})
+	})
+	return func(req *http.Request) *http.Response {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Result()
+	}
+{{- else if .HTTP.Is "echo"}}
+	e := echo.New()
+	e.Use(Middleware())
+	e.GET(testRoute, func(c echo.Context) error {
+		slog.InfoContext(c.Request().Context(), "loading thing")
+		return c.JSON(http.StatusOK, echo.Map{"id": c.Param("id")})
+	})
+	e.GET("/fail", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed")
+	})
+	return func(req *http.Request) *http.Response {
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		return w.Result()
+	}
+{{- else if .HTTP.Is "fiber"}}
+	app := fiber.New()
+	app.Use(Middleware())
+	app.Get(testRoute, func(c *fiber.Ctx) error {
+		slog.InfoContext(c.UserContext(), "loading thing")
+		return c.JSON(fiber.Map{"id": c.Params("id")})
+	})
+	app.Get("/fail", func(c *fiber.Ctx) error {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed")
+	})
+	return func(req *http.Request) *http.Response {
+		res, err := app.Test(req, -1)
+		if err != nil {
+			panic(err)
+		}
+		return res
+	}
+{{- else if .HTTP.Is "chi"}}
+	router := chi.NewRouter()
+	router.Use(Middleware)
+	router.Get(testRoute, func(w http.ResponseWriter, r *http.Request) {
+		slog.InfoContext(r.Context(), "loading thing")
+		w.WriteHeader(http.StatusOK)
+	})
+	router.Get("/fail", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	return func(req *http.Request) *http.Response {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Result()
+	}
+{{- else}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+testRoute, func(w http.ResponseWriter, r *http.Request) {
+		slog.InfoContext(r.Context(), "loading thing")
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("GET /fail", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	handler := Middleware(mux)
+	return func(req *http.Request) *http.Response {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Result()
+	}
+{{- end}}
+}
+
+// scrape returns the exposition of the metrics registry
+func scrape(t *testing.T) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	MetricsHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	return w.Body.String()
+}
+
+func TestMiddlewareContinuesTraceAndRecordsRoute(t *testing.T) {
+	recorder, logs := recordSpans(t)
+	serve := newTestServer()
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/things/42", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	res := serve(req)
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", res.StatusCode)
+	}
+	if got := res.Header.Get(TraceIDHeader); got != traceID {
+		t.Errorf("%s = %q, want the propagated trace %q", TraceIDHeader, got, traceID)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("recorded %d spans, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "GET "+testRoute {
+		t.Errorf("span name = %q, want %q", span.Name(), "GET "+testRoute)
+	}
+	if span.SpanKind() != trace.SpanKindServer || span.SpanContext().TraceID().String() != traceID {
+		t.Errorf("span = %v in trace %s, want a server span continuing the trace", span.SpanKind(), span.SpanContext().TraceID())
+	}
+
+	metrics := scrape(t)
+	for _, line := range []string{
+		` + "`" + `http_server_requests_total{method="GET",route="` + "`" + ` + testRoute + ` + "`" + `",status="200"} 1` + "`" + `,
+		` + "`" + `http_server_request_duration_seconds_count{method="GET",route="` + "`" + ` + testRoute + ` + "`" + `"} 1` + "`" + `,
+	} {
+		if !strings.Contains(metrics, line) {
+			t.Errorf("metrics miss %s", line)
+		}
+	}
+
+	// Both the handler log and the request log carry the trace ID
+	var records int
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("log line %q: %v", line, err)
+		}
+		if record["trace_id"] != traceID {
+			t.Errorf("log %q misses trace_id %s", line, traceID)
+		}
+		records++
+	}
+	if records != 2 {
+		t.Errorf("logged %d records, want 2", records)
+	}
+}
+
+func TestMiddlewareRecordsServerErrors(t *testing.T) {
+	recorder, _ := recordSpans(t)
+	serve := newTestServer()
+
+	res := serve(httptest.NewRequest(http.MethodGet, "/fail", nil))
+	_, _ = io.Copy(io.Discard, res.Body)
+	if res.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", res.StatusCode)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Status().Code != codes.Error {
+		t.Fatalf("spans = %v, want one failed span", spans)
+	}
+	if line := ` + "`" + `http_server_requests_total{method="GET",route="/fail",status="500"} 1` + "`" + `; !strings.Contains(scrape(t), line) {
+		t.Errorf("metrics miss %s", line)
+	}
+}
+
+func TestTransportPropagatesTrace(t *testing.T) {
+	recorder, _ := recordSpans(t)
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	ctx, parent := tracer().Start(context.Background(), "parent")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/hooks", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := NewHTTPClient(5 * time.Second).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	parent.End()
+
+	if req.Header.Get("traceparent") != "" {
+		t.Error("the transport modified the request of the caller")
+	}
+	traceID := parent.SpanContext().TraceID().String()
+	if !strings.Contains(traceparent, traceID) {
+		t.Errorf("traceparent = %q, want trace %s", traceparent, traceID)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 || spans[0].SpanKind() != trace.SpanKindClient || spans[0].Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Fatalf("spans = %v, want a client span of the parent", spans)
+	}
+	if !strings.Contains(scrape(t), ` + "`" + `http_client_request_duration_seconds_count{host="` + "`" + `+req.URL.Host+` + "`" + `",method="POST",status="202"} 1` + "`" + `) {
+		t.Error("metrics miss the client request")
+	}
+}
+
+func TestLogHandlerAddsTraceIDs(t *testing.T) {
+	recordSpans(t)
+	logs := &bytes.Buffer{}
+	logger := NewLogger(logs, slog.LevelInfo).With("component", "test")
+
+	logger.InfoContext(context.Background(), "untraced")
+	if strings.Contains(logs.String(), "trace_id") {
+		t.Errorf("log %q has a trace ID without a span", logs.String())
+	}
+
+	logs.Reset()
+	ctx, span := tracer().Start(context.Background(), "traced")
+	defer span.End()
+	logger.InfoContext(ctx, "traced")
+	for _, attr := range []string{` + "`" + `"trace_id":"` + "`" + ` + TraceID(ctx), ` + "`" + `"span_id":"` + "`" + ` + span.SpanContext().SpanID().String(), ` + "`" + `"component":"test"` + "`" + `} {
+		if !strings.Contains(logs.String(), attr) {
+			t.Errorf("log %q misses %s", logs.String(), attr)
+		}
+	}
+}
+
+func TestSetupWithoutExporter(t *testing.T) {
+	previous, previousLogger := otel.GetTracerProvider(), slog.Default()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		slog.SetDefault(previousLogger)
+	})
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+
+	shutdown, err := Setup(context.Background(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, span := tracer().Start(context.Background(), "local")
+	span.End()
+	if TraceID(ctx) == "" {
+		t.Error("spans have no trace ID without an exporter")
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+`
+
+// ObservabilityComposeTemplate generates the optional docker-compose override running the
+// collector, Jaeger, Prometheus and Grafana next to the API
+const ObservabilityComposeTemplate = `# Observability services of {{.Name}}, started along with the API by:
+#   docker compose -f docker-compose.yml -f docker-compose.observability.yml up
+#
+# Traces:  Jaeger on http://localhost:16686, through the OpenTelemetry collector
+# Metrics: Prometheus on http://localhost:9090, scraping /metrics of the API
+# Grafana: http://localhost:3000 with the request rate, errors and duration dashboard
+
+services:
+  app:
+    environment:
+      - OTEL_SERVICE_NAME={{.Name}}
+      - OTEL_EXPORTER_OTLP_ENDPOINT=http://otel-collector:4318
+    depends_on:
+      - otel-collector
+
+  otel-collector:
+    image: otel/opentelemetry-collector-contrib:0.98.0
+    command: ["--config=/etc/otelcol/config.yaml"]
+    volumes:
+      - ./deployment/observability/otel-collector.yaml:/etc/otelcol/config.yaml:ro
+    ports:
+      - "4317:4317"
+      - "4318:4318"
+    depends_on:
+      - jaeger
+    networks:
+      - {{.Name}}-network
+
+  jaeger:
+    image: jaegertracing/all-in-one:1.56
+    environment:
+      - COLLECTOR_OTLP_ENABLED=true
+    ports:
+      - "16686:16686"
+    networks:
+      - {{.Name}}-network
+
+  prometheus:
+    image: prom/prometheus:v2.51.2
+    command: ["--config.file=/etc/prometheus/prometheus.yml"]
+    volumes:
+      - ./deployment/observability/prometheus.yml:/etc/prometheus/prometheus.yml:ro
+    ports:
+      - "9090:9090"
+    networks:
+      - {{.Name}}-network
+
+  grafana:
+    image: grafana/grafana:10.4.2
+    environment:
+      - GF_AUTH_ANONYMOUS_ENABLED=true
+      - GF_AUTH_ANONYMOUS_ORG_ROLE=Viewer
+    volumes:
+      - ./deployment/observability/grafana/provisioning:/etc/grafana/provisioning:ro
+      - ./deployment/observability/grafana/dashboards:/var/lib/grafana/dashboards:ro
+    ports:
+      - "3000:3000"
+    depends_on:
+      - prometheus
+    networks:
+      - {{.Name}}-network
+`
+
+// ObservabilityPrometheusTemplate generates the Prometheus scrape configuration
+const ObservabilityPrometheusTemplate = `global:
+  scrape_interval: 15s
+
+scrape_configs:
+  - job_name: {{.Name}}
+    metrics_path: /metrics
+    static_configs:
+      - targets: ["app:{{.Port}}"]
+`
+
+// ObservabilityCollectorConfig is the OpenTelemetry collector configuration forwarding traces to Jaeger
+const ObservabilityCollectorConfig = `receivers:
+  otlp:
+    protocols:
+      grpc:
+        endpoint: 0.0.0.0:4317
+      http:
+        endpoint: 0.0.0.0:4318
+
+processors:
+  batch: {}
+
+exporters:
+  otlp/jaeger:
+    endpoint: jaeger:4317
+    tls:
+      insecure: true
+
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      processors: [batch]
+      exporters: [otlp/jaeger]
+`
+
+// ObservabilityGrafanaDatasources provisions the Prometheus and Jaeger data sources of Grafana
+const ObservabilityGrafanaDatasources = `apiVersion: 1
+
+datasources:
+  - name: Prometheus
+    type: prometheus
+    uid: prometheus
+    access: proxy
+    url: http://prometheus:9090
+    isDefault: true
+  - name: Jaeger
+    type: jaeger
+    uid: jaeger
+    access: proxy
+    url: http://jaeger:16686
+`
+
+// ObservabilityGrafanaDashboards provisions the dashboards directory of Grafana
+const ObservabilityGrafanaDashboards = `apiVersion: 1
+
+providers:
+  - name: default
+    type: file
+    options:
+      path: /var/lib/grafana/dashboards
+`
+
+// ObservabilityGrafanaREDDashboard is the request rate, errors and duration dashboard by route.
+// It is written as is: the legends use Grafana's own {{route}} placeholders.
+const ObservabilityGrafanaREDDashboard = `{
+  "uid": "red",
+  "title": "HTTP requests",
+  "schemaVersion": 39,
+  "time": {"from": "now-1h", "to": "now"},
+  "refresh": "30s",
+  "panels": [
+    {
+      "type": "timeseries",
+      "title": "Request rate",
+      "gridPos": {"x": 0, "y": 0, "w": 8, "h": 8},
+      "datasource": {"type": "prometheus", "uid": "prometheus"},
+      "fieldConfig": {"defaults": {"unit": "reqps"}},
+      "targets": [
+        {"expr": "sum by (route) (rate(http_server_requests_total[5m]))", "legendFormat": "{{route}}"}
+      ]
+    },
+    {
+      "type": "timeseries",
+      "title": "Error ratio",
+      "gridPos": {"x": 8, "y": 0, "w": 8, "h": 8},
+      "datasource": {"type": "prometheus", "uid": "prometheus"},
+      "fieldConfig": {"defaults": {"unit": "percentunit"}},
+      "targets": [
+        {"expr": "sum by (route) (rate(http_server_requests_total{status=~\"5..\"}[5m])) / sum by (route) (rate(http_server_requests_total[5m]))", "legendFormat": "{{route}}"}
+      ]
+    },
+    {
+      "type": "timeseries",
+      "title": "Duration p95",
+      "gridPos": {"x": 16, "y": 0, "w": 8, "h": 8},
+      "datasource": {"type": "prometheus", "uid": "prometheus"},
+      "fieldConfig": {"defaults": {"unit": "s"}},
+      "targets": [
+        {"expr": "histogram_quantile(0.95, sum by (route, le) (rate(http_server_request_duration_seconds_bucket[5m])))", "legendFormat": "{{route}}"}
+      ]
+    },
+    {
+      "type": "timeseries",
+      "title": "Database operations p95",
+      "gridPos": {"x": 0, "y": 8, "w": 24, "h": 8},
+      "datasource": {"type": "prometheus", "uid": "prometheus"},
+      "fieldConfig": {"defaults": {"unit": "s"}},
+      "targets": [
+        {"expr": "histogram_quantile(0.95, sum by (system, operation, le) (rate(db_client_operation_duration_seconds_bucket[5m])))", "legendFormat": "{{system}} {{operation}}"}
+      ]
+    }
+  ]
+}
+`
@@ -0,0 +1,1252 @@
+package templates
+
+// RelationsTemplate generates the errors and paging shared by the nested relation routes
+const RelationsTemplate = `package models
+
+import "errors"
+
+var (
+	// ErrRelationNotFound is returned when a record or its related record does not exist
+	ErrRelationNotFound = errors.New("not found")
+	// ErrHasDependents is returned when deleting a record still referenced through a relation without cascade
+	ErrHasDependents = errors.New("delete or detach them first")
+	// ErrInvalidPivot is returned when the attributes of an association are invalid
+	ErrInvalidPivot = errors.New("invalid pivot attributes")
+)
+
+// Page sizes of nested relation lists
+const (
+	DefaultRelationPageSize = 20
+	MaxRelationPageSize     = 100
+)
+
+// RelationPage returns the page and page size of a nested relation list with defaults applied
+func RelationPage(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = DefaultRelationPageSize
+	}
+	if pageSize > MaxRelationPageSize {
+		pageSize = MaxRelationPageSize
+	}
+	return page, pageSize
+}
+`
+
+// SchemaRelationModelTemplate generates the pivot attributes and list items of many-to-many relations
+const SchemaRelationModelTemplate = `package models
+
+import (
+	"time"
+
+	"{{.Module}}/internal/apperrors"
+)
+{{- range .Relations}}
+{{- if .Many}}
+{{- if .PivotFields}}
+
+// {{$.Names.PascalCase}}{{.GoName}}Pivot holds the attributes of the association of a {{$.Names.Singular}} with one of its {{.Label}}
+type {{$.Names.PascalCase}}{{.GoName}}Pivot struct {
+{{- range .PivotFields}}
+	{{.RequestField}}
+{{- end}}
+}
+
+// Validate checks the pivot attributes, reporting every rejected attribute
+func (r *{{$.Names.PascalCase}}{{.GoName}}Pivot) Validate() error {
+	var fields []apperrors.FieldError
+{{- range .PivotFields}}
+{{- with .Validation}}
+	{{.}}
+{{- end}}
+{{- end}}
+	return apperrors.Invalid(fields...)
+}
+{{- end}}
+
+// {{$.Names.PascalCase}}{{.GoName}}Item is a {{.Target.Singular}} associated with a {{$.Names.Singular}}
+type {{$.Names.PascalCase}}{{.GoName}}Item struct {
+	*{{.Target.PascalCase}}Response
+	AttachedAt time.Time ` + "`" + `json:"attached_at"` + "`" + `
+{{- if .PivotFields}}
+	Pivot      {{$.Names.PascalCase}}{{.GoName}}Pivot ` + "`" + `json:"pivot"` + "`" + `
+{{- end}}
+}
+{{- end}}
+{{- end}}
+`
+
+// relationDecorator declares the relation repository decorating the repository of the resource
+const relationDecorator = `
+// {{.Names.PascalCase}}RelationRepository decorates a {{.Names.PascalCase}} repository with the relations served below
+// the routes of {{.Names.Plural}}, and applies their cascade rules to deletes
+type {{.Names.PascalCase}}RelationRepository struct {
+	{{.Names.PascalCase}}RepositoryInterface
+`
+
+// relationDeletes routes deletes through deleteWith
+const relationDeletes = `
+// Delete deletes a {{.Names.Singular}} following the cascade rules of its relations
+func (r *{{.Names.PascalCase}}RelationRepository) Delete(ctx context.Context, id string) error {
+	return r.deleteWith(ctx, id, r.{{.Names.PascalCase}}RepositoryInterface.Delete)
+}
+
+// HardDelete permanently deletes a {{.Names.Singular}} following the cascade rules of its relations
+func (r *{{.Names.PascalCase}}RelationRepository) HardDelete(ctx context.Context, id string) error {
+	return r.deleteWith(ctx, id, r.{{.Names.PascalCase}}RepositoryInterface.HardDelete)
+}
+`
+
+// relationPivotRows declares the pivot rows of the SQL repositories and joins them with their targets
+const relationPivotRows = `
+{{- range .Relations}}
+{{- if .Many}}
+
+// {{.PivotRow}} is a row of the {{.Pivot}} table
+type {{.PivotRow}} struct {
+	TargetID  string    {{$.RowTag .TargetKey}}
+	CreatedAt time.Time {{$.RowTag "created_at"}}
+{{- range .PivotFields}}
+	{{.GoName}} {{.GoType}} {{$.RowTag .Name}}
+{{- end}}
+}
+
+// new{{$.Names.PascalCase}}{{.GoName}}Items joins a page of pivot rows with their {{.Label}}, in the order of the rows
+func new{{$.Names.PascalCase}}{{.GoName}}Items(rows []{{.PivotRow}}, targets []*models.{{.Target.PascalCase}}) []*models.{{$.Names.PascalCase}}{{.GoName}}Item {
+	byID := make(map[string]*models.{{.Target.PascalCase}}, len(targets))
+	for _, target := range targets {
+		byID[target.ID.Hex()] = target
+	}
+
+	items := make([]*models.{{$.Names.PascalCase}}{{.GoName}}Item, 0, len(rows))
+	for _, row := range rows {
+		target, ok := byID[row.TargetID]
+		if !ok {
+			// Deleted since the page was read
+			continue
+		}
+		items = append(items, &models.{{$.Names.PascalCase}}{{.GoName}}Item{
+			{{.Target.PascalCase}}Response: target.To{{.Target.PascalCase}}Response(),
+			AttachedAt: row.CreatedAt,
+{{- if .PivotFields}}
+			Pivot: models.{{$.Names.PascalCase}}{{.GoName}}Pivot{
+{{- range .PivotFields}}
+				{{.GoName}}: row.{{.GoName}},
+{{- end}}
+			},
+{{- end}}
+		})
+	}
+	return items
+}
+{{- end}}
+{{- end}}
+`
+
+// relationRepositoryInterface declares the interface of relation repositories
+const relationRepositoryInterface = `
+// {{.Names.PascalCase}}RelationRepositoryInterface is a {{.Names.PascalCase}} repository with the relations of {{.Names.Plural}}
+type {{.Names.PascalCase}}RelationRepositoryInterface interface {
+	{{.Names.PascalCase}}RepositoryInterface
+{{- range .Relations}}
+{{- if .Many}}
+	List{{.GoName}}(ctx context.Context, id string, page, pageSize int) ([]*models.{{$.Names.PascalCase}}{{.GoName}}Item, int64, error)
+	Attach{{.GoName}}(ctx context.Context, id, {{.Target.CamelCase}}ID string{{if .PivotFields}}, pivot *models.{{$.Names.PascalCase}}{{.GoName}}Pivot{{end}}) error
+	Detach{{.GoName}}(ctx context.Context, id, {{.Target.CamelCase}}ID string) error
+{{- else}}
+	List{{.GoName}}(ctx context.Context, id string, page, pageSize int) ([]*models.{{.Target.PascalCase}}, int64, error)
+{{- end}}
+{{- end}}
+}
+`
+
+// SchemaRelationGORMRepositoryTemplate generates the relation repository of GORM projects
+const SchemaRelationGORMRepositoryTemplate = `package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"{{.Module}}/internal/models"
+)
+` + relationDecorator + `	db *gorm.DB
+}
+
+// New{{.Names.PascalCase}}RelationRepository creates a {{.Names.PascalCase}} repository with the relations of {{.Names.Plural}}
+func New{{.Names.PascalCase}}RelationRepository(next {{.Names.PascalCase}}RepositoryInterface, db *gorm.DB) *{{.Names.PascalCase}}RelationRepository {
+	return &{{.Names.PascalCase}}RelationRepository{ {{- .Names.PascalCase}}RepositoryInterface: next, db: db}
+}
+{{- range .Relations}}
+{{- if .Many}}
+
+// List{{.GoName}} lists a page of the {{.Label}} of a {{$.Names.Singular}}, latest attached first
+func (r *{{$.Names.PascalCase}}RelationRepository) List{{.GoName}}(ctx context.Context, id string, page, pageSize int) ([]*models.{{$.Names.PascalCase}}{{.GoName}}Item, int64, error) {
+	var total int64
+	if err := r.db.WithContext(ctx).Raw({{printf "%q" .SQL.CountPivots}}, id).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []{{.PivotRow}}
+	if err := r.db.WithContext(ctx).Raw({{printf "%q" .SQL.ListPivots}}, id, pageSize, (page-1)*pageSize).Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var targets []*models.{{.Target.PascalCase}}
+	if len(rows) > 0 {
+		ids := make([]string, len(rows))
+		for i, row := range rows {
+			ids[i] = row.TargetID
+		}
+		if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&targets).Error; err != nil {
+			return nil, 0, err
+		}
+	}
+	return new{{$.Names.PascalCase}}{{.GoName}}Items(rows, targets), total, nil
+}
+
+// Attach{{.GoName}} associates a {{.Target.Singular}} with a {{$.Names.Singular}}, updating the attributes of an existing association
+func (r *{{$.Names.PascalCase}}RelationRepository) Attach{{.GoName}}(ctx context.Context, id, {{.Target.CamelCase}}ID string{{if .PivotFields}}, pivot *models.{{$.Names.PascalCase}}{{.GoName}}Pivot{{end}}) error {
+	var count int64
+	if err := r.db.WithContext(ctx).Raw({{printf "%q" .SQL.TargetExists}}, {{.Target.CamelCase}}ID).Scan(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("{{.Target.Singular}} %w", models.ErrRelationNotFound)
+	}
+
+	return r.db.WithContext(ctx).Exec({{printf "%q" .SQL.Attach}}, id, {{.Target.CamelCase}}ID, time.Now(){{range .PivotFields}}, pivot.{{.GoName}}{{end}}).Error
+}
+
+// Detach{{.GoName}} removes the association of a {{.Target.Singular}} with a {{$.Names.Singular}}
+func (r *{{$.Names.PascalCase}}RelationRepository) Detach{{.GoName}}(ctx context.Context, id, {{.Target.CamelCase}}ID string) error {
+	result := r.db.WithContext(ctx).Exec({{printf "%q" .SQL.Detach}}, id, {{.Target.CamelCase}}ID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("{{.Target.Singular}} %w", models.ErrRelationNotFound)
+	}
+	return nil
+}
+{{- else}}
+
+// List{{.GoName}} lists a page of the {{.Label}} of a {{$.Names.Singular}}, latest first
+func (r *{{$.Names.PascalCase}}RelationRepository) List{{.GoName}}(ctx context.Context, id string, page, pageSize int) ([]*models.{{.Target.PascalCase}}, int64, error) {
+	var total int64
+	if err := r.db.WithContext(ctx).Model(&models.{{.Target.PascalCase}}{}).Where("{{.ForeignKey}} = ?", id).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	items := []*models.{{.Target.PascalCase}}{}
+	err := r.db.WithContext(ctx).
+		Where("{{.ForeignKey}} = ?", id).
+		Order("created_at DESC, id").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&items).Error
+	return items, total, err
+}
+{{- end}}
+{{- end}}
+` + relationDeletes + `
+// deleteWith refuses to delete a {{.Names.Singular}} still referenced through relations without cascade,
+// and deletes the dependents of cascading relations before the {{.Names.Singular}}
+func (r *{{.Names.PascalCase}}RelationRepository) deleteWith(ctx context.Context, id string, del func(ctx context.Context, id string) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+{{- range .Relations}}
+{{- if not .Cascade}}
+		if err := r.restrict(tx, {{printf "%q" .SQL.CountDependents}}, "{{.Label}}", id); err != nil {
+			return err
+		}
+{{- end}}
+{{- end}}
+{{- range .Relations}}
+{{- if .Cascade}}
+		if err := tx.Exec({{printf "%q" .SQL.DeleteDependents}}, id).Error; err != nil {
+			return fmt.Errorf("failed to delete the {{.Label}} of the {{$.Names.Singular}}: %w", err)
+		}
+{{- end}}
+{{- end}}
+		return del(ctx, id)
+	})
+}
+{{- if .HasRestricted}}
+
+// restrict fails with ErrHasDependents when the count query finds records referencing the {{.Names.Singular}}
+func (r *{{.Names.PascalCase}}RelationRepository) restrict(tx *gorm.DB, query, dependents, id string) error {
+	var count int64
+	if err := tx.Raw(query, id).Scan(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("{{.Names.Singular}} still has %d %s: %w", count, dependents, models.ErrHasDependents)
+	}
+	return nil
+}
+{{- end}}
+` + relationPivotRows + relationRepositoryInterface
+
+// SchemaRelationSQLXRepositoryTemplate generates the relation repository of sqlx projects
+const SchemaRelationSQLXRepositoryTemplate = `package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"{{.Module}}/internal/models"
+)
+` + relationDecorator + `	db *sqlx.DB
+}
+
+// New{{.Names.PascalCase}}RelationRepository creates a {{.Names.PascalCase}} repository with the relations of {{.Names.Plural}}
+func New{{.Names.PascalCase}}RelationRepository(next {{.Names.PascalCase}}RepositoryInterface, db *sqlx.DB) *{{.Names.PascalCase}}RelationRepository {
+	return &{{.Names.PascalCase}}RelationRepository{ {{- .Names.PascalCase}}RepositoryInterface: next, db: db}
+}
+{{- range .Relations}}
+{{- if .Many}}
+
+// List{{.GoName}} lists a page of the {{.Label}} of a {{$.Names.Singular}}, latest attached first
+func (r *{{$.Names.PascalCase}}RelationRepository) List{{.GoName}}(ctx context.Context, id string, page, pageSize int) ([]*models.{{$.Names.PascalCase}}{{.GoName}}Item, int64, error) {
+	var total int64
+	if err := sqlx.GetContext(ctx, r.conn(ctx), &total, r.db.Rebind({{printf "%q" .SQL.CountPivots}}), id); err != nil {
+		return nil, 0, err
+	}
+
+	var rows []{{.PivotRow}}
+	if err := sqlx.SelectContext(ctx, r.conn(ctx), &rows, r.db.Rebind({{printf "%q" .SQL.ListPivots}}), id, pageSize, (page-1)*pageSize); err != nil {
+		return nil, 0, err
+	}
+
+	var targets []*models.{{.Target.PascalCase}}
+	if len(rows) > 0 {
+		ids := make([]string, len(rows))
+		for i, row := range rows {
+			ids[i] = row.TargetID
+		}
+		query, args, err := sqlx.In({{.ListQuery}}+{{printf "%q" .SQL.TargetsIn}}, ids)
+		if err != nil {
+			return nil, 0, err
+		}
+		var targetRows []{{.TargetRowType}}
+		if err := sqlx.SelectContext(ctx, r.conn(ctx), &targetRows, r.db.Rebind(query), args...); err != nil {
+			return nil, 0, err
+		}
+		if targets, err = to{{.Target.PascalPlural}}(targetRows); err != nil {
+			return nil, 0, err
+		}
+	}
+	return new{{$.Names.PascalCase}}{{.GoName}}Items(rows, targets), total, nil
+}
+
+// Attach{{.GoName}} associates a {{.Target.Singular}} with a {{$.Names.Singular}}, updating the attributes of an existing association
+func (r *{{$.Names.PascalCase}}RelationRepository) Attach{{.GoName}}(ctx context.Context, id, {{.Target.CamelCase}}ID string{{if .PivotFields}}, pivot *models.{{$.Names.PascalCase}}{{.GoName}}Pivot{{end}}) error {
+	var count int64
+	if err := sqlx.GetContext(ctx, r.conn(ctx), &count, r.db.Rebind({{printf "%q" .SQL.TargetExists}}), {{.Target.CamelCase}}ID); err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("{{.Target.Singular}} %w", models.ErrRelationNotFound)
+	}
+
+	_, err := r.conn(ctx).ExecContext(ctx, r.db.Rebind({{printf "%q" .SQL.Attach}}), id, {{.Target.CamelCase}}ID, time.Now(){{range .PivotFields}}, pivot.{{.GoName}}{{end}})
+	return err
+}
+
+// Detach{{.GoName}} removes the association of a {{.Target.Singular}} with a {{$.Names.Singular}}
+func (r *{{$.Names.PascalCase}}RelationRepository) Detach{{.GoName}}(ctx context.Context, id, {{.Target.CamelCase}}ID string) error {
+	result, err := r.conn(ctx).ExecContext(ctx, r.db.Rebind({{printf "%q" .SQL.Detach}}), id, {{.Target.CamelCase}}ID)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return fmt.Errorf("{{.Target.Singular}} %w", models.ErrRelationNotFound)
+	}
+	return nil
+}
+{{- else}}
+
+// List{{.GoName}} lists a page of the {{.Label}} of a {{$.Names.Singular}}, latest first
+func (r *{{$.Names.PascalCase}}RelationRepository) List{{.GoName}}(ctx context.Context, id string, page, pageSize int) ([]*models.{{.Target.PascalCase}}, int64, error) {
+	var total int64
+	if err := sqlx.GetContext(ctx, r.conn(ctx), &total, r.db.Rebind({{.CountQuery}}+{{printf "%q" .SQL.ChildrenWhere}}), id); err != nil {
+		return nil, 0, err
+	}
+
+	var rows []{{.TargetRowType}}
+	if err := sqlx.SelectContext(ctx, r.conn(ctx), &rows, r.db.Rebind({{.ListQuery}}+{{printf "%q" (print .SQL.ChildrenWhere .SQL.ChildrenPage)}}), id, pageSize, (page-1)*pageSize); err != nil {
+		return nil, 0, err
+	}
+
+	items, err := to{{.Target.PascalPlural}}(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+{{- end}}
+{{- end}}
+` + relationDeletes + `
+// deleteWith refuses to delete a {{.Names.Singular}} still referenced through relations without cascade,
+// and deletes the dependents of cascading relations in the transaction deleting the {{.Names.Singular}}
+func (r *{{.Names.PascalCase}}RelationRepository) deleteWith(ctx context.Context, id string, del func(ctx context.Context, id string) error) error {
+	return NewTransactor(r.db).WithinTransaction(ctx, func(ctx context.Context) error {
+{{- range .Relations}}
+{{- if not .Cascade}}
+		if err := r.restrict(ctx, {{printf "%q" .SQL.CountDependents}}, "{{.Label}}", id); err != nil {
+			return err
+		}
+{{- end}}
+{{- end}}
+{{- range .Relations}}
+{{- if .Cascade}}
+		if _, err := r.conn(ctx).ExecContext(ctx, r.db.Rebind({{printf "%q" .SQL.DeleteDependents}}), id); err != nil {
+			return fmt.Errorf("failed to delete the {{.Label}} of the {{$.Names.Singular}}: %w", err)
+		}
+{{- end}}
+{{- end}}
+		return del(ctx, id)
+	})
+}
+{{- if .HasRestricted}}
+
+// restrict fails with ErrHasDependents when the count query finds records referencing the {{.Names.Singular}}
+func (r *{{.Names.PascalCase}}RelationRepository) restrict(ctx context.Context, query, dependents, id string) error {
+	var count int64
+	if err := sqlx.GetContext(ctx, r.conn(ctx), &count, r.db.Rebind(query), id); err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("{{.Names.Singular}} still has %d %s: %w", count, dependents, models.ErrHasDependents)
+	}
+	return nil
+}
+{{- end}}
+
+// conn returns the transaction of the context, or the database outside transactions
+func (r *{{.Names.PascalCase}}RelationRepository) conn(ctx context.Context) sqlx.ExtContext {
+	return connFromContext(ctx, r.db)
+}
+` + relationPivotRows + relationRepositoryInterface
+
+// SchemaRelationPGXRepositoryTemplate generates the relation repository of pgx and sqlc projects,
+// running its statements on the pool with the rows the resource repositories scan into
+const SchemaRelationPGXRepositoryTemplate = `package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"{{.Module}}/internal/db"
+	"{{.Module}}/internal/models"
+)
+` + relationDecorator + `	pool *pgxpool.Pool
+}
+
+// New{{.Names.PascalCase}}RelationRepository creates a {{.Names.PascalCase}} repository with the relations of {{.Names.Plural}}
+func New{{.Names.PascalCase}}RelationRepository(next {{.Names.PascalCase}}RepositoryInterface, pool *pgxpool.Pool) *{{.Names.PascalCase}}RelationRepository {
+	return &{{.Names.PascalCase}}RelationRepository{ {{- .Names.PascalCase}}RepositoryInterface: next, pool: pool}
+}
+{{- range .Relations}}
+{{- if .Many}}
+
+// List{{.GoName}} lists a page of the {{.Label}} of a {{$.Names.Singular}}, latest attached first
+func (r *{{$.Names.PascalCase}}RelationRepository) List{{.GoName}}(ctx context.Context, id string, page, pageSize int) ([]*models.{{$.Names.PascalCase}}{{.GoName}}Item, int64, error) {
+	var total int64
+	if err := r.conn(ctx).QueryRow(ctx, {{printf "%q" .SQL.CountPivots}}, id).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	result, err := r.conn(ctx).Query(ctx, {{printf "%q" .SQL.ListPivots}}, id, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	rows, err := pgx.CollectRows(result, pgx.RowToStructByName[{{.PivotRow}}])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var targets []*models.{{.Target.PascalCase}}
+	if len(rows) > 0 {
+		ids := make([]string, len(rows))
+		for i, row := range rows {
+			ids[i] = row.TargetID
+		}
+		result, err := r.conn(ctx).Query(ctx, {{.ListQuery}}+{{printf "%q" .SQL.TargetsIn}}, ids)
+		if err != nil {
+			return nil, 0, err
+		}
+		targetRows, err := pgx.CollectRows(result, pgx.RowToStructByName[{{.TargetRowType}}])
+		if err != nil {
+			return nil, 0, err
+		}
+		if targets, err = to{{.Target.PascalPlural}}(targetRows); err != nil {
+			return nil, 0, err
+		}
+	}
+	return new{{$.Names.PascalCase}}{{.GoName}}Items(rows, targets), total, nil
+}
+
+// Attach{{.GoName}} associates a {{.Target.Singular}} with a {{$.Names.Singular}}, updating the attributes of an existing association
+func (r *{{$.Names.PascalCase}}RelationRepository) Attach{{.GoName}}(ctx context.Context, id, {{.Target.CamelCase}}ID string{{if .PivotFields}}, pivot *models.{{$.Names.PascalCase}}{{.GoName}}Pivot{{end}}) error {
+	var count int64
+	if err := r.conn(ctx).QueryRow(ctx, {{printf "%q" .SQL.TargetExists}}, {{.Target.CamelCase}}ID).Scan(&count); err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("{{.Target.Singular}} %w", models.ErrRelationNotFound)
+	}
+
+	_, err := r.conn(ctx).Exec(ctx, {{printf "%q" .SQL.Attach}}, id, {{.Target.CamelCase}}ID, time.Now(){{range .PivotFields}}, pivot.{{.GoName}}{{end}})
+	return err
+}
+
+// Detach{{.GoName}} removes the association of a {{.Target.Singular}} with a {{$.Names.Singular}}
+func (r *{{$.Names.PascalCase}}RelationRepository) Detach{{.GoName}}(ctx context.Context, id, {{.Target.CamelCase}}ID string) error {
+	tag, err := r.conn(ctx).Exec(ctx, {{printf "%q" .SQL.Detach}}, id, {{.Target.CamelCase}}ID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("{{.Target.Singular}} %w", models.ErrRelationNotFound)
+	}
+	return nil
+}
+{{- else}}
+
+// List{{.GoName}} lists a page of the {{.Label}} of a {{$.Names.Singular}}, latest first
+func (r *{{$.Names.PascalCase}}RelationRepository) List{{.GoName}}(ctx context.Context, id string, page, pageSize int) ([]*models.{{.Target.PascalCase}}, int64, error) {
+	var total int64
+	if err := r.conn(ctx).QueryRow(ctx, {{.CountQuery}}+{{printf "%q" .SQL.ChildrenWhere}}, id).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	result, err := r.conn(ctx).Query(ctx, {{.ListQuery}}+{{printf "%q" (print .SQL.ChildrenWhere .SQL.ChildrenPage)}}, id, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	rows, err := pgx.CollectRows(result, pgx.RowToStructByName[{{.TargetRowType}}])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	items, err := to{{.Target.PascalPlural}}(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+{{- end}}
+{{- end}}
+` + relationDeletes + `
+// deleteWith refuses to delete a {{.Names.Singular}} still referenced through relations without cascade,
+// and deletes the dependents of cascading relations in the transaction deleting the {{.Names.Singular}}
+func (r *{{.Names.PascalCase}}RelationRepository) deleteWith(ctx context.Context, id string, del func(ctx context.Context, id string) error) error {
+	return NewTransactor(r.pool).WithinTransaction(ctx, func(ctx context.Context) error {
+{{- range .Relations}}
+{{- if not .Cascade}}
+		if err := r.restrict(ctx, {{printf "%q" .SQL.CountDependents}}, "{{.Label}}", id); err != nil {
+			return err
+		}
+{{- end}}
+{{- end}}
+{{- range .Relations}}
+{{- if .Cascade}}
+		if _, err := r.conn(ctx).Exec(ctx, {{printf "%q" .SQL.DeleteDependents}}, id); err != nil {
+			return fmt.Errorf("failed to delete the {{.Label}} of the {{$.Names.Singular}}: %w", err)
+		}
+{{- end}}
+{{- end}}
+		return del(ctx, id)
+	})
+}
+{{- if .HasRestricted}}
+
+// restrict fails with ErrHasDependents when the count query finds records referencing the {{.Names.Singular}}
+func (r *{{.Names.PascalCase}}RelationRepository) restrict(ctx context.Context, query, dependents, id string) error {
+	var count int64
+	if err := r.conn(ctx).QueryRow(ctx, query, id).Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("{{.Names.Singular}} still has %d %s: %w", count, dependents, models.ErrHasDependents)
+	}
+	return nil
+}
+{{- end}}
+
+// conn returns the transaction of the context, or the pool outside transactions
+func (r *{{.Names.PascalCase}}RelationRepository) conn(ctx context.Context) pgxConn {
+	return connFromContext(ctx, r.pool)
+}
+` + relationPivotRows + relationRepositoryInterface
+
+// SchemaRelationMongoRepositoryTemplate generates the relation repository of MongoDB projects.
+// Pivot documents reference both sides by ObjectID, deletes run without a transaction.
+const SchemaRelationMongoRepositoryTemplate = `package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"{{.Module}}/internal/models"
+)
+` + relationDecorator + `	db *mongo.Database
+}
+
+// New{{.Names.PascalCase}}RelationRepository creates a {{.Names.PascalCase}} repository with the relations of {{.Names.Plural}}
+func New{{.Names.PascalCase}}RelationRepository(next {{.Names.PascalCase}}RepositoryInterface, db *mongo.Database) *{{.Names.PascalCase}}RelationRepository {
+	return &{{.Names.PascalCase}}RelationRepository{ {{- .Names.PascalCase}}RepositoryInterface: next, db: db}
+}
+{{- if .HasManyToMany}}
+
+// EnsureRelationIndexes creates the unique indexes of the pivot collections
+func (r *{{.Names.PascalCase}}RelationRepository) EnsureRelationIndexes(ctx context.Context) error {
+{{- range .Relations}}
+{{- if .Many}}
+	if _, err := r.db.Collection("{{.Pivot}}").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{ {Key: "{{.ParentKey}}", Value: 1}, {Key: "{{.TargetKey}}", Value: 1} }, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{ {Key: "{{.TargetKey}}", Value: 1} }},
+	}); err != nil {
+		return fmt.Errorf("failed to create the {{.Pivot}} indexes: %w", err)
+	}
+{{- end}}
+{{- end}}
+	return nil
+}
+{{- end}}
+{{- range .Relations}}
+{{- if .Many}}
+
+// {{.PivotRow}} is a document of the {{.Pivot}} collection joined with its {{.Target.Singular}}
+type {{.PivotRow}} struct {
+	Target    models.{{.Target.PascalCase}} ` + "`" + `bson:"target"` + "`" + `
+	CreatedAt time.Time {{$.RowTag "created_at"}}
+{{- range .PivotFields}}
+	{{.GoName}} {{.GoType}} {{$.RowTag .Name}}
+{{- end}}
+}
+
+// List{{.GoName}} lists a page of the {{.Label}} of a {{$.Names.Singular}}, latest attached first.
+// Pivot documents of deleted {{.Label}} are left out until they are detached.
+func (r *{{$.Names.PascalCase}}RelationRepository) List{{.GoName}}(ctx context.Context, id string, page, pageSize int) ([]*models.{{$.Names.PascalCase}}{{.GoName}}Item, int64, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid ID format: %w", err)
+	}
+
+	pipeline := mongo.Pipeline{
+		{ {Key: "$match", Value: bson.M{"{{.ParentKey}}": objectID}} },
+		{ {Key: "$lookup", Value: bson.M{"from": "{{.Target.TableName}}", "localField": "{{.TargetKey}}", "foreignField": "_id", "as": "target"}} },
+		{ {Key: "$unwind", Value: "$target"} },
+		{ {Key: "$sort", Value: bson.D{ {Key: "created_at", Value: -1}, {Key: "_id", Value: -1} }} },
+		{ {Key: "$facet", Value: bson.M{
+			"items": bson.A{bson.M{"$skip": (page - 1) * pageSize}, bson.M{"$limit": pageSize}},
+			"total": bson.A{bson.M{"$count": "n"}},
+		}} },
+	}
+	cursor, err := r.db.Collection("{{.Pivot}}").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var pages []struct {
+		Items []{{.PivotRow}} ` + "`" + `bson:"items"` + "`" + `
+		Total []struct {
+			N int64 ` + "`" + `bson:"n"` + "`" + `
+		} ` + "`" + `bson:"total"` + "`" + `
+	}
+	if err := cursor.All(ctx, &pages); err != nil {
+		return nil, 0, err
+	}
+
+	items := []*models.{{$.Names.PascalCase}}{{.GoName}}Item{}
+	var total int64
+	if len(pages) > 0 {
+		if len(pages[0].Total) > 0 {
+			total = pages[0].Total[0].N
+		}
+		for _, doc := range pages[0].Items {
+			target := doc.Target
+			items = append(items, &models.{{$.Names.PascalCase}}{{.GoName}}Item{
+				{{.Target.PascalCase}}Response: target.To{{.Target.PascalCase}}Response(),
+				AttachedAt: doc.CreatedAt,
+{{- if .PivotFields}}
+				Pivot: models.{{$.Names.PascalCase}}{{.GoName}}Pivot{
+{{- range .PivotFields}}
+					{{.GoName}}: doc.{{.GoName}},
+{{- end}}
+				},
+{{- end}}
+			})
+		}
+	}
+	return items, total, nil
+}
+
+// Attach{{.GoName}} associates a {{.Target.Singular}} with a {{$.Names.Singular}}, updating the attributes of an existing association
+func (r *{{$.Names.PascalCase}}RelationRepository) Attach{{.GoName}}(ctx context.Context, id, {{.Target.CamelCase}}ID string{{if .PivotFields}}, pivot *models.{{$.Names.PascalCase}}{{.GoName}}Pivot{{end}}) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %w", err)
+	}
+	targetID, err := primitive.ObjectIDFromHex({{.Target.CamelCase}}ID)
+	if err != nil {
+		return fmt.Errorf("{{.Target.Singular}} %w", models.ErrRelationNotFound)
+	}
+	count, err := r.db.Collection("{{.Target.TableName}}").CountDocuments(ctx, bson.M{"_id": targetID})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("{{.Target.Singular}} %w", models.ErrRelationNotFound)
+	}
+
+	update := bson.M{"$setOnInsert": bson.M{"created_at": time.Now()}}
+{{- if .PivotFields}}
+	update["$set"] = bson.M{
+{{- range .PivotFields}}
+		"{{.Name}}": pivot.{{.GoName}},
+{{- end}}
+	}
+{{- end}}
+	_, err = r.db.Collection("{{.Pivot}}").UpdateOne(ctx,
+		bson.M{"{{.ParentKey}}": objectID, "{{.TargetKey}}": targetID}, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// Detach{{.GoName}} removes the association of a {{.Target.Singular}} with a {{$.Names.Singular}}
+func (r *{{$.Names.PascalCase}}RelationRepository) Detach{{.GoName}}(ctx context.Context, id, {{.Target.CamelCase}}ID string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %w", err)
+	}
+	targetID, err := primitive.ObjectIDFromHex({{.Target.CamelCase}}ID)
+	if err != nil {
+		return fmt.Errorf("{{.Target.Singular}} %w", models.ErrRelationNotFound)
+	}
+
+	result, err := r.db.Collection("{{.Pivot}}").DeleteOne(ctx, bson.M{"{{.ParentKey}}": objectID, "{{.TargetKey}}": targetID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("{{.Target.Singular}} %w", models.ErrRelationNotFound)
+	}
+	return nil
+}
+{{- else}}
+
+// List{{.GoName}} lists a page of the {{.Label}} of a {{$.Names.Singular}}, latest first
+func (r *{{$.Names.PascalCase}}RelationRepository) List{{.GoName}}(ctx context.Context, id string, page, pageSize int) ([]*models.{{.Target.PascalCase}}, int64, error) {
+	collection := r.db.Collection("{{.Target.TableName}}")
+	filter := bson.M{"{{.ForeignKey}}": id}
+
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{ {Key: "created_at", Value: -1}, {Key: "_id", Value: -1} }).
+		SetSkip(int64((page - 1) * pageSize)).
+		SetLimit(int64(pageSize))
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	items := []*models.{{.Target.PascalCase}}{}
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+{{- end}}
+{{- end}}
+` + relationDeletes + `
+// deleteWith refuses to delete a {{.Names.Singular}} still referenced through relations without cascade,
+// then deletes the dependents of cascading relations and the {{.Names.Singular}}. MongoDB runs the
+// deletes one after the other, a failure leaves the dependents deleted so far.
+func (r *{{.Names.PascalCase}}RelationRepository) deleteWith(ctx context.Context, id string, del func(ctx context.Context, id string) error) error {
+{{- if .HasManyToMany}}
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid ID format: %w", err)
+	}
+{{- end}}
+{{- range .Relations}}
+{{- if not .Cascade}}
+	if err := r.restrict(ctx, "{{if .Many}}{{.Pivot}}{{else}}{{.Target.TableName}}{{end}}", {{template "relationMongoFilter" .}}, "{{.Label}}"); err != nil {
+		return err
+	}
+{{- end}}
+{{- end}}
+{{- range .Relations}}
+{{- if .Cascade}}
+	if _, err := r.db.Collection("{{if .Many}}{{.Pivot}}{{else}}{{.Target.TableName}}{{end}}").DeleteMany(ctx, {{template "relationMongoFilter" .}}); err != nil {
+		return fmt.Errorf("failed to delete the {{.Label}} of the {{$.Names.Singular}}: %w", err)
+	}
+{{- end}}
+{{- end}}
+	return del(ctx, id)
+}
+{{- if .HasRestricted}}
+
+// restrict fails with ErrHasDependents when the collection has documents referencing the {{.Names.Singular}}
+func (r *{{.Names.PascalCase}}RelationRepository) restrict(ctx context.Context, collection string, filter bson.M, dependents string) error {
+	count, err := r.db.Collection(collection).CountDocuments(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("{{.Names.Singular}} still has %d %s: %w", count, dependents, models.ErrHasDependents)
+	}
+	return nil
+}
+{{- end}}
+` + relationRepositoryInterface + `
+{{- define "relationMongoFilter"}}bson.M{"{{if .Many}}{{.ParentKey}}{{else}}{{.ForeignKey}}{{end}}": {{if .Many}}objectID{{else}}id{{end}}}{{end}}`
+
+// SchemaRelationServiceTemplate generates the service of the nested relation routes
+const SchemaRelationServiceTemplate = `package services
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"{{.Module}}/internal/models"
+	"{{.Module}}/internal/repositories"
+)
+
+// {{.Names.PascalCase}}RelationService serves the relations of {{.Names.Plural}}
+type {{.Names.PascalCase}}RelationService struct {
+	repo repositories.{{.Names.PascalCase}}RelationRepositoryInterface
+}
+
+// New{{.Names.PascalCase}}RelationService creates a new {{.Names.PascalCase}} relation service
+func New{{.Names.PascalCase}}RelationService(repo repositories.{{.Names.PascalCase}}RelationRepositoryInterface) *{{.Names.PascalCase}}RelationService {
+	return &{{.Names.PascalCase}}RelationService{repo: repo}
+}
+{{- range .Relations}}
+{{- if .Many}}
+
+// List{{.GoName}} lists a page of the {{.Label}} of a {{$.Names.Singular}}
+func (s *{{$.Names.PascalCase}}RelationService) List{{.GoName}}(ctx context.Context, id string, page, pageSize int) ([]*models.{{$.Names.PascalCase}}{{.GoName}}Item, int64, error) {
+	if err := s.ensureExists(ctx, id); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.List{{.GoName}}(ctx, id, page, pageSize)
+}
+
+// Attach{{.GoName}} associates a {{.Target.Singular}} with a {{$.Names.Singular}}
+func (s *{{$.Names.PascalCase}}RelationService) Attach{{.GoName}}(ctx context.Context, id, {{.Target.CamelCase}}ID string{{if .PivotFields}}, pivot *models.{{$.Names.PascalCase}}{{.GoName}}Pivot{{end}}) error {
+{{- if .PivotFields}}
+	if err := pivot.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", models.ErrInvalidPivot, err)
+	}
+{{- end}}
+	if err := s.ensureExists(ctx, id); err != nil {
+		return err
+	}
+	if _, err := primitive.ObjectIDFromHex({{.Target.CamelCase}}ID); err != nil {
+		return fmt.Errorf("{{.Target.Singular}} %w", models.ErrRelationNotFound)
+	}
+	return s.repo.Attach{{.GoName}}(ctx, id, {{.Target.CamelCase}}ID{{if .PivotFields}}, pivot{{end}})
+}
+
+// Detach{{.GoName}} removes the association of a {{.Target.Singular}} with a {{$.Names.Singular}}
+func (s *{{$.Names.PascalCase}}RelationService) Detach{{.GoName}}(ctx context.Context, id, {{.Target.CamelCase}}ID string) error {
+	if err := s.ensureExists(ctx, id); err != nil {
+		return err
+	}
+	if _, err := primitive.ObjectIDFromHex({{.Target.CamelCase}}ID); err != nil {
+		return fmt.Errorf("{{.Target.Singular}} %w", models.ErrRelationNotFound)
+	}
+	return s.repo.Detach{{.GoName}}(ctx, id, {{.Target.CamelCase}}ID)
+}
+{{- else}}
+
+// List{{.GoName}} lists a page of the {{.Label}} of a {{$.Names.Singular}}
+func (s *{{$.Names.PascalCase}}RelationService) List{{.GoName}}(ctx context.Context, id string, page, pageSize int) ([]*models.{{.Target.PascalCase}}, int64, error) {
+	if err := s.ensureExists(ctx, id); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.List{{.GoName}}(ctx, id, page, pageSize)
+}
+{{- end}}
+{{- end}}
+
+// ensureExists fails with ErrRelationNotFound unless the {{.Names.Singular}} exists
+func (s *{{.Names.PascalCase}}RelationService) ensureExists(ctx context.Context, id string) error {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return fmt.Errorf("{{.Names.Singular}} %w", models.ErrRelationNotFound)
+	}
+	exists, err := s.repo.Exists(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get {{.Names.Singular}}: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("{{.Names.Singular}} %w", models.ErrRelationNotFound)
+	}
+	return nil
+}
+
+// {{.Names.PascalCase}}RelationServiceInterface is the service interface of the relation routes
+type {{.Names.PascalCase}}RelationServiceInterface interface {
+{{- range .Relations}}
+{{- if .Many}}
+	List{{.GoName}}(ctx context.Context, id string, page, pageSize int) ([]*models.{{$.Names.PascalCase}}{{.GoName}}Item, int64, error)
+	Attach{{.GoName}}(ctx context.Context, id, {{.Target.CamelCase}}ID string{{if .PivotFields}}, pivot *models.{{$.Names.PascalCase}}{{.GoName}}Pivot{{end}}) error
+	Detach{{.GoName}}(ctx context.Context, id, {{.Target.CamelCase}}ID string) error
+{{- else}}
+	List{{.GoName}}(ctx context.Context, id string, page, pageSize int) ([]*models.{{.Target.PascalCase}}, int64, error)
+{{- end}}
+{{- end}}
+}
+`
+
+// SchemaRelationHandlerTemplate generates the handlers of the nested relation routes
+const SchemaRelationHandlerTemplate = `package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+{{range .HTTP.Imports}}	"{{.}}"
+{{end}}	"{{.Module}}/internal/models"
+	"{{.Module}}/internal/services"
+)
+
+// {{.Names.PascalCase}}RelationHandler handles the nested relation routes of {{.DisplayName}}
+type {{.Names.PascalCase}}RelationHandler struct {
+	service services.{{.Names.PascalCase}}RelationServiceInterface
+}
+
+// New{{.Names.PascalCase}}RelationHandler creates a new {{.Names.PascalCase}} relation handler
+func New{{.Names.PascalCase}}RelationHandler(service services.{{.Names.PascalCase}}RelationServiceInterface) *{{.Names.PascalCase}}RelationHandler {
+	return &{{.Names.PascalCase}}RelationHandler{service: service}
+}
+{{- range .Relations}}
+
+// List{{.GoName}} handles GET /{{$.Names.KebabPlural}}/:id/{{.Route}}
+func (h *{{$.Names.PascalCase}}RelationHandler) List{{.GoName}}({{$.HTTP.HandlerParams}}){{$.HTTP.HandlerResult}} {
+	id := {{$.HTTP.Param "id"}}
+	page, _ := strconv.Atoi({{$.HTTP.Query "page"}})
+	pageSize, _ := strconv.Atoi({{$.HTTP.Query "page_size"}})
+	page, pageSize = models.RelationPage(page, pageSize)
+
+	items, total, err := h.service.List{{.GoName}}({{$.HTTP.Context}}, id, page, pageSize)
+	if err != nil {
+		{{$.HTTP.Fail "h.status(err)" "err.Error()"}}
+	}
+{{- if not .Many}}
+
+	responses := make([]*models.{{.Target.PascalCase}}Response, len(items))
+	for i, item := range items {
+		responses[i] = item.To{{.Target.PascalCase}}Response()
+	}
+{{- end}}
+
+	{{$.HTTP.Reply "http.StatusOK"}}{{$.HTTP.Map}}{
+		"data":      {{if .Many}}items{{else}}responses{{end}},
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+{{- if .Many}}
+
+// Attach{{.GoName}} handles PUT /{{$.Names.KebabPlural}}/:id/{{.Route}}/:{{.TargetParam}}
+func (h *{{$.Names.PascalCase}}RelationHandler) Attach{{.GoName}}({{$.HTTP.HandlerParams}}){{$.HTTP.HandlerResult}} {
+	id := {{$.HTTP.Param "id"}}
+	{{.Target.CamelCase}}ID := {{$.HTTP.Param .TargetParam}}
+{{- if .PivotFields}}
+
+	var pivot models.{{$.Names.PascalCase}}{{.GoName}}Pivot
+	if err := {{$.HTTP.BindJSON "&pivot"}}; err != nil {
+		{{$.HTTP.Fail "http.StatusBadRequest" "err.Error()"}}
+	}
+{{- end}}
+
+	if err := h.service.Attach{{.GoName}}({{$.HTTP.Context}}, id, {{.Target.CamelCase}}ID{{if .PivotFields}}, &pivot{{end}}); err != nil {
+		{{$.HTTP.Fail "h.status(err)" "err.Error()"}}
+	}
+
+	{{$.HTTP.Reply "http.StatusOK"}}{{$.HTTP.Map}}{"message": "{{$.DisplayName}} {{.Target.Singular}} attached successfully"})
+}
+
+// Detach{{.GoName}} handles DELETE /{{$.Names.KebabPlural}}/:id/{{.Route}}/:{{.TargetParam}}
+func (h *{{$.Names.PascalCase}}RelationHandler) Detach{{.GoName}}({{$.HTTP.HandlerParams}}){{$.HTTP.HandlerResult}} {
+	id := {{$.HTTP.Param "id"}}
+	{{.Target.CamelCase}}ID := {{$.HTTP.Param .TargetParam}}
+
+	if err := h.service.Detach{{.GoName}}({{$.HTTP.Context}}, id, {{.Target.CamelCase}}ID); err != nil {
+		{{$.HTTP.Fail "h.status(err)" "err.Error()"}}
+	}
+
+	{{$.HTTP.Reply "http.StatusOK"}}{{$.HTTP.Map}}{"message": "{{$.DisplayName}} {{.Target.Singular}} detached successfully"})
+}
+{{- end}}
+{{- end}}
+
+// status returns the HTTP status of a relation error
+func (h *{{.Names.PascalCase}}RelationHandler) status(err error) int {
+	switch {
+	case errors.Is(err, models.ErrRelationNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrInvalidPivot):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Setup{{.Names.PascalCase}}RelationRoutes sets up the nested relation routes of {{.DisplayName}}
+func Setup{{.Names.PascalCase}}RelationRoutes({{.HTTP.Router}}, handler *{{.Names.PascalCase}}RelationHandler) {
+{{- range .Relations}}
+	{{$.HTTP.Root.Route "GET" (printf "/%s/:id/%s" $.Names.KebabPlural .Route) (printf "handler.List%s" .GoName)}}
+{{- if .Many}}
+	{{$.HTTP.Root.Route "PUT" (printf "/%s/:id/%s/:%s" $.Names.KebabPlural .Route .TargetParam) (printf "handler.Attach%s" .GoName)}}
+	{{$.HTTP.Root.Route "DELETE" (printf "/%s/:id/%s/:%s" $.Names.KebabPlural .Route .TargetParam) (printf "handler.Detach%s" .GoName)}}
+{{- end}}
+{{- end}}
+}
+`
+
+// SchemaRelationHandlerTestTemplate generates HTTP tests of the nested relation routes against an in-memory service
+const SchemaRelationHandlerTestTemplate = `package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+{{range .HTTP.Imports}}	"{{.}}"
+{{end}}	"go.mongodb.org/mongo-driver/bson/primitive"
+	"{{.Module}}/internal/models"
+	"{{.Module}}/internal/services"
+)
+
+// fake{{.Names.PascalCase}}RelationService keeps associations in memory so the routes can be tested without a database
+type fake{{.Names.PascalCase}}RelationService struct {
+	mu       sync.Mutex
+	parents  map[string]bool
+	attached map[string]bool // Keyed by relation, {{.Names.Singular}} and related ID
+}
+
+func newFake{{.Names.PascalCase}}RelationService(parents ...string) *fake{{.Names.PascalCase}}RelationService {
+	s := &fake{{.Names.PascalCase}}RelationService{parents: make(map[string]bool), attached: make(map[string]bool)}
+	for _, id := range parents {
+		s.parents[id] = true
+	}
+	return s
+}
+
+func (s *fake{{.Names.PascalCase}}RelationService) count(relation, id string) int64 {
+	var n int64
+	for key := range s.attached {
+		if strings.HasPrefix(key, relation+"/"+id+"/") {
+			n++
+		}
+	}
+	return n
+}
+{{- range .Relations}}
+{{- if .Many}}
+
+func (s *fake{{$.Names.PascalCase}}RelationService) List{{.GoName}}(ctx context.Context, id string, page, pageSize int) ([]*models.{{$.Names.PascalCase}}{{.GoName}}Item, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.parents[id] {
+		return nil, 0, fmt.Errorf("{{$.Names.Singular}} %w", models.ErrRelationNotFound)
+	}
+	total := s.count("{{.Route}}", id)
+	items := make([]*models.{{$.Names.PascalCase}}{{.GoName}}Item, 0, total)
+	for i := int64(0); i < total; i++ {
+		items = append(items, &models.{{$.Names.PascalCase}}{{.GoName}}Item{ {{- .Target.PascalCase}}Response: (&models.{{.Target.PascalCase}}{}).To{{.Target.PascalCase}}Response()})
+	}
+	return items, total, nil
+}
+
+func (s *fake{{$.Names.PascalCase}}RelationService) Attach{{.GoName}}(ctx context.Context, id, {{.Target.CamelCase}}ID string{{if .PivotFields}}, pivot *models.{{$.Names.PascalCase}}{{.GoName}}Pivot{{end}}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.parents[id] {
+		return fmt.Errorf("{{$.Names.Singular}} %w", models.ErrRelationNotFound)
+	}
+	s.attached["{{.Route}}/"+id+"/"+{{.Target.CamelCase}}ID] = true
+	return nil
+}
+
+func (s *fake{{$.Names.PascalCase}}RelationService) Detach{{.GoName}}(ctx context.Context, id, {{.Target.CamelCase}}ID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := "{{.Route}}/" + id + "/" + {{.Target.CamelCase}}ID
+	if !s.parents[id] || !s.attached[key] {
+		return fmt.Errorf("{{.Target.Singular}} %w", models.ErrRelationNotFound)
+	}
+	delete(s.attached, key)
+	return nil
+}
+{{- else}}
+
+func (s *fake{{$.Names.PascalCase}}RelationService) List{{.GoName}}(ctx context.Context, id string, page, pageSize int) ([]*models.{{.Target.PascalCase}}, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.parents[id] {
+		return nil, 0, fmt.Errorf("{{$.Names.Singular}} %w", models.ErrRelationNotFound)
+	}
+	return []*models.{{.Target.PascalCase}}{}, 0, nil
+}
+{{- end}}
+{{- end}}
+
+// new{{.Names.PascalCase}}RelationTestServer mounts the relation routes under /api/v1 on a {{.HTTP.Framework.GetDisplayName}} router
+// and returns a function sending a request through it
+func new{{.Names.PascalCase}}RelationTestServer(t *testing.T, service services.{{.Names.PascalCase}}RelationServiceInterface) func(method, path, body string) (int, map[string]interface{}) {
+	handler := New{{.Names.PascalCase}}RelationHandler(service)
+{{- if .HTTP.Is "fiber"}}
+	app := fiber.New()
+	Setup{{.Names.PascalCase}}RelationRoutes(app.Group("/api/v1"), handler)
+{{- else if .HTTP.Is "echo"}}
+	router := echo.New()
+	Setup{{.Names.PascalCase}}RelationRoutes(router.Group("/api/v1"), handler)
+{{- else if .HTTP.Is "chi"}}
+	router := chi.NewRouter()
+	router.Route("/api/v1", func(r chi.Router) {
+		Setup{{.Names.PascalCase}}RelationRoutes(r, handler)
+	})
+{{- else if .HTTP.Is "stdlib"}}
+	api := http.NewServeMux()
+	Setup{{.Names.PascalCase}}RelationRoutes(api, handler)
+	router := http.NewServeMux()
+	router.Handle("/api/v1/", http.StripPrefix("/api/v1", api))
+{{- else}}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	Setup{{.Names.PascalCase}}RelationRoutes(router.Group("/api/v1"), handler)
+{{- end}}
+
+	return func(method, path, body string) (int, map[string]interface{}) {
+		t.Helper()
+
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+{{- if .HTTP.Is "fiber"}}
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		defer resp.Body.Close()
+
+		status := resp.StatusCode
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("%s %s: failed to read body: %v", method, path, err)
+		}
+{{- else}}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		status, data := rec.Code, rec.Body.Bytes()
+{{- end}}
+
+		var decoded map[string]interface{}
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("%s %s: response is not a JSON object: %s", method, path, data)
+			}
+		}
+		return status, decoded
+	}
+}
+{{- range .Relations}}
+
+func Test{{$.Names.PascalCase}}RelationHandler_List{{.GoName}}(t *testing.T) {
+	id := primitive.NewObjectID().Hex()
+	do := new{{$.Names.PascalCase}}RelationTestServer(t, newFake{{$.Names.PascalCase}}RelationService(id))
+
+	status, body := do(http.MethodGet, "/api/v1/{{$.Names.KebabPlural}}/"+primitive.NewObjectID().Hex()+"/{{.Route}}", "")
+	if status != http.StatusNotFound {
+		t.Fatalf("unknown {{$.Names.Singular}}: expected %d, got %d: %v", http.StatusNotFound, status, body)
+	}
+
+	status, body = do(http.MethodGet, "/api/v1/{{$.Names.KebabPlural}}/"+id+"/{{.Route}}?page=1&page_size=10", "")
+	if status != http.StatusOK {
+		t.Fatalf("list: expected %d, got %d: %v", http.StatusOK, status, body)
+	}
+	if body["total"] != float64(0) || body["page_size"] != float64(10) {
+		t.Fatalf("list: expected no {{.Label}} on a page of 10, got %v", body)
+	}
+}
+{{- if .Many}}
+
+func Test{{$.Names.PascalCase}}RelationHandler_Attach{{.GoName}}(t *testing.T) {
+	id := primitive.NewObjectID().Hex()
+	do := new{{$.Names.PascalCase}}RelationTestServer(t, newFake{{$.Names.PascalCase}}RelationService(id))
+	path := "/api/v1/{{$.Names.KebabPlural}}/" + id + "/{{.Route}}/" + primitive.NewObjectID().Hex()
+
+	status, body := do(http.MethodPut, path, {{printf "%q" .TestPayload}})
+	if status != http.StatusOK {
+		t.Fatalf("attach: expected %d, got %d: %v", http.StatusOK, status, body)
+	}
+
+	status, body = do(http.MethodGet, "/api/v1/{{$.Names.KebabPlural}}/"+id+"/{{.Route}}", "")
+	if status != http.StatusOK || body["total"] != float64(1) {
+		t.Fatalf("list: expected one attached {{.Target.Singular}}, got %d: %v", status, body)
+	}
+
+	status, body = do(http.MethodDelete, path, "")
+	if status != http.StatusOK {
+		t.Fatalf("detach: expected %d, got %d: %v", http.StatusOK, status, body)
+	}
+
+	status, body = do(http.MethodDelete, path, "")
+	if status != http.StatusNotFound {
+		t.Fatalf("detach twice: expected %d, got %d: %v", http.StatusNotFound, status, body)
+	}
+{{- if .PivotFields}}
+
+	status, body = do(http.MethodPut, path, "{\"")
+	if status != http.StatusBadRequest {
+		t.Fatalf("attach with an invalid body: expected %d, got %d: %v", http.StatusBadRequest, status, body)
+	}
+{{- end}}
+}
+{{- end}}
+{{- end}}
+`
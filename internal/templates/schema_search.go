@@ -0,0 +1,596 @@
+package templates
+
+// SearchPackageTemplate generates the shared result type, paging and highlighting used by full-text search
+const SearchPackageTemplate = `package search
+
+import (
+	"errors"
+	"html"
+	"strings"
+	"unicode"
+)
+
+// Highlight delimiters written around matched terms. They are private use characters so
+// they can't clash with the text, and become <mark> tags once the snippet is HTML escaped.
+const (
+	StartMark = "\ue000"
+	EndMark   = "\ue001"
+)
+
+// Paging limits of search requests
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// ErrEmptyQuery is returned for queries without any searchable term
+var ErrEmptyQuery = errors.New("search query has no searchable terms")
+
+// Result is a ranked search match. Highlights maps field names onto HTML snippets of the
+// matched fields, with the matched terms wrapped in <mark> tags.
+type Result[T any] struct {
+	Item       T                 ` + "`" + `json:"item"` + "`" + `
+	Rank       float64           ` + "`" + `json:"rank"` + "`" + `
+	Highlights map[string]string ` + "`" + `json:"highlights,omitempty"` + "`" + `
+}
+
+// Paging normalizes the page and page size of a search request
+func Paging(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = DefaultPageSize
+	}
+	if pageSize > MaxPageSize {
+		pageSize = MaxPageSize
+	}
+	return page, pageSize
+}
+
+// Terms splits a query into lower case words
+func Terms(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// MatchExpression turns a query into an SQLite FTS5 expression matching every term.
+// Terms are quoted, so user input can't inject FTS5 query syntax.
+func MatchExpression(query string) string {
+	terms := Terms(query)
+	for i, term := range terms {
+		terms[i] = ` + "`" + `"` + "`" + ` + term + ` + "`" + `"` + "`" + `
+	}
+	return strings.Join(terms, " ")
+}
+
+// Highlight escapes a snippet for HTML and turns the highlight delimiters into <mark>
+// tags. It reports whether the snippet has a match.
+func Highlight(snippet string) (string, bool) {
+	if !strings.Contains(snippet, StartMark) {
+		return "", false
+	}
+	escaped := html.EscapeString(snippet)
+	escaped = strings.ReplaceAll(escaped, StartMark, "<mark>")
+	return strings.ReplaceAll(escaped, EndMark, "</mark>"), true
+}
+
+// Highlights highlights the snippets of the fields, leaving out the fields without a match
+func Highlights(snippets map[string]string) map[string]string {
+	highlights := make(map[string]string, len(snippets))
+	for field, snippet := range snippets {
+		if highlight, ok := Highlight(snippet); ok {
+			highlights[field] = highlight
+		}
+	}
+	return highlights
+}
+
+// Mark delimits the words of text starting with one of the terms and crops the text to
+// size words around the first match, for databases returning no snippets. It returns an
+// empty string when nothing matches.
+func Mark(text string, terms []string, size int) string {
+	words := strings.Fields(text)
+	first := -1
+	for i, word := range words {
+		lower := strings.TrimLeftFunc(strings.ToLower(word), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for _, term := range terms {
+			if strings.HasPrefix(lower, term) {
+				words[i] = StartMark + word + EndMark
+				if first < 0 {
+					first = i
+				}
+				break
+			}
+		}
+	}
+	if first < 0 {
+		return ""
+	}
+
+	start := first - size/2
+	if start < 0 {
+		start = 0
+	}
+	end := start + size
+	if end > len(words) {
+		end = len(words)
+	}
+
+	snippet := strings.Join(words[start:end], " ")
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(words) {
+		snippet += "…"
+	}
+	return snippet
+}
+`
+
+// SearchTestTemplate generates the tests of the search package
+const SearchTestTemplate = `package search
+
+import "testing"
+
+func TestMatchExpressionQuotesTerms(t *testing.T) {
+	got := MatchExpression(` + "`" + `red "shoes" OR -boots*` + "`" + `)
+	want := ` + "`" + `"red" "shoes" "or" "boots"` + "`" + `
+	if got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+	if MatchExpression("  -- ") != "" {
+		t.Fatal("expected no terms")
+	}
+}
+
+func TestHighlightEscapesSnippets(t *testing.T) {
+	got, ok := Highlight("<b>" + StartMark + "red" + EndMark + "</b> shoes")
+	if !ok {
+		t.Fatal("expected a match")
+	}
+	if want := "&lt;b&gt;<mark>red</mark>&lt;/b&gt; shoes"; got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+
+	if _, ok := Highlight("red shoes"); ok {
+		t.Fatal("expected no match without delimiters")
+	}
+}
+
+func TestHighlightsSkipFieldsWithoutMatch(t *testing.T) {
+	highlights := Highlights(map[string]string{
+		"name":        StartMark + "red" + EndMark + " shoes",
+		"description": "comfortable",
+	})
+	if len(highlights) != 1 || highlights["name"] != "<mark>red</mark> shoes" {
+		t.Fatalf("unexpected highlights %v", highlights)
+	}
+}
+
+func TestMarkCropsAroundFirstMatch(t *testing.T) {
+	text := "one two three four five six seven eight nine ten"
+	got := Mark(text, Terms("Seven"), 4)
+	want := "…five six " + StartMark + "seven" + EndMark + " eight…"
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	if Mark(text, Terms("eleven"), 4) != "" {
+		t.Fatal("expected no snippet without a match")
+	}
+}
+
+func TestPaging(t *testing.T) {
+	if page, size := Paging(0, 0); page != 1 || size != DefaultPageSize {
+		t.Fatalf("unexpected defaults %d, %d", page, size)
+	}
+	if _, size := Paging(2, 1000); size != MaxPageSize {
+		t.Fatalf("expected the page size to be capped, got %d", size)
+	}
+}
+`
+
+// searchRepositoryInterface declares the search repository interface shared by the backends
+const searchRepositoryInterface = `
+// {{.Names.PascalCase}}SearchRepositoryInterface runs full-text searches over {{.Names.Plural}}
+type {{.Names.PascalCase}}SearchRepositoryInterface interface {
+	Search(ctx context.Context, query string, page, pageSize int) ([]search.Result[*models.{{.Names.PascalCase}}], int64, error)
+}
+
+var _ {{.Names.PascalCase}}SearchRepositoryInterface = (*{{.Names.PascalCase}}SearchRepository)(nil)
+`
+
+// searchQueries declares the ranked search query and its count
+const searchQueries = `
+// {{.Names.CamelCase}}SearchQuery ranks the {{.Names.Plural}} matching a full-text query, with a snippet of every searchable field
+const {{.Names.CamelCase}}SearchQuery = ` + "`" + `{{.Query}}` + "`" + `
+
+// {{.Names.CamelCase}}SearchCountQuery counts the {{.Names.Plural}} matching a full-text query
+const {{.Names.CamelCase}}SearchCountQuery = ` + "`" + `{{.CountQuery}}` + "`" + `
+`
+
+// searchResults converts the rows of the SQL search queries into results
+const searchResults = `
+// {{.Names.CamelCase}}SearchRow is a {{.Names.Singular}} row with its rank and snippets
+type {{.Names.CamelCase}}SearchRow struct {
+	{{.RowType}}
+	SearchRank float64 ` + "`" + `{{.Tag "search_rank"}}` + "`" + `
+{{- range .Fields}}
+	{{.HighlightField}} string ` + "`" + `{{$.Tag .Highlight}}` + "`" + `
+{{- end}}
+}
+
+// {{.Names.CamelCase}}SearchResults converts search rows into results
+func {{.Names.CamelCase}}SearchResults(rows []{{.Names.CamelCase}}SearchRow) ([]search.Result[*models.{{.Names.PascalCase}}], error) {
+	results := make([]search.Result[*models.{{.Names.PascalCase}}], 0, len(rows))
+	for i := range rows {
+{{- if eq .Backend "gorm"}}
+		{{.Names.CamelCase}} := rows[i].{{.RowField}}
+{{- else}}
+		{{.Names.CamelCase}}, err := to{{.Names.PascalCase}}(rows[i].{{.RowField}})
+		if err != nil {
+			return nil, err
+		}
+{{- end}}
+		results = append(results, search.Result[*models.{{.Names.PascalCase}}]{
+			Item: {{if eq .Backend "gorm"}}&{{end}}{{.Names.CamelCase}},
+			Rank: rows[i].SearchRank,
+			Highlights: search.Highlights(map[string]string{
+{{- range .Fields}}
+				"{{.Name}}": rows[i].{{.HighlightField}},
+{{- end}}
+			}),
+		})
+	}
+	return results, nil
+}
+`
+
+// SchemaSearchGORMRepositoryTemplate generates the full-text search repository of a GORM resource
+const SchemaSearchGORMRepositoryTemplate = `package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"{{.Module}}/internal/models"
+	"{{.Module}}/internal/search"
+)
+` + searchRepositoryInterface + searchQueries + `
+// {{.Names.PascalCase}}SearchRepository runs full-text searches over {{.Names.Plural}} with {{.Engine}}
+type {{.Names.PascalCase}}SearchRepository struct {
+	db *gorm.DB
+}
+
+// New{{.Names.PascalCase}}SearchRepository creates a new {{.Names.PascalCase}} search repository
+func New{{.Names.PascalCase}}SearchRepository(db *gorm.DB) *{{.Names.PascalCase}}SearchRepository {
+	return &{{.Names.PascalCase}}SearchRepository{db: db}
+}
+
+// Search returns a page of the {{.Names.Plural}} matching the query, best matches first
+func (r *{{.Names.PascalCase}}SearchRepository) Search(ctx context.Context, query string, page, pageSize int) ([]search.Result[*models.{{.Names.PascalCase}}], int64, error) {
+	match := {{.MatchArg}}
+
+	var total int64
+	if err := r.db.WithContext(ctx).Raw({{.Names.CamelCase}}SearchCountQuery, match).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []{{.Names.CamelCase}}SearchRow
+	limit := fmt.Sprintf(" LIMIT %d OFFSET %d", pageSize, (page-1)*pageSize)
+	if err := r.db.WithContext(ctx).Raw({{.Names.CamelCase}}SearchQuery+limit, match).Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+
+	results, err := {{.Names.CamelCase}}SearchResults(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return results, total, nil
+}
+` + searchResults
+
+// SchemaSearchSQLXRepositoryTemplate generates the full-text search repository of an sqlx resource
+const SchemaSearchSQLXRepositoryTemplate = `package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"{{.Module}}/internal/models"
+	"{{.Module}}/internal/search"
+)
+` + searchRepositoryInterface + searchQueries + `
+// {{.Names.PascalCase}}SearchRepository runs full-text searches over {{.Names.Plural}} with {{.Engine}}
+type {{.Names.PascalCase}}SearchRepository struct {
+	db *sqlx.DB
+}
+
+// New{{.Names.PascalCase}}SearchRepository creates a new {{.Names.PascalCase}} search repository
+func New{{.Names.PascalCase}}SearchRepository(db *sqlx.DB) *{{.Names.PascalCase}}SearchRepository {
+	return &{{.Names.PascalCase}}SearchRepository{db: db}
+}
+
+// Search returns a page of the {{.Names.Plural}} matching the query, best matches first
+func (r *{{.Names.PascalCase}}SearchRepository) Search(ctx context.Context, query string, page, pageSize int) ([]search.Result[*models.{{.Names.PascalCase}}], int64, error) {
+	match := {{.MatchArg}}
+
+	var total int64
+	if err := r.db.GetContext(ctx, &total, r.db.Rebind({{.Names.CamelCase}}SearchCountQuery), match); err != nil {
+		return nil, 0, err
+	}
+
+	var rows []{{.Names.CamelCase}}SearchRow
+	limit := fmt.Sprintf(" LIMIT %d OFFSET %d", pageSize, (page-1)*pageSize)
+	if err := r.db.SelectContext(ctx, &rows, r.db.Rebind({{.Names.CamelCase}}SearchQuery+limit), match); err != nil {
+		return nil, 0, err
+	}
+
+	results, err := {{.Names.CamelCase}}SearchResults(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return results, total, nil
+}
+` + searchResults
+
+// SchemaSearchPGXRepositoryTemplate generates the full-text search repository of a pgx or sqlc resource
+const SchemaSearchPGXRepositoryTemplate = `package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+{{- if eq .Backend "sqlc"}}
+
+	"{{.Module}}/internal/db"
+{{- end}}
+	"{{.Module}}/internal/models"
+	"{{.Module}}/internal/search"
+)
+` + searchRepositoryInterface + searchQueries + `
+// {{.Names.PascalCase}}SearchRepository runs full-text searches over {{.Names.Plural}} with {{.Engine}}
+type {{.Names.PascalCase}}SearchRepository struct {
+	pool *pgxpool.Pool
+}
+
+// New{{.Names.PascalCase}}SearchRepository creates a new {{.Names.PascalCase}} search repository
+func New{{.Names.PascalCase}}SearchRepository(pool *pgxpool.Pool) *{{.Names.PascalCase}}SearchRepository {
+	return &{{.Names.PascalCase}}SearchRepository{pool: pool}
+}
+
+// Search returns a page of the {{.Names.Plural}} matching the query, best matches first
+func (r *{{.Names.PascalCase}}SearchRepository) Search(ctx context.Context, query string, page, pageSize int) ([]search.Result[*models.{{.Names.PascalCase}}], int64, error) {
+	var total int64
+	if err := r.pool.QueryRow(ctx, {{.Names.CamelCase}}SearchCountQuery, query).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	limit := fmt.Sprintf(" LIMIT %d OFFSET %d", pageSize, (page-1)*pageSize)
+	result, err := r.pool.Query(ctx, {{.Names.CamelCase}}SearchQuery+limit, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	rows, err := pgx.CollectRows(result, pgx.RowToStructByName[{{.Names.CamelCase}}SearchRow])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	results, err := {{.Names.CamelCase}}SearchResults(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return results, total, nil
+}
+` + searchResults
+
+// SchemaSearchMongoRepositoryTemplate generates the full-text search repository of a MongoDB resource
+const SchemaSearchMongoRepositoryTemplate = `package repositories
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"{{.Module}}/internal/models"
+	"{{.Module}}/internal/search"
+)
+` + searchRepositoryInterface + `
+// {{.Names.CamelCase}}SearchSnippetWords is the length of the snippets of matched fields, in words
+const {{.Names.CamelCase}}SearchSnippetWords = 16
+
+// {{.Names.PascalCase}}SearchRepository runs full-text searches over {{.Names.Plural}} with a MongoDB text index
+type {{.Names.PascalCase}}SearchRepository struct {
+	collection *mongo.Collection
+}
+
+// New{{.Names.PascalCase}}SearchRepository creates a new {{.Names.PascalCase}} search repository
+func New{{.Names.PascalCase}}SearchRepository(db *mongo.Database) *{{.Names.PascalCase}}SearchRepository {
+	return &{{.Names.PascalCase}}SearchRepository{collection: db.Collection("{{.Names.TableName}}")}
+}
+
+// EnsureIndexes creates the weighted text index used by Search. MongoDB allows a single
+// text index per collection.
+func (r *{{.Names.PascalCase}}SearchRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+{{- range .Fields}}
+			{Key: "{{.Column}}", Value: "text"},
+{{- end}}
+		},
+		Options: options.Index().
+			SetName("{{.Names.TableName}}_search").
+			SetDefaultLanguage("{{.Language}}").
+			SetWeights(bson.D{
+{{- range .Fields}}
+				{Key: "{{.Column}}", Value: {{.MongoWeight}}},
+{{- end}}
+			}),
+	})
+	return err
+}
+
+// {{.Names.CamelCase}}SearchDocument is a {{.Names.Singular}} document with its text score
+type {{.Names.CamelCase}}SearchDocument struct {
+	models.{{.Names.PascalCase}} ` + "`" + `bson:",inline"` + "`" + `
+	SearchRank float64 ` + "`" + `bson:"search_rank"` + "`" + `
+}
+
+// Search returns a page of the {{.Names.Plural}} matching the query, best matches first
+func (r *{{.Names.PascalCase}}SearchRepository) Search(ctx context.Context, query string, page, pageSize int) ([]search.Result[*models.{{.Names.PascalCase}}], int64, error) {
+	filter := bson.M{"$text": bson.M{"$search": query}}
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	score := bson.M{"$meta": "textScore"}
+	opts := options.Find().
+		SetProjection(bson.M{"search_rank": score}).
+		SetSort(bson.D{ {Key: "search_rank", Value: score} }).
+		SetSkip(int64((page - 1) * pageSize)).
+		SetLimit(int64(pageSize))
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var documents []{{.Names.CamelCase}}SearchDocument
+	if err := cursor.All(ctx, &documents); err != nil {
+		return nil, 0, err
+	}
+
+	// MongoDB returns no snippets, so the matched terms are marked here
+	terms := search.Terms(query)
+	results := make([]search.Result[*models.{{.Names.PascalCase}}], 0, len(documents))
+	for i := range documents {
+		{{.Names.CamelCase}} := documents[i].{{.Names.PascalCase}}
+		results = append(results, search.Result[*models.{{.Names.PascalCase}}]{
+			Item: &{{.Names.CamelCase}},
+			Rank: documents[i].SearchRank,
+			Highlights: search.Highlights(map[string]string{
+{{- range .Fields}}
+				"{{.Name}}": search.Mark({{$.Names.CamelCase}}.{{.GoName}}, terms, {{$.Names.CamelCase}}SearchSnippetWords),
+{{- end}}
+			}),
+		})
+	}
+	return results, total, nil
+}
+`
+
+// SchemaSearchServiceTemplate generates the full-text search service of a resource
+const SchemaSearchServiceTemplate = `package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"{{.Module}}/internal/models"
+	"{{.Module}}/internal/repositories"
+	"{{.Module}}/internal/search"
+)
+
+// {{.Names.PascalCase}}SearchService runs full-text searches over {{.Names.Plural}}
+type {{.Names.PascalCase}}SearchService struct {
+	repo repositories.{{.Names.PascalCase}}SearchRepositoryInterface
+}
+
+// New{{.Names.PascalCase}}SearchService creates a new {{.Names.PascalCase}} search service
+func New{{.Names.PascalCase}}SearchService(repo repositories.{{.Names.PascalCase}}SearchRepositoryInterface) *{{.Names.PascalCase}}SearchService {
+	return &{{.Names.PascalCase}}SearchService{repo: repo}
+}
+
+// Search returns a page of the {{.Names.Plural}} matching the query, best matches first.
+// Queries without searchable terms fail with search.ErrEmptyQuery.
+func (s *{{.Names.PascalCase}}SearchService) Search(ctx context.Context, query string, page, pageSize int) ([]search.Result[*models.{{.Names.PascalCase}}], int64, error) {
+	if len(search.Terms(query)) == 0 {
+		return nil, 0, search.ErrEmptyQuery
+	}
+	page, pageSize = search.Paging(page, pageSize)
+
+	results, total, err := s.repo.Search(ctx, strings.TrimSpace(query), page, pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search {{.Names.Plural}}: %w", err)
+	}
+	return results, total, nil
+}
+`
+
+// SchemaSearchHandlerTemplate generates the full-text search HTTP handler of a resource
+const SchemaSearchHandlerTemplate = `package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+{{range .HTTP.Imports}}	"{{.}}"
+{{end}}	"{{.Module}}/internal/models"
+	"{{.Module}}/internal/search"
+	"{{.Module}}/internal/services"
+)
+
+// {{.Names.PascalCase}}SearchHandler handles full-text search requests for {{.DisplayName}}
+type {{.Names.PascalCase}}SearchHandler struct {
+	service *services.{{.Names.PascalCase}}SearchService
+}
+
+// New{{.Names.PascalCase}}SearchHandler creates a new {{.Names.PascalCase}} search handler
+func New{{.Names.PascalCase}}SearchHandler(service *services.{{.Names.PascalCase}}SearchService) *{{.Names.PascalCase}}SearchHandler {
+	return &{{.Names.PascalCase}}SearchHandler{service: service}
+}
+
+// Search handles GET /{{.Names.KebabPlural}}/search?q=. Results are ranked best match first and
+// carry HTML snippets of the matched fields, with the matched terms in <mark> tags.
+func (h *{{.Names.PascalCase}}SearchHandler) Search({{.HTTP.HandlerParams}}){{.HTTP.HandlerResult}} {
+	query := {{.HTTP.Query "q"}}
+	page, _ := strconv.Atoi({{.HTTP.Query "page"}})
+	pageSize, _ := strconv.Atoi({{.HTTP.Query "page_size"}})
+	page, pageSize = search.Paging(page, pageSize)
+
+	results, total, err := h.service.Search({{.HTTP.Context}}, query, page, pageSize)
+	if errors.Is(err, search.ErrEmptyQuery) {
+		{{.HTTP.Fail "http.StatusBadRequest" "err.Error()"}}
+	}
+	if err != nil {
+		{{.HTTP.Fail "http.StatusInternalServerError" "err.Error()"}}
+	}
+
+	// Convert to response format
+	responses := make([]search.Result[*models.{{.Names.PascalCase}}Response], len(results))
+	for i, result := range results {
+		responses[i] = search.Result[*models.{{.Names.PascalCase}}Response]{
+			Item:       result.Item.To{{.Names.PascalCase}}Response(),
+			Rank:       result.Rank,
+			Highlights: result.Highlights,
+		}
+	}
+
+	{{.HTTP.Reply "http.StatusOK"}}{{.HTTP.Map}}{
+		"data":      responses,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// Setup{{.Names.PascalCase}}SearchRoutes sets up search routes for {{.DisplayName}}
+func Setup{{.Names.PascalCase}}SearchRoutes({{.HTTP.Router}}, handler *{{.Names.PascalCase}}SearchHandler) {
+	{{.HTTP.Root.Route "GET" (printf "/%s/search" .Names.KebabPlural) "handler.Search"}}
+}
+`
@@ -10,13 +10,22 @@ const SchemaModelTemplate = `package models
 
 import (
 	"time"
-	"encoding/json"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 {{- range .RequiredImports}}
 	"{{.}}"
+{{- end}}
+	"go.mongodb.org/mongo-driver/bson/primitive"
+{{- if .UsesGORM}}
+	"gorm.io/gorm"
 {{- end}}
 {{- if .Database.Provider | eq "supabase"}}
 	"github.com/google/uuid"
+{{- end}}
+	"{{.Module}}/internal/apperrors"
+{{- if .Geo}}
+	"{{.Module}}/internal/geo"
+{{- end}}
+{{- if .UsesMoney}}
+	"{{.Module}}/internal/money"
 {{- end}}
 )
 
@@ -29,19 +38,41 @@ type {{.Name}} struct {
 
 // {{.Names.PascalCase}} represents the {{.DisplayName}} model
 type {{.Names.PascalCase}} struct {
-	ID        primitive.ObjectID ` + "`" + `json:"id" bson:"_id,omitempty"` + "`" + `
+	ID        primitive.ObjectID ` + "`" + `json:"id" bson:"_id,omitempty"{{if .UsesGORM}} gorm:"primaryKey;type:varchar(24);serializer:objectid"{{end}}` + "`" + `
 	CreatedAt time.Time          ` + "`" + `json:"created_at" bson:"created_at"` + "`" + `
 	UpdatedAt time.Time          ` + "`" + `json:"updated_at" bson:"updated_at"` + "`" + `
 
 {{- range .Fields}}
 	{{.GoStructField}}
 {{- end}}
+{{- if .Geo}}
+
+	// Distance is the distance in meters to the position of a near filter
+	Distance *float64 ` + "`" + `json:"distance,omitempty" bson:"-"{{if .UsesGORM}} gorm:"-"{{end}}` + "`" + `
+{{- end}}
 }
 
 // CollectionName returns the MongoDB collection name for {{.Names.PascalCase}}
 func ({{.Names.PascalCase}}) CollectionName() string {
 	return "{{.Names.TableName}}"
 }
+{{- if ne .DBProvider "mongodb"}}
+
+// TableName returns the SQL table name for {{.Names.PascalCase}}
+func ({{.Names.PascalCase}}) TableName() string {
+	return "{{.Names.TableName}}"
+}
+{{- end}}
+{{- if .UsesGORM}}
+
+// BeforeCreate assigns a new ObjectID before inserting into SQL databases
+func (m *{{.Names.PascalCase}}) BeforeCreate(tx *gorm.DB) error {
+	if m.ID.IsZero() {
+		m.ID = primitive.NewObjectID()
+	}
+	return nil
+}
+{{- end}}
 
 // {{.Names.PascalCase}}Request represents the request payload for creating/updating {{.DisplayName}}
 type {{.Names.PascalCase}}Request struct {
@@ -61,6 +92,9 @@ type {{.Names.PascalCase}}Response struct {
 {{- range .Fields}}
 	{{.GoResponseField}}
 {{- end}}
+{{- if .Geo}}
+	Distance  *float64           ` + "`" + `json:"distance,omitempty"` + "`" + `
+{{- end}}
 }
 
 // To{{.Names.PascalCase}}Response converts model to response
@@ -71,17 +105,25 @@ func (m *{{.Names.PascalCase}}) To{{.Names.PascalCase}}Response() *{{.Names.Pasc
 		UpdatedAt: m.UpdatedAt,
 {{- range .Fields}}
 		{{.Names.PascalCase}}: m.{{.Names.PascalCase}},
+{{- end}}
+{{- if .Geo}}
+		Distance:  m.Distance,
 {{- end}}
 	}
 }
 
 // {{.Names.PascalCase}}Filter represents filter options for {{.DisplayName}}
 type {{.Names.PascalCase}}Filter struct {
-	Page     int    ` + "`" + `json:"page" form:"page"` + "`" + `
-	PageSize int    ` + "`" + `json:"page_size" form:"page_size"` + "`" + `
-	Sort     string ` + "`" + `json:"sort" form:"sort"` + "`" + `
-	Order    string ` + "`" + `json:"order" form:"order"` + "`" + `
-	Search   string ` + "`" + `json:"search" form:"search"` + "`" + `
+	Page     int    ` + "`" + `json:"page" form:"page" query:"page"` + "`" + `
+	PageSize int    ` + "`" + `json:"page_size" form:"page_size" query:"page_size"` + "`" + `
+	Sort     string ` + "`" + `json:"sort" form:"sort" query:"sort"` + "`" + `
+	Order    string ` + "`" + `json:"order" form:"order" query:"order"` + "`" + `
+	Search   string ` + "`" + `json:"search" form:"search" query:"search"` + "`" + `
+{{- if .Geo}}
+	Near     string ` + "`" + `json:"near,omitempty" form:"near" query:"near"` + "`" + `
+	Radius   string ` + "`" + `json:"radius,omitempty" form:"radius" query:"radius"` + "`" + `
+	BBox     string ` + "`" + `json:"bbox,omitempty" form:"bbox" query:"bbox"` + "`" + `
+{{- end}}
 
 {{- range .Fields}}
 {{- if .Filterable}}
@@ -89,16 +131,84 @@ type {{.Names.PascalCase}}Filter struct {
 {{- end}}
 {{- end}}
 }
+{{- if .Geo}}
 
-// Validate validates the {{.Names.PascalCase}}Request
+// GeoQuery parses the near, radius and bbox filters on {{.Geo.Name}}, nil without them
+func (f *{{.Names.PascalCase}}Filter) GeoQuery() (*geo.Query, error) {
+	return geo.ParseQuery(f.Near, f.Radius, f.BBox)
+}
+
+// SetDistance sets the distance of the {{.Names.Singular}} to the position of a near filter
+func (m *{{.Names.PascalCase}}) SetDistance(query *geo.Query) {
+	if query == nil || query.Near == nil || m.{{.Geo.GoName}} == nil {
+		return
+	}
+	distance := geo.Distance(*query.Near, m.{{.Geo.GoName}}.Point())
+	m.Distance = &distance
+}
+{{- end}}
+
+// Validate validates the {{.Names.PascalCase}}Request, reporting every rejected field
 func (r *{{.Names.PascalCase}}Request) Validate() error {
+	var fields []apperrors.FieldError
 {{- range .Fields}}
-{{- if .Required}}
+{{- if or .Required .IsMoney}}
 	{{.GoValidation}}
 {{- end}}
 {{- end}}
+	return apperrors.Invalid(fields...)
+}
+`
+
+// ObjectIDSerializerTemplate generates the GORM serializer storing ObjectIDs in SQL databases
+const ObjectIDSerializerTemplate = `package models
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"gorm.io/gorm/schema"
+)
+
+func init() {
+	schema.RegisterSerializer("objectid", ObjectIDSerializer{})
+}
+
+// ObjectIDSerializer stores primitive.ObjectID values as hex strings
+type ObjectIDSerializer struct{}
+
+// Scan implements schema.SerializerInterface
+func (ObjectIDSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var hex string
+	switch v := dbValue.(type) {
+	case nil:
+		return nil
+	case string:
+		hex = v
+	case []byte:
+		hex = string(v)
+	default:
+		return fmt.Errorf("unsupported ObjectID column value %T", dbValue)
+	}
+
+	id, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		return err
+	}
+	field.ReflectValueOf(ctx, dst).Set(reflect.ValueOf(id))
 	return nil
 }
+
+// Value implements schema.SerializerValuerInterface
+func (ObjectIDSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	id, ok := fieldValue.(primitive.ObjectID)
+	if !ok {
+		return nil, fmt.Errorf("unsupported ObjectID field value %T", fieldValue)
+	}
+	return id.Hex(), nil
+}
 `
 
 // SchemaRepositoryTemplate generates repository layer
@@ -106,9 +216,12 @@ const SchemaRepositoryTemplate = `package repositories
 
 import (
 	"context"
-	"fmt"
 	"strings"
 	"time"
+	"{{.Module}}/internal/apperrors"
+{{- if .Geo}}
+	"{{.Module}}/internal/geo"
+{{- end}}
 	"{{.Module}}/internal/models"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -144,14 +257,14 @@ func (r *{{.Names.PascalCase}}Repository) Create(ctx context.Context, {{.Names.C
 func (r *{{.Names.PascalCase}}Repository) GetByID(ctx context.Context, idStr string) (*models.{{.Names.PascalCase}}, error) {
 	id, err := primitive.ObjectIDFromHex(idStr)
 	if err != nil {
-		return nil, fmt.Errorf("invalid ID format: %w", err)
+		return nil, apperrors.BadRequest("invalid ID format: %w", err)
 	}
 
 	var {{.Names.CamelCase}} models.{{.Names.PascalCase}}
 	err = r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&{{.Names.CamelCase}})
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, nil
+			return nil, apperrors.NotFound("{{.Names.Singular}} %s not found", idStr)
 		}
 		return nil, err
 	}
@@ -172,6 +285,20 @@ func (r *{{.Names.PascalCase}}Repository) GetAll(ctx context.Context, filter *mo
 			mongoFilter["$or"] = searchConditions
 		}
 	}
+{{- if .Geo}}
+
+	// Apply geographic filters, near filters list the nearest {{.Names.Plural}} first
+	geoQuery, err := filter.GeoQuery()
+	if err != nil {
+		return nil, 0, err
+	}
+	if geoQuery != nil && geoQuery.BBox != nil {
+		mongoFilter["{{.Geo.Key}}"] = bson.M{"$geoWithin": bson.M{"$geometry": geoQuery.BBox.GeoJSON()}}
+	}
+	if geoQuery != nil && geoQuery.Near != nil {
+		return r.nearest(ctx, mongoFilter, geoQuery, filter)
+	}
+{{- end}}
 
 	// Count total records
 	total, err := r.collection.CountDocuments(ctx, mongoFilter)
@@ -196,7 +323,7 @@ func (r *{{.Names.PascalCase}}Repository) GetAll(ctx context.Context, filter *mo
 			sortOrder = 1
 		}
 	}
-	opts.SetSort(bson.D{{ "{" }}{{ "{" }}sortField, sortOrder{{ "}" }}{{ "}" }})
+	opts.SetSort(bson.D{{ "{" }}{{ "{" }}Key: sortField, Value: sortOrder{{ "}" }}{{ "}" }})
 
 	// Execute query
 	cursor, err := r.collection.Find(ctx, mongoFilter, opts)
@@ -211,6 +338,63 @@ func (r *{{.Names.PascalCase}}Repository) GetAll(ctx context.Context, filter *mo
 
 	return {{.Names.CamelPlural}}, total, nil
 }
+{{- if .Geo}}
+
+// nearest retrieves the page of {{.Names.Plural}} within the radius of a near filter, nearest first
+func (r *{{.Names.PascalCase}}Repository) nearest(ctx context.Context, match bson.M, query *geo.Query, filter *models.{{.Names.PascalCase}}Filter) ([]*models.{{.Names.PascalCase}}, int64, error) {
+	page := bson.A{}
+	if filter.Page > 0 && filter.PageSize > 0 {
+		page = append(page, bson.M{"$skip": int64((filter.Page - 1) * filter.PageSize)}, bson.M{"$limit": int64(filter.PageSize)})
+	} else {
+		page = append(page, bson.M{"$skip": int64(0)})
+	}
+
+	pipeline := bson.A{
+		bson.M{"$geoNear": bson.M{
+			"near":          query.Near.GeoJSON(),
+			"key":           "{{.Geo.Key}}",
+			"distanceField": "_distance",
+			"maxDistance":   query.Radius,
+			"spherical":     true,
+			"query":         match,
+		}},
+		bson.M{"$facet": bson.M{
+			"items": page,
+			"total": bson.A{bson.M{"$count": "count"}},
+		}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Items []*models.{{.Names.PascalCase}} ` + "`" + `bson:"items"` + "`" + `
+		Total []struct {
+			Count int64 ` + "`" + `bson:"count"` + "`" + `
+		} ` + "`" + `bson:"total"` + "`" + `
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, 0, err
+	}
+	if len(results) == 0 || len(results[0].Total) == 0 {
+		return nil, 0, nil
+	}
+
+	for _, {{.Names.CamelCase}} := range results[0].Items {
+		{{.Names.CamelCase}}.SetDistance(query)
+	}
+	return results[0].Items, results[0].Total[0].Count, nil
+}
+
+// EnsureIndexes creates the 2dsphere index the geographic filters of {{.Names.Plural}} require
+func (r *{{.Names.PascalCase}}Repository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.M{"{{.Geo.Key}}": "2dsphere"}})
+	return err
+}
+{{- end}}
 
 // Update updates a {{.Names.Singular}}
 func (r *{{.Names.PascalCase}}Repository) Update(ctx context.Context, {{.Names.CamelCase}} *models.{{.Names.PascalCase}}) error {
@@ -227,7 +411,7 @@ func (r *{{.Names.PascalCase}}Repository) Update(ctx context.Context, {{.Names.C
 func (r *{{.Names.PascalCase}}Repository) Delete(ctx context.Context, idStr string) error {
 	id, err := primitive.ObjectIDFromHex(idStr)
 	if err != nil {
-		return fmt.Errorf("invalid ID format: %w", err)
+		return apperrors.BadRequest("invalid ID format: %w", err)
 	}
 	
 	_, err = r.collection.DeleteOne(ctx, bson.M{"_id": id})
@@ -243,7 +427,7 @@ func (r *{{.Names.PascalCase}}Repository) HardDelete(ctx context.Context, idStr
 func (r *{{.Names.PascalCase}}Repository) Exists(ctx context.Context, idStr string) (bool, error) {
 	id, err := primitive.ObjectIDFromHex(idStr)
 	if err != nil {
-		return false, fmt.Errorf("invalid ID format: %w", err)
+		return false, apperrors.BadRequest("invalid ID format: %w", err)
 	}
 	
 	count, err := r.collection.CountDocuments(ctx, bson.M{"_id": id})
@@ -262,7 +446,7 @@ func (r *{{$.Names.PascalCase}}Repository) GetBy{{.Names.PascalCase}}(ctx contex
 	err := r.collection.FindOne(ctx, bson.M{"{{.Database.ColumnName}}": {{.Names.CamelCase}}}).Decode(&{{$.Names.CamelCase}})
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, nil
+			return nil, apperrors.NotFound("{{$.Names.Singular}} not found")
 		}
 		return nil, err
 	}
@@ -282,7 +466,7 @@ type {{.Names.PascalCase}}RepositoryInterface interface {
 	Exists(ctx context.Context, id string) (bool, error)
 {{- range .Fields}}
 {{- if .Database.Unique}}
-	GetBy{{.Names.PascalCase}}(ctx context.Context, {{.Names.CamelCase}} {{.GoType}}) (*models.{{.Names.PascalCase}}, error)
+	GetBy{{.Names.PascalCase}}(ctx context.Context, {{.Names.CamelCase}} {{.GoType}}) (*models.{{$.Names.PascalCase}}, error)
 {{- end}}
 {{- end}}
 }
@@ -292,7 +476,15 @@ type {{.Names.PascalCase}}RepositoryInterface interface {
 const SchemaServiceTemplate = `package services
 
 import (
+	"context"
 	"fmt"
+{{- if .Includes}}
+	"strings"
+{{- end}}
+	"{{.Module}}/internal/apperrors"
+{{- if .HasFeature "events"}}
+	"{{.Module}}/internal/events"
+{{- end}}
 	"{{.Module}}/internal/models"
 	"{{.Module}}/internal/repositories"
 )
@@ -300,12 +492,51 @@ import (
 // {{.Names.PascalCase}}Service handles business logic for {{.DisplayName}}
 type {{.Names.PascalCase}}Service struct {
 	repo repositories.{{.Names.PascalCase}}RepositoryInterface
+{{- if .HasFeature "events"}}
+	transactor events.TransactionRunner
+	outbox     events.Outbox
+{{- end}}
+{{- if .Includes}}
+	includes repositories.{{.Names.PascalCase}}Includer
+{{- end}}
 }
 
 // New{{.Names.PascalCase}}Service creates a new {{.Names.PascalCase}} service
 func New{{.Names.PascalCase}}Service(repo repositories.{{.Names.PascalCase}}RepositoryInterface) *{{.Names.PascalCase}}Service {
 	return &{{.Names.PascalCase}}Service{repo: repo}
 }
+{{- if .HasFeature "events"}}
+
+// WithEvents records {{.Names.PascalCase}}Created, {{.Names.PascalCase}}Updated and {{.Names.PascalCase}}Deleted events in the
+// outbox, in the transaction of the change
+func (s *{{.Names.PascalCase}}Service) WithEvents(transactor events.TransactionRunner, outbox events.Outbox) *{{.Names.PascalCase}}Service {
+	s.transactor = transactor
+	s.outbox = outbox
+	return s
+}
+{{- end}}
+{{- if .Includes}}
+
+// WithIncludes loads the relations requested with ?include through includes
+func (s *{{.Names.PascalCase}}Service) WithIncludes(includes repositories.{{.Names.PascalCase}}Includer) *{{.Names.PascalCase}}Service {
+	s.includes = includes
+	return s
+}
+
+// Include loads the requested relations of {{.Names.Plural}}
+func (s *{{.Names.PascalCase}}Service) Include(ctx context.Context, {{.Names.CamelPlural}} []*models.{{.Names.PascalCase}}, include models.IncludeTree) error {
+	if len(include) == 0 || len({{.Names.CamelPlural}}) == 0 {
+		return nil
+	}
+	if s.includes == nil {
+		return fmt.Errorf("including %s requires WithIncludes", strings.Join(include.Paths(), ","))
+	}
+	if err := s.includes.Include(ctx, {{.Names.CamelPlural}}, include); err != nil {
+		return fmt.Errorf("failed to include relations: %w", err)
+	}
+	return nil
+}
+{{- end}}
 
 // Create creates a new {{.Names.Singular}}
 func (s *{{.Names.PascalCase}}Service) Create(ctx context.Context, req *models.{{.Names.PascalCase}}Request) (*models.{{.Names.PascalCase}}, error) {
@@ -329,8 +560,17 @@ func (s *{{.Names.PascalCase}}Service) Create(ctx context.Context, req *models.{
 	}
 
 	// Create in database
+{{- if .HasFeature "events"}}
+	if err := s.withinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, {{.Names.CamelCase}}); err != nil {
+			return err
+		}
+		return s.recordEvent(ctx, events.{{.Names.PascalCase}}Created, {{.Names.CamelCase}})
+	}); err != nil {
+{{- else}}
 	if err := s.repo.Create(ctx, {{.Names.CamelCase}}); err != nil {
-		return nil, fmt.Errorf("failed to create {{.Names.Singular}}: %w", err)
+{{- end}}
+		return nil, fmt.Errorf("failed to create {{.Names.Singular}}: %w", apperrors.FromDatabase(err, "{{.DisplayName}}"))
 	}
 
 	return {{.Names.CamelCase}}, nil
@@ -342,6 +582,9 @@ func (s *{{.Names.PascalCase}}Service) GetByID(ctx context.Context, id string) (
 	if err != nil {
 		return nil, fmt.Errorf("failed to get {{.Names.Singular}}: %w", err)
 	}
+	if {{.Names.CamelCase}} == nil {
+		return nil, apperrors.NotFound("{{.Names.Singular}} %s not found", id)
+	}
 	return {{.Names.CamelCase}}, nil
 }
 
@@ -371,9 +614,9 @@ func (s *{{.Names.PascalCase}}Service) Update(ctx context.Context, id string, re
 	}
 
 	// Get existing {{.Names.Singular}}
-	{{.Names.CamelCase}}, err := s.repo.GetByID(ctx, id)
+	{{.Names.CamelCase}}, err := s.GetByID(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("{{.Names.Singular}} not found: %w", err)
+		return nil, err
 	}
 
 	// Business logic validations
@@ -389,8 +632,17 @@ func (s *{{.Names.PascalCase}}Service) Update(ctx context.Context, id string, re
 {{- end}}
 
 	// Update in database
+{{- if .HasFeature "events"}}
+	if err := s.withinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, {{.Names.CamelCase}}); err != nil {
+			return err
+		}
+		return s.recordEvent(ctx, events.{{.Names.PascalCase}}Updated, {{.Names.CamelCase}})
+	}); err != nil {
+{{- else}}
 	if err := s.repo.Update(ctx, {{.Names.CamelCase}}); err != nil {
-		return nil, fmt.Errorf("failed to update {{.Names.Singular}}: %w", err)
+{{- end}}
+		return nil, fmt.Errorf("failed to update {{.Names.Singular}}: %w", apperrors.FromDatabase(err, "{{.DisplayName}}"))
 	}
 
 	return {{.Names.CamelCase}}, nil
@@ -404,7 +656,7 @@ func (s *{{.Names.PascalCase}}Service) Delete(ctx context.Context, id string) er
 		return fmt.Errorf("failed to check {{.Names.Singular}} existence: %w", err)
 	}
 	if !exists {
-		return fmt.Errorf("{{.Names.Singular}} not found")
+		return apperrors.NotFound("{{.Names.Singular}} %s not found", id)
 	}
 
 	// Business logic validations
@@ -413,20 +665,58 @@ func (s *{{.Names.PascalCase}}Service) Delete(ctx context.Context, id string) er
 	}
 
 	// Delete from database
+{{- if .HasFeature "events"}}
+	if err := s.withinTransaction(ctx, func(ctx context.Context) error {
+		{{.Names.CamelCase}}, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if {{.Names.CamelCase}} == nil {
+			return apperrors.NotFound("{{.Names.Singular}} %s not found", id)
+		}
+		if err := s.repo.Delete(ctx, id); err != nil {
+			return err
+		}
+		return s.recordEvent(ctx, events.{{.Names.PascalCase}}Deleted, {{.Names.CamelCase}})
+	}); err != nil {
+{{- else}}
 	if err := s.repo.Delete(ctx, id); err != nil {
+{{- end}}
 		return fmt.Errorf("failed to delete {{.Names.Singular}}: %w", err)
 	}
 
 	return nil
 }
 
+{{if .HasFeature "events" -}}
+// withinTransaction runs fn in a transaction when events are recorded
+func (s *{{.Names.PascalCase}}Service) withinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.transactor == nil {
+		return fn(ctx)
+	}
+	return s.transactor.WithinTransaction(ctx, fn)
+}
+
+// recordEvent adds a {{.DisplayName}} event to the outbox
+func (s *{{.Names.PascalCase}}Service) recordEvent(ctx context.Context, eventType string, {{.Names.CamelCase}} *models.{{.Names.PascalCase}}) error {
+	if s.outbox == nil {
+		return nil
+	}
+	event, err := events.New{{.Names.PascalCase}}Event(eventType, {{.Names.CamelCase}})
+	if err != nil {
+		return err
+	}
+	return s.outbox.Add(ctx, event)
+}
+
+{{end -}}
 // validateCreate validates business rules for creating {{.Names.Singular}}
 func (s *{{.Names.PascalCase}}Service) validateCreate(ctx context.Context, req *models.{{.Names.PascalCase}}Request) error {
 {{- range .Fields}}
 {{- if .Database.Unique}}
 	// Check if {{.DisplayName}} already exists
-	if _, err := s.repo.GetBy{{.Names.PascalCase}}(ctx, req.{{.Names.PascalCase}}); err == nil {
-		return fmt.Errorf("{{.DisplayName}} already exists")
+	if existing, err := s.repo.GetBy{{.Names.PascalCase}}(ctx, req.{{.Names.PascalCase}}); err == nil && existing != nil {
+		return apperrors.Conflict("{{.DisplayName}} already exists")
 	}
 {{- end}}
 {{- end}}
@@ -439,8 +729,8 @@ func (s *{{.Names.PascalCase}}Service) validateUpdate(ctx context.Context, exist
 {{- if .Database.Unique}}
 	// Check if {{.DisplayName}} already exists (excluding current record)
 	if existing.{{.Names.PascalCase}} != req.{{.Names.PascalCase}} {
-		if _, err := s.repo.GetBy{{.Names.PascalCase}}(ctx, req.{{.Names.PascalCase}}); err == nil {
-			return fmt.Errorf("{{.DisplayName}} already exists")
+		if other, err := s.repo.GetBy{{.Names.PascalCase}}(ctx, req.{{.Names.PascalCase}}); err == nil && other != nil {
+			return apperrors.Conflict("{{.DisplayName}} already exists")
 		}
 	}
 {{- end}}
@@ -461,18 +751,23 @@ type {{.Names.PascalCase}}ServiceInterface interface {
 	GetAll(ctx context.Context, filter *models.{{.Names.PascalCase}}Filter) ([]*models.{{.Names.PascalCase}}, int64, error)
 	Update(ctx context.Context, id string, req *models.{{.Names.PascalCase}}Request) (*models.{{.Names.PascalCase}}, error)
 	Delete(ctx context.Context, id string) error
+{{- if .Includes}}
+	Include(ctx context.Context, {{.Names.CamelPlural}} []*models.{{.Names.PascalCase}}, include models.IncludeTree) error
+{{- end}}
 }
 `
 
-// SchemaHandlerTemplate generates HTTP handler
+// SchemaHandlerTemplate generates HTTP handler. Framework specific code comes from the
+// HTTP dialect (.HTTP), see generator.HTTPDialect.
 const SchemaHandlerTemplate = `package handlers
 
 import (
 	"net/http"
-	"strconv"
+
+{{range .HTTP.Imports}}	"{{.}}"
+{{end}}	"{{.Module}}/internal/apperrors"
 	"{{.Module}}/internal/models"
 	"{{.Module}}/internal/services"
-	"github.com/gin-gonic/gin"
 )
 
 // {{.Names.PascalCase}}Handler handles HTTP requests for {{.DisplayName}}
@@ -486,61 +781,88 @@ func New{{.Names.PascalCase}}Handler(service services.{{.Names.PascalCase}}Servi
 }
 
 // Create handles POST /{{.Names.KebabPlural}}
-func (h *{{.Names.PascalCase}}Handler) Create(c *gin.Context) {
+func (h *{{.Names.PascalCase}}Handler) Create({{.HTTP.HandlerParams}}){{.HTTP.HandlerResult}} {
 	var req models.{{.Names.PascalCase}}Request
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+	if err := {{.HTTP.BindJSON "&req"}}; err != nil {
+		{{.HTTP.Problem "apperrors.FromBinding(err, &req)"}}
 	}
 
-	{{.Names.CamelCase}}, err := h.service.Create(c.Request.Context(), &req)
+	{{.Names.CamelCase}}, err := h.service.Create({{.HTTP.Context}}, &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+		{{.HTTP.Problem "err"}}
 	}
 
-	c.JSON(http.StatusCreated, {{.Names.CamelCase}}.To{{.Names.PascalCase}}Response())
+	{{.HTTP.Respond "http.StatusCreated" (printf "%s.To%sResponse()" .Names.CamelCase .Names.PascalCase)}}
 }
 
 // GetByID handles GET /{{.Names.KebabPlural}}/:id
-func (h *{{.Names.PascalCase}}Handler) GetByID(c *gin.Context) {
-	id := c.Param("id")
+func (h *{{.Names.PascalCase}}Handler) GetByID({{.HTTP.HandlerParams}}){{.HTTP.HandlerResult}} {
+	id := {{.HTTP.Param "id"}}
 	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
-		return
+		{{.HTTP.Fail "http.StatusBadRequest" "\"Invalid ID\""}}
 	}
 
-	{{.Names.CamelCase}}, err := h.service.GetByID(c.Request.Context(), id)
+	view, err := models.ParseView("{{.Names.SnakeCase}}", {{.HTTP.RawQuery}})
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-		return
+		{{.HTTP.Fail "http.StatusBadRequest" "err.Error()"}}
 	}
 
-	c.JSON(http.StatusOK, {{.Names.CamelCase}}.To{{.Names.PascalCase}}Response())
+	{{.Names.CamelCase}}, err := h.service.GetByID({{.HTTP.Context}}, id)
+	if err != nil {
+		{{.HTTP.Problem "err"}}
+	}
+{{- if .Includes}}
+	if err := h.service.Include({{.HTTP.Context}}, []*models.{{.Names.PascalCase}}{ {{.Names.CamelCase}} }, view.Include); err != nil {
+		{{.HTTP.Problem "err"}}
+	}
+{{- end}}
+
+	response, err := view.Shape({{.Names.CamelCase}}.To{{.Names.PascalCase}}Response())
+	if err != nil {
+		{{.HTTP.Problem "err"}}
+	}
+	{{.HTTP.Respond "http.StatusOK" "response"}}
 }
 
 // GetAll handles GET /{{.Names.KebabPlural}}
-func (h *{{.Names.PascalCase}}Handler) GetAll(c *gin.Context) {
+func (h *{{.Names.PascalCase}}Handler) GetAll({{.HTTP.HandlerParams}}){{.HTTP.HandlerResult}} {
 	var filter models.{{.Names.PascalCase}}Filter
-	if err := c.ShouldBindQuery(&filter); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+	if err := {{.HTTP.BindQuery "&filter"}}; err != nil {
+		{{.HTTP.Problem "apperrors.FromBinding(err, &filter)"}}
+	}
+{{- if .Geo}}
+	if _, err := filter.GeoQuery(); err != nil {
+		{{.HTTP.Fail "http.StatusBadRequest" "err.Error()"}}
 	}
+{{- end}}
 
-	{{.Names.CamelPlural}}, total, err := h.service.GetAll(c.Request.Context(), &filter)
+	view, err := models.ParseView("{{.Names.SnakeCase}}", {{.HTTP.RawQuery}})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+		{{.HTTP.Fail "http.StatusBadRequest" "err.Error()"}}
 	}
 
+	{{.Names.CamelPlural}}, total, err := h.service.GetAll({{.HTTP.Context}}, &filter)
+	if err != nil {
+		{{.HTTP.Problem "err"}}
+	}
+{{- if .Includes}}
+	if err := h.service.Include({{.HTTP.Context}}, {{.Names.CamelPlural}}, view.Include); err != nil {
+		{{.HTTP.Problem "err"}}
+	}
+{{- end}}
+
 	// Convert to response format
 	responses := make([]*models.{{.Names.PascalCase}}Response, len({{.Names.CamelPlural}}))
 	for i, {{.Names.CamelCase}} := range {{.Names.CamelPlural}} {
 		responses[i] = {{.Names.CamelCase}}.To{{.Names.PascalCase}}Response()
 	}
+	data, err := view.Shape(responses)
+	if err != nil {
+		{{.HTTP.Problem "err"}}
+	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"data":      responses,
+	{{.HTTP.Reply "http.StatusOK"}}{{.HTTP.Map}}{
+		"data":      data,
 		"total":     total,
 		"page":      filter.Page,
 		"page_size": filter.PageSize,
@@ -548,54 +870,54 @@ func (h *{{.Names.PascalCase}}Handler) GetAll(c *gin.Context) {
 }
 
 // Update handles PUT /{{.Names.KebabPlural}}/:id
-func (h *{{.Names.PascalCase}}Handler) Update(c *gin.Context) {
-	id := c.Param("id")
+func (h *{{.Names.PascalCase}}Handler) Update({{.HTTP.HandlerParams}}){{.HTTP.HandlerResult}} {
+	id := {{.HTTP.Param "id"}}
 	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
-		return
+		{{.HTTP.Fail "http.StatusBadRequest" "\"Invalid ID\""}}
 	}
 
 	var req models.{{.Names.PascalCase}}Request
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+	if err := {{.HTTP.BindJSON "&req"}}; err != nil {
+		{{.HTTP.Problem "apperrors.FromBinding(err, &req)"}}
 	}
 
-	{{.Names.CamelCase}}, err := h.service.Update(c.Request.Context(), id, &req)
+	{{.Names.CamelCase}}, err := h.service.Update({{.HTTP.Context}}, id, &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+		{{.HTTP.Problem "err"}}
 	}
 
-	c.JSON(http.StatusOK, {{.Names.CamelCase}}.To{{.Names.PascalCase}}Response())
+	{{.HTTP.Respond "http.StatusOK" (printf "%s.To%sResponse()" .Names.CamelCase .Names.PascalCase)}}
 }
 
 // Delete handles DELETE /{{.Names.KebabPlural}}/:id
-func (h *{{.Names.PascalCase}}Handler) Delete(c *gin.Context) {
-	id := c.Param("id")
+func (h *{{.Names.PascalCase}}Handler) Delete({{.HTTP.HandlerParams}}){{.HTTP.HandlerResult}} {
+	id := {{.HTTP.Param "id"}}
 	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
-		return
+		{{.HTTP.Fail "http.StatusBadRequest" "\"Invalid ID\""}}
 	}
 
-	if err := h.service.Delete(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+	if err := h.service.Delete({{.HTTP.Context}}, id); err != nil {
+		{{.HTTP.Problem "err"}}
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "{{.DisplayName}} deleted successfully"})
+	{{.HTTP.Reply "http.StatusOK"}}{{.HTTP.Map}}{"message": "{{.DisplayName}} deleted successfully"})
 }
 
 // Setup{{.Names.PascalCase}}Routes sets up routes for {{.DisplayName}}
-func Setup{{.Names.PascalCase}}Routes(r *gin.RouterGroup, handler *{{.Names.PascalCase}}Handler) {
-	{{.Names.CamelPlural}} := r.Group("/{{.Names.KebabPlural}}")
-	{
-		{{.Names.CamelPlural}}.POST("", handler.Create)
-		{{.Names.CamelPlural}}.GET("", handler.GetAll)
-		{{.Names.CamelPlural}}.GET("/:id", handler.GetByID)
-		{{.Names.CamelPlural}}.PUT("/:id", handler.Update)
-		{{.Names.CamelPlural}}.DELETE("/:id", handler.Delete)
-	}
+func Setup{{.Names.PascalCase}}Routes({{.HTTP.Router}}, handler *{{.Names.PascalCase}}Handler) {
+{{- with .HTTP.Group .Names.CamelPlural (printf "/%s" .Names.KebabPlural)}}
+{{- with .Open}}
+	{{.}}
+{{- end}}
+	{{.Route "POST" "" "handler.Create"}}
+	{{.Route "GET" "" "handler.GetAll"}}
+	{{.Route "GET" "/:id" "handler.GetByID"}}
+	{{.Route "PUT" "/:id" "handler.Update"}}
+	{{.Route "DELETE" "/:id" "handler.Delete"}}
+{{- with .Close}}
+	{{.}}
+{{- end}}
+{{- end}}
 }
 `
 
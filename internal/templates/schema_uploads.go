@@ -0,0 +1,1513 @@
+package templates
+
+// BlobPackageTemplate generates the blob store interface, its configuration and upload validation
+const BlobPackageTemplate = `package blob
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Errors returned by stores and upload validation
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrEmpty           = errors.New("file is empty")
+	ErrTooLarge        = errors.New("file is too large")
+	ErrUnsupportedType = errors.New("unsupported file type")
+)
+
+// FormOverhead is the room left for the multipart framing around an uploaded file
+const FormOverhead = 1 << 20
+
+// Info describes a stored blob. Size is -1 when unknown.
+type Info struct {
+	Size        int64
+	ContentType string
+}
+
+// Store keeps uploaded files under slash separated keys
+type Store interface {
+	// Put stores the content of r under key, replacing any previous blob
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open returns the content of a blob, ErrNotFound when it does not exist
+	Open(ctx context.Context, key string) (io.ReadCloser, Info, error)
+	// Delete removes a blob, deleting a missing blob is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// Config mirrors the storage section of the project configuration
+type Config struct {
+	Provider     string   // "local" or "s3"
+	LocalPath    string   // Root directory of the local store
+	MaxFileSize  int64    // Project wide size limit in bytes, none when zero
+	AllowedTypes []string // MIME types of file fields without types of their own
+	S3           S3Config
+}
+
+// S3Config contains the settings of an S3 compatible bucket
+type S3Config struct {
+	Bucket    string
+	Region    string
+	AccessKey string
+	SecretKey string
+	Endpoint  string // Endpoint of S3 compatible services, buckets are then addressed path-style
+}
+
+// ConfigFromEnv reads the storage configuration from the STORAGE_* and S3_* variables
+func ConfigFromEnv() Config {
+	config := Config{
+		Provider:  getenv("STORAGE_PROVIDER", "local"),
+		LocalPath: getenv("STORAGE_LOCAL_PATH", "./uploads"),
+		S3: S3Config{
+			Bucket:    os.Getenv("S3_BUCKET"),
+			Region:    getenv("S3_REGION", "us-east-1"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+		},
+	}
+	if size, err := strconv.ParseInt(os.Getenv("STORAGE_MAX_FILE_SIZE"), 10, 64); err == nil {
+		config.MaxFileSize = size
+	}
+	for _, contentType := range strings.Split(os.Getenv("STORAGE_ALLOWED_TYPES"), ",") {
+		if contentType = strings.TrimSpace(contentType); contentType != "" {
+			config.AllowedTypes = append(config.AllowedTypes, contentType)
+		}
+	}
+	return config
+}
+
+// New creates the store of the configured provider
+func New(config Config) (Store, error) {
+	switch config.Provider {
+	case "", "local":
+		return NewLocalStore(config.LocalPath)
+	case "s3":
+		return NewS3Store(config.S3)
+	default:
+		return nil, fmt.Errorf("unsupported storage provider %q", config.Provider)
+	}
+}
+
+// FromEnv creates the store configured by the environment
+func FromEnv() (Store, Config, error) {
+	config := ConfigFromEnv()
+	store, err := New(config)
+	return store, config, err
+}
+
+// Rules limits the size and MIME types of the uploads of a field. Types may end in "/*"
+// to match a whole top-level type, every type is accepted when there are none.
+type Rules struct {
+	MaxSize int64
+	Types   []string
+}
+
+// Field describes the uploads of a file or image field
+type Field struct {
+	Rules
+	// Bounding box of the thumbnails of image fields, none when zero
+	ThumbnailWidth  int
+	ThumbnailHeight int
+}
+
+// HasThumbnail reports whether uploads of the field get a thumbnail
+func (f Field) HasThumbnail() bool {
+	return f.ThumbnailWidth > 0 && f.ThumbnailHeight > 0
+}
+
+// Limit applies the project wide limits of the configuration to the rules of a field
+func (c Config) Limit(rules Rules) Rules {
+	if c.MaxFileSize > 0 && c.MaxFileSize < rules.MaxSize {
+		rules.MaxSize = c.MaxFileSize
+	}
+	if len(rules.Types) == 0 {
+		rules.Types = c.AllowedTypes
+	}
+	return rules
+}
+
+// Read reads an upload, enforcing the size limit of the rules and checking the MIME type
+// detected from its content. Client supplied content types and names are never trusted.
+func Read(r io.Reader, rules Rules) ([]byte, string, error) {
+	data, err := io.ReadAll(io.LimitReader(r, rules.MaxSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) == 0 {
+		return nil, "", ErrEmpty
+	}
+	if int64(len(data)) > rules.MaxSize {
+		return nil, "", fmt.Errorf("%w, the limit is %d bytes", ErrTooLarge, rules.MaxSize)
+	}
+
+	contentType := DetectContentType(data)
+	if !Allowed(rules.Types, contentType) {
+		return nil, "", fmt.Errorf("%w %s", ErrUnsupportedType, contentType)
+	}
+	return data, contentType, nil
+}
+
+// DetectContentType returns the MIME type of data, without parameters
+func DetectContentType(data []byte) string {
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(data))
+	if err != nil {
+		return "application/octet-stream"
+	}
+	return mediaType
+}
+
+// Allowed reports whether contentType matches one of types, every type is allowed when types is empty
+func Allowed(types []string, contentType string) bool {
+	if len(types) == 0 {
+		return true
+	}
+	for _, allowed := range types {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if allowed == contentType {
+			return true
+		}
+		if strings.HasSuffix(allowed, "/*") && strings.HasPrefix(contentType, strings.TrimSuffix(allowed, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewKey returns a new random key below prefix with the extension of the content type
+func NewKey(prefix, contentType string) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return path.Join(prefix, hex.EncodeToString(random)) + Extension(contentType), nil
+}
+
+// extensions maps common content types onto their file extension, the mime package
+// returns platform dependent extensions for some of them
+var extensions = map[string]string{
+	"application/pdf":  ".pdf",
+	"application/zip":  ".zip",
+	"application/json": ".json",
+	"image/gif":        ".gif",
+	"image/jpeg":       ".jpg",
+	"image/png":        ".png",
+	"image/webp":       ".webp",
+	"text/csv":         ".csv",
+	"text/plain":       ".txt",
+}
+
+// Extension returns the file extension of a content type
+func Extension(contentType string) string {
+	if ext, ok := extensions[contentType]; ok {
+		return ext
+	}
+	if exts, err := mime.ExtensionsByType(contentType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	return ".bin"
+}
+
+// ContentType returns the content type of a key from its extension
+func ContentType(key string) string {
+	ext := strings.ToLower(path.Ext(key))
+	for contentType, known := range extensions {
+		if known == ext {
+			return contentType
+		}
+	}
+	if mediaType, _, err := mime.ParseMediaType(mime.TypeByExtension(ext)); err == nil {
+		return mediaType
+	}
+	return "application/octet-stream"
+}
+
+// Disposition returns the Content-Disposition of a served blob. Images are shown inline,
+// other files are downloaded so that uploaded documents never render in the API origin.
+func Disposition(contentType string) string {
+	if strings.HasPrefix(contentType, "image/") {
+		return "inline"
+	}
+	return "attachment"
+}
+
+// DeleteAll deletes the blobs of keys, skipping empty keys, and returns the first error
+func DeleteAll(ctx context.Context, store Store, keys ...string) error {
+	var first error
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if err := store.Delete(ctx, key); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func getenv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+`
+
+// BlobLocalTemplate generates the local disk blob store
+const BlobLocalTemplate = `package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStore keeps blobs as files below a root directory
+type LocalStore struct {
+	root string
+}
+
+// NewLocalStore creates a store below root, creating the directory when missing
+func NewLocalStore(root string) (*LocalStore, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create the storage directory: %w", err)
+	}
+	return &LocalStore{root: root}, nil
+}
+
+// Put stores a blob, writing a temporary file renamed into place once complete
+func (s *LocalStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+// Open opens a blob
+func (s *LocalStore) Open(ctx context.Context, key string) (io.ReadCloser, Info, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, Info{}, err
+	}
+	file, err := os.Open(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, Info{}, ErrNotFound
+	}
+	if err != nil {
+		return nil, Info{}, err
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, Info{}, err
+	}
+	return file, Info{Size: stat.Size(), ContentType: ContentType(key)}, nil
+}
+
+// Delete deletes a blob
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path returns the file of a key, rejecting keys that would leave the root directory
+func (s *LocalStore) path(key string) (string, error) {
+	if key == "" || strings.Contains(key, "\\") || path.Clean(key) != key ||
+		path.IsAbs(key) || key == ".." || strings.HasPrefix(key, "../") {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+`
+
+// BlobS3Template generates the S3 compatible blob store
+const BlobS3Template = `package blob
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// S3Store keeps blobs in an S3 compatible bucket. Requests are signed with AWS Signature
+// Version 4, anonymously when no access key is configured.
+type S3Store struct {
+	Client *http.Client
+
+	bucket    string
+	region    string
+	accessKey string
+	secretKey string
+	endpoint  *url.URL
+	pathStyle bool
+	now       func() time.Time
+}
+
+// NewS3Store creates a store for a bucket. Buckets of S3 compatible services are addressed
+// path-style below their endpoint, AWS buckets through their virtual-hosted name.
+func NewS3Store(config S3Config) (*S3Store, error) {
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("an S3 bucket is required")
+	}
+	region := config.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	endpoint := strings.TrimSuffix(config.Endpoint, "/")
+	pathStyle := endpoint != ""
+	if endpoint == "" {
+		endpoint = "https://s3." + region + ".amazonaws.com"
+	}
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", config.Endpoint)
+	}
+
+	return &S3Store{
+		Client:    http.DefaultClient,
+		bucket:    config.Bucket,
+		region:    region,
+		accessKey: config.AccessKey,
+		secretKey: config.SecretKey,
+		endpoint:  parsed,
+		pathStyle: pathStyle,
+		now:       time.Now,
+	}, nil
+}
+
+// Put uploads a blob. The content is read in full since the signature covers its hash.
+func (s *S3Store) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	req, err := s.request(ctx, http.MethodPut, key, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s.error(resp)
+	}
+	return nil
+}
+
+// Open downloads a blob
+func (s *S3Store) Open(ctx context.Context, key string) (io.ReadCloser, Info, error) {
+	req, err := s.request(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, Info{}, err
+	}
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, Info{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, Info{}, ErrNotFound
+		}
+		return nil, Info{}, s.error(resp)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = ContentType(key)
+	}
+	return resp.Body, Info{Size: resp.ContentLength, ContentType: contentType}, nil
+}
+
+// Delete deletes a blob
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	req, err := s.request(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return s.error(resp)
+	}
+}
+
+// request creates the signed request of an object
+func (s *S3Store) request(ctx context.Context, method, key string, body []byte) (*http.Request, error) {
+	if key == "" {
+		return nil, fmt.Errorf("invalid blob key %q", key)
+	}
+	target := *s.endpoint
+	if s.pathStyle {
+		target.Path = "/" + s.bucket + "/" + key
+		target.RawPath = "/" + escapePath(s.bucket) + "/" + escapePath(key)
+	} else {
+		target.Host = s.bucket + "." + target.Host
+		target.Path = "/" + key
+		target.RawPath = "/" + escapePath(key)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if s.accessKey != "" {
+		s.sign(req, body)
+	}
+	return req, nil
+}
+
+// signedHeaders lists the headers covered by request signatures
+const signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+
+// sign adds the AWS Signature Version 4 headers to a request
+func (s *S3Store) sign(req *http.Request, body []byte) {
+	now := s.now().UTC()
+	req.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	req.Header.Set("X-Amz-Content-Sha256", sha256Hex(body))
+
+	scope := now.Format("20060102") + "/" + s.region + "/s3/aws4_request"
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, signedHeaders, signature(s.secretKey, scope, req)))
+}
+
+// signature computes the Signature Version 4 of a request signed with signedHeaders.
+// The signing key is derived from the secret key and every part of the credential scope.
+func signature(secretKey, scope string, req *http.Request) string {
+	canonicalHeaders := "host:" + req.Host + "\n" +
+		"x-amz-content-sha256:" + req.Header.Get("X-Amz-Content-Sha256") + "\n" +
+		"x-amz-date:" + req.Header.Get("X-Amz-Date") + "\n"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		req.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		req.Header.Get("X-Amz-Date"),
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := []byte("AWS4" + secretKey)
+	for _, part := range strings.Split(scope, "/") {
+		key = hmacSHA256(key, part)
+	}
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// error converts an S3 error response
+func (s *S3Store) error(resp *http.Response) error {
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("S3 request failed with %s: %s", resp.Status, strings.TrimSpace(string(message)))
+}
+
+// escapePath percent-encodes a path the way S3 signatures expect: every byte except
+// unreserved characters and slashes
+func escapePath(p string) string {
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-_.~/", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+`
+
+// BlobThumbnailTemplate generates the thumbnails of image uploads
+const BlobThumbnailTemplate = `package blob
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"path"
+	"strings"
+)
+
+// MaxImagePixels limits the size of images decoded for thumbnails, so that small
+// compressed uploads can't expand into huge bitmaps
+const MaxImagePixels = 40_000_000
+
+// ThumbnailKey returns the key of the thumbnail of an image blob
+func ThumbnailKey(key string) string {
+	return strings.TrimSuffix(key, path.Ext(key)) + "_thumb.jpg"
+}
+
+// Thumbnail scales a JPEG, PNG or GIF image down to fit width x height, keeping its aspect
+// ratio, and encodes it as JPEG. Transparent areas are flattened onto white.
+func Thumbnail(data []byte, width, height int) ([]byte, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedType, err)
+	}
+	if config.Width*config.Height > MaxImagePixels {
+		return nil, fmt.Errorf("%w: the image has %dx%d pixels", ErrTooLarge, config.Width, config.Height)
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedType, err)
+	}
+
+	w, h := fit(src.Bounds().Dx(), src.Bounds().Dy(), width, height)
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, scale(src, w, h), &jpeg.Options{Quality: 85}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// fit returns the size of a w x h image scaled down to fit a bounding box, images
+// already fitting keep their size
+func fit(w, h, maxWidth, maxHeight int) (int, int) {
+	if w <= maxWidth && h <= maxHeight {
+		return w, h
+	}
+	if w*maxHeight > h*maxWidth {
+		return maxWidth, max(1, h*maxWidth/w)
+	}
+	return max(1, w*maxHeight/h), maxHeight
+}
+
+// scale resizes src to w x h, averaging the source pixels covered by every target pixel
+func scale(src image.Image, w, h int) *image.RGBA {
+	bounds := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/h
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*bounds.Dy()/h)
+		for x := 0; x < w; x++ {
+			x0 := bounds.Min.X + x*bounds.Dx()/w
+			x1 := max(x0+1, bounds.Min.X+(x+1)*bounds.Dx()/w)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+					n++
+				}
+			}
+			// Colors are alpha-premultiplied, adding the uncovered part of white flattens them
+			white := 0xffff - a/n
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r/n + white),
+				G: uint16(g/n + white),
+				B: uint16(b/n + white),
+				A: 0xffff,
+			})
+		}
+	}
+	return dst
+}
+`
+
+// BlobTestTemplate generates the tests of the blob stores against local disk and an S3 stand-in
+const BlobTestTemplate = `package blob
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testStore runs the behaviour every store shares
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+	key := "items/1/file/a b.txt"
+
+	if err := store.Put(ctx, key, strings.NewReader("hello"), 5, "text/plain"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	reader, info, err := store.Open(ctx, key)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	content, _ := io.ReadAll(reader)
+	reader.Close()
+	if string(content) != "hello" || info.Size != 5 || info.ContentType != "text/plain" {
+		t.Fatalf("Open returned %q with %+v", content, info)
+	}
+
+	if err := store.Put(ctx, key, strings.NewReader("replaced"), 8, "text/plain"); err != nil {
+		t.Fatalf("Put replacing a blob: %v", err)
+	}
+	reader, _, _ = store.Open(ctx, key)
+	content, _ = io.ReadAll(reader)
+	reader.Close()
+	if string(content) != "replaced" {
+		t.Fatalf("expected the replaced blob, got %q", content)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, _, err := store.Open(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after Delete, got %v", err)
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("deleting a missing blob should succeed, got %v", err)
+	}
+}
+
+func TestLocalStore(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, store)
+
+	for _, key := range []string{"", "../escape.txt", "/abs.txt", "a/../../b.txt", "a\\b.txt"} {
+		if err := store.Put(context.Background(), key, strings.NewReader("x"), 1, "text/plain"); err == nil {
+			t.Errorf("expected key %q to be rejected", key)
+		}
+	}
+}
+
+// fakeS3 is an in-memory stand-in for an S3 bucket checking request signatures
+type fakeS3 struct {
+	t         *testing.T
+	secretKey string
+	mu        sync.Mutex
+	objects   map[string][]byte
+	types     map[string]string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	authorization := r.Header.Get("Authorization")
+	credential := strings.TrimPrefix(strings.Split(authorization, ",")[0], "AWS4-HMAC-SHA256 Credential=")
+	parts := strings.SplitN(credential, "/", 2)
+	if len(parts) != 2 || parts[0] != "test-access" {
+		http.Error(w, "missing credentials", http.StatusForbidden)
+		return
+	}
+	if !strings.HasSuffix(authorization, "Signature="+signature(f.secretKey, parts[1], r)) {
+		http.Error(w, "SignatureDoesNotMatch", http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := r.URL.Path
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("X-Amz-Content-Sha256") != sha256Hex(body) {
+			http.Error(w, "XAmzContentSHA256Mismatch", http.StatusBadRequest)
+			return
+		}
+		f.objects[key] = body
+		f.types[key] = r.Header.Get("Content-Type")
+	case http.MethodGet:
+		body, ok := f.objects[key]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", f.types[key])
+		w.Write(body)
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestS3Store(t *testing.T) {
+	fake := &fakeS3{t: t, secretKey: "test-secret", objects: map[string][]byte{}, types: map[string]string{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	store, err := NewS3Store(S3Config{
+		Bucket:    "uploads",
+		Region:    "eu-west-1",
+		AccessKey: "test-access",
+		SecretKey: "test-secret",
+		Endpoint:  server.URL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, store)
+
+	if err := store.Put(context.Background(), "items/2/file/x.txt", strings.NewReader("x"), 1, "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fake.objects["/uploads/items/2/file/x.txt"]; !ok {
+		t.Fatalf("expected a path-style object below the bucket, got %v", fake.objects)
+	}
+
+	wrong, _ := NewS3Store(S3Config{Bucket: "uploads", AccessKey: "test-access", SecretKey: "wrong", Endpoint: server.URL})
+	if err := wrong.Put(context.Background(), "x.txt", strings.NewReader("x"), 1, "text/plain"); err == nil {
+		t.Fatal("expected requests signed with a wrong secret to fail")
+	}
+}
+
+func TestRead(t *testing.T) {
+	png := testImage(t, 4, 4)
+
+	data, contentType, err := Read(bytes.NewReader(png), Rules{MaxSize: 1 << 20, Types: []string{"image/*"}})
+	if err != nil || contentType != "image/png" || len(data) != len(png) {
+		t.Fatalf("Read returned %d bytes of %q, %v", len(data), contentType, err)
+	}
+	if _, _, err := Read(bytes.NewReader(png), Rules{MaxSize: 10}); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("expected ErrTooLarge, got %v", err)
+	}
+	if _, _, err := Read(strings.NewReader("<html><body>hi</body></html>"), Rules{MaxSize: 1 << 20, Types: []string{"image/png"}}); !errors.Is(err, ErrUnsupportedType) {
+		t.Fatalf("expected ErrUnsupportedType, got %v", err)
+	}
+	if _, _, err := Read(strings.NewReader(""), Rules{MaxSize: 1 << 20}); !errors.Is(err, ErrEmpty) {
+		t.Fatalf("expected ErrEmpty, got %v", err)
+	}
+}
+
+func TestConfigLimit(t *testing.T) {
+	config := Config{MaxFileSize: 100, AllowedTypes: []string{"application/pdf"}}
+	if rules := config.Limit(Rules{MaxSize: 1000}); rules.MaxSize != 100 || len(rules.Types) != 1 {
+		t.Fatalf("expected the project limits, got %+v", rules)
+	}
+	if rules := config.Limit(Rules{MaxSize: 10, Types: []string{"image/png"}}); rules.MaxSize != 10 || rules.Types[0] != "image/png" {
+		t.Fatalf("expected the field limits, got %+v", rules)
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	thumbnail, err := Thumbnail(testImage(t, 400, 200), 100, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(thumbnail))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := img.Bounds().Size(); size.X != 100 || size.Y != 50 {
+		t.Fatalf("expected a 100x50 thumbnail, got %v", size)
+	}
+
+	if _, err := Thumbnail([]byte("not an image"), 100, 100); !errors.Is(err, ErrUnsupportedType) {
+		t.Fatalf("expected ErrUnsupportedType, got %v", err)
+	}
+	if key := ThumbnailKey("items/1/image/abc.png"); key != "items/1/image/abc_thumb.jpg" {
+		t.Fatalf("unexpected thumbnail key %q", key)
+	}
+}
+
+// testImage encodes a w x h PNG
+func testImage(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+`
+
+// SchemaUploadModelTemplate generates the upload rules and blob key accessors of a model
+const SchemaUploadModelTemplate = `package models
+
+import (
+	"{{.Module}}/internal/blob"
+)
+
+// {{.Names.PascalCase}}UploadFields lists the upload rules of the file and image fields of {{.DisplayName}}
+var {{.Names.PascalCase}}UploadFields = map[string]blob.Field{
+{{- range .Uploads}}
+	"{{.Name}}": {
+		Rules: blob.Rules{MaxSize: {{.MaxSize}}{{with .AllowedTypes}}, Types: []string{ {{- range $i, $t := .}}{{if $i}}, {{end}}{{printf "%q" $t}}{{end}}}{{end}}},
+{{- with .Thumbnail}}
+		ThumbnailWidth:  {{.Width}},
+		ThumbnailHeight: {{.Height}},
+{{- end}}
+	},
+{{- end}}
+}
+
+// UploadKey returns the blob key stored in an upload field
+func (m *{{.Names.PascalCase}}) UploadKey(field string) string {
+	switch field {
+{{- range .Uploads}}
+	case "{{.Name}}":
+		return m.{{.GoName}}
+{{- end}}
+	}
+	return ""
+}
+
+// SetUploadKey stores the blob key of an upload field
+func (m *{{.Names.PascalCase}}) SetUploadKey(field, key string) {
+	switch field {
+{{- range .Uploads}}
+	case "{{.Name}}":
+		m.{{.GoName}} = key
+{{- end}}
+	}
+}
+
+// UploadKeys returns the blob keys of the given upload fields, all of them when none are
+// given, including the thumbnails of image fields
+func (m *{{.Names.PascalCase}}) UploadKeys(fields ...string) []string {
+	if len(fields) == 0 {
+		fields = []string{ {{- range $i, $u := .Uploads}}{{if $i}}, {{end}}"{{$u.Name}}"{{end}}}
+	}
+	var keys []string
+	for _, field := range fields {
+		key := m.UploadKey(field)
+		if key == "" {
+			continue
+		}
+		keys = append(keys, key)
+		if {{.Names.PascalCase}}UploadFields[field].HasThumbnail() {
+			keys = append(keys, blob.ThumbnailKey(key))
+		}
+	}
+	return keys
+}
+`
+
+// SchemaBlobRepositoryTemplate generates the repository decorator removing unreferenced uploads
+const SchemaBlobRepositoryTemplate = `package repositories
+
+import (
+	"context"
+	"log"
+
+	"{{.Module}}/internal/blob"
+	"{{.Module}}/internal/models"
+)
+
+// {{.Names.PascalCase}}BlobRepository decorates a {{.Names.PascalCase}} repository, deleting the uploaded files of
+// {{.Names.Plural}} once updates or deletes through it leave them unreferenced
+type {{.Names.PascalCase}}BlobRepository struct {
+	{{.Names.PascalCase}}RepositoryInterface
+	store blob.Store
+}
+
+// New{{.Names.PascalCase}}BlobRepository creates a {{.Names.PascalCase}} repository cleaning up uploaded files
+func New{{.Names.PascalCase}}BlobRepository(next {{.Names.PascalCase}}RepositoryInterface, store blob.Store) *{{.Names.PascalCase}}BlobRepository {
+	return &{{.Names.PascalCase}}BlobRepository{ {{- .Names.PascalCase}}RepositoryInterface: next, store: store}
+}
+
+// Update updates a {{.Names.Singular}} and deletes the files it no longer references
+func (r *{{.Names.PascalCase}}BlobRepository) Update(ctx context.Context, {{.Names.CamelCase}} *models.{{.Names.PascalCase}}) error {
+	previous, err := r.{{.Names.PascalCase}}RepositoryInterface.GetByID(ctx, {{.Names.CamelCase}}.ID.Hex())
+	if err != nil {
+		return err
+	}
+	if err := r.{{.Names.PascalCase}}RepositoryInterface.Update(ctx, {{.Names.CamelCase}}); err != nil {
+		return err
+	}
+	if previous != nil {
+		r.deleteUnreferenced(ctx, previous.UploadKeys(), {{.Names.CamelCase}}.UploadKeys())
+	}
+	return nil
+}
+
+// Delete deletes a {{.Names.Singular}} and its files
+func (r *{{.Names.PascalCase}}BlobRepository) Delete(ctx context.Context, id string) error {
+	return r.deleteWith(ctx, id, r.{{.Names.PascalCase}}RepositoryInterface.Delete)
+}
+
+// HardDelete permanently deletes a {{.Names.Singular}} and its files
+func (r *{{.Names.PascalCase}}BlobRepository) HardDelete(ctx context.Context, id string) error {
+	return r.deleteWith(ctx, id, r.{{.Names.PascalCase}}RepositoryInterface.HardDelete)
+}
+
+func (r *{{.Names.PascalCase}}BlobRepository) deleteWith(ctx context.Context, id string, del func(ctx context.Context, id string) error) error {
+	previous, err := r.{{.Names.PascalCase}}RepositoryInterface.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := del(ctx, id); err != nil {
+		return err
+	}
+	if previous != nil {
+		r.deleteUnreferenced(ctx, previous.UploadKeys(), nil)
+	}
+	return nil
+}
+
+// deleteUnreferenced deletes the previous keys missing from the current ones. The change is
+// already stored, so failures are logged and leave orphaned files behind.
+func (r *{{.Names.PascalCase}}BlobRepository) deleteUnreferenced(ctx context.Context, previous, current []string) {
+	referenced := make(map[string]bool, len(current))
+	for _, key := range current {
+		referenced[key] = true
+	}
+	for _, key := range previous {
+		if referenced[key] {
+			continue
+		}
+		if err := r.store.Delete(ctx, key); err != nil {
+			log.Printf("failed to delete the {{.Names.Singular}} file %s: %v", key, err)
+		}
+	}
+}
+`
+
+// SchemaUploadServiceTemplate generates the upload service of the file and image fields of a resource
+const SchemaUploadServiceTemplate = `package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"path"
+
+	"{{.Module}}/internal/blob"
+	"{{.Module}}/internal/models"
+	"{{.Module}}/internal/repositories"
+)
+
+// {{.Names.PascalCase}}UploadService stores the files of the file and image fields of {{.Names.Plural}}
+type {{.Names.PascalCase}}UploadService struct {
+	repo   repositories.{{.Names.PascalCase}}RepositoryInterface
+	store  blob.Store
+	config blob.Config
+}
+
+// New{{.Names.PascalCase}}UploadService creates a new {{.Names.PascalCase}} upload service
+func New{{.Names.PascalCase}}UploadService(repo repositories.{{.Names.PascalCase}}RepositoryInterface, store blob.Store, config blob.Config) *{{.Names.PascalCase}}UploadService {
+	return &{{.Names.PascalCase}}UploadService{repo: repo, store: store, config: config}
+}
+
+// Rules returns the upload rules of a field with the project wide limits applied
+func (s *{{.Names.PascalCase}}UploadService) Rules(field string) blob.Rules {
+	return s.config.Limit(models.{{.Names.PascalCase}}UploadFields[field].Rules)
+}
+
+// Upload stores a file in an upload field of a {{.Names.Singular}}, with the thumbnail of image fields,
+// and deletes the file it replaces. The type of the file is detected from its content.
+func (s *{{.Names.PascalCase}}UploadService) Upload(ctx context.Context, id, field string, r io.Reader) (*models.{{.Names.PascalCase}}, error) {
+	upload, ok := models.{{.Names.PascalCase}}UploadFields[field]
+	if !ok {
+		return nil, fmt.Errorf("upload field %s %w", field, blob.ErrNotFound)
+	}
+	{{.Names.CamelCase}}, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	data, contentType, err := blob.Read(r, s.Rules(field))
+	if err != nil {
+		return nil, err
+	}
+	key, err := blob.NewKey(path.Join("{{.Names.KebabPlural}}", {{.Names.CamelCase}}.ID.Hex(), field), contentType)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return nil, fmt.Errorf("failed to store the %s file: %w", field, err)
+	}
+	stored := []string{key}
+
+	if upload.HasThumbnail() {
+		thumbnail, err := blob.Thumbnail(data, upload.ThumbnailWidth, upload.ThumbnailHeight)
+		if err == nil {
+			err = s.store.Put(ctx, blob.ThumbnailKey(key), bytes.NewReader(thumbnail), int64(len(thumbnail)), "image/jpeg")
+		}
+		if err != nil {
+			s.discard(ctx, stored)
+			return nil, fmt.Errorf("failed to create the %s thumbnail: %w", field, err)
+		}
+		stored = append(stored, blob.ThumbnailKey(key))
+	}
+
+	replaced := {{.Names.CamelCase}}.UploadKeys(field)
+	{{.Names.CamelCase}}.SetUploadKey(field, key)
+	if err := s.repo.Update(ctx, {{.Names.CamelCase}}); err != nil {
+		s.discard(ctx, stored)
+		return nil, fmt.Errorf("failed to update {{.Names.Singular}}: %w", err)
+	}
+	s.discard(ctx, replaced)
+	return {{.Names.CamelCase}}, nil
+}
+
+// Open opens the file of an upload field of a {{.Names.Singular}}, or its thumbnail
+func (s *{{.Names.PascalCase}}UploadService) Open(ctx context.Context, id, field string, thumbnail bool) (io.ReadCloser, blob.Info, error) {
+	upload, ok := models.{{.Names.PascalCase}}UploadFields[field]
+	if !ok {
+		return nil, blob.Info{}, fmt.Errorf("upload field %s %w", field, blob.ErrNotFound)
+	}
+	if thumbnail && !upload.HasThumbnail() {
+		return nil, blob.Info{}, fmt.Errorf("%s thumbnail %w", field, blob.ErrNotFound)
+	}
+	{{.Names.CamelCase}}, err := s.get(ctx, id)
+	if err != nil {
+		return nil, blob.Info{}, err
+	}
+
+	key := {{.Names.CamelCase}}.UploadKey(field)
+	if key == "" {
+		return nil, blob.Info{}, fmt.Errorf("%s file %w", field, blob.ErrNotFound)
+	}
+	if thumbnail {
+		key = blob.ThumbnailKey(key)
+	}
+	return s.store.Open(ctx, key)
+}
+
+// Remove clears an upload field of a {{.Names.Singular}} and deletes its file
+func (s *{{.Names.PascalCase}}UploadService) Remove(ctx context.Context, id, field string) (*models.{{.Names.PascalCase}}, error) {
+	if _, ok := models.{{.Names.PascalCase}}UploadFields[field]; !ok {
+		return nil, fmt.Errorf("upload field %s %w", field, blob.ErrNotFound)
+	}
+	{{.Names.CamelCase}}, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	removed := {{.Names.CamelCase}}.UploadKeys(field)
+	if len(removed) == 0 {
+		return {{.Names.CamelCase}}, nil
+	}
+	{{.Names.CamelCase}}.SetUploadKey(field, "")
+	if err := s.repo.Update(ctx, {{.Names.CamelCase}}); err != nil {
+		return nil, fmt.Errorf("failed to update {{.Names.Singular}}: %w", err)
+	}
+	s.discard(ctx, removed)
+	return {{.Names.CamelCase}}, nil
+}
+
+// get loads a {{.Names.Singular}}, failing with blob.ErrNotFound when it does not exist
+func (s *{{.Names.PascalCase}}UploadService) get(ctx context.Context, id string) (*models.{{.Names.PascalCase}}, error) {
+	{{.Names.CamelCase}}, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get {{.Names.Singular}}: %w", err)
+	}
+	if {{.Names.CamelCase}} == nil {
+		return nil, fmt.Errorf("{{.Names.Singular}} %w", blob.ErrNotFound)
+	}
+	return {{.Names.CamelCase}}, nil
+}
+
+// discard deletes files that are not referenced, logging failures
+func (s *{{.Names.PascalCase}}UploadService) discard(ctx context.Context, keys []string) {
+	if err := blob.DeleteAll(ctx, s.store, keys...); err != nil {
+		log.Printf("failed to delete {{.Names.Singular}} files: %v", err)
+	}
+}
+`
+
+// SchemaUploadServiceTestTemplate generates the tests of the upload service against local disk
+const SchemaUploadServiceTestTemplate = `package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"{{.Module}}/internal/blob"
+	"{{.Module}}/internal/models"
+	"{{.Module}}/internal/repositories"
+)
+
+// fake{{.Names.PascalCase}}UploadRepository keeps {{.Names.Plural}} in memory
+type fake{{.Names.PascalCase}}UploadRepository struct {
+	repositories.{{.Names.PascalCase}}RepositoryInterface
+	mu    sync.Mutex
+	items map[string]models.{{.Names.PascalCase}}
+}
+
+func (r *fake{{.Names.PascalCase}}UploadRepository) GetByID(ctx context.Context, id string) (*models.{{.Names.PascalCase}}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	item, ok := r.items[id]
+	if !ok {
+		return nil, nil
+	}
+	return &item, nil
+}
+
+func (r *fake{{.Names.PascalCase}}UploadRepository) Update(ctx context.Context, {{.Names.CamelCase}} *models.{{.Names.PascalCase}}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.items[{{.Names.CamelCase}}.ID.Hex()] = *{{.Names.CamelCase}}
+	return nil
+}
+
+func (r *fake{{.Names.PascalCase}}UploadRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.items, id)
+	return nil
+}
+
+// test{{.Names.PascalCase}}Upload returns content accepted by the field
+func test{{.Names.PascalCase}}Upload(t *testing.T, field string) []byte {
+	var img bytes.Buffer
+	if err := png.Encode(&img, image.NewRGBA(image.Rect(0, 0, 64, 32))); err != nil {
+		t.Fatal(err)
+	}
+	candidates := [][]byte{img.Bytes(), []byte("uploaded file content"), []byte("%PDF-1.4\n%%EOF\n")}
+
+	rules := models.{{.Names.PascalCase}}UploadFields[field].Rules
+	for _, content := range candidates {
+		if blob.Allowed(rules.Types, blob.DetectContentType(content)) {
+			return content
+		}
+	}
+	t.Skipf("no sample content matches the types of %s", field)
+	return nil
+}
+
+func Test{{.Names.PascalCase}}UploadService(t *testing.T) {
+	ctx := context.Background()
+	store, err := blob.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := primitive.NewObjectID()
+	repo := &fake{{.Names.PascalCase}}UploadRepository{items: map[string]models.{{.Names.PascalCase}}{id.Hex(): {ID: id}}}
+	service := New{{.Names.PascalCase}}UploadService(repo, store, blob.Config{})
+
+	exists := func(key string) bool {
+		reader, _, err := store.Open(ctx, key)
+		if err == nil {
+			reader.Close()
+		}
+		return err == nil
+	}
+
+	for field := range models.{{.Names.PascalCase}}UploadFields {
+		content := test{{.Names.PascalCase}}Upload(t, field)
+
+		first, err := service.Upload(ctx, id.Hex(), field, bytes.NewReader(content))
+		if err != nil {
+			t.Fatalf("Upload %s: %v", field, err)
+		}
+		firstKeys := first.UploadKeys(field)
+		if len(firstKeys) == 0 || !strings.HasPrefix(firstKeys[0], "{{.Names.KebabPlural}}/"+id.Hex()+"/"+field+"/") {
+			t.Fatalf("unexpected %s keys %v", field, firstKeys)
+		}
+
+		reader, _, err := service.Open(ctx, id.Hex(), field, false)
+		if err != nil {
+			t.Fatalf("Open %s: %v", field, err)
+		}
+		stored, _ := io.ReadAll(reader)
+		reader.Close()
+		if !bytes.Equal(stored, content) {
+			t.Fatalf("Open %s returned other content", field)
+		}
+		if models.{{.Names.PascalCase}}UploadFields[field].HasThumbnail() {
+			reader, info, err := service.Open(ctx, id.Hex(), field, true)
+			if err != nil || info.ContentType != "image/jpeg" {
+				t.Fatalf("expected a JPEG thumbnail of %s, got %+v, %v", field, info, err)
+			}
+			reader.Close()
+		}
+
+		// Replacing the file deletes the previous one
+		second, err := service.Upload(ctx, id.Hex(), field, bytes.NewReader(content))
+		if err != nil {
+			t.Fatalf("Upload replacing %s: %v", field, err)
+		}
+		for _, key := range firstKeys {
+			if exists(key) {
+				t.Fatalf("expected the replaced %s file %s to be deleted", field, key)
+			}
+		}
+
+		if _, err := service.Remove(ctx, id.Hex(), field); err != nil {
+			t.Fatalf("Remove %s: %v", field, err)
+		}
+		for _, key := range second.UploadKeys(field) {
+			if exists(key) {
+				t.Fatalf("expected the removed %s file %s to be deleted", field, key)
+			}
+		}
+		if _, _, err := service.Open(ctx, id.Hex(), field, false); !errors.Is(err, blob.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound after Remove, got %v", err)
+		}
+
+		tooLarge := bytes.Repeat([]byte("a"), int(service.Rules(field).MaxSize)+1)
+		if _, err := service.Upload(ctx, id.Hex(), field, bytes.NewReader(tooLarge)); !errors.Is(err, blob.ErrTooLarge) {
+			t.Fatalf("expected ErrTooLarge for %s, got %v", field, err)
+		}
+	}
+
+	if _, err := service.Upload(ctx, primitive.NewObjectID().Hex(), "{{(index .Uploads 0).Name}}", strings.NewReader("x")); !errors.Is(err, blob.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a missing {{.Names.Singular}}, got %v", err)
+	}
+}
+
+func Test{{.Names.PascalCase}}BlobRepositoryDeletesFiles(t *testing.T) {
+	ctx := context.Background()
+	store, err := blob.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := primitive.NewObjectID()
+	repo := repositories.New{{.Names.PascalCase}}BlobRepository(&fake{{.Names.PascalCase}}UploadRepository{items: map[string]models.{{.Names.PascalCase}}{id.Hex(): {ID: id}}}, store)
+	service := New{{.Names.PascalCase}}UploadService(repo, store, blob.Config{})
+
+	var keys []string
+	for field := range models.{{.Names.PascalCase}}UploadFields {
+		{{.Names.CamelCase}}, err := service.Upload(ctx, id.Hex(), field, bytes.NewReader(test{{.Names.PascalCase}}Upload(t, field)))
+		if err != nil {
+			t.Fatalf("Upload %s: %v", field, err)
+		}
+		keys = {{.Names.CamelCase}}.UploadKeys()
+	}
+
+	if err := repo.Delete(ctx, id.Hex()); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range keys {
+		if _, _, err := store.Open(ctx, key); !errors.Is(err, blob.ErrNotFound) {
+			t.Fatalf("expected %s to be deleted with the {{.Names.Singular}}, got %v", key, err)
+		}
+	}
+}
+`
+
+// SchemaUploadHandlerTemplate generates the multipart upload HTTP handler of a resource
+const SchemaUploadHandlerTemplate = `package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+{{range .HTTP.Imports}}	"{{.}}"
+{{end}}	"{{.Module}}/internal/blob"
+	"{{.Module}}/internal/services"
+)
+
+// {{.Names.PascalCase}}UploadHandler handles the file and image uploads of {{.DisplayName}}
+type {{.Names.PascalCase}}UploadHandler struct {
+	service *services.{{.Names.PascalCase}}UploadService
+}
+
+// New{{.Names.PascalCase}}UploadHandler creates a new {{.Names.PascalCase}} upload handler
+func New{{.Names.PascalCase}}UploadHandler(service *services.{{.Names.PascalCase}}UploadService) *{{.Names.PascalCase}}UploadHandler {
+	return &{{.Names.PascalCase}}UploadHandler{service: service}
+}
+
+// Upload handles POST /{{.Names.KebabPlural}}/:id/<field> with the file in the "file" part of a multipart form
+func (h *{{.Names.PascalCase}}UploadHandler) Upload(field string) func({{.HTTP.HandlerParams}}){{.HTTP.HandlerResult}} {
+	return func({{.HTTP.HandlerParams}}){{.HTTP.HandlerResult}} {
+		id := {{.HTTP.Param "id"}}
+{{- if .HTTP.NetHTTP}}
+		r.Body = http.MaxBytesReader(w, r.Body, h.service.Rules(field).MaxSize+blob.FormOverhead)
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			{{.HTTP.Fail "h.status(err, http.StatusBadRequest)" "err.Error()"}}
+		}
+{{- else}}
+{{- if .HTTP.Is "gin"}}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.service.Rules(field).MaxSize+blob.FormOverhead)
+{{- else if .HTTP.Is "echo"}}
+		c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, h.service.Rules(field).MaxSize+blob.FormOverhead)
+{{- else}}
+		// Fiber limits request bodies through its BodyLimit setting
+{{- end}}
+		header, err := c.FormFile("file")
+		if err != nil {
+			{{.HTTP.Fail "h.status(err, http.StatusBadRequest)" "err.Error()"}}
+		}
+		file, err := header.Open()
+		if err != nil {
+			{{.HTTP.Fail "http.StatusInternalServerError" "err.Error()"}}
+		}
+{{- end}}
+		defer file.Close()
+
+		{{.Names.CamelCase}}, err := h.service.Upload({{.HTTP.Context}}, id, field, file)
+		if err != nil {
+			{{.HTTP.Fail "h.status(err, http.StatusInternalServerError)" "err.Error()"}}
+		}
+
+		{{.HTTP.Respond "http.StatusOK" (printf "%s.To%sResponse()" .Names.CamelCase .Names.PascalCase)}}
+	}
+}
+
+// Download handles GET /{{.Names.KebabPlural}}/:id/<field>, ?thumbnail=true serves the thumbnail of image fields
+func (h *{{.Names.PascalCase}}UploadHandler) Download(field string) func({{.HTTP.HandlerParams}}){{.HTTP.HandlerResult}} {
+	return func({{.HTTP.HandlerParams}}){{.HTTP.HandlerResult}} {
+		id := {{.HTTP.Param "id"}}
+		thumbnail, _ := strconv.ParseBool({{.HTTP.Query "thumbnail"}})
+
+		reader, info, err := h.service.Open({{.HTTP.Context}}, id, field, thumbnail)
+		if err != nil {
+			{{.HTTP.Fail "h.status(err, http.StatusInternalServerError)" "err.Error()"}}
+		}
+
+		{{.HTTP.SetHeader "\"Content-Disposition\"" "blob.Disposition(info.ContentType)"}}
+		{{.HTTP.SetHeader "\"X-Content-Type-Options\"" "\"nosniff\""}}
+{{- if .HTTP.Is "fiber"}}
+		c.Set(fiber.HeaderContentType, info.ContentType)
+		// fasthttp closes the reader once the body is sent
+		return c.SendStream(reader, int(info.Size))
+{{- else}}
+		defer reader.Close()
+{{- if .HTTP.Is "gin"}}
+		c.DataFromReader(http.StatusOK, info.Size, info.ContentType, reader, nil)
+{{- else if .HTTP.Is "echo"}}
+		if info.Size >= 0 {
+			c.Response().Header().Set(echo.HeaderContentLength, strconv.FormatInt(info.Size, 10))
+		}
+		return c.Stream(http.StatusOK, info.ContentType, reader)
+{{- else}}
+		w.Header().Set("Content-Type", info.ContentType)
+		if info.Size >= 0 {
+			w.Header().Set("Content-Length", strconv.FormatInt(info.Size, 10))
+		}
+		w.WriteHeader(http.StatusOK)
+		io.Copy(w, reader)
+{{- end}}
+{{- end}}
+	}
+}
+
+// Remove handles DELETE /{{.Names.KebabPlural}}/:id/<field>, clearing the field and deleting its file
+func (h *{{.Names.PascalCase}}UploadHandler) Remove(field string) func({{.HTTP.HandlerParams}}){{.HTTP.HandlerResult}} {
+	return func({{.HTTP.HandlerParams}}){{.HTTP.HandlerResult}} {
+		id := {{.HTTP.Param "id"}}
+
+		{{.Names.CamelCase}}, err := h.service.Remove({{.HTTP.Context}}, id, field)
+		if err != nil {
+			{{.HTTP.Fail "h.status(err, http.StatusInternalServerError)" "err.Error()"}}
+		}
+
+		{{.HTTP.Respond "http.StatusOK" (printf "%s.To%sResponse()" .Names.CamelCase .Names.PascalCase)}}
+	}
+}
+
+// status returns the HTTP status of an upload error, fallback for unexpected errors
+func (h *{{.Names.PascalCase}}UploadHandler) status(err error, fallback int) int {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.Is(err, blob.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, blob.ErrTooLarge), errors.As(err, &maxBytesErr):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, blob.ErrUnsupportedType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, blob.ErrEmpty):
+		return http.StatusBadRequest
+	default:
+		return fallback
+	}
+}
+
+// Setup{{.Names.PascalCase}}UploadRoutes sets up the upload routes of the file and image fields of {{.DisplayName}}
+func Setup{{.Names.PascalCase}}UploadRoutes({{.HTTP.Router}}, handler *{{.Names.PascalCase}}UploadHandler) {
+{{- range .Uploads}}
+	{{$.HTTP.Root.Route "POST" (printf "/%s/:id/%s" $.Names.KebabPlural .Route) (printf "handler.Upload(%q)" .Name)}}
+	{{$.HTTP.Root.Route "GET" (printf "/%s/:id/%s" $.Names.KebabPlural .Route) (printf "handler.Download(%q)" .Name)}}
+	{{$.HTTP.Root.Route "DELETE" (printf "/%s/:id/%s" $.Names.KebabPlural .Route) (printf "handler.Remove(%q)" .Name)}}
+{{- end}}
+}
+`
@@ -0,0 +1,251 @@
+package templates
+
+// SchemaVersionModelTemplate generates the request and response of an older API version of a
+// resource, from the schema the version was generated from, mapped onto the shared model
+const SchemaVersionModelTemplate = `package models
+
+import (
+	"time"
+{{- range .Contract.RequiredImports}}
+	"{{.}}"
+{{- end}}
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"{{.Module}}/internal/geo"
+	"{{.Module}}/internal/money"
+)
+
+// {{.Names.PascalCase}}{{.Suffix}}Request is the request payload of {{.DisplayName}} in API {{.Version}}
+type {{.Names.PascalCase}}{{.Suffix}}Request struct {
+{{- range .Contract.Fields}}
+{{- if not .ReadOnly}}
+	{{.GoRequestField}}
+{{- end}}
+{{- end}}
+}
+
+// ToRequest maps the {{.Version}} request onto the current request, fields added since {{.Version}}
+// keep their zero value
+func (r *{{.Names.PascalCase}}{{.Suffix}}Request) ToRequest() *{{.Names.PascalCase}}Request {
+	return &{{.Names.PascalCase}}Request{
+{{- range .Contract.Fields}}
+{{- if index $.RequestFields .Name}}
+		{{.Names.PascalCase}}: r.{{.Names.PascalCase}},
+{{- end}}
+{{- end}}
+	}
+}
+
+// {{.Names.PascalCase}}{{.Suffix}}Response is the response payload of {{.DisplayName}} in API {{.Version}}
+type {{.Names.PascalCase}}{{.Suffix}}Response struct {
+	ID        primitive.ObjectID ` + "`" + `json:"id"` + "`" + `
+	CreatedAt time.Time          ` + "`" + `json:"created_at"` + "`" + `
+	UpdatedAt time.Time          ` + "`" + `json:"updated_at"` + "`" + `
+
+{{- range .Contract.Fields}}
+	{{.GoResponseField}}
+{{- end}}
+}
+
+// To{{.Names.PascalCase}}{{.Suffix}}Response converts the model to the {{.Version}} response
+func (m *{{.Names.PascalCase}}) To{{.Names.PascalCase}}{{.Suffix}}Response() *{{.Names.PascalCase}}{{.Suffix}}Response {
+	return &{{.Names.PascalCase}}{{.Suffix}}Response{
+		ID:        m.ID,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+{{- range .Contract.Fields}}
+{{- if index $.ResponseFields .Name}}
+		{{.Names.PascalCase}}: m.{{.Names.PascalCase}},
+{{- else}}
+		// {{.Names.PascalCase}} was removed or retyped since {{$.Version}} and stays empty
+{{- end}}
+{{- end}}
+	}
+}
+`
+
+// SchemaVersionHandlerTemplate generates the HTTP handler serving an older API version of a
+// resource through the current service
+const SchemaVersionHandlerTemplate = `package handlers
+
+import (
+	"net/http"
+
+{{range .HTTP.Imports}}	"{{.}}"
+{{end}}	"{{.Module}}/internal/apperrors"
+	"{{.Module}}/internal/models"
+	"{{.Module}}/internal/services"
+)
+
+// {{.Names.PascalCase}}{{.Suffix}}Handler serves the {{.Version}} contract of {{.DisplayName}}
+type {{.Names.PascalCase}}{{.Suffix}}Handler struct {
+	service services.{{.Names.PascalCase}}ServiceInterface
+}
+
+// New{{.Names.PascalCase}}{{.Suffix}}Handler creates a new {{.Names.PascalCase}} {{.Version}} handler
+func New{{.Names.PascalCase}}{{.Suffix}}Handler(service services.{{.Names.PascalCase}}ServiceInterface) *{{.Names.PascalCase}}{{.Suffix}}Handler {
+	return &{{.Names.PascalCase}}{{.Suffix}}Handler{service: service}
+}
+
+// Create handles POST /{{.Names.KebabPlural}}
+func (h *{{.Names.PascalCase}}{{.Suffix}}Handler) Create({{.HTTP.HandlerParams}}){{.HTTP.HandlerResult}} {
+	{{- template "versionHeaders" .}}
+	var req models.{{.Names.PascalCase}}{{.Suffix}}Request
+	if err := {{.HTTP.BindJSON "&req"}}; err != nil {
+		{{.HTTP.Problem "apperrors.FromBinding(err, &req)"}}
+	}
+
+	{{.Names.CamelCase}}, err := h.service.Create({{.HTTP.Context}}, req.ToRequest())
+	if err != nil {
+		{{.HTTP.Problem "err"}}
+	}
+
+	{{.HTTP.Respond "http.StatusCreated" (printf "%s.To%s%sResponse()" .Names.CamelCase .Names.PascalCase .Suffix)}}
+}
+
+// GetByID handles GET /{{.Names.KebabPlural}}/:id
+func (h *{{.Names.PascalCase}}{{.Suffix}}Handler) GetByID({{.HTTP.HandlerParams}}){{.HTTP.HandlerResult}} {
+	{{- template "versionHeaders" .}}
+	id := {{.HTTP.Param "id"}}
+	if id == "" {
+		{{.HTTP.Fail "http.StatusBadRequest" "\"Invalid ID\""}}
+	}
+
+	{{.Names.CamelCase}}, err := h.service.GetByID({{.HTTP.Context}}, id)
+	if err != nil {
+		{{.HTTP.Problem "err"}}
+	}
+
+	{{.HTTP.Respond "http.StatusOK" (printf "%s.To%s%sResponse()" .Names.CamelCase .Names.PascalCase .Suffix)}}
+}
+
+// GetAll handles GET /{{.Names.KebabPlural}}
+func (h *{{.Names.PascalCase}}{{.Suffix}}Handler) GetAll({{.HTTP.HandlerParams}}){{.HTTP.HandlerResult}} {
+	{{- template "versionHeaders" .}}
+	var filter models.{{.Names.PascalCase}}Filter
+	if err := {{.HTTP.BindQuery "&filter"}}; err != nil {
+		{{.HTTP.Problem "apperrors.FromBinding(err, &filter)"}}
+	}
+
+	{{.Names.CamelPlural}}, total, err := h.service.GetAll({{.HTTP.Context}}, &filter)
+	if err != nil {
+		{{.HTTP.Problem "err"}}
+	}
+
+	responses := make([]*models.{{.Names.PascalCase}}{{.Suffix}}Response, len({{.Names.CamelPlural}}))
+	for i, {{.Names.CamelCase}} := range {{.Names.CamelPlural}} {
+		responses[i] = {{.Names.CamelCase}}.To{{.Names.PascalCase}}{{.Suffix}}Response()
+	}
+
+	{{.HTTP.Reply "http.StatusOK"}}{{.HTTP.Map}}{
+		"data":      responses,
+		"total":     total,
+		"page":      filter.Page,
+		"page_size": filter.PageSize,
+	})
+}
+
+// Update handles PUT /{{.Names.KebabPlural}}/:id
+func (h *{{.Names.PascalCase}}{{.Suffix}}Handler) Update({{.HTTP.HandlerParams}}){{.HTTP.HandlerResult}} {
+	{{- template "versionHeaders" .}}
+	id := {{.HTTP.Param "id"}}
+	if id == "" {
+		{{.HTTP.Fail "http.StatusBadRequest" "\"Invalid ID\""}}
+	}
+
+	var req models.{{.Names.PascalCase}}{{.Suffix}}Request
+	if err := {{.HTTP.BindJSON "&req"}}; err != nil {
+		{{.HTTP.Problem "apperrors.FromBinding(err, &req)"}}
+	}
+
+	{{.Names.CamelCase}}, err := h.service.Update({{.HTTP.Context}}, id, req.ToRequest())
+	if err != nil {
+		{{.HTTP.Problem "err"}}
+	}
+
+	{{.HTTP.Respond "http.StatusOK" (printf "%s.To%s%sResponse()" .Names.CamelCase .Names.PascalCase .Suffix)}}
+}
+
+// Delete handles DELETE /{{.Names.KebabPlural}}/:id
+func (h *{{.Names.PascalCase}}{{.Suffix}}Handler) Delete({{.HTTP.HandlerParams}}){{.HTTP.HandlerResult}} {
+	{{- template "versionHeaders" .}}
+	id := {{.HTTP.Param "id"}}
+	if id == "" {
+		{{.HTTP.Fail "http.StatusBadRequest" "\"Invalid ID\""}}
+	}
+
+	if err := h.service.Delete({{.HTTP.Context}}, id); err != nil {
+		{{.HTTP.Problem "err"}}
+	}
+
+	{{.HTTP.Reply "http.StatusOK"}}{{.HTTP.Map}}{"message": "{{.DisplayName}} deleted successfully"})
+}
+
+// Setup{{.Names.PascalCase}}{{.Suffix}}Routes sets up the {{.Version}} routes of {{.DisplayName}}
+func Setup{{.Names.PascalCase}}{{.Suffix}}Routes({{.HTTP.Router}}, handler *{{.Names.PascalCase}}{{.Suffix}}Handler) {
+{{- with .HTTP.Group .Names.CamelPlural (printf "/%s" .Names.KebabPlural)}}
+{{- with .Open}}
+	{{.}}
+{{- end}}
+	{{.Route "POST" "" "handler.Create"}}
+	{{.Route "GET" "" "handler.GetAll"}}
+	{{.Route "GET" "/:id" "handler.GetByID"}}
+	{{.Route "PUT" "/:id" "handler.Update"}}
+	{{.Route "DELETE" "/:id" "handler.Delete"}}
+{{- with .Close}}
+	{{.}}
+{{- end}}
+{{- end}}
+}
+{{- define "versionHeaders"}}
+	for name, value := range apiVersionHeaders("{{.Version}}") {
+		{{.HTTP.SetHeader "name" "value"}}
+	}
+{{end}}
+`
+
+// APIVersionsTemplate generates the lifecycle of the API versions of a project and the
+// deprecation headers of older versions
+const APIVersionsTemplate = `package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// apiVersion is the lifecycle of an API version, recorded in .vibercode/manifest.vibe
+type apiVersion struct {
+	Deprecated time.Time // Zero while the version is supported
+	Sunset     time.Time // Zero without a planned removal
+	Successor  string
+}
+
+// apiVersions lists the API versions of the project
+var apiVersions = map[string]apiVersion{
+{{- range .Versions}}
+	"{{.Version}}": {
+{{- if .Deprecated}}Deprecated: time.Unix({{.Deprecated}}, 0), {{end}}
+{{- if .Sunset}}Sunset: time.Unix({{.Sunset}}, 0), {{end}}
+{{- if .Successor}}Successor: "{{.Successor}}"{{end}}},
+{{- end}}
+}
+
+// apiVersionHeaders returns the Deprecation (RFC 9745), Sunset (RFC 8594) and successor Link
+// headers of a deprecated version, nil for supported versions
+func apiVersionHeaders(version string) map[string]string {
+	lifecycle, ok := apiVersions[version]
+	if !ok || lifecycle.Deprecated.IsZero() {
+		return nil
+	}
+
+	headers := map[string]string{"Deprecation": fmt.Sprintf("@%d", lifecycle.Deprecated.Unix())}
+	if !lifecycle.Sunset.IsZero() {
+		headers["Sunset"] = lifecycle.Sunset.UTC().Format(http.TimeFormat)
+	}
+	if lifecycle.Successor != "" {
+		headers["Link"] = fmt.Sprintf("</api/%s>; rel=\"successor-version\"", lifecycle.Successor)
+	}
+	return headers
+}
+`
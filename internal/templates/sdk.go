@@ -0,0 +1,1567 @@
+package templates
+
+// SDKClientTemplate generates pkg/sdk/client.go, the Client of the Go SDK with its options, the
+// retries of failed requests and the pagination iterators
+const SDKClientTemplate = `// Package sdk is the typed Go client of the {{.Title}} API. Create a client with New and call
+// the endpoints of a resource through its field, failed requests return an *Error mirroring
+// the problem details of the API:
+//
+//	client := sdk.New("https://api.example.com", sdk.WithBearerToken(token))
+//	page, err := client.{{(index .Resources 0).Names.PascalPlural}}.List(ctx, sdk.New{{(index .Resources 0).Names.PascalCase}}Filter().PageSize(50))
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultBasePath is the path the versions of the API are served under
+const DefaultBasePath = "/api"
+
+// UserAgent identifies the SDK in requests
+const UserAgent = "{{.Name}}-go-sdk"
+
+// TokenSource returns the bearer token of a request. It is called before every attempt so
+// that expired tokens can be refreshed.
+type TokenSource func(ctx context.Context) (string, error)
+
+// RetryPolicy controls how failed requests are retried. Requests are retried on network
+// errors and on 429, 502, 503 and 504 responses with an exponential backoff, creations only
+// on 429 responses since the server may have processed them.
+type RetryPolicy struct {
+	MaxAttempts int           // Attempts of a request, 1 disables retries
+	MinBackoff  time.Duration // Longest wait before the first retry, doubled after every attempt
+	MaxBackoff  time.Duration // Longest wait between two attempts
+}
+
+// DefaultRetryPolicy retries a request twice
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, MinBackoff: 200 * time.Millisecond, MaxBackoff: 5 * time.Second}
+
+// Client calls the {{.Title}} API, it is safe for concurrent use
+type Client struct {
+	baseURL    string
+	basePath   string
+	httpClient *http.Client
+	headers    http.Header
+	token      TokenSource
+	retry      RetryPolicy
+{{range .Resources}}
+	{{.Names.PascalPlural}} *{{.Names.PascalCase}}Client
+{{- end}}
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sends the requests with httpClient instead of http.DefaultClient
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithBasePath serves the API below path instead of DefaultBasePath
+func WithBasePath(path string) Option {
+	return func(c *Client) {
+		c.basePath = "/" + strings.Trim(path, "/")
+	}
+}
+
+// WithBearerToken authenticates the requests with a static bearer token
+func WithBearerToken(token string) Option {
+	return WithTokenSource(func(context.Context) (string, error) {
+		return token, nil
+	})
+}
+
+// WithTokenSource authenticates the requests with the bearer tokens of source
+func WithTokenSource(source TokenSource) Option {
+	return func(c *Client) {
+		c.token = source
+	}
+}
+
+// WithHeader adds a header to every request, e.g. an API key
+func WithHeader(name, value string) Option {
+	return func(c *Client) {
+		c.headers.Add(name, value)
+	}
+}
+
+// WithRetryPolicy retries failed requests with policy instead of DefaultRetryPolicy
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// New creates a client of the API served at baseURL, e.g. https://api.example.com
+func New(baseURL string, options ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		basePath:   DefaultBasePath,
+		httpClient: http.DefaultClient,
+		headers:    make(http.Header),
+		retry:      DefaultRetryPolicy,
+	}
+	for _, option := range options {
+		option(c)
+	}
+{{- range .Resources}}
+	c.{{.Names.PascalPlural}} = &{{.Names.PascalCase}}Client{client: c}
+{{- end}}
+	return c
+}
+
+// do sends a request, retrying it according to the retry policy, and decodes the response
+// into out when it is not nil
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+	target := c.baseURL + c.basePath + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.send(ctx, method, target, payload)
+		if err != nil && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err == nil && resp.StatusCode < http.StatusBadRequest {
+			return decodeResponse(resp, out)
+		}
+
+		var retry bool
+		var wait time.Duration
+		if err != nil {
+			retry = method != http.MethodPost
+			err = fmt.Errorf("%s %s: %w", method, path, err)
+		} else {
+			switch resp.StatusCode {
+			case http.StatusTooManyRequests:
+				retry = true
+			case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+				retry = method != http.MethodPost
+			}
+			wait = retryAfter(resp)
+			err = newError(resp)
+		}
+		if !retry || attempt >= c.retry.MaxAttempts {
+			return err
+		}
+
+		if backoff := c.retry.backoff(attempt); wait == 0 || wait > c.retry.MaxBackoff {
+			wait = backoff
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// send sends one attempt of a request
+func (c *Client) send(ctx context.Context, method, target string, payload []byte) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range c.headers {
+		req.Header[name] = values
+	}
+	req.Header.Set("Accept", "application/json, application/problem+json")
+	req.Header.Set("User-Agent", UserAgent)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != nil {
+		token, err := c.token(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return c.httpClient.Do(req)
+}
+
+// backoff returns the wait before the retry following an attempt, a random duration up to
+// the exponential backoff so that clients failing together do not retry together
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	wait := p.MinBackoff << (attempt - 1)
+	if wait <= 0 || wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	if wait <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(wait) + 1))
+}
+
+// retryAfter returns the wait a 429 or 503 response asks for, zero when it asks for none
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// decodeResponse decodes the JSON body of a successful response into out
+func decodeResponse(resp *http.Response, out interface{}) error {
+	defer resp.Body.Close()
+	if out == nil {
+		_, err := io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// Order is the direction records are sorted in
+type Order string
+
+const (
+	Asc  Order = "asc"
+	Desc Order = "desc"
+)
+
+// DefaultPageSize is the size of the pages requested by iterators without one
+const DefaultPageSize = 50
+
+// Page is a page of listed records
+type Page[T any] struct {
+	Data     []*T  ` + "`" + `json:"data"` + "`" + `
+	Total    int64 ` + "`" + `json:"total"` + "`" + `
+	Page     int   ` + "`" + `json:"page"` + "`" + `
+	PageSize int   ` + "`" + `json:"page_size"` + "`" + `
+}
+
+// Iterator walks the records of every page of a listing, requesting the pages as it goes:
+//
+//	it := client.{{(index .Resources 0).Names.PascalPlural}}.Iterate(ctx, nil)
+//	for it.Next() {
+//		{{(index .Resources 0).Names.CamelCase}} := it.Item()
+//	}
+//	if err := it.Err(); err != nil {
+type Iterator[T any] struct {
+	fetch    func(page, pageSize int) (*Page[T], error)
+	page     int
+	pageSize int
+	items    []*T
+	item     *T
+	total    int64
+	done     bool
+	err      error
+}
+
+func newIterator[T any](values url.Values, fetch func(page, pageSize int) (*Page[T], error)) *Iterator[T] {
+	it := &Iterator[T]{fetch: fetch, page: 1, pageSize: DefaultPageSize}
+	if page, err := strconv.Atoi(values.Get("page")); err == nil && page > 0 {
+		it.page = page
+	}
+	if pageSize, err := strconv.Atoi(values.Get("page_size")); err == nil && pageSize > 0 {
+		it.pageSize = pageSize
+	}
+	return it
+}
+
+// Next advances to the next record, false once every record was read or a page failed
+func (it *Iterator[T]) Next() bool {
+	for len(it.items) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		page, err := it.fetch(it.page, it.pageSize)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.items, it.total = page.Data, page.Total
+		it.done = len(page.Data) < it.pageSize || int64(it.page*it.pageSize) >= page.Total
+		it.page++
+	}
+	it.item, it.items = it.items[0], it.items[1:]
+	return true
+}
+
+// Item returns the current record
+func (it *Iterator[T]) Item() *T {
+	return it.item
+}
+
+// Total returns the number of listed records reported by the last page
+func (it *Iterator[T]) Total() int64 {
+	return it.total
+}
+
+// Err returns the error of the page that stopped the iteration
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// filter holds the query parameters of a listing
+type filter struct {
+	values url.Values
+}
+
+func (f *filter) set(name, value string) {
+	if f.values == nil {
+		f.values = make(url.Values)
+	}
+	f.values.Set(name, value)
+}
+
+// query returns a copy of the query parameters
+func (f *filter) query() url.Values {
+	query := make(url.Values, len(f.values))
+	for name, values := range f.values {
+		query[name] = append([]string(nil), values...)
+	}
+	return query
+}
+{{- if .Money}}
+
+// Money is an amount of money, the amount is a decimal string to keep its precision
+type Money struct {
+	Amount   string ` + "`" + `json:"amount"` + "`" + `
+	Currency string ` + "`" + `json:"currency"` + "`" + ` // ISO 4217 code
+}
+{{- end}}
+{{- if .Geo}}
+
+// Coordinates is a point on Earth
+type Coordinates struct {
+	Latitude  float64 ` + "`" + `json:"latitude"` + "`" + `
+	Longitude float64 ` + "`" + `json:"longitude"` + "`" + `
+}
+{{- end}}
+`
+
+// SDKErrorsTemplate generates pkg/sdk/errors.go, the Error mirroring the problem details of the
+// API with sentinels of its codes
+const SDKErrorsTemplate = `package sdk
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Stable error codes of the API, part of its contract
+const (
+	CodeBadRequest   = "bad_request"
+	CodeValidation   = "validation_failed"
+	CodeUnauthorized = "unauthorized"
+	CodeForbidden    = "forbidden"
+	CodeNotFound     = "not_found"
+	CodeConflict     = "conflict"
+	CodeInternal     = "internal_error"
+)
+
+// Sentinels matching the errors of a kind with errors.Is
+var (
+	ErrBadRequest   = &Error{Status: http.StatusBadRequest, Code: CodeBadRequest}
+	ErrValidation   = &Error{Status: http.StatusBadRequest, Code: CodeValidation}
+	ErrUnauthorized = &Error{Status: http.StatusUnauthorized, Code: CodeUnauthorized}
+	ErrForbidden    = &Error{Status: http.StatusForbidden, Code: CodeForbidden}
+	ErrNotFound     = &Error{Status: http.StatusNotFound, Code: CodeNotFound}
+	ErrConflict     = &Error{Status: http.StatusConflict, Code: CodeConflict}
+	ErrInternal     = &Error{Status: http.StatusInternalServerError, Code: CodeInternal}
+)
+
+// Error is a failed request, decoded from the problem details (RFC 7807) of the response
+type Error struct {
+	Type     string       ` + "`" + `json:"type"` + "`" + `
+	Title    string       ` + "`" + `json:"title"` + "`" + `
+	Status   int          ` + "`" + `json:"status"` + "`" + `
+	Detail   string       ` + "`" + `json:"detail,omitempty"` + "`" + `
+	Instance string       ` + "`" + `json:"instance,omitempty"` + "`" + `
+	Code     string       ` + "`" + `json:"code"` + "`" + `
+	Errors   []FieldError ` + "`" + `json:"errors,omitempty"` + "`" + `
+}
+
+// FieldError describes why the value of a request field was rejected
+type FieldError struct {
+	Field   string ` + "`" + `json:"field"` + "`" + ` // JSON path of the field
+	Code    string ` + "`" + `json:"code"` + "`" + `  // Failed rule, e.g. required or max
+	Message string ` + "`" + `json:"message"` + "`" + `
+}
+
+// Error returns the detail of the error
+func (e *Error) Error() string {
+	message := e.Title
+	if e.Detail != "" {
+		message = e.Detail
+	}
+	if message == "" {
+		message = strings.ToLower(http.StatusText(e.Status))
+	}
+	return e.Code + ": " + message
+}
+
+// Is reports whether target is an *Error with the same code, errors.Is(err, sdk.ErrNotFound)
+// matches every not_found error
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Field returns the error of the field with the given JSON path, nil when it was accepted
+func (e *Error) Field(name string) *FieldError {
+	for i := range e.Errors {
+		if e.Errors[i].Field == name {
+			return &e.Errors[i]
+		}
+	}
+	return nil
+}
+
+// newError reads the error of a failed response. Responses without problem details, e.g.
+// from a proxy, get the code of their status.
+func newError(resp *http.Response) error {
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+
+	apiErr := &Error{}
+	if err := json.Unmarshal(body, apiErr); err != nil || apiErr.Code == "" {
+		apiErr = &Error{
+			Title:  http.StatusText(resp.StatusCode),
+			Detail: strings.TrimSpace(string(body)),
+			Code:   statusCode(resp.StatusCode),
+		}
+	}
+	apiErr.Status = resp.StatusCode
+	return apiErr
+}
+
+// statusCode returns the error code of a status
+func statusCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusUnprocessableEntity:
+		return CodeValidation
+	}
+	return CodeInternal
+}
+`
+
+// SDKResourceTemplate generates the types, filter builder and client of a resource in the Go SDK
+const SDKResourceTemplate = `package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// {{.Names.PascalCase}} is a {{.Names.Singular}} as returned by the API
+type {{.Names.PascalCase}} struct {
+	ID        string    ` + "`" + `json:"id"` + "`" + `
+	CreatedAt time.Time ` + "`" + `json:"created_at"` + "`" + `
+	UpdatedAt time.Time ` + "`" + `json:"updated_at"` + "`" + `
+{{- range .Fields}}
+	{{.Name}} {{.GoType}} ` + "`" + `json:"{{.JSON}}{{if .OmitEmpty}},omitempty{{end}}"` + "`" + `
+{{- end}}
+}
+
+// {{.Names.PascalCase}}Request is the payload creating and updating {{.Names.Plural}}
+type {{.Names.PascalCase}}Request struct {
+{{- range .Request}}
+	{{.Name}} {{.GoType}} ` + "`" + `json:"{{.JSON}}{{if .OmitEmpty}},omitempty{{end}}"` + "`" + `
+{{- end}}
+}
+{{- range .Enums}}
+
+// Values of {{$.Names.PascalCase}}.{{.Field}}
+const (
+{{- range .Values}}
+	{{.Name}} = {{printf "%q" .Value}}
+{{- end}}
+)
+{{- end}}
+
+// {{.Names.PascalCase}}Filter selects the {{.Names.Plural}} listed, the zero value lists the first page of
+// every {{.Names.Singular}}
+type {{.Names.PascalCase}}Filter struct {
+	filter
+}
+
+// New{{.Names.PascalCase}}Filter creates a filter of {{.Names.Plural}}
+func New{{.Names.PascalCase}}Filter() *{{.Names.PascalCase}}Filter {
+	return &{{.Names.PascalCase}}Filter{}
+}
+
+// Page selects the page of the listing, starting at 1
+func (f *{{.Names.PascalCase}}Filter) Page(page int) *{{.Names.PascalCase}}Filter {
+	f.set("page", strconv.Itoa(page))
+	return f
+}
+
+// PageSize sets the number of {{.Names.Plural}} of a page
+func (f *{{.Names.PascalCase}}Filter) PageSize(size int) *{{.Names.PascalCase}}Filter {
+	f.set("page_size", strconv.Itoa(size))
+	return f
+}
+
+// Sort sorts the {{.Names.Plural}} by the field with the given JSON name
+func (f *{{.Names.PascalCase}}Filter) Sort(field string, order Order) *{{.Names.PascalCase}}Filter {
+	f.set("sort", field)
+	f.set("order", string(order))
+	return f
+}
+
+// Search lists the {{.Names.Plural}} matching a full text search
+func (f *{{.Names.PascalCase}}Filter) Search(text string) *{{.Names.PascalCase}}Filter {
+	f.set("search", text)
+	return f
+}
+{{- if .Includes}}
+
+// Include selects the related records embedded in the listed {{.Names.Plural}}, e.g. {{printf "%q" (index .Includes 0)}},
+// instead of the relations the API embeds by default
+func (f *{{.Names.PascalCase}}Filter) Include(relations ...string) *{{.Names.PascalCase}}Filter {
+	f.set("include", strings.Join(relations, ","))
+	return f
+}
+{{- end}}
+{{- range .Filters}}
+
+// {{.Method}} lists the {{$.Names.Plural}} {{.Doc}}
+func (f *{{$.Names.PascalCase}}Filter) {{.Method}}(value {{.GoType}}) *{{$.Names.PascalCase}}Filter {
+	f.set({{printf "%q" .JSON}}, {{.Format}})
+	return f
+}
+{{- end}}
+
+// query returns the query parameters of the filter, none for a nil filter
+func (f *{{.Names.PascalCase}}Filter) query() url.Values {
+	if f == nil {
+		return make(url.Values)
+	}
+	return f.filter.query()
+}
+
+// {{.Names.PascalCase}}Client calls the {{.Names.Singular}} endpoints of the API
+type {{.Names.PascalCase}}Client struct {
+	client *Client
+}
+
+// Create creates a {{.Names.Singular}}
+func (c *{{.Names.PascalCase}}Client) Create(ctx context.Context, req *{{.Names.PascalCase}}Request) (*{{.Names.PascalCase}}, error) {
+	var {{.Names.CamelCase}} {{.Names.PascalCase}}
+	if err := c.client.do(ctx, http.MethodPost, {{printf "%q" .Path}}, nil, req, &{{.Names.CamelCase}}); err != nil {
+		return nil, err
+	}
+	return &{{.Names.CamelCase}}, nil
+}
+
+// Get returns the {{.Names.Singular}} with the given ID
+{{- if .Includes}}, embedding the related records of include instead of
+// the relations the API embeds by default
+func (c *{{.Names.PascalCase}}Client) Get(ctx context.Context, id string, include ...string) (*{{.Names.PascalCase}}, error) {
+	var query url.Values
+	if len(include) > 0 {
+		query = url.Values{"include": {strings.Join(include, ",")}}
+	}
+{{- else}}
+func (c *{{.Names.PascalCase}}Client) Get(ctx context.Context, id string) (*{{.Names.PascalCase}}, error) {
+	var query url.Values
+{{- end}}
+	var {{.Names.CamelCase}} {{.Names.PascalCase}}
+	if err := c.client.do(ctx, http.MethodGet, {{printf "%q" .Path}}+"/"+url.PathEscape(id), query, nil, &{{.Names.CamelCase}}); err != nil {
+		return nil, err
+	}
+	return &{{.Names.CamelCase}}, nil
+}
+
+// List returns a page of the {{.Names.Plural}} selected by filter, nil selects the first page
+func (c *{{.Names.PascalCase}}Client) List(ctx context.Context, filter *{{.Names.PascalCase}}Filter) (*Page[{{.Names.PascalCase}}], error) {
+	return c.list(ctx, filter.query())
+}
+
+// Iterate walks every {{.Names.Singular}} selected by filter, requesting the pages as it goes
+func (c *{{.Names.PascalCase}}Client) Iterate(ctx context.Context, filter *{{.Names.PascalCase}}Filter) *Iterator[{{.Names.PascalCase}}] {
+	query := filter.query()
+	return newIterator(query, func(page, pageSize int) (*Page[{{.Names.PascalCase}}], error) {
+		query.Set("page", strconv.Itoa(page))
+		query.Set("page_size", strconv.Itoa(pageSize))
+		return c.list(ctx, query)
+	})
+}
+
+func (c *{{.Names.PascalCase}}Client) list(ctx context.Context, query url.Values) (*Page[{{.Names.PascalCase}}], error) {
+	var page Page[{{.Names.PascalCase}}]
+	if err := c.client.do(ctx, http.MethodGet, {{printf "%q" .Path}}, query, nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// Update replaces the fields of the {{.Names.Singular}} with the given ID
+func (c *{{.Names.PascalCase}}Client) Update(ctx context.Context, id string, req *{{.Names.PascalCase}}Request) (*{{.Names.PascalCase}}, error) {
+	var {{.Names.CamelCase}} {{.Names.PascalCase}}
+	if err := c.client.do(ctx, http.MethodPut, {{printf "%q" .Path}}+"/"+url.PathEscape(id), nil, req, &{{.Names.CamelCase}}); err != nil {
+		return nil, err
+	}
+	return &{{.Names.CamelCase}}, nil
+}
+
+// Delete deletes the {{.Names.Singular}} with the given ID
+func (c *{{.Names.PascalCase}}Client) Delete(ctx context.Context, id string) error {
+	return c.client.do(ctx, http.MethodDelete, {{printf "%q" .Path}}+"/"+url.PathEscape(id), nil, nil, nil)
+}
+`
+
+// SDKTestTemplate generates pkg/sdk/sdk_test.go, testing the Go SDK against the generated
+// handlers served by httptest, backed by in-memory services
+const SDKTestTemplate = `package sdk_test
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+{{if .HTTP.Is "fiber"}}
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+{{- else if .HTTP.Is "echo"}}
+	"github.com/labstack/echo/v4"
+{{- else if .HTTP.Is "chi"}}
+	"github.com/go-chi/chi/v5"
+{{- else if .HTTP.Is "gin"}}
+	"github.com/gin-gonic/gin"
+{{- end}}
+
+	"{{.Module}}/internal/apperrors"
+	"{{.Module}}/internal/handlers"
+	"{{.Module}}/internal/models"
+	"{{.Module}}/pkg/sdk"
+)
+
+// {{.Test.Resource.Names.CamelCase}}Payload is a valid {{.Test.Resource.Names.PascalCase}}Request
+const {{.Test.Resource.Names.CamelCase}}Payload = {{printf "%q" .Test.Payload}}
+
+// memoryService implements the generated service of a model M, with its request R and
+// filter F, in memory. Records are converted from requests through their JSON encoding.
+type memoryService[M, R, F any] struct {
+	mu      sync.Mutex
+	records []map[string]interface{}
+}
+
+func (s *memoryService[M, R, F]) Create(ctx context.Context, req *R) (*M, error) {
+	record, err := requestRecord(req)
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, 12)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	record["id"], record["created_at"], record["updated_at"] = hex.EncodeToString(id), now, now
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, record)
+	return decodeRecord[M](record)
+}
+
+func (s *memoryService[M, R, F]) GetByID(ctx context.Context, id string) (*M, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.find(id)
+	if i < 0 {
+		return nil, apperrors.NotFound("record %s not found", id)
+	}
+	return decodeRecord[M](s.records[i])
+}
+
+func (s *memoryService[M, R, F]) GetAll(ctx context.Context, filter *F) ([]*M, int64, error) {
+	query, err := toRecord(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	page, size := intValue(query["page"], 1), intValue(query["page_size"], 10)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var matches []map[string]interface{}
+	for _, record := range s.records {
+		if matchesQuery(record, query) {
+			matches = append(matches, record)
+		}
+	}
+	items := []*M{}
+	for i := (page - 1) * size; i >= 0 && i < len(matches) && i < page*size; i++ {
+		item, err := decodeRecord[M](matches[i])
+		if err != nil {
+			return nil, 0, err
+		}
+		items = append(items, item)
+	}
+	return items, int64(len(matches)), nil
+}
+
+func (s *memoryService[M, R, F]) Update(ctx context.Context, id string, req *R) (*M, error) {
+	values, err := requestRecord(req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.find(id)
+	if i < 0 {
+		return nil, apperrors.NotFound("record %s not found", id)
+	}
+	for name, value := range values {
+		s.records[i][name] = value
+	}
+	s.records[i]["updated_at"] = time.Now().UTC()
+	return decodeRecord[M](s.records[i])
+}
+
+func (s *memoryService[M, R, F]) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.find(id)
+	if i < 0 {
+		return apperrors.NotFound("record %s not found", id)
+	}
+	s.records = append(s.records[:i], s.records[i+1:]...)
+	return nil
+}
+
+func (s *memoryService[M, R, F]) Include(ctx context.Context, items []*M, include models.IncludeTree) error {
+	return nil
+}
+
+func (s *memoryService[M, R, F]) find(id string) int {
+	for i, record := range s.records {
+		if record["id"] == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// requestRecord validates a request like the generated services and converts it to a record
+func requestRecord(req interface{}) (map[string]interface{}, error) {
+	if validator, ok := req.(interface{ Validate() error }); ok {
+		if err := validator.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return toRecord(req)
+}
+
+func toRecord(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var record map[string]interface{}
+	return record, json.Unmarshal(data, &record)
+}
+
+func decodeRecord[M any](record map[string]interface{}) (*M, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	item := new(M)
+	return item, json.Unmarshal(data, item)
+}
+
+// matchesQuery reports whether a record has the field values of a filter
+func matchesQuery(record, query map[string]interface{}) bool {
+	for name, value := range query {
+		switch name {
+		case "page", "page_size", "sort", "order", "search":
+			continue
+		}
+		if value != nil && fmt.Sprint(record[name]) != fmt.Sprint(value) {
+			return false
+		}
+	}
+	return true
+}
+
+func intValue(value interface{}, fallback int) int {
+	if n, ok := value.(float64); ok && n > 0 {
+		return int(n)
+	}
+	return fallback
+}
+
+// newHandler serves the generated handlers of every resource on a {{.HTTP.Framework.GetDisplayName}} router,
+// backed by in-memory services
+func newHandler() http.Handler {
+{{- if .HTTP.Is "fiber"}}
+	app := fiber.New()
+{{- range .Versions}}
+	{{.Version}} := app.Group("/api/{{.Version}}")
+{{- $version := .Version}}
+{{- range .Resources}}
+	handlers.Setup{{.Names.PascalCase}}Routes({{$version}}, handlers.New{{.Names.PascalCase}}Handler(&memoryService[models.{{.Names.PascalCase}}, models.{{.Names.PascalCase}}Request, models.{{.Names.PascalCase}}Filter]{}))
+{{- end}}
+{{- end}}
+	return adaptor.FiberApp(app)
+{{- else if .HTTP.Is "echo"}}
+	router := echo.New()
+{{- range .Versions}}
+	{{.Version}} := router.Group("/api/{{.Version}}")
+{{- $version := .Version}}
+{{- range .Resources}}
+	handlers.Setup{{.Names.PascalCase}}Routes({{$version}}, handlers.New{{.Names.PascalCase}}Handler(&memoryService[models.{{.Names.PascalCase}}, models.{{.Names.PascalCase}}Request, models.{{.Names.PascalCase}}Filter]{}))
+{{- end}}
+{{- end}}
+	return router
+{{- else if .HTTP.Is "chi"}}
+	router := chi.NewRouter()
+{{- range .Versions}}
+	router.Route("/api/{{.Version}}", func({{.Version}} chi.Router) {
+{{- $version := .Version}}
+{{- range .Resources}}
+		handlers.Setup{{.Names.PascalCase}}Routes({{$version}}, handlers.New{{.Names.PascalCase}}Handler(&memoryService[models.{{.Names.PascalCase}}, models.{{.Names.PascalCase}}Request, models.{{.Names.PascalCase}}Filter]{}))
+{{- end}}
+	})
+{{- end}}
+	return router
+{{- else if .HTTP.Is "stdlib"}}
+	router := http.NewServeMux()
+{{- range .Versions}}
+	{{.Version}} := http.NewServeMux()
+{{- $version := .Version}}
+{{- range .Resources}}
+	handlers.Setup{{.Names.PascalCase}}Routes({{$version}}, handlers.New{{.Names.PascalCase}}Handler(&memoryService[models.{{.Names.PascalCase}}, models.{{.Names.PascalCase}}Request, models.{{.Names.PascalCase}}Filter]{}))
+{{- end}}
+	router.Handle("/api/{{.Version}}/", http.StripPrefix("/api/{{.Version}}", {{.Version}}))
+{{- end}}
+	return router
+{{- else}}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+{{- range .Versions}}
+	{{.Version}} := router.Group("/api/{{.Version}}")
+{{- $version := .Version}}
+{{- range .Resources}}
+	handlers.Setup{{.Names.PascalCase}}Routes({{$version}}, handlers.New{{.Names.PascalCase}}Handler(&memoryService[models.{{.Names.PascalCase}}, models.{{.Names.PascalCase}}Request, models.{{.Names.PascalCase}}Filter]{}))
+{{- end}}
+{{- end}}
+	return router
+{{- end}}
+}
+
+// newClient returns a client of a test server running handler
+func newClient(t *testing.T, handler http.Handler, options ...sdk.Option) *sdk.Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return sdk.New(server.URL, options...)
+}
+
+func new{{.Test.Resource.Names.PascalCase}}Request(t *testing.T) *sdk.{{.Test.Resource.Names.PascalCase}}Request {
+	t.Helper()
+
+	var req sdk.{{.Test.Resource.Names.PascalCase}}Request
+	if err := json.Unmarshal([]byte({{.Test.Resource.Names.CamelCase}}Payload), &req); err != nil {
+		t.Fatalf("invalid payload: %v", err)
+	}
+	return &req
+}
+
+func Test{{.Test.Resource.Names.PascalCase}}CRUD(t *testing.T) {
+	ctx := context.Background()
+	client := newClient(t, newHandler())
+
+	created, err := client.{{.Test.Resource.Names.PascalPlural}}.Create(ctx, new{{.Test.Resource.Names.PascalCase}}Request(t))
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if created.ID == "" || created.CreatedAt.IsZero() {
+		t.Fatalf("create: incomplete {{.Test.Resource.Names.Singular}} %+v", created)
+	}
+
+	got, err := client.{{.Test.Resource.Names.PascalPlural}}.Get(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.ID != created.ID {
+		t.Errorf("get: ID = %s, want %s", got.ID, created.ID)
+	}
+{{- with .Test.Field}}
+	if got.{{.Name}} != {{.Value}} {
+		t.Errorf("get: {{.Name}} = %v, want %v", got.{{.Name}}, {{.Value}})
+	}
+{{- end}}
+
+	if _, err := client.{{.Test.Resource.Names.PascalPlural}}.Update(ctx, created.ID, new{{.Test.Resource.Names.PascalCase}}Request(t)); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := client.{{.Test.Resource.Names.PascalPlural}}.Delete(ctx, created.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	_, err = client.{{.Test.Resource.Names.PascalPlural}}.Get(ctx, created.ID)
+	if !errors.Is(err, sdk.ErrNotFound) {
+		t.Fatalf("get deleted: err = %v, want not_found", err)
+	}
+	var apiErr *sdk.Error
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusNotFound || apiErr.Instance == "" {
+		t.Errorf("get deleted: problem = %+v", apiErr)
+	}
+}
+
+func Test{{.Test.Resource.Names.PascalCase}}ListAndIterate(t *testing.T) {
+	ctx := context.Background()
+	client := newClient(t, newHandler())
+	for i := 0; i < 5; i++ {
+		if _, err := client.{{.Test.Resource.Names.PascalPlural}}.Create(ctx, new{{.Test.Resource.Names.PascalCase}}Request(t)); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	page, err := client.{{.Test.Resource.Names.PascalPlural}}.List(ctx, sdk.New{{.Test.Resource.Names.PascalCase}}Filter().Page(2).PageSize(2))
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if page.Total != 5 || len(page.Data) != 2 || page.Page != 2 {
+		t.Errorf("list: page %d of %d {{.Test.Resource.Names.Plural}} with %d, want page 2 of 5 with 2", page.Page, page.Total, len(page.Data))
+	}
+
+	it := client.{{.Test.Resource.Names.PascalPlural}}.Iterate(ctx, sdk.New{{.Test.Resource.Names.PascalCase}}Filter().PageSize(2))
+	seen := make(map[string]bool)
+	for it.Next() {
+		seen[it.Item().ID] = true
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("iterate: %v", err)
+	}
+	if len(seen) != 5 {
+		t.Errorf("iterate: %d {{.Test.Resource.Names.Plural}}, want 5", len(seen))
+	}
+{{- with .Test.Filter}}
+
+	page, err = client.{{$.Test.Resource.Names.PascalPlural}}.List(ctx, sdk.New{{$.Test.Resource.Names.PascalCase}}Filter().{{.Name}}({{.Value}}))
+	if err != nil || page.Total != 5 {
+		t.Errorf("filter: %v, %+v", err, page)
+	}
+	page, err = client.{{$.Test.Resource.Names.PascalPlural}}.List(ctx, sdk.New{{$.Test.Resource.Names.PascalCase}}Filter().{{.Name}}({{.Other}}))
+	if err != nil || page.Total != 0 {
+		t.Errorf("filter: %v, %+v", err, page)
+	}
+{{- end}}
+}
+{{- with .Test.Required}}
+
+func Test{{$.Test.Resource.Names.PascalCase}}ValidationError(t *testing.T) {
+	client := newClient(t, newHandler())
+
+	req := new{{$.Test.Resource.Names.PascalCase}}Request(t)
+	req.{{.Name}} = ""
+	_, err := client.{{$.Test.Resource.Names.PascalPlural}}.Create(context.Background(), req)
+	if !errors.Is(err, sdk.ErrValidation) {
+		t.Fatalf("err = %v, want validation_failed", err)
+	}
+	var apiErr *sdk.Error
+	if !errors.As(err, &apiErr) || apiErr.Field({{printf "%q" .JSON}}) == nil {
+		t.Errorf("problem does not name {{.JSON}}: %+v", apiErr)
+	}
+}
+{{- end}}
+
+func TestRetriesAndAuthentication(t *testing.T) {
+	ctx := context.Background()
+	handler := newHandler()
+	var attempts int32
+	var authorization atomic.Value
+	flaky := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization.Store(r.Header.Get("Authorization"))
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+	client := newClient(t, flaky, sdk.WithBearerToken("t0ken"), sdk.WithRetryPolicy(sdk.RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  10 * time.Millisecond,
+	}))
+
+	if _, err := client.{{.Test.Resource.Names.PascalPlural}}.List(ctx, nil); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if n := atomic.LoadInt32(&attempts); n != 2 {
+		t.Errorf("list: %d attempts, want 2", n)
+	}
+	if got := authorization.Load(); got != "Bearer t0ken" {
+		t.Errorf("Authorization = %v", got)
+	}
+
+	// Creations are not retried, the server may have processed them
+	atomic.StoreInt32(&attempts, 0)
+	_, err := client.{{.Test.Resource.Names.PascalPlural}}.Create(ctx, new{{.Test.Resource.Names.PascalCase}}Request(t))
+	var apiErr *sdk.Error
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusServiceUnavailable {
+		t.Errorf("create: err = %v, want a 503 error", err)
+	}
+	if n := atomic.LoadInt32(&attempts); n != 1 {
+		t.Errorf("create: %d attempts, want 1", n)
+	}
+}
+`
+
+// SDKPackageJSONTemplate generates sdk/typescript/package.json
+const SDKPackageJSONTemplate = `{
+  "name": "{{.Name}}-sdk",
+  "version": "1.0.0",
+  "description": "Typed client of the {{.Title}} API",
+  "main": "dist/index.js",
+  "types": "dist/index.d.ts",
+  "files": [
+    "dist"
+  ],
+  "scripts": {
+    "build": "tsc",
+    "prepare": "tsc"
+  },
+  "engines": {
+    "node": ">=18"
+  },
+  "devDependencies": {
+    "typescript": "^5.4.0"
+  }
+}
+`
+
+// SDKTSConfigTemplate generates sdk/typescript/tsconfig.json
+const SDKTSConfigTemplate = `{
+  "compilerOptions": {
+    "target": "ES2020",
+    "module": "commonjs",
+    "lib": ["ES2020", "DOM"],
+    "declaration": true,
+    "outDir": "dist",
+    "rootDir": "src",
+    "strict": true,
+    "esModuleInterop": true,
+    "skipLibCheck": true
+  },
+  "include": ["src"]
+}
+`
+
+// SDKTSClientTemplate generates sdk/typescript/src/client.ts, the fetch client of the TypeScript SDK
+// with its retries, token injection and pagination
+const SDKTSClientTemplate = `import { errorFromResponse } from './errors';
+
+/** Path the versions of the API are served under */
+export const DEFAULT_BASE_PATH = '/api';
+
+/** Size of the pages requested by iterators without one */
+export const DEFAULT_PAGE_SIZE = 50;
+
+/** Returns the bearer token of a request, called before every attempt so that expired tokens can be refreshed */
+export type TokenSource = () => string | Promise<string>;
+
+/**
+ * Controls how failed requests are retried. Requests are retried on network errors and on 429, 502, 503 and 504
+ * responses with an exponential backoff, creations only on 429 responses since the server may have processed them.
+ */
+export interface RetryPolicy {
+  /** Attempts of a request, 1 disables retries */
+  maxAttempts: number;
+  /** Longest wait in milliseconds before the first retry, doubled after every attempt */
+  minBackoff: number;
+  /** Longest wait in milliseconds between two attempts */
+  maxBackoff: number;
+}
+
+/** Retries a request twice */
+export const DEFAULT_RETRY_POLICY: RetryPolicy = { maxAttempts: 3, minBackoff: 200, maxBackoff: 5000 };
+
+export interface ClientOptions {
+  /** URL the API is served at, e.g. https://api.example.com */
+  baseUrl: string;
+  /** Path the versions of the API are served under, DEFAULT_BASE_PATH by default */
+  basePath?: string;
+  /** Bearer token of the requests, or a function returning it */
+  token?: string | TokenSource;
+  /** Headers added to every request, e.g. an API key */
+  headers?: Record<string, string>;
+  retry?: Partial<RetryPolicy>;
+  /** fetch implementation, the global fetch by default */
+  fetch?: typeof fetch;
+}
+
+export interface RequestOptions {
+  query?: URLSearchParams;
+  body?: unknown;
+  signal?: AbortSignal;
+}
+
+/** A page of listed records */
+export interface Page<T> {
+  data: T[];
+  total: number;
+  page: number;
+  page_size: number;
+}
+
+export type Order = 'asc' | 'desc';
+{{- if .Money}}
+
+/** An amount of money, the amount is a decimal string to keep its precision */
+export interface Money {
+  amount: string;
+  /** ISO 4217 code */
+  currency: string;
+}
+{{- end}}
+{{- if .Geo}}
+
+/** A point on Earth */
+export interface Coordinates {
+  latitude: number;
+  longitude: number;
+}
+{{- end}}
+
+const RETRYABLE_STATUSES = [502, 503, 504];
+
+/** Sends the requests of the resources, retrying them according to the retry policy */
+export class HttpClient {
+  private readonly baseUrl: string;
+  private readonly retry: RetryPolicy;
+  private readonly fetch: typeof fetch;
+
+  constructor(private readonly options: ClientOptions) {
+    const basePath = (options.basePath ?? DEFAULT_BASE_PATH).replace(/^\/+|\/+$/g, '');
+    this.baseUrl = options.baseUrl.replace(/\/+$/, '') + (basePath ? '/' + basePath : '');
+    this.retry = { ...DEFAULT_RETRY_POLICY, ...options.retry };
+    this.fetch = options.fetch ?? globalThis.fetch.bind(globalThis);
+  }
+
+  /** Sends a request and returns its decoded JSON response, failed requests throw an ApiError */
+  async request<T>(method: string, path: string, options: RequestOptions = {}): Promise<T> {
+    const query = options.query?.toString();
+    const url = this.baseUrl + path + (query ? '?' + query : '');
+    const body = options.body === undefined ? undefined : JSON.stringify(options.body);
+
+    for (let attempt = 1; ; attempt++) {
+      let response: Response | undefined;
+      let error: unknown;
+      try {
+        const headers = await this.headers(body !== undefined);
+        response = await this.fetch(url, { method, headers, body, signal: options.signal });
+      } catch (err) {
+        if (options.signal?.aborted) {
+          throw err;
+        }
+        error = err;
+      }
+      if (response?.ok) {
+        return (await response.json()) as T;
+      }
+
+      let retry = method !== 'POST';
+      let wait = 0;
+      if (response) {
+        retry = response.status === 429 || (retry && RETRYABLE_STATUSES.includes(response.status));
+        wait = retryAfter(response);
+        error = await errorFromResponse(response);
+      }
+      if (!retry || attempt >= this.retry.maxAttempts) {
+        throw error;
+      }
+      if (wait === 0 || wait > this.retry.maxBackoff) {
+        wait = backoff(this.retry, attempt);
+      }
+      await sleep(wait, options.signal);
+    }
+  }
+
+  private async headers(json: boolean): Promise<Record<string, string>> {
+    const headers: Record<string, string> = {
+      ...this.options.headers,
+      Accept: 'application/json, application/problem+json',
+    };
+    if (json) {
+      headers['Content-Type'] = 'application/json';
+    }
+    const { token } = this.options;
+    if (token !== undefined) {
+      headers.Authorization = 'Bearer ' + (typeof token === 'string' ? token : await token());
+    }
+    return headers;
+  }
+}
+
+/** Query parameters of a listing, extended by the filters of the resources */
+export class Filter {
+  protected readonly params = new URLSearchParams();
+
+  /** Selects the page of the listing, starting at 1 */
+  page(page: number): this {
+    this.params.set('page', String(page));
+    return this;
+  }
+
+  /** Sets the number of records of a page */
+  pageSize(size: number): this {
+    this.params.set('page_size', String(size));
+    return this;
+  }
+
+  /** Sorts the records by the field with the given JSON name */
+  sort(field: string, order: Order = 'asc'): this {
+    this.params.set('sort', field);
+    this.params.set('order', order);
+    return this;
+  }
+
+  /** Lists the records matching a full text search */
+  search(text: string): this {
+    this.params.set('search', text);
+    return this;
+  }
+
+  /** Returns a copy of the query parameters */
+  toQuery(): URLSearchParams {
+    return new URLSearchParams(this.params);
+  }
+}
+
+/** Walks the records of every page of a listing, requesting the pages as it goes */
+export async function* paginate<T>(
+  query: URLSearchParams,
+  list: (query: URLSearchParams) => Promise<Page<T>>,
+): AsyncGenerator<T, void, undefined> {
+  let page = Number(query.get('page')) || 1;
+  const pageSize = Number(query.get('page_size')) || DEFAULT_PAGE_SIZE;
+  for (;;) {
+    query.set('page', String(page));
+    query.set('page_size', String(pageSize));
+    const result = await list(query);
+    const data = result.data ?? [];
+    yield* data;
+    if (data.length < pageSize || page * pageSize >= result.total) {
+      return;
+    }
+    page++;
+  }
+}
+
+/** Returns a random wait up to the exponential backoff so that clients failing together do not retry together */
+function backoff(policy: RetryPolicy, attempt: number): number {
+  return Math.random() * Math.min(policy.minBackoff * 2 ** (attempt - 1), policy.maxBackoff);
+}
+
+/** Returns the wait in milliseconds a 429 or 503 response asks for, 0 when it asks for none */
+function retryAfter(response: Response): number {
+  const seconds = Number(response.headers.get('Retry-After'));
+  return Number.isInteger(seconds) && seconds > 0 ? seconds * 1000 : 0;
+}
+
+function sleep(ms: number, signal?: AbortSignal): Promise<void> {
+  return new Promise((resolve, reject) => {
+    if (signal?.aborted) {
+      reject(signal.reason);
+      return;
+    }
+    const abort = () => {
+      clearTimeout(timer);
+      reject(signal?.reason);
+    };
+    const timer = setTimeout(() => {
+      signal?.removeEventListener('abort', abort);
+      resolve();
+    }, ms);
+    signal?.addEventListener('abort', abort, { once: true });
+  });
+}
+`
+
+// SDKTSErrorsTemplate generates sdk/typescript/src/errors.ts, the ApiError mirroring the problem
+// details of the API
+const SDKTSErrorsTemplate = `/** Stable error codes of the API, part of its contract */
+export const ErrorCode = {
+  BadRequest: 'bad_request',
+  Validation: 'validation_failed',
+  Unauthorized: 'unauthorized',
+  Forbidden: 'forbidden',
+  NotFound: 'not_found',
+  Conflict: 'conflict',
+  Internal: 'internal_error',
+} as const;
+
+export type ErrorCode = (typeof ErrorCode)[keyof typeof ErrorCode];
+
+/** Why the value of a request field was rejected */
+export interface FieldError {
+  /** JSON path of the field */
+  field: string;
+  /** Failed rule, e.g. required or max */
+  code: string;
+  message: string;
+}
+
+/** Problem details (RFC 7807) of a failed request */
+export interface Problem {
+  type: string;
+  title: string;
+  status: number;
+  detail?: string;
+  instance?: string;
+  code: string;
+  errors?: FieldError[];
+}
+
+/** A failed request, carrying the problem details of the response */
+export class ApiError extends Error implements Problem {
+  readonly type: string;
+  readonly title: string;
+  readonly status: number;
+  readonly detail?: string;
+  readonly instance?: string;
+  readonly code: string;
+  readonly errors: FieldError[];
+
+  constructor(problem: Problem) {
+    super(problem.code + ': ' + (problem.detail || problem.title));
+    this.name = 'ApiError';
+    this.type = problem.type;
+    this.title = problem.title;
+    this.status = problem.status;
+    this.detail = problem.detail;
+    this.instance = problem.instance;
+    this.code = problem.code;
+    this.errors = problem.errors ?? [];
+  }
+
+  /** Returns the error of the field with the given JSON path, undefined when it was accepted */
+  field(name: string): FieldError | undefined {
+    return this.errors.find((error) => error.field === name);
+  }
+}
+
+/** Reports whether err is an ApiError, with the given code when there is one */
+export function isApiError(err: unknown, code?: ErrorCode): err is ApiError {
+  return err instanceof ApiError && (code === undefined || err.code === code);
+}
+
+/** Reads the error of a failed response. Responses without problem details, e.g. from a proxy, get the code of their status. */
+export async function errorFromResponse(response: Response): Promise<ApiError> {
+  const text = await response.text();
+  let problem: Partial<Problem> | undefined;
+  try {
+    problem = JSON.parse(text);
+  } catch {
+    problem = undefined;
+  }
+  if (typeof problem?.code !== 'string') {
+    problem = { title: response.statusText, detail: text.trim() || undefined, code: statusCode(response.status) };
+  }
+  return new ApiError({ type: 'about:blank', title: '', ...problem, code: problem.code ?? ErrorCode.Internal, status: response.status });
+}
+
+function statusCode(status: number): ErrorCode {
+  switch (status) {
+    case 400:
+      return ErrorCode.BadRequest;
+    case 401:
+      return ErrorCode.Unauthorized;
+    case 403:
+      return ErrorCode.Forbidden;
+    case 404:
+      return ErrorCode.NotFound;
+    case 409:
+      return ErrorCode.Conflict;
+    case 422:
+      return ErrorCode.Validation;
+    default:
+      return ErrorCode.Internal;
+  }
+}
+`
+
+// SDKTSResourceTemplate generates the types, filter builder and resource client of a resource in the
+// TypeScript SDK
+const SDKTSResourceTemplate = `import { Filter, HttpClient, Page, paginate{{if .Money}}, Money{{end}}{{if .Geo}}, Coordinates{{end}} } from './client';
+{{- range .Imports}}
+import type { {{.Type}} } from './{{.File}}';
+{{- end}}
+{{- range .Enums}}
+
+/** Values of {{$.Names.PascalCase}}.{{.Field}} */
+export type {{.Type}} = {{range $i, $value := .Values}}{{if $i}} | {{end}}'{{$value.Value}}'{{end}};
+{{- end}}
+
+/** A {{.Names.Singular}} as returned by the API */
+export interface {{.Names.PascalCase}} {
+  id: string;
+  /** RFC 3339 time */
+  created_at: string;
+  /** RFC 3339 time */
+  updated_at: string;
+{{- range .Fields}}
+  {{.JSON}}{{if .Optional}}?{{end}}: {{.TSType}};
+{{- end}}
+}
+
+/** Payload creating and updating {{.Names.Plural}} */
+export interface {{.Names.PascalCase}}Request {
+{{- range .Request}}
+  {{.JSON}}{{if .Optional}}?{{end}}: {{.TSType}};
+{{- end}}
+}
+
+/** Selects the {{.Names.Plural}} listed */
+export class {{.Names.PascalCase}}Filter extends Filter {
+{{- if .Includes}}
+  /** Embeds related records in the listed {{.Names.Plural}}, e.g. '{{index .Includes 0}}' */
+  include(...relations: string[]): this {
+    this.params.set('include', relations.join(','));
+    return this;
+  }
+{{- end}}
+{{- range $i, $filter := .Filters}}
+{{- if or $i $.Includes}}
+{{end}}
+  /** Lists the {{$.Names.Plural}} {{.Doc}} */
+  {{.TSMethod}}(value: {{.TSType}}): this {
+    this.params.set('{{.JSON}}', {{.TSFormat}});
+    return this;
+  }
+{{- end}}
+}
+
+/** Calls the {{.Names.Singular}} endpoints of the API */
+export class {{.Names.PascalCase}}Resource {
+  constructor(private readonly http: HttpClient) {}
+
+  /** Creates a {{.Names.Singular}} */
+  create(request: {{.Names.PascalCase}}Request, signal?: AbortSignal): Promise<{{.Names.PascalCase}}> {
+    return this.http.request('POST', '{{.Path}}', { body: request, signal });
+  }
+
+  /** Returns the {{.Names.Singular}} with the given ID{{if .Includes}}, embedding the related records of include{{end}} */
+  get(id: string, {{if .Includes}}options: { include?: string[]; signal?: AbortSignal } = {}{{else}}options: { signal?: AbortSignal } = {}{{end}}): Promise<{{.Names.PascalCase}}> {
+{{- if .Includes}}
+    const query = options.include?.length ? new URLSearchParams({ include: options.include.join(',') }) : undefined;
+    return this.http.request('GET', '{{.Path}}/' + encodeURIComponent(id), { query, signal: options.signal });
+{{- else}}
+    return this.http.request('GET', '{{.Path}}/' + encodeURIComponent(id), { signal: options.signal });
+{{- end}}
+  }
+
+  /** Returns a page of the {{.Names.Plural}} selected by filter, the first page without one */
+  list(filter?: {{.Names.PascalCase}}Filter, signal?: AbortSignal): Promise<Page<{{.Names.PascalCase}}>> {
+    return this.http.request('GET', '{{.Path}}', { query: filter?.toQuery(), signal });
+  }
+
+  /**
+   * Walks every {{.Names.Singular}} selected by filter, requesting the pages as it goes:
+   *
+   *   for await (const {{.Names.CamelCase}} of client.{{.Names.CamelPlural}}.iterate()) {}
+   */
+  iterate(filter?: {{.Names.PascalCase}}Filter, signal?: AbortSignal): AsyncGenerator<{{.Names.PascalCase}}, void, undefined> {
+    return paginate(filter?.toQuery() ?? new URLSearchParams(), (query) =>
+      this.http.request<Page<{{.Names.PascalCase}}>>('GET', '{{.Path}}', { query, signal }),
+    );
+  }
+
+  /** Replaces the fields of the {{.Names.Singular}} with the given ID */
+  update(id: string, request: {{.Names.PascalCase}}Request, signal?: AbortSignal): Promise<{{.Names.PascalCase}}> {
+    return this.http.request('PUT', '{{.Path}}/' + encodeURIComponent(id), { body: request, signal });
+  }
+
+  /** Deletes the {{.Names.Singular}} with the given ID */
+  async delete(id: string, signal?: AbortSignal): Promise<void> {
+    await this.http.request('DELETE', '{{.Path}}/' + encodeURIComponent(id), { signal });
+  }
+}
+`
+
+// SDKTSIndexTemplate generates sdk/typescript/src/index.ts, the Client composing the resources
+const SDKTSIndexTemplate = `import { ClientOptions, HttpClient } from './client';
+{{- range .Resources}}
+import { {{.Names.PascalCase}}Resource } from './{{.Names.KebabCase}}';
+{{- end}}
+
+export * from './client';
+export * from './errors';
+{{- range .Resources}}
+export * from './{{.Names.KebabCase}}';
+{{- end}}
+
+/**
+ * Typed client of the {{.Title}} API. Failed requests throw an ApiError mirroring the problem details of the API:
+ *
+ *   const client = new Client({ baseUrl: 'https://api.example.com', token });
+ *   const page = await client.{{(index .Resources 0).Names.CamelPlural}}.list(new {{(index .Resources 0).Names.PascalCase}}Filter().pageSize(50));
+ */
+export class Client {
+{{- range .Resources}}
+  readonly {{.Names.CamelPlural}}: {{.Names.PascalCase}}Resource;
+{{- end}}
+
+  constructor(options: ClientOptions) {
+    const http = new HttpClient(options);
+{{- range .Resources}}
+    this.{{.Names.CamelPlural}} = new {{.Names.PascalCase}}Resource(http);
+{{- end}}
+  }
+}
+`
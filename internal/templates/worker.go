@@ -0,0 +1,1806 @@
+package templates
+
+// JobsPackageTemplate generates the jobs, their statuses and the store of the job queue
+const JobsPackageTemplate = `package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned when a job does not exist
+var ErrNotFound = errors.New("job not found")
+
+// ErrDuplicate is returned when a job with the same unique key was already enqueued
+var ErrDuplicate = errors.New("duplicate job")
+
+// ErrNotDead is returned when requeueing a job that is not in the dead letter
+var ErrNotDead = errors.New("job is not in the dead letter")
+
+// DefaultQueue is the queue of jobs enqueued without one
+const DefaultQueue = "default"
+
+// Status is the state of a job
+type Status string
+
+const (
+	StatusPending   Status = "pending"   // Waiting for its run_at, including retries
+	StatusRunning   Status = "running"   // Claimed by a worker until locked_until
+	StatusSucceeded Status = "succeeded" // Handled successfully
+	StatusDead      Status = "dead"      // Out of attempts or failed permanently, kept for inspection
+)
+
+// Job is a unit of background work
+type Job struct {
+	ID          string          ` + "`json:\"id\"`" + `
+	Queue       string          ` + "`json:\"queue\"`" + `
+	Type        string          ` + "`json:\"type\"`" + `
+	Payload     json.RawMessage ` + "`json:\"payload\"`" + `
+	Status      Status          ` + "`json:\"status\"`" + `
+	Attempts    int             ` + "`json:\"attempts\"`" + `
+	MaxAttempts int             ` + "`json:\"max_attempts\"`" + `
+	RunAt       time.Time       ` + "`json:\"run_at\"`" + `
+	LockedUntil *time.Time      ` + "`json:\"locked_until,omitempty\"`" + `
+	LastError   string          ` + "`json:\"last_error,omitempty\"`" + `
+	UniqueKey   string          ` + "`json:\"unique_key,omitempty\"`" + `
+	CreatedAt   time.Time       ` + "`json:\"created_at\"`" + `
+	UpdatedAt   time.Time       ` + "`json:\"updated_at\"`" + `
+	FinishedAt  *time.Time      ` + "`json:\"finished_at,omitempty\"`" + `
+}
+
+// Filter selects the jobs returned by Store.List, the latest first
+type Filter struct {
+	Queue  string
+	Status Status
+	Limit  int
+}
+
+// QueueStats counts the jobs of a queue by status
+type QueueStats struct {
+	Queue     string ` + "`json:\"queue\"`" + `
+	Pending   int64  ` + "`json:\"pending\"`" + `
+	Running   int64  ` + "`json:\"running\"`" + `
+	Succeeded int64  ` + "`json:\"succeeded\"`" + `
+	Dead      int64  ` + "`json:\"dead\"`" + `
+}
+
+// add counts jobs of a status
+func (s *QueueStats) add(status Status, count int64) {
+	switch status {
+	case StatusPending:
+		s.Pending += count
+	case StatusRunning:
+		s.Running += count
+	case StatusSucceeded:
+		s.Succeeded += count
+	case StatusDead:
+		s.Dead += count
+	}
+}
+
+// Store persists the jobs of the queue
+type Store interface {
+	// Enqueue adds a pending job, ErrDuplicate when its unique key is taken
+	Enqueue(ctx context.Context, job *Job) error
+	// Claim locks the next due job of the queues until now+lease and counts the attempt.
+	// Running jobs whose lock expired are claimed again. It returns nil when no job is due.
+	Claim(ctx context.Context, queues []string, now time.Time, lease time.Duration) (*Job, error)
+	// Complete marks a claimed job as succeeded
+	Complete(ctx context.Context, id string, now time.Time) error
+	// Retry schedules a claimed job to run again at runAt
+	Retry(ctx context.Context, id string, runAt time.Time, lastError string) error
+	// Bury moves a job to the dead letter
+	Bury(ctx context.Context, id string, now time.Time, lastError string) error
+	// Requeue resets the attempts of a dead job and makes it due at now, ErrNotDead for
+	// jobs out of the dead letter
+	Requeue(ctx context.Context, id string, now time.Time) error
+	Get(ctx context.Context, id string) (*Job, error)
+	List(ctx context.Context, filter Filter) ([]*Job, error)
+	Stats(ctx context.Context) ([]QueueStats, error)
+	Delete(ctx context.Context, id string) error
+	// Prune deletes the jobs that succeeded before the given time
+	Prune(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Option configures an enqueued job
+type Option func(*Job)
+
+// InQueue enqueues the job in the named queue instead of DefaultQueue
+func InQueue(queue string) Option {
+	return func(job *Job) { job.Queue = queue }
+}
+
+// RunAt delays the job until the given time
+func RunAt(at time.Time) Option {
+	return func(job *Job) { job.RunAt = at.UTC() }
+}
+
+// Delay delays the job by the given duration
+func Delay(d time.Duration) Option {
+	return func(job *Job) { job.RunAt = job.RunAt.Add(d) }
+}
+
+// MaxAttempts sets the attempts of the job before it is moved to the dead letter
+func MaxAttempts(attempts int) Option {
+	return func(job *Job) { job.MaxAttempts = attempts }
+}
+
+// Unique makes Enqueue return ErrDuplicate while a job with the same key exists
+func Unique(key string) Option {
+	return func(job *Job) { job.UniqueKey = key }
+}
+
+// DefaultMaxAttempts is the attempts of jobs enqueued without MaxAttempts
+const DefaultMaxAttempts = 10
+
+// Enqueue adds a job of the given type whose payload is encoded as JSON
+func Enqueue[T any](ctx context.Context, store Store, jobType string, payload T, opts ...Option) (*Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	job := &Job{
+		ID:          NewID(),
+		Queue:       DefaultQueue,
+		Type:        jobType,
+		Payload:     data,
+		Status:      StatusPending,
+		MaxAttempts: DefaultMaxAttempts,
+		RunAt:       now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	for _, opt := range opts {
+		opt(job)
+	}
+	if err := store.Enqueue(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// NewID returns a random job identifier
+func NewID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return hex.EncodeToString(b[0:4]) + "-" + hex.EncodeToString(b[4:6]) + "-" + hex.EncodeToString(b[6:8]) + "-" +
+		hex.EncodeToString(b[8:10]) + "-" + hex.EncodeToString(b[10:])
+}
+`
+
+// JobsQueueTemplate generates the queue running typed job handlers with retries and a dead letter
+const JobsQueueTemplate = `package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// HandlerFunc handles a claimed job, returning an error retries it
+type HandlerFunc func(ctx context.Context, job *Job) error
+
+// permanentError is a failure that is not retried
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps an error so the job goes straight to the dead letter instead of being retried
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+// Queue claims due jobs from the store and runs the handler registered for their type.
+// Failed jobs are retried with an exponential backoff and moved to the dead letter once
+// they run out of attempts.
+type Queue struct {
+	store    Store
+	handlers map[string]HandlerFunc
+	now      func() time.Time
+
+	Queues       []string      // Queues claimed from
+	Concurrency  int           // Jobs handled at the same time
+	PollInterval time.Duration // Wait between claims when no job is due
+	Lease        time.Duration // Time a job is locked for, and the timeout of its handler
+	BaseDelay    time.Duration // Delay before the first retry, doubled after each attempt
+	MaxDelay     time.Duration
+}
+
+// NewQueue creates a queue processing the jobs of the store
+func NewQueue(store Store) *Queue {
+	return &Queue{
+		store:        store,
+		handlers:     make(map[string]HandlerFunc),
+		now:          func() time.Time { return time.Now().UTC() },
+		Queues:       []string{DefaultQueue},
+		Concurrency:  4,
+		PollInterval: time.Second,
+		Lease:        5 * time.Minute,
+		BaseDelay:    10 * time.Second,
+		MaxDelay:     time.Hour,
+	}
+}
+
+// Handle registers the handler of a job type
+func (q *Queue) Handle(jobType string, handler HandlerFunc) {
+	q.handlers[jobType] = handler
+}
+
+// Register registers a typed handler of a job type, the payload is decoded from JSON and
+// jobs whose payload does not decode go to the dead letter
+func Register[T any](q *Queue, jobType string, handler func(ctx context.Context, payload T) error) {
+	q.Handle(jobType, func(ctx context.Context, job *Job) error {
+		var payload T
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return Permanent(fmt.Errorf("invalid %s payload: %w", jobType, err))
+		}
+		return handler(ctx, payload)
+	})
+}
+
+// JobTypes returns the registered job types
+func (q *Queue) JobTypes() []string {
+	types := make([]string, 0, len(q.handlers))
+	for jobType := range q.handlers {
+		types = append(types, jobType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Run handles jobs with Concurrency workers until the context is cancelled
+func (q *Queue) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < q.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				worked, err := q.Work(ctx)
+				if err != nil && ctx.Err() == nil {
+					log.Printf("jobs: %v", err)
+				}
+				if worked && err == nil {
+					continue
+				}
+				select {
+				case <-ctx.Done():
+				case <-time.After(q.PollInterval):
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Work claims and handles one due job, it reports false when no job was due
+func (q *Queue) Work(ctx context.Context) (bool, error) {
+	job, err := q.store.Claim(ctx, q.Queues, q.now(), q.Lease)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim a job: %w", err)
+	}
+	if job == nil {
+		return false, nil
+	}
+
+	// A worker that crashed may have left the job running past its last attempt
+	if job.Attempts > job.MaxAttempts {
+		return true, q.store.Bury(ctx, job.ID, q.now(), fmt.Sprintf("lock expired after %d attempts", job.MaxAttempts))
+	}
+
+	handleErr := q.handle(ctx, job)
+	// The outcome is recorded even when the worker is shutting down
+	ctx = context.WithoutCancel(ctx)
+	switch {
+	case handleErr == nil:
+		err = q.store.Complete(ctx, job.ID, q.now())
+	case errors.As(handleErr, new(permanentError)) || job.Attempts >= job.MaxAttempts:
+		err = q.store.Bury(ctx, job.ID, q.now(), handleErr.Error())
+	default:
+		err = q.store.Retry(ctx, job.ID, q.now().Add(q.Backoff(job.Attempts)), handleErr.Error())
+	}
+	if err != nil {
+		return true, fmt.Errorf("failed to record job %s: %w", job.ID, err)
+	}
+	return true, nil
+}
+
+// handle runs the handler of a job within its lease, recovering panics
+func (q *Queue) handle(ctx context.Context, job *Job) (err error) {
+	handler, ok := q.handlers[job.Type]
+	if !ok {
+		return Permanent(fmt.Errorf("no handler registered for job type %q", job.Type))
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, q.Lease)
+	defer cancel()
+	return handler(ctx, job)
+}
+
+// Backoff returns the delay before the retry following the given attempt
+func (q *Queue) Backoff(attempt int) time.Duration {
+	delay := q.BaseDelay
+	for i := 1; i < attempt && delay < q.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > q.MaxDelay {
+		delay = q.MaxDelay
+	}
+	return delay
+}
+`
+
+// JobsScheduleTemplate generates the cron-style schedules enqueuing recurring jobs
+const JobsScheduleTemplate = `package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule returns the activation times of a recurring job
+type Schedule interface {
+	// Next returns the first activation strictly after t
+	Next(t time.Time) time.Time
+}
+
+// ParseSchedule parses a cron expression evaluated in UTC. It accepts the five standard
+// fields (minute, hour, day of month, month, day of week) with lists, ranges and steps,
+// the @yearly, @monthly, @weekly, @daily and @hourly shorthands and "@every <duration>".
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		interval, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || interval < time.Second {
+			return nil, fmt.Errorf("invalid schedule %q: the interval must be a duration of at least 1s", spec)
+		}
+		return everySchedule(interval), nil
+	}
+
+	switch spec {
+	case "@yearly", "@annually":
+		spec = "0 0 1 1 *"
+	case "@monthly":
+		spec = "0 0 1 * *"
+	case "@weekly":
+		spec = "0 0 * * 0"
+	case "@daily", "@midnight":
+		spec = "0 0 * * *"
+	case "@hourly":
+		spec = "0 * * * *"
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q: expected 5 fields, got %d", spec, len(fields))
+	}
+
+	var s cronSchedule
+	var err error
+	for i, bounds := range cronBounds {
+		if s.fields[i], err = parseCronField(fields[i], bounds[0], bounds[1]); err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %w", spec, err)
+		}
+	}
+	// Sunday is both 0 and 7
+	if s.fields[4]&(1<<7) != 0 {
+		s.fields[4] |= 1
+	}
+	s.anyDay = fields[2] == "*" || fields[4] == "*"
+	return s, nil
+}
+
+// everySchedule activates at the multiples of an interval since the Unix epoch, so every
+// worker agrees on the activation times
+type everySchedule time.Duration
+
+func (e everySchedule) Next(t time.Time) time.Time {
+	interval := time.Duration(e)
+	return t.UTC().Truncate(interval).Add(interval)
+}
+
+// cronBounds are the minimum and maximum of the cron fields
+var cronBounds = [5][2]int{
+	{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7},
+}
+
+// cronSchedule holds a bit per allowed value of the minute, hour, day of month, month and
+// day of week fields
+type cronSchedule struct {
+	fields [5]uint64
+	anyDay bool // A day matches when either day field does if both are restricted, like cron
+}
+
+func (c cronSchedule) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case !c.has(3, int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !c.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case !c.has(1, t.Hour()):
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case !c.has(0, t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// has reports whether a value is allowed in a field
+func (c cronSchedule) has(field, value int) bool {
+	return c.fields[field]&(1<<uint(value)) != 0
+}
+
+// matchesDay reports whether the day of t is allowed
+func (c cronSchedule) matchesDay(t time.Time) bool {
+	dayOfMonth, dayOfWeek := c.has(2, t.Day()), c.has(4, int(t.Weekday()))
+	if c.anyDay {
+		return dayOfMonth && dayOfWeek
+	}
+	return dayOfMonth || dayOfWeek
+}
+
+// parseCronField parses a comma separated list of values, ranges and steps
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		expr, stepText, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepText); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q", part)
+			}
+		}
+
+		low, high := min, max
+		if expr != "*" {
+			lowText, highText, isRange := strings.Cut(expr, "-")
+			var err error
+			if low, err = strconv.Atoi(lowText); err != nil {
+				return 0, fmt.Errorf("invalid value %q", part)
+			}
+			high = low
+			if isRange {
+				if high, err = strconv.Atoi(highText); err != nil {
+					return 0, fmt.Errorf("invalid range %q", part)
+				}
+			} else if hasStep {
+				high = max
+			}
+		}
+		if low < min || high > max || low > high {
+			return 0, fmt.Errorf("%q is outside %d-%d", part, min, max)
+		}
+		for value := low; value <= high; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+	return bits, nil
+}
+
+// scheduledJob is a recurring job of the scheduler
+type scheduledJob struct {
+	name     string
+	schedule Schedule
+	jobType  string
+	payload  interface{}
+	opts     []Option
+	next     time.Time
+}
+
+// Scheduler enqueues recurring jobs. Each activation is enqueued with a unique key, so
+// several workers running the same schedules enqueue it once. Activations missed while no
+// scheduler was running are enqueued once.
+type Scheduler struct {
+	store Store
+	jobs  []*scheduledJob
+	now   func() time.Time
+
+	Interval time.Duration // Wait between checks of the due schedules
+}
+
+// NewScheduler creates a scheduler enqueuing into the store
+func NewScheduler(store Store) *Scheduler {
+	return &Scheduler{
+		store:    store,
+		now:      func() time.Time { return time.Now().UTC() },
+		Interval: time.Second,
+	}
+}
+
+// Add schedules a job of the given type with a cron expression, see ParseSchedule
+func (s *Scheduler) Add(name, spec, jobType string, payload interface{}, opts ...Option) error {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		return err
+	}
+	for _, job := range s.jobs {
+		if job.name == name {
+			return fmt.Errorf("schedule %q is already registered", name)
+		}
+	}
+
+	s.jobs = append(s.jobs, &scheduledJob{
+		name:     name,
+		schedule: schedule,
+		jobType:  jobType,
+		payload:  payload,
+		opts:     opts,
+		next:     schedule.Next(s.now()),
+	})
+	return nil
+}
+
+// Run enqueues the due schedules until the context is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Tick(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("jobs: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick enqueues the schedules whose activation is due
+func (s *Scheduler) Tick(ctx context.Context) error {
+	now := s.now()
+	for _, job := range s.jobs {
+		if job.next.IsZero() || job.next.After(now) {
+			continue
+		}
+
+		// Missed activations collapse into the latest one
+		activation := job.next
+		for next := job.schedule.Next(activation); !next.IsZero() && !next.After(now); next = job.schedule.Next(next) {
+			activation = next
+		}
+
+		opts := append([]Option{Unique(fmt.Sprintf("schedule:%s:%d", job.name, activation.Unix())), RunAt(activation)}, job.opts...)
+		if _, err := Enqueue(ctx, s.store, job.jobType, json.RawMessage(mustJSON(job.payload)), opts...); err != nil && !errors.Is(err, ErrDuplicate) {
+			return fmt.Errorf("failed to enqueue schedule %s: %w", job.name, err)
+		}
+		job.next = job.schedule.Next(activation)
+	}
+	return nil
+}
+
+// mustJSON encodes a scheduled payload, nil payloads are encoded as an empty object
+func mustJSON(payload interface{}) []byte {
+	if payload == nil {
+		return []byte("{}")
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		panic(fmt.Sprintf("jobs: invalid scheduled payload: %v", err))
+	}
+	return data
+}
+`
+
+// JobsHandlersTemplate generates the registration of the job handlers and schedules of the
+// project, written once and then owned by the project
+const JobsHandlersTemplate = `package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// PruneJobs is the type of the job deleting old succeeded jobs
+const PruneJobs = "jobs.prune"
+
+// PrunePayload is the payload of PruneJobs
+type PrunePayload struct {
+	RetentionHours int ` + "`json:\"retention_hours\"`" + `
+}
+
+// RegisterHandlers registers the job handlers run by the worker.
+//
+//	const SendWelcomeEmail = "users.send_welcome_email"
+//
+//	jobs.Register(queue, SendWelcomeEmail, func(ctx context.Context, payload WelcomeEmail) error { ... })
+//
+// and enqueue them from services with jobs.Enqueue(ctx, store, SendWelcomeEmail, payload).
+func RegisterHandlers(queue *Queue, store Store) {
+	Register(queue, PruneJobs, func(ctx context.Context, payload PrunePayload) error {
+		pruned, err := store.Prune(ctx, time.Now().UTC().Add(-time.Duration(payload.RetentionHours)*time.Hour))
+		if err != nil {
+			return err
+		}
+		log.Printf("jobs: pruned %d succeeded jobs", pruned)
+		return nil
+	})
+}
+
+// RegisterSchedules registers the recurring jobs enqueued by the worker
+func RegisterSchedules(scheduler *Scheduler) error {
+	return scheduler.Add("prune-jobs", "@daily", PruneJobs, PrunePayload{RetentionHours: 7 * 24})
+}
+`
+
+// JobsSQLStoreTemplate generates the job store of SQL databases. Postgres and MySQL claim
+// jobs with SELECT ... FOR UPDATE SKIP LOCKED, SQLite polls with an optimistic update.
+const JobsSQLStoreTemplate = `package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Dialect is the SQL database of a SQLStore
+type Dialect string
+
+const (
+	Postgres Dialect = "postgres"
+	MySQL    Dialect = "mysql"
+	SQLite   Dialect = "sqlite"
+)
+
+// jobColumns are the columns of the jobs table in the order scanned by scanJob
+const jobColumns = "id, queue, job_type, payload, status, attempts, max_attempts, run_at, locked_until, last_error, unique_key, created_at, updated_at, finished_at"
+
+// SQLStore stores the jobs in the jobs table
+type SQLStore struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+// NewSQLStore creates a job store on a database
+func NewSQLStore(db *sql.DB, dialect Dialect) *SQLStore {
+	return &SQLStore{db: db, dialect: dialect}
+}
+
+// rebind replaces the ? placeholders of a query with the placeholders of the dialect
+func (s *SQLStore) rebind(query string) string {
+	if s.dialect != Postgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			fmt.Fprintf(&b, "$%d", n)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Enqueue adds a pending job
+func (s *SQLStore) Enqueue(ctx context.Context, job *Job) error {
+	insert := "INSERT INTO jobs"
+	conflict := ""
+	switch s.dialect {
+	case MySQL:
+		insert = "INSERT IGNORE INTO jobs"
+	default:
+		conflict = " ON CONFLICT DO NOTHING"
+	}
+
+	result, err := s.db.ExecContext(ctx, s.rebind(insert+" ("+jobColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"+conflict),
+		job.ID, job.Queue, job.Type, string(job.Payload), job.Status, job.Attempts, job.MaxAttempts, job.RunAt.UTC(),
+		job.LockedUntil, job.LastError, nullString(job.UniqueKey), job.CreatedAt.UTC(), job.UpdatedAt.UTC(), job.FinishedAt)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrDuplicate
+	}
+	return nil
+}
+
+// dueCondition selects the jobs of the queues due at a time, its arguments follow the queues
+func dueCondition(queues []string) (string, []interface{}) {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(queues)), ", ")
+	args := make([]interface{}, len(queues))
+	for i, queue := range queues {
+		args[i] = queue
+	}
+	return "queue IN (" + placeholders + ") AND ((status = 'pending' AND run_at <= ?) OR (status = 'running' AND locked_until <= ?))", args
+}
+
+// Claim locks the next due job of the queues
+func (s *SQLStore) Claim(ctx context.Context, queues []string, now time.Time, lease time.Duration) (*Job, error) {
+	if len(queues) == 0 {
+		return nil, nil
+	}
+	if s.dialect == SQLite {
+		return s.claimPolling(ctx, queues, now.UTC(), lease)
+	}
+	return s.claimLocked(ctx, queues, now.UTC(), lease)
+}
+
+// claimLocked selects the next due job with FOR UPDATE SKIP LOCKED, so concurrent workers
+// skip the rows locked by each other instead of waiting for them
+func (s *SQLStore) claimLocked(ctx context.Context, queues []string, now time.Time, lease time.Duration) (*Job, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	condition, args := dueCondition(queues)
+	args = append(args, now, now)
+	job, err := scanJob(tx.QueryRowContext(ctx, s.rebind("SELECT "+jobColumns+" FROM jobs WHERE "+condition+
+		" ORDER BY run_at, created_at LIMIT 1 FOR UPDATE SKIP LOCKED"), args...))
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	lockedUntil := now.Add(lease)
+	if _, err := tx.ExecContext(ctx, s.rebind("UPDATE jobs SET status = 'running', attempts = attempts + 1, locked_until = ?, updated_at = ? WHERE id = ?"),
+		lockedUntil, now, job.ID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	job.Status, job.Attempts, job.LockedUntil, job.UpdatedAt = StatusRunning, job.Attempts+1, &lockedUntil, now
+	return job, nil
+}
+
+// claimPolling selects due jobs and claims the first one no other worker claimed in the
+// meantime, checked by the attempts and status the job was selected with
+func (s *SQLStore) claimPolling(ctx context.Context, queues []string, now time.Time, lease time.Duration) (*Job, error) {
+	condition, args := dueCondition(queues)
+	args = append(args, now, now)
+	rows, err := s.db.QueryContext(ctx, s.rebind("SELECT "+jobColumns+" FROM jobs WHERE "+condition+
+		" ORDER BY run_at, created_at LIMIT 10"), args...)
+	if err != nil {
+		return nil, err
+	}
+	candidates, err := scanJobs(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	lockedUntil := now.Add(lease)
+	for _, job := range candidates {
+		result, err := s.db.ExecContext(ctx, s.rebind("UPDATE jobs SET status = 'running', attempts = attempts + 1, locked_until = ?, updated_at = ? WHERE id = ? AND status = ? AND attempts = ?"),
+			lockedUntil, now, job.ID, job.Status, job.Attempts)
+		if err != nil {
+			return nil, err
+		}
+		if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+			continue
+		}
+
+		job.Status, job.Attempts, job.LockedUntil, job.UpdatedAt = StatusRunning, job.Attempts+1, &lockedUntil, now
+		return job, nil
+	}
+	return nil, nil
+}
+
+// Complete marks a claimed job as succeeded
+func (s *SQLStore) Complete(ctx context.Context, id string, now time.Time) error {
+	return s.update(ctx, "status = 'succeeded', locked_until = NULL, last_error = '', updated_at = ?, finished_at = ?", id, now.UTC(), now.UTC())
+}
+
+// Retry schedules a claimed job to run again
+func (s *SQLStore) Retry(ctx context.Context, id string, runAt time.Time, lastError string) error {
+	return s.update(ctx, "status = 'pending', locked_until = NULL, run_at = ?, last_error = ?, updated_at = ?", id, runAt.UTC(), lastError, time.Now().UTC())
+}
+
+// Bury moves a job to the dead letter
+func (s *SQLStore) Bury(ctx context.Context, id string, now time.Time, lastError string) error {
+	return s.update(ctx, "status = 'dead', locked_until = NULL, last_error = ?, updated_at = ?, finished_at = ?", id, lastError, now.UTC(), now.UTC())
+}
+
+// Requeue makes a dead job due again with its attempts reset
+func (s *SQLStore) Requeue(ctx context.Context, id string, now time.Time) error {
+	result, err := s.db.ExecContext(ctx, s.rebind("UPDATE jobs SET status = 'pending', attempts = 0, run_at = ?, updated_at = ?, finished_at = NULL WHERE id = ? AND status = 'dead'"),
+		now.UTC(), now.UTC(), id)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		if _, err := s.Get(ctx, id); err != nil {
+			return err
+		}
+		return ErrNotDead
+	}
+	return nil
+}
+
+// update sets columns of a job, the arguments of the assignments come before the id
+func (s *SQLStore) update(ctx context.Context, assignments, id string, args ...interface{}) error {
+	result, err := s.db.ExecContext(ctx, s.rebind("UPDATE jobs SET "+assignments+" WHERE id = ?"), append(args, id)...)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Get returns a job
+func (s *SQLStore) Get(ctx context.Context, id string) (*Job, error) {
+	return scanJob(s.db.QueryRowContext(ctx, s.rebind("SELECT "+jobColumns+" FROM jobs WHERE id = ?"), id))
+}
+
+// List returns the latest jobs matching the filter
+func (s *SQLStore) List(ctx context.Context, filter Filter) ([]*Job, error) {
+	query := "SELECT " + jobColumns + " FROM jobs WHERE 1 = 1"
+	var args []interface{}
+	if filter.Queue != "" {
+		query += " AND queue = ?"
+		args = append(args, filter.Queue)
+	}
+	if filter.Status != "" {
+		query += " AND status = ?"
+		args = append(args, filter.Status)
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT %d", limit)
+
+	rows, err := s.db.QueryContext(ctx, s.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanJobs(rows)
+}
+
+// Stats counts the jobs of every queue by status
+func (s *SQLStore) Stats(ctx context.Context) ([]QueueStats, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT queue, status, COUNT(*) FROM jobs GROUP BY queue, status ORDER BY queue")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []QueueStats
+	for rows.Next() {
+		var queue string
+		var status Status
+		var count int64
+		if err := rows.Scan(&queue, &status, &count); err != nil {
+			return nil, err
+		}
+		if len(stats) == 0 || stats[len(stats)-1].Queue != queue {
+			stats = append(stats, QueueStats{Queue: queue})
+		}
+		stats[len(stats)-1].add(status, count)
+	}
+	return stats, rows.Err()
+}
+
+// Delete deletes a job
+func (s *SQLStore) Delete(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, s.rebind("DELETE FROM jobs WHERE id = ?"), id)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Prune deletes the jobs that succeeded before the given time
+func (s *SQLStore) Prune(ctx context.Context, before time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx, s.rebind("DELETE FROM jobs WHERE status = 'succeeded' AND finished_at < ?"), before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// rowScanner is a row of jobs
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanJob scans a job, ErrNotFound when there is no row
+func scanJob(row rowScanner) (*Job, error) {
+	var job Job
+	var payload string
+	var lockedUntil, finishedAt sql.NullTime
+	var uniqueKey sql.NullString
+	err := row.Scan(&job.ID, &job.Queue, &job.Type, &payload, &job.Status, &job.Attempts, &job.MaxAttempts, &job.RunAt,
+		&lockedUntil, &job.LastError, &uniqueKey, &job.CreatedAt, &job.UpdatedAt, &finishedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	job.Payload = []byte(payload)
+	job.UniqueKey = uniqueKey.String
+	if lockedUntil.Valid {
+		job.LockedUntil = &lockedUntil.Time
+	}
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+	return &job, nil
+}
+
+// scanJobs scans and closes rows of jobs
+func scanJobs(rows *sql.Rows) ([]*Job, error) {
+	defer rows.Close()
+
+	jobs := make([]*Job, 0)
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// nullString stores empty unique keys as NULL so they don't collide
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+`
+
+// JobsMemoryStoreTemplate generates the in-memory job store used by tests
+const JobsMemoryStoreTemplate = `package jobs
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps jobs in memory, for tests and single process development setups
+type MemoryStore struct {
+	mu   sync.Mutex
+	jobs map[string]*Job
+}
+
+// NewMemoryStore creates an empty in-memory job store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{jobs: make(map[string]*Job)}
+}
+
+// Enqueue adds a pending job
+func (s *MemoryStore) Enqueue(ctx context.Context, job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if job.UniqueKey != "" {
+		for _, existing := range s.jobs {
+			if existing.UniqueKey == job.UniqueKey {
+				return ErrDuplicate
+			}
+		}
+	}
+	stored := *job
+	s.jobs[job.ID] = &stored
+	return nil
+}
+
+// Claim locks the next due job of the queues
+func (s *MemoryStore) Claim(ctx context.Context, queues []string, now time.Time, lease time.Duration) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var next *Job
+	for _, job := range s.sorted() {
+		if !contains(queues, job.Queue) {
+			continue
+		}
+		due := job.Status == StatusPending && !job.RunAt.After(now)
+		expired := job.Status == StatusRunning && job.LockedUntil != nil && !job.LockedUntil.After(now)
+		if due || expired {
+			next = job
+			break
+		}
+	}
+	if next == nil {
+		return nil, nil
+	}
+
+	lockedUntil := now.Add(lease)
+	next.Status, next.Attempts, next.LockedUntil, next.UpdatedAt = StatusRunning, next.Attempts+1, &lockedUntil, now
+	claimed := *next
+	return &claimed, nil
+}
+
+// Complete marks a claimed job as succeeded
+func (s *MemoryStore) Complete(ctx context.Context, id string, now time.Time) error {
+	return s.update(id, func(job *Job) error {
+		job.Status, job.LockedUntil, job.LastError, job.UpdatedAt, job.FinishedAt = StatusSucceeded, nil, "", now, &now
+		return nil
+	})
+}
+
+// Retry schedules a claimed job to run again
+func (s *MemoryStore) Retry(ctx context.Context, id string, runAt time.Time, lastError string) error {
+	return s.update(id, func(job *Job) error {
+		job.Status, job.LockedUntil, job.RunAt, job.LastError, job.UpdatedAt = StatusPending, nil, runAt, lastError, time.Now().UTC()
+		return nil
+	})
+}
+
+// Bury moves a job to the dead letter
+func (s *MemoryStore) Bury(ctx context.Context, id string, now time.Time, lastError string) error {
+	return s.update(id, func(job *Job) error {
+		job.Status, job.LockedUntil, job.LastError, job.UpdatedAt, job.FinishedAt = StatusDead, nil, lastError, now, &now
+		return nil
+	})
+}
+
+// Requeue makes a dead job due again with its attempts reset
+func (s *MemoryStore) Requeue(ctx context.Context, id string, now time.Time) error {
+	return s.update(id, func(job *Job) error {
+		if job.Status != StatusDead {
+			return ErrNotDead
+		}
+		job.Status, job.Attempts, job.RunAt, job.UpdatedAt, job.FinishedAt = StatusPending, 0, now, now, nil
+		return nil
+	})
+}
+
+// update changes a job under the lock
+func (s *MemoryStore) update(id string, change func(job *Job) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return ErrNotFound
+	}
+	return change(job)
+}
+
+// Get returns a job
+func (s *MemoryStore) Get(ctx context.Context, id string) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	found := *job
+	return &found, nil
+}
+
+// List returns the latest jobs matching the filter
+func (s *MemoryStore) List(ctx context.Context, filter Filter) ([]*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	sorted := s.sorted()
+	jobs := make([]*Job, 0)
+	for i := len(sorted) - 1; i >= 0 && len(jobs) < limit; i-- {
+		job := sorted[i]
+		if (filter.Queue == "" || job.Queue == filter.Queue) && (filter.Status == "" || job.Status == filter.Status) {
+			found := *job
+			jobs = append(jobs, &found)
+		}
+	}
+	return jobs, nil
+}
+
+// Stats counts the jobs of every queue by status
+func (s *MemoryStore) Stats(ctx context.Context) ([]QueueStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	byQueue := make(map[string]*QueueStats)
+	for _, job := range s.jobs {
+		if byQueue[job.Queue] == nil {
+			byQueue[job.Queue] = &QueueStats{Queue: job.Queue}
+		}
+		byQueue[job.Queue].add(job.Status, 1)
+	}
+
+	stats := make([]QueueStats, 0, len(byQueue))
+	for _, queue := range byQueue {
+		stats = append(stats, *queue)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Queue < stats[j].Queue })
+	return stats, nil
+}
+
+// Delete deletes a job
+func (s *MemoryStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.jobs[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.jobs, id)
+	return nil
+}
+
+// Prune deletes the jobs that succeeded before the given time
+func (s *MemoryStore) Prune(ctx context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var pruned int64
+	for id, job := range s.jobs {
+		if job.Status == StatusSucceeded && job.FinishedAt != nil && job.FinishedAt.Before(before) {
+			delete(s.jobs, id)
+			pruned++
+		}
+	}
+	return pruned, nil
+}
+
+// sorted returns the jobs by run_at then created_at
+func (s *MemoryStore) sorted() []*Job {
+	jobs := make([]*Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		if !jobs[i].RunAt.Equal(jobs[j].RunAt) {
+			return jobs[i].RunAt.Before(jobs[j].RunAt)
+		}
+		return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+	})
+	return jobs
+}
+
+// contains reports whether a queue is in the list
+func contains(queues []string, queue string) bool {
+	for _, q := range queues {
+		if q == queue {
+			return true
+		}
+	}
+	return false
+}
+`
+
+// JobsTestTemplate generates tests of the queue, its retries and dead letter and the schedules
+const JobsTestTemplate = `package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// testClock is a settable clock shared by queues and schedulers
+type testClock struct{ now time.Time }
+
+func newTestClock(at string) *testClock {
+	now, err := time.Parse(time.RFC3339, at)
+	if err != nil {
+		panic(err)
+	}
+	return &testClock{now: now}
+}
+
+func (c *testClock) Now() time.Time          { return c.now }
+func (c *testClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// newTestQueue creates a queue on the clock
+func newTestQueue(store Store, clock *testClock) *Queue {
+	queue := NewQueue(store)
+	queue.now = clock.Now
+	return queue
+}
+
+type greeting struct {
+	Name string ` + "`json:\"name\"`" + `
+}
+
+func TestQueue_RunsTypedHandlers(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	queue := newTestQueue(store, newTestClock("2030-01-01T00:00:00Z"))
+
+	var greeted string
+	Register(queue, "greet", func(ctx context.Context, payload greeting) error {
+		greeted = payload.Name
+		return nil
+	})
+
+	job, err := Enqueue(ctx, store, "greet", greeting{Name: "Ada"}, RunAt(time.Date(2029, 12, 31, 0, 0, 0, 0, time.UTC)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if worked, err := queue.Work(ctx); !worked || err != nil {
+		t.Fatalf("expected a job to be worked, got %v, %v", worked, err)
+	}
+	if greeted != "Ada" {
+		t.Fatalf("expected the handler to receive the payload, got %q", greeted)
+	}
+
+	stored, _ := store.Get(ctx, job.ID)
+	if stored.Status != StatusSucceeded || stored.Attempts != 1 || stored.FinishedAt == nil {
+		t.Fatalf("expected a succeeded job after one attempt, got %+v", stored)
+	}
+	if worked, _ := queue.Work(ctx); worked {
+		t.Fatal("expected no job left")
+	}
+}
+
+func TestQueue_RetriesWithBackoffThenBuries(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	clock := newTestClock("2030-01-01T00:00:00Z")
+	queue := newTestQueue(store, clock)
+	queue.BaseDelay, queue.MaxDelay = time.Minute, 3*time.Minute
+
+	attempts := 0
+	queue.Handle("flaky", func(ctx context.Context, job *Job) error {
+		attempts++
+		return errors.New("unavailable")
+	})
+
+	job, err := Enqueue(ctx, store, "flaky", struct{}{}, MaxAttempts(4), RunAt(clock.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, delay := range []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute} {
+		if worked, err := queue.Work(ctx); !worked || err != nil {
+			t.Fatalf("expected a job to be worked, got %v, %v", worked, err)
+		}
+		stored, _ := store.Get(ctx, job.ID)
+		if stored.Status != StatusPending || !stored.RunAt.Equal(clock.Now().Add(delay)) || stored.LastError != "unavailable" {
+			t.Fatalf("expected a retry in %s, got %+v", delay, stored)
+		}
+		if worked, _ := queue.Work(ctx); worked {
+			t.Fatal("expected the retry to wait for its backoff")
+		}
+		clock.Advance(delay)
+	}
+
+	if worked, err := queue.Work(ctx); !worked || err != nil {
+		t.Fatalf("expected the last attempt to be worked, got %v, %v", worked, err)
+	}
+	stored, _ := store.Get(ctx, job.ID)
+	if stored.Status != StatusDead || attempts != 4 {
+		t.Fatalf("expected the job in the dead letter after 4 attempts, got %d attempts: %+v", attempts, stored)
+	}
+
+	if err := store.Requeue(ctx, job.ID, clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+	stored, _ = store.Get(ctx, job.ID)
+	if stored.Status != StatusPending || stored.Attempts != 0 {
+		t.Fatalf("expected a requeued job with its attempts reset, got %+v", stored)
+	}
+}
+
+func TestQueue_BuriesPermanentFailures(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	queue := newTestQueue(store, newTestClock("2030-01-01T00:00:00Z"))
+	Register(queue, "greet", func(ctx context.Context, payload greeting) error { return nil })
+	queue.Handle("refused", func(ctx context.Context, job *Job) error { return Permanent(errors.New("refused")) })
+	queue.Handle("panics", func(ctx context.Context, job *Job) error { panic("boom") })
+
+	for _, jobType := range []string{"refused", "unknown"} {
+		job, _ := Enqueue(ctx, store, jobType, struct{}{}, RunAt(time.Time{}))
+		if _, err := queue.Work(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if stored, _ := store.Get(ctx, job.ID); stored.Status != StatusDead || stored.Attempts != 1 {
+			t.Fatalf("%s: expected the job in the dead letter after one attempt, got %+v", jobType, stored)
+		}
+	}
+
+	job, _ := Enqueue(ctx, store, "greet", "not an object", RunAt(time.Time{}))
+	if _, err := queue.Work(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if stored, _ := store.Get(ctx, job.ID); stored.Status != StatusDead {
+		t.Fatalf("expected an undecodable payload in the dead letter, got %+v", stored)
+	}
+
+	job, _ = Enqueue(ctx, store, "panics", struct{}{}, RunAt(time.Time{}))
+	if _, err := queue.Work(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if stored, _ := store.Get(ctx, job.ID); stored.Status != StatusPending || stored.LastError != "panic: boom" {
+		t.Fatalf("expected a recovered panic to be retried, got %+v", stored)
+	}
+}
+
+func TestQueue_ReclaimsExpiredLocks(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	clock := newTestClock("2030-01-01T00:00:00Z")
+	job, _ := Enqueue(ctx, store, "greet", greeting{}, RunAt(clock.Now()))
+
+	claimed, err := store.Claim(ctx, []string{DefaultQueue}, clock.Now(), time.Minute)
+	if err != nil || claimed == nil || claimed.ID != job.ID {
+		t.Fatalf("expected the job to be claimed, got %+v, %v", claimed, err)
+	}
+	if again, _ := store.Claim(ctx, []string{DefaultQueue}, clock.Now(), time.Minute); again != nil {
+		t.Fatalf("expected a locked job not to be claimed twice, got %+v", again)
+	}
+	if other, _ := store.Claim(ctx, []string{"emails"}, clock.Now(), time.Minute); other != nil {
+		t.Fatalf("expected jobs of other queues not to be claimed, got %+v", other)
+	}
+
+	clock.Advance(time.Minute)
+	reclaimed, _ := store.Claim(ctx, []string{DefaultQueue}, clock.Now(), time.Minute)
+	if reclaimed == nil || reclaimed.Attempts != 2 {
+		t.Fatalf("expected the job to be claimed again once its lock expired, got %+v", reclaimed)
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	from := time.Date(2030, 1, 1, 10, 7, 30, 0, time.UTC) // A Tuesday
+	for spec, expected := range map[string]string{
+		"* * * * *":         "2030-01-01T10:08:00Z",
+		"*/15 * * * *":      "2030-01-01T10:15:00Z",
+		"0 9-17 * * *":      "2030-01-01T11:00:00Z",
+		"30 2 * * *":        "2030-01-02T02:30:00Z",
+		"0 0 * * 0":         "2030-01-06T00:00:00Z",
+		"0 0 * * 7":         "2030-01-06T00:00:00Z",
+		"0 0 1,15 * *":      "2030-01-15T00:00:00Z",
+		"0 0 13 * 5":        "2030-01-04T00:00:00Z",
+		"0 0 29 2 *":        "2032-02-29T00:00:00Z",
+		"@hourly":           "2030-01-01T11:00:00Z",
+		"@daily":            "2030-01-02T00:00:00Z",
+		"@monthly":          "2030-02-01T00:00:00Z",
+		"@every 10m":        "2030-01-01T10:10:00Z",
+	} {
+		schedule, err := ParseSchedule(spec)
+		if err != nil {
+			t.Fatalf("%s: %v", spec, err)
+		}
+		if next := schedule.Next(from).Format(time.RFC3339); next != expected {
+			t.Errorf("%s: expected %s, got %s", spec, expected, next)
+		}
+	}
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "@every 1ms", "@every soon"} {
+		if _, err := ParseSchedule(spec); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+}
+
+func TestScheduler_EnqueuesEachActivationOnce(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	clock := newTestClock("2030-01-01T10:07:30Z")
+
+	// Two workers running the same schedules
+	var schedulers []*Scheduler
+	for i := 0; i < 2; i++ {
+		scheduler := NewScheduler(store)
+		scheduler.now = clock.Now
+		if err := scheduler.Add("report", "*/5 * * * *", "report", nil); err != nil {
+			t.Fatal(err)
+		}
+		schedulers = append(schedulers, scheduler)
+	}
+	if err := schedulers[0].Add("report", "@hourly", "report", nil); err == nil {
+		t.Fatal("expected duplicate schedule names to be refused")
+	}
+
+	tick := func() {
+		for _, scheduler := range schedulers {
+			if err := scheduler.Tick(ctx); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	count := func() int {
+		jobs, _ := store.List(ctx, Filter{})
+		return len(jobs)
+	}
+
+	tick()
+	if n := count(); n != 0 {
+		t.Fatalf("expected no job before the first activation, got %d", n)
+	}
+
+	clock.Advance(3 * time.Minute) // 10:10:30
+	tick()
+	if n := count(); n != 1 {
+		t.Fatalf("expected one job for the 10:10 activation, got %d", n)
+	}
+
+	clock.Advance(20 * time.Minute) // 10:30:30, 10:15 to 10:30 were missed
+	tick()
+	jobs, _ := store.List(ctx, Filter{})
+	if len(jobs) != 2 || !jobs[0].RunAt.Equal(time.Date(2030, 1, 1, 10, 30, 0, 0, time.UTC)) {
+		t.Fatalf("expected missed activations to collapse into the 10:30 one, got %d jobs", len(jobs))
+	}
+}
+`
+
+// JobHandlerTemplate generates the admin endpoints inspecting the job queues
+const JobHandlerTemplate = `package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+{{range .HTTP.Imports}}	"{{.}}"
+{{end}}	"{{.Module}}/internal/jobs"
+)
+
+// JobHandler serves the admin endpoints of the job queues, mount it behind admin authentication
+type JobHandler struct {
+	store jobs.Store
+}
+
+// NewJobHandler creates a new job handler
+func NewJobHandler(store jobs.Store) *JobHandler {
+	return &JobHandler{store: store}
+}
+
+// Queues handles GET /jobs/queues, the jobs of every queue by status
+func (h *JobHandler) Queues({{.HTTP.HandlerParams}}){{.HTTP.HandlerResult}} {
+	stats, err := h.store.Stats({{.HTTP.Context}})
+	if err != nil {
+		{{.HTTP.Fail "http.StatusInternalServerError" "err.Error()"}}
+	}
+
+	{{.HTTP.Reply "http.StatusOK"}}{{.HTTP.Map}}{"data": stats})
+}
+
+// GetAll handles GET /jobs?queue=&status=&limit=, the latest jobs first
+func (h *JobHandler) GetAll({{.HTTP.HandlerParams}}){{.HTTP.HandlerResult}} {
+	filter := jobs.Filter{
+		Queue:  {{.HTTP.Query "queue"}},
+		Status: jobs.Status({{.HTTP.Query "status"}}),
+	}
+	if limit := {{.HTTP.Query "limit"}}; limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > 1000 {
+			{{.HTTP.Fail "http.StatusBadRequest" "\"limit must be between 1 and 1000\""}}
+		}
+		filter.Limit = n
+	}
+
+	list, err := h.store.List({{.HTTP.Context}}, filter)
+	if err != nil {
+		{{.HTTP.Fail "http.StatusInternalServerError" "err.Error()"}}
+	}
+
+	{{.HTTP.Reply "http.StatusOK"}}{{.HTTP.Map}}{"data": list, "total": len(list)})
+}
+
+// GetByID handles GET /jobs/:id
+func (h *JobHandler) GetByID({{.HTTP.HandlerParams}}){{.HTTP.HandlerResult}} {
+	job, err := h.store.Get({{.HTTP.Context}}, {{.HTTP.Param "id"}})
+	if err != nil {
+		{{.HTTP.Fail "jobErrorStatus(err)" "err.Error()"}}
+	}
+
+	{{.HTTP.Respond "http.StatusOK" "job"}}
+}
+
+// Retry handles POST /jobs/:id/retry, moving a job out of the dead letter
+func (h *JobHandler) Retry({{.HTTP.HandlerParams}}){{.HTTP.HandlerResult}} {
+	id := {{.HTTP.Param "id"}}
+	if err := h.store.Requeue({{.HTTP.Context}}, id, time.Now().UTC()); err != nil {
+		{{.HTTP.Fail "jobErrorStatus(err)" "err.Error()"}}
+	}
+
+	job, err := h.store.Get({{.HTTP.Context}}, id)
+	if err != nil {
+		{{.HTTP.Fail "jobErrorStatus(err)" "err.Error()"}}
+	}
+
+	{{.HTTP.Respond "http.StatusOK" "job"}}
+}
+
+// Delete handles DELETE /jobs/:id, running jobs can't be deleted
+func (h *JobHandler) Delete({{.HTTP.HandlerParams}}){{.HTTP.HandlerResult}} {
+	job, err := h.store.Get({{.HTTP.Context}}, {{.HTTP.Param "id"}})
+	if err != nil {
+		{{.HTTP.Fail "jobErrorStatus(err)" "err.Error()"}}
+	}
+	if job.Status == jobs.StatusRunning {
+		{{.HTTP.Fail "http.StatusConflict" "\"job is running\""}}
+	}
+
+	if err := h.store.Delete({{.HTTP.Context}}, job.ID); err != nil {
+		{{.HTTP.Fail "jobErrorStatus(err)" "err.Error()"}}
+	}
+
+	{{.HTTP.Reply "http.StatusOK"}}{{.HTTP.Map}}{"message": "Job deleted successfully"})
+}
+
+// jobErrorStatus maps store errors to HTTP statuses
+func jobErrorStatus(err error) int {
+	if errors.Is(err, jobs.ErrNotFound) {
+		return http.StatusNotFound
+	}
+	if errors.Is(err, jobs.ErrNotDead) {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// SetupJobRoutes sets up the admin routes of the job queues
+func SetupJobRoutes({{.HTTP.Router}}, handler *JobHandler) {
+{{- with .HTTP.Group "jobs" "/jobs"}}
+{{- with .Open}}
+	{{.}}
+{{- end}}
+	{{.Route "GET" "" "handler.GetAll"}}
+	{{.Route "GET" "/queues" "handler.Queues"}}
+	{{.Route "GET" "/:id" "handler.GetByID"}}
+	{{.Route "POST" "/:id/retry" "handler.Retry"}}
+	{{.Route "DELETE" "/:id" "handler.Delete"}}
+{{- with .Close}}
+	{{.}}
+{{- end}}
+{{- end}}
+}
+`
+
+// JobHandlerTestTemplate generates HTTP tests of the job admin routes
+const JobHandlerTestTemplate = `package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+{{range .HTTP.Imports}}	"{{.}}"
+{{end}}	"{{.Module}}/internal/jobs"
+)
+
+// newJobTestServer mounts the job routes under /admin on a {{.HTTP.Framework.GetDisplayName}} router and returns
+// a function sending a request through it
+func newJobTestServer(t *testing.T, store jobs.Store) func(method, path string) (int, map[string]interface{}) {
+	handler := NewJobHandler(store)
+{{- if .HTTP.Is "fiber"}}
+	app := fiber.New()
+	SetupJobRoutes(app.Group("/admin"), handler)
+{{- else if .HTTP.Is "echo"}}
+	router := echo.New()
+	SetupJobRoutes(router.Group("/admin"), handler)
+{{- else if .HTTP.Is "chi"}}
+	router := chi.NewRouter()
+	router.Route("/admin", func(r chi.Router) {
+		SetupJobRoutes(r, handler)
+	})
+{{- else if .HTTP.Is "stdlib"}}
+	admin := http.NewServeMux()
+	SetupJobRoutes(admin, handler)
+	router := http.NewServeMux()
+	router.Handle("/admin/", http.StripPrefix("/admin", admin))
+{{- else}}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	SetupJobRoutes(router.Group("/admin"), handler)
+{{- end}}
+
+	return func(method, path string) (int, map[string]interface{}) {
+		t.Helper()
+
+		req := httptest.NewRequest(method, path, nil)
+{{- if .HTTP.Is "fiber"}}
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		defer resp.Body.Close()
+
+		status := resp.StatusCode
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("%s %s: failed to read body: %v", method, path, err)
+		}
+{{- else}}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		status, data := rec.Code, rec.Body.Bytes()
+{{- end}}
+
+		var decoded map[string]interface{}
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("%s %s: response is not a JSON object: %s", method, path, data)
+			}
+		}
+		return status, decoded
+	}
+}
+
+func TestJobHandler_InspectsAndRetriesJobs(t *testing.T) {
+	ctx := context.Background()
+	store := jobs.NewMemoryStore()
+	queue := jobs.NewQueue(store)
+	queue.Handle("fails", func(ctx context.Context, job *jobs.Job) error { return jobs.Permanent(errors.New("refused")) })
+
+	dead, err := jobs.Enqueue(ctx, store, "fails", struct{}{}, jobs.RunAt(time.Now().Add(-time.Second)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := queue.Work(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := jobs.Enqueue(ctx, store, "send", struct{}{}, jobs.InQueue("emails"), jobs.Delay(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	do := newJobTestServer(t, store)
+
+	status, body := do(http.MethodGet, "/admin/jobs/queues")
+	if status != http.StatusOK {
+		t.Fatalf("queues: expected %d, got %d: %v", http.StatusOK, status, body)
+	}
+	stats, _ := body["data"].([]interface{})
+	if len(stats) != 2 {
+		t.Fatalf("queues: expected the default and emails queues, got %v", body)
+	}
+	if queue, _ := stats[0].(map[string]interface{}); queue["queue"] != jobs.DefaultQueue || queue["dead"] != float64(1) {
+		t.Fatalf("queues: expected one dead job in the default queue, got %v", stats[0])
+	}
+
+	status, body = do(http.MethodGet, "/admin/jobs?status=dead")
+	if status != http.StatusOK || body["total"] != float64(1) {
+		t.Fatalf("list: expected one dead job, got %d: %v", status, body)
+	}
+	if status, body = do(http.MethodGet, "/admin/jobs?limit=0"); status != http.StatusBadRequest {
+		t.Fatalf("list: expected %d for an invalid limit, got %d: %v", http.StatusBadRequest, status, body)
+	}
+
+	status, body = do(http.MethodGet, "/admin/jobs/"+dead.ID)
+	if status != http.StatusOK || body["status"] != string(jobs.StatusDead) || body["last_error"] != "refused" {
+		t.Fatalf("get: expected the dead job with its error, got %d: %v", status, body)
+	}
+
+	status, body = do(http.MethodPost, "/admin/jobs/"+dead.ID+"/retry")
+	if status != http.StatusOK || body["status"] != string(jobs.StatusPending) || body["attempts"] != float64(0) {
+		t.Fatalf("retry: expected a pending job, got %d: %v", status, body)
+	}
+	if status, body = do(http.MethodPost, "/admin/jobs/"+dead.ID+"/retry"); status != http.StatusConflict {
+		t.Fatalf("retry: expected %d for a job out of the dead letter, got %d: %v", http.StatusConflict, status, body)
+	}
+
+	if status, body = do(http.MethodDelete, "/admin/jobs/"+dead.ID); status != http.StatusOK {
+		t.Fatalf("delete: expected %d, got %d: %v", http.StatusOK, status, body)
+	}
+	if status, body = do(http.MethodGet, "/admin/jobs/"+dead.ID); status != http.StatusNotFound {
+		t.Fatalf("get after delete: expected %d, got %d: %v", http.StatusNotFound, status, body)
+	}
+}
+`
+
+// WorkerMainTemplate generates the worker entrypoint running the job queue and the schedules
+const WorkerMainTemplate = `package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/joho/godotenv"
+	"{{.Module}}/internal/jobs"
+	"{{.Module}}/pkg/database"
+)
+
+func main() {
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found")
+	}
+
+	// Connect to database
+{{- if eq .Provider "supabase"}}
+	if err := database.Connect(); err != nil {
+{{- else}}
+	if _, err := database.Connect(); err != nil {
+{{- end}}
+		log.Fatal("Failed to connect to database:", err)
+	}
+	db, err := database.DB.DB()
+	if err != nil {
+		log.Fatal("Failed to access the database connection:", err)
+	}
+
+	store := jobs.NewSQLStore(db, jobs.{{.Dialect}})
+	queue := jobs.NewQueue(store)
+	if concurrency, err := strconv.Atoi(os.Getenv("WORKER_CONCURRENCY")); err == nil && concurrency > 0 {
+		queue.Concurrency = concurrency
+	}
+	jobs.RegisterHandlers(queue, store)
+
+	scheduler := jobs.NewScheduler(store)
+	if err := jobs.RegisterSchedules(scheduler); err != nil {
+		log.Fatal("Failed to register schedules:", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Health check endpoint for the liveness probe
+	port := os.Getenv("WORKER_HEALTH_PORT")
+	if port == "" {
+		port = "8081"
+	}
+	go func() {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+		if err := http.ListenAndServe(":"+port, mux); err != nil {
+			log.Println("Health check server stopped:", err)
+		}
+	}()
+
+	go scheduler.Run(ctx)
+
+	log.Printf("Worker started with %d workers on queues %v", queue.Concurrency, queue.Queues)
+	queue.Run(ctx)
+	log.Println("Worker stopped")
+}
+`